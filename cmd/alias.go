@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"sherpa/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// alias flags
+	aliasConfigFile string
+)
+
+// aliasCmd manages repository aliases stored in the configuration file
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage short names for repositories",
+	Long: `Aliases are short names for repositories, usable anywhere a repository argument
+is accepted. They are stored under aliases in the configuration file.
+
+  # Define an alias, then use it like a repository
+  sherpa alias add pay https://gitlab.com/org/payments/backend#main
+  sherpa pay --token $GITLAB_TOKEN
+
+  # Override the branch of an alias
+  sherpa pay#develop
+
+  # List and remove aliases
+  sherpa alias ls
+  sherpa alias rm pay`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add <name> <repository>",
+	Short: "Define or replace an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile := aliasConfigPath()
+		if err := config.SetAlias(configFile, args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Alias %s -> %s saved to %s\n", args[0], args[1], configFile)
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List aliases",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.NewLoader().LoadConfig(aliasConfigPath())
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		return writeAliases(cmd.OutOrStdout(), cfg.Aliases)
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove an alias",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return config.RemoveAlias(aliasConfigPath(), args[0])
+	},
+}
+
+func init() {
+	aliasCmd.PersistentFlags().StringVarP(&aliasConfigFile, "config", "c", "", "Configuration file holding the aliases (default: sherpa/config.yml in the user config directory)")
+	aliasCmd.AddCommand(aliasAddCmd, aliasListCmd, aliasRemoveCmd)
+	RootCmd.AddCommand(aliasCmd)
+}
+
+// aliasConfigPath returns the configuration file aliases are read from and written to
+func aliasConfigPath() string {
+	if aliasConfigFile != "" {
+		return aliasConfigFile
+	}
+	return config.DefaultConfigFile()
+}
+
+// writeAliases lists aliases sorted by name, one per line
+func writeAliases(out io.Writer, aliases map[string]string) error {
+	if len(aliases) == 0 {
+		fmt.Fprintln(out, "No aliases defined. Add one with: sherpa alias add <name> <repository>")
+		return nil
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, aliases[name])
+	}
+	return w.Flush()
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliasCmd(t *testing.T) {
+	t.Run("should add, list and remove aliases", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yml")
+		run := func(args ...string) string {
+			var out bytes.Buffer
+			RootCmd.SetOut(&out)
+			RootCmd.SetArgs(append([]string{"alias"}, append(args, "--config", configFile)...))
+			require.NoError(t, RootCmd.Execute())
+			return out.String()
+		}
+		defer func() {
+			RootCmd.SetOut(nil)
+			RootCmd.SetArgs(nil)
+			aliasConfigFile = ""
+		}()
+
+		assert.Contains(t, run("ls"), "No aliases defined")
+		assert.Contains(t, run("add", "pay", "gitlab.com/org/payments#main"), "Alias pay -> gitlab.com/org/payments#main saved")
+		run("add", "api", "owner/api")
+		assert.Equal(t, "api  owner/api\npay  gitlab.com/org/payments#main\n", run("ls"))
+
+		run("rm", "pay")
+		assert.Equal(t, "api  owner/api\n", run("ls"))
+	})
+}
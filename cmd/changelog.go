@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sherpa/internal/generators"
+	"sherpa/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// changelog flags
+	changelogOutput   string
+	changelogLanguage string
+)
+
+// changelogCmd summarizes the changes between two generated snapshots
+var changelogCmd = &cobra.Command{
+	Use:   "changelog <before> <after>",
+	Short: "Summarize what changed between two generated snapshots of a repository",
+	Long: `Changelog compares two tree.json snapshots of a repository, given as the files or
+as the output directories holding them, and writes a Markdown summary of what
+changed: file, size and token totals, new directories and the files added, removed
+or changed. Snapshots are written with --tree-json.
+
+The summary is printed, or written to a file with --output, for instance to attach
+it to a notification after refreshing outputs.
+
+  sherpa changelog old-output/owner_repo sherpa-output/owner_repo
+  sherpa changelog before/tree.json after/tree.json -o CHANGES.md`,
+	Args: cobra.ExactArgs(2),
+	RunE: runChangelog,
+}
+
+func init() {
+	changelogCmd.Flags().StringVarP(&changelogOutput, "output", "o", "", "Write the summary to this file instead of the standard output")
+	changelogCmd.Flags().StringVar(&changelogLanguage, "lang", "", "Language for headings (en, fr, ja)")
+	RootCmd.AddCommand(changelogCmd)
+}
+
+// runChangelog executes the changelog command
+func runChangelog(cmd *cobra.Command, args []string) error {
+	if changelogLanguage != "" && !generators.IsSupportedLanguage(changelogLanguage) {
+		return fmt.Errorf("unsupported output language '%s'. Valid options: %s", changelogLanguage, strings.Join(generators.SupportedLanguages(), ", "))
+	}
+
+	before, err := readSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	after, err := readSnapshot(args[1])
+	if err != nil {
+		return err
+	}
+
+	generator := generators.NewGeneratorWithConfig(true, models.OutputConfig{Language: changelogLanguage})
+	changelog := generator.GenerateChangelog(before, after)
+	if changelogOutput == "" {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), changelog)
+		return err
+	}
+	if err := os.WriteFile(changelogOutput, []byte(changelog), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", changelogOutput, err)
+	}
+	return nil
+}
+
+// readSnapshot reads a tree.json file, or the tree.json of an output directory
+func readSnapshot(path string) (*generators.TreeDocument, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "tree.json")
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot %s not found: write outputs with --tree-json to compare them", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+	}
+
+	var document generators.TreeDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return &document, nil
+}
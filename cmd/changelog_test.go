@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelogCmd(t *testing.T) {
+	writeSnapshot := func(t *testing.T, tree string) string {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "tree.json"), []byte(`{"repository": "owner/repo", "tree": [`+tree+`]}`), 0644))
+		return dir
+	}
+	defer func() {
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs(nil)
+	}()
+
+	t.Run("should compare the snapshots of two output directories", func(t *testing.T) {
+		before := writeSnapshot(t, `{"name": "main.go", "path": "main.go", "type": "file", "size": 10, "tokens": 3}`)
+		after := writeSnapshot(t, `{"name": "main.go", "path": "main.go", "type": "file", "size": 10, "tokens": 3},
+			{"name": "util.go", "path": "util.go", "type": "file", "size": 20, "tokens": 5}`)
+
+		var out bytes.Buffer
+		RootCmd.SetOut(&out)
+		RootCmd.SetArgs([]string{"changelog", before, filepath.Join(after, "tree.json")})
+		require.NoError(t, RootCmd.Execute())
+		assert.Contains(t, out.String(), "## Added Files (1)\n\n- `util.go` (20 B, ~5 tokens)\n")
+	})
+
+	t.Run("should explain how to write missing snapshots", func(t *testing.T) {
+		RootCmd.SetArgs([]string{"changelog", t.TempDir(), t.TempDir()})
+		err := RootCmd.Execute()
+		assert.ErrorContains(t, err, "write outputs with --tree-json to compare them")
+	})
+}
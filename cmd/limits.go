@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/config"
+	"sherpa/internal/orchestration"
+	"sherpa/pkg/models"
+	"sherpa/pkg/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// limits flags
+	limitsToken      string
+	limitsConfigFile string
+	limitsPlatform   string
+)
+
+// limitsPlatforms are the platforms whose quotas are checked, in the order they are printed
+var limitsPlatforms = []models.Platform{models.PlatformGitHub, models.PlatformGitLab, models.PlatformGitea}
+
+// limitsCmd prints the API quotas of the configured platform tokens
+var limitsCmd = &cobra.Command{
+	Use:   "limits",
+	Short: "Print the remaining API quota of each configured platform token",
+	Long: `Limits asks GitHub, GitLab and Gitea for the current API quotas of the token of
+each platform, read from its environment variable or --token, and prints how many
+requests remain and when the quota resets. Check it before a large batch run:
+sherpa sends about one request per file fetched, and waits for quotas to reset
+only up to --max-wait.
+
+Platforms without a token are skipped. Instances with rate limiting disabled
+report no quota.
+
+  sherpa limits
+  sherpa limits --platform gitlab --token $GITLAB_TOKEN`,
+	Args: cobra.NoArgs,
+	RunE: runLimits,
+}
+
+func init() {
+	limitsCmd.Flags().StringVarP(&limitsToken, "token", "t", "", "Personal access token, used for every platform checked (default: the token environment variable of each platform)")
+	limitsCmd.Flags().StringVarP(&limitsConfigFile, "config", "c", "", "Configuration file path (default: sherpa/config.yml in the user config directory, when it exists)")
+	limitsCmd.Flags().StringVarP(&limitsPlatform, "platform", "p", "", "Only check this platform (github, gitlab, gitea)")
+	RootCmd.AddCommand(limitsCmd)
+}
+
+// runLimits executes the limits command
+func runLimits(cmd *cobra.Command, args []string) error {
+	platforms := limitsPlatforms
+	if limitsPlatform != "" {
+		platform := models.Platform(strings.ToLower(limitsPlatform))
+		switch platform {
+		case models.PlatformGitHub, models.PlatformGitLab, models.PlatformGitea:
+			platforms = []models.Platform{platform}
+		default:
+			return fmt.Errorf("invalid platform '%s'. Valid options: github, gitlab, gitea", limitsPlatform)
+		}
+	}
+
+	if limitsConfigFile == "" {
+		limitsConfigFile = config.DefaultConfigFile()
+	}
+	configLoader := config.NewLoader()
+	cfg, err := configLoader.LoadConfig(limitsConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	outputTheme, err := ui.LookupTheme(ui.DefaultTheme)
+	if err != nil {
+		return err
+	}
+	printer := ui.New(cmd.OutOrStdout(), cmd.ErrOrStderr(), outputTheme, ui.ColorEnabled(os.Stdout))
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	client := &http.Client{Transport: adapters.NewHeaderTransport(nil, cfg.HTTP)}
+
+	var failures []error
+	checked := 0
+	for _, platform := range platforms {
+		token, err := orchestration.GetTokenForPlatform(platform, cfg, limitsToken)
+		if err != nil {
+			printer.Block().Info("%s: skipped, %v", platformTitle(platform), err).Flush()
+			continue
+		}
+		checked++
+
+		limits, err := adapters.FetchRateLimits(ctx, client, platform, cfg, token)
+		if err != nil {
+			printer.ErrorBlock().Error("%s: %v", platformTitle(platform), err).Flush()
+			failures = append(failures, err)
+			continue
+		}
+		writeLimits(printer.Block(), platform, limits, time.Now()).Flush()
+	}
+
+	if checked == 0 {
+		return fmt.Errorf("no platform token found: set the token environment variable of a platform or use --token")
+	}
+	return errors.Join(failures...)
+}
+
+// writeLimits describes the quotas of a platform, warning about the ones running low
+func writeLimits(block *ui.Block, platform models.Platform, limits []adapters.RateLimit, now time.Time) *ui.Block {
+	if len(limits) == 0 {
+		return block.Success("%s: no rate limit reported", platformTitle(platform))
+	}
+
+	low := false
+	for _, limit := range limits {
+		low = low || limit.Low()
+	}
+	if low {
+		block.Warning("%s (%s): quota running low", platformTitle(platform), limits[0].Host)
+	} else {
+		block.Success("%s (%s)", platformTitle(platform), limits[0].Host)
+	}
+	for _, limit := range limits {
+		resets := "unknown reset"
+		if !limit.Reset.IsZero() {
+			resets = fmt.Sprintf("resets at %s (in %s)", limit.Reset.Format(time.TimeOnly), max(limit.Reset.Sub(now).Round(time.Second), 0))
+		}
+		block.Field(limit.Resource, "%d/%d remaining, %s", limit.Remaining, limit.Limit, resets)
+	}
+	return block
+}
+
+// platformTitle returns the display name of a platform
+func platformTitle(platform models.Platform) string {
+	switch platform {
+	case models.PlatformGitHub:
+		return "GitHub"
+	case models.PlatformGitLab:
+		return "GitLab"
+	case models.PlatformGitea:
+		return "Gitea"
+	default:
+		return string(platform)
+	}
+}
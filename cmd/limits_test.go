@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sherpa/internal/adapters"
+	"sherpa/pkg/models"
+	"sherpa/pkg/ui"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitsCmd(t *testing.T) {
+	defer func() {
+		RootCmd.SetOut(nil)
+		RootCmd.SetArgs(nil)
+		limitsToken, limitsConfigFile, limitsPlatform = "", "", ""
+	}()
+
+	t.Run("should print the quotas of the platforms with a token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"resources": {"core": {"limit": 5000, "remaining": 4321, "reset": 1700000000}}}`))
+		}))
+		defer server.Close()
+
+		configFile := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(configFile, []byte("github:\n  base_url: "+server.URL+"\n"), 0644))
+		t.Setenv("GITHUB_TOKEN", "secret")
+		t.Setenv("GITLAB_TOKEN", "")
+		t.Setenv("GITEA_TOKEN", "")
+
+		var out bytes.Buffer
+		RootCmd.SetOut(&out)
+		RootCmd.SetArgs([]string{"limits", "--config", configFile})
+		require.NoError(t, RootCmd.Execute())
+		assert.Contains(t, out.String(), "GitHub (127.0.0.1")
+		assert.Contains(t, out.String(), "core: 4321/5000 remaining")
+		assert.Contains(t, out.String(), "GitLab: skipped")
+	})
+
+	t.Run("should fail without any platform token", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "")
+		RootCmd.SetArgs([]string{"limits", "--platform", "github", "--config", filepath.Join(t.TempDir(), "missing.yml")})
+		assert.ErrorContains(t, RootCmd.Execute(), "no platform token found")
+	})
+
+	t.Run("should reject unknown platforms", func(t *testing.T) {
+		RootCmd.SetArgs([]string{"limits", "--platform", "bitbucket"})
+		assert.ErrorContains(t, RootCmd.Execute(), "invalid platform 'bitbucket'")
+	})
+}
+
+func TestWriteLimits(t *testing.T) {
+	theme, err := ui.LookupTheme("plain")
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+
+	t.Run("should warn about quotas running low", func(t *testing.T) {
+		var out bytes.Buffer
+		printer := ui.New(&out, &out, theme, false)
+		writeLimits(printer.Block(), models.PlatformGitHub, []adapters.RateLimit{
+			{Host: "api.github.com", Resource: "core", Limit: 5000, Remaining: 100, Reset: now.Add(12 * time.Minute)},
+		}, now).Flush()
+		assert.Contains(t, out.String(), "GitHub (api.github.com): quota running low")
+		assert.Contains(t, out.String(), "core: 100/5000 remaining, resets at 12:12:00 (in 12m0s)")
+	})
+
+	t.Run("should report instances without rate limits", func(t *testing.T) {
+		var out bytes.Buffer
+		printer := ui.New(&out, &out, theme, false)
+		writeLimits(printer.Block(), models.PlatformGitea, nil, now).Flush()
+		assert.Contains(t, out.String(), "Gitea: no rate limit reported")
+	})
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/config"
+	"sherpa/internal/orchestration"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+	"sherpa/pkg/ui"
+	"sherpa/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// pr flags
+	prToken         string
+	prBaseURL       string
+	prOutput        string
+	prConfigFile    string
+	prContextLines  int
+	prNoDescription bool
+	prLanguage      string
+	prQuiet         bool
+	prVerbose       int
+)
+
+// prCmd generates llms-pr.txt for a GitHub pull request
+var prCmd = &cobra.Command{
+	Use:   "pr <url>",
+	Short: "Generate llms-pr.txt from the files changed in a GitHub pull request",
+	Long: `PR fetches only the files changed in a GitHub pull request and writes a focused
+llms-pr.txt with the pull request description, the list of changed files and their
+diffs. With --context, the lines of the new version around each change are included
+as well, read at the head commit of the pull request.
+
+The output is written to <output>/<owner>_<repo>_pull_<number>/llms-pr.txt.
+
+  sherpa pr https://github.com/owner/repo/pull/123
+  sherpa pr https://github.com/owner/repo/pull/123 --context 10
+  sherpa pr https://github.example.com/owner/repo/pull/7 --base-url https://github.example.com/api/v3`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPR,
+}
+
+func init() {
+	prCmd.Flags().StringVarP(&prToken, "token", "t", "", "Personal access token for GitHub (default: GITHUB_TOKEN)")
+	prCmd.Flags().StringVar(&prBaseURL, "base-url", "", "GitHub API base URL for GitHub Enterprise (default: derived from the pull request URL)")
+	prCmd.Flags().StringVarP(&prOutput, "output", "o", "./sherpa-output", "Output directory")
+	prCmd.Flags().StringVarP(&prConfigFile, "config", "c", "", "Configuration file path (default: sherpa/config.yml in the user config directory, when it exists)")
+	prCmd.Flags().IntVarP(&prContextLines, "context", "C", 0, "Include N lines of the new version around each change (0 = diffs only)")
+	prCmd.Flags().BoolVar(&prNoDescription, "no-description", false, "Leave the pull request description out")
+	prCmd.Flags().StringVar(&prLanguage, "lang", "", "Language for generated headings and notes (en, fr, ja)")
+	prCmd.Flags().BoolVarP(&prQuiet, "quiet", "q", false, "Suppress progress output")
+	prCmd.Flags().CountVarP(&prVerbose, "verbose", "v", "Increase log verbosity: -v info, -vv debug, -vvv trace with a line per fetched file")
+	RootCmd.AddCommand(prCmd)
+}
+
+// runPR executes the pr command
+func runPR(cmd *cobra.Command, args []string) error {
+	repoInfo, number, err := adapters.ParsePullRequestURL(args[0])
+	if err != nil {
+		return err
+	}
+	if prContextLines < 0 {
+		return fmt.Errorf("invalid --context %d: must be zero or more", prContextLines)
+	}
+
+	outputTheme, err := ui.LookupTheme(ui.DefaultTheme)
+	if err != nil {
+		return err
+	}
+	printer := ui.New(os.Stdout, os.Stderr, outputTheme, ui.ColorEnabled(os.Stdout))
+	logger.SetColors(ui.ColorEnabled(os.Stderr))
+	if prQuiet {
+		logger.SetQuiet()
+	} else {
+		logger.SetVerbosity(prVerbose)
+	}
+
+	cliOptions := &models.CLIOptions{
+		Token:      prToken,
+		Output:     prOutput,
+		ConfigFile: prConfigFile,
+		Language:   prLanguage,
+		Verbose:    prVerbose > 0,
+		Quiet:      prQuiet,
+	}
+
+	if prConfigFile == "" {
+		prConfigFile = config.DefaultConfigFile()
+	}
+	configLoader := config.NewLoader()
+	cfg, err := configLoader.LoadConfig(prConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := configLoader.OverrideWithFlags(cfg, cliOptions); err != nil {
+		return fmt.Errorf("failed to process configuration: %w", err)
+	}
+	cfg.GitHub.BaseURL = pullRequestAPIURL(repoInfo, prBaseURL, cfg.GitHub.BaseURL)
+	if err := configLoader.ValidateConfig(cfg); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	utils.SetSIUnits(cfg.Output.SizeUnits == models.SizeUnitsSI)
+
+	orchestrator := orchestration.NewOrchestrator(cfg, cliOptions)
+	orchestrator.SetPrinter(printer)
+	_, err = orchestrator.ProcessPullRequest(context.Background(), repoInfo, number, orchestration.PullRequestOptions{
+		ContextLines:  prContextLines,
+		NoDescription: prNoDescription,
+	})
+	logger.FlushRepeated()
+	return err
+}
+
+// pullRequestAPIURL returns the GitHub API base URL for a pull request: --base-url when
+// set, the configured URL for github.com, and the GitHub Enterprise API of the host otherwise
+func pullRequestAPIURL(repoInfo *models.RepositoryInfo, flagURL, configured string) string {
+	if flagURL != "" {
+		return flagURL
+	}
+	host := strings.TrimSuffix(repoInfo.URL, "/"+repoInfo.FullName)
+	if host == "https://github.com" || host == "http://github.com" || host == "https://www.github.com" {
+		return configured
+	}
+	return host + "/api/v3"
+}
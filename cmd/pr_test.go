@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"sherpa/internal/adapters"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestAPIURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		flagURL string
+		want    string
+	}{
+		{
+			name: "should keep the configured API for github.com",
+			url:  "https://github.com/owner/repo/pull/1",
+			want: "https://api.github.com",
+		},
+		{
+			name: "should use the GitHub Enterprise API of other hosts",
+			url:  "https://github.example.com/owner/repo/pull/1",
+			want: "https://github.example.com/api/v3",
+		},
+		{
+			name:    "should prefer --base-url",
+			url:     "https://github.example.com/owner/repo/pull/1",
+			flagURL: "https://api.example.com",
+			want:    "https://api.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoInfo, _, err := adapters.ParsePullRequestURL(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, pullRequestAPIURL(repoInfo, tt.flagURL, "https://api.github.com"))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinArg is the repository argument that reads the repository list from stdin
+const stdinArg = "-"
+
+// readRepositoryArgs replaces the "-" argument with the repositories read from stdin
+// and appends the repositories listed in reposFile, when set
+func readRepositoryArgs(args []string, reposFile string, stdin io.Reader) ([]string, error) {
+	var repositories []string
+	readStdin := false
+	for _, arg := range args {
+		if arg != stdinArg {
+			repositories = append(repositories, arg)
+			continue
+		}
+		if readStdin {
+			continue
+		}
+		readStdin = true
+
+		listed, err := readRepositoryList(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read repositories from stdin: %w", err)
+		}
+		repositories = append(repositories, listed...)
+	}
+
+	if reposFile != "" {
+		file, err := os.Open(reposFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open repository list: %w", err)
+		}
+		defer file.Close()
+
+		listed, err := readRepositoryList(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read repository list %s: %w", reposFile, err)
+		}
+		repositories = append(repositories, listed...)
+	}
+	return repositories, nil
+}
+
+// readRepositoryList reads one repository per line, skipping blank lines and # comments.
+// Only the first field of a line is kept, so tab-separated listings like the output
+// of "gh repo list" can be piped as they are
+func readRepositoryList(r io.Reader) ([]string, error) {
+	var repositories []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		repositories = append(repositories, fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return repositories, nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRepositoryList(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "should read one repository per line",
+			input: "owner/repo\nhttps://gitlab.com/group/project#develop\n",
+			want:  []string{"owner/repo", "https://gitlab.com/group/project#develop"},
+		},
+		{
+			name:  "should skip blank lines and comments",
+			input: "# services\n\n  owner/api  \n#owner/old\n",
+			want:  []string{"owner/api"},
+		},
+		{
+			name:  "should keep the first field of tab-separated listings",
+			input: "my-org/api\tThe public API\tpublic\t2026-01-02T10:00:00Z\nmy-org/web\t\tprivate\t2026-01-01T10:00:00Z\n",
+			want:  []string{"my-org/api", "my-org/web"},
+		},
+		{
+			name:  "should read an empty list",
+			input: "",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repositories, err := readRepositoryList(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, repositories)
+		})
+	}
+}
+
+func TestReadRepositoryArgs(t *testing.T) {
+	t.Run("should replace - with the repositories read from stdin", func(t *testing.T) {
+		args, err := readRepositoryArgs([]string{"./local", "-", "owner/last"}, "", strings.NewReader("owner/a\nowner/b\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"./local", "owner/a", "owner/b", "owner/last"}, args)
+	})
+
+	t.Run("should read stdin only once", func(t *testing.T) {
+		args, err := readRepositoryArgs([]string{"-", "-"}, "", strings.NewReader("owner/a\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"owner/a"}, args)
+	})
+
+	t.Run("should append the repositories of the list file", func(t *testing.T) {
+		reposFile := filepath.Join(t.TempDir(), "repos.txt")
+		require.NoError(t, os.WriteFile(reposFile, []byte("# backend\nowner/api#main\n"), 0644))
+
+		args, err := readRepositoryArgs([]string{"owner/web"}, reposFile, strings.NewReader(""))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"owner/web", "owner/api#main"}, args)
+	})
+
+	t.Run("should fail when the list file does not exist", func(t *testing.T) {
+		_, err := readRepositoryArgs(nil, filepath.Join(t.TempDir(), "missing.txt"), strings.NewReader(""))
+		assert.ErrorContains(t, err, "failed to open repository list")
+	})
+}
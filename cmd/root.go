@@ -3,13 +3,22 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"sherpa/internal/adapters"
 	"sherpa/internal/config"
+	"sherpa/internal/faults"
+	"sherpa/internal/httpdebug"
 	"sherpa/internal/orchestration"
+	"sherpa/internal/session"
 	"sherpa/pkg/logger"
 	"sherpa/pkg/models"
+	"sherpa/pkg/ui"
+	"sherpa/pkg/utils"
 
 	"github.com/spf13/cobra"
 )
@@ -25,7 +34,7 @@ var (
 	ignoreFlag          string
 	includeOnly         string
 	configFile          string
-	verbose             bool
+	verbose             int
 	quiet               bool
 	defaultPlatform     string
 	maxReposConcurrency int
@@ -34,6 +43,78 @@ var (
 	maxTotalMemory      int64
 	maxFiles            int
 	dryRun              bool
+	language            string
+	maxTreeEntries      int
+	expandTree          bool
+	treeJSON            bool
+	deterministic       bool
+	repoLogs            bool
+	editorRules         bool
+	stripLicenses       bool
+	llmsTxt             bool
+	llmsTxtLegacy       bool
+	artifacts           string
+	outputFormat        string
+	fileTags            bool
+	ackSensitive        bool
+	maxRepoSize         string
+	match               string
+	matchNeighbors      bool
+	lock                bool
+	locked              bool
+	failFast            bool
+	failOnError         bool
+	errorThreshold      string
+	offline             bool
+	writeWorkers        int
+	fsyncPolicy         string
+	noRepoConfig        bool
+	tokenBudget         int
+	maxTokens           int
+	splitSize           string
+	splitTokens         string
+	fitFor              string
+	packing             string
+	nameTemplate        string
+	review              bool
+	faultInject         string
+	goModules           []string
+	npmPackages         []string
+	pypiPackages        []string
+	incremental         bool
+	userAgent           string
+	maxWait             time.Duration
+	headers             []string
+	debugHTTP           bool
+	debugHTTPFile       string
+	recordSession       string
+	replaySession       string
+	strategy            string
+	noHistory           bool
+	theme               string
+	noColor             bool
+	includeArchived     bool
+	includeForks        bool
+	visibility          string
+	topics              []string
+	reposFile           string
+	selectTags          []string
+	siUnits             bool
+	subdirectory        string
+	diffRange           string
+	includeIssues       bool
+	includeReleases     bool
+	releaseLimit        int
+	includeWiki         bool
+	altText             bool
+	altTextEndpoint     string
+	altTextModel        string
+	budgetTime          time.Duration
+	sample              string
+	issueState          string
+	issueLabels         []string
+	issueLimit          int
+	branchFlag          string
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -42,7 +123,7 @@ var RootCmd = &cobra.Command{
 	Short:   "Git Repository to LLMs Context Generator",
 	Version: Version,
 	Long: `Sherpa is a lightweight CLI tool that processes repositories from
-GitLab, GitHub, and local folders, generating comprehensive llms-full.txt files for LLM context.
+GitLab, GitHub, Gitea/Forgejo, and local folders, generating comprehensive llms-full.txt files for LLM context.
 
 It helps developers quickly create LLM-readable context from internal
 codebases for debugging and cross-project analysis.
@@ -51,15 +132,43 @@ Platform Detection:
   Sherpa automatically detects the platform based on the repository URL or path:
   - GitHub: https://github.com/owner/repo or owner/repo
   - GitLab: https://gitlab.com/owner/repo or bare repo names (default)
-  - Local: /path/to/folder, ./relative/path, or ~/home/path
+  - Gitea/Forgejo: https://gitea.com/owner/repo, https://codeberg.org/owner/repo,
+    hosts named gitea or forgejo, or owner/repo with --default-platform gitea
+  - Local: /path/to/folder, ./relative/path, ~/home/path, or file:///path/to/folder
+  - Gists and snippets: https://gist.github.com/owner/id, https://gitlab.com/-/snippets/id
+    or https://gitlab.com/group/project/-/snippets/id, using the GitHub or GitLab token
+  - Downloads: URLs ending in .tar.gz, .tgz, .tar or .zip, and raw file URLs, processed
+    like local folders without a token
+  - Go modules: --gomod module/path@version, fetched from GOPROXY (default proxy.golang.org)
+  - Packages: --npm name@version and --pypi name==version, fetched from npm and PyPI
+  - Git remotes: git://, ssh://, or git+https:// URLs, shallow-cloned with git without a token
+  - Aliases: names defined with "sherpa alias add", replaced by their repository
+  - Organizations and groups: org:name (GitHub unless --default-platform is set),
+    group/subgroup/* (GitLab), or a group URL ending in /*, expanded into their repositories
+  - Lists: --repos-file repos.txt, or - to read repositories from stdin, one per line
+  - Tags: --tags team:payments, the repositories of the configuration file with every tag
 
 Branch Targeting:
   Specify a target branch using URL fragment syntax (#branch):
   - https://gitlab.com/owner/repo#develop
   - https://github.com/owner/repo#feature-branch
   - owner/repo#main
+  - owner/repo#feature/foo#bar (everything after the first # is the branch)
+  Or use --branch feature/foo for every repository, replacing any fragment, which
+  also suits URLs whose fragment is an anchor.
   
   If no branch is specified, the repository's default branch is used.
+
+Changes Between Refs:
+  Use --diff base..head to process only the files changed between two refs, read
+  at head, and write their unified diffs to llms-diff.txt:
+  - sherpa owner/repo --diff v1.2.0..v1.3.0
+
+Subdirectories:
+  Add a path after the branch to process only that part of a repository:
+  - owner/repo#main:services/api
+  - https://gitlab.com/group/monorepo#:docs (default branch)
+  Or use --path services/api for every repository without a path of its own.
   Note: Branch targeting is not applicable to local folders.
 
 Examples:
@@ -71,10 +180,64 @@ Examples:
   sherpa https://gitlab.com/owner/repo --token $GITLAB_TOKEN
   sherpa platform-api --token $GITLAB_TOKEN
 
+  # Gists and snippets
+  sherpa https://gist.github.com/owner/6cad326836d38bd3a7ae --token $GITHUB_TOKEN
+  sherpa https://gitlab.com/group/project/-/snippets/42 --token $GITLAB_TOKEN
+
+  # Archives and raw files
+  sherpa https://releases.example.com/tool/tool-1.2.0.tar.gz
+  sherpa https://raw.githubusercontent.com/owner/repo/main/install.sh
+
+  # Go module versions
+  sherpa --gomod github.com/spf13/cobra@v1.8.0
+  sherpa ./my-service --gomod golang.org/x/sync@latest
+
+  # npm and PyPI packages
+  sherpa --npm left-pad@1.3.0 --pypi requests==2.31.0
+
+  # Shallow clones instead of API calls
+  sherpa owner/repo --strategy clone
+  sherpa git+https://git.example.com/team/tool.git
+
+  # Repository aliases
+  sherpa alias add pay https://gitlab.com/org/payments/backend#main
+  sherpa pay#develop --token $GITLAB_TOKEN
+
+  # Every repository of an organization or group
+  sherpa org:my-github-org --token $GITHUB_TOKEN
+  sherpa "platform-team/backend/*" --topic payments --token $GITLAB_TOKEN
+
+  # Repository lists from a file or another command
+  sherpa --repos-file repos.txt --token $GITHUB_TOKEN
+  gh repo list my-org --limit 50 | sherpa - --token $GITHUB_TOKEN
+
+  # Repositories of the configuration file tagged with both tags
+  sherpa --tags team:payments,tier:critical --token $GITLAB_TOKEN
+
+  # Open bugs as context alongside the code
+  sherpa owner/repo --include-issues --issue-label bug --token $GITHUB_TOKEN
+
+  # Architecture notes from the wiki and the latest release notes
+  sherpa owner/repo --include-wiki --include-releases --release-limit 5 --token $GITHUB_TOKEN
+
+  # A tenth of the files, to get a feel for a repository
+  sherpa owner/repo --sample 10% --token $GITHUB_TOKEN
+
+  # Good-enough context within five minutes
+  sherpa owner/repo --budget-time 5m --token $GITHUB_TOKEN
+
+  # Changed files of a GitHub pull request
+  sherpa pr https://github.com/owner/repo/pull/123 --context 10
+
+  # Previous runs
+  sherpa history
+  sherpa rerun last
+
   # Local folders
   sherpa /path/to/my/project
   sherpa ./src/backend
   sherpa ~/my-projects/frontend
+  sherpa file:///home/me/my-projects/backend
 
   # Branch targeting
   sherpa owner/repo#feature-branch --token $GITHUB_TOKEN
@@ -84,9 +247,15 @@ Examples:
   sherpa owner/repo --default-platform github
   sherpa owner/repo --default-platform gitlab
 
+  # Self-hosted Gitea or Forgejo
+  sherpa owner/repo --default-platform gitea --base-url https://git.company.com --token $GITEA_TOKEN
+
   # Mixed platforms with environment tokens
   sherpa owner/repo platform-api ./local-project
 
+  # Custom User-Agent and headers, e.g. for an API gateway
+  sherpa owner/repo --user-agent "sherpa-ci/1.0" --header "X-Team: platform" --token $GITHUB_TOKEN
+
   # Use configuration file
   sherpa platform-api --config .sherpa.yml
 
@@ -99,7 +268,7 @@ Examples:
   # Preview operations with dry run
   sherpa owner/repo --dry-run --token $GITHUB_TOKEN
   sherpa repo1 repo2 repo3 ./local-folder --dry-run --token $GITHUB_TOKEN`,
-	Args: cobra.MinimumNArgs(1),
+	Args: requireInputs,
 	RunE: runFetch,
 }
 
@@ -110,27 +279,108 @@ func init() {
 	RootCmd.Flags().StringVarP(&outputDir, "output", "o", "./sherpa-output", "Output directory")
 	RootCmd.Flags().StringVar(&ignoreFlag, "ignore", "", "Comma-separated ignore patterns")
 	RootCmd.Flags().StringVar(&includeOnly, "include-only", "", "Include only matching patterns")
-	RootCmd.Flags().StringVarP(&configFile, "config", "c", "", "Configuration file path")
-	RootCmd.Flags().StringVar(&defaultPlatform, "default-platform", "", "Default platform for owner/repo format (github or gitlab)")
-	RootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
-	RootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress output")
+	RootCmd.Flags().StringVarP(&configFile, "config", "c", "", "Configuration file path (default: sherpa/config.yml in the user config directory, when it exists)")
+	RootCmd.Flags().StringVar(&defaultPlatform, "default-platform", "", "Default platform for owner/repo format (github, gitlab or gitea)")
+	RootCmd.Flags().CountVarP(&verbose, "verbose", "v", "Increase log verbosity: -v info, -vv debug, -vvv trace with a line per fetched file")
+	RootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress output, printing one summary line per repository")
+	RootCmd.Flags().StringVar(&theme, "theme", ui.DefaultTheme, "Output theme: "+strings.Join(ui.Themes(), ", "))
+	RootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colors in output and logs (also set by the NO_COLOR environment variable)")
 	RootCmd.Flags().IntVarP(&maxReposConcurrency, "max-repos-concurrency", "m", 5, "Maximum number of repositories to process concurrently")
 	RootCmd.Flags().IntVar(&maxFilesConcurrency, "max-files-concurrency", 20, "Maximum number of files to process concurrently per repository")
 	RootCmd.Flags().Int64Var(&maxMemoryPerFile, "max-memory-per-file", 50*1024*1024, "Maximum memory per file in bytes (default: 50MB)")
 	RootCmd.Flags().Int64Var(&maxTotalMemory, "max-total-memory", 2*1024*1024*1024, "Maximum total memory in bytes (default: 2GB)")
 	RootCmd.Flags().IntVar(&maxFiles, "max-files", 1000, "Maximum number of files to process")
+	RootCmd.Flags().StringVar(&maxRepoSize, "max-repo-size", "", "Abort before fetching when the filtered repository tree exceeds this size (e.g. 500MB)")
+	RootCmd.Flags().StringVar(&sample, "sample", "", "Fetch only this share of the files (e.g. 10%), picked reproducibly per repository and ref")
+	RootCmd.Flags().DurationVar(&budgetTime, "budget-time", 0, "Stop fetching as the run nears this duration (e.g. 5m) and write outputs with the remaining files as stubs")
+	RootCmd.Flags().StringVar(&match, "match", "", "Fetch only files matching a code search query (e.g. \"payment AND retry\")")
+	RootCmd.Flags().BoolVar(&matchNeighbors, "match-neighbors", false, "With --match, also fetch files in the same directories as matches")
+	RootCmd.Flags().StringVar(&diffRange, "diff", "", "Fetch only files changed between two refs (e.g. v1.2.0..v1.3.0) and write their diffs to llms-diff.txt")
+	RootCmd.Flags().BoolVar(&includeIssues, "include-issues", false, "Include GitHub and GitLab issues in an Issues section of the output")
+	RootCmd.Flags().StringVar(&issueState, "issue-state", "", "With --include-issues, the issues included: open, closed or all (default open)")
+	RootCmd.Flags().StringArrayVar(&issueLabels, "issue-label", nil, "With --include-issues, include only issues with this label (repeatable, all must match)")
+	RootCmd.Flags().IntVar(&issueLimit, "issue-limit", 0, "With --include-issues, the maximum number of issues, most recently updated first (default 20)")
+	RootCmd.Flags().BoolVar(&includeReleases, "include-releases", false, "Include GitHub and GitLab release notes in a Releases section of the output")
+	RootCmd.Flags().IntVar(&releaseLimit, "release-limit", 0, "With --include-releases, the maximum number of releases, most recent first (default 10)")
+	RootCmd.Flags().BoolVar(&includeWiki, "include-wiki", false, "Include the pages of the GitHub or GitLab wiki in a Wiki section of the output")
+	RootCmd.Flags().BoolVar(&altText, "alt-text", false, "Describe the images without alt text of included Markdown files with a vision-capable model")
+	RootCmd.Flags().StringVar(&altTextEndpoint, "alt-text-endpoint", "", "With --alt-text, the OpenAI-compatible chat completions URL of the model")
+	RootCmd.Flags().StringVar(&altTextModel, "alt-text-model", "", "With --alt-text, the vision-capable model describing the images")
+	RootCmd.Flags().BoolVar(&lock, "lock", false, "Record the commit of each repository in sherpa.lock, or in output.lock_file when set")
+	RootCmd.Flags().BoolVar(&locked, "locked", false, "Regenerate outputs at the exact commits recorded in sherpa.lock, or in output.lock_file when set")
+	RootCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop processing repositories at the first failure")
+	RootCmd.Flags().BoolVar(&failOnError, "fail-on-error", true, "Exit with an error code when repositories fail or are incomplete beyond --error-threshold")
+	RootCmd.Flags().StringVar(&errorThreshold, "error-threshold", "", "Failed or incomplete repositories tolerated before the run fails, as a count or a share (e.g. 3 or 10%, default 0)")
+	RootCmd.Flags().BoolVar(&offline, "offline", false, "Forbid network access, serving repositories from local folders and the state of previous --incremental runs")
+	RootCmd.Flags().IntVar(&writeWorkers, "write-workers", 0, "Maximum number of output files written concurrently (default 8)")
+	RootCmd.Flags().StringVar(&fsyncPolicy, "fsync", "", "Sync policy for output files: none, file or full (default none)")
+	RootCmd.Flags().IntVar(&tokenBudget, "token-budget", 0, "Pack file contents into about N tokens, outlining or stubbing less relevant files (0 = unlimited)")
+	RootCmd.Flags().StringVar(&packing, "packing", "", "Packing strategy under --token-budget: greedy or knapsack (default greedy)")
+	RootCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Cap outputs at N tokens, truncating or dropping the lowest-priority files (0 = unlimited)")
+	RootCmd.Flags().StringVar(&splitSize, "split-size", "", "Split llms-full.txt into self-contained parts below this size (e.g. 2MB)")
+	RootCmd.Flags().StringVar(&splitTokens, "split-tokens", "", "Split llms-full.txt into self-contained parts below this many tokens (e.g. 100k)")
+	RootCmd.Flags().StringVar(&fitFor, "fit-for", "", "Fit outputs for upload to a tool, splitting and capping them in the txt format: "+strings.Join(config.FitPresetNames(), ", "))
+	RootCmd.Flags().StringVar(&nameTemplate, "name-template", "", "Name of output directories, with {owner}, {name}, {repo}, {ref}, {sha} and {platform} tokens (e.g. {repo}@{ref}_{sha})")
+	RootCmd.Flags().StringVar(&userAgent, "user-agent", "", "User-Agent sent with every platform and download request")
+	RootCmd.Flags().DurationVar(&maxWait, "max-wait", 0, "Wait up to this long for an exhausted rate limit to reset instead of failing (e.g. 15m, 0 = never wait)")
+	RootCmd.Flags().StringArrayVar(&headers, "header", nil, "Extra header sent with every platform and download request (e.g. \"X-Team: platform\", repeatable)")
+	RootCmd.Flags().StringArrayVar(&goModules, "gomod", nil, "Fetch a Go module version from the module proxy (e.g. github.com/foo/bar@v1.2.3, repeatable)")
+	RootCmd.Flags().StringArrayVar(&npmPackages, "npm", nil, "Fetch a published npm package version (e.g. left-pad@1.3.0, repeatable)")
+	RootCmd.Flags().StringArrayVar(&pypiPackages, "pypi", nil, "Fetch a published PyPI package release (e.g. requests==2.31.0, repeatable)")
+	RootCmd.Flags().BoolVar(&incremental, "incremental", false, "Fetch only files changed since the commit of the previous run")
+	RootCmd.Flags().BoolVar(&review, "review", false, "Interactively choose the files to include before their content is fetched")
+	RootCmd.Flags().BoolVar(&noRepoConfig, "no-repo-config", false, "Ignore .sherpa.yml and .sherpaignore files found inside processed repositories")
 	RootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview operations without making API calls or creating files")
+	RootCmd.Flags().StringVar(&language, "lang", "", "Language for generated headings and notes (en, fr, ja)")
+	RootCmd.Flags().IntVar(&maxTreeEntries, "max-tree-entries", 0, "Fold deep subtrees into summary lines when the tree exceeds N entries (0 = unlimited)")
+	RootCmd.Flags().BoolVar(&expandTree, "expand-tree", false, "Always render the full project tree without folding")
+	RootCmd.Flags().StringVar(&outputFormat, "format", "", "Output format: txt (llms-full.txt), md (llms-full.md with a table of contents) or repomix (llms-full.xml with repomix file delimiters)")
+	RootCmd.Flags().BoolVar(&treeJSON, "tree-json", false, "Also write the project tree with sizes, tags and annotations as tree.json")
+	RootCmd.Flags().BoolVar(&deterministic, "deterministic", false, "Write byte-identical outputs for identical inputs, without timestamps or run identifiers")
+	RootCmd.Flags().BoolVar(&repoLogs, "repo-logs", false, "Write the logs of each repository to sherpa.log in its output directory")
+	RootCmd.Flags().BoolVar(&llmsTxt, "llms-txt", false, "Also write an llms.txt index linking the README and documentation, following llmstxt.org")
+	RootCmd.Flags().BoolVar(&llmsTxtLegacy, "llms-txt-legacy", false, "Write llms.txt in the former header and project tree format (implies --llms-txt)")
+	RootCmd.Flags().BoolVar(&editorRules, "editor-rules", false, "Also write .cursorrules and .continuerules pointing Cursor and Continue to the outputs and repository conventions")
+	RootCmd.Flags().BoolVar(&stripLicenses, "strip-licenses", false, "Replace the license headers repeated at the top of files with a one-line marker, keeping the first one")
+	RootCmd.Flags().StringVar(&artifacts, "artifacts", "", "Context files written: full (llms-full.txt), index (llms.txt) or both")
+	RootCmd.Flags().BoolVar(&ackSensitive, "ack-sensitive", false, "Acknowledge that outputs may include files matching sensitive patterns")
+	RootCmd.Flags().StringVar(&faultInject, "fault-inject", "", "Randomly fail API requests with timeouts, 429 and 500 responses (e.g. p=0.05,seed=42)")
+	_ = RootCmd.Flags().MarkHidden("fault-inject")
+	RootCmd.Flags().StringVar(&strategy, "strategy", models.StrategyAPI, "How repositories are fetched: api, or clone to shallow-clone them with git")
+	RootCmd.Flags().BoolVar(&debugHTTP, "debug-http", false, "Log the method, URL, status, latency and rate limit headers of every HTTP request")
+	RootCmd.Flags().StringVar(&debugHTTPFile, "debug-http-file", "", "Dump every HTTP request with its request and response bodies to this file as JSON lines")
+	RootCmd.Flags().StringVar(&recordSession, "record", "", "Record every HTTP request with its response to this session archive (e.g. session.tar)")
+	RootCmd.Flags().StringVar(&replaySession, "replay", "", "Answer HTTP requests from a session archive written with --record, without network access")
+	RootCmd.Flags().BoolVar(&includeArchived, "include-archived", false, "Also process archived repositories of organizations and groups")
+	RootCmd.Flags().BoolVar(&includeForks, "include-forks", false, "Also process forks in organizations and groups")
+	RootCmd.Flags().StringVar(&visibility, "visibility", "", "Process only public, private or internal repositories of organizations and groups")
+	RootCmd.Flags().StringArrayVar(&topics, "topic", nil, "Process only repositories of organizations and groups with this topic (repeatable, all must match)")
+	RootCmd.Flags().StringVar(&reposFile, "repos-file", "", "Read repositories from this file, one per line (blank lines and # comments are ignored)")
+	RootCmd.Flags().StringSliceVar(&selectTags, "tags", nil, "Process the repositories of the configuration file with these tags, like team:payments (comma-separated or repeatable, all must match)")
+	RootCmd.Flags().BoolVar(&siUnits, "si", false, "Print sizes in powers of 1000 (kB, MB) and token counts like 3.4k")
+	RootCmd.Flags().StringVar(&branchFlag, "branch", "", "Fetch this branch of every repository, instead of any #branch fragment (e.g. feature/foo#bar)")
+	RootCmd.Flags().StringVar(&subdirectory, "path", "", "Process only this subdirectory of each repository (e.g. services/api)")
+	RootCmd.Flags().BoolVar(&noHistory, "no-history", false, "Do not record this run in the history listed by \"sherpa history\"")
+	RootCmd.Flags().BoolVar(&fileTags, "file-tags", false, "Tag file headings with classifications like [test], [config], [generated], [doc], [entrypoint]")
 }
 
 // runFetch executes the fetch command
 func runFetch(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
+	startedAt := time.Now()
 
-	// Configure logging based on flags
+	// Configure user-facing output and logging based on flags
+	outputTheme, err := ui.LookupTheme(theme)
+	if err != nil {
+		return err
+	}
+	color := !noColor && ui.ColorEnabled(os.Stdout)
+	printer := ui.New(os.Stdout, os.Stderr, outputTheme, color)
+	logger.SetColors(!noColor && ui.ColorEnabled(os.Stderr))
 	if quiet {
 		logger.SetQuiet()
-	} else if verbose {
-		logger.SetVerbose()
+	} else {
+		logger.SetVerbosity(verbose)
 	}
 
 	logger.Logger.Info("Starting sherpa operation")
@@ -149,13 +399,82 @@ func runFetch(cmd *cobra.Command, args []string) error {
 		MaxMemoryPerFile:    maxMemoryPerFile,
 		MaxTotalMemory:      maxTotalMemory,
 		MaxFiles:            maxFiles,
-		Verbose:             verbose,
+		Verbose:             verbose > 0,
 		Quiet:               quiet,
 		DryRun:              dryRun,
+		Language:            language,
+		MaxTreeEntries:      maxTreeEntries,
+		ExpandTree:          expandTree,
+		TreeJSON:            treeJSON,
+		Deterministic:       deterministic,
+		RepoLogs:            repoLogs,
+		EditorRules:         editorRules,
+		StripLicenses:       stripLicenses,
+		LLMsTxt:             llmsTxt,
+		LLMsTxtLegacy:       llmsTxtLegacy,
+		Artifacts:           artifacts,
+		Format:              outputFormat,
+		FileTags:            fileTags,
+		AckSensitive:        ackSensitive,
+		MaxRepoSize:         maxRepoSize,
+		Match:               match,
+		MatchNeighbors:      matchNeighbors,
+		Diff:                diffRange,
+		IncludeIssues:       includeIssues,
+		IncludeReleases:     includeReleases,
+		ReleaseLimit:        releaseLimit,
+		IncludeWiki:         includeWiki,
+		AltText:             altText,
+		AltTextEndpoint:     altTextEndpoint,
+		AltTextModel:        altTextModel,
+		BudgetTime:          budgetTime,
+		Sample:              sample,
+		IssueState:          issueState,
+		IssueLabels:         issueLabels,
+		IssueLimit:          issueLimit,
+		Lock:                lock,
+		Locked:              locked,
+		FailFast:            failFast,
+		Offline:             offline,
+		WriteWorkers:        writeWorkers,
+		Fsync:               fsyncPolicy,
+		NoRepoConfig:        noRepoConfig,
+		TokenBudget:         tokenBudget,
+		MaxTokens:           maxTokens,
+		SplitSize:           splitSize,
+		SplitTokens:         splitTokens,
+		FitFor:              fitFor,
+		Packing:             packing,
+		NameTemplate:        nameTemplate,
+		Review:              review,
+		Incremental:         incremental,
+		SI:                  siUnits,
+		Path:                subdirectory,
+		Branch:              branchFlag,
+		UserAgent:           userAgent,
+		MaxWait:             maxWait,
+		Headers:             headers,
+		DebugHTTP:           debugHTTP,
+		DebugHTTPFile:       debugHTTPFile,
+		Record:              recordSession,
+		Replay:              replaySession,
+		Strategy:            strategy,
+		FaultInject:         faultInject,
+		RepositoryFilter: models.RepositoryFilter{
+			IncludeArchived: includeArchived,
+			IncludeForks:    includeForks,
+			Visibility:      strings.ToLower(visibility),
+			Topics:          topics,
+		},
 	}
 
-	// Load and configure
+	// Load and configure, from the user configuration file unless --config is set
+	if configFile == "" {
+		configFile = config.DefaultConfigFile()
+	}
+	tags := config.ParseTags(selectTags)
 	configLoader := config.NewLoader()
+	configLoader.Offline = offline
 	config, err := configLoader.LoadConfig(configFile)
 	if err != nil {
 		logger.Logger.WithError(err).Error("Failed to load configuration")
@@ -173,19 +492,256 @@ func runFetch(cmd *cobra.Command, args []string) error {
 		logger.Logger.WithError(err).Error("Configuration validation failed")
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
+	utils.SetSIUnits(config.Output.SizeUnits == models.SizeUnitsSI)
 
-	// Parse and group repositories by platform
-	reposByPlatform, err := parseRepositories(args, cliOptions.DefaultPlatform)
+	if cliOptions.Strategy != models.StrategyAPI && cliOptions.Strategy != models.StrategyClone {
+		return fmt.Errorf("invalid strategy '%s'. Valid options: api, clone", cliOptions.Strategy)
+	}
+	threshold, err := orchestration.ParseErrorThreshold(errorThreshold)
+	if err != nil {
+		return err
+	}
+	if cliOptions.Record != "" || cliOptions.Replay != "" {
+		switch {
+		case cliOptions.Record != "" && cliOptions.Replay != "":
+			return fmt.Errorf("--record and --replay cannot be combined")
+		case cliOptions.Offline:
+			return fmt.Errorf("--record and --replay cannot be combined with --offline")
+		case cliOptions.Strategy == models.StrategyClone:
+			return fmt.Errorf("--record and --replay capture API requests and cannot be used with --strategy clone")
+		}
+	}
+	switch cliOptions.RepositoryFilter.Visibility {
+	case "", "public", "private", "internal":
+	default:
+		return fmt.Errorf("invalid visibility '%s'. Valid options: public, private, internal", visibility)
+	}
+
+	// Parse and group repositories by platform, replacing aliases with their repository
+	args, err = readRepositoryArgs(args, reposFile, os.Stdin)
+	if err != nil {
+		return err
+	}
+	args = configLoader.ResolveAliases(config, args)
+	// Tagged repositories are selected again on reruns, so they are not recorded as arguments
+	repoArgs := args
+	if len(tags) > 0 {
+		tagged, err := configLoader.SelectTagged(config, tags)
+		if err != nil {
+			return err
+		}
+		repoArgs = appendMissing(args, configLoader.ResolveAliases(config, tagged))
+	}
+	reposByPlatform, err := parseRepositories(repoArgs, cliOptions.DefaultPlatform)
 	if err != nil {
 		logger.Logger.WithError(err).Error("Failed to parse repositories")
 		return fmt.Errorf("failed to parse repositories: %w", err)
 	}
+	if err := applyTags(reposByPlatform, configLoader, config, cliOptions.DefaultPlatform); err != nil {
+		return err
+	}
+	if err := parsePackages(reposByPlatform, goModules, npmPackages, pypiPackages); err != nil {
+		logger.Logger.WithError(err).Error("Failed to parse packages")
+		return fmt.Errorf("failed to parse packages: %w", err)
+	}
+	branch := cliOptions.Branch
+	if config.Processing.Diff != "" {
+		if branch != "" {
+			return fmt.Errorf("--diff and --branch cannot be combined")
+		}
+		if cliOptions.Offline {
+			return fmt.Errorf("--diff compares refs on the platform and cannot be used with --offline")
+		}
+		// Files are read at the head of the compared range
+		_, branch, _ = models.ParseDiffRange(config.Processing.Diff)
+	}
+	applyBranch(reposByPlatform, branch)
+	if err := applySubdirectory(reposByPlatform, cliOptions.Path); err != nil {
+		return err
+	}
 
 	logger.Logger.Debug("Configuration loaded and repositories parsed successfully")
 
 	// Create orchestrator and process repositories
 	orchestrator := orchestration.NewOrchestrator(config, cliOptions)
-	return orchestrator.ProcessRepositories(ctx, reposByPlatform)
+	orchestrator.SetPrinter(printer)
+	if cliOptions.FaultInject != "" {
+		injector, err := faults.Parse(cliOptions.FaultInject)
+		if err != nil {
+			return fmt.Errorf("invalid --fault-inject: %w", err)
+		}
+		orchestrator.SetFaultInjector(injector)
+	}
+	if cliOptions.DebugHTTPFile != "" {
+		dump, err := os.Create(cliOptions.DebugHTTPFile)
+		if err != nil {
+			return fmt.Errorf("failed to create HTTP debug file: %w", err)
+		}
+		defer dump.Close()
+		orchestrator.SetHTTPRecorder(httpdebug.NewDumpRecorder(dump))
+	} else if cliOptions.DebugHTTP {
+		orchestrator.SetHTTPRecorder(httpdebug.NewRecorder())
+	}
+	if cliOptions.Record != "" {
+		recorder, err := session.Create(cliOptions.Record)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				logger.Logger.WithError(err).Error("Failed to write session")
+				return
+			}
+			logger.Logger.WithField("session", cliOptions.Record).WithField("requests", recorder.Len()).Info("Recorded session")
+		}()
+		orchestrator.SetBaseTransport(recorder.Transport(nil))
+	} else if cliOptions.Replay != "" {
+		replayer, err := session.Open(cliOptions.Replay)
+		if err != nil {
+			return err
+		}
+		orchestrator.SetBaseTransport(replayer.Transport())
+	}
+	err = orchestrator.ProcessRepositories(ctx, reposByPlatform)
+	logger.FlushRepeated()
+	if err == nil {
+		// Surface repository failures through the exit code, unless they are tolerated
+		err = orchestrator.Err()
+		summary := orchestrator.Summary()
+		failed := orchestrator.FailedRepositories()
+		if err != nil && (!failOnError || !threshold.Exceeded(failed, summary.Repositories)) {
+			logger.Logger.WithError(err).WithFields(map[string]interface{}{
+				"failed":    failed,
+				"threshold": threshold.String(),
+			}).Warn("Tolerating failed repositories, exiting successfully")
+			err = nil
+		}
+	}
+
+	// Record the run so it can be listed and replayed
+	if !cliOptions.DryRun && !noHistory {
+		recordRun(cmd, args, startedAt, orchestrator, err)
+	}
+	return err
+}
+
+// requireInputs requires at least one repository argument, unless packages, a repository
+// list or tags are given through flags
+func requireInputs(cmd *cobra.Command, args []string) error {
+	if len(goModules) > 0 || len(npmPackages) > 0 || len(pypiPackages) > 0 || reposFile != "" || len(selectTags) > 0 {
+		return nil
+	}
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+// applyTags gives repositories the tags the configuration file lists them with, whether they
+// were selected with --tags or named otherwise. Tags apply to every branch of a repository.
+func applyTags(reposByPlatform map[models.Platform][]*models.RepositoryInfo, loader *config.Loader, cfg *models.Config, defaultPlatform string) error {
+	if len(cfg.Repos) == 0 {
+		return nil
+	}
+	tagsByRepo := make(map[string][]string)
+	for _, repo := range cfg.Repos {
+		parsed, err := parseRepositories(loader.ResolveAliases(cfg, []string{repo.Repo}), defaultPlatform)
+		if err != nil {
+			return fmt.Errorf("invalid repository in the configuration file: %w", err)
+		}
+		for platform, repoInfos := range parsed {
+			for _, repoInfo := range repoInfos {
+				key := string(platform) + ":" + repoInfo.FullName
+				for _, tag := range repo.Tags {
+					if !slices.Contains(tagsByRepo[key], tag) {
+						tagsByRepo[key] = append(tagsByRepo[key], tag)
+					}
+				}
+			}
+		}
+	}
+
+	for platform, repoInfos := range reposByPlatform {
+		for _, repoInfo := range repoInfos {
+			repoInfo.Tags = tagsByRepo[string(platform)+":"+repoInfo.FullName]
+		}
+	}
+	return nil
+}
+
+// appendMissing appends the values missing from args, so repositories named twice are
+// processed once
+func appendMissing(args, values []string) []string {
+	result := slices.Clone(args)
+	for _, value := range values {
+		if !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// applyBranch targets the branch given with --branch for every repository, organization,
+// group and git remote, replacing the branch of their fragment. URLs whose fragment is not
+// a branch, like anchors copied from the browser, are fetched as intended this way
+func applyBranch(reposByPlatform map[models.Platform][]*models.RepositoryInfo, branch string) {
+	if branch == "" {
+		return
+	}
+	for platform, repoInfos := range reposByPlatform {
+		for _, repoInfo := range repoInfos {
+			// Local folders are read as they are, without branches
+			if repoInfo.Kind == "" && platform == models.PlatformLocal {
+				continue
+			}
+			switch repoInfo.Kind {
+			case "", models.KindGroup, models.KindGitClone:
+				repoInfo.Branch = branch
+			}
+		}
+	}
+}
+
+// applySubdirectory restricts the inputs that name no path of their own, like
+// owner/repo#main:services/api, to the subdirectory given with --path
+func applySubdirectory(reposByPlatform map[models.Platform][]*models.RepositoryInfo, subdir string) error {
+	if subdir == "" {
+		return nil
+	}
+	subdir, err := adapters.CleanSubdirectory(subdir)
+	if err != nil {
+		return fmt.Errorf("invalid --path: %w", err)
+	}
+
+	for _, repoInfos := range reposByPlatform {
+		for _, repoInfo := range repoInfos {
+			if repoInfo.Subdirectory == "" {
+				repoInfo.Subdirectory = subdir
+			}
+		}
+	}
+	return nil
+}
+
+// parsePackages parses the packages given through flags and adds them to the local
+// inputs, since they are downloaded and processed like local folders
+func parsePackages(reposByPlatform map[models.Platform][]*models.RepositoryInfo, goModules, npmPackages, pypiPackages []string) error {
+	sources := []struct {
+		specs []string
+		parse func(spec string) (*models.RepositoryInfo, error)
+	}{
+		{specs: goModules, parse: adapters.ParseGoModule},
+		{specs: npmPackages, parse: adapters.ParseNPMPackage},
+		{specs: pypiPackages, parse: adapters.ParsePyPIPackage},
+	}
+
+	for _, source := range sources {
+		for _, spec := range source.specs {
+			repoInfo, err := source.parse(spec)
+			if err != nil {
+				return err
+			}
+			reposByPlatform[models.PlatformLocal] = append(reposByPlatform[models.PlatformLocal], repoInfo)
+		}
+	}
+	return nil
 }
 
 // parseRepositories parses repository arguments and groups them by platform
@@ -199,11 +755,18 @@ func parseRepositories(args []string, defaultPlatformFlag string) (map[models.Pl
 		defaultPlatformEnum = models.PlatformGitHub
 	case "gitlab":
 		defaultPlatformEnum = models.PlatformGitLab
+	case "gitea":
+		defaultPlatformEnum = models.PlatformGitea
 	case "":
 		// No default platform specified, use existing logic
 		defaultPlatformEnum = ""
 	default:
-		return nil, fmt.Errorf("invalid default platform '%s'. Valid options: github, gitlab", defaultPlatformFlag)
+		return nil, fmt.Errorf("invalid default platform '%s'. Valid options: github, gitlab, gitea", defaultPlatformFlag)
+	}
+
+	args, err := expandLocalGlobs(args)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, arg := range args {
@@ -217,3 +780,51 @@ func parseRepositories(args []string, defaultPlatformFlag string) (map[models.Pl
 
 	return reposByPlatform, nil
 }
+
+// expandLocalGlobs expands folder patterns like ./services/* into one argument per
+// matching folder, for shells that do not expand them (e.g. on Windows) or quoted patterns
+func expandLocalGlobs(args []string) ([]string, error) {
+	var expanded []string
+	for _, arg := range args {
+		pattern, branch, _ := strings.Cut(arg, "#")
+		if !strings.ContainsAny(pattern, "*?[") || strings.Contains(pattern, "://") || strings.HasPrefix(pattern, "git@") {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		// Quoted patterns like "~/work/*" reach us without the shell expanding the tilde
+		home, err := utils.ExpandHome(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(home)
+		if err != nil {
+			return nil, fmt.Errorf("invalid folder pattern '%s': %w", pattern, err)
+		}
+
+		folders := 0
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				continue
+			}
+			// Keep the path local so folders like "api" are not parsed as repository names
+			if !filepath.IsAbs(match) {
+				match = "." + string(filepath.Separator) + match
+			}
+			if branch != "" {
+				match += "#" + branch
+			}
+			expanded = append(expanded, match)
+			folders++
+		}
+		if folders == 0 && adapters.IsGroupPattern(pattern) {
+			// group/subgroup/* lists a GitLab group when no local folder matches
+			expanded = append(expanded, arg)
+			continue
+		}
+		if folders == 0 {
+			return nil, fmt.Errorf("folder pattern '%s' matched no folders", pattern)
+		}
+	}
+	return expanded, nil
+}
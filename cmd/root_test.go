@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"sherpa/internal/config"
 	"sherpa/internal/orchestration"
 	"sherpa/pkg/models"
 
@@ -36,6 +39,14 @@ func TestRootCmd(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("should accept no args with Go modules", func(t *testing.T) {
+		goModules = []string{"github.com/foo/bar@v1.2.3"}
+		defer func() { goModules = nil }()
+
+		err := RootCmd.Args(&cobra.Command{}, []string{})
+		assert.NoError(t, err)
+	})
+
 	t.Run("should accept valid args", func(t *testing.T) {
 		cmd := &cobra.Command{}
 		cmd.SetArgs([]string{"owner/repo"})
@@ -81,6 +92,13 @@ func TestParseRepositories(t *testing.T) {
 			expectedCount:   1,
 			expectedError:   false,
 		},
+		{
+			name:            "should use gitea as default platform",
+			args:            []string{"owner/repo"},
+			defaultPlatform: "gitea",
+			expectedCount:   1,
+			expectedError:   false,
+		},
 		{
 			name:            "should error on invalid default platform",
 			args:            []string{"owner/repo"},
@@ -110,6 +128,185 @@ func TestParseRepositories(t *testing.T) {
 	}
 }
 
+func TestParsePackages(t *testing.T) {
+	t.Run("should add Go modules to the local inputs", func(t *testing.T) {
+		reposByPlatform := map[models.Platform][]*models.RepositoryInfo{}
+
+		err := parsePackages(reposByPlatform, []string{"github.com/foo/bar@v1.2.3", "golang.org/x/mod"}, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, reposByPlatform[models.PlatformLocal], 2)
+		assert.Equal(t, models.KindGoModule, reposByPlatform[models.PlatformLocal][0].Kind)
+		assert.Equal(t, "golang.org/x/mod@latest", reposByPlatform[models.PlatformLocal][1].FullName)
+	})
+
+	t.Run("should add npm and PyPI packages to the local inputs", func(t *testing.T) {
+		reposByPlatform := map[models.Platform][]*models.RepositoryInfo{}
+
+		err := parsePackages(reposByPlatform, nil, []string{"left-pad@1.3.0"}, []string{"requests==2.31.0"})
+		require.NoError(t, err)
+		require.Len(t, reposByPlatform[models.PlatformLocal], 2)
+		assert.Equal(t, models.KindNPMPackage, reposByPlatform[models.PlatformLocal][0].Kind)
+		assert.Equal(t, models.KindPyPIPackage, reposByPlatform[models.PlatformLocal][1].Kind)
+	})
+
+	t.Run("should error on invalid packages", func(t *testing.T) {
+		err := parsePackages(map[models.Platform][]*models.RepositoryInfo{}, []string{"@v1.0.0"}, nil, nil)
+		assert.Error(t, err)
+
+		err = parsePackages(map[models.Platform][]*models.RepositoryInfo{}, nil, nil, []string{"requests>=2"})
+		assert.Error(t, err)
+	})
+}
+
+func TestApplySubdirectory(t *testing.T) {
+	t.Run("should scope inputs without a path of their own", func(t *testing.T) {
+		reposByPlatform, err := parseRepositories([]string{"owner/api", "owner/web#main:frontend"}, "github")
+		require.NoError(t, err)
+
+		require.NoError(t, applySubdirectory(reposByPlatform, "./services/api/"))
+		repos := reposByPlatform[models.PlatformGitHub]
+		require.Len(t, repos, 2)
+		assert.Equal(t, "services/api", repos[0].Subdirectory)
+		assert.Equal(t, "frontend", repos[1].Subdirectory)
+	})
+
+	t.Run("should reject paths leaving the repository", func(t *testing.T) {
+		err := applySubdirectory(map[models.Platform][]*models.RepositoryInfo{}, "../secrets")
+		assert.ErrorContains(t, err, "invalid --path")
+	})
+}
+
+func TestApplyBranch(t *testing.T) {
+	t.Run("should replace the branch of repositories and groups", func(t *testing.T) {
+		reposByPlatform, err := parseRepositories([]string{"owner/api", "https://github.com/owner/web#readme", "org:owner"}, "github")
+		require.NoError(t, err)
+
+		applyBranch(reposByPlatform, "feature/foo#bar")
+		for _, repoInfo := range reposByPlatform[models.PlatformGitHub] {
+			assert.Equal(t, "feature/foo#bar", repoInfo.Branch, repoInfo.FullName)
+		}
+	})
+
+	t.Run("should leave local folders and downloads without a branch", func(t *testing.T) {
+		reposByPlatform, err := parseRepositories([]string{t.TempDir(), "https://example.com/release.tar.gz"}, "github")
+		require.NoError(t, err)
+
+		applyBranch(reposByPlatform, "main")
+		for _, repoInfo := range reposByPlatform[models.PlatformLocal] {
+			assert.Empty(t, repoInfo.Branch, repoInfo.FullName)
+		}
+	})
+}
+
+func TestApplyTags(t *testing.T) {
+	cfg := &models.Config{
+		Aliases: map[string]string{"pay": "https://gitlab.com/org/payments/backend#main"},
+		Repos: []models.TaggedRepo{
+			{Repo: "owner/api", Tags: []string{"team:payments", "tier:critical"}},
+			{Repo: "https://github.com/owner/api#v2", Tags: []string{"team:platform"}},
+			{Repo: "pay", Tags: []string{"team:payments"}},
+		},
+	}
+
+	t.Run("should tag the repositories of the configuration on every branch", func(t *testing.T) {
+		reposByPlatform, err := parseRepositories([]string{"owner/api#develop", "https://gitlab.com/org/payments/backend", "owner/web"}, "github")
+		require.NoError(t, err)
+
+		require.NoError(t, applyTags(reposByPlatform, config.NewLoader(), cfg, "github"))
+		github := reposByPlatform[models.PlatformGitHub]
+		require.Len(t, github, 2)
+		assert.Equal(t, []string{"team:payments", "tier:critical", "team:platform"}, github[0].Tags)
+		assert.Empty(t, github[1].Tags)
+		assert.Equal(t, []string{"team:payments"}, reposByPlatform[models.PlatformGitLab][0].Tags)
+	})
+
+	t.Run("should fail on invalid repositories", func(t *testing.T) {
+		invalid := &models.Config{Repos: []models.TaggedRepo{{Repo: "https://example.com/", Tags: []string{"team:web"}}}}
+		assert.Error(t, applyTags(map[models.Platform][]*models.RepositoryInfo{}, config.NewLoader(), invalid, ""))
+	})
+}
+
+func TestAppendMissing(t *testing.T) {
+	args := []string{"owner/api", "owner/web"}
+	assert.Equal(t, []string{"owner/api", "owner/web", "owner/docs"}, appendMissing(args, []string{"owner/web", "owner/docs"}))
+	assert.Equal(t, []string{"owner/api", "owner/web"}, args)
+}
+
+func TestExpandLocalGlobs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"services/auth", "services/payment"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, "services", "README.md"), []byte("# Services"), 0644))
+
+	tests := []struct {
+		name          string
+		args          []string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name:     "should keep arguments without patterns",
+			args:     []string{"owner/repo", "https://github.com/owner/repo"},
+			expected: []string{"owner/repo", "https://github.com/owner/repo"},
+		},
+		{
+			name: "should expand a pattern into matching folders only",
+			args: []string{filepath.Join(root, "services", "*")},
+			expected: []string{
+				filepath.Join(root, "services", "auth"),
+				filepath.Join(root, "services", "payment"),
+			},
+		},
+		{
+			name: "should keep the branch fragment on each folder",
+			args: []string{filepath.Join(root, "services", "p*") + "#develop"},
+			expected: []string{
+				filepath.Join(root, "services", "payment") + "#develop",
+			},
+		},
+		{
+			name:          "should error when no folder matches",
+			args:          []string{filepath.Join(root, "missing", "*")},
+			expectedError: true,
+		},
+		{
+			name:     "should keep group patterns matching no folder",
+			args:     []string{"gitlab-group/subgroup/*", "org:my-org"},
+			expected: []string{"gitlab-group/subgroup/*", "org:my-org"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := expandLocalGlobs(tt.args)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("should parse each matching folder as its own local repository", func(t *testing.T) {
+		result, err := parseRepositories([]string{filepath.Join(root, "services", "*")}, "")
+		require.NoError(t, err)
+		require.Len(t, result[models.PlatformLocal], 2)
+		assert.Equal(t, "auth", result[models.PlatformLocal][0].Name)
+		assert.Equal(t, "payment", result[models.PlatformLocal][1].Name)
+	})
+
+	t.Run("should expand the home directory of quoted patterns", func(t *testing.T) {
+		t.Setenv("HOME", root)
+		t.Setenv("USERPROFILE", root)
+
+		result, err := expandLocalGlobs([]string{"~/services/a*"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(root, "services", "auth")}, result)
+	})
+}
+
 func TestGetTokenForPlatform(t *testing.T) {
 	config := &models.Config{
 		GitLab: models.GitLabConfig{
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"sherpa/internal/config"
+	"sherpa/internal/rpc"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// rpc flags
+	rpcConfigFile string
+)
+
+// rpcCmd serves editor extensions over standard input and output
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Serve editor extensions over JSON-RPC on standard input and output",
+	Long: `Rpc answers JSON-RPC 2.0 requests of an editor extension, one JSON message per
+line on standard input, with responses on standard output. Logs are written to the
+standard error. Requests are handled one at a time:
+
+  initialize     name, version and methods of the server
+  profiles/list  profiles to generate with: default and the --fit-for presets
+  generate       generate outputs for a folder: {"path", "profile", "output"}
+  shutdown       stop serving
+
+While generate runs, "progress" notifications report the stages reached
+(fetching, writing, done, failed). Its result lists the output files written,
+by default to sherpa-output in the folder, which is then ignored.
+
+  echo '{"jsonrpc":"2.0","id":1,"method":"generate","params":{"path":"."}}' | sherpa rpc`,
+	Args: cobra.NoArgs,
+	RunE: runRPC,
+}
+
+func init() {
+	rpcCmd.Flags().StringVarP(&rpcConfigFile, "config", "c", "", "Configuration file path (default: sherpa/config.yml in the user config directory, when it exists)")
+	RootCmd.AddCommand(rpcCmd)
+}
+
+// runRPC executes the rpc command
+func runRPC(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if rpcConfigFile == "" {
+		rpcConfigFile = config.DefaultConfigFile()
+	}
+	server := &rpc.Server{ConfigFile: rpcConfigFile, Version: Version}
+	return server.Serve(ctx, os.Stdin, cmd.OutOrStdout())
+}
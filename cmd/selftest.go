@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sherpa/internal/config"
+	"sherpa/internal/fakevcs"
+	"sherpa/internal/orchestration"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// selftest flags
+	selftestFixtures string
+	selftestOutput   string
+	selftestVerbose  int
+)
+
+// selftestCmd runs the full pipeline against a fake GitHub/GitLab/Gitea API
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run the full pipeline against a built-in fake GitHub/GitLab/Gitea API",
+	Long: `Selftest starts a local fake GitHub, GitLab and Gitea API serving fixture repositories,
+processes every fixture on each platform and checks the generated outputs.
+
+No network access or real token is required, which makes it suitable for
+validating provider behavior changes.
+
+  # Use the built-in fixtures
+  sherpa selftest
+
+  # Serve your own fixtures, laid out as <owner>/<repo>/<files>
+  sherpa selftest --fixtures ./testdata/fixtures --output ./selftest-output`,
+	Args: cobra.NoArgs,
+	RunE: runSelftest,
+}
+
+func init() {
+	selftestCmd.Flags().StringVar(&selftestFixtures, "fixtures", "", "Directory of fixture repositories laid out as <owner>/<repo>/<files> (default: built-in fixtures)")
+	selftestCmd.Flags().StringVarP(&selftestOutput, "output", "o", "", "Keep generated outputs in this directory (default: temporary directory, removed afterwards)")
+	selftestCmd.Flags().CountVarP(&selftestVerbose, "verbose", "v", "Increase log verbosity: -v info, -vv debug, -vvv trace with a line per fetched file")
+	RootCmd.AddCommand(selftestCmd)
+}
+
+// runSelftest executes the selftest command
+func runSelftest(cmd *cobra.Command, args []string) error {
+	if selftestVerbose > 0 {
+		logger.SetVerbosity(selftestVerbose)
+	} else {
+		logger.SetQuiet()
+	}
+
+	fixtures := fakevcs.DefaultFixtures()
+	if selftestFixtures != "" {
+		loaded, err := fakevcs.LoadFixtures(selftestFixtures)
+		if err != nil {
+			return err
+		}
+		fixtures = loaded
+	}
+
+	outputRoot := selftestOutput
+	if outputRoot == "" {
+		tempDir, err := os.MkdirTemp("", "sherpa-selftest-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary output directory: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+		outputRoot = tempDir
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	failures, err := selftest(ctx, fixtures, outputRoot, cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+	if failures > 0 {
+		return fmt.Errorf("selftest failed: %d check(s) failed", failures)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), "Selftest passed")
+	return nil
+}
+
+// selftest serves fixtures from a fake API, processes them on every platform and
+// reports each check to out. It returns the number of failed checks.
+func selftest(ctx context.Context, fixtures *fakevcs.Fixtures, outputRoot string, out io.Writer) (int, error) {
+	server := fakevcs.NewServer(fixtures)
+	defer server.Close()
+
+	failures := 0
+	for _, platform := range []models.Platform{models.PlatformGitHub, models.PlatformGitLab, models.PlatformGitea} {
+		platformFailures := 0
+		requestsBefore := server.Requests()
+		platformDir := filepath.Join(outputRoot, string(platform))
+
+		cfg, err := selftestConfig(server, platformDir)
+		if err != nil {
+			return 0, err
+		}
+
+		var repos []*models.RepositoryInfo
+		for _, repo := range fixtures.Repositories {
+			owner, name, _ := strings.Cut(repo.Path, "/")
+			repos = append(repos, &models.RepositoryInfo{
+				Platform: platform,
+				Owner:    owner,
+				Name:     name,
+				FullName: repo.Path,
+			})
+		}
+
+		cliOptions := &models.CLIOptions{Token: fakevcs.Token, Quiet: selftestVerbose == 0}
+		orchestrator := orchestration.NewOrchestrator(cfg, cliOptions)
+		if err := orchestrator.ProcessRepositories(ctx, map[models.Platform][]*models.RepositoryInfo{platform: repos}); err != nil {
+			fmt.Fprintf(out, "FAIL %s: %v\n", platform, err)
+			failures++
+			continue
+		}
+
+		if err := orchestrator.Err(); err != nil {
+			fmt.Fprintf(out, "FAIL %s: %v\n", platform, err)
+			platformFailures++
+		}
+
+		lock, lockErr := orchestration.LoadLockFile(cfg.Output.LockFile)
+		for _, repo := range fixtures.Repositories {
+			for _, problem := range checkSelftestOutput(platformDir, repo, platform, lock, lockErr) {
+				fmt.Fprintf(out, "FAIL %s %s: %s\n", platform, repo.Path, problem)
+				platformFailures++
+			}
+		}
+		failures += platformFailures
+		if platformFailures == 0 {
+			fmt.Fprintf(out, "PASS %s (%d repositories, %d API requests)\n", platform, len(fixtures.Repositories), server.Requests()-requestsBefore)
+		}
+	}
+
+	return failures, nil
+}
+
+// selftestConfig returns the default configuration pointed at the fake server
+func selftestConfig(server *fakevcs.Server, outputDir string) (*models.Config, error) {
+	cfg, err := config.NewLoader().LoadConfig("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	cfg.GitHub.BaseURL = server.GitHubURL()
+	cfg.GitLab.BaseURL = server.GitLabURL()
+	cfg.Gitea.BaseURL = server.GiteaURL()
+	cfg.Output.Directory = outputDir
+	cfg.Output.OrganizeByDate = false
+	cfg.Output.LockFile = filepath.Join(outputDir, "sherpa.lock")
+	cfg.Cache.Enabled = false
+	return cfg, nil
+}
+
+// checkSelftestOutput lists the problems found in the output generated for a fixture repository
+func checkSelftestOutput(outputDir string, repo fakevcs.Repository, platform models.Platform, lock *orchestration.LockFile, lockErr error) []string {
+	var problems []string
+
+	outputPath := filepath.Join(outputDir, utils.SanitizeRepoName(repo.Path), "llms-full.txt")
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		return []string{fmt.Sprintf("missing output: %v", err)}
+	}
+
+	output := string(content)
+	if !strings.Contains(output, repo.Path) {
+		problems = append(problems, "output does not mention the repository")
+	}
+	for _, path := range repo.Expect {
+		if !strings.Contains(output, path) {
+			problems = append(problems, fmt.Sprintf("output is missing %s", path))
+		}
+	}
+
+	if lockErr != nil {
+		problems = append(problems, fmt.Sprintf("lock file not written: %v", lockErr))
+	} else if entry, ok := lock.Lookup(platform, repo.Path, ""); !ok {
+		problems = append(problems, "repository missing from lock file")
+	} else if entry.Commit != repo.Commit() {
+		problems = append(problems, fmt.Sprintf("lock file commit %s, expected %s", entry.Commit, repo.Commit()))
+	}
+
+	return problems
+}
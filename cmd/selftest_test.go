@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/internal/fakevcs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelftest(t *testing.T) {
+	t.Run("should pass with the built-in fixtures", func(t *testing.T) {
+		outputRoot := t.TempDir()
+		var out bytes.Buffer
+
+		failures, err := selftest(context.Background(), fakevcs.DefaultFixtures(), outputRoot, &out)
+		require.NoError(t, err)
+		assert.Equal(t, 0, failures, out.String())
+		assert.Contains(t, out.String(), "PASS github")
+		assert.Contains(t, out.String(), "PASS gitlab")
+		assert.Contains(t, out.String(), "PASS gitea")
+
+		assert.FileExists(t, filepath.Join(outputRoot, "github", "sherpa-fixtures_hello", "llms-full.txt"))
+		assert.FileExists(t, filepath.Join(outputRoot, "gitlab", "sherpa.lock"))
+	})
+
+	t.Run("should report missing expected files", func(t *testing.T) {
+		fixtures := fakevcs.DefaultFixtures()
+		fixtures.Repositories[0].Expect = append(fixtures.Repositories[0].Expect, "does/not/exist.go")
+		var out bytes.Buffer
+
+		failures, err := selftest(context.Background(), fixtures, t.TempDir(), &out)
+		require.NoError(t, err)
+		assert.Equal(t, 3, failures) // One per platform
+		assert.Contains(t, out.String(), "output is missing does/not/exist.go")
+	})
+}
+
+func TestCheckSelftestOutput(t *testing.T) {
+	t.Run("should report a missing output file", func(t *testing.T) {
+		repo := fakevcs.DefaultFixtures().Repositories[0]
+
+		problems := checkSelftestOutput(t.TempDir(), repo, "github", nil, os.ErrNotExist)
+		require.Len(t, problems, 1)
+		assert.Contains(t, problems[0], "missing output")
+	})
+}
@@ -0,0 +1,112 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sherpa/internal/adapters/gitclone"
+	"sherpa/pkg/models"
+)
+
+// gitRemotePrefixes are the prefixes of inputs cloned with git rather than read through an API.
+// git+ prefixes force cloning of HTTP(S) and SSH remotes we have no adapter for.
+var gitRemotePrefixes = []string{"git://", "ssh://", "git+https://", "git+http://", "git+ssh://"}
+
+// GitCloneProvider serves a shallow clone from the folder it was cloned to, reporting the
+// remote instead of the temporary folder and pinning outputs to the cloned commit
+type GitCloneProvider struct {
+	*DownloadProvider
+	commit string
+}
+
+// ResolveCommit returns the cloned commit, which is the only one available
+func (p *GitCloneProvider) ResolveCommit(ctx context.Context, repoPath, ref string) (string, error) {
+	return p.commit, nil
+}
+
+// CreateGitCloneProvider shallow-clones a repository into parentDir and creates a provider
+// serving it. The returned directory must be removed once processing is done.
+func CreateGitCloneProvider(ctx context.Context, options gitclone.Options, name, webURL, parentDir string) (Provider, string, error) {
+	folderPath, commit, err := gitclone.Clone(ctx, options, parentDir)
+	if err != nil {
+		return nil, "", err
+	}
+	provider, err := newDownloadProvider(folderPath, name, webURL)
+	if err != nil {
+		os.RemoveAll(filepath.Dir(folderPath))
+		return nil, "", err
+	}
+	return &GitCloneProvider{DownloadProvider: provider, commit: commit}, filepath.Dir(folderPath), nil
+}
+
+// isGitRemote reports whether an input is a git remote to clone, like git://host/repo.git
+func isGitRemote(input string) bool {
+	for _, prefix := range gitRemotePrefixes {
+		if strings.HasPrefix(input, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGitRemote describes a git remote. Clones need no token, so they are processed with
+// the local folders.
+func parseGitRemote(input string) *models.RepositoryInfo {
+	remote := strings.TrimPrefix(input, "git+")
+	owner := ""
+	if u, err := url.Parse(remote); err == nil {
+		owner = u.Hostname()
+	}
+	return &models.RepositoryInfo{
+		Platform: models.PlatformLocal,
+		Owner:    owner,
+		Name:     gitclone.Name(remote),
+		FullName: remote,
+		URL:      remote,
+		Kind:     models.KindGitClone,
+	}
+}
+
+// CloneURL returns the git remote of a repository, derived from its URL when one was given
+// or from the base URL of its platform
+func CloneURL(repoInfo *models.RepositoryInfo, config *models.Config) (string, error) {
+	if repoInfo.Kind == models.KindGitClone || strings.HasPrefix(repoInfo.URL, "git@") {
+		return repoInfo.URL, nil
+	}
+
+	base := ""
+	if repoInfo.URL != "" {
+		u, err := url.Parse(repoInfo.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid repository URL: %w", err)
+		}
+		base = u.Scheme + "://" + u.Host
+	} else {
+		switch repoInfo.Platform {
+		case models.PlatformGitHub:
+			base = strings.TrimSuffix(strings.TrimRight(config.GitHub.BaseURL, "/"), "/api/v3")
+			if base == "https://api.github.com" {
+				base = "https://github.com"
+			}
+		case models.PlatformGitLab:
+			base = strings.TrimSuffix(strings.TrimRight(config.GitLab.BaseURL, "/"), "/api/v4")
+		case models.PlatformGitea:
+			base = strings.TrimSuffix(strings.TrimRight(config.Gitea.BaseURL, "/"), "/api/v1")
+		default:
+			return "", fmt.Errorf("cannot clone %s repositories", repoInfo.Platform)
+		}
+	}
+	return base + "/" + repoInfo.FullName + ".git", nil
+}
+
+// CloneUsername returns the username sent with a token when cloning over HTTPS
+func CloneUsername(platform models.Platform) string {
+	if platform == models.PlatformGitHub {
+		return "x-access-token"
+	}
+	return "oauth2"
+}
@@ -0,0 +1,91 @@
+package adapters
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepositoryURL_GitRemotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *models.RepositoryInfo
+	}{
+		{
+			name:  "should parse git protocol remotes",
+			input: "git://git.example.com/team/tool.git",
+			expected: &models.RepositoryInfo{
+				Platform: models.PlatformLocal, Owner: "git.example.com", Name: "tool",
+				FullName: "git://git.example.com/team/tool.git", URL: "git://git.example.com/team/tool.git", Kind: models.KindGitClone,
+			},
+		},
+		{
+			name:  "should strip the git+ prefix and keep the branch",
+			input: "git+https://git.example.com/team/tool.git#develop",
+			expected: &models.RepositoryInfo{
+				Platform: models.PlatformLocal, Owner: "git.example.com", Name: "tool",
+				FullName: "https://git.example.com/team/tool.git", URL: "https://git.example.com/team/tool.git", Branch: "develop", Kind: models.KindGitClone,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoInfo, err := ParseRepositoryURL(tt.input, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, repoInfo)
+		})
+	}
+}
+
+func TestCloneURL(t *testing.T) {
+	config := &models.Config{
+		GitHub: models.GitHubConfig{BaseURL: "https://api.github.com"},
+		GitLab: models.GitLabConfig{BaseURL: "https://gitlab.company.com/api/v4"},
+		Gitea:  models.GiteaConfig{BaseURL: "https://codeberg.org"},
+	}
+
+	tests := []struct {
+		name     string
+		repoInfo *models.RepositoryInfo
+		expected string
+	}{
+		{
+			name:     "should derive the remote from the repository URL",
+			repoInfo: &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/repo", URL: "https://github.com/owner/repo/tree/main"},
+			expected: "https://github.com/owner/repo.git",
+		},
+		{
+			name:     "should keep SSH remotes",
+			repoInfo: &models.RepositoryInfo{Platform: models.PlatformGitLab, FullName: "group/project", URL: "git@gitlab.com:group/project.git"},
+			expected: "git@gitlab.com:group/project.git",
+		},
+		{
+			name:     "should use the GitHub web host for the public API",
+			repoInfo: &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/repo"},
+			expected: "https://github.com/owner/repo.git",
+		},
+		{
+			name:     "should strip API paths from base URLs",
+			repoInfo: &models.RepositoryInfo{Platform: models.PlatformGitLab, FullName: "group/sub/project"},
+			expected: "https://gitlab.company.com/group/sub/project.git",
+		},
+		{
+			name:     "should use the Gitea base URL",
+			repoInfo: &models.RepositoryInfo{Platform: models.PlatformGitea, FullName: "owner/repo"},
+			expected: "https://codeberg.org/owner/repo.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote, err := CloneURL(tt.repoInfo, config)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, remote)
+		})
+	}
+}
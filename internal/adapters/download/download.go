@@ -0,0 +1,287 @@
+// Package download fetches archives and raw files over HTTP(S) into a local folder so they
+// can be processed like any other local project.
+package download
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/logger"
+)
+
+// MaxSize caps both the downloaded and the extracted size of an input
+const MaxSize int64 = 512 * 1024 * 1024
+
+// archiveExtensions lists the supported archive extensions, longest first
+var archiveExtensions = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+// ArchiveExtension returns the archive extension of a URL path, or an empty string when it
+// does not name a supported archive
+func ArchiveExtension(urlPath string) string {
+	lower := strings.ToLower(urlPath)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// Name returns the project name of a download URL: the file name without its archive extension
+func Name(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	name := path.Base(u.Path)
+	if ext := ArchiveExtension(name); ext != "" {
+		name = name[:len(name)-len(ext)]
+	}
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return u.Hostname()
+	}
+	return name
+}
+
+// Fetch downloads rawURL into a new directory below parentDir and returns the folder to
+// process. Archives are extracted, dropping the single top-level directory most source
+// archives wrap their files in; any other file is stored alone under its own name. The
+// folder is created inside its own temporary directory, which callers remove when done.
+func Fetch(ctx context.Context, client *http.Client, rawURL, parentDir string) (string, error) {
+	logger.Logger.WithField("url", rawURL).Info("Downloading input")
+
+	data, err := get(ctx, client, rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	u, _ := url.Parse(rawURL)
+	return unpack(parentDir, Name(rawURL), rawURL, func(root string) error {
+		ext := ArchiveExtension(u.Path)
+		if ext == "" {
+			return os.WriteFile(filepath.Join(root, Name(rawURL)), data, 0644)
+		}
+		if err := extractArchive(data, ext, root); err != nil {
+			return err
+		}
+		return unwrapSingleDir(root)
+	})
+}
+
+// extractArchive extracts an archive with the given extension below root
+func extractArchive(data []byte, ext, root string) error {
+	switch ext {
+	case ".tar.gz", ".tgz":
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to read gzip archive: %w", err)
+		}
+		return extractTar(gz, root)
+	case ".tar":
+		return extractTar(bytes.NewReader(data), root)
+	case ".zip":
+		return extractZip(data, root, "")
+	default:
+		return fmt.Errorf("unsupported archive format %s", ext)
+	}
+}
+
+// unpack creates a folder called name inside a new temporary directory below parentDir,
+// named after the input so the local provider reports a meaningful name, and fills it
+// with extract. source names the input in errors.
+func unpack(parentDir, name, source string, extract func(root string) error) (string, error) {
+	dir, err := os.MkdirTemp(parentDir, "sherpa-download-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	root := filepath.Join(dir, name)
+	if err := os.Mkdir(root, 0755); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	if err := extract(root); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to unpack %s: %w", source, err)
+	}
+	return root, nil
+}
+
+// get downloads a URL, refusing bodies larger than MaxSize
+func get(ctx context.Context, client *http.Client, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, sherpaerrors.FromStatus(resp.StatusCode, fmt.Errorf("failed to download %s: %s", rawURL, resp.Status))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if int64(len(data)) > MaxSize {
+		return nil, sherpaerrors.New(sherpaerrors.KindTooLarge, fmt.Sprintf("download %s exceeds %d bytes", rawURL, MaxSize))
+	}
+	return data, nil
+}
+
+// extractTar writes the regular files and directories of a tar stream below root.
+// Links and other special entries are skipped.
+func extractTar(r io.Reader, root string) error {
+	tr := tar.NewReader(r)
+	var total int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			target, err := entryPath(root, header.Name)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += header.Size
+			if total > MaxSize {
+				return sherpaerrors.New(sherpaerrors.KindTooLarge, fmt.Sprintf("archive content exceeds %d bytes", MaxSize))
+			}
+			if err := writeEntry(root, header.Name, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// extractZip writes the files and directories of a zip archive below root, removing prefix
+// from entry names. Entries outside prefix are rejected.
+func extractZip(data []byte, root, prefix string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, file := range zr.File {
+		name, found := strings.CutPrefix(file.Name, prefix)
+		if !found {
+			return fmt.Errorf("archive entry %s is outside %s", file.Name, prefix)
+		}
+
+		mode := file.Mode()
+		if mode.IsDir() {
+			target, err := entryPath(root, name)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			continue
+		}
+
+		total += int64(file.UncompressedSize64)
+		if total > MaxSize {
+			return sherpaerrors.New(sherpaerrors.KindTooLarge, fmt.Sprintf("archive content exceeds %d bytes", MaxSize))
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeEntry(root, name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEntry writes an archive file below root
+func writeEntry(root, name string, r io.Reader) error {
+	target, err := entryPath(root, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	// The declared size is not trusted, so copying stops at MaxSize as well
+	if _, err := io.Copy(f, io.LimitReader(r, MaxSize)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// entryPath resolves an archive entry name below root, rejecting names escaping it
+func entryPath(root, name string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("archive entry %s escapes the extraction directory", name)
+	}
+	return filepath.Join(root, filepath.FromSlash(cleaned)), nil
+}
+
+// unwrapSingleDir moves the content of a lone top-level directory up into root
+func unwrapSingleDir(root string) error {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return fmt.Errorf("failed to read download directory: %w", err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return nil
+	}
+
+	// Move the wrapper aside first, since it may contain an entry with its own name
+	wrapper := filepath.Join(filepath.Dir(root), ".unwrap")
+	if err := os.Rename(filepath.Join(root, entries[0].Name()), wrapper); err != nil {
+		return fmt.Errorf("failed to move %s: %w", entries[0].Name(), err)
+	}
+	children, err := os.ReadDir(wrapper)
+	if err != nil {
+		return fmt.Errorf("failed to read download directory: %w", err)
+	}
+	for _, child := range children {
+		if err := os.Rename(filepath.Join(wrapper, child.Name()), filepath.Join(root, child.Name())); err != nil {
+			return fmt.Errorf("failed to move %s: %w", child.Name(), err)
+		}
+	}
+	return os.Remove(wrapper)
+}
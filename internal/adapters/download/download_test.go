@@ -0,0 +1,156 @@
+package download
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sherpaerrors "sherpa/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tarGz builds a gzipped tarball of the given files
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// zipArchive builds a zip archive of the given files
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// serve starts a server answering every request with body
+func serve(t *testing.T, body []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestArchiveExtension(t *testing.T) {
+	tests := []struct {
+		name     string
+		urlPath  string
+		expected string
+	}{
+		{name: "should detect gzipped tarballs", urlPath: "/v1/tool.tar.gz", expected: ".tar.gz"},
+		{name: "should detect tgz", urlPath: "/tool.TGZ", expected: ".tgz"},
+		{name: "should detect zip archives", urlPath: "/archive/main.zip", expected: ".zip"},
+		{name: "should ignore other files", urlPath: "/main.go", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ArchiveExtension(tt.urlPath))
+		})
+	}
+}
+
+func TestName(t *testing.T) {
+	t.Run("should drop the archive extension", func(t *testing.T) {
+		assert.Equal(t, "tool-1.0", Name("https://example.com/dl/tool-1.0.tar.gz"))
+	})
+
+	t.Run("should keep raw file names", func(t *testing.T) {
+		assert.Equal(t, "main.go", Name("https://raw.githubusercontent.com/o/r/main/main.go"))
+	})
+
+	t.Run("should fall back to the host", func(t *testing.T) {
+		assert.Equal(t, "example.com", Name("https://example.com/"))
+	})
+}
+
+func TestFetch(t *testing.T) {
+	t.Run("should extract tarballs without their top-level directory", func(t *testing.T) {
+		server := serve(t, tarGz(t, map[string]string{
+			"tool-1.0/README.md":   "# Tool\n",
+			"tool-1.0/cmd/main.go": "package main\n",
+		}))
+
+		folder, err := Fetch(context.Background(), server.Client(), server.URL+"/tool-1.0.tar.gz", t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "tool-1.0", filepath.Base(folder))
+
+		content, err := os.ReadFile(filepath.Join(folder, "cmd", "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "package main\n", string(content))
+		assert.FileExists(t, filepath.Join(folder, "README.md"))
+	})
+
+	t.Run("should extract zip archives", func(t *testing.T) {
+		server := serve(t, zipArchive(t, map[string]string{"a.txt": "a", "dir/b.txt": "b"}))
+
+		folder, err := Fetch(context.Background(), server.Client(), server.URL+"/files.zip", t.TempDir())
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(folder, "a.txt"))
+		assert.FileExists(t, filepath.Join(folder, "dir", "b.txt"))
+	})
+
+	t.Run("should store raw files under their name", func(t *testing.T) {
+		server := serve(t, []byte("print('hi')\n"))
+
+		folder, err := Fetch(context.Background(), server.Client(), server.URL+"/raw/main/hello.py", t.TempDir())
+		require.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(folder, "hello.py"))
+		require.NoError(t, err)
+		assert.Equal(t, "print('hi')\n", string(content))
+	})
+
+	t.Run("should reject entries escaping the extraction directory", func(t *testing.T) {
+		parent := t.TempDir()
+		server := serve(t, zipArchive(t, map[string]string{"../evil.txt": "x"}))
+
+		_, err := Fetch(context.Background(), server.Client(), server.URL+"/evil.zip", parent)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "escapes the extraction directory")
+		assert.NoFileExists(t, filepath.Join(parent, "evil.txt"))
+
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should classify missing downloads", func(t *testing.T) {
+		server := serve(t, nil)
+
+		_, err := Fetch(context.Background(), server.Client(), server.URL+"/missing.zip", t.TempDir())
+		require.Error(t, err)
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(err))
+	})
+}
@@ -0,0 +1,92 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+
+	"sherpa/pkg/logger"
+)
+
+// DefaultGoProxy is used when GOPROXY names no proxy URL
+const DefaultGoProxy = "https://proxy.golang.org"
+
+// GoProxy returns the first proxy URL listed in GOPROXY, skipping direct and off entries
+func GoProxy() string {
+	for _, entry := range strings.FieldsFunc(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+		entry = strings.TrimSpace(entry)
+		if strings.HasPrefix(entry, "https://") || strings.HasPrefix(entry, "http://") {
+			return strings.TrimSuffix(entry, "/")
+		}
+	}
+	return DefaultGoProxy
+}
+
+// FetchGoModule downloads a module version from a Go module proxy into a new directory
+// below parentDir and returns the folder to process with the version fetched. An empty
+// version or latest resolves to the latest version known to the proxy.
+func FetchGoModule(ctx context.Context, client *http.Client, proxy, modulePath, version, parentDir string) (string, string, error) {
+	if version == "" || version == "latest" {
+		latest, err := latestGoModuleVersion(ctx, client, proxy, modulePath)
+		if err != nil {
+			return "", "", err
+		}
+		version = latest
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"module":  modulePath,
+		"version": version,
+		"proxy":   proxy,
+	}).Info("Downloading Go module")
+
+	zipURL := fmt.Sprintf("%s/%s/@v/%s.zip", strings.TrimSuffix(proxy, "/"), escapeModulePath(modulePath), escapeModulePath(version))
+	data, err := get(ctx, client, zipURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Module zips hold every file below module@version/
+	prefix := modulePath + "@" + version + "/"
+	folder, err := unpack(parentDir, path.Base(modulePath)+"@"+version, zipURL, func(root string) error {
+		return extractZip(data, root, prefix)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return folder, version, nil
+}
+
+// latestGoModuleVersion asks the proxy for the latest version of a module
+func latestGoModuleVersion(ctx context.Context, client *http.Client, proxy, modulePath string) (string, error) {
+	latestURL := fmt.Sprintf("%s/%s/@latest", strings.TrimSuffix(proxy, "/"), escapeModulePath(modulePath))
+	var info struct {
+		Version string `json:"Version"`
+	}
+	if err := getJSON(ctx, client, latestURL, &info); err != nil {
+		return "", err
+	}
+	if info.Version == "" {
+		return "", fmt.Errorf("invalid latest version response for module %s", modulePath)
+	}
+	return info.Version, nil
+}
+
+// escapeModulePath escapes a module path or version for the proxy protocol, which
+// replaces every uppercase letter by an exclamation mark and its lowercase form
+func escapeModulePath(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if unicode.IsUpper(r) {
+			sb.WriteRune('!')
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
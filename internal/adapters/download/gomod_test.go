@@ -0,0 +1,111 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sherpaerrors "sherpa/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveGoProxy starts a module proxy serving a single module version
+func serveGoProxy(t *testing.T, escapedPath, version string, zipData []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + escapedPath + "/@latest":
+			w.Write([]byte(`{"Version":"` + version + `","Time":"2024-01-02T03:04:05Z"}`))
+		case "/" + escapedPath + "/@v/" + version + ".zip":
+			w.Write(zipData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGoProxy(t *testing.T) {
+	tests := []struct {
+		name     string
+		goproxy  string
+		expected string
+	}{
+		{name: "should default to the public proxy", goproxy: "", expected: DefaultGoProxy},
+		{name: "should use the first proxy URL", goproxy: "https://goproxy.company.com/,https://proxy.golang.org,direct", expected: "https://goproxy.company.com"},
+		{name: "should skip direct and off", goproxy: "direct|http://localhost:3000", expected: "http://localhost:3000"},
+		{name: "should default when no proxy URL is listed", goproxy: "off", expected: DefaultGoProxy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOPROXY", tt.goproxy)
+			assert.Equal(t, tt.expected, GoProxy())
+		})
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	assert.Equal(t, "github.com/!azure/azure-sdk-for-go", escapeModulePath("github.com/Azure/azure-sdk-for-go"))
+	assert.Equal(t, "v1.0.0-!r!c1", escapeModulePath("v1.0.0-RC1"))
+	assert.Equal(t, "golang.org/x/mod", escapeModulePath("golang.org/x/mod"))
+}
+
+func TestFetchGoModule(t *testing.T) {
+	modulePath := "github.com/Foo/bar"
+	zipData := zipArchive(t, map[string]string{
+		modulePath + "@v1.2.3/go.mod":          "module github.com/Foo/bar\n",
+		modulePath + "@v1.2.3/bar.go":          "package bar\n",
+		modulePath + "@v1.2.3/internal/x/x.go": "package x\n",
+	})
+
+	t.Run("should extract a module version without its path prefix", func(t *testing.T) {
+		server := serveGoProxy(t, "github.com/!foo/bar", "v1.2.3", zipData)
+
+		folder, version, err := FetchGoModule(context.Background(), server.Client(), server.URL, modulePath, "v1.2.3", t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "v1.2.3", version)
+		assert.Equal(t, "bar@v1.2.3", filepath.Base(folder))
+
+		content, err := os.ReadFile(filepath.Join(folder, "internal", "x", "x.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "package x\n", string(content))
+		assert.FileExists(t, filepath.Join(folder, "go.mod"))
+	})
+
+	t.Run("should resolve the latest version", func(t *testing.T) {
+		server := serveGoProxy(t, "github.com/!foo/bar", "v1.2.3", zipData)
+
+		folder, version, err := FetchGoModule(context.Background(), server.Client(), server.URL, modulePath, "latest", t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "v1.2.3", version)
+		assert.FileExists(t, filepath.Join(folder, "bar.go"))
+	})
+
+	t.Run("should reject entries outside the module directory", func(t *testing.T) {
+		parent := t.TempDir()
+		server := serveGoProxy(t, "github.com/!foo/bar", "v1.2.3", zipArchive(t, map[string]string{"other/bar.go": "package bar\n"}))
+
+		_, _, err := FetchGoModule(context.Background(), server.Client(), server.URL, modulePath, "v1.2.3", parent)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is outside")
+
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should report unknown versions as not found", func(t *testing.T) {
+		server := serveGoProxy(t, "github.com/!foo/bar", "v1.2.3", zipData)
+
+		_, _, err := FetchGoModule(context.Background(), server.Client(), server.URL, modulePath, "v9.9.9", t.TempDir())
+		require.Error(t, err)
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(err))
+	})
+}
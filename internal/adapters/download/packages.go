@@ -0,0 +1,158 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"sherpa/pkg/logger"
+)
+
+const (
+	// DefaultNPMRegistry is used when no npm registry is configured
+	DefaultNPMRegistry = "https://registry.npmjs.org"
+	// DefaultPyPIURL is the package index whose JSON API describes PyPI releases
+	DefaultPyPIURL = "https://pypi.org"
+)
+
+// NPMRegistry returns the registry configured through npm_config_registry, as npm does
+func NPMRegistry() string {
+	for _, name := range []string{"npm_config_registry", "NPM_CONFIG_REGISTRY"} {
+		if registry := strings.TrimSpace(os.Getenv(name)); registry != "" {
+			return strings.TrimSuffix(registry, "/")
+		}
+	}
+	return DefaultNPMRegistry
+}
+
+// FetchNPMPackage downloads the published tarball of an npm package version into a new
+// directory below parentDir and returns the folder to process with the version fetched.
+// version may be a dist-tag like latest.
+func FetchNPMPackage(ctx context.Context, client *http.Client, registry, name, version, parentDir string) (string, string, error) {
+	// Scoped packages keep their @ but escape the slash
+	manifestURL := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(registry, "/"), strings.ReplaceAll(name, "/", "%2F"), url.PathEscape(version))
+	var manifest struct {
+		Version string `json:"version"`
+		Dist    struct {
+			Tarball string `json:"tarball"`
+		} `json:"dist"`
+	}
+	if err := getJSON(ctx, client, manifestURL, &manifest); err != nil {
+		return "", "", err
+	}
+	if manifest.Version == "" || manifest.Dist.Tarball == "" {
+		return "", "", fmt.Errorf("npm package %s@%s has no published tarball", name, version)
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"package": name,
+		"version": manifest.Version,
+	}).Info("Downloading npm package")
+
+	data, err := get(ctx, client, manifest.Dist.Tarball)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Tarballs hold every file below a package/ directory, dropped when unwrapping
+	folder, err := unpack(parentDir, path.Base(name)+"@"+manifest.Version, manifest.Dist.Tarball, func(root string) error {
+		if err := extractArchive(data, ".tgz", root); err != nil {
+			return err
+		}
+		return unwrapSingleDir(root)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return folder, manifest.Version, nil
+}
+
+// FetchPyPIPackage downloads a release of a PyPI package into a new directory below
+// parentDir and returns the folder to process with the version fetched. The source
+// distribution is preferred, falling back to a wheel. An empty version or latest resolves
+// to the latest release.
+func FetchPyPIPackage(ctx context.Context, client *http.Client, index, name, version, parentDir string) (string, string, error) {
+	releaseURL := fmt.Sprintf("%s/pypi/%s/json", strings.TrimSuffix(index, "/"), url.PathEscape(name))
+	if version != "" && version != "latest" {
+		releaseURL = fmt.Sprintf("%s/pypi/%s/%s/json", strings.TrimSuffix(index, "/"), url.PathEscape(name), url.PathEscape(version))
+	}
+	var release struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		URLs []pypiFile `json:"urls"`
+	}
+	if err := getJSON(ctx, client, releaseURL, &release); err != nil {
+		return "", "", err
+	}
+
+	file, ext := pickPyPIFile(release.URLs)
+	if file == nil {
+		return "", "", fmt.Errorf("PyPI package %s %s has no source distribution or wheel", name, release.Info.Version)
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"package": name,
+		"version": release.Info.Version,
+		"file":    file.Filename,
+	}).Info("Downloading PyPI package")
+
+	data, err := get(ctx, client, file.URL)
+	if err != nil {
+		return "", "", err
+	}
+
+	folder, err := unpack(parentDir, name+"-"+release.Info.Version, file.URL, func(root string) error {
+		if err := extractArchive(data, ext, root); err != nil {
+			return err
+		}
+		return unwrapSingleDir(root)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return folder, release.Info.Version, nil
+}
+
+// pypiFile is a file of a PyPI release
+type pypiFile struct {
+	Filename    string `json:"filename"`
+	URL         string `json:"url"`
+	PackageType string `json:"packagetype"`
+}
+
+// pickPyPIFile returns the source distribution of a release, or its first wheel, with the
+// archive extension to extract it with
+func pickPyPIFile(files []pypiFile) (*pypiFile, string) {
+	for i := range files {
+		if files[i].PackageType == "sdist" {
+			if ext := ArchiveExtension(files[i].Filename); ext != "" {
+				return &files[i], ext
+			}
+		}
+	}
+	for i := range files {
+		// Wheels are zip archives
+		if files[i].PackageType == "bdist_wheel" && strings.HasSuffix(strings.ToLower(files[i].Filename), ".whl") {
+			return &files[i], ".zip"
+		}
+	}
+	return nil, ""
+}
+
+// getJSON downloads a URL and decodes its JSON body into v
+func getJSON(ctx context.Context, client *http.Client, rawURL string, v interface{}) error {
+	data, err := get(ctx, client, rawURL)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", rawURL, err)
+	}
+	return nil
+}
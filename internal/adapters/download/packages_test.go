@@ -0,0 +1,147 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sherpaerrors "sherpa/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveRoutes starts a server answering the given paths and 404 for any other
+func serveRoutes(t *testing.T, routes map[string]func(serverURL string) []byte) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, exists := routes[r.URL.EscapedPath()]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(route(server.URL))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNPMRegistry(t *testing.T) {
+	t.Run("should default to the public registry", func(t *testing.T) {
+		t.Setenv("npm_config_registry", "")
+		t.Setenv("NPM_CONFIG_REGISTRY", "")
+		assert.Equal(t, DefaultNPMRegistry, NPMRegistry())
+	})
+
+	t.Run("should use the configured registry", func(t *testing.T) {
+		t.Setenv("npm_config_registry", "https://npm.company.com/")
+		assert.Equal(t, "https://npm.company.com", NPMRegistry())
+	})
+}
+
+func TestFetchNPMPackage(t *testing.T) {
+	tarball := tarGz(t, map[string]string{
+		"package/package.json": `{"name":"left-pad"}`,
+		"package/index.js":     "module.exports = leftPad;\n",
+	})
+	manifest := func(serverURL string) []byte {
+		return []byte(`{"name":"left-pad","version":"1.3.0","dist":{"tarball":"` + serverURL + `/left-pad/-/left-pad-1.3.0.tgz"}}`)
+	}
+
+	t.Run("should extract the package tarball", func(t *testing.T) {
+		server := serveRoutes(t, map[string]func(string) []byte{
+			"/left-pad/1.3.0":                manifest,
+			"/left-pad/-/left-pad-1.3.0.tgz": func(string) []byte { return tarball },
+		})
+
+		folder, version, err := FetchNPMPackage(context.Background(), server.Client(), server.URL, "left-pad", "1.3.0", t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "1.3.0", version)
+		assert.Equal(t, "left-pad@1.3.0", filepath.Base(folder))
+
+		content, err := os.ReadFile(filepath.Join(folder, "index.js"))
+		require.NoError(t, err)
+		assert.Equal(t, "module.exports = leftPad;\n", string(content))
+	})
+
+	t.Run("should resolve dist-tags and scoped names", func(t *testing.T) {
+		server := serveRoutes(t, map[string]func(string) []byte{
+			"/@acme%2Fleft-pad/latest":       manifest,
+			"/left-pad/-/left-pad-1.3.0.tgz": func(string) []byte { return tarball },
+		})
+
+		folder, version, err := FetchNPMPackage(context.Background(), server.Client(), server.URL, "@acme/left-pad", "latest", t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "1.3.0", version)
+		assert.FileExists(t, filepath.Join(folder, "package.json"))
+	})
+
+	t.Run("should report unknown versions as not found", func(t *testing.T) {
+		server := serveRoutes(t, map[string]func(string) []byte{})
+
+		_, _, err := FetchNPMPackage(context.Background(), server.Client(), server.URL, "left-pad", "9.9.9", t.TempDir())
+		require.Error(t, err)
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(err))
+	})
+}
+
+func TestFetchPyPIPackage(t *testing.T) {
+	sdist := tarGz(t, map[string]string{
+		"requests-2.31.0/setup.py":             "setup(name='requests')\n",
+		"requests-2.31.0/requests/__init__.py": "__version__ = '2.31.0'\n",
+	})
+	wheel := zipArchive(t, map[string]string{
+		"requests/__init__.py":               "__version__ = '2.31.0'\n",
+		"requests-2.31.0.dist-info/METADATA": "Name: requests\n",
+	})
+
+	t.Run("should prefer the source distribution", func(t *testing.T) {
+		server := serveRoutes(t, map[string]func(string) []byte{
+			"/pypi/requests/2.31.0/json": func(serverURL string) []byte {
+				return []byte(`{"info":{"version":"2.31.0"},"urls":[` +
+					`{"filename":"requests-2.31.0-py3-none-any.whl","url":"` + serverURL + `/files/requests.whl","packagetype":"bdist_wheel"},` +
+					`{"filename":"requests-2.31.0.tar.gz","url":"` + serverURL + `/files/requests.tar.gz","packagetype":"sdist"}]}`)
+			},
+			"/files/requests.tar.gz": func(string) []byte { return sdist },
+		})
+
+		folder, version, err := FetchPyPIPackage(context.Background(), server.Client(), server.URL, "requests", "2.31.0", t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "2.31.0", version)
+		assert.Equal(t, "requests-2.31.0", filepath.Base(folder))
+		assert.FileExists(t, filepath.Join(folder, "setup.py"))
+		assert.FileExists(t, filepath.Join(folder, "requests", "__init__.py"))
+	})
+
+	t.Run("should fall back to a wheel for the latest release", func(t *testing.T) {
+		server := serveRoutes(t, map[string]func(string) []byte{
+			"/pypi/requests/json": func(serverURL string) []byte {
+				return []byte(`{"info":{"version":"2.31.0"},"urls":[` +
+					`{"filename":"requests-2.31.0-py3-none-any.whl","url":"` + serverURL + `/files/requests.whl","packagetype":"bdist_wheel"}]}`)
+			},
+			"/files/requests.whl": func(string) []byte { return wheel },
+		})
+
+		folder, version, err := FetchPyPIPackage(context.Background(), server.Client(), server.URL, "requests", "", t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "2.31.0", version)
+		assert.FileExists(t, filepath.Join(folder, "requests", "__init__.py"))
+		assert.FileExists(t, filepath.Join(folder, "requests-2.31.0.dist-info", "METADATA"))
+	})
+
+	t.Run("should error when a release has no usable file", func(t *testing.T) {
+		server := serveRoutes(t, map[string]func(string) []byte{
+			"/pypi/requests/2.31.0/json": func(string) []byte {
+				return []byte(`{"info":{"version":"2.31.0"},"urls":[{"filename":"requests-2.31.0.exe","url":"x","packagetype":"bdist_wininst"}]}`)
+			},
+		})
+
+		_, _, err := FetchPyPIPackage(context.Background(), server.Client(), server.URL, "requests", "2.31.0", t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no source distribution or wheel")
+	})
+}
@@ -0,0 +1,140 @@
+// Package fetchpool fetches the files of a repository with a bounded pool of workers,
+// shared by the platform clients.
+package fetchpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency is the number of files fetched at once when none is configured
+const DefaultConcurrency = 5
+
+// FetchFunc fetches a single file. Errors specific to the file can be returned either way,
+// as the error or in the Error field of the file.
+type FetchFunc func(ctx context.Context, filePath string) (*models.FileInfo, error)
+
+// Fetch fetches files with at most maxConcurrency requests in flight, returning them in the
+// order of filePaths with their fetch duration. Files failing on their own carry their error,
+// including rate limited ones, which are fetched again by later runs. Rejected credentials
+// stop the remaining fetches, since every later request would fail too, and so does the
+// cancellation of ctx: the files left unfetched carry that error.
+//
+// Memory is bounded by config: contents above MaxMemoryPerFile are dropped with an error,
+// and fetching fails once the contents held exceed MaxTotalMemory.
+func Fetch(ctx context.Context, filePaths []string, maxConcurrency int, config *models.ProcessingConfig, fetch FetchFunc) ([]models.FileInfo, error) {
+	return FetchBatches(ctx, filePaths, 1, maxConcurrency, config, func(ctx context.Context, batch []string) ([]*models.FileInfo, error) {
+		file, err := fetch(ctx, batch[0])
+		if err != nil {
+			file = &models.FileInfo{Path: batch[0], Name: path.Base(batch[0]), Error: err}
+		}
+		return []*models.FileInfo{file}, nil
+	})
+}
+
+// BatchFunc fetches a batch of files, returning them in the order of filePaths. Errors
+// specific to a file go in its Error field, while an error returned fails the whole batch.
+type BatchFunc func(ctx context.Context, filePaths []string) ([]*models.FileInfo, error)
+
+// FetchBatches fetches files by batches of batchSize files, with at most maxConcurrency
+// batches in flight, like Fetch. Each file records the duration of its batch. The files of a
+// batch are accounted for as soon as the batch returns, so their contents are only held by
+// the results.
+func FetchBatches(ctx context.Context, filePaths []string, batchSize, maxConcurrency int, config *models.ProcessingConfig, fetch BatchFunc) ([]models.FileInfo, error) {
+	if maxConcurrency <= 0 {
+		maxConcurrency = DefaultConcurrency
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if config.MaxFiles > 0 && len(filePaths) > config.MaxFiles {
+		return nil, sherpaerrors.Wrap(sherpaerrors.KindTooLarge, fmt.Errorf("too many files to process safely: %d (max: %d)", len(filePaths), config.MaxFiles))
+	}
+
+	results := make([]models.FileInfo, len(filePaths))
+	fetched := make([]bool, len(filePaths))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrency)
+
+	var mu sync.Mutex
+	var held int64  // Bytes of content held by fetched files
+	var stop error  // Rejected credentials that stopped the remaining fetches
+	var limit error // Total memory limit exceeded
+
+	for first := 0; first < len(filePaths); first += batchSize {
+		// Batches are started in the order given, which is the priority of their files
+		if groupCtx.Err() != nil {
+			break
+		}
+		batch := filePaths[first:min(first+batchSize, len(filePaths))]
+		group.Go(func() error {
+			start := time.Now()
+			files, err := fetch(groupCtx, batch)
+			duration := time.Since(start)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for j, filePath := range batch {
+				var file *models.FileInfo
+				if err == nil && j < len(files) {
+					file = files[j]
+				}
+				if file == nil {
+					file = &models.FileInfo{Path: filePath, Name: path.Base(filePath), Error: err}
+					if err == nil {
+						file.Error = fmt.Errorf("file %s missing from its batch", filePath)
+					}
+				}
+				file.FetchDuration = duration
+
+				if config.MaxMemoryPerFile > 0 && file.ContentSize > config.MaxMemoryPerFile {
+					file.Error = sherpaerrors.Wrap(sherpaerrors.KindTooLarge, fmt.Errorf("file content of %d bytes exceeds the memory limit per file (%d bytes)", file.ContentSize, config.MaxMemoryPerFile))
+					file.Content = ""
+					file.ContentSize = 0
+				}
+				results[first+j] = *file
+				fetched[first+j] = true
+
+				held += file.ContentSize
+				if config.MaxTotalMemory > 0 && held > config.MaxTotalMemory && limit == nil {
+					limit = sherpaerrors.Wrap(sherpaerrors.KindTooLarge, fmt.Errorf("file contents exceed the total memory limit (%d bytes)", config.MaxTotalMemory))
+				}
+				if sherpaerrors.KindOf(file.Error) == sherpaerrors.KindAuth && stop == nil {
+					stop = file.Error
+				}
+			}
+			if limit != nil {
+				return limit
+			}
+			return stop
+		})
+	}
+	_ = group.Wait()
+
+	if limit != nil {
+		return nil, limit
+	}
+
+	// Files never started, or cut short by the stop, carry its cause
+	cause := stop
+	if cause == nil {
+		cause = ctx.Err()
+	}
+	for i, filePath := range filePaths {
+		cutShort := stop != nil && ctx.Err() == nil && errors.Is(results[i].Error, context.Canceled)
+		if fetched[i] && !cutShort {
+			continue
+		}
+		results[i] = models.FileInfo{Path: filePath, Name: path.Base(filePath), Error: cause, FetchDuration: results[i].FetchDuration}
+	}
+	return results, nil
+}
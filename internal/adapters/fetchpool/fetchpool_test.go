@@ -0,0 +1,234 @@
+package fetchpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig allows plenty of files and memory
+var testConfig = &models.ProcessingConfig{MaxFiles: 1000, MaxMemoryPerFile: 1024, MaxTotalMemory: 1024 * 1024}
+
+// paths returns n file paths
+func paths(n int) []string {
+	filePaths := make([]string, n)
+	for i := range filePaths {
+		filePaths[i] = fmt.Sprintf("src/file%03d.go", i)
+	}
+	return filePaths
+}
+
+// content fetches a file whose content is its path
+func content(ctx context.Context, filePath string) (*models.FileInfo, error) {
+	return &models.FileInfo{Path: filePath, Content: filePath, ContentSize: int64(len(filePath))}, nil
+}
+
+func TestFetch(t *testing.T) {
+	t.Run("should return files in the order given", func(t *testing.T) {
+		filePaths := paths(50)
+		files, err := Fetch(context.Background(), filePaths, 8, testConfig, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+			time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+			return content(ctx, filePath)
+		})
+		require.NoError(t, err)
+
+		require.Len(t, files, len(filePaths))
+		for i, file := range files {
+			assert.Equal(t, filePaths[i], file.Path)
+			assert.Equal(t, filePaths[i], file.Content)
+		}
+	})
+
+	t.Run("should keep at most maxConcurrency fetches in flight", func(t *testing.T) {
+		var inFlight, peak atomic.Int32
+		_, err := Fetch(context.Background(), paths(30), 3, testConfig, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				previous := peak.Load()
+				if current <= previous || peak.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return content(ctx, filePath)
+		})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+	})
+
+	t.Run("should keep errors of single files with the files", func(t *testing.T) {
+		files, err := Fetch(context.Background(), []string{"ok.go", "missing.go"}, 2, testConfig, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+			if filePath == "missing.go" {
+				return nil, sherpaerrors.New(sherpaerrors.KindNotFound, "file not found")
+			}
+			return content(ctx, filePath)
+		})
+		require.NoError(t, err)
+
+		assert.NoError(t, files[0].Error)
+		assert.Equal(t, "missing.go", files[1].Path)
+		assert.Equal(t, "missing.go", files[1].Name)
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(files[1].Error))
+	})
+
+	t.Run("should stop fetching once credentials are rejected", func(t *testing.T) {
+		var calls atomic.Int32
+		files, err := Fetch(context.Background(), paths(100), 1, testConfig, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+			if calls.Add(1) > 5 {
+				return nil, sherpaerrors.New(sherpaerrors.KindAuth, "401 Unauthorized")
+			}
+			return content(ctx, filePath)
+		})
+		require.NoError(t, err)
+
+		assert.Less(t, int(calls.Load()), 10, "fetches should stop after the credentials are rejected")
+		require.Len(t, files, 100)
+		assert.NoError(t, files[4].Error)
+		for _, file := range files[5:] {
+			assert.Equal(t, sherpaerrors.KindAuth, sherpaerrors.KindOf(file.Error), file.Path)
+		}
+	})
+
+	t.Run("should keep fetching other files when one is rate limited", func(t *testing.T) {
+		files, err := Fetch(context.Background(), paths(10), 1, testConfig, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+			if filePath == "src/file002.go" {
+				return nil, sherpaerrors.New(sherpaerrors.KindRateLimited, "rate limit exceeded")
+			}
+			return content(ctx, filePath)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, sherpaerrors.KindRateLimited, sherpaerrors.KindOf(files[2].Error))
+		assert.NoError(t, files[9].Error)
+	})
+
+	t.Run("should leave files unfetched once the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls atomic.Int32
+		files, err := Fetch(ctx, paths(100), 1, testConfig, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+			if calls.Add(1) == 3 {
+				cancel()
+			}
+			return content(ctx, filePath)
+		})
+		require.NoError(t, err)
+
+		require.Len(t, files, 100)
+		assert.True(t, errors.Is(files[99].Error, context.Canceled))
+		assert.Less(t, int(calls.Load()), 10)
+	})
+
+	t.Run("should drop contents above the memory limit per file", func(t *testing.T) {
+		config := &models.ProcessingConfig{MaxFiles: 10, MaxMemoryPerFile: 8, MaxTotalMemory: 1024}
+		files, err := Fetch(context.Background(), []string{"a.go", "large.go"}, 2, config, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+			if filePath == "large.go" {
+				return &models.FileInfo{Path: filePath, Content: strings.Repeat("x", 16), ContentSize: 16}, nil
+			}
+			return content(ctx, filePath)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "a.go", files[0].Content)
+		assert.Empty(t, files[1].Content)
+		assert.Equal(t, sherpaerrors.KindTooLarge, sherpaerrors.KindOf(files[1].Error))
+	})
+
+	t.Run("should fail once contents exceed the total memory limit", func(t *testing.T) {
+		config := &models.ProcessingConfig{MaxFiles: 1000, MaxMemoryPerFile: 1024, MaxTotalMemory: 100}
+		_, err := Fetch(context.Background(), paths(50), 4, config, content)
+
+		require.Error(t, err)
+		assert.Equal(t, sherpaerrors.KindTooLarge, sherpaerrors.KindOf(err))
+	})
+
+	t.Run("should reject more files than allowed", func(t *testing.T) {
+		config := &models.ProcessingConfig{MaxFiles: 10, MaxMemoryPerFile: 1024, MaxTotalMemory: 1024}
+		_, err := Fetch(context.Background(), paths(11), 4, config, content)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many files to process safely")
+	})
+
+	t.Run("should record how long each file took", func(t *testing.T) {
+		files, err := Fetch(context.Background(), paths(2), 2, testConfig, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+			time.Sleep(2 * time.Millisecond)
+			return content(ctx, filePath)
+		})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, files[0].FetchDuration, 2*time.Millisecond)
+	})
+}
+
+func TestFetchBatches(t *testing.T) {
+	// contents fetches a batch of files whose contents are their paths
+	contents := func(ctx context.Context, filePaths []string) ([]*models.FileInfo, error) {
+		files := make([]*models.FileInfo, len(filePaths))
+		for i, filePath := range filePaths {
+			files[i], _ = content(ctx, filePath)
+		}
+		return files, nil
+	}
+
+	t.Run("should fetch batches concurrently and return files in the order given", func(t *testing.T) {
+		filePaths := paths(95)
+		var batches, inFlight, peak atomic.Int32
+		files, err := FetchBatches(context.Background(), filePaths, 10, 3, testConfig, func(ctx context.Context, batch []string) ([]*models.FileInfo, error) {
+			batches.Add(1)
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				previous := peak.Load()
+				if current <= previous || peak.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(1+rand.Intn(3)) * time.Millisecond)
+			return contents(ctx, batch)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(10), batches.Load())
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+		assert.Greater(t, peak.Load(), int32(1))
+		require.Len(t, files, len(filePaths))
+		for i, file := range files {
+			assert.Equal(t, filePaths[i], file.Path)
+			assert.Equal(t, filePaths[i], file.Content)
+		}
+	})
+
+	t.Run("should fail every file of a failed batch", func(t *testing.T) {
+		files, err := FetchBatches(context.Background(), paths(4), 2, 2, testConfig, func(ctx context.Context, batch []string) ([]*models.FileInfo, error) {
+			if batch[0] == "src/file002.go" {
+				return nil, sherpaerrors.New(sherpaerrors.KindRateLimited, "rate limit exceeded")
+			}
+			return contents(ctx, batch)
+		})
+		require.NoError(t, err)
+
+		assert.NoError(t, files[0].Error)
+		assert.NoError(t, files[1].Error)
+		assert.Equal(t, sherpaerrors.KindRateLimited, sherpaerrors.KindOf(files[2].Error))
+		assert.Equal(t, sherpaerrors.KindRateLimited, sherpaerrors.KindOf(files[3].Error))
+		assert.Equal(t, "file003.go", files[3].Name)
+	})
+
+	t.Run("should fail once the contents of batches exceed the total memory limit", func(t *testing.T) {
+		config := &models.ProcessingConfig{MaxFiles: 1000, MaxMemoryPerFile: 1024, MaxTotalMemory: 100}
+		_, err := FetchBatches(context.Background(), paths(20), 5, 2, config, contents)
+		require.Error(t, err)
+		assert.Equal(t, sherpaerrors.KindTooLarge, sherpaerrors.KindOf(err))
+	})
+}
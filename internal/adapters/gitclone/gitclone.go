@@ -0,0 +1,188 @@
+// Package gitclone shallow-clones git repositories with go-git into a local folder, so
+// repositories can be processed without platform API access.
+package gitclone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/logger"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// fetchedRef is the local ref the fetched ref is stored at until it is checked out
+const fetchedRef = "refs/sherpa/fetched"
+
+// Options describes what to clone
+type Options struct {
+	URL      string // Remote URL, in any form git understands
+	Ref      string // Branch, tag or commit to fetch, empty for the default branch
+	Username string // Username sent with Token over HTTPS
+	Token    string // Token sent as an Authorization header over HTTPS, never in the URL
+}
+
+// Clone fetches the ref at depth 1 into a new folder below parentDir, using the system
+// temporary directory when parentDir is empty. It returns the folder holding the files,
+// without the .git directory, and the commit that was fetched. The parent of the folder
+// must be removed once processing is done.
+func Clone(ctx context.Context, options Options, parentDir string) (string, string, error) {
+	// Values starting with a dash would be read by git as options
+	if options.URL == "" || strings.HasPrefix(options.URL, "-") {
+		return "", "", fmt.Errorf("invalid git remote '%s'", options.URL)
+	}
+	if strings.HasPrefix(options.Ref, "-") {
+		return "", "", fmt.Errorf("invalid git ref '%s'", options.Ref)
+	}
+
+	logger.Logger.WithField("url", options.URL).Info("Cloning repository")
+
+	dir, err := os.MkdirTemp(parentDir, "sherpa-clone-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create clone directory: %w", err)
+	}
+	folder := filepath.Join(dir, Name(options.URL))
+
+	commit, err := clone(ctx, options, folder)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	return folder, commit, nil
+}
+
+// clone fetches the ref into folder and returns its commit. Fetching into an empty
+// repository works for branches, tags and, on most hosts, commits alike.
+func clone(ctx context.Context, options Options, folder string) (string, error) {
+	repo, err := git.PlainInit(folder, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to create clone repository: %w", err)
+	}
+	remote, err := repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{options.URL}})
+	if err != nil {
+		return "", fmt.Errorf("invalid git remote '%s': %w", options.URL, err)
+	}
+
+	// The token is sent as basic auth credentials, so it never shows up in the URL
+	var auth transport.AuthMethod
+	if options.Token != "" {
+		auth = &githttp.BasicAuth{Username: options.Username, Password: options.Token}
+	}
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", classify(options.URL, "list refs", err)
+	}
+	source, err := remoteRef(refs, options.Ref)
+	if err != nil {
+		return "", classify(options.URL, "fetch", err)
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec("+" + source + ":" + fetchedRef)},
+		Depth:    1,
+		Auth:     auth,
+		Tags:     git.NoTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return "", classify(options.URL, "fetch", err)
+	}
+
+	commit, err := fetchedCommit(repo)
+	if err != nil {
+		return "", err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to open the worktree of %s: %w", options.URL, err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: commit, Force: true}); err != nil {
+		return "", fmt.Errorf("failed to check out %s of %s: %w", commit, options.URL, err)
+	}
+
+	// The history is not processed, so the repository metadata is dropped
+	if err := os.RemoveAll(filepath.Join(folder, ".git")); err != nil {
+		return "", fmt.Errorf("failed to remove git metadata: %w", err)
+	}
+	return commit.String(), nil
+}
+
+// remoteRef returns the remote ref to fetch for ref among the refs of the remote: HEAD when
+// ref is empty, a branch, a tag, a full ref name or, when none matches, a commit hash
+func remoteRef(refs []*plumbing.Reference, ref string) (string, error) {
+	if ref == "" {
+		ref = string(plumbing.HEAD)
+	}
+
+	names := make(map[plumbing.ReferenceName]bool, len(refs))
+	for _, remote := range refs {
+		names[remote.Name()] = true
+	}
+	for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref), plumbing.ReferenceName(ref)} {
+		if names[name] {
+			return name.String(), nil
+		}
+	}
+	if plumbing.IsHash(ref) {
+		return ref, nil
+	}
+	return "", fmt.Errorf("couldn't find remote ref %s: %w", ref, plumbing.ErrReferenceNotFound)
+}
+
+// fetchedCommit returns the commit fetched, peeling annotated tags
+func fetchedCommit(repo *git.Repository) (plumbing.Hash, error) {
+	ref, err := repo.Reference(fetchedRef, true)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read the fetched ref: %w", err)
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return ref.Hash(), nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read the fetched tag: %w", err)
+	}
+	commit, err := tag.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read the commit of the fetched tag: %w", err)
+	}
+	return commit.Hash, nil
+}
+
+// classify wraps an error of a git operation on remote, classifying it when its cause is known
+func classify(remote, operation string, err error) error {
+	err = fmt.Errorf("git %s failed for %s: %w", operation, remote, err)
+	switch {
+	case errors.Is(err, transport.ErrAuthenticationRequired),
+		errors.Is(err, transport.ErrAuthorizationFailed),
+		errors.Is(err, transport.ErrInvalidAuthMethod):
+		return sherpaerrors.Wrap(sherpaerrors.KindAuth, err)
+	case errors.Is(err, transport.ErrRepositoryNotFound),
+		errors.Is(err, transport.ErrEmptyRemoteRepository),
+		errors.Is(err, plumbing.ErrReferenceNotFound):
+		return sherpaerrors.Wrap(sherpaerrors.KindNotFound, err)
+	default:
+		return err
+	}
+}
+
+// Name returns the repository name of a remote URL, like repo for
+// https://host/owner/repo.git or git@host:owner/repo.git
+func Name(remote string) string {
+	trimmed := strings.TrimSuffix(strings.TrimRight(remote, "/"), ".git")
+	if i := strings.LastIndexAny(trimmed, "/:"); i >= 0 {
+		trimmed = trimmed[i+1:]
+	}
+	if trimmed == "" || trimmed == "." || trimmed == ".." {
+		return "repository"
+	}
+	return trimmed
+}
@@ -0,0 +1,159 @@
+package gitclone
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	sherpaerrors "sherpa/pkg/errors"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initRepository creates a git repository with one commit per set of files, the first one
+// tagged v1.0.0 and the last one on a develop branch when develop is given, and returns its
+// file:// remote
+func initRepository(t *testing.T, files, develop map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "tool.git")
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Sherpa", "-c", "user.email=sherpa@example.com", "-c", "init.defaultBranch=main"}, args...)...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	commit := func(files map[string]string) {
+		for name, content := range files {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+		git("add", "-A")
+		git("commit", "--quiet", "-m", "update")
+	}
+
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	git("init", "--quiet")
+	commit(files)
+	git("tag", "-a", "v1.0.0", "-m", "release")
+	if develop != nil {
+		git("checkout", "--quiet", "-b", "develop")
+		commit(develop)
+		git("checkout", "--quiet", "main")
+	}
+	return "file://" + dir
+}
+
+func TestClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	remote := initRepository(t, map[string]string{"main.go": "package main\n", "docs/README.md": "# Tool\n"}, map[string]string{"main.go": "package main // develop\n"})
+
+	t.Run("should clone the default branch without git metadata", func(t *testing.T) {
+		folder, commit, err := Clone(context.Background(), Options{URL: remote}, t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "tool", filepath.Base(folder))
+		assert.Len(t, commit, 40)
+
+		content, err := os.ReadFile(filepath.Join(folder, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "package main\n", string(content))
+		assert.FileExists(t, filepath.Join(folder, "docs", "README.md"))
+		assert.NoDirExists(t, filepath.Join(folder, ".git"))
+	})
+
+	t.Run("should clone the requested branch", func(t *testing.T) {
+		folder, _, err := Clone(context.Background(), Options{URL: remote, Ref: "develop"}, t.TempDir())
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(folder, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "package main // develop\n", string(content))
+	})
+
+	t.Run("should clone the commit of an annotated tag", func(t *testing.T) {
+		_, head, err := Clone(context.Background(), Options{URL: remote}, t.TempDir())
+		require.NoError(t, err)
+
+		folder, commit, err := Clone(context.Background(), Options{URL: remote, Ref: "v1.0.0"}, t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, head, commit)
+		assert.FileExists(t, filepath.Join(folder, "main.go"))
+	})
+
+	t.Run("should report missing refs as not found and clean up", func(t *testing.T) {
+		parentDir := t.TempDir()
+		_, _, err := Clone(context.Background(), Options{URL: remote, Ref: "missing"}, parentDir)
+		require.Error(t, err)
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(err))
+
+		entries, err := os.ReadDir(parentDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should reject values read as git options", func(t *testing.T) {
+		_, _, err := Clone(context.Background(), Options{URL: "--upload-pack=touch /tmp/pwned"}, t.TempDir())
+		assert.ErrorContains(t, err, "invalid git remote")
+
+		_, _, err = Clone(context.Background(), Options{URL: remote, Ref: "--output=/tmp/pwned"}, t.TempDir())
+		assert.ErrorContains(t, err, "invalid git ref")
+	})
+}
+
+func TestRemoteRef(t *testing.T) {
+	refs := []*plumbing.Reference{
+		plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main"),
+		plumbing.NewHashReference("refs/heads/main", plumbing.ZeroHash),
+		plumbing.NewHashReference("refs/tags/v1.0.0", plumbing.ZeroHash),
+	}
+	commit := "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected string
+	}{
+		{name: "should fetch HEAD without a ref", ref: "", expected: "HEAD"},
+		{name: "should resolve branches", ref: "main", expected: "refs/heads/main"},
+		{name: "should resolve tags", ref: "v1.0.0", expected: "refs/tags/v1.0.0"},
+		{name: "should accept full ref names", ref: "refs/tags/v1.0.0", expected: "refs/tags/v1.0.0"},
+		{name: "should fetch commits by hash", ref: commit, expected: commit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := remoteRef(refs, tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, source)
+		})
+	}
+
+	t.Run("should report missing refs as not found", func(t *testing.T) {
+		_, err := remoteRef(refs, "missing")
+		assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+	})
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name     string
+		remote   string
+		expected string
+	}{
+		{name: "should strip the .git suffix", remote: "https://git.example.com/team/tool.git", expected: "tool"},
+		{name: "should handle scp-like remotes", remote: "git@git.example.com:tool.git", expected: "tool"},
+		{name: "should handle trailing slashes", remote: "git://git.example.com/team/tool/", expected: "tool"},
+		{name: "should fall back to a generic name", remote: "file:///", expected: "repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Name(tt.remote))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package gitclone
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+)
+
+// wikiExtensions are the markup formats wiki pages are written in
+var wikiExtensions = map[string]bool{
+	".md": true, ".markdown": true, ".mdown": true, ".mkdn": true,
+	".textile": true, ".rdoc": true, ".org": true, ".creole": true,
+	".mediawiki": true, ".wiki": true, ".rst": true, ".asciidoc": true,
+	".adoc": true, ".asc": true, ".pod": true,
+}
+
+// CloneWiki clones a wiki repository and returns its pages, Home first. Sidebars, footers
+// and other files starting with an underscore are left out. A missing wiki has no pages.
+func CloneWiki(ctx context.Context, options Options) ([]models.WikiPage, error) {
+	folder, _, err := Clone(ctx, options, "")
+	if err != nil {
+		if errors.Is(err, sherpaerrors.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer os.RemoveAll(filepath.Dir(folder))
+
+	var pages []models.WikiPage
+	err = filepath.WalkDir(folder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != folder && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(name))
+		if !wikiExtensions[ext] || strings.HasPrefix(name, "_") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, models.WikiPage{
+			Title:   strings.ReplaceAll(strings.TrimSuffix(name, filepath.Ext(name)), "-", " "),
+			Path:    filepath.ToSlash(rel),
+			Content: string(content),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].Title == "Home" && pages[j].Title != "Home"
+	})
+	return pages, nil
+}
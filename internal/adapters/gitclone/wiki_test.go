@@ -0,0 +1,42 @@
+package gitclone
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneWiki(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Run("should return the pages with Home first", func(t *testing.T) {
+		remote := initRepository(t, map[string]string{
+			"Architecture-Overview.md": "# Architecture\n",
+			"Home.md":                  "Welcome\n",
+			"_Sidebar.md":              "* [[Home]]\n",
+			"images/diagram.png":       "png",
+			"guides/Deploying.rst":     "Deploying\n=========\n",
+		}, nil)
+
+		pages, err := CloneWiki(context.Background(), Options{URL: remote})
+		require.NoError(t, err)
+		require.Len(t, pages, 3)
+		assert.Equal(t, "Home", pages[0].Title)
+		assert.Equal(t, "Welcome\n", pages[0].Content)
+		assert.Equal(t, "Architecture Overview", pages[1].Title)
+		assert.Equal(t, "Architecture-Overview.md", pages[1].Path)
+		assert.Equal(t, "guides/Deploying.rst", pages[2].Path)
+	})
+
+	t.Run("should return no pages for a missing wiki", func(t *testing.T) {
+		pages, err := CloneWiki(context.Background(), Options{URL: "file://" + filepath.Join(t.TempDir(), "missing.wiki.git")})
+		require.NoError(t, err)
+		assert.Empty(t, pages)
+	})
+}
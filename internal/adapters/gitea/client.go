@@ -0,0 +1,484 @@
+package gitea
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"sherpa/internal/adapters/fetchpool"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// treePageSize is the number of tree entries requested per page
+const treePageSize = 1000
+
+// repoPageSize is the number of repositories requested per page, the default maximum of Gitea
+const repoPageSize = 50
+
+// Client talks to the REST API of Gitea and Forgejo instances
+type Client struct {
+	httpClient *http.Client
+	apiURL     string // Base URL of the v1 API, without trailing slash
+	baseURL    string
+	token      string
+}
+
+// NewClient creates a new Gitea client
+func NewClient(baseURL, token string) (*Client, error) {
+	return NewClientWithTransport(baseURL, token, nil)
+}
+
+// NewClientWithTransport creates a Gitea client sending requests through transport,
+// http.DefaultTransport when nil
+func NewClientWithTransport(baseURL, token string, transport http.RoundTripper) (*Client, error) {
+	if token == "" {
+		return nil, fmt.Errorf("Gitea token is required")
+	}
+
+	if baseURL == "" {
+		baseURL = "https://gitea.com"
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("failed to parse base URL: %s", baseURL)
+	}
+
+	// Accept both the instance URL and the API URL
+	apiURL := strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(apiURL, "/api/v1") {
+		apiURL += "/api/v1"
+	}
+
+	logger.Logger.WithField("api_url", apiURL).Debug("Created Gitea client")
+
+	return &Client{
+		httpClient: &http.Client{Transport: transport},
+		apiURL:     apiURL,
+		baseURL:    baseURL,
+		token:      token,
+	}, nil
+}
+
+// repositoryResponse is the subset of the repository API response used by sherpa
+type repositoryResponse struct {
+	ID            int64    `json:"id"`
+	Name          string   `json:"name"`
+	FullName      string   `json:"full_name"`
+	HTMLURL       string   `json:"html_url"`
+	Description   string   `json:"description"`
+	DefaultBranch string   `json:"default_branch"`
+	Archived      bool     `json:"archived"`
+	Fork          bool     `json:"fork"`
+	Private       bool     `json:"private"`
+	Internal      bool     `json:"internal"`
+	Topics        []string `json:"topics"`
+}
+
+// treeResponse is a page of the git trees API response
+type treeResponse struct {
+	Entries []struct {
+		Path string `json:"path"`
+		Mode string `json:"mode"`
+		Type string `json:"type"`
+		Size int64  `json:"size"`
+		SHA  string `json:"sha"`
+	} `json:"tree"`
+	Truncated  bool `json:"truncated"`
+	TotalCount int  `json:"total_count"`
+}
+
+// contentsResponse is the subset of the contents API response used by sherpa
+type contentsResponse struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Size     int64  `json:"size"`
+	Content  string `json:"content"`
+}
+
+// GetRepository fetches repository information by owner/repo
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*models.Repository, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":      owner,
+		"repository": repo,
+	}).Debug("Fetching Gitea repository information")
+
+	var repository repositoryResponse
+	if err := c.get(ctx, repoEndpoint(owner, repo), nil, &repository); err != nil {
+		logger.Logger.WithError(err).WithFields(map[string]interface{}{
+			"owner":      owner,
+			"repository": repo,
+		}).Error("Failed to fetch Gitea repository")
+		return nil, fmt.Errorf("failed to fetch repository %s/%s: %w", owner, repo, err)
+	}
+
+	return &models.Repository{
+		ID:                repository.ID,
+		Name:              repository.Name,
+		Path:              repository.Name,
+		PathWithNamespace: repository.FullName,
+		WebURL:            repository.HTMLURL,
+		Description:       repository.Description,
+		Platform:          models.PlatformGitea,
+		Owner:             owner,
+	}, nil
+}
+
+// ListRepositories lists the repositories of an organization, or of a user when no
+// organization has that name
+func (c *Client) ListRepositories(ctx context.Context, owner string) ([]models.ListedRepository, error) {
+	logger.Logger.WithField("owner", owner).Debug("Listing Gitea repositories")
+
+	repos, err := c.listRepositories(ctx, "/orgs/"+url.PathEscape(owner)+"/repos")
+	if sherpaerrors.KindOf(err) == sherpaerrors.KindNotFound {
+		repos, err = c.listRepositories(ctx, "/users/"+url.PathEscape(owner)+"/repos")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories of %s: %w", owner, err)
+	}
+	return repos, nil
+}
+
+// listRepositories reads every page of a repository listing endpoint
+func (c *Client) listRepositories(ctx context.Context, endpoint string) ([]models.ListedRepository, error) {
+	var repos []models.ListedRepository
+	for page := 1; ; page++ {
+		var repositories []repositoryResponse
+		query := url.Values{"page": {fmt.Sprint(page)}, "limit": {fmt.Sprint(repoPageSize)}}
+		if err := c.get(ctx, endpoint, query, &repositories); err != nil {
+			return nil, err
+		}
+		for _, repository := range repositories {
+			visibility := "public"
+			if repository.Private {
+				visibility = "private"
+			} else if repository.Internal {
+				visibility = "internal"
+			}
+			repos = append(repos, models.ListedRepository{
+				FullName:      repository.FullName,
+				DefaultBranch: repository.DefaultBranch,
+				Archived:      repository.Archived,
+				Fork:          repository.Fork,
+				Visibility:    visibility,
+				Topics:        repository.Topics,
+			})
+		}
+		if len(repositories) < repoPageSize {
+			return repos, nil
+		}
+	}
+}
+
+// ResolveCommit returns the commit SHA a ref points to, using the default branch when ref is empty
+func (c *Client) ResolveCommit(ctx context.Context, owner, repo, ref string) (string, error) {
+	if ref == "" {
+		branch, err := c.defaultBranch(ctx, owner, repo)
+		if err != nil {
+			return "", err
+		}
+		ref = branch
+	}
+
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	query := url.Values{"sha": {ref}, "limit": {"1"}, "stat": {"false"}}
+	if err := c.get(ctx, repoEndpoint(owner, repo)+"/commits", query, &commits); err != nil {
+		logger.Logger.WithError(err).WithFields(map[string]interface{}{
+			"owner":      owner,
+			"repository": repo,
+			"ref":        ref,
+		}).Error("Failed to resolve Gitea commit")
+		return "", fmt.Errorf("failed to resolve commit for %s: %w", ref, err)
+	}
+	if len(commits) == 0 {
+		return "", sherpaerrors.New(sherpaerrors.KindNotFound, fmt.Sprintf("no commit found for %s", ref))
+	}
+
+	return commits[0].SHA, nil
+}
+
+// GetRepositoryTree fetches the complete repository tree structure
+func (c *Client) GetRepositoryTree(ctx context.Context, owner, repo, branch string) ([]models.RepositoryTree, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":      owner,
+		"repository": repo,
+		"branch":     branch,
+	}).Debug("Fetching Gitea repository tree structure")
+
+	targetBranch := branch
+	if targetBranch == "" {
+		defaultBranch, err := c.defaultBranch(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repository info: %w", err)
+		}
+		targetBranch = defaultBranch
+	}
+
+	var allFiles []models.RepositoryTree
+	for page := 1; ; page++ {
+		var tree treeResponse
+		query := url.Values{
+			"recursive": {"true"},
+			"page":      {fmt.Sprint(page)},
+			"per_page":  {fmt.Sprint(treePageSize)},
+		}
+		if err := c.get(ctx, repoEndpoint(owner, repo)+"/git/trees/"+url.PathEscape(targetBranch), query, &tree); err != nil {
+			logger.Logger.WithError(err).WithFields(map[string]interface{}{
+				"owner":      owner,
+				"repository": repo,
+				"branch":     targetBranch,
+			}).Error("Failed to fetch Gitea repository tree")
+			return nil, fmt.Errorf("failed to fetch repository tree: %w", err)
+		}
+
+		for _, entry := range tree.Entries {
+			if entry.Type == "blob" { // Only include files, not directories
+				allFiles = append(allFiles, models.RepositoryTree{
+					ID:   entry.SHA,
+					Name: extractFileName(entry.Path),
+					Type: "blob",
+					Path: entry.Path,
+					Mode: entry.Mode,
+					Size: entry.Size,
+				})
+			}
+		}
+
+		if !tree.Truncated || len(tree.Entries) == 0 {
+			break
+		}
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":      owner,
+		"repository": repo,
+		"branch":     targetBranch,
+		"file_count": len(allFiles),
+	}).Debug("Successfully fetched Gitea repository tree")
+	return allFiles, nil
+}
+
+// GetFileContent fetches the content of a specific file
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, filePath, branch string) (string, error) {
+	contents, err := c.getContents(ctx, owner, repo, filePath, branch)
+	if err != nil {
+		return "", err
+	}
+	return decodeContent(contents)
+}
+
+// getContents fetches a file with its metadata
+func (c *Client) getContents(ctx context.Context, owner, repo, filePath, branch string) (*contentsResponse, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":      owner,
+		"repository": repo,
+		"file":       filePath,
+		"branch":     branch,
+	}).Trace("Fetching Gitea file content")
+
+	var query url.Values
+	if branch != "" {
+		query = url.Values{"ref": {branch}}
+	}
+
+	var contents contentsResponse
+	if err := c.get(ctx, repoEndpoint(owner, repo)+"/contents/"+escapePath(filePath), query, &contents); err != nil {
+		logger.Logger.WithError(err).WithFields(map[string]interface{}{
+			"owner":      owner,
+			"repository": repo,
+			"file":       filePath,
+			"branch":     branch,
+		}).Error("Failed to fetch file from Gitea")
+		return nil, fmt.Errorf("failed to fetch file %s: %w", filePath, err)
+	}
+	if contents.Type != "" && contents.Type != "file" {
+		return nil, fmt.Errorf("%s is a %s, not a file", filePath, contents.Type)
+	}
+
+	return &contents, nil
+}
+
+// GetFileInfo fetches file information and content
+func (c *Client) GetFileInfo(ctx context.Context, owner, repo, filePath, branch string) (*models.FileInfo, error) {
+	fileInfo := &models.FileInfo{
+		Path: filePath,
+		Name: extractFileName(filePath),
+	}
+
+	contents, err := c.getContents(ctx, owner, repo, filePath, branch)
+	if err != nil {
+		fileInfo.Error = err
+		return fileInfo, nil
+	}
+
+	content, err := decodeContent(contents)
+	if err != nil {
+		fileInfo.Error = err
+		return fileInfo, nil
+	}
+
+	fileInfo.Content = content
+	fileInfo.ContentSize = int64(len(content))
+	fileInfo.Size = contents.Size
+	if fileInfo.Size == 0 {
+		fileInfo.Size = fileInfo.ContentSize
+	}
+	fileInfo.IsText = isTextFile(content)
+	fileInfo.IsBinary = !fileInfo.IsText
+
+	return fileInfo, nil
+}
+
+// GetMultipleFiles fetches multiple files concurrently, in the order given
+func (c *Client) GetMultipleFiles(ctx context.Context, owner, repo string, filePaths []string, branch string, maxConcurrency int, config *models.ProcessingConfig) ([]models.FileInfo, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":           owner,
+		"repository":      repo,
+		"file_count":      len(filePaths),
+		"max_concurrency": maxConcurrency,
+	}).Debug("Fetching multiple files concurrently from Gitea")
+
+	return fetchpool.Fetch(ctx, filePaths, maxConcurrency, config, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+		return c.GetFileInfo(ctx, owner, repo, filePath, branch)
+	})
+}
+
+// TestConnection tests the Gitea connection and authentication
+func (c *Client) TestConnection(ctx context.Context) error {
+	logger.Logger.WithField("base_url", c.baseURL).Debug("Testing Gitea connection")
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+	}
+	if err := c.get(ctx, "/user", nil, &user); err != nil {
+		logger.Logger.WithError(err).WithField("base_url", c.baseURL).Error("Failed to authenticate with Gitea")
+		return fmt.Errorf("failed to authenticate with Gitea: %w", err)
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"user_id":  user.ID,
+		"username": user.Login,
+		"base_url": c.baseURL,
+	}).Debug("Gitea connection test successful")
+	return nil
+}
+
+// defaultBranch returns the default branch of a repository
+func (c *Client) defaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	var repository repositoryResponse
+	if err := c.get(ctx, repoEndpoint(owner, repo), nil, &repository); err != nil {
+		return "", fmt.Errorf("failed to fetch repository %s/%s: %w", owner, repo, err)
+	}
+	if repository.DefaultBranch == "" {
+		return "main", nil
+	}
+	return repository.DefaultBranch, nil
+}
+
+// get sends an authenticated GET request to an API endpoint and decodes the JSON response
+func (c *Client) get(ctx context.Context, endpoint string, query url.Values, result interface{}) error {
+	requestURL := c.apiURL + endpoint
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			message = apiErr.Message
+		}
+		err := sherpaerrors.FromStatus(resp.StatusCode, fmt.Errorf("GET %s: %d %s", endpoint, resp.StatusCode, message))
+		return sherpaerrors.WithHint(err, errorHint(resp.StatusCode, message))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// Helper functions
+
+// errorHint returns a remediation for a failed API response, or an empty string
+func errorHint(status int, message string) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "the token is invalid, expired or revoked: create a new one and pass it with --token"
+	case http.StatusForbidden:
+		if strings.Contains(message, "required scope") {
+			return "the token lacks a required scope: reading repositories needs read:repository"
+		}
+		return "the token's user cannot access this repository"
+	case http.StatusNotFound:
+		return "check the repository name: Gitea also answers 404 for private repositories the token's user cannot read"
+	}
+	return ""
+}
+
+// repoEndpoint returns the API path of a repository
+func repoEndpoint(owner, repo string) string {
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+// escapePath escapes each segment of a file path
+func escapePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// decodeContent returns the decoded content of a contents API response
+func decodeContent(contents *contentsResponse) (string, error) {
+	if contents.Encoding != "base64" {
+		return contents.Content, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(contents.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode file content: %w", err)
+	}
+	return string(decoded), nil
+}
+
+func extractFileName(path string) string {
+	parts := strings.Split(path, "/")
+	return parts[len(parts)-1]
+}
+
+func isTextFile(content string) bool {
+	// Simple heuristic: if content contains null bytes, it's likely binary
+	for _, b := range []byte(content) {
+		if b == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sherpa/internal/fakevcs"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Refs(t *testing.T) {
+	fixtures := &fakevcs.Fixtures{Repositories: []fakevcs.Repository{{
+		Path:          "owner/tool",
+		Description:   "A tool",
+		DefaultBranch: "trunk",
+		Files: map[string]string{
+			"README.md":   "# tool\n",
+			"src/main.go": "package main\n",
+			"logo.png":    "\x89PNG\x00\x01",
+		},
+	}}}
+	server := fakevcs.NewServer(fixtures)
+	defer server.Close()
+
+	client, err := NewClient(server.GiteaURL(), fakevcs.Token)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("should describe the repository", func(t *testing.T) {
+		repo, err := client.GetRepository(ctx, "owner", "tool")
+		require.NoError(t, err)
+		assert.Equal(t, "owner/tool", repo.PathWithNamespace)
+		assert.Equal(t, "A tool", repo.Description)
+		assert.Equal(t, models.PlatformGitea, repo.Platform)
+	})
+
+	t.Run("should list the files of the default branch", func(t *testing.T) {
+		tree, err := client.GetRepositoryTree(ctx, "owner", "tool", "")
+		require.NoError(t, err)
+
+		var paths []string
+		for _, entry := range tree {
+			assert.Equal(t, "blob", entry.Type)
+			paths = append(paths, entry.Path)
+		}
+		assert.ElementsMatch(t, []string{"README.md", "src/main.go", "logo.png"}, paths)
+	})
+
+	t.Run("should fetch files in the order given", func(t *testing.T) {
+		content, err := client.GetFileContent(ctx, "owner", "tool", "README.md", "")
+		require.NoError(t, err)
+		assert.Equal(t, "# tool\n", content)
+
+		files, err := client.GetMultipleFiles(ctx, "owner", "tool", []string{"src/main.go", "logo.png"}, "trunk", 2, &models.ProcessingConfig{})
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		require.NoError(t, files[0].Error)
+		assert.Equal(t, "package main\n", files[0].Content)
+		assert.Equal(t, int64(len("package main\n")), files[0].Size)
+		assert.True(t, files[0].IsText)
+		assert.True(t, files[1].IsBinary)
+	})
+
+	t.Run("should record missing files on the file", func(t *testing.T) {
+		info, err := client.GetFileInfo(ctx, "owner", "tool", "missing.go", "trunk")
+		require.NoError(t, err)
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(info.Error))
+	})
+
+	t.Run("should resolve the commit of the default branch", func(t *testing.T) {
+		commit, err := client.ResolveCommit(ctx, "owner", "tool", "")
+		require.NoError(t, err)
+		assert.NotEmpty(t, commit)
+
+		pinned, err := client.ResolveCommit(ctx, "owner", "tool", "trunk")
+		require.NoError(t, err)
+		assert.Equal(t, commit, pinned)
+	})
+
+	t.Run("should fail on an unknown branch instead of reading another one", func(t *testing.T) {
+		_, err := client.ResolveCommit(ctx, "owner", "tool", "main")
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(err))
+
+		_, err = client.GetRepositoryTree(ctx, "owner", "tool", "main")
+		assert.Error(t, err)
+
+		_, err = client.GetFileContent(ctx, "owner", "tool", "README.md", "main")
+		assert.Error(t, err)
+	})
+
+	t.Run("should list the repositories of an owner", func(t *testing.T) {
+		repos, err := client.ListRepositories(ctx, "owner")
+		require.NoError(t, err)
+		require.Len(t, repos, 1)
+		assert.Equal(t, "owner/tool", repos[0].FullName)
+		assert.Equal(t, "trunk", repos[0].DefaultBranch)
+		assert.Equal(t, "public", repos[0].Visibility)
+	})
+}
+
+func TestClient_Errors(t *testing.T) {
+	server := fakevcs.NewServer(&fakevcs.Fixtures{})
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("should require a token", func(t *testing.T) {
+		_, err := NewClient(server.GiteaURL(), "")
+		assert.ErrorContains(t, err, "token is required")
+	})
+
+	t.Run("should classify rejected tokens", func(t *testing.T) {
+		client, err := NewClient(server.GiteaURL(), "invalid")
+		require.NoError(t, err)
+
+		err = client.TestConnection(ctx)
+		assert.Equal(t, sherpaerrors.KindAuth, sherpaerrors.KindOf(err))
+		assert.Contains(t, sherpaerrors.HintOf(err), "--token")
+	})
+
+	t.Run("should classify missing repositories", func(t *testing.T) {
+		client, err := NewClient(server.GiteaURL(), fakevcs.Token)
+		require.NoError(t, err)
+		require.NoError(t, client.TestConnection(ctx))
+
+		_, err = client.GetRepository(ctx, "owner", "missing")
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(err))
+		assert.ErrorContains(t, err, "The target couldn't be found.")
+		assert.Contains(t, sherpaerrors.HintOf(err), "private repositories")
+	})
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   sherpaerrors.Kind
+		hint   string
+	}{
+		{name: "should hint at missing token scopes", status: http.StatusForbidden, body: `{"message":"token does not have at least one of required scope(s): [read:repository]"}`, kind: sherpaerrors.KindAuth, hint: "read:repository"},
+		{name: "should classify rate limits", status: http.StatusTooManyRequests, body: `{"message":"rate limit exceeded"}`, kind: sherpaerrors.KindRateLimited},
+		{name: "should keep other failures unclassified", status: http.StatusInternalServerError, body: "internal error", kind: sherpaerrors.KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer api.Close()
+
+			client, err := NewClient(api.URL+"/api/v1/", "secret")
+			require.NoError(t, err)
+
+			_, err = client.GetFileContent(ctx, "owner", "tool", "README.md", "")
+			require.Error(t, err)
+			assert.Equal(t, tt.kind, sherpaerrors.KindOf(err))
+			assert.Equal(t, tt.hint != "", sherpaerrors.HintOf(err) != "")
+			assert.Contains(t, sherpaerrors.HintOf(err), tt.hint)
+		})
+	}
+}
@@ -2,10 +2,13 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
-	"time"
 
+	"sherpa/internal/adapters/fetchpool"
+	sherpaerrors "sherpa/pkg/errors"
 	"sherpa/pkg/logger"
 	"sherpa/pkg/models"
 
@@ -18,10 +21,17 @@ type Client struct {
 	client  *github.Client
 	baseURL string
 	token   string
+	graphQL bool // Fetch file contents by batches with the GraphQL API
 }
 
 // NewClient creates a new GitHub client
 func NewClient(baseURL, token string) (*Client, error) {
+	return NewClientWithTransport(baseURL, token, nil)
+}
+
+// NewClientWithTransport creates a GitHub client sending requests through transport,
+// http.DefaultTransport when nil
+func NewClientWithTransport(baseURL, token string, transport http.RoundTripper) (*Client, error) {
 	if token == "" {
 		return nil, fmt.Errorf("GitHub token is required")
 	}
@@ -34,7 +44,11 @@ func NewClient(baseURL, token string) (*Client, error) {
 	tokenSource := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	oauth2Client := oauth2.NewClient(context.Background(), tokenSource)
+	ctx := context.Background()
+	if transport != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: transport})
+	}
+	oauth2Client := oauth2.NewClient(ctx, tokenSource)
 
 	// Create GitHub client
 	client := github.NewClient(oauth2Client)
@@ -74,7 +88,7 @@ func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*models
 			"owner":      owner,
 			"repository": repo,
 		}).Error("Failed to fetch GitHub repository")
-		return nil, fmt.Errorf("failed to fetch repository %s/%s: %w", owner, repo, err)
+		return nil, fmt.Errorf("failed to fetch repository %s/%s: %w", owner, repo, classifyError(err))
 	}
 
 	return &models.Repository{
@@ -89,6 +103,141 @@ func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*models
 	}, nil
 }
 
+// ListRepositories lists the repositories of an organization, or of a user when no
+// organization has that name
+func (c *Client) ListRepositories(ctx context.Context, owner string) ([]models.ListedRepository, error) {
+	logger.Logger.WithField("owner", owner).Debug("Listing GitHub repositories")
+
+	var repos []models.ListedRepository
+	orgOptions := &github.RepositoryListByOrgOptions{Type: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := c.client.Repositories.ListByOrg(ctx, owner, orgOptions)
+		if err != nil {
+			if sherpaerrors.KindOf(classifyError(err)) == sherpaerrors.KindNotFound && len(repos) == 0 {
+				return c.listUserRepositories(ctx, owner)
+			}
+			return nil, fmt.Errorf("failed to list repositories of %s: %w", owner, classifyError(err))
+		}
+		repos = append(repos, listedRepositories(page)...)
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+		orgOptions.Page = resp.NextPage
+	}
+}
+
+// listUserRepositories lists the repositories owned by a user
+func (c *Client) listUserRepositories(ctx context.Context, user string) ([]models.ListedRepository, error) {
+	var repos []models.ListedRepository
+	userOptions := &github.RepositoryListByUserOptions{Type: "owner", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := c.client.Repositories.ListByUser(ctx, user, userOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of %s: %w", user, classifyError(err))
+		}
+		repos = append(repos, listedRepositories(page)...)
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+		userOptions.Page = resp.NextPage
+	}
+}
+
+func listedRepositories(page []*github.Repository) []models.ListedRepository {
+	repos := make([]models.ListedRepository, 0, len(page))
+	for _, repository := range page {
+		visibility := repository.GetVisibility()
+		if visibility == "" {
+			visibility = "public"
+			if repository.GetPrivate() {
+				visibility = "private"
+			}
+		}
+		repos = append(repos, models.ListedRepository{
+			FullName:      repository.GetFullName(),
+			DefaultBranch: repository.GetDefaultBranch(),
+			Archived:      repository.GetArchived(),
+			Fork:          repository.GetFork(),
+			Visibility:    visibility,
+			Topics:        repository.Topics,
+		})
+	}
+	return repos
+}
+
+// ResolveCommit returns the commit SHA a ref points to, using the default branch when ref is empty
+func (c *Client) ResolveCommit(ctx context.Context, owner, repo, ref string) (string, error) {
+	if ref == "" {
+		repository, _, err := c.client.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch repository %s/%s: %w", owner, repo, classifyError(err))
+		}
+		ref = repository.GetDefaultBranch()
+	}
+
+	sha, _, err := c.client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err != nil {
+		logger.Logger.WithError(err).WithFields(map[string]interface{}{
+			"owner":      owner,
+			"repository": repo,
+			"ref":        ref,
+		}).Error("Failed to resolve GitHub commit")
+		return "", fmt.Errorf("failed to resolve commit for %s: %w", ref, classifyError(err))
+	}
+
+	return sha, nil
+}
+
+// maxCompareFiles is the number of files GitHub lists at most in a comparison
+const maxCompareFiles = 300
+
+// ChangedFiles lists the paths of files added, modified, removed or renamed between two
+// commits, including the previous path of renamed files. It fails when head does not
+// descend from base or when GitHub may have truncated the list.
+func (c *Client) ChangedFiles(ctx context.Context, owner, repo, base, head string) ([]string, error) {
+	comparison, _, err := c.client.Repositories.CompareCommits(ctx, owner, repo, base, head, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, classifyError(err))
+	}
+
+	// Other statuses compare against a merge base, missing changes only found in base
+	switch comparison.GetStatus() {
+	case "identical", "ahead":
+	default:
+		return nil, fmt.Errorf("commit %s does not descend from %s (%s)", head, base, comparison.GetStatus())
+	}
+	if len(comparison.Files) >= maxCompareFiles {
+		return nil, fmt.Errorf("comparison %s...%s lists %d files or more and may be truncated", base, head, maxCompareFiles)
+	}
+
+	var paths []string
+	for _, file := range comparison.Files {
+		paths = append(paths, file.GetFilename())
+		if file.GetPreviousFilename() != "" {
+			paths = append(paths, file.GetPreviousFilename())
+		}
+	}
+	return paths, nil
+}
+
+// CompareRefs lists the files changed from the merge base of base to head, with their
+// diffs. It fails when GitHub may have truncated the list.
+func (c *Client) CompareRefs(ctx context.Context, owner, repo, base, head string) (*models.Comparison, error) {
+	comparison, _, err := c.client.Repositories.CompareCommits(ctx, owner, repo, base, head, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, classifyError(err))
+	}
+	if len(comparison.Files) >= maxCompareFiles {
+		return nil, fmt.Errorf("comparison %s...%s lists %d files or more and may be truncated", base, head, maxCompareFiles)
+	}
+
+	result := &models.Comparison{Base: base, Head: head, Commits: comparison.GetTotalCommits()}
+	for _, file := range comparison.Files {
+		result.Files = append(result.Files, changedFile(file))
+	}
+	return result, nil
+}
+
 // GetRepositoryTree fetches the complete repository tree structure
 func (c *Client) GetRepositoryTree(ctx context.Context, owner, repo, branch string) ([]models.RepositoryTree, error) {
 	logger.Logger.WithFields(map[string]interface{}{
@@ -103,7 +252,7 @@ func (c *Client) GetRepositoryTree(ctx context.Context, owner, repo, branch stri
 		// Get default branch first
 		repository, _, err := c.client.Repositories.Get(ctx, owner, repo)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get repository info: %w", err)
+			return nil, fmt.Errorf("failed to get repository info: %w", classifyError(err))
 		}
 		targetBranch = repository.GetDefaultBranch()
 		if targetBranch == "" {
@@ -136,7 +285,7 @@ func (c *Client) GetRepositoryTree(ctx context.Context, owner, repo, branch stri
 				"repository": repo,
 				"branch":     branch,
 			}).Error("Failed to fetch GitHub repository tree")
-			return nil, fmt.Errorf("failed to fetch repository tree: %w", err)
+			return nil, fmt.Errorf("failed to fetch repository tree: %w", classifyError(err))
 		}
 	}
 
@@ -149,6 +298,7 @@ func (c *Client) GetRepositoryTree(ctx context.Context, owner, repo, branch stri
 				Type: "blob",
 				Path: entry.GetPath(),
 				Mode: entry.GetMode(),
+				Size: int64(entry.GetSize()),
 			}
 			allFiles = append(allFiles, file)
 		}
@@ -163,14 +313,80 @@ func (c *Client) GetRepositoryTree(ctx context.Context, owner, repo, branch stri
 	return allFiles, nil
 }
 
+// ListSubtree lists the files of the directory dir at branch, or the default branch when it
+// is empty, and of its subdirectories when recursive. Paths are relative to the repository
+// root, and a missing directory has no files.
+func (c *Client) ListSubtree(ctx context.Context, owner, repo, branch, dir string, recursive bool) ([]models.RepositoryTree, error) {
+	targetBranch := branch
+	if targetBranch == "" {
+		repository, _, err := c.client.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repository info: %w", classifyError(err))
+		}
+		targetBranch = repository.GetDefaultBranch()
+		if targetBranch == "" {
+			targetBranch = "main"
+		}
+	}
+
+	dir = strings.Trim(dir, "/")
+	sha := targetBranch
+	if dir != "" {
+		sha += ":" + dir
+	}
+	tree, _, err := c.client.Git.GetTree(ctx, owner, repo, sha, recursive)
+	if err != nil {
+		err = classifyError(err)
+		if dir != "" && sherpaerrors.KindOf(err) == sherpaerrors.KindNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch repository tree for %s at %s: %w", dir, targetBranch, err)
+	}
+
+	var files []models.RepositoryTree
+	for _, entry := range tree.Entries {
+		if entry.GetType() != "blob" {
+			continue
+		}
+		path := entry.GetPath()
+		if dir != "" {
+			path = dir + "/" + path
+		}
+		files = append(files, models.RepositoryTree{
+			ID:   entry.GetSHA(),
+			Name: extractFileName(path),
+			Type: "blob",
+			Path: path,
+			Mode: entry.GetMode(),
+			Size: int64(entry.GetSize()),
+		})
+	}
+	return files, nil
+}
+
 // GetFileContent fetches the content of a specific file
 func (c *Client) GetFileContent(ctx context.Context, owner, repo, filePath, branch string) (string, error) {
+	fileContent, err := c.getContents(ctx, owner, repo, filePath, branch)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode file content: %w", err)
+	}
+
+	return content, nil
+}
+
+// getContents fetches a file with its metadata, falling back to the default branch
+func (c *Client) getContents(ctx context.Context, owner, repo, filePath, branch string) (*github.RepositoryContent, error) {
 	logger.Logger.WithFields(map[string]interface{}{
 		"owner":      owner,
 		"repository": repo,
 		"file":       filePath,
 		"branch":     branch,
-	}).Debug("Fetching GitHub file content")
+	}).Trace("Fetching GitHub file content")
 
 	// Prepare options with branch if specified
 	opts := &github.RepositoryContentGetOptions{}
@@ -187,7 +403,7 @@ func (c *Client) GetFileContent(ctx context.Context, owner, repo, filePath, bran
 				"repository": repo,
 				"file":       filePath,
 				"branch":     branch,
-			}).Debug("Branch-specific file fetch failed, trying default branch")
+			}).Trace("Branch-specific file fetch failed, trying default branch")
 
 			fileContent, _, _, err = c.client.Repositories.GetContents(ctx, owner, repo, filePath, nil)
 		}
@@ -199,20 +415,15 @@ func (c *Client) GetFileContent(ctx context.Context, owner, repo, filePath, bran
 				"file":       filePath,
 				"branch":     branch,
 			}).Error("Failed to fetch file from GitHub")
-			return "", fmt.Errorf("failed to fetch file %s: %w", filePath, err)
+			return nil, fmt.Errorf("failed to fetch file %s: %w", filePath, classifyError(err))
 		}
 	}
 
 	if fileContent == nil {
-		return "", fmt.Errorf("file content is nil")
+		return nil, fmt.Errorf("file content is nil")
 	}
 
-	content, err := fileContent.GetContent()
-	if err != nil {
-		return "", fmt.Errorf("failed to decode file content: %w", err)
-	}
-
-	return content, nil
+	return fileContent, nil
 }
 
 // GetFileInfo fetches file information and content
@@ -223,21 +434,31 @@ func (c *Client) GetFileInfo(ctx context.Context, owner, repo, filePath, branch
 	}
 
 	// Get file content
-	content, err := c.GetFileContent(ctx, owner, repo, filePath, branch)
+	fileContent, err := c.getContents(ctx, owner, repo, filePath, branch)
 	if err != nil {
 		fileInfo.Error = err
 		return fileInfo, nil
 	}
 
+	content, err := fileContent.GetContent()
+	if err != nil {
+		fileInfo.Error = fmt.Errorf("failed to decode file content: %w", err)
+		return fileInfo, nil
+	}
+
 	fileInfo.Content = content
-	fileInfo.Size = int64(len(content))
+	fileInfo.ContentSize = int64(len(content))
+	fileInfo.Size = int64(fileContent.GetSize())
+	if fileInfo.Size == 0 {
+		fileInfo.Size = fileInfo.ContentSize
+	}
 	fileInfo.IsText = isTextFile(content)
 	fileInfo.IsBinary = !fileInfo.IsText
 
 	return fileInfo, nil
 }
 
-// GetMultipleFiles fetches multiple files concurrently with rate limiting
+// GetMultipleFiles fetches multiple files concurrently, in the order given
 func (c *Client) GetMultipleFiles(ctx context.Context, owner, repo string, filePaths []string, branch string, maxConcurrency int, config *models.ProcessingConfig) ([]models.FileInfo, error) {
 	logger.Logger.WithFields(map[string]interface{}{
 		"owner":           owner,
@@ -246,52 +467,66 @@ func (c *Client) GetMultipleFiles(ctx context.Context, owner, repo string, fileP
 		"max_concurrency": maxConcurrency,
 	}).Debug("Fetching multiple files concurrently from GitHub")
 
-	if maxConcurrency <= 0 {
-		maxConcurrency = 5 // Default concurrency
+	if c.graphQL {
+		return c.getMultipleFilesGraphQL(ctx, owner, repo, filePaths, branch, maxConcurrency, config)
 	}
+	return fetchpool.Fetch(ctx, filePaths, maxConcurrency, config, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+		return c.GetFileInfo(ctx, owner, repo, filePath, branch)
+	})
+}
 
-	// Add resource limits for security
-	maxMemoryPerFile := config.MaxMemoryPerFile
-	maxTotalMemory := config.MaxTotalMemory
-	maxFiles := config.MaxFiles
-
-	if len(filePaths) > maxFiles {
-		return nil, fmt.Errorf("too many files to process safely: %d (max: %d)", len(filePaths), maxFiles)
+// SearchCode returns the paths of files matching a code search query in the repository at
+// branch, or at the default branch when it is empty. GitHub code search only indexes the
+// default branch, so searching another branch fails rather than selecting the paths that
+// match on the default branch.
+func (c *Client) SearchCode(ctx context.Context, owner, repo, query, branch string) ([]string, error) {
+	if branch != "" {
+		repository, _, err := c.client.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repository %s/%s: %w", owner, repo, classifyError(err))
+		}
+		if defaultBranch := repository.GetDefaultBranch(); branch != defaultBranch {
+			return nil, fmt.Errorf("GitHub code search only indexes the default branch %s, so --match cannot search %s", defaultBranch, branch)
+		}
 	}
 
-	if int64(len(filePaths))*maxMemoryPerFile > maxTotalMemory {
-		return nil, fmt.Errorf("estimated memory usage too high for %d files", len(filePaths))
-	}
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":      owner,
+		"repository": repo,
+		"query":      query,
+	}).Debug("Searching GitHub code")
 
-	semaphore := make(chan struct{}, maxConcurrency)
-	results := make(chan models.FileInfo, len(filePaths))
+	fullQuery := fmt.Sprintf("%s repo:%s/%s", query, owner, repo)
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
 
-	// Start workers
-	for _, filePath := range filePaths {
-		go func(path string) {
-			semaphore <- struct{}{}        // Acquire
-			defer func() { <-semaphore }() // Release
+	seen := make(map[string]bool)
+	var paths []string
+	for {
+		result, resp, err := c.client.Search.Code(ctx, fullQuery, opts)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(map[string]interface{}{
+				"owner":      owner,
+				"repository": repo,
+				"query":      query,
+			}).Error("Failed to search GitHub code")
+			return nil, fmt.Errorf("failed to search code: %w", classifyError(err))
+		}
 
-			fileInfo, err := c.GetFileInfo(ctx, owner, repo, path, branch)
-			if err != nil {
-				fileInfo = &models.FileInfo{
-					Path:  path,
-					Name:  extractFileName(path),
-					Error: err,
-				}
+		for _, codeResult := range result.CodeResults {
+			path := codeResult.GetPath()
+			if path != "" && !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
 			}
-			results <- *fileInfo
-		}(filePath)
-	}
+		}
 
-	// Collect results
-	var files []models.FileInfo
-	for i := 0; i < len(filePaths); i++ {
-		files = append(files, <-results)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
 	}
 
-	close(results)
-	return files, nil
+	return paths, nil
 }
 
 // TestConnection tests the GitHub connection and authentication
@@ -317,7 +552,7 @@ func (c *Client) TestConnection(ctx context.Context) error {
 				return nil
 			}(),
 		}).Error("Failed to authenticate with GitHub")
-		return fmt.Errorf("failed to authenticate with GitHub: %w", err)
+		return fmt.Errorf("failed to authenticate with GitHub: %w", classifyError(err))
 	}
 
 	if user == nil {
@@ -350,85 +585,23 @@ func isTextFile(content string) bool {
 	return true
 }
 
-// GetRateLimitInfo returns current rate limit information
-func (c *Client) GetRateLimitInfo(ctx context.Context) (*RateLimitInfo, error) {
-	rateLimits, _, err := c.client.RateLimit.Get(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	return &RateLimitInfo{
-		Limit:     rateLimits.Core.Limit,
-		Remaining: rateLimits.Core.Remaining,
-		ResetTime: rateLimits.Core.Reset.Time,
-	}, nil
-}
-
-// RateLimitInfo contains rate limiting information
-type RateLimitInfo struct {
-	Limit     int
-	Remaining int
-	ResetTime time.Time
-}
-
-// WithRetry executes a function with exponential backoff retry logic
-func (c *Client) WithRetry(ctx context.Context, maxRetries int, fn func() error) error {
-	var lastErr error
-
-	for i := 0; i <= maxRetries; i++ {
-		if i > 0 {
-			// Exponential backoff
-			backoff := time.Duration(i*i) * time.Second
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(backoff):
-			}
-		}
-
-		if err := fn(); err != nil {
-			lastErr = err
-
-			// Check if it's a rate limit error
-			if isRateLimitError(err) {
-				continue
-			}
-
-			// Check if it's a temporary network error
-			if isTemporaryError(err) {
-				continue
-			}
-
-			// For other errors, don't retry
-			return err
-		}
-
-		return nil
-	}
-
-	return fmt.Errorf("max retries exceeded: %w", lastErr)
-}
-
-func isRateLimitError(err error) bool {
+// classifyError maps GitHub API errors onto the shared error taxonomy, with a remediation
+// hint for authorization failures and missing repositories
+func classifyError(err error) error {
 	if err == nil {
-		return false
+		return nil
 	}
-	return strings.Contains(err.Error(), "rate limit") ||
-		strings.Contains(err.Error(), "403") ||
-		strings.Contains(err.Error(), "API rate limit exceeded")
-}
 
-func isTemporaryError(err error) bool {
-	if err == nil {
-		return false
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return sherpaerrors.Wrap(sherpaerrors.KindRateLimited, err)
 	}
 
-	// Check for common temporary HTTP errors
-	if strings.Contains(err.Error(), "timeout") ||
-		strings.Contains(err.Error(), "connection reset") ||
-		strings.Contains(err.Error(), "temporary failure") {
-		return true
+	var responseErr *github.ErrorResponse
+	if errors.As(err, &responseErr) && responseErr.Response != nil {
+		return sherpaerrors.WithHint(sherpaerrors.FromStatus(responseErr.Response.StatusCode, err), errorHint(responseErr.Response))
 	}
 
-	return false
+	return err
 }
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"sherpa/internal/fakevcs"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ListSubtree(t *testing.T) {
+	fixtures := &fakevcs.Fixtures{Repositories: []fakevcs.Repository{{
+		Path: "owner/project",
+		Files: map[string]string{
+			"README.md":      "# project\n",
+			"src/main.go":    "package main\n",
+			"src/api/api.go": "package api\n",
+			"docs/guide.md":  "# guide\n",
+		},
+	}}}
+	server := fakevcs.NewServer(fixtures)
+	defer server.Close()
+
+	client, err := NewClient(server.GitHubURL(), fakevcs.Token)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	paths := func(entries []models.RepositoryTree) []string {
+		var paths []string
+		for _, entry := range entries {
+			paths = append(paths, entry.Path)
+		}
+		return paths
+	}
+
+	t.Run("should list the files of a directory recursively with their full path", func(t *testing.T) {
+		entries, err := client.ListSubtree(ctx, "owner", "project", "", "src", true)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"src/main.go", "src/api/api.go"}, paths(entries))
+		for _, entry := range entries {
+			assert.Equal(t, path.Base(entry.Path), entry.Name)
+		}
+	})
+
+	t.Run("should list only the files at the root of the repository", func(t *testing.T) {
+		entries, err := client.ListSubtree(ctx, "owner", "project", "main", "", false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md"}, paths(entries))
+	})
+
+	t.Run("should list nothing for a missing directory", func(t *testing.T) {
+		entries, err := client.ListSubtree(ctx, "owner", "project", "main", "missing", true)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestClient_SearchCode(t *testing.T) {
+	fixtures := &fakevcs.Fixtures{Repositories: []fakevcs.Repository{{
+		Path:          "owner/project",
+		DefaultBranch: "trunk",
+		Files:         map[string]string{"retry.go": "package retry\n"},
+	}}}
+	server := fakevcs.NewServer(fixtures)
+	defer server.Close()
+
+	client, err := NewClient(server.GitHubURL(), fakevcs.Token)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("should search the default branch", func(t *testing.T) {
+		for _, branch := range []string{"", "trunk"} {
+			paths, err := client.SearchCode(ctx, "owner", "project", "retry", branch)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"retry.go"}, paths)
+		}
+	})
+
+	t.Run("should fail on other branches, which code search does not index", func(t *testing.T) {
+		_, err := client.SearchCode(ctx, "owner", "project", "retry", "feature")
+		assert.ErrorContains(t, err, "only indexes the default branch trunk")
+	})
+}
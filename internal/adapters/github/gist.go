@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// GetGist fetches a gist and the content of its files in a single request. Files the API
+// truncates are downloaded from their raw URL.
+func (c *Client) GetGist(ctx context.Context, gistID string) (*models.Repository, []models.FileInfo, error) {
+	logger.Logger.WithField("gist", gistID).Debug("Fetching GitHub gist")
+
+	gist, _, err := c.client.Gists.Get(ctx, gistID)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("gist", gistID).Error("Failed to fetch GitHub gist")
+		return nil, nil, fmt.Errorf("failed to fetch gist %s: %w", gistID, classifyError(err))
+	}
+
+	owner := gist.GetOwner().GetLogin()
+	name := gist.GetDescription()
+	if name == "" {
+		name = gistID
+	}
+	repository := &models.Repository{
+		ID:                gist.GetID(),
+		Name:              name,
+		Path:              gistID,
+		PathWithNamespace: owner + "/" + gistID,
+		WebURL:            gist.GetHTMLURL(),
+		Description:       gist.GetDescription(),
+		Platform:          models.PlatformGitHub,
+		Owner:             owner,
+	}
+
+	files := make([]models.FileInfo, 0, len(gist.Files))
+	for filename, gistFile := range gist.Files {
+		fileInfo := models.FileInfo{
+			Path: string(filename),
+			Name: string(filename),
+			Size: int64(gistFile.GetSize()),
+		}
+
+		content := gistFile.GetContent()
+		if len(content) < gistFile.GetSize() && gistFile.GetRawURL() != "" {
+			content, err = c.getRaw(ctx, gistFile.GetRawURL())
+			if err != nil {
+				fileInfo.Error = err
+				files = append(files, fileInfo)
+				continue
+			}
+		}
+
+		fileInfo.Content = content
+		fileInfo.ContentSize = int64(len(content))
+		if fileInfo.Size == 0 {
+			fileInfo.Size = fileInfo.ContentSize
+		}
+		fileInfo.IsText = isTextFile(content)
+		fileInfo.IsBinary = !fileInfo.IsText
+		files = append(files, fileInfo)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return repository, files, nil
+}
+
+// getRaw downloads a raw file through the authenticated HTTP client
+func (c *Client) getRaw(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", sherpaerrors.FromStatus(resp.StatusCode, fmt.Errorf("failed to download %s: %s", rawURL, resp.Status))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,160 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"sherpa/internal/adapters/fetchpool"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// graphQLBatchSize is the number of file blobs fetched by a single GraphQL request
+const graphQLBatchSize = 50
+
+// EnableGraphQL fetches file contents with the GraphQL API, many blobs per request, instead
+// of one REST request per file
+func (c *Client) EnableGraphQL() {
+	c.graphQL = true
+}
+
+// graphQLBlob is a file blob as returned by a GraphQL object expression
+type graphQLBlob struct {
+	Text        *string `json:"text"`
+	ByteSize    int64   `json:"byteSize"`
+	IsBinary    bool    `json:"isBinary"`
+	IsTruncated bool    `json:"isTruncated"`
+}
+
+// graphQLResponse is the response to a batch of object expressions, keyed by alias
+type graphQLResponse struct {
+	Data struct {
+		Repository map[string]*graphQLBlob `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// getMultipleFilesGraphQL fetches files by batches of graphQLBatchSize blobs, one request
+// per batch, with at most maxConcurrency batches in flight. Files the GraphQL API cannot
+// serve, missing at the ref or truncated because they are large, are fetched through the
+// REST API.
+func (c *Client) getMultipleFilesGraphQL(ctx context.Context, owner, repo string, filePaths []string, branch string, maxConcurrency int, config *models.ProcessingConfig) ([]models.FileInfo, error) {
+	return fetchpool.FetchBatches(ctx, filePaths, graphQLBatchSize, maxConcurrency, config, func(ctx context.Context, batch []string) ([]*models.FileInfo, error) {
+		blobs, err := c.getBlobs(ctx, owner, repo, batch, branch)
+		if err != nil {
+			return nil, err
+		}
+
+		files := make([]*models.FileInfo, len(batch))
+		for i, filePath := range batch {
+			files[i] = c.blobFileInfo(ctx, owner, repo, filePath, branch, blobs[filePath])
+			// The content is now held by its file only
+			delete(blobs, filePath)
+		}
+		return files, nil
+	})
+}
+
+// blobFileInfo builds the file info of a GraphQL blob, falling back to the REST API when the
+// blob is missing or has no text
+func (c *Client) blobFileInfo(ctx context.Context, owner, repo, filePath, branch string, blob *graphQLBlob) *models.FileInfo {
+	if blob == nil || blob.IsTruncated || (!blob.IsBinary && blob.Text == nil) {
+		fileInfo, err := c.GetFileInfo(ctx, owner, repo, filePath, branch)
+		if err != nil {
+			return &models.FileInfo{Path: filePath, Name: extractFileName(filePath), Error: err}
+		}
+		return fileInfo
+	}
+	fileInfo := &models.FileInfo{
+		Path:     filePath,
+		Name:     extractFileName(filePath),
+		Size:     blob.ByteSize,
+		IsBinary: blob.IsBinary,
+		IsText:   !blob.IsBinary,
+	}
+	if blob.Text != nil {
+		fileInfo.Content = *blob.Text
+		fileInfo.ContentSize = int64(len(fileInfo.Content))
+	}
+	return fileInfo
+}
+
+// getBlobs fetches the blobs of files at branch, the default branch when empty, in a single
+// GraphQL request. Files missing at the ref have no blob.
+func (c *Client) getBlobs(ctx context.Context, owner, repo string, filePaths []string, branch string) (map[string]*graphQLBlob, error) {
+	ref := branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// Expressions are passed as variables so paths need no escaping
+	variables := map[string]interface{}{"owner": owner, "name": repo}
+	var declarations, objects strings.Builder
+	for i, filePath := range filePaths {
+		variables[fmt.Sprintf("e%d", i)] = ref + ":" + filePath
+		declarations.WriteString(fmt.Sprintf(", $e%d: String!", i))
+		objects.WriteString(fmt.Sprintf("f%d: object(expression: $e%d) { ... on Blob { text byteSize isBinary isTruncated } }\n", i, i))
+	}
+	query := fmt.Sprintf("query($owner: String!, $name: String!%s) {\nrepository(owner: $owner, name: $name) {\n%s}\n}", declarations.String(), objects.String())
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":      owner,
+		"repository": repo,
+		"file_count": len(filePaths),
+		"branch":     branch,
+	}).Trace("Fetching GitHub file blobs with GraphQL")
+
+	req, err := c.client.NewRequest(http.MethodPost, c.graphQLURL(), map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	var response graphQLResponse
+	if _, err := c.client.Do(ctx, req, &response); err != nil {
+		return nil, fmt.Errorf("failed to fetch files with GraphQL: %w", classifyError(err))
+	}
+	if response.Data.Repository == nil {
+		return nil, graphQLError(owner, repo, response)
+	}
+
+	blobs := make(map[string]*graphQLBlob, len(filePaths))
+	for i, filePath := range filePaths {
+		blobs[filePath] = response.Data.Repository[fmt.Sprintf("f%d", i)]
+	}
+	return blobs, nil
+}
+
+// graphQLError reports the errors of a GraphQL response without a repository, classifying
+// a missing repository as not found
+func graphQLError(owner, repo string, response graphQLResponse) error {
+	var messages []string
+	notFound := false
+	for _, graphQLErr := range response.Errors {
+		messages = append(messages, graphQLErr.Message)
+		notFound = notFound || graphQLErr.Type == "NOT_FOUND"
+	}
+	if len(messages) == 0 {
+		messages = append(messages, "no repository in the response")
+	}
+	err := fmt.Errorf("failed to fetch files of %s/%s with GraphQL: %s", owner, repo, strings.Join(messages, "; "))
+	if notFound {
+		return sherpaerrors.Wrap(sherpaerrors.KindNotFound, err)
+	}
+	return err
+}
+
+// graphQLURL returns the GraphQL endpoint: /graphql on github.com, and /api/graphql next to
+// the /api/v3 REST API of GitHub Enterprise Server
+func (c *Client) graphQLURL() string {
+	base := c.client.BaseURL
+	if prefix, found := strings.CutSuffix(base.Path, "/api/v3/"); found {
+		return base.ResolveReference(&url.URL{Path: prefix + "/api/graphql"}).String()
+	}
+	return base.ResolveReference(&url.URL{Path: "graphql"}).String()
+}
@@ -0,0 +1,27 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GraphQLURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		expected string
+	}{
+		{name: "should use the GraphQL endpoint of github.com", baseURL: "", expected: "https://api.github.com/graphql"},
+		{name: "should use the GraphQL endpoint of GitHub Enterprise Server", baseURL: "https://github.example.com/api/v3/", expected: "https://github.example.com/api/graphql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.baseURL, "token")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, client.graphQLURL())
+		})
+	}
+}
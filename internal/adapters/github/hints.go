@@ -0,0 +1,87 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// errorHint returns a remediation for a failed GitHub API response, read from its status
+// and the headers GitHub sets on authorization failures, or an empty string
+func errorHint(response *http.Response) string {
+	switch response.StatusCode {
+	case http.StatusUnauthorized:
+		return "the token is invalid, expired or revoked: create a new one and pass it with --token"
+	case http.StatusForbidden:
+		if sso := response.Header.Get("X-GitHub-SSO"); strings.HasPrefix(sso, "required") {
+			return ssoHint(sso)
+		}
+		if hint := scopeHint(response.Header.Get("X-Accepted-OAuth-Scopes"), response.Header.Get("X-OAuth-Scopes")); hint != "" {
+			return hint
+		}
+		if permissions := response.Header.Get("X-Accepted-GitHub-Permissions"); permissions != "" {
+			return fmt.Sprintf("the fine-grained token needs the %s permissions on this repository", permissions)
+		}
+		return "the token cannot access this resource: check its scopes, or that its owner can read the repository"
+	case http.StatusNotFound:
+		return "check the repository name: GitHub also answers 404 for private repositories the token cannot read, which needs the repo scope"
+	}
+	return ""
+}
+
+// ssoHint asks to authorize the token for the organization named in an X-GitHub-SSO header
+// like "required; url=https://github.com/orgs/acme/sso?authorization_request=..."
+func ssoHint(header string) string {
+	_, rawURL, found := strings.Cut(header, "url=")
+	if !found {
+		return "authorize the token for SAML single sign-on of the organization"
+	}
+	rawURL = strings.TrimSpace(rawURL)
+
+	organization := "the organization"
+	if parsed, err := url.Parse(rawURL); err == nil {
+		parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		if len(parts) >= 2 && parts[0] == "orgs" {
+			organization = "organization " + parts[1]
+		}
+	}
+	return fmt.Sprintf("authorize the token for SAML single sign-on of %s at %s", organization, rawURL)
+}
+
+// scopeHint names the scopes a classic token lacks, from the X-Accepted-OAuth-Scopes and
+// X-OAuth-Scopes headers, when it has none of the accepted ones
+func scopeHint(accepted, granted string) string {
+	acceptedScopes := splitScopes(accepted)
+	if len(acceptedScopes) == 0 {
+		return ""
+	}
+	grantedScopes := splitScopes(granted)
+	for _, scope := range acceptedScopes {
+		for _, have := range grantedScopes {
+			if scope == have {
+				return ""
+			}
+		}
+	}
+
+	lacking := fmt.Sprintf("the %s scope", acceptedScopes[0])
+	if len(acceptedScopes) > 1 {
+		lacking = fmt.Sprintf("one of the %s scopes", strings.Join(acceptedScopes, ", "))
+	}
+	if len(grantedScopes) == 0 {
+		return fmt.Sprintf("the token lacks %s", lacking)
+	}
+	return fmt.Sprintf("the token lacks %s (it has %s)", lacking, strings.Join(grantedScopes, ", "))
+}
+
+// splitScopes splits a comma-separated scope header
+func splitScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		expected string
+	}{
+		{
+			name:     "should ask for a new token on 401",
+			status:   http.StatusUnauthorized,
+			expected: "the token is invalid, expired or revoked: create a new one and pass it with --token",
+		},
+		{
+			name:     "should ask to authorize SSO for the organization",
+			status:   http.StatusForbidden,
+			header:   http.Header{"X-Github-Sso": {"required; url=https://github.com/orgs/acme/sso?authorization_request=abc"}},
+			expected: "authorize the token for SAML single sign-on of organization acme at https://github.com/orgs/acme/sso?authorization_request=abc",
+		},
+		{
+			name:     "should name the scope the token lacks",
+			status:   http.StatusForbidden,
+			header:   http.Header{"X-Accepted-Oauth-Scopes": {"repo"}, "X-Oauth-Scopes": {"read:org, gist"}},
+			expected: "the token lacks the repo scope (it has read:org, gist)",
+		},
+		{
+			name:     "should list the accepted scopes when several are",
+			status:   http.StatusForbidden,
+			header:   http.Header{"X-Accepted-Oauth-Scopes": {"repo, public_repo"}, "X-Oauth-Scopes": {""}},
+			expected: "the token lacks one of the repo, public_repo scopes",
+		},
+		{
+			name:     "should name the permissions of fine-grained tokens",
+			status:   http.StatusForbidden,
+			header:   http.Header{"X-Accepted-Github-Permissions": {"contents=read"}},
+			expected: "the fine-grained token needs the contents=read permissions on this repository",
+		},
+		{
+			name:     "should fall back to a generic hint when the token has an accepted scope",
+			status:   http.StatusForbidden,
+			header:   http.Header{"X-Accepted-Oauth-Scopes": {"repo"}, "X-Oauth-Scopes": {"repo"}},
+			expected: "the token cannot access this resource: check its scopes, or that its owner can read the repository",
+		},
+		{
+			name:     "should explain that private repositories answer 404",
+			status:   http.StatusNotFound,
+			expected: "check the repository name: GitHub also answers 404 for private repositories the token cannot read, which needs the repo scope",
+		},
+		{
+			name:     "should not hint at server errors",
+			status:   http.StatusBadGateway,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			assert.Equal(t, tt.expected, errorHint(&http.Response{StatusCode: tt.status, Header: header}))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// ListIssues lists the issues of a repository selected by config, most recently updated
+// first. Pull requests, which GitHub lists as issues, are left out.
+func (c *Client) ListIssues(ctx context.Context, owner, repo string, config models.IssuesConfig) ([]models.Issue, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":      owner,
+		"repository": repo,
+		"state":      config.State,
+		"labels":     config.Labels,
+	}).Debug("Listing GitHub issues")
+
+	options := &github.IssueListByRepoOptions{
+		State:       config.State,
+		Labels:      config.Labels,
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: min(max(config.Limit, 1), 100)},
+	}
+	var issues []models.Issue
+	for {
+		page, resp, err := c.client.Issues.ListByRepo(ctx, owner, repo, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", classifyError(err))
+		}
+		for _, issue := range page {
+			if issue.IsPullRequest() {
+				continue
+			}
+			issues = append(issues, models.Issue{
+				Number:    issue.GetNumber(),
+				Title:     issue.GetTitle(),
+				Body:      issue.GetBody(),
+				State:     issue.GetState(),
+				Author:    issue.GetUser().GetLogin(),
+				URL:       issue.GetHTMLURL(),
+				Labels:    issueLabels(issue.Labels),
+				Comments:  issue.GetComments(),
+				CreatedAt: issue.GetCreatedAt().Time,
+			})
+			if config.Limit > 0 && len(issues) == config.Limit {
+				return issues, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
+
+func issueLabels(labels []*github.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// GetPullRequest fetches a pull request with every file it changes
+func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, number int) (*models.PullRequest, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":        owner,
+		"repository":   repo,
+		"pull_request": number,
+	}).Debug("Fetching GitHub pull request")
+
+	pull, _, err := c.client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pull request #%d: %w", number, classifyError(err))
+	}
+
+	pr := &models.PullRequest{
+		Number:  pull.GetNumber(),
+		Title:   pull.GetTitle(),
+		Body:    pull.GetBody(),
+		Author:  pull.GetUser().GetLogin(),
+		URL:     pull.GetHTMLURL(),
+		BaseRef: pull.GetBase().GetRef(),
+		HeadRef: pull.GetHead().GetRef(),
+		HeadSHA: pull.GetHead().GetSHA(),
+	}
+
+	options := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := c.client.PullRequests.ListFiles(ctx, owner, repo, number, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of pull request #%d: %w", number, classifyError(err))
+		}
+		for _, file := range page {
+			pr.Files = append(pr.Files, changedFile(file))
+		}
+		if resp.NextPage == 0 {
+			return pr, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
+
+// changedFile converts a file of a pull request or comparison
+func changedFile(file *github.CommitFile) models.ChangedFile {
+	return models.ChangedFile{
+		Path:         file.GetFilename(),
+		PreviousPath: file.GetPreviousFilename(),
+		Status:       file.GetStatus(),
+		Additions:    file.GetAdditions(),
+		Deletions:    file.GetDeletions(),
+		Patch:        file.GetPatch(),
+	}
+}
@@ -0,0 +1,51 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// ListReleases lists the published releases of a repository, most recent first, up to
+// limit when above zero. Drafts are left out.
+func (c *Client) ListReleases(ctx context.Context, owner, repo string, limit int) ([]models.Release, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"owner":      owner,
+		"repository": repo,
+		"limit":      limit,
+	}).Debug("Listing GitHub releases")
+
+	options := &github.ListOptions{PerPage: min(max(limit, 1), 100)}
+	var releases []models.Release
+	for {
+		page, resp, err := c.client.Repositories.ListReleases(ctx, owner, repo, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases: %w", classifyError(err))
+		}
+		for _, release := range page {
+			if release.GetDraft() {
+				continue
+			}
+			releases = append(releases, models.Release{
+				Name:        release.GetName(),
+				Tag:         release.GetTagName(),
+				Body:        release.GetBody(),
+				Author:      release.GetAuthor().GetLogin(),
+				URL:         release.GetHTMLURL(),
+				Prerelease:  release.GetPrerelease(),
+				PublishedAt: release.GetPublishedAt().Time,
+			})
+			if limit > 0 && len(releases) == limit {
+				return releases, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return releases, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,32 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"sherpa/internal/adapters/gitclone"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// ListWikiPages returns the pages of the wiki of a repository. GitHub has no API for wikis,
+// so the wiki repository is cloned with git.
+func (c *Client) ListWikiPages(ctx context.Context, owner, repo string) ([]models.WikiPage, error) {
+	remote := c.webURL() + "/" + owner + "/" + repo + ".wiki.git"
+	logger.Logger.WithField("url", remote).Debug("Cloning GitHub wiki")
+
+	return gitclone.CloneWiki(ctx, gitclone.Options{
+		URL:      remote,
+		Username: "x-access-token",
+		Token:    c.token,
+	})
+}
+
+// webURL returns the web URL of the GitHub instance the client talks to
+func (c *Client) webURL() string {
+	base := strings.TrimSuffix(strings.TrimRight(c.baseURL, "/"), "/api/v3")
+	if base == "https://api.github.com" {
+		return "https://github.com"
+	}
+	return base
+}
@@ -2,11 +2,15 @@ package gitlab
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
-	"time"
+	"sync"
+	"sync/atomic"
 
+	"sherpa/internal/adapters/fetchpool"
+	sherpaerrors "sherpa/pkg/errors"
 	"sherpa/pkg/logger"
 	"sherpa/pkg/models"
 
@@ -18,10 +22,33 @@ type Client struct {
 	client  *gitlab.Client
 	baseURL string
 	token   string
+
+	mu              sync.Mutex
+	defaultBranches map[string]string               // Default branch of each project, fetched once
+	blobs           map[treeKey]map[string]treeBlob // Blobs of the files of each tree listed, by path
+	noBlobSizes     atomic.Bool                     // Set once a blob size lookup failed, so no other is sent
+}
+
+// treeBlob is the blob of a file listed in a tree
+type treeBlob struct {
+	id   string
+	size int64
+}
+
+// treeKey identifies the tree of a project at a ref
+type treeKey struct {
+	repoPath string
+	ref      string
 }
 
 // NewClient creates a new GitLab client
 func NewClient(baseURL, token string) (*Client, error) {
+	return NewClientWithTransport(baseURL, token, nil)
+}
+
+// NewClientWithTransport creates a GitLab client sending requests through transport,
+// the client's default pooled transport when nil
+func NewClientWithTransport(baseURL, token string, transport http.RoundTripper) (*Client, error) {
 	if token == "" {
 		return nil, fmt.Errorf("GitLab token is required")
 	}
@@ -31,15 +58,22 @@ func NewClient(baseURL, token string) (*Client, error) {
 	}
 
 	// Create GitLab client
-	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(baseURL))
+	options := []gitlab.ClientOptionFunc{gitlab.WithBaseURL(baseURL)}
+	if transport != nil {
+		options = append(options, gitlab.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+
+	client, err := gitlab.NewClient(token, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitLab client: %w", err)
 	}
 
 	return &Client{
-		client:  client,
-		baseURL: baseURL,
-		token:   token,
+		client:          client,
+		baseURL:         baseURL,
+		token:           token,
+		defaultBranches: make(map[string]string),
+		blobs:           make(map[treeKey]map[string]treeBlob),
 	}, nil
 }
 
@@ -49,8 +83,9 @@ func (c *Client) GetRepository(ctx context.Context, repoPath string) (*models.Re
 	project, _, err := c.client.Projects.GetProject(repoPath, &gitlab.GetProjectOptions{}, gitlab.WithContext(ctx))
 	if err != nil {
 		logger.Logger.WithError(err).WithField("repository", repoPath).Error("Failed to fetch repository")
-		return nil, fmt.Errorf("failed to fetch repository %s: %w", repoPath, err)
+		return nil, fmt.Errorf("failed to fetch repository %s: %w", repoPath, classifyError(err))
 	}
+	c.setDefaultBranch(repoPath, project.DefaultBranch)
 
 	return &models.Repository{
 		ID:                project.ID,
@@ -59,25 +94,191 @@ func (c *Client) GetRepository(ctx context.Context, repoPath string) (*models.Re
 		PathWithNamespace: project.PathWithNamespace,
 		WebURL:            project.WebURL,
 		Description:       project.Description,
+		Platform:          models.PlatformGitLab,
 	}, nil
 }
 
+// ListRepositories lists the projects of a group and its subgroups, leaving out projects
+// shared with the group from elsewhere
+func (c *Client) ListRepositories(ctx context.Context, group string) ([]models.ListedRepository, error) {
+	logger.Logger.WithField("group", group).Debug("Listing group projects")
+
+	var repos []models.ListedRepository
+	options := &gitlab.ListGroupProjectsOptions{
+		IncludeSubGroups: gitlab.Ptr(true),
+		WithShared:       gitlab.Ptr(false),
+		ListOptions:      gitlab.ListOptions{PerPage: 100},
+	}
+	for {
+		projects, resp, err := c.client.Groups.ListGroupProjects(group, options, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects of group %s: %w", group, classifyError(err))
+		}
+		for _, project := range projects {
+			repos = append(repos, models.ListedRepository{
+				FullName:      project.PathWithNamespace,
+				DefaultBranch: project.DefaultBranch,
+				Archived:      project.Archived,
+				Fork:          project.ForkedFromProject != nil,
+				Visibility:    string(project.Visibility),
+				Topics:        project.Topics,
+			})
+		}
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
+
+// ResolveCommit returns the commit SHA a ref points to, using the default branch when ref is empty
+func (c *Client) ResolveCommit(ctx context.Context, repoPath, ref string) (string, error) {
+	ref, err := c.resolveRef(ctx, repoPath, ref)
+	if err != nil {
+		return "", err
+	}
+
+	commit, _, err := c.client.Commits.GetCommit(repoPath, ref, &gitlab.GetCommitOptions{}, gitlab.WithContext(ctx))
+	if err != nil {
+		logger.Logger.WithError(err).WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"ref":        ref,
+		}).Error("Failed to resolve GitLab commit")
+		return "", fmt.Errorf("failed to resolve commit for %s: %w", ref, classifyError(err))
+	}
+
+	return commit.ID, nil
+}
+
+// resolveRef returns ref, or the default branch of the project when it is empty. The default
+// branch is fetched once per project, since every file is fetched with the same ref.
+func (c *Client) resolveRef(ctx context.Context, repoPath, ref string) (string, error) {
+	if ref != "" {
+		return ref, nil
+	}
+	c.mu.Lock()
+	branch, ok := c.defaultBranches[repoPath]
+	c.mu.Unlock()
+	if ok {
+		return branch, nil
+	}
+
+	project, _, err := c.client.Projects.GetProject(repoPath, &gitlab.GetProjectOptions{}, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch repository %s: %w", repoPath, classifyError(err))
+	}
+	if project.DefaultBranch == "" {
+		return "", fmt.Errorf("repository %s has no default branch", repoPath)
+	}
+	c.setDefaultBranch(repoPath, project.DefaultBranch)
+	return project.DefaultBranch, nil
+}
+
+// setDefaultBranch records the default branch of a project, unless it is empty like the one
+// of an empty repository
+func (c *Client) setDefaultBranch(repoPath, branch string) {
+	if branch == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultBranches[repoPath] = branch
+}
+
+// maxCompareDiffs is the number of diffs GitLab returns at most in a comparison by default
+const maxCompareDiffs = 1000
+
+// ChangedFiles lists the paths of files added, modified, removed or renamed between two
+// commits, including the previous path of renamed files. It fails when GitLab may have
+// truncated the list.
+func (c *Client) ChangedFiles(ctx context.Context, repoPath, base, head string) ([]string, error) {
+	comparison, _, err := c.client.Repositories.Compare(repoPath, &gitlab.CompareOptions{
+		From:     gitlab.Ptr(base),
+		To:       gitlab.Ptr(head),
+		Straight: gitlab.Ptr(true),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, classifyError(err))
+	}
+	if comparison.CompareTimeout || len(comparison.Diffs) >= maxCompareDiffs {
+		return nil, fmt.Errorf("comparison %s...%s is incomplete", base, head)
+	}
+
+	var paths []string
+	for _, diff := range comparison.Diffs {
+		paths = append(paths, diff.NewPath)
+		if diff.OldPath != diff.NewPath {
+			paths = append(paths, diff.OldPath)
+		}
+	}
+	return paths, nil
+}
+
+// CompareRefs lists the files changed from the merge base of base to head, with their
+// diffs. It fails when GitLab may have truncated the list.
+func (c *Client) CompareRefs(ctx context.Context, repoPath, base, head string) (*models.Comparison, error) {
+	comparison, _, err := c.client.Repositories.Compare(repoPath, &gitlab.CompareOptions{
+		From: gitlab.Ptr(base),
+		To:   gitlab.Ptr(head),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, classifyError(err))
+	}
+	if comparison.CompareTimeout || len(comparison.Diffs) >= maxCompareDiffs {
+		return nil, fmt.Errorf("comparison %s...%s is incomplete", base, head)
+	}
+
+	result := &models.Comparison{Base: base, Head: head, Commits: len(comparison.Commits)}
+	for _, diff := range comparison.Diffs {
+		file := models.ChangedFile{Path: diff.NewPath, Status: "modified", Patch: diff.Diff}
+		switch {
+		case diff.NewFile:
+			file.Status = "added"
+		case diff.DeletedFile:
+			file.Status = "removed"
+		case diff.RenamedFile:
+			file.Status = "renamed"
+			file.PreviousPath = diff.OldPath
+		}
+		file.Additions, file.Deletions = countDiffLines(diff.Diff)
+		result.Files = append(result.Files, file)
+	}
+	return result, nil
+}
+
+// countDiffLines counts the lines added and removed by the hunks of a diff, which GitLab
+// does not report. GitLab diffs start at the first hunk, without file headers.
+func countDiffLines(diff string) (int, int) {
+	additions, deletions := 0, 0
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
 // GetRepositoryTree fetches the complete repository tree structure
 func (c *Client) GetRepositoryTree(ctx context.Context, repoPath, branch string) ([]models.RepositoryTree, error) {
 	logger.Logger.WithFields(map[string]interface{}{
 		"repository": repoPath,
 		"branch":     branch,
 	}).Debug("Fetching repository tree structure")
-	var allFiles []models.RepositoryTree
 
-	// Start with root directory
-	files, err := c.getTreeRecursive(ctx, repoPath, "", branch, &allFiles)
+	var files []models.RepositoryTree
+	err := c.WalkRepositoryTree(ctx, repoPath, branch, func(page []models.RepositoryTree) error {
+		files = append(files, page...)
+		return nil
+	})
 	if err != nil {
 		logger.Logger.WithError(err).WithFields(map[string]interface{}{
 			"repository": repoPath,
 			"branch":     branch,
 		}).Error("Failed to fetch repository tree")
-		return nil, fmt.Errorf("failed to fetch repository tree: %w", err)
+		return nil, err
 	}
 
 	logger.Logger.WithFields(map[string]interface{}{
@@ -88,124 +289,151 @@ func (c *Client) GetRepositoryTree(ctx context.Context, repoPath, branch string)
 	return files, nil
 }
 
-// getTreeRecursive recursively fetches tree structure
-func (c *Client) getTreeRecursive(ctx context.Context, repoPath, path, branch string, allFiles *[]models.RepositoryTree) ([]models.RepositoryTree, error) {
+// WalkRepositoryTree lists the repository tree recursively, calling fn with each page of
+// entries as soon as it is received. Listing stops at the first error returned by fn.
+func (c *Client) WalkRepositoryTree(ctx context.Context, repoPath, branch string, fn func([]models.RepositoryTree) error) error {
+	ref, err := c.resolveRef(ctx, repoPath, branch)
+	if err != nil {
+		return fmt.Errorf("failed to fetch repository tree: %w", err)
+	}
+	if err := c.walkTree(ctx, repoPath, "", ref, true, fn); err != nil {
+		return fmt.Errorf("failed to fetch repository tree: %w", classifyError(err))
+	}
+	return nil
+}
+
+// ListSubtree lists the entries of the directory dir at branch, or the default branch when
+// it is empty, and of its subdirectories when recursive. A missing directory has no entries.
+func (c *Client) ListSubtree(ctx context.Context, repoPath, branch, dir string, recursive bool) ([]models.RepositoryTree, error) {
+	ref, err := c.resolveRef(ctx, repoPath, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository tree: %w", err)
+	}
+	var entries []models.RepositoryTree
+	err = c.walkTree(ctx, repoPath, strings.Trim(dir, "/"), ref, recursive, func(page []models.RepositoryTree) error {
+		entries = append(entries, page...)
+		return nil
+	})
+	if err != nil {
+		if sherpaerrors.KindOf(err) == sherpaerrors.KindNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch repository tree: %w", err)
+	}
+	return entries, nil
+}
+
+// walkTree lists the tree below path at ref page by page
+func (c *Client) walkTree(ctx context.Context, repoPath, path, ref string, recursive bool, fn func([]models.RepositoryTree) error) error {
 	opt := &gitlab.ListTreeOptions{
 		Path:      &path,
-		Recursive: &[]bool{true}[0],
+		Recursive: gitlab.Ptr(recursive),
+		Ref:       gitlab.Ptr(ref),
 		ListOptions: gitlab.ListOptions{
 			PerPage: 100,
 		},
 	}
 
-	// Use specified branch or fall back to default branch detection
-	if branch != "" {
-		opt.Ref = &branch
-	}
-
-	var pageFiles []models.RepositoryTree
-
 	for {
 		treeNodes, resp, err := c.client.Repositories.ListTree(repoPath, opt, gitlab.WithContext(ctx))
 		if err != nil {
-			// If branch-specific call fails and we have a branch specified, try default branches
-			if branch != "" {
-				logger.Logger.WithFields(map[string]interface{}{
-					"repository": repoPath,
-					"branch":     branch,
-					"path":       path,
-				}).Debug("Branch-specific tree fetch failed, trying default branches")
-
-				// Try main branch
-				opt.Ref = &[]string{"main"}[0]
-				treeNodes, resp, err = c.client.Repositories.ListTree(repoPath, opt, gitlab.WithContext(ctx))
-				if err != nil {
-					// Try master branch
-					opt.Ref = &[]string{"master"}[0]
-					treeNodes, resp, err = c.client.Repositories.ListTree(repoPath, opt, gitlab.WithContext(ctx))
-					if err != nil {
-						return nil, fmt.Errorf("failed to list tree for path %s: %w", path, err)
-					}
-				}
-			} else {
-				return nil, fmt.Errorf("failed to list tree for path %s: %w", path, err)
-			}
+			return fmt.Errorf("failed to list tree for path %s at %s: %w", path, ref, classifyError(err))
 		}
 
+		page := make([]models.RepositoryTree, 0, len(treeNodes))
 		for _, node := range treeNodes {
-			file := models.RepositoryTree{
+			page = append(page, models.RepositoryTree{
 				ID:   node.ID,
 				Name: node.Name,
 				Type: node.Type,
 				Path: node.Path,
 				Mode: node.Mode,
-			}
-			pageFiles = append(pageFiles, file)
-			*allFiles = append(*allFiles, file)
+			})
+		}
+		c.setBlobSizes(ctx, repoPath, ref, page)
+		c.recordBlobs(repoPath, ref, page)
+		if err := fn(page); err != nil {
+			return err
 		}
 
 		if resp.NextPage == 0 {
-			break
+			return nil
 		}
 		opt.Page = resp.NextPage
 	}
+}
+
+// recordBlobs remembers the blobs of the files of a tree page, so their contents are
+// fetched by ID without GitLab resolving the path at the ref again, and their sizes are kept
+func (c *Client) recordBlobs(repoPath, ref string, page []models.RepositoryTree) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := treeKey{repoPath: repoPath, ref: ref}
+	blobs := c.blobs[key]
+	if blobs == nil {
+		blobs = make(map[string]treeBlob)
+		c.blobs[key] = blobs
+	}
+	for _, entry := range page {
+		if entry.Type == "blob" && entry.ID != "" {
+			blobs[entry.Path] = treeBlob{id: entry.ID, size: entry.Size}
+		}
+	}
+}
 
-	return pageFiles, nil
+// listedBlob returns the blob of a file listed in the tree of a project at a ref
+func (c *Client) listedBlob(repoPath, ref, filePath string) (treeBlob, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	blob, ok := c.blobs[treeKey{repoPath: repoPath, ref: ref}][filePath]
+	return blob, ok
 }
 
 // GetFileContent fetches the content of a specific file
 func (c *Client) GetFileContent(ctx context.Context, repoPath, filePath, branch string) (string, error) {
+	content, _, err := c.readFile(ctx, repoPath, filePath, branch)
+	return content, err
+}
+
+// readFile fetches the content of a file at branch, or at the default branch of the project
+// when branch is empty, with its blob size when the file was listed, 0 otherwise. Files
+// listed by a tree walk are read from the raw blob endpoint by their ID, others from the raw
+// file endpoint, and the body is copied as it is received instead of being decoded from
+// base64 JSON.
+func (c *Client) readFile(ctx context.Context, repoPath, filePath, branch string) (string, int64, error) {
+	ref, err := c.resolveRef(ctx, repoPath, branch)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fetch file %s: %w", filePath, err)
+	}
 	logger.Logger.WithFields(map[string]interface{}{
 		"repository": repoPath,
 		"file":       filePath,
-		"branch":     branch,
-	}).Debug("Fetching file content")
-
-	opt := &gitlab.GetFileOptions{}
-
-	// Use specified branch or fall back to default branch detection
-	if branch != "" {
-		opt.Ref = &branch
+		"ref":        ref,
+	}).Trace("Fetching file content")
+
+	var opt interface{}
+	endpoint := fmt.Sprintf("projects/%s/repository/files/%s/raw", gitlab.PathEscape(repoPath), gitlab.PathEscape(filePath))
+	blob, listed := c.listedBlob(repoPath, ref, filePath)
+	if listed {
+		endpoint = fmt.Sprintf("projects/%s/repository/blobs/%s/raw", gitlab.PathEscape(repoPath), blob.id)
 	} else {
-		opt.Ref = &[]string{"main"}[0]
+		opt = &gitlab.GetRawFileOptions{Ref: gitlab.Ptr(ref)}
 	}
-
-	file, _, err := c.client.RepositoryFiles.GetFile(repoPath, filePath, opt, gitlab.WithContext(ctx))
+	req, err := c.client.NewRequest(http.MethodGet, endpoint, opt, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
 	if err != nil {
-		// If branch-specific call fails, try default branches
-		if branch != "" {
-			logger.Logger.WithFields(map[string]interface{}{
-				"repository": repoPath,
-				"file":       filePath,
-				"branch":     branch,
-			}).Debug("Branch-specific file fetch failed, trying default branches")
-		}
-
-		// Try main branch
-		opt.Ref = &[]string{"main"}[0]
-		file, _, err = c.client.RepositoryFiles.GetFile(repoPath, filePath, opt, gitlab.WithContext(ctx))
-		if err != nil {
-			// Try master branch
-			opt.Ref = &[]string{"master"}[0]
-			file, _, err = c.client.RepositoryFiles.GetFile(repoPath, filePath, opt, gitlab.WithContext(ctx))
-			if err != nil {
-				logger.Logger.WithError(err).WithFields(map[string]interface{}{
-					"repository": repoPath,
-					"file":       filePath,
-					"branch":     branch,
-				}).Error("Failed to fetch file from all attempted branches")
-				return "", fmt.Errorf("failed to fetch file %s: %w", filePath, err)
-			}
-		}
+		return "", 0, fmt.Errorf("failed to fetch file %s: %w", filePath, err)
 	}
 
-	// Decode base64 content from GitLab API
-	decoded, err := base64.StdEncoding.DecodeString(file.Content)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode file content: %w", err)
+	var content strings.Builder
+	if _, err := c.client.Do(req, &content); err != nil {
+		logger.Logger.WithError(err).WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"file":       filePath,
+			"ref":        ref,
+		}).Debug("Failed to fetch file")
+		return "", 0, fmt.Errorf("failed to fetch file %s at %s: %w", filePath, ref, classifyError(err))
 	}
-
-	return string(decoded), nil
+	return content.String(), blob.size, nil
 }
 
 // GetFileInfo fetches file information and content
@@ -215,22 +443,25 @@ func (c *Client) GetFileInfo(ctx context.Context, repoPath, filePath, branch str
 		Name: extractFileName(filePath),
 	}
 
-	// Get file content
-	content, err := c.GetFileContent(ctx, repoPath, filePath, branch)
+	content, size, err := c.readFile(ctx, repoPath, filePath, branch)
 	if err != nil {
 		fileInfo.Error = err
 		return fileInfo, nil
 	}
 
 	fileInfo.Content = content
-	fileInfo.Size = int64(len(content))
+	fileInfo.ContentSize = int64(len(content))
+	fileInfo.Size = size
+	if fileInfo.Size == 0 {
+		fileInfo.Size = fileInfo.ContentSize
+	}
 	fileInfo.IsText = isTextFile(content)
 	fileInfo.IsBinary = !fileInfo.IsText
 
 	return fileInfo, nil
 }
 
-// GetMultipleFiles fetches multiple files concurrently with rate limiting
+// GetMultipleFiles fetches multiple files concurrently, in the order given
 func (c *Client) GetMultipleFiles(ctx context.Context, repoPath string, filePaths []string, branch string, maxConcurrency int, config *models.ProcessingConfig) ([]models.FileInfo, error) {
 	logger.Logger.WithFields(map[string]interface{}{
 		"repository":      repoPath,
@@ -238,52 +469,51 @@ func (c *Client) GetMultipleFiles(ctx context.Context, repoPath string, filePath
 		"branch":          branch,
 		"max_concurrency": maxConcurrency,
 	}).Debug("Fetching multiple files concurrently")
-	if maxConcurrency <= 0 {
-		maxConcurrency = 5 // Default concurrency
-	}
-
-	// Add resource limits for security
-	maxMemoryPerFile := config.MaxMemoryPerFile
-	maxTotalMemory := config.MaxTotalMemory
-	maxFiles := config.MaxFiles
+	return fetchpool.Fetch(ctx, filePaths, maxConcurrency, config, func(ctx context.Context, filePath string) (*models.FileInfo, error) {
+		return c.GetFileInfo(ctx, repoPath, filePath, branch)
+	})
+}
 
-	if len(filePaths) > maxFiles {
-		return nil, fmt.Errorf("too many files to process safely: %d (max: %d)", len(filePaths), maxFiles)
-	}
+// SearchCode returns the paths of files matching a blob search query in the project
+func (c *Client) SearchCode(ctx context.Context, repoPath, query, branch string) ([]string, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"query":      query,
+		"branch":     branch,
+	}).Debug("Searching GitLab code")
 
-	if int64(len(filePaths))*maxMemoryPerFile > maxTotalMemory {
-		return nil, fmt.Errorf("estimated memory usage too high for %d files", len(filePaths))
+	opt := &gitlab.SearchOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	if branch != "" {
+		opt.Ref = &branch
 	}
 
-	semaphore := make(chan struct{}, maxConcurrency)
-	results := make(chan models.FileInfo, len(filePaths))
-
-	// Start workers
-	for _, filePath := range filePaths {
-		go func(path string) {
-			semaphore <- struct{}{}        // Acquire
-			defer func() { <-semaphore }() // Release
+	seen := make(map[string]bool)
+	var paths []string
+	for {
+		blobs, resp, err := c.client.Search.BlobsByProject(repoPath, query, opt, gitlab.WithContext(ctx))
+		if err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Error("Failed to search GitLab code")
+			return nil, fmt.Errorf("failed to search code: %w", classifyError(err))
+		}
 
-			fileInfo, err := c.GetFileInfo(ctx, repoPath, path, branch)
-			if err != nil {
-				fileInfo = &models.FileInfo{
-					Path:  path,
-					Name:  extractFileName(path),
-					Error: err,
-				}
+		for _, blob := range blobs {
+			path := blob.Path
+			if path == "" {
+				path = blob.Filename
 			}
-			results <- *fileInfo
-		}(filePath)
-	}
+			if path != "" && !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
 
-	// Collect results
-	var files []models.FileInfo
-	for i := 0; i < len(filePaths); i++ {
-		files = append(files, <-results)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
 	}
 
-	close(results)
-	return files, nil
+	return paths, nil
 }
 
 // TestConnection tests the GitLab connection and authentication
@@ -292,7 +522,7 @@ func (c *Client) TestConnection(ctx context.Context) error {
 	user, _, err := c.client.Users.CurrentUser(gitlab.WithContext(ctx))
 	if err != nil {
 		logger.Logger.WithError(err).WithField("base_url", c.baseURL).Error("Failed to authenticate with GitLab")
-		return fmt.Errorf("failed to authenticate with GitLab: %w", err)
+		return fmt.Errorf("failed to authenticate with GitLab: %w", classifyError(err))
 	}
 
 	if user == nil {
@@ -325,81 +555,22 @@ func isTextFile(content string) bool {
 	return true
 }
 
-// GetRateLimitInfo returns current rate limit information
-func (c *Client) GetRateLimitInfo() *RateLimitInfo {
-	// This is a placeholder for rate limit information
-	// The GitLab client doesn't expose rate limit headers directly
-	return &RateLimitInfo{
-		Limit:     1000,
-		Remaining: 1000,
-		ResetTime: time.Now().Add(time.Hour),
-	}
-}
-
-// RateLimitInfo contains rate limiting information
-type RateLimitInfo struct {
-	Limit     int
-	Remaining int
-	ResetTime time.Time
-}
-
-// WithRetry executes a function with exponential backoff retry logic
-func (c *Client) WithRetry(ctx context.Context, maxRetries int, fn func() error) error {
-	var lastErr error
-
-	for i := 0; i <= maxRetries; i++ {
-		if i > 0 {
-			// Exponential backoff
-			backoff := time.Duration(i*i) * time.Second
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(backoff):
-			}
-		}
-
-		if err := fn(); err != nil {
-			lastErr = err
-
-			// Check if it's a rate limit error
-			if isRateLimitError(err) {
-				continue
-			}
-
-			// Check if it's a temporary network error
-			if isTemporaryError(err) {
-				continue
-			}
-
-			// For other errors, don't retry
-			return err
-		}
-
-		return nil
-	}
-
-	return fmt.Errorf("max retries exceeded: %w", lastErr)
-}
-
-func isRateLimitError(err error) bool {
+// classifyError maps GitLab API errors onto the shared error taxonomy, with a remediation
+// hint for authorization failures and missing projects
+func classifyError(err error) error {
 	if err == nil {
-		return false
+		return nil
 	}
-	return strings.Contains(err.Error(), "rate limit") ||
-		strings.Contains(err.Error(), "429")
-}
 
-func isTemporaryError(err error) bool {
-	if err == nil {
-		return false
+	// The client reports 404 responses with a bare sentinel instead of an ErrorResponse
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return sherpaerrors.WithHint(sherpaerrors.Wrap(sherpaerrors.KindNotFound, err), notFoundHint)
 	}
 
-	// Check for common temporary HTTP errors
-	if strings.Contains(err.Error(), "timeout") ||
-		strings.Contains(err.Error(), "connection reset") ||
-		strings.Contains(err.Error(), "temporary failure") {
-		return true
+	var responseErr *gitlab.ErrorResponse
+	if errors.As(err, &responseErr) && responseErr.Response != nil {
+		return sherpaerrors.WithHint(sherpaerrors.FromStatus(responseErr.Response.StatusCode, err), errorHint(responseErr.Response))
 	}
 
-	return false
+	return err
 }
@@ -0,0 +1,215 @@
+package gitlab
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"sherpa/internal/fakevcs"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Refs(t *testing.T) {
+	fixtures := &fakevcs.Fixtures{Repositories: []fakevcs.Repository{{
+		Path:          "group/project",
+		DefaultBranch: "trunk",
+		Files:         map[string]string{"README.md": "# project\n", "src/main.go": "package main\n"},
+	}}}
+	server := fakevcs.NewServer(fixtures)
+	defer server.Close()
+
+	client, err := NewClient(server.GitLabURL(), fakevcs.Token)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("should fetch files from the default branch of the project", func(t *testing.T) {
+		content, err := client.GetFileContent(ctx, "group/project", "README.md", "")
+		require.NoError(t, err)
+		assert.Equal(t, "# project\n", content)
+
+		tree, err := client.GetRepositoryTree(ctx, "group/project", "")
+		require.NoError(t, err)
+		var paths []string
+		for _, entry := range tree {
+			if entry.Type == "blob" {
+				paths = append(paths, entry.Path)
+			}
+		}
+		assert.ElementsMatch(t, []string{"README.md", "src/main.go"}, paths)
+	})
+
+	t.Run("should fetch files from the requested branch", func(t *testing.T) {
+		info, err := client.GetFileInfo(ctx, "group/project", "src/main.go", "trunk")
+		require.NoError(t, err)
+		require.NoError(t, info.Error)
+		assert.Equal(t, "package main\n", info.Content)
+	})
+
+	t.Run("should fail on an unknown branch instead of reading another one", func(t *testing.T) {
+		_, err := client.GetFileContent(ctx, "group/project", "README.md", "main")
+		assert.ErrorContains(t, err, "at main")
+
+		err = client.WalkRepositoryTree(ctx, "group/project", "main", func([]models.RepositoryTree) error { return nil })
+		assert.Error(t, err)
+	})
+
+	t.Run("should read the files of a listed tree by blob ID", func(t *testing.T) {
+		var mu sync.Mutex
+		var paths []string
+		transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			paths = append(paths, req.URL.EscapedPath())
+			mu.Unlock()
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		client, err := NewClientWithTransport(server.GitLabURL(), fakevcs.Token, transport)
+		require.NoError(t, err)
+
+		_, err = client.GetRepositoryTree(ctx, "group/project", "trunk")
+		require.NoError(t, err)
+		files, err := client.GetMultipleFiles(ctx, "group/project", []string{"README.md", "src/main.go"}, "trunk", 2, &models.ProcessingConfig{})
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		assert.Equal(t, "# project\n", files[0].Content)
+		assert.Equal(t, int64(len("# project\n")), files[0].Size)
+
+		blobRequests := 0
+		for _, path := range paths {
+			assert.NotContains(t, path, "/repository/files/")
+			if strings.Contains(path, "/repository/blobs/") {
+				blobRequests++
+			}
+		}
+		assert.Equal(t, 2, blobRequests)
+	})
+
+	t.Run("should list the sizes of files without fetching them", func(t *testing.T) {
+		var mu sync.Mutex
+		var paths []string
+		transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			paths = append(paths, req.URL.EscapedPath())
+			mu.Unlock()
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		client, err := NewClientWithTransport(server.GitLabURL(), fakevcs.Token, transport)
+		require.NoError(t, err)
+
+		tree, err := client.GetRepositoryTree(ctx, "group/project", "trunk")
+		require.NoError(t, err)
+		sizes := make(map[string]int64)
+		for _, entry := range tree {
+			if entry.Type == "blob" {
+				sizes[entry.Path] = entry.Size
+			}
+		}
+		assert.Equal(t, map[string]int64{"README.md": int64(len("# project\n")), "src/main.go": int64(len("package main\n"))}, sizes)
+
+		for _, path := range paths {
+			assert.NotContains(t, path, "/raw")
+		}
+		assert.Contains(t, paths, "/api/graphql")
+	})
+
+	t.Run("should list trees without sizes when GraphQL is unavailable", func(t *testing.T) {
+		responses := map[string]*http.Response{
+			"disabled":   {StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"404 Not Found"}`))},
+			"restricted": {StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"data":{"project":null}}`))},
+		}
+		for name, response := range responses {
+			t.Run(name, func(t *testing.T) {
+				graphQLRequests := 0
+				transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path == "/api/graphql" {
+						graphQLRequests++
+						response.Header = http.Header{"Content-Type": {"application/json"}}
+						response.Request = req
+						return response, nil
+					}
+					return http.DefaultTransport.RoundTrip(req)
+				})
+				client, err := NewClientWithTransport(server.GitLabURL(), fakevcs.Token, transport)
+				require.NoError(t, err)
+
+				tree, err := client.GetRepositoryTree(ctx, "group/project", "trunk")
+				require.NoError(t, err)
+				assert.Len(t, tree, 3)
+				entries, err := client.ListSubtree(ctx, "group/project", "trunk", "src", true)
+				require.NoError(t, err)
+				assert.Len(t, entries, 1)
+				assert.Equal(t, 1, graphQLRequests, "the lookup should not be retried after a failure")
+
+				info, err := client.GetFileInfo(ctx, "group/project", "README.md", "trunk")
+				require.NoError(t, err)
+				require.NoError(t, info.Error)
+				assert.Equal(t, int64(len("# project\n")), info.Size)
+			})
+		}
+	})
+
+	t.Run("should resolve the commit of the default branch", func(t *testing.T) {
+		commit, err := client.ResolveCommit(ctx, "group/project", "")
+		require.NoError(t, err)
+		assert.NotEmpty(t, commit)
+	})
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClient_ListSubtree(t *testing.T) {
+	fixtures := &fakevcs.Fixtures{Repositories: []fakevcs.Repository{{
+		Path: "group/project",
+		Files: map[string]string{
+			"README.md":      "# project\n",
+			"src/main.go":    "package main\n",
+			"src/api/api.go": "package api\n",
+			"docs/guide.md":  "# guide\n",
+		},
+	}}}
+	server := fakevcs.NewServer(fixtures)
+	defer server.Close()
+
+	client, err := NewClient(server.GitLabURL(), fakevcs.Token)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	blobs := func(entries []models.RepositoryTree) []string {
+		var paths []string
+		for _, entry := range entries {
+			if entry.Type == "blob" {
+				paths = append(paths, entry.Path)
+			}
+		}
+		return paths
+	}
+
+	t.Run("should list a directory recursively", func(t *testing.T) {
+		entries, err := client.ListSubtree(ctx, "group/project", "", "src", true)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"src/main.go", "src/api/api.go"}, blobs(entries))
+	})
+
+	t.Run("should list only the root of the repository", func(t *testing.T) {
+		entries, err := client.ListSubtree(ctx, "group/project", "main", "", false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md"}, blobs(entries))
+	})
+
+	t.Run("should list nothing for a missing directory", func(t *testing.T) {
+		entries, err := client.ListSubtree(ctx, "group/project", "main", "missing", true)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
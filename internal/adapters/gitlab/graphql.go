@@ -0,0 +1,121 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// blobSizesQuery looks up the sizes of the blobs at paths, which the REST tree listing
+// leaves out. Paths are passed as variables so they need no escaping.
+const blobSizesQuery = `query($fullPath: ID!, $ref: String!, $paths: [String!]!, $first: Int!) {
+project(fullPath: $fullPath) {
+repository {
+blobs(ref: $ref, paths: $paths, first: $first) { nodes { path size } }
+}
+}
+}`
+
+// blobSizesResponse is the response to blobSizesQuery. Sizes are BigInt values, which
+// GitLab serializes as strings.
+type blobSizesResponse struct {
+	Data struct {
+		Project *struct {
+			Repository struct {
+				Blobs struct {
+					Nodes []struct {
+						Path string      `json:"path"`
+						Size json.Number `json:"size"`
+					} `json:"nodes"`
+				} `json:"blobs"`
+			} `json:"repository"`
+		} `json:"project"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// setBlobSizes sets the sizes of the files of a tree page at ref from the GraphQL API, in a
+// single request, so size limits apply before any content is fetched. The lookup is
+// best-effort: GraphQL may be disabled or restricted on self-managed instances, so after a
+// failure, logged once, sizes are left unknown and taken from the contents once fetched.
+func (c *Client) setBlobSizes(ctx context.Context, repoPath, ref string, page []models.RepositoryTree) {
+	if c.noBlobSizes.Load() {
+		return
+	}
+	if err := c.lookupBlobSizes(ctx, repoPath, ref, page); err != nil && ctx.Err() == nil {
+		if c.noBlobSizes.CompareAndSwap(false, true) {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Failed to look up GitLab file sizes, size limits only apply to fetched files")
+		}
+	}
+}
+
+// lookupBlobSizes sets the sizes of the files of a tree page at ref in a single GraphQL request
+func (c *Client) lookupBlobSizes(ctx context.Context, repoPath, ref string, page []models.RepositoryTree) error {
+	var paths []string
+	for _, entry := range page {
+		if entry.Type == "blob" {
+			paths = append(paths, entry.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"ref":        ref,
+		"file_count": len(paths),
+	}).Trace("Fetching GitLab blob sizes with GraphQL")
+
+	body := map[string]interface{}{
+		"query":     blobSizesQuery,
+		"variables": map[string]interface{}{"fullPath": repoPath, "ref": ref, "paths": paths, "first": len(paths)},
+	}
+	req, err := c.client.NewRequest(http.MethodPost, "", body, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	// The GraphQL API is served at /api/graphql, next to the /api/v4 REST API
+	req.URL.Path = strings.TrimSuffix(req.URL.Path, "v4/") + "graphql"
+
+	var response blobSizesResponse
+	if _, err := c.client.Do(req, &response); err != nil {
+		return fmt.Errorf("failed to fetch blob sizes at %s: %w", ref, classifyError(err))
+	}
+	if response.Data.Project == nil {
+		return blobSizesError(repoPath, response)
+	}
+
+	sizes := make(map[string]int64, len(paths))
+	for _, node := range response.Data.Project.Repository.Blobs.Nodes {
+		if size, err := node.Size.Int64(); err == nil {
+			sizes[node.Path] = size
+		}
+	}
+	for i := range page {
+		if size, ok := sizes[page[i].Path]; ok && page[i].Type == "blob" {
+			page[i].Size = size
+		}
+	}
+	return nil
+}
+
+// blobSizesError reports the errors of a GraphQL response without a project. The REST API
+// already listed the project, so a missing one means GraphQL cannot see it, not that it is
+// missing.
+func blobSizesError(repoPath string, response blobSizesResponse) error {
+	messages := []string{"project not visible to GraphQL"}
+	for _, graphQLErr := range response.Errors {
+		messages = append(messages, graphQLErr.Message)
+	}
+	return fmt.Errorf("failed to fetch blob sizes of %s: %s", repoPath, strings.Join(messages, "; "))
+}
@@ -0,0 +1,40 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// notFoundHint is the remediation for missing projects, which GitLab also reports for
+// private projects the token cannot read
+const notFoundHint = "check the project path: GitLab also answers 404 for private projects the token's user is not a member of"
+
+// scopePattern extracts the scopes required by an insufficient_scope WWW-Authenticate header
+var scopePattern = regexp.MustCompile(`scope="([^"]*)"`)
+
+// errorHint returns a remediation for a failed GitLab API response, read from its status
+// and WWW-Authenticate header, or an empty string
+func errorHint(response *http.Response) string {
+	switch response.StatusCode {
+	case http.StatusUnauthorized:
+		return "the token is invalid, expired or revoked: create a new one and pass it with --token"
+	case http.StatusForbidden:
+		authenticate := response.Header.Get("WWW-Authenticate")
+		if strings.Contains(authenticate, "insufficient_scope") {
+			if match := scopePattern.FindStringSubmatch(authenticate); match != nil && match[1] != "" {
+				scopes := strings.Fields(match[1])
+				if len(scopes) == 1 {
+					return fmt.Sprintf("the token lacks the %s scope", scopes[0])
+				}
+				return fmt.Sprintf("the token lacks one of the %s scopes", strings.Join(scopes, ", "))
+			}
+			return "the token lacks a required scope: read_api is needed to read repositories"
+		}
+		return "the token's user cannot access this project: reading the code of private projects needs at least the Reporter role"
+	case http.StatusNotFound:
+		return notFoundHint
+	}
+	return ""
+}
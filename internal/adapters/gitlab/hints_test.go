@@ -0,0 +1,60 @@
+package gitlab
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		expected string
+	}{
+		{
+			name:     "should ask for a new token on 401",
+			status:   http.StatusUnauthorized,
+			expected: "the token is invalid, expired or revoked: create a new one and pass it with --token",
+		},
+		{
+			name:     "should name the scope the token lacks",
+			status:   http.StatusForbidden,
+			header:   http.Header{"Www-Authenticate": {`Bearer realm="GitLab", error="insufficient_scope", error_description="The request requires higher privileges than provided by the access token.", scope="read_api"`}},
+			expected: "the token lacks the read_api scope",
+		},
+		{
+			name:     "should list the scopes when several are accepted",
+			status:   http.StatusForbidden,
+			header:   http.Header{"Www-Authenticate": {`Bearer realm="GitLab", error="insufficient_scope", scope="api read_api"`}},
+			expected: "the token lacks one of the api, read_api scopes",
+		},
+		{
+			name:     "should point at the project role otherwise",
+			status:   http.StatusForbidden,
+			expected: "the token's user cannot access this project: reading the code of private projects needs at least the Reporter role",
+		},
+		{
+			name:     "should explain that private projects answer 404",
+			status:   http.StatusNotFound,
+			expected: notFoundHint,
+		},
+		{
+			name:     "should not hint at server errors",
+			status:   http.StatusInternalServerError,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			assert.Equal(t, tt.expected, errorHint(&http.Response{StatusCode: tt.status, Header: header}))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ListIssues lists the issues of a project selected by config, most recently updated first
+func (c *Client) ListIssues(ctx context.Context, repoPath string, config models.IssuesConfig) ([]models.Issue, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"state":      config.State,
+		"labels":     config.Labels,
+	}).Debug("Listing GitLab issues")
+
+	options := &gitlab.ListProjectIssuesOptions{
+		OrderBy:     gitlab.Ptr("updated_at"),
+		Sort:        gitlab.Ptr("desc"),
+		ListOptions: gitlab.ListOptions{PerPage: min(max(config.Limit, 1), 100)},
+	}
+	// GitLab names open issues "opened" and lists every state when none is given
+	switch config.State {
+	case models.IssueStateOpen:
+		options.State = gitlab.Ptr("opened")
+	case models.IssueStateClosed:
+		options.State = gitlab.Ptr("closed")
+	}
+	if len(config.Labels) > 0 {
+		labels := gitlab.LabelOptions(config.Labels)
+		options.Labels = &labels
+	}
+
+	var issues []models.Issue
+	for {
+		page, resp, err := c.client.Issues.ListProjectIssues(repoPath, options, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", classifyError(err))
+		}
+		for _, issue := range page {
+			converted := models.Issue{
+				Number:   issue.IID,
+				Title:    issue.Title,
+				Body:     issue.Description,
+				State:    models.IssueStateOpen,
+				URL:      issue.WebURL,
+				Labels:   issue.Labels,
+				Comments: issue.UserNotesCount,
+			}
+			if issue.State == "closed" {
+				converted.State = models.IssueStateClosed
+			}
+			if issue.Author != nil {
+				converted.Author = issue.Author.Username
+			}
+			if issue.CreatedAt != nil {
+				converted.CreatedAt = *issue.CreatedAt
+			}
+			issues = append(issues, converted)
+			if config.Limit > 0 && len(issues) == config.Limit {
+				return issues, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,56 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ListReleases lists the releases of a project, most recently released first, up to limit
+// when above zero. Upcoming releases are left out.
+func (c *Client) ListReleases(ctx context.Context, repoPath string, limit int) ([]models.Release, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"limit":      limit,
+	}).Debug("Listing GitLab releases")
+
+	options := &gitlab.ListReleasesOptions{
+		OrderBy:     gitlab.Ptr("released_at"),
+		Sort:        gitlab.Ptr("desc"),
+		ListOptions: gitlab.ListOptions{PerPage: min(max(limit, 1), 100)},
+	}
+	var releases []models.Release
+	for {
+		page, resp, err := c.client.Releases.ListReleases(repoPath, options, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases: %w", classifyError(err))
+		}
+		for _, release := range page {
+			if release.UpcomingRelease {
+				continue
+			}
+			converted := models.Release{
+				Name:   release.Name,
+				Tag:    release.TagName,
+				Body:   release.Description,
+				Author: release.Author.Username,
+				URL:    release.Links.Self,
+			}
+			if release.ReleasedAt != nil {
+				converted.PublishedAt = *release.ReleasedAt
+			}
+			releases = append(releases, converted)
+			if limit > 0 && len(releases) == limit {
+				return releases, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return releases, nil
+		}
+		options.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,132 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// GetSnippet fetches a snippet and the content of its files. projectPath is empty for
+// personal snippets.
+func (c *Client) GetSnippet(ctx context.Context, projectPath string, snippetID int) (*models.Repository, []models.FileInfo, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"project": projectPath,
+		"snippet": snippetID,
+	}).Debug("Fetching GitLab snippet")
+
+	var snippet *gitlab.Snippet
+	var err error
+	if projectPath != "" {
+		snippet, _, err = c.client.ProjectSnippets.GetSnippet(projectPath, snippetID, gitlab.WithContext(ctx))
+	} else {
+		snippet, _, err = c.client.Snippets.GetSnippet(snippetID, gitlab.WithContext(ctx))
+	}
+	if err != nil {
+		logger.Logger.WithError(err).WithField("snippet", snippetID).Error("Failed to fetch GitLab snippet")
+		return nil, nil, fmt.Errorf("failed to fetch snippet %d: %w", snippetID, classifyError(err))
+	}
+
+	pathWithNamespace := fmt.Sprintf("snippets/%d", snippetID)
+	if projectPath != "" {
+		pathWithNamespace = projectPath + "/-/" + pathWithNamespace
+	}
+	name := snippet.Title
+	if name == "" {
+		name = fmt.Sprintf("snippet-%d", snippetID)
+	}
+	repository := &models.Repository{
+		ID:                snippet.ID,
+		Name:              name,
+		Path:              fmt.Sprintf("%d", snippetID),
+		PathWithNamespace: pathWithNamespace,
+		WebURL:            snippet.WebURL,
+		Description:       snippet.Description,
+		Platform:          models.PlatformGitLab,
+		Owner:             snippet.Author.Username,
+	}
+
+	// Snippets created before multi-file support only expose their single file name
+	paths := make(map[string]string, len(snippet.Files))
+	for _, file := range snippet.Files {
+		paths[file.Path] = snippetRef(file.RawURL)
+	}
+	if len(paths) == 0 && snippet.FileName != "" {
+		paths[snippet.FileName] = ""
+	}
+
+	files := make([]models.FileInfo, 0, len(paths))
+	for filePath, ref := range paths {
+		fileInfo := models.FileInfo{
+			Path: filePath,
+			Name: extractFileName(filePath),
+		}
+
+		data, err := c.getSnippetFile(ctx, projectPath, snippetID, ref, filePath)
+		if err != nil {
+			fileInfo.Error = fmt.Errorf("failed to fetch file %s: %w", filePath, classifyError(err))
+			files = append(files, fileInfo)
+			continue
+		}
+
+		content := string(data)
+		fileInfo.Content = content
+		fileInfo.ContentSize = int64(len(content))
+		fileInfo.Size = fileInfo.ContentSize
+		fileInfo.IsText = isTextFile(content)
+		fileInfo.IsBinary = !fileInfo.IsText
+		files = append(files, fileInfo)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return repository, files, nil
+}
+
+// getSnippetFile downloads a snippet file, or the whole snippet content when ref is empty
+func (c *Client) getSnippetFile(ctx context.Context, projectPath string, snippetID int, ref, filePath string) ([]byte, error) {
+	switch {
+	case ref == "" && projectPath != "":
+		data, _, err := c.client.ProjectSnippets.SnippetContent(projectPath, snippetID, gitlab.WithContext(ctx))
+		return data, err
+	case ref == "":
+		data, _, err := c.client.Snippets.SnippetContent(snippetID, gitlab.WithContext(ctx))
+		return data, err
+	case projectPath == "":
+		data, _, err := c.client.Snippets.SnippetFileContent(snippetID, ref, filePath, gitlab.WithContext(ctx))
+		return data, err
+	}
+
+	// The client library has no project snippet file endpoint
+	endpoint := fmt.Sprintf("projects/%s/snippets/%d/files/%s/%s/raw", gitlab.PathEscape(projectPath), snippetID, ref, gitlab.PathEscape(filePath))
+	req, err := c.client.NewRequest(http.MethodGet, endpoint, nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := c.client.Do(req, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// snippetRef extracts the ref from a snippet file raw URL like
+// https://gitlab.com/-/snippets/1/raw/main/file.rb, defaulting to main
+func snippetRef(rawURL string) string {
+	_, rest, found := strings.Cut(rawURL, "/raw/")
+	if !found {
+		return "main"
+	}
+	ref, _, _ := strings.Cut(rest, "/")
+	if ref == "" {
+		return "main"
+	}
+	return ref
+}
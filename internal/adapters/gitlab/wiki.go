@@ -0,0 +1,36 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ListWikiPages returns the pages of the wiki of a project. Projects with the wiki disabled
+// have no pages.
+func (c *Client) ListWikiPages(ctx context.Context, repoPath string) ([]models.WikiPage, error) {
+	logger.Logger.WithField("repository", repoPath).Debug("Listing GitLab wiki pages")
+
+	wikis, resp, err := c.client.Wikis.ListWikis(repoPath, &gitlab.ListWikisOptions{WithContent: gitlab.Ptr(true)}, gitlab.WithContext(ctx))
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list wiki pages: %w", classifyError(err))
+	}
+
+	pages := make([]models.WikiPage, 0, len(wikis))
+	for _, wiki := range wikis {
+		pages = append(pages, models.WikiPage{
+			Title:   wiki.Title,
+			Path:    wiki.Slug,
+			Content: wiki.Content,
+		})
+	}
+	return pages, nil
+}
@@ -0,0 +1,102 @@
+package adapters
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// GroupPrefix marks an organization or user whose repositories are all processed, like
+// org:my-github-org
+const GroupPrefix = "org:"
+
+// IsGroupPattern reports whether an argument names the repositories of an organization or
+// group, like org:my-org, group/subgroup/* or https://gitlab.com/group/*
+func IsGroupPattern(input string) bool {
+	input, _, _ = strings.Cut(strings.TrimSpace(input), "#")
+	if strings.HasPrefix(input, GroupPrefix) {
+		return true
+	}
+	group, ok := strings.CutSuffix(input, "/*")
+	return ok && !strings.ContainsAny(group, "*?[") && !isLocalPath(input)
+}
+
+// parseGroup parses an organization or group argument. org: names use the default
+// platform, GitHub unless set; bare group/* paths default to GitLab like bare names, and
+// URLs are matched by host.
+func parseGroup(input string, defaultPlatform models.Platform) (*models.RepositoryInfo, error) {
+	var group, groupURL string
+	platform := defaultPlatform
+	switch {
+	case strings.HasPrefix(input, GroupPrefix):
+		group = strings.TrimPrefix(input, GroupPrefix)
+		if platform == "" {
+			platform = models.PlatformGitHub
+		}
+	case strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://"):
+		u, err := url.Parse(strings.TrimSuffix(input, "/*"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL: %w", err)
+		}
+		group, groupURL = u.Path, input
+		platform = groupPlatform(u.Hostname())
+	default:
+		group = strings.TrimSuffix(input, "/*")
+		if platform == "" {
+			platform = models.PlatformGitLab
+		}
+	}
+
+	group = strings.Trim(group, "/")
+	if group == "" {
+		return nil, fmt.Errorf("missing organization or group name in '%s'", input)
+	}
+	if strings.Contains(group, "/") && platform != models.PlatformGitLab {
+		return nil, fmt.Errorf("'%s' is not an organization: only GitLab groups can be nested", group)
+	}
+
+	return &models.RepositoryInfo{
+		Platform: platform,
+		Owner:    group,
+		Name:     path.Base(group),
+		FullName: group,
+		URL:      groupURL,
+		Kind:     models.KindGroup,
+	}, nil
+}
+
+// groupPlatform returns the platform of a group URL host, GitLab for unknown self-hosted hosts
+func groupPlatform(hostname string) models.Platform {
+	switch {
+	case hostname == "github.com" || hostname == "www.github.com":
+		return models.PlatformGitHub
+	case isGiteaHost(hostname):
+		return models.PlatformGitea
+	default:
+		return models.PlatformGitLab
+	}
+}
+
+// GroupRepositories turns the listed repositories of a group selected by the filter into
+// repositories to process, applying the branch and path of the group argument when set
+func GroupRepositories(group *models.RepositoryInfo, listed []models.ListedRepository, filter models.RepositoryFilter) []*models.RepositoryInfo {
+	var repoInfos []*models.RepositoryInfo
+	for _, repo := range listed {
+		if !filter.Matches(repo) {
+			continue
+		}
+		owner, name := path.Split(repo.FullName)
+		repoInfos = append(repoInfos, &models.RepositoryInfo{
+			Platform:     group.Platform,
+			Owner:        strings.TrimSuffix(owner, "/"),
+			Name:         name,
+			FullName:     repo.FullName,
+			Branch:       group.Branch,
+			Subdirectory: group.Subdirectory,
+		})
+	}
+	return repoInfos
+}
@@ -0,0 +1,88 @@
+package adapters
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroup(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		defaultPlatform models.Platform
+		platform        models.Platform
+		group           string
+		branch          string
+		err             string
+	}{
+		{name: "should parse a GitHub organization", input: "org:my-github-org", platform: models.PlatformGitHub, group: "my-github-org"},
+		{name: "should use the default platform for organizations", input: "org:infra", defaultPlatform: models.PlatformGitea, platform: models.PlatformGitea, group: "infra"},
+		{name: "should parse a nested GitLab group", input: "gitlab-group/subgroup/*", platform: models.PlatformGitLab, group: "gitlab-group/subgroup"},
+		{name: "should keep the branch of a group", input: "gitlab-group/*#develop", platform: models.PlatformGitLab, group: "gitlab-group", branch: "develop"},
+		{name: "should detect the platform of a group URL", input: "https://github.com/my-org/*", platform: models.PlatformGitHub, group: "my-org"},
+		{name: "should parse a self-hosted group URL as GitLab", input: "https://git.example.com/team/backend/*", platform: models.PlatformGitLab, group: "team/backend"},
+		{name: "should reject nested organizations outside GitLab", input: "org:my-org/team", err: "only GitLab groups can be nested"},
+		{name: "should reject a missing name", input: "org:", err: "missing organization or group name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoInfo, err := ParseRepositoryURL(tt.input, tt.defaultPlatform)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, models.KindGroup, repoInfo.Kind)
+			assert.Equal(t, tt.platform, repoInfo.Platform)
+			assert.Equal(t, tt.group, repoInfo.FullName)
+			assert.Equal(t, tt.branch, repoInfo.Branch)
+		})
+	}
+}
+
+func TestIsGroupPattern(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "org:my-org", expected: true},
+		{input: "group/subgroup/*", expected: true},
+		{input: "group/*#main", expected: true},
+		{input: "./services/*", expected: false},
+		{input: "/srv/repos/*", expected: false},
+		{input: "group/api-*", expected: false},
+		{input: "owner/repo", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run("should classify "+tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsGroupPattern(tt.input))
+		})
+	}
+}
+
+func TestGroupRepositories(t *testing.T) {
+	t.Run("should keep the filtered repositories with the group branch and path", func(t *testing.T) {
+		group := &models.RepositoryInfo{Platform: models.PlatformGitLab, FullName: "team", Branch: "release", Subdirectory: "docs", Kind: models.KindGroup}
+		listed := []models.ListedRepository{
+			{FullName: "team/backend/api", Visibility: "internal"},
+			{FullName: "team/old", Archived: true},
+		}
+
+		repoInfos := GroupRepositories(group, listed, models.RepositoryFilter{})
+		require.Len(t, repoInfos, 1)
+		assert.Equal(t, &models.RepositoryInfo{
+			Platform:     models.PlatformGitLab,
+			Owner:        "team/backend",
+			Name:         "api",
+			FullName:     "team/backend/api",
+			Branch:       "release",
+			Subdirectory: "docs",
+		}, repoInfos[0])
+	})
+}
@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	"net/http"
+
+	"sherpa/pkg/models"
+)
+
+// NewHeaderTransport wraps base so every request carries the configured User-Agent and
+// headers, using http.DefaultTransport when base is nil. base is returned as is when no
+// header is configured.
+func NewHeaderTransport(base http.RoundTripper, config models.HTTPConfig) http.RoundTripper {
+	if config.UserAgent == "" && len(config.Headers) == 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &headerTransport{base: base, userAgent: config.UserAgent, headers: config.Headers}
+}
+
+// headerTransport is an http.RoundTripper setting headers on outgoing requests
+type headerTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	headers   map[string]string
+}
+
+// RoundTrip sends a copy of the request with the configured headers. They are set last,
+// so they replace the User-Agent and any header set by the platform clients.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,43 @@
+package adapters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHeaderTransport(t *testing.T) {
+	t.Run("should return the base transport without headers", func(t *testing.T) {
+		assert.Nil(t, NewHeaderTransport(nil, models.HTTPConfig{}))
+		assert.Equal(t, http.DefaultTransport, NewHeaderTransport(http.DefaultTransport, models.HTTPConfig{}))
+	})
+
+	t.Run("should set the User-Agent and headers on every request", func(t *testing.T) {
+		var received http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Clone()
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewHeaderTransport(nil, models.HTTPConfig{
+			UserAgent: "sherpa-ci/1.0",
+			Headers:   map[string]string{"X-Gateway-Route": "internal"},
+		})}
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("User-Agent", "go-github/v60")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, "sherpa-ci/1.0", received.Get("User-Agent"))
+		assert.Equal(t, "internal", received.Get("X-Gateway-Route"))
+		assert.Equal(t, "go-github/v60", req.Header.Get("User-Agent"), "the original request should not be modified")
+	})
+}
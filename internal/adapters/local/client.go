@@ -2,6 +2,7 @@ package local
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"sync"
 
+	sherpaerrors "sherpa/pkg/errors"
 	"sherpa/pkg/models"
 	"sherpa/pkg/utils"
 )
@@ -16,10 +18,18 @@ import (
 // Client handles local folder operations
 type Client struct {
 	basePath string
+	config   models.LocalConfig
+	mu       sync.Mutex
+	denied   []string // Directories the last tree listing could not read
 }
 
 // NewClient creates a new local folder client
 func NewClient(basePath string) (*Client, error) {
+	return NewClientWithConfig(basePath, models.LocalConfig{})
+}
+
+// NewClientWithConfig creates a local folder client walking the folder with config
+func NewClientWithConfig(basePath string, config models.LocalConfig) (*Client, error) {
 	// Validate that the path exists and is a directory
 	info, err := os.Stat(basePath)
 	if err != nil {
@@ -37,6 +47,7 @@ func NewClient(basePath string) (*Client, error) {
 
 	return &Client{
 		basePath: absPath,
+		config:   config,
 	}, nil
 }
 
@@ -59,56 +70,26 @@ func (c *Client) GetRepository(ctx context.Context, repoPath string) (*models.Re
 
 // GetRepositoryTree returns the tree structure of the local folder
 func (c *Client) GetRepositoryTree(ctx context.Context, repoPath, branch string) ([]models.RepositoryTree, error) {
-	var treeItems []models.RepositoryTree
-
-	err := filepath.WalkDir(c.basePath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return nil // Continue walking even if we can't read a specific file
-		}
-
-		// Skip the root directory itself
-		if path == c.basePath {
-			return nil
-		}
-
-		// Check if this is a symlink and skip it for security
-		if d.Type()&fs.ModeSymlink != 0 {
-			return nil // Skip symlinks for security
-		}
-
-		// Get relative path from base
-		relPath, err := filepath.Rel(c.basePath, path)
-		if err != nil {
-			return nil // Continue walking
-		}
-
-		// Convert to forward slashes for consistency
-		relPath = filepath.ToSlash(relPath)
-
-		// Determine type
-		itemType := "blob"
-		if d.IsDir() {
-			itemType = "tree"
-		}
-
-		treeItems = append(treeItems, models.RepositoryTree{
-			ID:   relPath,
-			Name: d.Name(),
-			Type: itemType,
-			Path: relPath,
-			Mode: "100644", // Default file mode
-		})
-
-		return nil
-	})
-
+	w := newWalker(c.basePath, c.config)
+	treeItems, err := w.walkAll(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to walk directory: %w", err)
 	}
 
+	c.mu.Lock()
+	c.denied = w.denied
+	c.mu.Unlock()
 	return treeItems, nil
 }
 
+// DeniedPaths returns the directories the last tree listing skipped because they could not
+// be read, relative to the folder
+func (c *Client) DeniedPaths() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.denied
+}
+
 // sanitizePath validates and sanitizes file paths to prevent directory traversal attacks
 func (c *Client) sanitizePath(filePath string) (string, error) {
 	// Clean the path to resolve any . or .. elements
@@ -151,26 +132,51 @@ func (c *Client) GetFileContent(ctx context.Context, repoPath, filePath, branch
 	// Check if file exists and is readable
 	info, err := os.Stat(fullPath)
 	if err != nil {
-		return "", fmt.Errorf("file not found: %s", filePath)
+		return "", sherpaerrors.New(sherpaerrors.KindNotFound, fmt.Sprintf("file not found: %s", filePath))
 	}
 
 	if info.IsDir() {
 		return "", fmt.Errorf("path is a directory: %s", filePath)
 	}
+	if err := checkReadable(fullPath); err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
 
 	// Check if file is binary
 	if utils.IsBinaryFile(fullPath) {
-		return "", fmt.Errorf("file is binary: %s", filePath)
+		return "", sherpaerrors.New(sherpaerrors.KindBinarySkipped, fmt.Sprintf("file is binary: %s", filePath))
 	}
 
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+		return "", fmt.Errorf("failed to read file %s: %w", filePath, classifyError(err))
 	}
 
 	return string(content), nil
 }
 
+// ReadRawFile returns the content of a file as it is, binary files included
+func (c *Client) ReadRawFile(ctx context.Context, repoPath, filePath, branch string) ([]byte, error) {
+	fullPath, err := c.sanitizePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, sherpaerrors.New(sherpaerrors.KindNotFound, fmt.Sprintf("file not found: %s", filePath))
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("path is a directory: %s", filePath)
+	}
+
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, classifyError(err))
+	}
+	return content, nil
+}
+
 // GetFileInfo returns information about a file
 func (c *Client) GetFileInfo(ctx context.Context, repoPath, filePath, branch string) (*models.FileInfo, error) {
 	fullPath, err := c.sanitizePath(filePath)
@@ -187,7 +193,7 @@ func (c *Client) GetFileInfo(ctx context.Context, repoPath, filePath, branch str
 		return &models.FileInfo{
 			Path:  filePath,
 			Name:  filepath.Base(filePath),
-			Error: fmt.Errorf("file not found: %s", filePath),
+			Error: sherpaerrors.New(sherpaerrors.KindNotFound, fmt.Sprintf("file not found: %s", filePath)),
 		}, nil
 	}
 
@@ -205,6 +211,12 @@ func (c *Client) GetFileInfo(ctx context.Context, repoPath, filePath, branch str
 		return fileInfo, nil
 	}
 
+	// Unreadable files would otherwise be taken for binary ones and vanish from the output
+	if err := checkReadable(fullPath); err != nil {
+		fileInfo.Error = fmt.Errorf("failed to read file: %w", err)
+		return fileInfo, nil
+	}
+
 	// Check if file is binary
 	if utils.IsBinaryFile(fullPath) {
 		fileInfo.IsBinary = true
@@ -215,11 +227,12 @@ func (c *Client) GetFileInfo(ctx context.Context, repoPath, filePath, branch str
 	// Read file content
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
-		fileInfo.Error = fmt.Errorf("failed to read file: %w", err)
+		fileInfo.Error = fmt.Errorf("failed to read file: %w", classifyError(err))
 		return fileInfo, nil
 	}
 
 	fileInfo.Content = string(content)
+	fileInfo.ContentSize = int64(len(content))
 	return fileInfo, nil
 }
 
@@ -231,11 +244,11 @@ func (c *Client) GetMultipleFiles(ctx context.Context, repoPath string, filePath
 	maxFiles := config.MaxFiles
 
 	if len(filePaths) > maxFiles {
-		return nil, fmt.Errorf("too many files to process safely: %d (max: %d)", len(filePaths), maxFiles)
+		return nil, sherpaerrors.Wrap(sherpaerrors.KindTooLarge, fmt.Errorf("too many files to process safely: %d (max: %d)", len(filePaths), maxFiles))
 	}
 
 	if int64(len(filePaths))*maxMemoryPerFile > maxTotalMemory {
-		return nil, fmt.Errorf("estimated memory usage too high for %d files", len(filePaths))
+		return nil, sherpaerrors.Wrap(sherpaerrors.KindTooLarge, fmt.Errorf("estimated memory usage too high for %d files", len(filePaths)))
 	}
 
 	// Use a semaphore to limit concurrency and WaitGroup to wait for completion
@@ -272,6 +285,61 @@ func (c *Client) GetMultipleFiles(ctx context.Context, repoPath string, filePath
 	return results, nil
 }
 
+// SearchCode returns the paths of text files containing every term of the query.
+// Terms are separated by whitespace and the AND keyword; matching is case-insensitive.
+func (c *Client) SearchCode(ctx context.Context, repoPath, query, branch string) ([]string, error) {
+	terms := parseSearchTerms(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("empty search query")
+	}
+
+	tree, err := c.GetRepositoryTree(ctx, repoPath, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range tree {
+		if entry.Type != "blob" {
+			continue
+		}
+
+		content, err := c.GetFileContent(ctx, repoPath, entry.Path, branch)
+		if err != nil {
+			continue // Binary or unreadable files cannot match
+		}
+
+		lowerContent := strings.ToLower(content)
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(lowerContent, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			paths = append(paths, entry.Path)
+		}
+	}
+
+	return paths, nil
+}
+
+// parseSearchTerms splits a query like "payment AND retry" into lowercase terms
+func parseSearchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if field == "AND" {
+			continue
+		}
+		field = strings.Trim(field, `"'`)
+		if field != "" {
+			terms = append(terms, strings.ToLower(field))
+		}
+	}
+	return terms
+}
+
 // TestConnection tests if the local folder is accessible
 func (c *Client) TestConnection(ctx context.Context) error {
 	// Test if we can read the directory
@@ -286,3 +354,24 @@ func (c *Client) TestConnection(ctx context.Context) error {
 func (c *Client) GetBasePath() string {
 	return c.basePath
 }
+
+// checkReadable returns the error opening the file at fullPath, if any
+func checkReadable(fullPath string) error {
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return classifyError(err)
+	}
+	return file.Close()
+}
+
+// classifyError maps file system errors onto the shared error taxonomy
+func classifyError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return sherpaerrors.Wrap(sherpaerrors.KindNotFound, err)
+	case errors.Is(err, fs.ErrPermission):
+		return sherpaerrors.Wrap(sherpaerrors.KindAuth, err)
+	default:
+		return err
+	}
+}
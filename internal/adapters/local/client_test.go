@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	sherpaerrors "sherpa/pkg/errors"
 	"sherpa/pkg/models"
 
 	"github.com/stretchr/testify/assert"
@@ -212,6 +213,39 @@ func TestClient_GetFileContent(t *testing.T) {
 	}
 }
 
+func TestClient_GetFileContent_ErrorKinds(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	client, err := NewClient(tmpDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		filePath string
+		expected sherpaerrors.Kind
+	}{
+		{
+			name:     "should classify missing files as not found",
+			filePath: "nonexistent.go",
+			expected: sherpaerrors.KindNotFound,
+		},
+		{
+			name:     "should classify binary files as skipped",
+			filePath: "binary.bin",
+			expected: sherpaerrors.KindBinarySkipped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetFileContent(context.Background(), "test", tt.filePath, "main")
+			require.Error(t, err)
+			assert.Equal(t, tt.expected, sherpaerrors.KindOf(err))
+		})
+	}
+}
+
 func TestClient_GetFileInfo(t *testing.T) {
 	tmpDir := setupTestDir(t)
 	defer os.RemoveAll(tmpDir)
@@ -282,6 +316,13 @@ func TestClient_GetFileInfo(t *testing.T) {
 			if tt.hasContent {
 				assert.NotEmpty(t, fileInfo.Content)
 				assert.True(t, fileInfo.IsText)
+				assert.Equal(t, int64(len(fileInfo.Content)), fileInfo.ContentSize)
+			}
+
+			if !tt.isDir && !tt.expectError {
+				info, err := os.Stat(filepath.Join(tmpDir, tt.filePath))
+				require.NoError(t, err)
+				assert.Equal(t, info.Size(), fileInfo.Size)
 			}
 
 			if tt.isDir {
@@ -387,6 +428,54 @@ func TestClient_GetMultipleFiles_Concurrency(t *testing.T) {
 	}
 }
 
+func TestClient_SearchCode(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	client, err := NewClient(tmpDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "should match files containing a single term",
+			query:    "package",
+			expected: []string{"main.go", "subdir/test.go"},
+		},
+		{
+			name:     "should require every term joined by AND",
+			query:    "package AND hello",
+			expected: []string{"main.go"},
+		},
+		{
+			name:     "should return nothing when no file matches",
+			query:    "payment AND retry",
+			expected: nil,
+		},
+		{
+			name:    "should error on empty query",
+			query:   "AND",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := client.SearchCode(context.Background(), "test", tt.query, "main")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expected, paths)
+		})
+	}
+}
+
 func TestClient_TestConnection(t *testing.T) {
 	tmpDir := setupTestDir(t)
 	defer os.RemoveAll(tmpDir)
@@ -0,0 +1,39 @@
+//go:build darwin
+
+package local
+
+import (
+	"io/fs"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// networkFilesystems are the names of network and FUSE filesystems
+var networkFilesystems = map[string]bool{
+	"nfs":     true,
+	"smbfs":   true,
+	"afpfs":   true,
+	"webdav":  true,
+	"cifs":    true,
+	"macfuse": true,
+	"osxfuse": true,
+}
+
+// deviceID returns the device holding the file described by info
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
+
+// networkFilesystem reports whether path is on a network or FUSE filesystem
+func networkFilesystem(path string) bool {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return false
+	}
+	return networkFilesystems[unix.ByteSliceToString(stat.Fstypename[:])]
+}
@@ -0,0 +1,42 @@
+//go:build linux
+
+package local
+
+import (
+	"io/fs"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// networkFilesystems are the statfs types of network and FUSE filesystems
+var networkFilesystems = map[uint32]bool{
+	unix.NFS_SUPER_MAGIC:  true,
+	unix.SMB_SUPER_MAGIC:  true,
+	unix.SMB2_SUPER_MAGIC: true,
+	unix.CIFS_SUPER_MAGIC: true,
+	unix.AFS_SUPER_MAGIC:  true,
+	unix.AFS_FS_MAGIC:     true,
+	unix.CODA_SUPER_MAGIC: true,
+	unix.V9FS_MAGIC:       true,
+	unix.CEPH_SUPER_MAGIC: true,
+	unix.FUSE_SUPER_MAGIC: true,
+}
+
+// deviceID returns the device holding the file described by info
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
+
+// networkFilesystem reports whether path is on a network or FUSE filesystem
+func networkFilesystem(path string) bool {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return false
+	}
+	return networkFilesystems[uint32(stat.Type)]
+}
@@ -0,0 +1,17 @@
+//go:build !linux && !darwin
+
+package local
+
+import "io/fs"
+
+// deviceID reports no device on platforms where Sherpa does not tell mounts apart, so
+// every mount is walked
+func deviceID(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+// networkFilesystem reports no network filesystem on platforms where Sherpa does not
+// tell mounts apart
+func networkFilesystem(path string) bool {
+	return false
+}
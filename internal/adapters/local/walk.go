@@ -0,0 +1,144 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// walker lists a local folder without hanging on the folders Sherpa may be pointed at by
+// mistake, like a home directory: symlinked directories are walked once each so symlink
+// loops end, excluded mounts are skipped, and the walk fails past a maximum number of
+// entries instead of listing millions of files
+type walker struct {
+	basePath string
+	config   models.LocalConfig
+	rootDev  uint64
+	hasDev   bool
+	visited  map[string]bool // Real paths of the directories walked when following symlinks
+	entries  []models.RepositoryTree
+	denied   []string // Directories that could not be listed for lack of permission
+}
+
+// newWalker creates a walker of basePath
+func newWalker(basePath string, config models.LocalConfig) *walker {
+	w := &walker{basePath: basePath, config: config, visited: make(map[string]bool)}
+	if info, err := os.Stat(basePath); err == nil {
+		w.rootDev, w.hasDev = deviceID(info)
+	}
+	if realPath, err := filepath.EvalSymlinks(basePath); err == nil {
+		w.visited[realPath] = true
+	}
+	return w
+}
+
+// walkAll lists every entry below the folder, directories before their content and
+// entries of a directory sorted by name
+func (w *walker) walkAll(ctx context.Context) ([]models.RepositoryTree, error) {
+	if err := w.walk(ctx, w.basePath, ""); err != nil {
+		return nil, err
+	}
+	return w.entries, nil
+}
+
+// walk lists the entries of dir, whose path relative to the folder is relDir
+func (w *walker) walk(ctx context.Context, dir, relDir string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil && errors.Is(err, fs.ErrPermission) && relDir != "" {
+		w.denied = append(w.denied, relDir)
+	}
+	if err != nil && len(dirEntries) == 0 {
+		return nil // Continue walking even if we can't read a specific directory
+	}
+
+	for _, d := range dirEntries {
+		path := filepath.Join(dir, d.Name())
+		relPath := d.Name()
+		if relDir != "" {
+			relPath = relDir + "/" + d.Name()
+		}
+
+		var info fs.FileInfo
+		if d.Type()&fs.ModeSymlink != 0 {
+			if !w.config.FollowSymlinks {
+				continue // Skip symlinks for security
+			}
+			if info, err = os.Stat(path); err != nil {
+				continue // Dangling symlink
+			}
+		} else if info, err = d.Info(); err != nil {
+			continue
+		}
+
+		// Sockets, pipes and devices would block or never end when read
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			continue
+		}
+		if info.IsDir() && !w.enter(path, relPath, info) {
+			continue
+		}
+
+		if w.config.MaxWalkEntries > 0 && len(w.entries) >= w.config.MaxWalkEntries {
+			return sherpaerrors.WithHint(
+				sherpaerrors.New(sherpaerrors.KindTooLarge, fmt.Sprintf("folder %s has more than %d entries", w.basePath, w.config.MaxWalkEntries)),
+				"point Sherpa at a smaller folder, or raise local.max_walk_entries in the configuration file")
+		}
+
+		entry := models.RepositoryTree{
+			ID:   relPath,
+			Name: d.Name(),
+			Type: "blob",
+			Path: relPath,
+			Mode: "100644", // Default file mode
+			Size: info.Size(),
+		}
+		if info.IsDir() {
+			entry.Type = "tree"
+			entry.Size = 0
+		}
+		w.entries = append(w.entries, entry)
+
+		if info.IsDir() {
+			if err := w.walk(ctx, path, relPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// enter reports whether the directory at path is walked, given the mount policy and the
+// directories already walked through symlinks
+func (w *walker) enter(path, relPath string, info fs.FileInfo) bool {
+	if w.hasDev && w.config.Mounts != "" && w.config.Mounts != models.MountsAll {
+		if dev, ok := deviceID(info); ok && dev != w.rootDev {
+			if w.config.Mounts == models.MountsSame || networkFilesystem(path) {
+				logger.Logger.WithField("path", relPath).Debug("Skipping mounted filesystem")
+				return false
+			}
+		}
+	}
+
+	if w.config.FollowSymlinks {
+		realPath, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return false
+		}
+		if w.visited[realPath] {
+			logger.Logger.WithField("path", relPath).Debug("Skipping directory already walked through a symlink")
+			return false
+		}
+		w.visited[realPath] = true
+	}
+	return true
+}
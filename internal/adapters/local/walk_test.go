@@ -0,0 +1,125 @@
+package local
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetRepositoryTree_Walk(t *testing.T) {
+	// setupLoop creates a folder whose subdirectory links back to the folder itself
+	setupLoop := func(t *testing.T) string {
+		t.Helper()
+		tmpDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "src"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "src", "main.go"), []byte("package main\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# loop\n"), 0644))
+		if err := os.Symlink(tmpDir, filepath.Join(tmpDir, "src", "loop")); err != nil {
+			t.Skipf("symlinks are not supported: %v", err)
+		}
+		return tmpDir
+	}
+
+	paths := func(tree []models.RepositoryTree) []string {
+		var paths []string
+		for _, entry := range tree {
+			paths = append(paths, entry.Path)
+		}
+		return paths
+	}
+
+	t.Run("should skip symlinks by default", func(t *testing.T) {
+		client, err := NewClient(setupLoop(t))
+		require.NoError(t, err)
+
+		tree, err := client.GetRepositoryTree(context.Background(), "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md", "src", "src/main.go"}, paths(tree))
+	})
+
+	t.Run("should walk each directory once when following symlinks", func(t *testing.T) {
+		tmpDir := setupLoop(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "docs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "docs", "guide.md"), []byte("# guide\n"), 0644))
+		require.NoError(t, os.Symlink(filepath.Join(tmpDir, "docs"), filepath.Join(tmpDir, "manual")))
+		require.NoError(t, os.Symlink(filepath.Join(tmpDir, "README.md"), filepath.Join(tmpDir, "src", "README.md")))
+
+		client, err := NewClientWithConfig(tmpDir, models.LocalConfig{FollowSymlinks: true})
+		require.NoError(t, err)
+
+		tree, err := client.GetRepositoryTree(context.Background(), "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md", "docs", "docs/guide.md", "src", "src/README.md", "src/main.go"}, paths(tree))
+	})
+
+	t.Run("should fail past the maximum number of entries", func(t *testing.T) {
+		client, err := NewClientWithConfig(setupLoop(t), models.LocalConfig{MaxWalkEntries: 2})
+		require.NoError(t, err)
+
+		_, err = client.GetRepositoryTree(context.Background(), "", "")
+		require.Error(t, err)
+		assert.Equal(t, sherpaerrors.KindTooLarge, sherpaerrors.KindOf(err))
+		assert.Contains(t, sherpaerrors.HintOf(err), "local.max_walk_entries")
+	})
+
+	t.Run("should stop when the context is canceled", func(t *testing.T) {
+		client, err := NewClient(setupLoop(t))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = client.GetRepositoryTree(ctx, "", "")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should walk every directory on the filesystem of the folder", func(t *testing.T) {
+		client, err := NewClientWithConfig(setupLoop(t), models.LocalConfig{Mounts: models.MountsSame})
+		require.NoError(t, err)
+
+		tree, err := client.GetRepositoryTree(context.Background(), "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"README.md", "src", "src/main.go"}, paths(tree))
+	})
+}
+
+func TestClient_PermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "app.conf"), []byte("secret\n"), 0000))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "private"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "private", "key.pem"), []byte("key\n"), 0644))
+	require.NoError(t, os.Chmod(filepath.Join(tmpDir, "private"), 0000))
+	t.Cleanup(func() { _ = os.Chmod(filepath.Join(tmpDir, "private"), 0755) })
+
+	client, err := NewClient(tmpDir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("should report the directories that cannot be listed", func(t *testing.T) {
+		_, err := client.GetRepositoryTree(ctx, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"private"}, client.DeniedPaths())
+	})
+
+	t.Run("should fail to read files without permission instead of taking them for binary ones", func(t *testing.T) {
+		info, err := client.GetFileInfo(ctx, "", "app.conf", "")
+		require.NoError(t, err)
+		assert.ErrorIs(t, info.Error, fs.ErrPermission)
+		assert.False(t, info.IsBinary)
+
+		_, err = client.GetFileContent(ctx, "", "app.conf", "")
+		assert.ErrorIs(t, err, fs.ErrPermission)
+	})
+}
@@ -0,0 +1,27 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrOffline is returned for network requests made while network access is disabled
+var ErrOffline = errors.New("network access is disabled by --offline")
+
+// offlineTransport is an http.RoundTripper refusing every request
+type offlineTransport struct{}
+
+// NewOfflineTransport returns a transport refusing every request with ErrOffline, so code
+// paths reaching the network in offline mode fail instead of sending traffic
+func NewOfflineTransport() http.RoundTripper {
+	return offlineTransport{}
+}
+
+// RoundTrip refuses the request without sending it
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), ErrOffline)
+}
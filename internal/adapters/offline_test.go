@@ -0,0 +1,28 @@
+package adapters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOfflineTransport(t *testing.T) {
+	t.Run("should refuse requests without sending them", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewOfflineTransport()}
+		resp, err := client.Get(server.URL)
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		assert.ErrorIs(t, err, ErrOffline)
+		assert.False(t, called)
+	})
+}
@@ -0,0 +1,165 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sherpa/internal/adapters/download"
+	"sherpa/pkg/models"
+)
+
+// ParseGoModule parses a module path with an optional version, like
+// github.com/foo/bar@v1.2.3, into a Go module input. A missing version means latest.
+func ParseGoModule(spec string) (*models.RepositoryInfo, error) {
+	modulePath, version, _ := strings.Cut(strings.TrimSpace(spec), "@")
+	if version == "" {
+		version = "latest"
+	}
+	if modulePath == "" || strings.ContainsAny(modulePath, " \\:") || strings.HasPrefix(modulePath, "/") || strings.Contains(modulePath, "..") {
+		return nil, fmt.Errorf("invalid Go module '%s', expected 'module/path@version'", spec)
+	}
+	if strings.ContainsAny(version, " /\\") {
+		return nil, fmt.Errorf("invalid version '%s' for Go module %s", version, modulePath)
+	}
+
+	return &models.RepositoryInfo{
+		Platform: models.PlatformLocal,
+		Owner:    modulePath,
+		Name:     path.Base(modulePath) + "@" + version,
+		FullName: modulePath + "@" + version,
+		URL:      goModuleURL(modulePath, version),
+		Kind:     models.KindGoModule,
+	}, nil
+}
+
+// CreateGoModuleProvider downloads a Go module version from proxy into parentDir and
+// creates a provider serving it. The returned directory must be removed once processing
+// is done.
+func CreateGoModuleProvider(ctx context.Context, client *http.Client, proxy string, repoInfo *models.RepositoryInfo, parentDir string) (Provider, string, error) {
+	modulePath, version, _ := strings.Cut(repoInfo.FullName, "@")
+	folderPath, version, err := download.FetchGoModule(ctx, client, proxy, modulePath, version, parentDir)
+	if err != nil {
+		return nil, "", err
+	}
+	return newPackageProvider(folderPath, path.Base(modulePath)+"@"+version, goModuleURL(modulePath, version))
+}
+
+// goModuleURL returns the documentation page of a module version
+func goModuleURL(modulePath, version string) string {
+	if version == "latest" {
+		return "https://pkg.go.dev/" + modulePath
+	}
+	return "https://pkg.go.dev/" + modulePath + "@" + version
+}
+
+// ParseNPMPackage parses an npm package name with an optional version or dist-tag, like
+// left-pad@1.3.0 or @scope/name@latest, into an npm package input. A missing version means latest.
+func ParseNPMPackage(spec string) (*models.RepositoryInfo, error) {
+	spec = strings.TrimSpace(spec)
+	name, version := spec, "latest"
+	// The leading @ of a scoped name is not a version separator
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		name, version = spec[:i], spec[i+1:]
+	}
+	// Only scoped names have a slash, between the scope and the name
+	slashes := 0
+	if strings.HasPrefix(name, "@") {
+		slashes = 1
+	}
+	if name == "" || version == "" || strings.ContainsAny(name, " \\:") || strings.Contains(name, "..") || strings.Count(name, "/") != slashes {
+		return nil, fmt.Errorf("invalid npm package '%s', expected 'name@version' or '@scope/name@version'", spec)
+	}
+	if strings.ContainsAny(version, " /\\") {
+		return nil, fmt.Errorf("invalid version '%s' for npm package %s", version, name)
+	}
+
+	return &models.RepositoryInfo{
+		Platform: models.PlatformLocal,
+		Owner:    name,
+		Name:     path.Base(name) + "@" + version,
+		FullName: name + "@" + version,
+		URL:      npmPackageURL(name, version),
+		Kind:     models.KindNPMPackage,
+	}, nil
+}
+
+// ParsePyPIPackage parses a PyPI package name with an optional pinned version, like
+// requests==2.31.0, into a PyPI package input. A missing version means latest.
+func ParsePyPIPackage(spec string) (*models.RepositoryInfo, error) {
+	name, version, _ := strings.Cut(strings.TrimSpace(spec), "==")
+	if version == "" {
+		version = "latest"
+	}
+	if name == "" || strings.ContainsAny(name, " /\\:@<>=!~") || strings.Contains(name, "..") {
+		return nil, fmt.Errorf("invalid PyPI package '%s', expected 'name==version'", spec)
+	}
+	if strings.ContainsAny(version, " /\\=") {
+		return nil, fmt.Errorf("invalid version '%s' for PyPI package %s", version, name)
+	}
+
+	return &models.RepositoryInfo{
+		Platform: models.PlatformLocal,
+		Owner:    name,
+		Name:     name + "==" + version,
+		FullName: name + "==" + version,
+		URL:      pypiPackageURL(name, version),
+		Kind:     models.KindPyPIPackage,
+	}, nil
+}
+
+// CreateNPMPackageProvider downloads an npm package version from registry into parentDir
+// and creates a provider serving it. The returned directory must be removed once
+// processing is done.
+func CreateNPMPackageProvider(ctx context.Context, client *http.Client, registry string, repoInfo *models.RepositoryInfo, parentDir string) (Provider, string, error) {
+	i := strings.LastIndex(repoInfo.FullName, "@")
+	name, version := repoInfo.FullName[:i], repoInfo.FullName[i+1:]
+	folderPath, version, err := download.FetchNPMPackage(ctx, client, registry, name, version, parentDir)
+	if err != nil {
+		return nil, "", err
+	}
+	return newPackageProvider(folderPath, name+"@"+version, npmPackageURL(name, version))
+}
+
+// CreatePyPIPackageProvider downloads a PyPI package release from index into parentDir
+// and creates a provider serving it. The returned directory must be removed once
+// processing is done.
+func CreatePyPIPackageProvider(ctx context.Context, client *http.Client, index string, repoInfo *models.RepositoryInfo, parentDir string) (Provider, string, error) {
+	name, version, _ := strings.Cut(repoInfo.FullName, "==")
+	folderPath, version, err := download.FetchPyPIPackage(ctx, client, index, name, version, parentDir)
+	if err != nil {
+		return nil, "", err
+	}
+	return newPackageProvider(folderPath, name+"=="+version, pypiPackageURL(name, version))
+}
+
+// newPackageProvider creates the provider of a fetched package, removing its directory
+// when that fails
+func newPackageProvider(folderPath, name, packageURL string) (Provider, string, error) {
+	provider, err := newDownloadProvider(folderPath, name, packageURL)
+	if err != nil {
+		os.RemoveAll(filepath.Dir(folderPath))
+		return nil, "", err
+	}
+	return provider, filepath.Dir(folderPath), nil
+}
+
+// npmPackageURL returns the npm page of a package version
+func npmPackageURL(name, version string) string {
+	if version == "latest" {
+		return "https://www.npmjs.com/package/" + name
+	}
+	return "https://www.npmjs.com/package/" + name + "/v/" + version
+}
+
+// pypiPackageURL returns the PyPI page of a package release
+func pypiPackageURL(name, version string) string {
+	if version == "latest" {
+		return "https://pypi.org/project/" + name + "/"
+	}
+	return "https://pypi.org/project/" + name + "/" + version + "/"
+}
@@ -0,0 +1,147 @@
+package adapters
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoModule(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             string
+		expectedName     string
+		expectedFullName string
+		expectedURL      string
+		expectError      bool
+	}{
+		{
+			name:             "should parse a module version",
+			spec:             "github.com/foo/bar@v1.2.3",
+			expectedName:     "bar@v1.2.3",
+			expectedFullName: "github.com/foo/bar@v1.2.3",
+			expectedURL:      "https://pkg.go.dev/github.com/foo/bar@v1.2.3",
+		},
+		{
+			name:             "should default to the latest version",
+			spec:             "golang.org/x/mod",
+			expectedName:     "mod@latest",
+			expectedFullName: "golang.org/x/mod@latest",
+			expectedURL:      "https://pkg.go.dev/golang.org/x/mod",
+		},
+		{name: "should reject an empty module path", spec: "@v1.0.0", expectError: true},
+		{name: "should reject paths escaping the module", spec: "github.com/foo/../bar@v1.0.0", expectError: true},
+		{name: "should reject versions with slashes", spec: "github.com/foo/bar@v1/x", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseGoModule(tt.spec)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, models.PlatformLocal, result.Platform)
+			assert.Equal(t, models.KindGoModule, result.Kind)
+			assert.Equal(t, tt.expectedName, result.Name)
+			assert.Equal(t, tt.expectedFullName, result.FullName)
+			assert.Equal(t, tt.expectedURL, result.URL)
+		})
+	}
+}
+
+func TestParseNPMPackage(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             string
+		expectedName     string
+		expectedFullName string
+		expectedURL      string
+		expectError      bool
+	}{
+		{
+			name:             "should parse a package version",
+			spec:             "left-pad@1.3.0",
+			expectedName:     "left-pad@1.3.0",
+			expectedFullName: "left-pad@1.3.0",
+			expectedURL:      "https://www.npmjs.com/package/left-pad/v/1.3.0",
+		},
+		{
+			name:             "should parse scoped packages",
+			spec:             "@types/node@20.1.0",
+			expectedName:     "node@20.1.0",
+			expectedFullName: "@types/node@20.1.0",
+			expectedURL:      "https://www.npmjs.com/package/@types/node/v/20.1.0",
+		},
+		{
+			name:             "should default scoped packages to the latest version",
+			spec:             "@types/node",
+			expectedName:     "node@latest",
+			expectedFullName: "@types/node@latest",
+			expectedURL:      "https://www.npmjs.com/package/@types/node",
+		},
+		{name: "should reject nested names", spec: "left/pad@1.0.0", expectError: true},
+		{name: "should reject empty versions", spec: "left-pad@", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseNPMPackage(tt.spec)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, models.KindNPMPackage, result.Kind)
+			assert.Equal(t, tt.expectedName, result.Name)
+			assert.Equal(t, tt.expectedFullName, result.FullName)
+			assert.Equal(t, tt.expectedURL, result.URL)
+		})
+	}
+}
+
+func TestParsePyPIPackage(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             string
+		expectedFullName string
+		expectedURL      string
+		expectError      bool
+	}{
+		{
+			name:             "should parse a pinned release",
+			spec:             "requests==2.31.0",
+			expectedFullName: "requests==2.31.0",
+			expectedURL:      "https://pypi.org/project/requests/2.31.0/",
+		},
+		{
+			name:             "should default to the latest release",
+			spec:             "requests",
+			expectedFullName: "requests==latest",
+			expectedURL:      "https://pypi.org/project/requests/",
+		},
+		{name: "should reject version ranges", spec: "requests>=2.0", expectError: true},
+		{name: "should reject empty names", spec: "==1.0", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParsePyPIPackage(tt.spec)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, models.KindPyPIPackage, result.Kind)
+			assert.Equal(t, tt.expectedFullName, result.FullName)
+			assert.Equal(t, tt.expectedURL, result.URL)
+		})
+	}
+}
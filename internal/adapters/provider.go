@@ -3,16 +3,22 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"sherpa/internal/adapters/download"
+	"sherpa/internal/adapters/gitea"
 	"sherpa/internal/adapters/github"
 	"sherpa/internal/adapters/gitlab"
 	"sherpa/internal/adapters/local"
 	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
 )
 
 // Provider defines the interface for VCS providers (GitLab, GitHub, etc.)
@@ -25,6 +31,84 @@ type Provider interface {
 	TestConnection(ctx context.Context) error
 }
 
+// CodeSearcher is implemented by providers able to search file contents,
+// allowing targeted fetches without listing the whole repository tree
+type CodeSearcher interface {
+	SearchCode(ctx context.Context, repoPath, query, branch string) ([]string, error)
+}
+
+// CommitResolver is implemented by providers that can pin a ref to an exact commit
+type CommitResolver interface {
+	ResolveCommit(ctx context.Context, repoPath, ref string) (string, error)
+}
+
+// ChangeLister is implemented by providers that can list the files changed between two
+// commits, letting unchanged files be reused from a previous run
+type ChangeLister interface {
+	ChangedFiles(ctx context.Context, repoPath, base, head string) ([]string, error)
+}
+
+// RefComparer is implemented by providers that can compare two refs with the diffs of the
+// files changed between them, so only the changes of a release can be processed
+type RefComparer interface {
+	CompareRefs(ctx context.Context, repoPath, base, head string) (*models.Comparison, error)
+}
+
+// IssueLister is implemented by providers that can list the issues of a repository, so
+// project context found in issues can be included with the code
+type IssueLister interface {
+	ListIssues(ctx context.Context, repoPath string, config models.IssuesConfig) ([]models.Issue, error)
+}
+
+// ReleaseLister is implemented by providers that can list the releases of a repository,
+// so release notes can be included with the code
+type ReleaseLister interface {
+	ListReleases(ctx context.Context, repoPath string, limit int) ([]models.Release, error)
+}
+
+// WikiReader is implemented by providers that can read the wiki of a repository, which
+// often holds architectural documentation missing from the code
+type WikiReader interface {
+	ListWikiPages(ctx context.Context, repoPath string) ([]models.WikiPage, error)
+}
+
+// TreeWalker is implemented by providers listing the repository tree page by page, so file
+// contents can be fetched while the rest of the tree is still being listed
+type TreeWalker interface {
+	WalkRepositoryTree(ctx context.Context, repoPath, ref string, fn func(entries []models.RepositoryTree) error) error
+}
+
+// SubtreeLister is implemented by providers listing a single directory of the repository, so
+// runs restricted to a few directories do not list the whole tree. Paths are relative to the
+// repository root, and a directory missing at ref has no entries.
+type SubtreeLister interface {
+	ListSubtree(ctx context.Context, repoPath, ref, dir string, recursive bool) ([]models.RepositoryTree, error)
+}
+
+// RawFileReader is implemented by providers refusing binary files in GetFileContent, to read
+// files as they are, like the images of docs described for their alt text
+type RawFileReader interface {
+	ReadRawFile(ctx context.Context, repoPath, filePath, ref string) ([]byte, error)
+}
+
+// PermissionReporter is implemented by providers that skip the directories they are not
+// allowed to read while listing the tree, so they are reported instead of vanishing
+type PermissionReporter interface {
+	DeniedPaths() []string
+}
+
+// RepositoryLister is implemented by providers that can list the repositories of an
+// organization, user or group, so a whole group can be processed from one argument
+type RepositoryLister interface {
+	ListRepositories(ctx context.Context, group string) ([]models.ListedRepository, error)
+}
+
+// PullRequestFetcher is implemented by providers that can read a pull request with the
+// files it changes, so a review can be given only the changed code
+type PullRequestFetcher interface {
+	GetPullRequest(ctx context.Context, repoPath string, number int) (*models.PullRequest, error)
+}
+
 // GitLabProvider wraps the GitLab client to implement the Provider interface
 type GitLabProvider struct {
 	client *gitlab.Client
@@ -32,7 +116,12 @@ type GitLabProvider struct {
 
 // NewGitLabProvider creates a new GitLab provider
 func NewGitLabProvider(baseURL, token string) (*GitLabProvider, error) {
-	client, err := gitlab.NewClient(baseURL, token)
+	return NewGitLabProviderWithTransport(baseURL, token, nil)
+}
+
+// NewGitLabProviderWithTransport creates a GitLab provider sending requests through transport
+func NewGitLabProviderWithTransport(baseURL, token string, transport http.RoundTripper) (*GitLabProvider, error) {
+	client, err := gitlab.NewClientWithTransport(baseURL, token, transport)
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +152,46 @@ func (p *GitLabProvider) TestConnection(ctx context.Context) error {
 	return p.client.TestConnection(ctx)
 }
 
+func (p *GitLabProvider) SearchCode(ctx context.Context, repoPath, query, branch string) ([]string, error) {
+	return p.client.SearchCode(ctx, repoPath, query, branch)
+}
+
+func (p *GitLabProvider) ResolveCommit(ctx context.Context, repoPath, ref string) (string, error) {
+	return p.client.ResolveCommit(ctx, repoPath, ref)
+}
+
+func (p *GitLabProvider) ChangedFiles(ctx context.Context, repoPath, base, head string) ([]string, error) {
+	return p.client.ChangedFiles(ctx, repoPath, base, head)
+}
+
+func (p *GitLabProvider) CompareRefs(ctx context.Context, repoPath, base, head string) (*models.Comparison, error) {
+	return p.client.CompareRefs(ctx, repoPath, base, head)
+}
+
+func (p *GitLabProvider) ListIssues(ctx context.Context, repoPath string, config models.IssuesConfig) ([]models.Issue, error) {
+	return p.client.ListIssues(ctx, repoPath, config)
+}
+
+func (p *GitLabProvider) WalkRepositoryTree(ctx context.Context, repoPath, ref string, fn func(entries []models.RepositoryTree) error) error {
+	return p.client.WalkRepositoryTree(ctx, repoPath, ref, fn)
+}
+
+func (p *GitLabProvider) ListSubtree(ctx context.Context, repoPath, ref, dir string, recursive bool) ([]models.RepositoryTree, error) {
+	return p.client.ListSubtree(ctx, repoPath, ref, dir, recursive)
+}
+
+func (p *GitLabProvider) ListReleases(ctx context.Context, repoPath string, limit int) ([]models.Release, error) {
+	return p.client.ListReleases(ctx, repoPath, limit)
+}
+
+func (p *GitLabProvider) ListWikiPages(ctx context.Context, repoPath string) ([]models.WikiPage, error) {
+	return p.client.ListWikiPages(ctx, repoPath)
+}
+
+func (p *GitLabProvider) ListRepositories(ctx context.Context, group string) ([]models.ListedRepository, error) {
+	return p.client.ListRepositories(ctx, group)
+}
+
 // GitHubProvider wraps the GitHub client to implement the Provider interface
 type GitHubProvider struct {
 	client *github.Client
@@ -70,7 +199,12 @@ type GitHubProvider struct {
 
 // NewGitHubProvider creates a new GitHub provider
 func NewGitHubProvider(baseURL, token string) (*GitHubProvider, error) {
-	client, err := github.NewClient(baseURL, token)
+	return NewGitHubProviderWithTransport(baseURL, token, nil)
+}
+
+// NewGitHubProviderWithTransport creates a GitHub provider sending requests through transport
+func NewGitHubProviderWithTransport(baseURL, token string, transport http.RoundTripper) (*GitHubProvider, error) {
+	client, err := github.NewClientWithTransport(baseURL, token, transport)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +227,14 @@ func (p *GitHubProvider) GetRepositoryTree(ctx context.Context, repoPath, branch
 	return p.client.GetRepositoryTree(ctx, owner, repo, branch)
 }
 
+func (p *GitHubProvider) ListSubtree(ctx context.Context, repoPath, ref, dir string, recursive bool) ([]models.RepositoryTree, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.ListSubtree(ctx, owner, repo, ref, dir, recursive)
+}
+
 func (p *GitHubProvider) GetFileContent(ctx context.Context, repoPath, filePath, branch string) (string, error) {
 	owner, repo, err := parseGitHubRepoPath(repoPath)
 	if err != nil {
@@ -121,6 +263,149 @@ func (p *GitHubProvider) TestConnection(ctx context.Context) error {
 	return p.client.TestConnection(ctx)
 }
 
+func (p *GitHubProvider) SearchCode(ctx context.Context, repoPath, query, branch string) ([]string, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.SearchCode(ctx, owner, repo, query, branch)
+}
+
+func (p *GitHubProvider) ResolveCommit(ctx context.Context, repoPath, ref string) (string, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return p.client.ResolveCommit(ctx, owner, repo, ref)
+}
+
+func (p *GitHubProvider) ChangedFiles(ctx context.Context, repoPath, base, head string) ([]string, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.ChangedFiles(ctx, owner, repo, base, head)
+}
+
+func (p *GitHubProvider) ListRepositories(ctx context.Context, owner string) ([]models.ListedRepository, error) {
+	return p.client.ListRepositories(ctx, owner)
+}
+
+func (p *GitHubProvider) ListIssues(ctx context.Context, repoPath string, config models.IssuesConfig) ([]models.Issue, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.ListIssues(ctx, owner, repo, config)
+}
+
+func (p *GitHubProvider) ListReleases(ctx context.Context, repoPath string, limit int) ([]models.Release, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.ListReleases(ctx, owner, repo, limit)
+}
+
+func (p *GitHubProvider) ListWikiPages(ctx context.Context, repoPath string) ([]models.WikiPage, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.ListWikiPages(ctx, owner, repo)
+}
+
+func (p *GitHubProvider) CompareRefs(ctx context.Context, repoPath, base, head string) (*models.Comparison, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.CompareRefs(ctx, owner, repo, base, head)
+}
+
+func (p *GitHubProvider) GetPullRequest(ctx context.Context, repoPath string, number int) (*models.PullRequest, error) {
+	owner, repo, err := parseGitHubRepoPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.GetPullRequest(ctx, owner, repo, number)
+}
+
+// GiteaProvider wraps the Gitea client to implement the Provider interface for Gitea and Forgejo
+type GiteaProvider struct {
+	client *gitea.Client
+}
+
+// NewGiteaProvider creates a new Gitea provider
+func NewGiteaProvider(baseURL, token string) (*GiteaProvider, error) {
+	return NewGiteaProviderWithTransport(baseURL, token, nil)
+}
+
+// NewGiteaProviderWithTransport creates a Gitea provider sending requests through transport
+func NewGiteaProviderWithTransport(baseURL, token string, transport http.RoundTripper) (*GiteaProvider, error) {
+	client, err := gitea.NewClientWithTransport(baseURL, token, transport)
+	if err != nil {
+		return nil, err
+	}
+	return &GiteaProvider{client: client}, nil
+}
+
+func (p *GiteaProvider) GetRepository(ctx context.Context, repoPath string) (*models.Repository, error) {
+	owner, repo, err := parseOwnerRepoPath(repoPath, "Gitea")
+	if err != nil {
+		return nil, err
+	}
+	return p.client.GetRepository(ctx, owner, repo)
+}
+
+func (p *GiteaProvider) GetRepositoryTree(ctx context.Context, repoPath, branch string) ([]models.RepositoryTree, error) {
+	owner, repo, err := parseOwnerRepoPath(repoPath, "Gitea")
+	if err != nil {
+		return nil, err
+	}
+	return p.client.GetRepositoryTree(ctx, owner, repo, branch)
+}
+
+func (p *GiteaProvider) GetFileContent(ctx context.Context, repoPath, filePath, branch string) (string, error) {
+	owner, repo, err := parseOwnerRepoPath(repoPath, "Gitea")
+	if err != nil {
+		return "", err
+	}
+	return p.client.GetFileContent(ctx, owner, repo, filePath, branch)
+}
+
+func (p *GiteaProvider) GetFileInfo(ctx context.Context, repoPath, filePath, branch string) (*models.FileInfo, error) {
+	owner, repo, err := parseOwnerRepoPath(repoPath, "Gitea")
+	if err != nil {
+		return nil, err
+	}
+	return p.client.GetFileInfo(ctx, owner, repo, filePath, branch)
+}
+
+func (p *GiteaProvider) GetMultipleFiles(ctx context.Context, repoPath string, filePaths []string, branch string, maxConcurrency int, config *models.ProcessingConfig) ([]models.FileInfo, error) {
+	owner, repo, err := parseOwnerRepoPath(repoPath, "Gitea")
+	if err != nil {
+		return nil, err
+	}
+	return p.client.GetMultipleFiles(ctx, owner, repo, filePaths, branch, maxConcurrency, config)
+}
+
+func (p *GiteaProvider) TestConnection(ctx context.Context) error {
+	return p.client.TestConnection(ctx)
+}
+
+func (p *GiteaProvider) ResolveCommit(ctx context.Context, repoPath, ref string) (string, error) {
+	owner, repo, err := parseOwnerRepoPath(repoPath, "Gitea")
+	if err != nil {
+		return "", err
+	}
+	return p.client.ResolveCommit(ctx, owner, repo, ref)
+}
+
+func (p *GiteaProvider) ListRepositories(ctx context.Context, owner string) ([]models.ListedRepository, error) {
+	return p.client.ListRepositories(ctx, owner)
+}
+
 // LocalProvider wraps the local client to implement the Provider interface
 type LocalProvider struct {
 	client *local.Client
@@ -128,7 +413,12 @@ type LocalProvider struct {
 
 // NewLocalProvider creates a new local provider
 func NewLocalProvider(folderPath string) (*LocalProvider, error) {
-	client, err := local.NewClient(folderPath)
+	return NewLocalProviderWithConfig(folderPath, models.LocalConfig{})
+}
+
+// NewLocalProviderWithConfig creates a local provider walking the folder with config
+func NewLocalProviderWithConfig(folderPath string, config models.LocalConfig) (*LocalProvider, error) {
+	client, err := local.NewClientWithConfig(folderPath, config)
 	if err != nil {
 		return nil, err
 	}
@@ -155,27 +445,148 @@ func (p *LocalProvider) GetMultipleFiles(ctx context.Context, repoPath string, f
 	return p.client.GetMultipleFiles(ctx, repoPath, filePaths, branch, maxConcurrency, config)
 }
 
+func (p *LocalProvider) ReadRawFile(ctx context.Context, repoPath, filePath, ref string) ([]byte, error) {
+	return p.client.ReadRawFile(ctx, repoPath, filePath, ref)
+}
+
+func (p *LocalProvider) DeniedPaths() []string {
+	return p.client.DeniedPaths()
+}
+
 func (p *LocalProvider) TestConnection(ctx context.Context) error {
 	return p.client.TestConnection(ctx)
 }
 
-// ParseRepositoryURL parses a repository URL or path and returns repository information
+func (p *LocalProvider) SearchCode(ctx context.Context, repoPath, query, branch string) ([]string, error) {
+	return p.client.SearchCode(ctx, repoPath, query, branch)
+}
+
+// DownloadProvider serves a downloaded archive, raw file or package from the folder it was
+// unpacked to, reporting its name and URL instead of the temporary folder
+type DownloadProvider struct {
+	*LocalProvider
+	name string
+	url  string
+}
+
+// NewDownloadProvider creates a provider for the folder a URL was downloaded to
+func NewDownloadProvider(folderPath, rawURL string) (*DownloadProvider, error) {
+	return newDownloadProvider(folderPath, download.Name(rawURL), rawURL)
+}
+
+func newDownloadProvider(folderPath, name, rawURL string) (*DownloadProvider, error) {
+	localProvider, err := NewLocalProvider(folderPath)
+	if err != nil {
+		return nil, err
+	}
+	return &DownloadProvider{LocalProvider: localProvider, name: name, url: rawURL}, nil
+}
+
+func (p *DownloadProvider) GetRepository(ctx context.Context, repoPath string) (*models.Repository, error) {
+	repository, err := p.LocalProvider.GetRepository(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repository.Name = p.name
+	repository.Path = p.url
+	repository.PathWithNamespace = p.url
+	repository.WebURL = p.url
+	repository.Description = ""
+	repository.Owner = ""
+	if u, err := url.Parse(p.url); err == nil {
+		repository.Owner = u.Hostname()
+	}
+	return repository, nil
+}
+
+// ParseRepositoryURL parses a repository URL or path and returns repository information.
+// A path after the branch, like owner/repo#main:services/api or owner/repo#:docs,
+// restricts processing to that subdirectory.
 func ParseRepositoryURL(input string, defaultPlatform models.Platform) (*models.RepositoryInfo, error) {
-	input = strings.TrimSpace(input)
+	input, subdir, err := cutSubdirectory(strings.TrimSpace(input))
+	if err != nil {
+		return nil, err
+	}
 
-	// Extract branch from fragment (e.g., #develop)
-	var branch string
-	if strings.Contains(input, "#") {
-		parts := strings.Split(input, "#")
-		if len(parts) == 2 {
-			input = parts[0]
-			branch = parts[1]
+	repoInfo, err := parseRepositoryURL(input, defaultPlatform)
+	if err != nil {
+		return nil, err
+	}
+	repoInfo.Subdirectory = subdir
+	return repoInfo, nil
+}
+
+// cutSubdirectory removes the path following the branch of a fragment, returning the input
+// with its branch only and the cleaned path
+func cutSubdirectory(input string) (string, string, error) {
+	base, fragment, found := strings.Cut(input, "#")
+	if !found {
+		return input, "", nil
+	}
+	branch, subdir, found := strings.Cut(fragment, ":")
+	if !found {
+		return input, "", nil
+	}
+
+	subdir, err := CleanSubdirectory(subdir)
+	if err != nil {
+		return "", "", err
+	}
+	if branch != "" {
+		base += "#" + branch
+	}
+	return base, subdir, nil
+}
+
+// CleanSubdirectory normalizes a path within a repository to a slash-separated relative
+// path, rejecting paths that leave the repository
+func CleanSubdirectory(subdir string) (string, error) {
+	normalized := strings.ReplaceAll(strings.TrimSpace(subdir), "\\", "/")
+	for _, segment := range strings.Split(normalized, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("invalid path '%s': must stay within the repository", subdir)
 		}
 	}
+	return strings.Trim(path.Clean("/"+normalized), "/"), nil
+}
+
+// splitBranch removes the fragment of an input, returning the input and its branch. Only
+// the first # starts the fragment, so branches like feature/foo#bar are kept whole
+func splitBranch(input string) (string, string) {
+	input, branch, _ := strings.Cut(input, "#")
+	return input, branch
+}
+
+// parseRepositoryURL parses an input whose fragment holds a branch only
+func parseRepositoryURL(input string, defaultPlatform models.Platform) (*models.RepositoryInfo, error) {
+	input, branch := splitBranch(input)
+
+	// Handle organizations and groups, expanded into their repositories once listed
+	if IsGroupPattern(input) {
+		repoInfo, err := parseGroup(input, defaultPlatform)
+		if err != nil {
+			return nil, err
+		}
+		repoInfo.Branch = branch
+		return repoInfo, nil
+	}
+
+	// Handle file:// URLs, as printed in the metadata of local folders
+	if strings.HasPrefix(input, "file://") {
+		localPath, err := fileURLPath(input)
+		if err != nil {
+			return nil, err
+		}
+		input = localPath
+	}
 
 	// Handle local paths (check if path exists on filesystem)
 	if isLocalPath(input) {
-		absPath, err := filepath.Abs(input)
+		expanded, err := utils.ExpandHome(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid local path: %w", err)
+		}
+		absPath, err := filepath.Abs(expanded)
 		if err != nil {
 			return nil, fmt.Errorf("invalid local path: %w", err)
 		}
@@ -202,6 +613,16 @@ func ParseRepositoryURL(input string, defaultPlatform models.Platform) (*models.
 		if err != nil {
 			return nil, err
 		}
+		// Gist and snippet fragments point at files, and downloads have no branches
+		if repoInfo.Kind == "" {
+			repoInfo.Branch = branch
+		}
+		return repoInfo, nil
+	}
+
+	// Handle git remotes cloned without an adapter
+	if isGitRemote(input) {
+		repoInfo := parseGitRemote(input)
 		repoInfo.Branch = branch
 		return repoInfo, nil
 	}
@@ -268,20 +689,47 @@ func isLocalPath(input string) bool {
 	return false
 }
 
+// fileURLPath returns the local path of a file:// URL. Only local hosts are accepted, and
+// Windows paths like file:///C:/work lose their leading slash
+func fileURLPath(input string) (string, error) {
+	rest := strings.TrimPrefix(input, "file://")
+	host, escaped, found := strings.Cut(rest, "/")
+	if !found || (host != "" && !strings.EqualFold(host, "localhost")) {
+		return "", fmt.Errorf("invalid file URL '%s': expected file:///absolute/path", input)
+	}
+
+	localPath, err := url.PathUnescape("/" + escaped)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URL '%s': %w", input, err)
+	}
+	if len(localPath) > 2 && localPath[2] == ':' {
+		localPath = localPath[1:]
+	}
+	return filepath.FromSlash(localPath), nil
+}
+
 func parseURL(input string) (*models.RepositoryInfo, error) {
 	u, err := url.Parse(input)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
+	if isDownloadURL(u) {
+		return parseDownloadURL(u, input), nil
+	}
+
 	switch u.Hostname() {
+	case "gist.github.com":
+		return parseGistURL(u, input)
 	case "github.com", "www.github.com":
 		return parseGitHubURL(u, input)
 	case "gitlab.com", "www.gitlab.com":
 		return parseGitLabURL(u, input)
 	default:
 		// For self-hosted instances, try to determine by URL structure
-		if strings.Contains(u.Path, "/tree/") || strings.Contains(u.Path, "/blob/") {
+		if isGiteaHost(u.Hostname()) || isGiteaPath(u.Path) {
+			return parseGiteaURL(u, input)
+		} else if strings.Contains(u.Path, "/tree/") || strings.Contains(u.Path, "/blob/") {
 			// GitHub-style URL structure
 			return parseGitHubURL(u, input)
 		} else {
@@ -313,8 +761,112 @@ func parseGitHubURL(u *url.URL, original string) (*models.RepositoryInfo, error)
 	}, nil
 }
 
+// isDownloadURL reports whether a URL points at an archive or a raw file rather than a
+// repository page
+func isDownloadURL(u *url.URL) bool {
+	if download.ArchiveExtension(u.Path) != "" {
+		return true
+	}
+	switch u.Hostname() {
+	case "raw.githubusercontent.com", "gist.githubusercontent.com":
+		return true
+	}
+	// GitHub, GitLab and Gitea all serve raw files below a /raw/ path segment
+	return strings.Contains(u.Path, "/raw/")
+}
+
+// parseDownloadURL describes an archive or raw file URL. Downloads need no token, so they
+// are processed with the local folders.
+func parseDownloadURL(u *url.URL, original string) *models.RepositoryInfo {
+	return &models.RepositoryInfo{
+		Platform: models.PlatformLocal,
+		Owner:    u.Hostname(),
+		Name:     download.Name(original),
+		FullName: original,
+		URL:      original,
+		Kind:     models.KindDownload,
+	}
+}
+
+func parseGistURL(u *url.URL, original string) (*models.RepositoryInfo, error) {
+	// Gist URL format: https://gist.github.com/owner/id or https://gist.github.com/id
+	pathParts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if pathParts[0] == "" {
+		return nil, fmt.Errorf("invalid gist URL format")
+	}
+	if len(pathParts) > 2 {
+		// Drop trailing views like /raw or /revisions
+		pathParts = pathParts[:2]
+	}
+
+	id := pathParts[len(pathParts)-1]
+	owner := ""
+	fullName := id
+	if len(pathParts) == 2 {
+		owner = pathParts[0]
+		fullName = owner + "/" + id
+	}
+
+	return &models.RepositoryInfo{
+		Platform: models.PlatformGitHub,
+		Owner:    owner,
+		Name:     id,
+		FullName: fullName,
+		URL:      original,
+		Kind:     models.KindSnippet,
+	}, nil
+}
+
+func parseGiteaURL(u *url.URL, original string) (*models.RepositoryInfo, error) {
+	// Gitea URL format: https://gitea.com/owner/repo or https://gitea.com/owner/repo/src/branch/main
+	pathParts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(pathParts) < 2 {
+		return nil, fmt.Errorf("invalid Gitea URL format")
+	}
+
+	owner := pathParts[0]
+	repo := strings.TrimSuffix(pathParts[1], ".git")
+
+	return &models.RepositoryInfo{
+		Platform: models.PlatformGitea,
+		Owner:    owner,
+		Name:     repo,
+		FullName: fmt.Sprintf("%s/%s", owner, repo),
+		URL:      original,
+	}, nil
+}
+
+// isGiteaHost reports whether a hostname is a known Gitea or Forgejo instance,
+// or is named after one (e.g. gitea.company.com, forgejo.internal)
+func isGiteaHost(hostname string) bool {
+	switch hostname {
+	case "gitea.com", "codeberg.org":
+		return true
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "gitea" || label == "forgejo" {
+			return true
+		}
+	}
+	return false
+}
+
+// isGiteaPath reports whether a URL path uses the Gitea and Forgejo file browser layout
+func isGiteaPath(urlPath string) bool {
+	for _, marker := range []string{"/src/branch/", "/src/tag/", "/src/commit/"} {
+		if strings.Contains(urlPath, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 func parseGitLabURL(u *url.URL, original string) (*models.RepositoryInfo, error) {
 	// GitLab URL format: https://gitlab.com/owner/repo or https://gitlab.com/group/subgroup/repo
+	if repoInfo, ok := parseGitLabSnippetURL(u, original); ok {
+		return repoInfo, nil
+	}
+
 	pathParts := strings.Split(strings.Trim(u.Path, "/"), "/")
 	if len(pathParts) < 2 {
 		return nil, fmt.Errorf("invalid GitLab URL format")
@@ -335,6 +887,42 @@ func parseGitLabURL(u *url.URL, original string) (*models.RepositoryInfo, error)
 	}, nil
 }
 
+// parseGitLabSnippetURL recognizes personal snippet URLs like https://gitlab.com/-/snippets/42
+// and project snippet URLs like https://gitlab.com/group/project/-/snippets/42
+func parseGitLabSnippetURL(u *url.URL, original string) (*models.RepositoryInfo, bool) {
+	urlPath := strings.Trim(u.Path, "/")
+
+	var projectPath, id string
+	if project, rest, found := strings.Cut(urlPath, "/-/snippets/"); found {
+		projectPath, id = project, rest
+	} else if rest, found := strings.CutPrefix(urlPath, "-/snippets/"); found {
+		id = rest
+	} else if rest, found := strings.CutPrefix(urlPath, "snippets/"); found {
+		id = rest
+	} else {
+		return nil, false
+	}
+
+	// Drop trailing views like /raw
+	id, _, _ = strings.Cut(id, "/")
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, false
+	}
+
+	repoInfo := &models.RepositoryInfo{
+		Platform: models.PlatformGitLab,
+		Name:     id,
+		FullName: "snippets/" + id,
+		URL:      original,
+		Kind:     models.KindSnippet,
+	}
+	if projectPath != "" {
+		repoInfo.Owner = strings.Split(projectPath, "/")[0]
+		repoInfo.FullName = projectPath + "/-/snippets/" + id
+	}
+	return repoInfo, true
+}
+
 func parseSSHURL(input string) (*models.RepositoryInfo, error) {
 	// SSH URL formats:
 	// git@github.com:owner/repo.git
@@ -356,6 +944,10 @@ func parseSSHURL(input string) (*models.RepositoryInfo, error) {
 	case "gitlab.com":
 		platform = models.PlatformGitLab
 	default:
+		if isGiteaHost(hostname) {
+			platform = models.PlatformGitea
+			break
+		}
 		// Default to GitLab for self-hosted
 		platform = models.PlatformGitLab
 	}
@@ -373,11 +965,27 @@ func parseSSHURL(input string) (*models.RepositoryInfo, error) {
 
 // CreateProvider creates a VCS provider based on platform and configuration
 func CreateProvider(platform models.Platform, config *models.Config, token string) (Provider, error) {
+	return CreateProviderWithTransport(platform, config, token, nil)
+}
+
+// CreateProviderWithTransport creates a provider whose API requests go through transport,
+// the client default when nil, and are retried as configured for the platform
+func CreateProviderWithTransport(platform models.Platform, config *models.Config, token string, transport http.RoundTripper) (Provider, error) {
+	transport = NewRetryTransport(transport, config.Requests(platform))
 	switch platform {
 	case models.PlatformGitLab:
-		return NewGitLabProvider(config.GitLab.BaseURL, token)
+		return NewGitLabProviderWithTransport(config.GitLab.BaseURL, token, transport)
 	case models.PlatformGitHub:
-		return NewGitHubProvider(config.GitHub.BaseURL, token)
+		provider, err := NewGitHubProviderWithTransport(config.GitHub.BaseURL, token, transport)
+		if err != nil {
+			return nil, err
+		}
+		if config.GitHub.API == models.GitHubAPIGraphQL {
+			provider.client.EnableGraphQL()
+		}
+		return provider, nil
+	case models.PlatformGitea:
+		return NewGiteaProviderWithTransport(config.Gitea.BaseURL, token, transport)
 	case models.PlatformLocal:
 		// For local platform, token is not needed, but we need the folder path
 		// This should be handled differently in the orchestration layer
@@ -388,15 +996,35 @@ func CreateProvider(platform models.Platform, config *models.Config, token strin
 }
 
 // CreateLocalProvider creates a local provider for a specific folder path
-func CreateLocalProvider(folderPath string) (Provider, error) {
-	return NewLocalProvider(folderPath)
+func CreateLocalProvider(folderPath string, config models.LocalConfig) (Provider, error) {
+	return NewLocalProviderWithConfig(folderPath, config)
+}
+
+// CreateDownloadProvider downloads an archive or raw file into parentDir and creates a
+// provider serving it. The returned directory must be removed once processing is done.
+func CreateDownloadProvider(ctx context.Context, client *http.Client, rawURL, parentDir string) (Provider, string, error) {
+	folderPath, err := download.Fetch(ctx, client, rawURL, parentDir)
+	if err != nil {
+		return nil, "", err
+	}
+	provider, err := NewDownloadProvider(folderPath, rawURL)
+	if err != nil {
+		os.RemoveAll(filepath.Dir(folderPath))
+		return nil, "", err
+	}
+	return provider, filepath.Dir(folderPath), nil
 }
 
 // Helper function for GitHub provider
 func parseGitHubRepoPath(repoPath string) (owner, repo string, err error) {
+	return parseOwnerRepoPath(repoPath, "GitHub")
+}
+
+// parseOwnerRepoPath splits an owner/repo path for platforms without nested namespaces
+func parseOwnerRepoPath(repoPath, platformName string) (owner, repo string, err error) {
 	parts := strings.Split(repoPath, "/")
 	if len(parts) != 2 {
-		return "", "", fmt.Errorf("invalid GitHub repository path format, expected 'owner/repo'")
+		return "", "", fmt.Errorf("invalid %s repository path format, expected 'owner/repo'", platformName)
 	}
 	return parts[0], parts[1], nil
 }
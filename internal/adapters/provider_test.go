@@ -3,6 +3,7 @@ package adapters
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"sherpa/pkg/models"
@@ -40,6 +41,19 @@ func TestCreateProvider(t *testing.T) {
 		assert.NotNil(t, provider)
 	})
 
+	t.Run("should create Gitea provider", func(t *testing.T) {
+		config := &models.Config{
+			Gitea: models.GiteaConfig{
+				BaseURL:  "https://codeberg.org",
+				TokenEnv: "GITEA_TOKEN",
+			},
+		}
+
+		provider, err := CreateProvider(models.PlatformGitea, config, "gitea-token")
+		require.NoError(t, err)
+		assert.IsType(t, &GiteaProvider{}, provider)
+	})
+
 	t.Run("should error on unsupported platform", func(t *testing.T) {
 		config := &models.Config{}
 		token := "token"
@@ -222,7 +236,7 @@ func TestCreateLocalProvider(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			provider, err := CreateLocalProvider(tt.path)
+			provider, err := CreateLocalProvider(tt.path, models.LocalConfig{})
 			if tt.wantError {
 				assert.Error(t, err)
 				assert.Nil(t, provider)
@@ -394,6 +408,79 @@ func TestParseRepositoryURL_Local(t *testing.T) {
 	}
 }
 
+func TestParseRepositoryURL_LocalHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "work", "api"), 0755))
+
+	result, err := ParseRepositoryURL("~/work/api#main", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.PlatformLocal, result.Platform)
+	assert.Equal(t, "api", result.Name)
+	assert.Equal(t, filepath.Join(home, "work", "api"), result.FullName)
+	assert.Equal(t, "main", result.Branch)
+}
+
+func TestParseRepositoryURL_FileURL(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my project")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	fileURL := "file://" + filepath.ToSlash(dir)
+	if !strings.HasPrefix(fileURL, "file:///") {
+		fileURL = "file:///" + filepath.ToSlash(dir)
+	}
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedBranch string
+		wantErr        string
+	}{
+		{
+			name:  "should read the folder of a file URL",
+			input: fileURL,
+		},
+		{
+			name:  "should decode escaped characters",
+			input: strings.ReplaceAll(fileURL, " ", "%20"),
+		},
+		{
+			name:  "should accept localhost as host",
+			input: strings.Replace(fileURL, "file://", "file://localhost", 1),
+		},
+		{
+			name:           "should keep the branch",
+			input:          fileURL + "#main",
+			expectedBranch: "main",
+		},
+		{
+			name:    "should reject remote hosts",
+			input:   "file://server/share/project",
+			wantErr: "invalid file URL",
+		},
+		{
+			name:    "should reject missing folders",
+			input:   fileURL + "/missing",
+			wantErr: "local path does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseRepositoryURL(tt.input, "")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, models.PlatformLocal, result.Platform)
+			assert.Equal(t, dir, result.FullName)
+			assert.Equal(t, "my project", result.Name)
+			assert.Equal(t, tt.expectedBranch, result.Branch)
+		})
+	}
+}
+
 func TestParseRepositoryURL_LocalWithBranch(t *testing.T) {
 	// Create a temporary directory for testing
 	tmpDir, err := os.MkdirTemp("", "sherpa-test-*")
@@ -408,3 +495,245 @@ func TestParseRepositoryURL_LocalWithBranch(t *testing.T) {
 	assert.Equal(t, "main", result.Branch)
 	assert.Equal(t, tmpDir, result.FullName) // Branch should be stripped from path
 }
+
+func TestParseRepositoryURL_Subdirectory(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedFullName string
+		expectedBranch   string
+		expectedSubdir   string
+		wantErr          bool
+	}{
+		{
+			name:             "should read the path after the branch",
+			input:            "owner/repo#main:services/api",
+			expectedFullName: "owner/repo",
+			expectedBranch:   "main",
+			expectedSubdir:   "services/api",
+		},
+		{
+			name:             "should use the default branch when only a path is given",
+			input:            "https://gitlab.com/group/monorepo#:docs/",
+			expectedFullName: "group/monorepo",
+			expectedSubdir:   "docs",
+		},
+		{
+			name:             "should clean the path",
+			input:            "owner/repo#develop:./services//api/",
+			expectedFullName: "owner/repo",
+			expectedBranch:   "develop",
+			expectedSubdir:   "services/api",
+		},
+		{
+			name:             "should keep branches without a path",
+			input:            "owner/repo#feature/x",
+			expectedFullName: "owner/repo",
+			expectedBranch:   "feature/x",
+		},
+		{
+			name:             "should keep # within branches",
+			input:            "owner/repo#feature/foo#bar",
+			expectedFullName: "owner/repo",
+			expectedBranch:   "feature/foo#bar",
+		},
+		{
+			name:             "should read the path after branches containing #",
+			input:            "https://github.com/owner/repo#release/1.x#hotfix:cmd",
+			expectedFullName: "owner/repo",
+			expectedBranch:   "release/1.x#hotfix",
+			expectedSubdir:   "cmd",
+		},
+		{
+			name:    "should reject paths leaving the repository",
+			input:   "owner/repo#main:../other",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseRepositoryURL(tt.input, "")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFullName, result.FullName)
+			assert.Equal(t, tt.expectedBranch, result.Branch)
+			assert.Equal(t, tt.expectedSubdir, result.Subdirectory)
+		})
+	}
+}
+
+func TestParseRepositoryURL_Gitea(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		defaultPlatform  models.Platform
+		expectedPlatform models.Platform
+		expectedFullName string
+	}{
+		{
+			name:             "should detect gitea.com",
+			input:            "https://gitea.com/owner/repo",
+			expectedPlatform: models.PlatformGitea,
+			expectedFullName: "owner/repo",
+		},
+		{
+			name:             "should detect Codeberg as Forgejo",
+			input:            "https://codeberg.org/owner/repo.git",
+			expectedPlatform: models.PlatformGitea,
+			expectedFullName: "owner/repo",
+		},
+		{
+			name:             "should detect self-hosted hosts named after the forge",
+			input:            "https://forgejo.company.com/team/service",
+			expectedPlatform: models.PlatformGitea,
+			expectedFullName: "team/service",
+		},
+		{
+			name:             "should detect Gitea file browser URLs",
+			input:            "https://git.company.com/team/service/src/branch/main/README.md",
+			expectedPlatform: models.PlatformGitea,
+			expectedFullName: "team/service",
+		},
+		{
+			name:             "should keep self-hosted GitLab with src groups",
+			input:            "https://git.company.com/src/service",
+			expectedPlatform: models.PlatformGitLab,
+			expectedFullName: "src/service",
+		},
+		{
+			name:             "should detect Gitea SSH URLs",
+			input:            "git@gitea.company.com:team/service.git",
+			expectedPlatform: models.PlatformGitea,
+			expectedFullName: "team/service",
+		},
+		{
+			name:             "should use gitea as default platform",
+			input:            "owner/repo",
+			defaultPlatform:  models.PlatformGitea,
+			expectedPlatform: models.PlatformGitea,
+			expectedFullName: "owner/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseRepositoryURL(tt.input, tt.defaultPlatform)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPlatform, result.Platform)
+			assert.Equal(t, tt.expectedFullName, result.FullName)
+		})
+	}
+}
+
+func TestParseRepositoryURL_Snippets(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedPlatform models.Platform
+		expectedFullName string
+		expectedKind     string
+	}{
+		{
+			name:             "should detect gists",
+			input:            "https://gist.github.com/octocat/6cad326836d38bd3a7ae",
+			expectedPlatform: models.PlatformGitHub,
+			expectedFullName: "octocat/6cad326836d38bd3a7ae",
+			expectedKind:     models.KindSnippet,
+		},
+		{
+			name:             "should detect gists without owner",
+			input:            "https://gist.github.com/6cad326836d38bd3a7ae",
+			expectedPlatform: models.PlatformGitHub,
+			expectedFullName: "6cad326836d38bd3a7ae",
+			expectedKind:     models.KindSnippet,
+		},
+		{
+			name:             "should detect personal snippets",
+			input:            "https://gitlab.com/-/snippets/42",
+			expectedPlatform: models.PlatformGitLab,
+			expectedFullName: "snippets/42",
+			expectedKind:     models.KindSnippet,
+		},
+		{
+			name:             "should detect project snippets on self-hosted GitLab",
+			input:            "https://git.company.com/group/project/-/snippets/42/raw",
+			expectedPlatform: models.PlatformGitLab,
+			expectedFullName: "group/project/-/snippets/42",
+			expectedKind:     models.KindSnippet,
+		},
+		{
+			name:             "should keep projects named snippets",
+			input:            "https://gitlab.com/team/snippets",
+			expectedPlatform: models.PlatformGitLab,
+			expectedFullName: "team/snippets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseRepositoryURL(tt.input, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPlatform, result.Platform)
+			assert.Equal(t, tt.expectedFullName, result.FullName)
+			assert.Equal(t, tt.expectedKind, result.Kind)
+		})
+	}
+
+	t.Run("should not read gist file fragments as branches", func(t *testing.T) {
+		result, err := ParseRepositoryURL("https://gist.github.com/octocat/6cad326836d38bd3a7ae#file-hello-go", "")
+		require.NoError(t, err)
+		assert.Empty(t, result.Branch)
+	})
+}
+
+func TestParseRepositoryURL_Downloads(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		expectedName string
+	}{
+		{
+			name:         "should detect tarballs",
+			input:        "https://releases.example.com/tool/tool-1.2.0.tar.gz",
+			expectedName: "tool-1.2.0",
+		},
+		{
+			name:         "should detect zip archives on forges",
+			input:        "https://github.com/owner/repo/archive/refs/heads/main.zip",
+			expectedName: "main",
+		},
+		{
+			name:         "should detect GitHub raw files",
+			input:        "https://raw.githubusercontent.com/owner/repo/main/cmd/main.go",
+			expectedName: "main.go",
+		},
+		{
+			name:         "should detect GitLab raw files",
+			input:        "https://gitlab.com/group/project/-/raw/main/setup.py",
+			expectedName: "setup.py",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseRepositoryURL(tt.input, "")
+			require.NoError(t, err)
+			assert.Equal(t, models.PlatformLocal, result.Platform)
+			assert.Equal(t, models.KindDownload, result.Kind)
+			assert.Equal(t, tt.input, result.FullName)
+			assert.Equal(t, tt.input, result.URL)
+			assert.Equal(t, tt.expectedName, result.Name)
+		})
+	}
+
+	t.Run("should keep repository URLs", func(t *testing.T) {
+		result, err := ParseRepositoryURL("https://github.com/owner/repo/tree/main", "")
+		require.NoError(t, err)
+		assert.Equal(t, models.PlatformGitHub, result.Platform)
+		assert.Empty(t, result.Kind)
+	})
+}
@@ -0,0 +1,38 @@
+package adapters
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// ParsePullRequestURL parses a GitHub pull request URL like
+// https://github.com/owner/repo/pull/123, returning its repository and number. Pages of
+// the pull request, like /pull/123/files, are accepted too.
+func ParsePullRequestURL(input string) (*models.RepositoryInfo, int, error) {
+	u, err := url.Parse(strings.TrimSpace(input))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, 0, fmt.Errorf("invalid pull request URL '%s': expected https://github.com/owner/repo/pull/123", input)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 4 || segments[2] != "pull" {
+		return nil, 0, fmt.Errorf("invalid pull request URL '%s': expected https://github.com/owner/repo/pull/123", input)
+	}
+	number, err := strconv.Atoi(segments[3])
+	if err != nil || number <= 0 {
+		return nil, 0, fmt.Errorf("invalid pull request number '%s'", segments[3])
+	}
+
+	owner, name := segments[0], strings.TrimSuffix(segments[1], ".git")
+	return &models.RepositoryInfo{
+		Platform: models.PlatformGitHub,
+		Owner:    owner,
+		Name:     name,
+		FullName: owner + "/" + name,
+		URL:      fmt.Sprintf("%s://%s/%s/%s", u.Scheme, u.Host, owner, name),
+	}, number, nil
+}
@@ -0,0 +1,66 @@
+package adapters
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePullRequestURL(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedFullName string
+		expectedURL      string
+		expectedNumber   int
+		wantErr          bool
+	}{
+		{
+			name:             "should parse a pull request URL",
+			input:            "https://github.com/owner/repo/pull/123",
+			expectedFullName: "owner/repo",
+			expectedURL:      "https://github.com/owner/repo",
+			expectedNumber:   123,
+		},
+		{
+			name:             "should accept pages of the pull request",
+			input:            "https://github.example.com/team/service/pull/7/files",
+			expectedFullName: "team/service",
+			expectedURL:      "https://github.example.com/team/service",
+			expectedNumber:   7,
+		},
+		{
+			name:    "should reject repository URLs",
+			input:   "https://github.com/owner/repo",
+			wantErr: true,
+		},
+		{
+			name:    "should reject invalid numbers",
+			input:   "https://github.com/owner/repo/pull/abc",
+			wantErr: true,
+		},
+		{
+			name:    "should reject inputs that are not URLs",
+			input:   "owner/repo#123",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoInfo, number, err := ParsePullRequestURL(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, models.PlatformGitHub, repoInfo.Platform)
+			assert.Equal(t, tt.expectedFullName, repoInfo.FullName)
+			assert.Equal(t, tt.expectedURL, repoInfo.URL)
+			assert.Equal(t, tt.expectedNumber, number)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+)
+
+// rateLimitsTimeout bounds the request asking a platform for its quotas
+const rateLimitsTimeout = 30 * time.Second
+
+// gitHubRateLimits is the response of the GitHub rate limit API
+type gitHubRateLimits struct {
+	Resources map[string]struct {
+		Limit     int   `json:"limit"`
+		Remaining int   `json:"remaining"`
+		Reset     int64 `json:"reset"`
+	} `json:"resources"`
+}
+
+// FetchRateLimits returns the current quotas of token on a platform. GitHub reports each
+// of its quotas through its rate limit API, which does not count against them. GitLab and
+// Gitea report the quota of the authenticated user API request in its headers, and nothing
+// when rate limiting is disabled on the instance.
+func FetchRateLimits(ctx context.Context, client *http.Client, platform models.Platform, config *models.Config, token string) ([]RateLimit, error) {
+	ctx, cancel := context.WithTimeout(ctx, rateLimitsTimeout)
+	defer cancel()
+
+	var endpoint string
+	header := make(http.Header)
+	switch platform {
+	case models.PlatformGitHub:
+		endpoint = strings.TrimSuffix(config.GitHub.BaseURL, "/") + "/rate_limit"
+		header.Set("Authorization", "Bearer "+token)
+		header.Set("Accept", "application/vnd.github+json")
+	case models.PlatformGitLab:
+		endpoint = apiEndpoint(config.GitLab.BaseURL, "/api/v4") + "/user"
+		header.Set("PRIVATE-TOKEN", token)
+	case models.PlatformGitea:
+		endpoint = apiEndpoint(config.Gitea.BaseURL, "/api/v1") + "/user"
+		header.Set("Authorization", "token "+token)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", platform)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s base URL: %w", platform, err)
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s rate limits: %w", platform, err)
+	}
+	defer resp.Body.Close()
+
+	// GitHub Enterprise answers 404 when rate limiting is disabled
+	if platform == models.PlatformGitHub && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, sherpaerrors.FromStatus(resp.StatusCode, fmt.Errorf("failed to fetch %s rate limits: %s", platform, resp.Status))
+	}
+
+	host := req.URL.Host
+	if platform != models.PlatformGitHub {
+		limit, remaining, reset, ok := parseRateLimit(resp.Header)
+		if !ok {
+			return nil, nil
+		}
+		return []RateLimit{{Host: host, Resource: "core", Limit: limit, Remaining: remaining, Reset: reset}}, nil
+	}
+
+	var body gitHubRateLimits
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse %s rate limits: %w", platform, err)
+	}
+	var limits []RateLimit
+	for resource, quota := range body.Resources {
+		limits = append(limits, RateLimit{
+			Host:      host,
+			Resource:  resource,
+			Limit:     quota.Limit,
+			Remaining: quota.Remaining,
+			Reset:     time.Unix(quota.Reset, 0),
+		})
+	}
+	// The quotas sherpa requests count against come first
+	rank := map[string]int{"core": 0, "graphql": 1, "search": 2}
+	sort.Slice(limits, func(i, j int) bool {
+		ri, iKnown := rank[limits[i].Resource]
+		rj, jKnown := rank[limits[j].Resource]
+		if iKnown != jKnown {
+			return iKnown
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return limits[i].Resource < limits[j].Resource
+	})
+	return limits, nil
+}
+
+// Low reports whether less than a tenth of the quota remains, below which the throttler
+// spreads requests over the time left before it resets
+func (l RateLimit) Low() bool {
+	return l.Limit > 0 && float64(l.Remaining) < lowQuotaShare*float64(l.Limit)
+}
+
+// apiEndpoint returns the API URL of an instance given by its URL or its API URL
+func apiEndpoint(baseURL, apiPath string) string {
+	endpoint := strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(endpoint, apiPath) {
+		endpoint += apiPath
+	}
+	return endpoint
+}
@@ -0,0 +1,90 @@
+package adapters
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchRateLimits(t *testing.T) {
+	t.Run("should read every GitHub quota from the rate limit API", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v3/rate_limit", r.URL.Path)
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"resources": {
+				"search": {"limit": 30, "remaining": 30, "reset": 1700000060},
+				"core": {"limit": 5000, "remaining": 4321, "reset": 1700000000},
+				"graphql": {"limit": 5000, "remaining": 12, "reset": 1700000000},
+				"code_scanning_upload": {"limit": 1000, "remaining": 1000, "reset": 1700000000}
+			}}`))
+		}))
+		defer server.Close()
+
+		config := &models.Config{GitHub: models.GitHubConfig{BaseURL: server.URL + "/api/v3/"}}
+		limits, err := FetchRateLimits(context.Background(), server.Client(), models.PlatformGitHub, config, "secret")
+		require.NoError(t, err)
+		require.Len(t, limits, 4)
+
+		var resources []string
+		for _, limit := range limits {
+			resources = append(resources, limit.Resource)
+		}
+		assert.Equal(t, []string{"core", "graphql", "search", "code_scanning_upload"}, resources)
+		assert.Equal(t, 4321, limits[0].Remaining)
+		assert.Equal(t, time.Unix(1700000000, 0), limits[0].Reset)
+		assert.False(t, limits[0].Low())
+		assert.True(t, limits[1].Low())
+	})
+
+	t.Run("should read the GitLab quota from the headers of the user API", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v4/user", r.URL.Path)
+			assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+			w.Header().Set("RateLimit-Limit", "2000")
+			w.Header().Set("RateLimit-Remaining", "1999")
+			w.Header().Set("RateLimit-Reset", "1700000000")
+			w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		config := &models.Config{GitLab: models.GitLabConfig{BaseURL: server.URL}}
+		limits, err := FetchRateLimits(context.Background(), server.Client(), models.PlatformGitLab, config, "secret")
+		require.NoError(t, err)
+		require.Len(t, limits, 1)
+		assert.Equal(t, RateLimit{Host: server.Listener.Addr().String(), Resource: "core", Limit: 2000, Remaining: 1999, Reset: time.Unix(1700000000, 0)}, limits[0])
+	})
+
+	t.Run("should report no quota when rate limiting is disabled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/user", r.URL.Path)
+			assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+			w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		config := &models.Config{Gitea: models.GiteaConfig{BaseURL: server.URL + "/api/v1"}}
+		limits, err := FetchRateLimits(context.Background(), server.Client(), models.PlatformGitea, config, "secret")
+		require.NoError(t, err)
+		assert.Empty(t, limits)
+	})
+
+	t.Run("should classify rejected tokens", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		config := &models.Config{GitHub: models.GitHubConfig{BaseURL: server.URL}}
+		_, err := FetchRateLimits(context.Background(), server.Client(), models.PlatformGitHub, config, "revoked")
+		require.Error(t, err)
+		assert.Equal(t, sherpaerrors.KindAuth, sherpaerrors.KindOf(err))
+	})
+}
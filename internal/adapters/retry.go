@@ -0,0 +1,94 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// defaultBackoff is the delay before the first retry when none is configured
+const defaultBackoff = time.Second
+
+// NewRetryTransport wraps base so requests failing with a network error or a 500, 502, 503
+// or 504 response are sent again, up to config.Retries times, waiting config.Backoff before
+// the first retry and twice as long before each following one. Rate limited responses are
+// left to the throttler. base is returned unchanged without retries, and
+// http.DefaultTransport is used when it is nil.
+func NewRetryTransport(base http.RoundTripper, config models.RequestConfig) http.RoundTripper {
+	if config.Retries <= 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	backoff := config.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	return &retryTransport{base: base, retries: config.Retries, backoff: backoff, sleep: sleepContext}
+}
+
+// retryTransport is an http.RoundTripper sending failed requests again
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	backoff time.Duration
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// RoundTrip sends the request, and sends it again after a delay while it fails with a
+// transient error and retries are left
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := t.backoff
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		// Requests whose body cannot be read again are sent once
+		if attempt == t.retries || !transientFailure(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		fields := map[string]interface{}{"url": req.URL.Redacted(), "attempt": attempt + 1, "delay": delay}
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(fields).Debug("Retrying failed request")
+		} else {
+			fields["status"] = resp.StatusCode
+			logger.Logger.WithFields(fields).Debug("Retrying failed request")
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+// transientFailure reports whether a request failed in a way sending it again may fix: a
+// network error other than a canceled request, or a server error response
+func transientFailure(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, ErrOffline)
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package adapters
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport(t *testing.T) {
+	// failingServer answers status to the first failures requests and 200 with their body after
+	failingServer := func(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) <= failures {
+				w.WriteHeader(status)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		}))
+		t.Cleanup(server.Close)
+		return server, &requests
+	}
+	newTransport := func(retries int, delays *[]time.Duration) http.RoundTripper {
+		transport := NewRetryTransport(nil, models.RequestConfig{Retries: retries, Backoff: 100 * time.Millisecond})
+		transport.(*retryTransport).sleep = func(_ context.Context, d time.Duration) error {
+			*delays = append(*delays, d)
+			return nil
+		}
+		return transport
+	}
+
+	t.Run("should send failed requests again with a doubling backoff", func(t *testing.T) {
+		server, requests := failingServer(t, 2, http.StatusServiceUnavailable)
+		var delays []time.Duration
+		client := &http.Client{Transport: newTransport(3, &delays)}
+
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "payload", string(body), "the body is sent again")
+		assert.Equal(t, int32(3), requests.Load())
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, delays)
+	})
+
+	t.Run("should return the last failure once retries run out", func(t *testing.T) {
+		server, requests := failingServer(t, 5, http.StatusBadGateway)
+		var delays []time.Duration
+		client := &http.Client{Transport: newTransport(1, &delays)}
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("should not send client errors again", func(t *testing.T) {
+		server, requests := failingServer(t, 1, http.StatusNotFound)
+		var delays []time.Duration
+		client := &http.Client{Transport: newTransport(3, &delays)}
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, int32(1), requests.Load())
+		assert.Empty(t, delays)
+	})
+
+	t.Run("should leave the transport unchanged without retries", func(t *testing.T) {
+		base := &http.Transport{}
+		assert.Same(t, base, NewRetryTransport(base, models.RequestConfig{Backoff: time.Second}))
+	})
+}
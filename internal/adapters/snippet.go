@@ -0,0 +1,197 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sherpa/internal/adapters/github"
+	"sherpa/internal/adapters/gitlab"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+)
+
+// snippetFetchFunc fetches a gist or snippet with the content of all its files
+type snippetFetchFunc func(ctx context.Context, snippetPath string) (*models.Repository, []models.FileInfo, error)
+
+// snippet is a fetched gist or snippet
+type snippet struct {
+	repository *models.Repository
+	files      []models.FileInfo
+}
+
+// SnippetProvider serves GitHub gists and GitLab snippets through the Provider interface.
+// A snippet is fetched with all its file contents on first use and served from memory afterwards.
+type SnippetProvider struct {
+	fetch          snippetFetchFunc
+	testConnection func(ctx context.Context) error
+
+	mu       sync.Mutex
+	snippets map[string]*snippet
+}
+
+// NewGistProviderWithTransport creates a provider fetching GitHub gists by id
+func NewGistProviderWithTransport(baseURL, token string, transport http.RoundTripper) (*SnippetProvider, error) {
+	client, err := github.NewClientWithTransport(baseURL, token, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch := func(ctx context.Context, snippetPath string) (*models.Repository, []models.FileInfo, error) {
+		// Gists are addressed as owner/id or id alone
+		return client.GetGist(ctx, snippetPath[strings.LastIndex(snippetPath, "/")+1:])
+	}
+	return newSnippetProvider(fetch, client.TestConnection), nil
+}
+
+// NewGitLabSnippetProviderWithTransport creates a provider fetching GitLab snippets addressed as
+// snippets/ID for personal snippets or group/project/-/snippets/ID for project snippets
+func NewGitLabSnippetProviderWithTransport(baseURL, token string, transport http.RoundTripper) (*SnippetProvider, error) {
+	client, err := gitlab.NewClientWithTransport(baseURL, token, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch := func(ctx context.Context, snippetPath string) (*models.Repository, []models.FileInfo, error) {
+		projectPath, snippetID, err := parseGitLabSnippetPath(snippetPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client.GetSnippet(ctx, projectPath, snippetID)
+	}
+	return newSnippetProvider(fetch, client.TestConnection), nil
+}
+
+// CreateSnippetProviderWithTransport creates the gist or snippet provider of a platform,
+// retrying its requests as configured for the platform
+func CreateSnippetProviderWithTransport(platform models.Platform, config *models.Config, token string, transport http.RoundTripper) (Provider, error) {
+	transport = NewRetryTransport(transport, config.Requests(platform))
+	switch platform {
+	case models.PlatformGitHub:
+		return NewGistProviderWithTransport(config.GitHub.BaseURL, token, transport)
+	case models.PlatformGitLab:
+		return NewGitLabSnippetProviderWithTransport(config.GitLab.BaseURL, token, transport)
+	default:
+		return nil, fmt.Errorf("snippets are not supported on platform: %s", platform)
+	}
+}
+
+func newSnippetProvider(fetch snippetFetchFunc, testConnection func(ctx context.Context) error) *SnippetProvider {
+	return &SnippetProvider{
+		fetch:          fetch,
+		testConnection: testConnection,
+		snippets:       make(map[string]*snippet),
+	}
+}
+
+// get returns a snippet, fetching it on first use
+func (p *SnippetProvider) get(ctx context.Context, snippetPath string) (*snippet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cached, exists := p.snippets[snippetPath]; exists {
+		return cached, nil
+	}
+
+	repository, files, err := p.fetch(ctx, snippetPath)
+	if err != nil {
+		return nil, err
+	}
+	fetched := &snippet{repository: repository, files: files}
+	p.snippets[snippetPath] = fetched
+	return fetched, nil
+}
+
+// file returns a file of a snippet
+func (p *SnippetProvider) file(ctx context.Context, snippetPath, filePath string) (*models.FileInfo, error) {
+	fetched, err := p.get(ctx, snippetPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fetched.files {
+		if fetched.files[i].Path == filePath {
+			file := fetched.files[i]
+			return &file, nil
+		}
+	}
+	return nil, sherpaerrors.New(sherpaerrors.KindNotFound, fmt.Sprintf("file %s not found in %s", filePath, snippetPath))
+}
+
+func (p *SnippetProvider) GetRepository(ctx context.Context, repoPath string) (*models.Repository, error) {
+	fetched, err := p.get(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repository := *fetched.repository
+	return &repository, nil
+}
+
+// GetRepositoryTree lists the files of a snippet; snippets have no branches, so branch is ignored
+func (p *SnippetProvider) GetRepositoryTree(ctx context.Context, repoPath, branch string) ([]models.RepositoryTree, error) {
+	fetched, err := p.get(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := make([]models.RepositoryTree, 0, len(fetched.files))
+	for _, file := range fetched.files {
+		tree = append(tree, models.RepositoryTree{
+			Name: file.Name,
+			Type: "blob",
+			Path: file.Path,
+			Mode: "100644",
+			Size: file.Size,
+		})
+	}
+	return tree, nil
+}
+
+func (p *SnippetProvider) GetFileContent(ctx context.Context, repoPath, filePath, branch string) (string, error) {
+	file, err := p.file(ctx, repoPath, filePath)
+	if err != nil {
+		return "", err
+	}
+	if file.Error != nil {
+		return "", file.Error
+	}
+	return file.Content, nil
+}
+
+func (p *SnippetProvider) GetFileInfo(ctx context.Context, repoPath, filePath, branch string) (*models.FileInfo, error) {
+	return p.file(ctx, repoPath, filePath)
+}
+
+func (p *SnippetProvider) GetMultipleFiles(ctx context.Context, repoPath string, filePaths []string, branch string, maxConcurrency int, config *models.ProcessingConfig) ([]models.FileInfo, error) {
+	files := make([]models.FileInfo, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		file, err := p.file(ctx, repoPath, filePath)
+		if err != nil {
+			files = append(files, models.FileInfo{Path: filePath, Name: filePath[strings.LastIndex(filePath, "/")+1:], Error: err})
+			continue
+		}
+		files = append(files, *file)
+	}
+	return files, nil
+}
+
+func (p *SnippetProvider) TestConnection(ctx context.Context) error {
+	return p.testConnection(ctx)
+}
+
+// parseGitLabSnippetPath splits snippets/ID or group/project/-/snippets/ID into the project
+// path, empty for personal snippets, and the snippet id
+func parseGitLabSnippetPath(snippetPath string) (projectPath string, snippetID int, err error) {
+	idPart := strings.TrimPrefix(snippetPath, "snippets/")
+	if project, id, found := strings.Cut(snippetPath, "/-/snippets/"); found {
+		projectPath, idPart = project, id
+	}
+
+	snippetID, err = strconv.Atoi(idPart)
+	if err != nil || snippetID <= 0 {
+		return "", 0, fmt.Errorf("invalid GitLab snippet path format, expected 'snippets/ID' or 'group/project/-/snippets/ID'")
+	}
+	return projectPath, snippetID, nil
+}
@@ -0,0 +1,73 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitLabSnippetPath(t *testing.T) {
+	tests := []struct {
+		name            string
+		snippetPath     string
+		expectedProject string
+		expectedID      int
+		expectedError   bool
+	}{
+		{name: "should parse personal snippets", snippetPath: "snippets/42", expectedID: 42},
+		{name: "should parse project snippets", snippetPath: "group/sub/project/-/snippets/7", expectedProject: "group/sub/project", expectedID: 7},
+		{name: "should reject non-numeric ids", snippetPath: "snippets/abc", expectedError: true},
+		{name: "should reject repository paths", snippetPath: "group/project", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, id, err := parseGitLabSnippetPath(tt.snippetPath)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedProject, project)
+			assert.Equal(t, tt.expectedID, id)
+		})
+	}
+}
+
+func TestSnippetProvider(t *testing.T) {
+	fetches := 0
+	provider := newSnippetProvider(func(ctx context.Context, snippetPath string) (*models.Repository, []models.FileInfo, error) {
+		fetches++
+		return &models.Repository{Name: "helpers"}, []models.FileInfo{
+			{Path: "deploy.sh", Name: "deploy.sh", Content: "#!/bin/sh", Size: 9, IsText: true},
+		}, nil
+	}, func(ctx context.Context) error { return nil })
+
+	t.Run("should list snippet files as blobs", func(t *testing.T) {
+		tree, err := provider.GetRepositoryTree(context.Background(), "snippets/1", "")
+		require.NoError(t, err)
+		assert.Equal(t, []models.RepositoryTree{{Name: "deploy.sh", Type: "blob", Path: "deploy.sh", Mode: "100644", Size: 9}}, tree)
+	})
+
+	t.Run("should serve files from a single fetch", func(t *testing.T) {
+		content, err := provider.GetFileContent(context.Background(), "snippets/1", "deploy.sh", "")
+		require.NoError(t, err)
+		assert.Equal(t, "#!/bin/sh", content)
+
+		_, err = provider.GetRepository(context.Background(), "snippets/1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, fetches)
+	})
+
+	t.Run("should report missing files as not found", func(t *testing.T) {
+		files, err := provider.GetMultipleFiles(context.Background(), "snippets/1", []string{"missing.txt"}, "", 1, &models.ProcessingConfig{})
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.ErrorIs(t, files[0].Error, sherpaerrors.ErrNotFound)
+	})
+}
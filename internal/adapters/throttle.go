@@ -0,0 +1,273 @@
+package adapters
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sherpa/pkg/logger"
+)
+
+// lowQuotaShare is the share of a quota below which requests are spread over the time left
+// before it resets
+const lowQuotaShare = 0.1
+
+// RateLimit is the quota of a platform API as reported by its last response
+type RateLimit struct {
+	Host      string    `json:"host"`
+	Resource  string    `json:"resource"` // Quota requests count against: core, search or graphql
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// RateLimitStatus summarizes the quotas seen during a run and the time spent waiting for them
+type RateLimitStatus struct {
+	Limits []RateLimit   // Last known quota of each API, sorted by host and resource
+	Waits  int           // Number of times requests waited for a quota
+	Waited time.Duration // Total time spent waiting
+}
+
+// Throttler paces platform API requests by the rate limit headers of their responses,
+// X-RateLimit-* on GitHub and Gitea and RateLimit-* on GitLab. With a maximum wait, requests
+// wait for an exhausted quota to reset, rate limited requests are sent again once it resets,
+// and requests are spread over the time left when less than a tenth of the quota remains.
+// Quotas resetting later than the maximum wait are not waited for, so their requests fail
+// as rate limited, like without a maximum wait.
+type Throttler struct {
+	maxWait time.Duration
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+
+	mu     sync.Mutex
+	limits map[string]*RateLimit
+	waits  int
+	waited time.Duration
+}
+
+// NewThrottler creates a throttler waiting at most maxWait for a quota, or never when zero
+func NewThrottler(maxWait time.Duration) *Throttler {
+	return &Throttler{
+		maxWait: maxWait,
+		now:     time.Now,
+		sleep:   sleepContext,
+		limits:  make(map[string]*RateLimit),
+	}
+}
+
+// Transport wraps base so its requests are paced by the throttler, using
+// http.DefaultTransport when base is nil
+func (t *Throttler) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &throttledTransport{throttler: t, base: base}
+}
+
+// Status returns the quotas seen so far and the time spent waiting for them
+func (t *Throttler) Status() RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := RateLimitStatus{Waits: t.waits, Waited: t.waited}
+	for _, limit := range t.limits {
+		status.Limits = append(status.Limits, *limit)
+	}
+	sort.Slice(status.Limits, func(i, j int) bool {
+		if status.Limits[i].Host != status.Limits[j].Host {
+			return status.Limits[i].Host < status.Limits[j].Host
+		}
+		return status.Limits[i].Resource < status.Limits[j].Resource
+	})
+	return status
+}
+
+// throttledTransport is an http.RoundTripper pacing requests with a Throttler
+type throttledTransport struct {
+	throttler *Throttler
+	base      http.RoundTripper
+}
+
+// RoundTrip waits for the quota of the request, sends it, and sends it again once when it is
+// rate limited and the quota resets within the maximum wait
+func (tt *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := tt.throttler
+	host, resource := req.URL.Host, rateLimitResource(req.URL.Path)
+	if err := t.wait(req.Context(), host, resource, t.reserve(host, resource)); err != nil {
+		return nil, err
+	}
+
+	resp, err := tt.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.observe(host, resource, resp.Header)
+
+	delay, limited := t.retryDelay(resp)
+	if !limited || (req.Body != nil && req.GetBody == nil) {
+		return resp, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if err := t.wait(req.Context(), host, resource, delay); err != nil {
+		return nil, err
+	}
+
+	resp, err = tt.base.RoundTrip(retry)
+	if err != nil {
+		return nil, err
+	}
+	t.observe(host, resource, resp.Header)
+	return resp, nil
+}
+
+// reserve counts a request against the known quota of an API and returns how long it
+// should wait first: until the reset when the quota is exhausted, or its share of the time
+// left when the quota is low. Waits above the maximum wait are skipped.
+func (t *Throttler) reserve(host, resource string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	limit, exists := t.limits[host+" "+resource]
+	if !exists || t.maxWait <= 0 {
+		return 0
+	}
+	untilReset := limit.Reset.Sub(t.now())
+	remaining := limit.Remaining
+	if limit.Remaining > 0 {
+		limit.Remaining--
+	}
+	if untilReset <= 0 {
+		return 0
+	}
+
+	var delay time.Duration
+	switch {
+	case remaining <= 0:
+		delay = untilReset + time.Second
+	case limit.Limit > 0 && float64(remaining) < lowQuotaShare*float64(limit.Limit):
+		delay = untilReset / time.Duration(remaining+1)
+	}
+	if delay > t.maxWait {
+		return 0
+	}
+	return delay
+}
+
+// wait sleeps for delay, recording the wait, unless ctx is done first
+func (t *Throttler) wait(ctx context.Context, host, resource string, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	logger.Logger.WithFields(map[string]interface{}{
+		"host":     host,
+		"resource": resource,
+		"wait":     delay.Round(time.Second).String(),
+	}).Info("Waiting for the rate limit quota")
+
+	t.mu.Lock()
+	t.waits++
+	t.waited += delay
+	t.mu.Unlock()
+	return t.sleep(ctx, delay)
+}
+
+// observe records the quota reported by the headers of a response
+func (t *Throttler) observe(host, resource string, header http.Header) {
+	limit, remaining, reset, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits[host+" "+resource] = &RateLimit{Host: host, Resource: resource, Limit: limit, Remaining: remaining, Reset: reset}
+	if limit > 0 && float64(remaining) < lowQuotaShare*float64(limit) {
+		logger.Logger.WithFields(map[string]interface{}{
+			"host":      host,
+			"resource":  resource,
+			"remaining": remaining,
+			"limit":     limit,
+			"reset":     reset.Format(time.RFC3339),
+		}).Debug("Rate limit quota is low")
+	}
+}
+
+// retryDelay reports whether a response was rate limited and how long to wait before
+// sending the request again, from its Retry-After or rate limit reset headers. Responses
+// whose quota resets later than the maximum wait are not retried.
+func (t *Throttler) retryDelay(resp *http.Response) (time.Duration, bool) {
+	if t.maxWait <= 0 {
+		return 0, false
+	}
+	_, remaining, reset, hasLimit := parseRateLimit(resp.Header)
+	retryAfter := resp.Header.Get("Retry-After")
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && (retryAfter != "" || (hasLimit && remaining == 0)))
+	if !limited {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if hasLimit && !reset.IsZero() {
+		delay = reset.Sub(t.now()) + time.Second
+	} else {
+		return 0, false
+	}
+	if delay > t.maxWait {
+		return 0, false
+	}
+	return max(delay, 0), true
+}
+
+// parseRateLimit reads the quota of an API from X-RateLimit-* or RateLimit-* headers, whose
+// reset is a Unix time
+func parseRateLimit(header http.Header) (limit, remaining int, reset time.Time, ok bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		value, err := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if err != nil {
+			continue
+		}
+		limit, _ = strconv.Atoi(header.Get(prefix + "Limit"))
+		if seconds, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			reset = time.Unix(seconds, 0)
+		}
+		return limit, value, reset, true
+	}
+	return 0, 0, time.Time{}, false
+}
+
+// rateLimitResource returns the quota a request counts against, named like the resources
+// of the GitHub rate limit API
+func rateLimitResource(urlPath string) string {
+	switch {
+	case strings.Contains(urlPath, "/search/"):
+		return "search"
+	case strings.HasSuffix(urlPath, "/graphql"):
+		return "graphql"
+	default:
+		return "core"
+	}
+}
+
+// sleepContext sleeps for d, or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
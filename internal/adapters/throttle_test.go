@@ -0,0 +1,139 @@
+package adapters
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rateLimitedServer answers with the quota it is given, rate limiting requests once it is
+// exhausted until reset
+type rateLimitedServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	remaining int
+	reset     time.Time
+	requests  int
+}
+
+func newRateLimitedServer(t *testing.T, remaining int, reset time.Time) *rateLimitedServer {
+	server := &rateLimitedServer{remaining: remaining, reset: reset}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		server.requests++
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(server.reset.Unix(), 10))
+		if server.remaining == 0 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		server.remaining--
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(server.remaining))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestThrottler returns a throttler whose sleeps are recorded, and refill the quota of
+// server when it is set
+func newTestThrottler(maxWait time.Duration, now time.Time, server *rateLimitedServer) (*Throttler, *[]time.Duration) {
+	var sleeps []time.Duration
+	throttler := NewThrottler(maxWait)
+	throttler.now = func() time.Time { return now }
+	throttler.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		if server != nil {
+			server.mu.Lock()
+			server.remaining = 100
+			server.mu.Unlock()
+		}
+		return nil
+	}
+	return throttler, &sleeps
+}
+
+func get(t *testing.T, transport http.RoundTripper, url string) int {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestThrottler(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	t.Run("should record the quota of each API", func(t *testing.T) {
+		server := newRateLimitedServer(t, 50, now.Add(time.Hour))
+		throttler, sleeps := newTestThrottler(0, now, nil)
+
+		get(t, throttler.Transport(nil), server.URL+"/repos/owner/repo")
+		get(t, throttler.Transport(nil), server.URL+"/search/code")
+
+		status := throttler.Status()
+		require.Len(t, status.Limits, 2)
+		assert.Equal(t, "core", status.Limits[0].Resource)
+		assert.Equal(t, 49, status.Limits[0].Remaining)
+		assert.Equal(t, 100, status.Limits[0].Limit)
+		assert.Equal(t, now.Add(time.Hour), status.Limits[0].Reset)
+		assert.Equal(t, "search", status.Limits[1].Resource)
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("should not wait without a maximum wait", func(t *testing.T) {
+		server := newRateLimitedServer(t, 0, now.Add(time.Minute))
+		throttler, sleeps := newTestThrottler(0, now, server)
+
+		assert.Equal(t, http.StatusForbidden, get(t, throttler.Transport(nil), server.URL))
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("should send a rate limited request again once the quota resets", func(t *testing.T) {
+		server := newRateLimitedServer(t, 0, now.Add(time.Minute))
+		throttler, sleeps := newTestThrottler(5*time.Minute, now, server)
+
+		assert.Equal(t, http.StatusOK, get(t, throttler.Transport(nil), server.URL))
+		assert.Equal(t, []time.Duration{time.Minute + time.Second}, *sleeps)
+		assert.Equal(t, 2, server.requests)
+		assert.Equal(t, 1, throttler.Status().Waits)
+	})
+
+	t.Run("should not wait for a reset later than the maximum wait", func(t *testing.T) {
+		server := newRateLimitedServer(t, 0, now.Add(time.Hour))
+		throttler, sleeps := newTestThrottler(5*time.Minute, now, server)
+
+		assert.Equal(t, http.StatusForbidden, get(t, throttler.Transport(nil), server.URL))
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("should wait for an exhausted quota before sending requests", func(t *testing.T) {
+		server := newRateLimitedServer(t, 1, now.Add(time.Minute))
+		throttler, sleeps := newTestThrottler(5*time.Minute, now, server)
+		transport := throttler.Transport(nil)
+
+		get(t, transport, server.URL)
+		assert.Equal(t, http.StatusOK, get(t, transport, server.URL))
+		assert.Equal(t, []time.Duration{time.Minute + time.Second}, *sleeps)
+		assert.Equal(t, 2, server.requests)
+	})
+
+	t.Run("should spread requests over the time left when the quota is low", func(t *testing.T) {
+		server := newRateLimitedServer(t, 6, now.Add(time.Minute))
+		throttler, sleeps := newTestThrottler(5*time.Minute, now, nil)
+		transport := throttler.Transport(nil)
+
+		get(t, transport, server.URL)
+		get(t, transport, server.URL)
+		assert.Equal(t, []time.Duration{10 * time.Second}, *sleeps)
+	})
+}
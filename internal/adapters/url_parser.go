@@ -19,17 +19,7 @@ func NewURLParser() *URLParser {
 
 // ParseRepositoryURL parses a repository URL or path and returns repository information
 func (p *URLParser) ParseRepositoryURL(input string, defaultPlatform models.Platform) (*models.RepositoryInfo, error) {
-	input = strings.TrimSpace(input)
-
-	// Extract branch from fragment (e.g., #develop)
-	var branch string
-	if strings.Contains(input, "#") {
-		parts := strings.Split(input, "#")
-		if len(parts) == 2 {
-			input = parts[0]
-			branch = parts[1]
-		}
-	}
+	input, branch := splitBranch(strings.TrimSpace(input))
 
 	// Handle URLs
 	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"sherpa/pkg/models"
+)
+
+// aliasNamePattern restricts alias names to words that cannot be mistaken for paths or URLs
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// DefaultConfigFile returns the user configuration file read when --config is not set,
+// like ~/.config/sherpa/config.yml, or an empty string when there is no home directory
+func DefaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sherpa", "config.yml")
+}
+
+// ValidateAliasName checks that an alias name is a single word
+func ValidateAliasName(name string) error {
+	if !aliasNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid alias name '%s': use letters, digits, '.', '_' and '-' only", name)
+	}
+	return nil
+}
+
+// ResolveAliases replaces the arguments naming an alias of the configuration with the
+// repository it stands for. A branch given after the alias, like pay#develop, replaces the
+// branch of the repository.
+func (l *Loader) ResolveAliases(config *models.Config, args []string) []string {
+	resolved := make([]string, len(args))
+	for i, arg := range args {
+		name, branch, hasBranch := strings.Cut(arg, "#")
+		target, exists := config.Aliases[name]
+		if !exists {
+			resolved[i] = arg
+			continue
+		}
+		if hasBranch {
+			target, _, _ = strings.Cut(target, "#")
+			target += "#" + branch
+		}
+		resolved[i] = target
+	}
+	return resolved
+}
+
+// SetAlias records an alias in a configuration file, creating the file when needed.
+// Comments and other settings of the file are kept.
+func SetAlias(configFile, name, repository string) error {
+	if err := ValidateAliasName(name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(repository) == "" {
+		return fmt.Errorf("alias '%s' needs a repository", name)
+	}
+
+	return updateAliases(configFile, func(aliases *yaml.Node) error {
+		for i := 0; i+1 < len(aliases.Content); i += 2 {
+			if aliases.Content[i].Value == name {
+				aliases.Content[i+1] = scalarNode(repository)
+				return nil
+			}
+		}
+		aliases.Content = append(aliases.Content, scalarNode(name), scalarNode(repository))
+		return nil
+	})
+}
+
+// RemoveAlias deletes an alias from a configuration file
+func RemoveAlias(configFile, name string) error {
+	return updateAliases(configFile, func(aliases *yaml.Node) error {
+		for i := 0; i+1 < len(aliases.Content); i += 2 {
+			if aliases.Content[i].Value == name {
+				aliases.Content = append(aliases.Content[:i], aliases.Content[i+2:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("alias '%s' does not exist", name)
+	})
+}
+
+// updateAliases applies update to the aliases mapping of a configuration file and writes
+// the file back
+func updateAliases(configFile string, update func(aliases *yaml.Node) error) error {
+	if configFile == "" {
+		return fmt.Errorf("no configuration file to store aliases in")
+	}
+
+	var document yaml.Node
+	data, err := os.ReadFile(configFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s is not a mapping", configFile)
+	}
+
+	var aliases *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "aliases" {
+			aliases = root.Content[i+1]
+		}
+	}
+	if aliases == nil {
+		aliases = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		root.Content = append(root.Content, scalarNode("aliases"), aliases)
+	} else if aliases.Kind != yaml.MappingNode {
+		// An empty aliases key is null
+		*aliases = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	if err := update(aliases); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(configFile, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// scalarNode returns a YAML string node
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAlias(t *testing.T) {
+	t.Run("should create the config file and its directory", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "sherpa", "config.yml")
+		require.NoError(t, SetAlias(configFile, "pay", "gitlab.com/org/payments#main"))
+
+		config, err := NewLoader().LoadConfig(configFile)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"pay": "gitlab.com/org/payments#main"}, config.Aliases)
+	})
+
+	t.Run("should keep other settings and comments", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(configFile, []byte("# Team settings\noutput:\n  directory: ./contexts\naliases:\n  web: owner/web\n"), 0644))
+
+		require.NoError(t, SetAlias(configFile, "pay", "gitlab.com/org/payments"))
+		require.NoError(t, SetAlias(configFile, "web", "owner/web#develop"))
+
+		data, err := os.ReadFile(configFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "# Team settings")
+
+		config, err := NewLoader().LoadConfig(configFile)
+		require.NoError(t, err)
+		assert.Equal(t, "./contexts", config.Output.Directory)
+		assert.Equal(t, map[string]string{"web": "owner/web#develop", "pay": "gitlab.com/org/payments"}, config.Aliases)
+	})
+
+	t.Run("should reject names that look like paths", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yml")
+		assert.ErrorContains(t, SetAlias(configFile, "org/pay", "gitlab.com/org/payments"), "invalid alias name")
+		assert.ErrorContains(t, SetAlias(configFile, "-pay", "gitlab.com/org/payments"), "invalid alias name")
+		assert.NoFileExists(t, configFile)
+	})
+}
+
+func TestRemoveAlias(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, SetAlias(configFile, "pay", "gitlab.com/org/payments"))
+	require.NoError(t, SetAlias(configFile, "web", "owner/web"))
+
+	t.Run("should remove the alias", func(t *testing.T) {
+		require.NoError(t, RemoveAlias(configFile, "pay"))
+
+		config, err := NewLoader().LoadConfig(configFile)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"web": "owner/web"}, config.Aliases)
+	})
+
+	t.Run("should fail for unknown aliases", func(t *testing.T) {
+		assert.ErrorContains(t, RemoveAlias(configFile, "missing"), "does not exist")
+	})
+}
+
+func TestLoader_ResolveAliases(t *testing.T) {
+	config := &models.Config{Aliases: map[string]string{
+		"pay": "https://gitlab.com/org/payments/backend#main",
+		"web": "owner/web",
+	}}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "should replace aliases with their repository",
+			args:     []string{"pay", "web", "./local"},
+			expected: []string{"https://gitlab.com/org/payments/backend#main", "owner/web", "./local"},
+		},
+		{
+			name:     "should override the branch of an alias",
+			args:     []string{"pay#develop", "web#v2"},
+			expected: []string{"https://gitlab.com/org/payments/backend#develop", "owner/web#v2"},
+		},
+		{
+			name:     "should keep arguments that are not aliases",
+			args:     []string{"owner/pay", "payments"},
+			expected: []string{"owner/pay", "payments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewLoader().ResolveAliases(config, tt.args))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// validateAltText checks the alt text settings, which need an endpoint and a model once
+// alt text is included
+func validateAltText(config models.AltTextConfig) error {
+	if config.Limit < 0 {
+		return fmt.Errorf("alt text limit must not be negative")
+	}
+	if config.Timeout < 0 {
+		return fmt.Errorf("alt text timeout must not be negative")
+	}
+	if config.MaxSize != "" {
+		if _, err := utils.ParseSize(config.MaxSize); err != nil {
+			return fmt.Errorf("invalid alt text max_size: %w", err)
+		}
+	}
+	if !config.Include {
+		return nil
+	}
+
+	endpoint, err := url.Parse(config.Endpoint)
+	if config.Endpoint == "" || err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("alt text needs the http or https URL of a chat completions endpoint: set processing.alt_text.endpoint or --alt-text-endpoint")
+	}
+	if config.Model == "" {
+		return fmt.Errorf("alt text needs a vision-capable model: set processing.alt_text.model or --alt-text-model")
+	}
+	return nil
+}
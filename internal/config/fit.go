@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// FitPreset holds the upload constraints of a downstream tool, applied to the output so the
+// generated files can be uploaded as they are. Empty or zero values keep the configuration.
+type FitPreset struct {
+	Description string // The constraints the preset follows
+	SplitSize   string // Largest file the tool accepts
+	SplitTokens string // Largest file the tool reads in full
+	MaxTokens   int    // Total content the tool accepts across files
+	MaxParts    int    // Most files the tool accepts
+}
+
+// FitPresets are the downstream tools outputs can be fitted for with --fit-for
+var FitPresets = map[string]FitPreset{
+	"cursor": {
+		Description: "files of at most 1MB, which Cursor indexes",
+		SplitSize:   "1MB",
+	},
+	"continue": {
+		Description: "files of at most 100k tokens, so one fits the context of Continue models",
+		SplitTokens: "100k",
+	},
+	"claude-projects": {
+		Description: "files of at most 30MB, with about 180k tokens of project knowledge",
+		SplitSize:   "30MB",
+		MaxTokens:   180000,
+	},
+	"chatgpt-projects": {
+		Description: "up to 20 files of at most 2M tokens and 512MB",
+		SplitSize:   "512MB",
+		SplitTokens: "2M",
+		MaxParts:    20,
+	},
+}
+
+// FitPresetNames returns the names of the fit presets, sorted
+func FitPresetNames() []string {
+	names := make([]string, 0, len(FitPresets))
+	for name := range FitPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyFitPreset applies the constraints of the tool named by --fit-for to the output. They
+// split llms-full.txt, so the output format is switched to text.
+func applyFitPreset(config *models.Config, tool string) error {
+	preset, exists := FitPresets[strings.ToLower(tool)]
+	if !exists {
+		return fmt.Errorf("unknown --fit-for tool '%s'. Valid options: %s", tool, strings.Join(FitPresetNames(), ", "))
+	}
+	if preset.SplitSize != "" {
+		config.Output.SplitSize = preset.SplitSize
+	}
+	if preset.SplitTokens != "" {
+		config.Output.SplitTokens = preset.SplitTokens
+	}
+	if preset.MaxTokens > 0 {
+		config.Output.MaxTokens = preset.MaxTokens
+	}
+	if preset.MaxParts > 0 {
+		config.Output.MaxParts = preset.MaxParts
+	}
+	if config.Output.Format != "" && config.Output.Format != models.FormatText {
+		logger.Logger.WithField("format", config.Output.Format).Warnf("--fit-for %s writes the %s format", tool, models.FormatText)
+	}
+	config.Output.Format = models.FormatText
+	return nil
+}
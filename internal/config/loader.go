@@ -2,15 +2,28 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+	"sherpa/internal/generators"
 	"sherpa/pkg/models"
 	"sherpa/pkg/utils"
 )
 
+// DefaultLockFile is the lock file written with --lock and read with --locked when
+// output.lock_file is not set
+const DefaultLockFile = "sherpa.lock"
+
 // Loader handles configuration loading and validation
-type Loader struct{}
+type Loader struct {
+	Offline bool // Serve the organization policy from its cache without fetching it
+
+	client   *http.Client // Fetches the organization policy, with a timeout when nil
+	cacheDir string       // Caches the organization policy, in the user cache directory when empty
+}
 
 // NewLoader creates a new configuration loader
 func NewLoader() *Loader {
@@ -28,9 +41,15 @@ func (l *Loader) LoadConfig(configFile string) (*models.Config, error) {
 				return nil, fmt.Errorf("failed to read config file: %w", err)
 			}
 
+			// The organization policy adds its rules to the local configuration
+			policy, err := l.loadPolicy(data)
+			if err != nil {
+				return nil, err
+			}
 			if err := yaml.Unmarshal(data, config); err != nil {
 				return nil, fmt.Errorf("failed to parse config file: %w", err)
 			}
+			enforcePolicy(config, policy)
 		}
 	}
 
@@ -47,6 +66,15 @@ func (l *Loader) getDefaultConfig() *models.Config {
 		GitHub: models.GitHubConfig{
 			BaseURL:  "https://api.github.com",
 			TokenEnv: "GITHUB_TOKEN",
+			API:      models.GitHubAPIREST,
+		},
+		Gitea: models.GiteaConfig{
+			BaseURL:  "https://gitea.com",
+			TokenEnv: "GITEA_TOKEN",
+		},
+		Local: models.LocalConfig{
+			MaxWalkEntries: 500000,
+			Mounts:         models.MountsLocal,
 		},
 		Processing: models.ProcessingConfig{
 			Ignore: []string{
@@ -60,19 +88,61 @@ func (l *Loader) getDefaultConfig() *models.Config {
 			IncludeOnly:      []string{},
 			MaxFileSize:      "1MB",
 			SkipBinary:       true,
+			BinaryExtensions: []string{
+				".png", ".jpg", ".jpeg", ".gif", ".bmp", ".ico", ".webp", ".tif", ".tiff", ".psd",
+				".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx",
+				".zip", ".gz", ".tgz", ".bz2", ".xz", ".7z", ".rar", ".tar", ".jar", ".war",
+				".woff", ".woff2", ".ttf", ".otf", ".eot",
+				".mp3", ".mp4", ".mov", ".avi", ".wav", ".ogg", ".webm", ".flac",
+				".exe", ".dll", ".so", ".dylib", ".a", ".o", ".class", ".pyc", ".wasm", ".bin",
+			},
 			MaxConcurrency:   20,
 			MaxMemoryPerFile: 50 * 1024 * 1024,  // 50MB per file
 			MaxTotalMemory:   2 * 1024 * 1024 * 1024, // 2GB total limit
 			MaxFiles:         1000,              // Maximum number of files to process
+			FrameworkPresets: true,
+			RepoConfig:       true,
+			VendoredVersions: true,
+			Issues: models.IssuesConfig{
+				State: models.IssueStateOpen,
+				Limit: 20,
+			},
+			Releases: models.ReleasesConfig{
+				Limit: 10,
+			},
+			AltText: models.AltTextConfig{
+				APIKeyEnv: "SHERPA_ALT_TEXT_API_KEY",
+				Limit:     20,
+				MaxSize:   "4MB",
+				Timeout:   time.Minute,
+			},
 		},
 		Output: models.OutputConfig{
 			Directory:      "./sherpa-output",
 			OrganizeByDate: false,
+			Language:       "en",
+			TreeStyle:      models.TreeStyleUnix,
+			WriteWorkers:   8,
+			Fsync:          models.FsyncNone,
+			Packing:        models.PackingGreedy,
+			Format:         models.FormatText,
+			Artifacts:      models.ArtifactsFull,
+			SizeUnits:      models.SizeUnitsBinary,
+		},
+		Sensitive: models.SensitiveConfig{
+			Patterns: []string{
+				"auth/",
+				"payments/",
+				"crypto/",
+			},
+			Disclaimer: "This document contains source code from security-sensitive areas. " +
+				"Do not share it outside approved tools and follow your organization's data handling policy.",
 		},
 		Cache: models.CacheConfig{
 			Enabled:   false,
 			Directory: "./.sherpa-cache",
 			TTL:       0,
+			SkipAfter: 3,
 		},
 	}
 }
@@ -83,6 +153,8 @@ func (l *Loader) OverrideWithFlags(config *models.Config, flags *models.CLIOptio
 		// Determine which platform to update based on the base URL
 		if flags.BaseURL == "https://api.github.com" || flags.BaseURL == "https://github.com" {
 			config.GitHub.BaseURL = flags.BaseURL
+		} else if strings.EqualFold(flags.DefaultPlatform, string(models.PlatformGitea)) {
+			config.Gitea.BaseURL = flags.BaseURL
 		} else {
 			config.GitLab.BaseURL = flags.BaseURL
 		}
@@ -92,6 +164,13 @@ func (l *Loader) OverrideWithFlags(config *models.Config, flags *models.CLIOptio
 		config.Output.Directory = flags.Output
 	}
 
+	// Output flags given with --fit-for refine its preset
+	if flags.FitFor != "" {
+		if err := applyFitPreset(config, flags.FitFor); err != nil {
+			return err
+		}
+	}
+
 	if flags.Ignore != "" {
 		config.Processing.Ignore = utils.ParsePatterns(flags.Ignore)
 	}
@@ -112,6 +191,189 @@ func (l *Loader) OverrideWithFlags(config *models.Config, flags *models.CLIOptio
 		config.Processing.MaxFiles = flags.MaxFiles
 	}
 
+	if flags.MaxRepoSize != "" {
+		config.Processing.MaxRepoSize = flags.MaxRepoSize
+	}
+
+	if flags.NoRepoConfig {
+		config.Processing.RepoConfig = false
+	}
+
+	if flags.Match != "" {
+		config.Processing.Match = flags.Match
+	}
+
+	if flags.MatchNeighbors {
+		config.Processing.MatchNeighbors = true
+	}
+
+	if flags.Diff != "" {
+		config.Processing.Diff = flags.Diff
+	}
+
+	if flags.IncludeIssues {
+		config.Processing.Issues.Include = true
+	}
+
+	if flags.IssueState != "" {
+		config.Processing.Issues.State = strings.ToLower(flags.IssueState)
+	}
+
+	if len(flags.IssueLabels) > 0 {
+		config.Processing.Issues.Labels = flags.IssueLabels
+	}
+
+	if flags.IssueLimit > 0 {
+		config.Processing.Issues.Limit = flags.IssueLimit
+	}
+
+	if flags.IncludeReleases {
+		config.Processing.Releases.Include = true
+	}
+
+	if flags.ReleaseLimit > 0 {
+		config.Processing.Releases.Limit = flags.ReleaseLimit
+	}
+
+	if flags.IncludeWiki {
+		config.Processing.Wiki = true
+	}
+
+	if flags.AltText {
+		config.Processing.AltText.Include = true
+	}
+
+	if flags.AltTextEndpoint != "" {
+		config.Processing.AltText.Endpoint = flags.AltTextEndpoint
+	}
+
+	if flags.AltTextModel != "" {
+		config.Processing.AltText.Model = flags.AltTextModel
+	}
+
+	if flags.BudgetTime > 0 {
+		config.Processing.BudgetTime = flags.BudgetTime
+	}
+
+	if flags.Sample != "" {
+		config.Processing.Sample = flags.Sample
+	}
+
+	if flags.Language != "" {
+		config.Output.Language = flags.Language
+	}
+
+	if flags.MaxTreeEntries > 0 {
+		config.Output.MaxTreeEntries = flags.MaxTreeEntries
+	}
+
+	if flags.ExpandTree {
+		config.Output.ExpandTree = true
+	}
+
+	if flags.TreeJSON {
+		config.Output.TreeJSON = true
+	}
+
+	if flags.LLMsTxt {
+		config.Output.LLMsTxt = true
+	}
+
+	if flags.LLMsTxtLegacy {
+		config.Output.LLMsTxtLegacy = true
+	}
+
+	if flags.Artifacts != "" {
+		config.Output.Artifacts = flags.Artifacts
+	}
+
+	if flags.FileTags {
+		config.Output.FileTags = true
+	}
+
+	if flags.WriteWorkers > 0 {
+		config.Output.WriteWorkers = flags.WriteWorkers
+	}
+
+	if flags.Fsync != "" {
+		config.Output.Fsync = flags.Fsync
+	}
+
+	if flags.SI {
+		config.Output.SizeUnits = models.SizeUnitsSI
+	}
+
+	if flags.TokenBudget > 0 {
+		config.Output.TokenBudget = flags.TokenBudget
+	}
+
+	if flags.Packing != "" {
+		config.Output.Packing = flags.Packing
+	}
+
+	if flags.NameTemplate != "" {
+		config.Output.NameTemplate = flags.NameTemplate
+	}
+
+	if flags.MaxTokens > 0 {
+		config.Output.MaxTokens = flags.MaxTokens
+	}
+
+	if flags.SplitSize != "" {
+		config.Output.SplitSize = flags.SplitSize
+	}
+
+	if flags.SplitTokens != "" {
+		config.Output.SplitTokens = flags.SplitTokens
+	}
+
+	if flags.Format != "" {
+		config.Output.Format = flags.Format
+	}
+
+	if flags.Incremental {
+		config.Output.Incremental = true
+	}
+
+	if flags.Deterministic {
+		config.Output.Deterministic = true
+	}
+
+	if (flags.Lock || flags.Locked) && config.Output.LockFile == "" {
+		config.Output.LockFile = DefaultLockFile
+	}
+
+	if flags.RepoLogs {
+		config.Output.RepoLogs = true
+	}
+
+	if flags.EditorRules {
+		config.Output.EditorRules = true
+	}
+
+	if flags.StripLicenses {
+		config.Output.StripLicenses = true
+	}
+
+	if flags.UserAgent != "" {
+		config.HTTP.UserAgent = flags.UserAgent
+	}
+
+	if flags.MaxWait > 0 {
+		config.HTTP.MaxWait = flags.MaxWait
+	}
+
+	for _, header := range flags.Headers {
+		name, value, found := strings.Cut(header, ":")
+		if !found {
+			return fmt.Errorf("invalid header '%s', expected 'Name: value'", header)
+		}
+		if config.HTTP.Headers == nil {
+			config.HTTP.Headers = make(map[string]string)
+		}
+		config.HTTP.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
 	return nil
 }
 
@@ -127,5 +389,193 @@ func (l *Loader) ValidateConfig(config *models.Config) error {
 		}
 	}
 
+	for _, ext := range config.Processing.BinaryExtensions {
+		if strings.Trim(ext, ".") == "" || strings.ContainsAny(ext, "/*?[") {
+			return fmt.Errorf("invalid binary extension '%s': use extensions like .png", ext)
+		}
+	}
+
+	if config.Processing.MaxRepoSize != "" {
+		if _, err := utils.ParseSize(config.Processing.MaxRepoSize); err != nil {
+			return fmt.Errorf("invalid max_repo_size: %w", err)
+		}
+	}
+
+	if config.Processing.Diff != "" {
+		if _, _, err := models.ParseDiffRange(config.Processing.Diff); err != nil {
+			return err
+		}
+		if config.Processing.Match != "" {
+			return fmt.Errorf("diff and match cannot be combined")
+		}
+		if config.Output.Incremental {
+			return fmt.Errorf("diff and incremental cannot be combined")
+		}
+	}
+
+	switch config.Processing.Issues.State {
+	case "", models.IssueStateOpen, models.IssueStateClosed, models.IssueStateAll:
+	default:
+		return fmt.Errorf("invalid issue state '%s'. Valid options: %s, %s, %s", config.Processing.Issues.State, models.IssueStateOpen, models.IssueStateClosed, models.IssueStateAll)
+	}
+
+	if config.Processing.Issues.Limit < 0 {
+		return fmt.Errorf("issue limit must not be negative")
+	}
+
+	if config.Processing.Releases.Limit < 0 {
+		return fmt.Errorf("release limit must not be negative")
+	}
+
+	if err := validateAltText(config.Processing.AltText); err != nil {
+		return err
+	}
+
+	if config.HTTP.MaxWait < 0 {
+		return fmt.Errorf("max_wait must not be negative")
+	}
+
+	if config.Processing.BudgetTime < 0 {
+		return fmt.Errorf("budget_time must not be negative")
+	}
+
+	if config.Processing.Sample != "" {
+		if _, err := utils.ParsePercent(config.Processing.Sample); err != nil {
+			return fmt.Errorf("invalid sample: %w", err)
+		}
+	}
+
+	switch config.Output.TreeStyle {
+	case "", models.TreeStyleUnix, models.TreeStylePlain:
+	default:
+		return fmt.Errorf("invalid tree_style '%s'. Valid options: %s, %s", config.Output.TreeStyle, models.TreeStyleUnix, models.TreeStylePlain)
+	}
+
+	for _, platform := range []models.Platform{models.PlatformGitHub, models.PlatformGitLab, models.PlatformGitea} {
+		requests := config.Requests(platform)
+		if requests.MaxConcurrency < 0 || requests.Retries < 0 || requests.Backoff < 0 {
+			return fmt.Errorf("%s max_concurrency, retries and backoff must not be negative", platform)
+		}
+	}
+
+	if config.Local.MaxWalkEntries < 0 {
+		return fmt.Errorf("max_walk_entries must not be negative")
+	}
+
+	switch config.Local.Mounts {
+	case "", models.MountsAll, models.MountsLocal, models.MountsSame:
+	default:
+		return fmt.Errorf("invalid local mounts '%s'. Valid options: %s, %s, %s", config.Local.Mounts, models.MountsAll, models.MountsLocal, models.MountsSame)
+	}
+
+	switch config.GitHub.API {
+	case "", models.GitHubAPIREST, models.GitHubAPIGraphQL:
+	default:
+		return fmt.Errorf("invalid github api '%s'. Valid options: %s, %s", config.GitHub.API, models.GitHubAPIREST, models.GitHubAPIGraphQL)
+	}
+
+	switch config.Output.Fsync {
+	case "", models.FsyncNone, models.FsyncFile, models.FsyncFull:
+	default:
+		return fmt.Errorf("invalid fsync policy '%s'. Valid options: %s, %s, %s", config.Output.Fsync, models.FsyncNone, models.FsyncFile, models.FsyncFull)
+	}
+
+	switch config.Output.SizeUnits {
+	case "", models.SizeUnitsBinary, models.SizeUnitsSI:
+	default:
+		return fmt.Errorf("invalid size_units '%s'. Valid options: %s, %s", config.Output.SizeUnits, models.SizeUnitsBinary, models.SizeUnitsSI)
+	}
+
+	switch config.Output.Packing {
+	case "", models.PackingGreedy, models.PackingKnapsack:
+	default:
+		return fmt.Errorf("invalid packing strategy '%s'. Valid options: %s, %s", config.Output.Packing, models.PackingGreedy, models.PackingKnapsack)
+	}
+
+	if config.Output.NameTemplate != "" {
+		values := make(map[string]string, len(models.OutputNameTokens))
+		for _, token := range models.OutputNameTokens {
+			values[token] = token
+		}
+		if _, err := utils.ExpandNameTemplate(config.Output.NameTemplate, values); err != nil {
+			return fmt.Errorf("invalid name_template '%s': %w", config.Output.NameTemplate, err)
+		}
+		if !strings.Contains(config.Output.NameTemplate, "{repo}") && !strings.Contains(config.Output.NameTemplate, "{name}") {
+			return fmt.Errorf("invalid name_template '%s': must include {repo} or {name}", config.Output.NameTemplate)
+		}
+	}
+
+	switch config.Output.Format {
+	case "", models.FormatText, models.FormatMarkdown, models.FormatRepomix:
+	default:
+		return fmt.Errorf("invalid output format '%s'. Valid options: %s, %s, %s", config.Output.Format, models.FormatText, models.FormatMarkdown, models.FormatRepomix)
+	}
+
+	switch config.Output.Artifacts {
+	case "", models.ArtifactsFull, models.ArtifactsIndex, models.ArtifactsBoth:
+	default:
+		return fmt.Errorf("invalid artifacts '%s'. Valid options: %s, %s, %s", config.Output.Artifacts, models.ArtifactsFull, models.ArtifactsIndex, models.ArtifactsBoth)
+	}
+
+	if config.Output.TokenBudget < 0 {
+		return fmt.Errorf("token_budget must not be negative")
+	}
+
+	if config.Output.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative")
+	}
+
+	if config.Output.SplitSize != "" {
+		if _, err := utils.ParseSize(config.Output.SplitSize); err != nil {
+			return fmt.Errorf("invalid split_size: %w", err)
+		}
+	}
+
+	if config.Output.SplitTokens != "" {
+		if _, err := utils.ParseTokenCount(config.Output.SplitTokens); err != nil {
+			return fmt.Errorf("invalid split_tokens: %w", err)
+		}
+	}
+
+	if config.Output.MaxParts < 0 {
+		return fmt.Errorf("max_parts must not be negative")
+	}
+
+	if (config.Output.SplitSize != "" || config.Output.SplitTokens != "") && config.Output.Format != "" && config.Output.Format != models.FormatText {
+		return fmt.Errorf("split_size and split_tokens are only supported with the %s format", models.FormatText)
+	}
+
+	if config.Output.Deterministic && config.Output.OrganizeByDate {
+		return fmt.Errorf("deterministic outputs cannot be organized by date")
+	}
+
+	if strings.ContainsAny(config.HTTP.UserAgent, "\r\n") {
+		return fmt.Errorf("invalid user_agent: must be a single line")
+	}
+
+	for name, value := range config.HTTP.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:()<>@,;\\\"/[]?={}") {
+			return fmt.Errorf("invalid header name '%s'", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value for header %s: must be a single line", name)
+		}
+	}
+
+	for _, repo := range config.Repos {
+		if strings.TrimSpace(repo.Repo) == "" {
+			return fmt.Errorf("repositories entries need a repo")
+		}
+		for _, tag := range repo.Tags {
+			if err := ValidateTag(tag); err != nil {
+				return fmt.Errorf("repository %s: %w", repo.Repo, err)
+			}
+		}
+	}
+
+	if config.Output.Language != "" && !generators.IsSupportedLanguage(config.Output.Language) {
+		return fmt.Errorf("unsupported output language '%s'. Valid options: %s", config.Output.Language, strings.Join(generators.SupportedLanguages(), ", "))
+	}
+
 	return nil
 }
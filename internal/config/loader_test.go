@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"sherpa/pkg/models"
 
@@ -69,6 +71,29 @@ processing:
 		assert.Contains(t, config.Processing.IncludeOnly, "*.go")
 	})
 
+	t.Run("should load the request settings of each platform", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yml")
+		require.NoError(t, os.WriteFile(configFile, []byte(`
+github:
+  max_concurrency: 4
+  retries: 3
+  backoff: 2s
+gitlab:
+  max_concurrency: 50
+`), 0644))
+
+		config, err := loader.LoadConfig(configFile)
+		require.NoError(t, err)
+		assert.Equal(t, models.RequestConfig{MaxConcurrency: 4, Retries: 3, Backoff: 2 * time.Second}, config.Requests(models.PlatformGitHub))
+		assert.Equal(t, "GITHUB_TOKEN", config.GitHub.TokenEnv)
+		assert.Equal(t, 50, config.ProcessingFor(models.PlatformGitLab).MaxConcurrency)
+		assert.Equal(t, 20, config.ProcessingFor(models.PlatformGitea).MaxConcurrency)
+		require.NoError(t, loader.ValidateConfig(config))
+
+		config.Gitea.Retries = -1
+		assert.ErrorContains(t, loader.ValidateConfig(config), "gitea max_concurrency, retries and backoff must not be negative")
+	})
+
 	t.Run("should error on invalid YAML", func(t *testing.T) {
 		// Create temporary config file with invalid YAML
 		tempFile, err := os.CreateTemp("", "test-config-*.yml")
@@ -116,6 +141,146 @@ func TestLoader_OverrideWithFlags(t *testing.T) {
 		assert.Equal(t, "https://custom.gitlab.com", config.GitLab.BaseURL)
 	})
 
+	t.Run("should apply the base URL to Gitea when it is the default platform", func(t *testing.T) {
+		config := &models.Config{}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{
+			BaseURL:         "https://git.company.com",
+			DefaultPlatform: "gitea",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "https://git.company.com", config.Gitea.BaseURL)
+		assert.Empty(t, config.GitLab.BaseURL)
+	})
+
+	t.Run("should add request headers to the configured ones", func(t *testing.T) {
+		config := &models.Config{
+			HTTP: models.HTTPConfig{Headers: map[string]string{"X-Team": "platform"}},
+		}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{
+			UserAgent: "sherpa-ci/1.0",
+			Headers:   []string{"X-Gateway-Route: internal", "X-Team:payments"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "sherpa-ci/1.0", config.HTTP.UserAgent)
+		assert.Equal(t, map[string]string{"X-Team": "payments", "X-Gateway-Route": "internal"}, config.HTTP.Headers)
+	})
+
+	t.Run("should switch to SI units", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{SizeUnits: models.SizeUnitsBinary},
+		}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{SI: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, models.SizeUnitsSI, config.Output.SizeUnits)
+	})
+
+	t.Run("should select issues", func(t *testing.T) {
+		config := &models.Config{
+			Processing: models.ProcessingConfig{Issues: models.IssuesConfig{State: models.IssueStateOpen, Limit: 20}},
+		}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{IncludeIssues: true, IssueState: "Closed", IssueLabels: []string{"bug"}, IssueLimit: 5})
+		require.NoError(t, err)
+
+		assert.Equal(t, models.IssuesConfig{Include: true, State: models.IssueStateClosed, Labels: []string{"bug"}, Limit: 5}, config.Processing.Issues)
+	})
+
+	t.Run("should select releases and the wiki", func(t *testing.T) {
+		config := &models.Config{
+			Processing: models.ProcessingConfig{Releases: models.ReleasesConfig{Limit: 10}},
+		}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{IncludeReleases: true, ReleaseLimit: 3, IncludeWiki: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, models.ReleasesConfig{Include: true, Limit: 3}, config.Processing.Releases)
+		assert.True(t, config.Processing.Wiki)
+	})
+
+	t.Run("should set the time budget", func(t *testing.T) {
+		config := &models.Config{}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{BudgetTime: 5 * time.Minute})
+		require.NoError(t, err)
+
+		assert.Equal(t, 5*time.Minute, config.Processing.BudgetTime)
+	})
+
+	t.Run("should set the artifacts and llms.txt", func(t *testing.T) {
+		config := &models.Config{}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{Artifacts: models.ArtifactsBoth, LLMsTxtLegacy: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, models.ArtifactsBoth, config.Output.Artifacts)
+		assert.True(t, config.Output.LLMsTxtLegacy)
+	})
+
+	t.Run("should write the lock file only when requested", func(t *testing.T) {
+		config := &models.Config{}
+		require.NoError(t, loader.OverrideWithFlags(config, &models.CLIOptions{}))
+		assert.Empty(t, config.Output.LockFile)
+
+		require.NoError(t, loader.OverrideWithFlags(config, &models.CLIOptions{Lock: true}))
+		assert.Equal(t, DefaultLockFile, config.Output.LockFile)
+
+		config = &models.Config{Output: models.OutputConfig{LockFile: "locks/prod.lock"}}
+		require.NoError(t, loader.OverrideWithFlags(config, &models.CLIOptions{Locked: true}))
+		assert.Equal(t, "locks/prod.lock", config.Output.LockFile)
+	})
+
+	t.Run("should set the sample", func(t *testing.T) {
+		config := &models.Config{}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{Sample: "10%"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "10%", config.Processing.Sample)
+	})
+
+	t.Run("should fit outputs for a downstream tool", func(t *testing.T) {
+		config := &models.Config{Output: models.OutputConfig{Format: models.FormatMarkdown}}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{FitFor: "Claude-Projects"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "30MB", config.Output.SplitSize)
+		assert.Equal(t, 180000, config.Output.MaxTokens)
+		assert.Equal(t, models.FormatText, config.Output.Format)
+
+		defaults := loader.getDefaultConfig()
+		require.NoError(t, loader.OverrideWithFlags(defaults, &models.CLIOptions{FitFor: "claude-projects"}))
+		assert.NoError(t, loader.ValidateConfig(defaults))
+	})
+
+	t.Run("should refine a fit preset with output flags", func(t *testing.T) {
+		config := &models.Config{}
+
+		err := loader.OverrideWithFlags(config, &models.CLIOptions{FitFor: "chatgpt-projects", SplitTokens: "500k"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "500k", config.Output.SplitTokens)
+		assert.Equal(t, "512MB", config.Output.SplitSize)
+		assert.Equal(t, 20, config.Output.MaxParts)
+	})
+
+	t.Run("should error on unknown fit presets", func(t *testing.T) {
+		err := loader.OverrideWithFlags(&models.Config{}, &models.CLIOptions{FitFor: "notepad"})
+		assert.ErrorContains(t, err, "Valid options: chatgpt-projects, claude-projects, continue, cursor")
+	})
+
+	t.Run("should error on headers without a value", func(t *testing.T) {
+		err := loader.OverrideWithFlags(&models.Config{}, &models.CLIOptions{Headers: []string{"X-Team"}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expected 'Name: value'")
+	})
+
 	t.Run("should not override empty CLI options", func(t *testing.T) {
 		config := &models.Config{
 			Output: models.OutputConfig{
@@ -187,4 +352,369 @@ func TestLoader_ValidateConfig(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid max_file_size")
 	})
+
+	t.Run("should error on invalid binary extension", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency:   1,
+				BinaryExtensions: []string{".png", "*.jpg"},
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid binary extension '*.jpg'")
+	})
+
+	t.Run("should error on invalid tree style", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				TreeStyle: "fancy",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid tree_style")
+	})
+
+	t.Run("should error on invalid fsync policy", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				Fsync:     "sometimes",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid fsync policy")
+	})
+
+	t.Run("should error on invalid size units", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				SizeUnits: "iec",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid size_units")
+	})
+
+	t.Run("should error on invalid issue states", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+				Issues:         models.IssuesConfig{Include: true, State: "opened"},
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "invalid issue state")
+	})
+
+	t.Run("should error on negative release limits", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+				Releases:       models.ReleasesConfig{Include: true, Limit: -1},
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "release limit must not be negative")
+	})
+
+	t.Run("should error on alt text without endpoint or model", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+				AltText:        models.AltTextConfig{Include: true, Model: "llava"},
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "processing.alt_text.endpoint")
+
+		config.Processing.AltText = models.AltTextConfig{Include: true, Endpoint: "http://localhost:11434/v1/chat/completions"}
+		err = loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "processing.alt_text.model")
+
+		config.Processing.AltText.Model = "llava"
+		assert.NoError(t, loader.ValidateConfig(config))
+	})
+
+	t.Run("should error on invalid artifacts", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				Artifacts: "all",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "invalid artifacts 'all'")
+	})
+
+	t.Run("should error on negative time budgets", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+				BudgetTime:     -time.Minute,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "budget_time must not be negative")
+	})
+
+	t.Run("should error on invalid samples", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+				Sample:         "0%",
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "invalid sample: percentage must be above 0% and up to 100%")
+	})
+
+	t.Run("should error on invalid diff ranges", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+				Diff:           "v1.2.0",
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "invalid diff range")
+
+		config.Processing.Diff = "v1.2.0..v1.3.0"
+		config.Processing.Match = "retry"
+		err = loader.ValidateConfig(config)
+		assert.ErrorContains(t, err, "diff and match cannot be combined")
+	})
+
+	t.Run("should error on invalid packing strategy", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				Packing:   "random",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid packing strategy")
+	})
+
+	t.Run("should error on deterministic outputs organized by date", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory:      "./valid-output",
+				OrganizeByDate: true,
+				Deterministic:  true,
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "organized by date")
+	})
+
+	t.Run("should validate the tokens of the name template", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory:    "./valid-output",
+				NameTemplate: "{repo}@{ref}_{sha}",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+		assert.NoError(t, loader.ValidateConfig(config))
+
+		config.Output.NameTemplate = "{repo}@{branch}"
+		assert.ErrorContains(t, loader.ValidateConfig(config), "unknown name token {branch}")
+
+		config.Output.NameTemplate = "{owner}_{sha}"
+		assert.ErrorContains(t, loader.ValidateConfig(config), "must include {repo} or {name}")
+	})
+
+	t.Run("should error on invalid output format", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				Format:    "html",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid output format")
+	})
+
+	t.Run("should error on negative max tokens", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				MaxTokens: -1,
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "max_tokens must not be negative")
+	})
+
+	t.Run("should error on invalid split tokens", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory:   "./valid-output",
+				SplitTokens: "lots",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid split_tokens")
+	})
+
+	t.Run("should error when splitting the markdown format", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				Format:    models.FormatMarkdown,
+				SplitSize: "2MB",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "only supported with the txt format")
+	})
+
+	t.Run("should error when splitting the repomix format", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory:   "./valid-output",
+				Format:      models.FormatRepomix,
+				SplitTokens: "100k",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "only supported with the txt format")
+	})
+
+	t.Run("should error on invalid header names", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+			HTTP: models.HTTPConfig{
+				Headers: map[string]string{"X Team": "platform"},
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid header name")
+	})
+
+	t.Run("should error on invalid repository tags", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+			Repos: []models.TaggedRepo{{Repo: "owner/api", Tags: []string{"team payments"}}},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "repository owner/api: invalid tag 'team payments'")
+	})
+
+	t.Run("should error on unsupported output language", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory: "./valid-output",
+				Language:  "de",
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency: 1,
+			},
+		}
+
+		err := loader.ValidateConfig(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported output language")
+	})
 }
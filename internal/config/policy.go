@@ -0,0 +1,237 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// DefaultPolicyTTL is how long a fetched policy is used when the configuration sets no ttl
+const DefaultPolicyTTL = time.Hour
+
+// policyFetchTimeout bounds the download of a policy and of its signature
+const policyFetchTimeout = 30 * time.Second
+
+// policySignatureSuffix is appended to the policy URL to fetch its detached signature
+const policySignatureSuffix = ".sig"
+
+// maxPolicySize bounds the download of a policy and of its signature
+const maxPolicySize = 1 << 20
+
+// policyRules holds the only settings a policy can set, the ignore rules and sensitive
+// patterns kept consistent across an organization. Connection settings like base URLs or
+// headers are left out, so a policy can never send tokens to another host.
+type policyRules struct {
+	Processing struct {
+		Ignore []string `yaml:"ignore"`
+	} `yaml:"processing"`
+	Sensitive struct {
+		Patterns []string `yaml:"patterns"`
+	} `yaml:"sensitive"`
+}
+
+// loadPolicy fetches and parses the policy named by the local configuration. It returns nil
+// when the local configuration names none.
+func (l *Loader) loadPolicy(local []byte) (*policyRules, error) {
+	var settings struct {
+		Policy models.PolicyConfig `yaml:"policy"`
+	}
+	if err := yaml.Unmarshal(local, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if settings.Policy.URL == "" {
+		return nil, nil
+	}
+
+	data, err := l.fetchPolicy(settings.Policy)
+	if err != nil {
+		return nil, err
+	}
+	var policy policyRules
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse policy %s, which can only set processing.ignore and sensitive.patterns: %w", settings.Policy.URL, err)
+	}
+	return &policy, nil
+}
+
+// enforcePolicy adds the ignore rules and sensitive patterns of the policy to the lists of
+// the local configuration, so they stay consistent across an organization
+func enforcePolicy(config *models.Config, policy *policyRules) {
+	if policy == nil {
+		return
+	}
+	config.Processing.Ignore = appendMissing(config.Processing.Ignore, policy.Processing.Ignore)
+	config.Sensitive.Patterns = appendMissing(config.Sensitive.Patterns, policy.Sensitive.Patterns)
+}
+
+// appendMissing appends the values of extra that list does not hold yet
+func appendMissing(list, extra []string) []string {
+	for _, value := range extra {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// fetchPolicy returns the content of a policy, from the cache while it is younger than its
+// ttl, or always with Offline. A policy that cannot be refreshed is served from the cache
+// when one was fetched before.
+func (l *Loader) fetchPolicy(policy models.PolicyConfig) ([]byte, error) {
+	parsed, err := url.Parse(policy.URL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid policy url '%s': expected an https URL", policy.URL)
+	}
+	if policy.PublicKey == "" {
+		return nil, fmt.Errorf("policy %s has no public_key: policies must be signed", policy.URL)
+	}
+	ttl := policy.TTL
+	if ttl <= 0 {
+		ttl = DefaultPolicyTTL
+	}
+
+	path := l.policyCachePath(policy.URL)
+	cached, cachedAt, cacheErr := readCachedPolicy(path, policy)
+	if cacheErr == nil && (l.Offline || time.Since(cachedAt) < ttl) {
+		return cached, nil
+	}
+	if l.Offline {
+		return nil, fmt.Errorf("policy %s was never fetched and cannot be with --offline", policy.URL)
+	}
+
+	data, signature, err := l.downloadPolicy(policy)
+	if err == nil {
+		err = verifyPolicy(policy, data, signature)
+	}
+	if err != nil {
+		if cacheErr == nil {
+			logger.Logger.WithError(err).WithField("url", policy.URL).Warn("Failed to refresh the policy, using the cached one")
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if err := writeCachedPolicy(path, data, signature); err != nil {
+		logger.Logger.WithError(err).WithField("url", policy.URL).Warn("Failed to cache the policy")
+	}
+	return data, nil
+}
+
+// downloadPolicy fetches a policy and its signature
+func (l *Loader) downloadPolicy(policy models.PolicyConfig) (data, signature []byte, err error) {
+	data, err = l.get(policy.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch policy: %w", err)
+	}
+	signature, err = l.get(policy.URL + policySignatureSuffix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch policy signature: %w", err)
+	}
+	return data, signature, nil
+}
+
+// get returns the body of a successful GET request, failing past maxPolicySize
+func (l *Loader) get(rawURL string) ([]byte, error) {
+	client := l.client
+	if client == nil {
+		client = &http.Client{Timeout: policyFetchTimeout}
+	}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPolicySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxPolicySize {
+		return nil, fmt.Errorf("GET %s: response larger than %d bytes", rawURL, maxPolicySize)
+	}
+	return body, nil
+}
+
+// verifyPolicy checks the base64 Ed25519 signature of a policy against its public key
+func verifyPolicy(policy models.PolicyConfig, data, signature []byte) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(policy.PublicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid policy public_key: expected a base64 Ed25519 public key")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(key), data, decoded) {
+		return fmt.Errorf("policy %s does not match its signature", policy.URL)
+	}
+	return nil
+}
+
+// policyCachePath returns where the policy fetched from rawURL is cached
+func (l *Loader) policyCachePath(rawURL string) string {
+	dir := l.cacheDir
+	if dir == "" {
+		if userDir, err := os.UserCacheDir(); err == nil {
+			dir = filepath.Join(userDir, "sherpa", "policies")
+		} else {
+			dir = filepath.Join(os.TempDir(), "sherpa-policies")
+		}
+	}
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".yml")
+}
+
+// readCachedPolicy reads a cached policy and when it was fetched. The signature is checked
+// again, so a cache written before a key rotation is not trusted.
+func readCachedPolicy(path string, policy models.PolicyConfig) ([]byte, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	signature, err := os.ReadFile(path + policySignatureSuffix)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if err := verifyPolicy(policy, data, signature); err != nil {
+		return nil, time.Time{}, err
+	}
+	return data, info.ModTime(), nil
+}
+
+// writeCachedPolicy caches a policy and its signature
+func writeCachedPolicy(path string, data, signature []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+policySignatureSuffix, signature, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
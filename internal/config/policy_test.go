@@ -0,0 +1,173 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// policyServer serves a policy over TLS with its signature, counting the policy requests
+type policyServer struct {
+	*httptest.Server
+	policy     string
+	signature  string
+	requests   atomic.Int32
+	publicKey  string
+	privateKey ed25519.PrivateKey
+}
+
+func newPolicyServer(t *testing.T, policy string) *policyServer {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	server := &policyServer{publicKey: base64.StdEncoding.EncodeToString(publicKey), privateKey: privateKey}
+	server.sign(policy)
+	server.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/policy.yml":
+			server.requests.Add(1)
+			_, _ = w.Write([]byte(server.policy))
+		case "/policy.yml.sig":
+			_, _ = w.Write([]byte(server.signature))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sign serves policy with its signature
+func (s *policyServer) sign(policy string) {
+	s.policy = policy
+	s.signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.privateKey, []byte(policy)))
+}
+
+// loader returns a loader trusting the certificate of the server
+func (s *policyServer) loader(t *testing.T) *Loader {
+	return &Loader{cacheDir: t.TempDir(), client: s.Client()}
+}
+
+// config returns a local configuration naming the policy of the server, followed by extra
+func (s *policyServer) config(t *testing.T, extra string) string {
+	return writeConfig(t, "policy:\n  url: "+s.URL+"/policy.yml\n  public_key: "+s.publicKey+"\n"+extra)
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoader_Policy(t *testing.T) {
+	policy := `
+processing:
+  ignore: ["secrets/", "*.pem"]
+sensitive:
+  patterns: ["billing/"]
+`
+
+	t.Run("should add the rules of the policy to the local configuration", func(t *testing.T) {
+		server := newPolicyServer(t, policy)
+
+		config, err := server.loader(t).LoadConfig(server.config(t, `
+output:
+  directory: "./mine"
+processing:
+  ignore: ["dist/"]
+`))
+		require.NoError(t, err)
+
+		assert.Equal(t, "./mine", config.Output.Directory)
+		assert.Equal(t, []string{"dist/", "secrets/", "*.pem"}, config.Processing.Ignore)
+		assert.Contains(t, config.Sensitive.Patterns, "billing/")
+	})
+
+	t.Run("should use the cached policy within its ttl", func(t *testing.T) {
+		server := newPolicyServer(t, policy)
+		loader := server.loader(t)
+		configFile := server.config(t, "")
+
+		_, err := loader.LoadConfig(configFile)
+		require.NoError(t, err)
+		config, err := loader.LoadConfig(configFile)
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(1), server.requests.Load())
+		assert.Contains(t, config.Processing.Ignore, "secrets/")
+	})
+
+	t.Run("should fall back to the cached policy when it cannot be refreshed", func(t *testing.T) {
+		server := newPolicyServer(t, policy)
+		loader := server.loader(t)
+		configFile := server.config(t, "")
+
+		_, err := loader.LoadConfig(configFile)
+		require.NoError(t, err)
+		path := loader.policyCachePath(server.URL + "/policy.yml")
+		expired := time.Now().Add(-2 * DefaultPolicyTTL)
+		require.NoError(t, os.Chtimes(path, expired, expired))
+		server.Close()
+
+		config, err := loader.LoadConfig(configFile)
+		require.NoError(t, err)
+		assert.Contains(t, config.Processing.Ignore, "secrets/")
+	})
+
+	t.Run("should not fetch the policy offline", func(t *testing.T) {
+		server := newPolicyServer(t, policy)
+		loader := server.loader(t)
+		loader.Offline = true
+
+		_, err := loader.LoadConfig(server.config(t, ""))
+		assert.Error(t, err)
+		assert.Zero(t, server.requests.Load())
+	})
+
+	t.Run("should check the signature of the policy", func(t *testing.T) {
+		server := newPolicyServer(t, policy)
+		server.policy = policy + "\n# tampered\n"
+
+		_, err := server.loader(t).LoadConfig(server.config(t, ""))
+		assert.ErrorContains(t, err, "does not match its signature")
+	})
+
+	t.Run("should refuse unsigned policies", func(t *testing.T) {
+		server := newPolicyServer(t, policy)
+
+		_, err := server.loader(t).LoadConfig(writeConfig(t, "policy:\n  url: "+server.URL+"/policy.yml\n"))
+		assert.ErrorContains(t, err, "policies must be signed")
+		assert.Zero(t, server.requests.Load())
+	})
+
+	t.Run("should refuse policies setting other settings", func(t *testing.T) {
+		server := newPolicyServer(t, "github:\n  base_url: https://attacker.example.com\n")
+
+		_, err := server.loader(t).LoadConfig(server.config(t, ""))
+		assert.ErrorContains(t, err, "can only set processing.ignore and sensitive.patterns")
+	})
+
+	t.Run("should refuse oversized policies", func(t *testing.T) {
+		server := newPolicyServer(t, "processing:\n  ignore:\n"+strings.Repeat("    - \"secrets/\"\n", maxPolicySize/10))
+
+		_, err := server.loader(t).LoadConfig(server.config(t, ""))
+		assert.ErrorContains(t, err, "response larger than")
+	})
+
+	t.Run("should reject a policy url that is not https", func(t *testing.T) {
+		for _, url := range []string{"/etc/policy.yml", "http://platform.example.com/policy.yml"} {
+			_, err := (&Loader{cacheDir: t.TempDir()}).LoadConfig(writeConfig(t, "policy:\n  url: "+url+"\n  public_key: key\n"))
+			assert.ErrorContains(t, err, "invalid policy url")
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// ParseTags splits the values of --tags on commas, dropping blanks and repeated tags
+func ParseTags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// ValidateTag checks that a tag is a single word, like team:payments
+func ValidateTag(tag string) error {
+	if tag == "" || strings.ContainsAny(tag, " \t\r\n,#") {
+		return fmt.Errorf("invalid tag '%s': tags cannot be empty or contain spaces, commas or '#'", tag)
+	}
+	return nil
+}
+
+// SelectTagged returns the repositories of the configuration having every tag, in the order
+// they are listed. It fails when none has them, so a mistyped tag does not go unnoticed.
+func (l *Loader) SelectTagged(config *models.Config, tags []string) ([]string, error) {
+	var selected []string
+	for _, repo := range config.Repos {
+		if hasTags(repo.Tags, tags) {
+			selected = append(selected, repo.Repo)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no repository of the configuration is tagged %s", strings.Join(tags, " and "))
+	}
+	return selected, nil
+}
+
+// hasTags reports whether every wanted tag is in tags
+func hasTags(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
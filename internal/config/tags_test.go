@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	assert.Equal(t, []string{"team:payments", "tier:critical"}, ParseTags([]string{"team:payments, tier:critical", "team:payments", " ,"}))
+	assert.Empty(t, ParseTags(nil))
+}
+
+func TestLoader_SelectTagged(t *testing.T) {
+	config := &models.Config{Repos: []models.TaggedRepo{
+		{Repo: "owner/api", Tags: []string{"team:payments", "tier:critical"}},
+		{Repo: "owner/web", Tags: []string{"team:payments"}},
+		{Repo: "pay", Tags: []string{"team:billing"}},
+	}}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{name: "should select the repositories with a tag", tags: []string{"team:payments"}, expected: []string{"owner/api", "owner/web"}},
+		{name: "should select the repositories with every tag", tags: []string{"team:payments", "tier:critical"}, expected: []string{"owner/api"}},
+		{name: "should keep aliases for the caller to resolve", tags: []string{"team:billing"}, expected: []string{"pay"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := NewLoader().SelectTagged(config, tt.tags)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, selected)
+		})
+	}
+
+	t.Run("should fail when no repository has the tags", func(t *testing.T) {
+		_, err := NewLoader().SelectTagged(config, []string{"team:billing", "tier:critical"})
+		assert.ErrorContains(t, err, "no repository of the configuration is tagged team:billing and tier:critical")
+	})
+}
+
+func TestValidateTag(t *testing.T) {
+	assert.NoError(t, ValidateTag("team:payments"))
+	for _, tag := range []string{"", "team payments", "a,b", "tier#1"} {
+		assert.Error(t, ValidateTag(tag), tag)
+	}
+}
@@ -0,0 +1,244 @@
+package fakevcs
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Repository is a repository served by the fake server
+type Repository struct {
+	Path          string            // owner/repo for GitHub, group/project for GitLab
+	Description   string            // Repository description returned by the API
+	DefaultBranch string            // Branch served when no ref is requested, main if empty
+	Files         map[string]string // File contents keyed by slash-separated path
+	Expect        []string          // Paths that must appear in the generated output
+	Archived      bool              // Reported when listing the repositories of an owner or group
+	Fork          bool
+	Private       bool
+	Topics        []string
+	PullRequests  []PullRequest // Pull requests served on GitHub, whose head is the repository itself
+}
+
+// PullRequest is a pull request of a fixture repository
+type PullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	Author string
+	Files  []PullRequestFile
+}
+
+// PullRequestFile is a file changed by a fixture pull request
+type PullRequestFile struct {
+	Path         string
+	PreviousPath string
+	Status       string
+	Additions    int
+	Deletions    int
+	Patch        string
+}
+
+// PullRequest returns the pull request with the given number
+func (r *Repository) PullRequest(number string) (*PullRequest, bool) {
+	for i := range r.PullRequests {
+		if fmt.Sprint(r.PullRequests[i].Number) == number {
+			return &r.PullRequests[i], true
+		}
+	}
+	return nil, false
+}
+
+// Fixtures is the set of repositories served by the fake server
+type Fixtures struct {
+	Repositories []Repository
+	Snippets     []Repository // Gists and snippets, with Path holding their id
+}
+
+// Branch returns the default branch of the repository
+func (r *Repository) Branch() string {
+	if r.DefaultBranch == "" {
+		return "main"
+	}
+	return r.DefaultBranch
+}
+
+// Commit returns a stable commit SHA derived from the repository contents
+func (r *Repository) Commit() string {
+	hash := sha1.New()
+	for _, path := range r.SortedPaths() {
+		fmt.Fprintf(hash, "%s\x00%s\x00", path, r.Files[path])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// SortedPaths returns the file paths of the repository in lexical order
+func (r *Repository) SortedPaths() []string {
+	paths := make([]string, 0, len(r.Files))
+	for path := range r.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Lookup returns the repository with the given path
+func (f *Fixtures) Lookup(path string) (*Repository, bool) {
+	for i := range f.Repositories {
+		if f.Repositories[i].Path == path {
+			return &f.Repositories[i], true
+		}
+	}
+	return nil, false
+}
+
+// InGroup returns the repositories directly under an owner or group, or also under its
+// subgroups when nested is set
+func (f *Fixtures) InGroup(group string, nested bool) []*Repository {
+	var repos []*Repository
+	for i := range f.Repositories {
+		rest, ok := strings.CutPrefix(f.Repositories[i].Path, group+"/")
+		if ok && (nested || !strings.Contains(rest, "/")) {
+			repos = append(repos, &f.Repositories[i])
+		}
+	}
+	return repos
+}
+
+// Visibility returns the visibility of the repository reported by the APIs
+func (r *Repository) Visibility() string {
+	if r.Private {
+		return "private"
+	}
+	return "public"
+}
+
+// LookupSnippet returns the gist or snippet with the given id
+func (f *Fixtures) LookupSnippet(id string) (*Repository, bool) {
+	for i := range f.Snippets {
+		if f.Snippets[i].Path == id {
+			return &f.Snippets[i], true
+		}
+	}
+	return nil, false
+}
+
+// DefaultFixtures returns the built-in repositories used by sherpa selftest
+func DefaultFixtures() *Fixtures {
+	return &Fixtures{
+		Repositories: []Repository{
+			{
+				Path:        "sherpa-fixtures/hello",
+				Description: "Small Go service used to validate providers end to end",
+				Files: map[string]string{
+					"README.md":                    "# hello\n\nGreets people over HTTP.\n",
+					"go.mod":                       "module example.com/hello\n\ngo 1.24\n",
+					"main.go":                      "package main\n\nimport \"example.com/hello/internal/greet\"\n\nfunc main() {\n\tprintln(greet.Hello(\"world\"))\n}\n",
+					"internal/greet/greet.go":      "package greet\n\n// Hello returns a greeting with retry-safe formatting\nfunc Hello(name string) string {\n\treturn \"Hello, \" + name\n}\n",
+					"internal/greet/greet_test.go": "package greet\n\nimport \"testing\"\n\nfunc TestHello(t *testing.T) {\n\tif Hello(\"a\") != \"Hello, a\" {\n\t\tt.Fail()\n\t}\n}\n",
+					"docs/usage.md":                "# Usage\n\nRun `go run .`\n",
+					"llms-annotations.yml":         "annotations:\n  - path: internal/greet\n    description: Greeting logic\n    read_first: true\n",
+				},
+				Expect: []string{
+					"README.md",
+					"main.go",
+					"internal/greet/greet.go",
+				},
+			},
+		},
+	}
+}
+
+// LoadFixtures reads repositories from a directory laid out as <owner>/<repo>/<files>
+func LoadFixtures(dir string) (*Fixtures, error) {
+	owners, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures directory: %w", err)
+	}
+
+	fixtures := &Fixtures{}
+	for _, owner := range owners {
+		if !owner.IsDir() {
+			continue
+		}
+
+		repos, err := os.ReadDir(filepath.Join(dir, owner.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixtures owner %s: %w", owner.Name(), err)
+		}
+
+		for _, repo := range repos {
+			if !repo.IsDir() {
+				continue
+			}
+
+			repository, err := loadRepository(filepath.Join(dir, owner.Name(), repo.Name()))
+			if err != nil {
+				return nil, err
+			}
+			repository.Path = owner.Name() + "/" + repo.Name()
+			fixtures.Repositories = append(fixtures.Repositories, repository)
+		}
+	}
+
+	if len(fixtures.Repositories) == 0 {
+		return nil, fmt.Errorf("no fixture repositories found in %s (expected <owner>/<repo> directories)", dir)
+	}
+	return fixtures, nil
+}
+
+// loadRepository reads every file below root into a fixture repository
+func loadRepository(root string) (Repository, error) {
+	repository := Repository{Files: make(map[string]string)}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		repository.Files[filepath.ToSlash(relPath)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return Repository{}, fmt.Errorf("failed to load fixture repository %s: %w", root, err)
+	}
+
+	return repository, nil
+}
+
+// matchesQuery reports whether content contains every term of a code search query.
+// The AND keyword and qualifiers such as repo:owner/name are ignored.
+func matchesQuery(content, query string) bool {
+	content = strings.ToLower(content)
+	matched := false
+	for _, term := range strings.Fields(query) {
+		term = strings.Trim(term, `"'`)
+		if term == "" || term == "AND" || strings.Contains(term, ":") {
+			continue
+		}
+		if !strings.Contains(content, strings.ToLower(term)) {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
@@ -0,0 +1,60 @@
+package fakevcs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	t.Run("should load owner/repo directories", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, writeFixture(dir, "acme/api/README.md", "# api"))
+		require.NoError(t, writeFixture(dir, "acme/api/cmd/main.go", "package main"))
+
+		fixtures, err := LoadFixtures(dir)
+		require.NoError(t, err)
+		require.Len(t, fixtures.Repositories, 1)
+
+		repo := fixtures.Repositories[0]
+		assert.Equal(t, "acme/api", repo.Path)
+		assert.Equal(t, []string{"README.md", "cmd/main.go"}, repo.SortedPaths())
+	})
+
+	t.Run("should error when no repositories exist", func(t *testing.T) {
+		_, err := LoadFixtures(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestMatchesQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		query    string
+		expected bool
+	}{
+		{name: "should match all terms", content: "payment with Retry", query: "payment AND retry", expected: true},
+		{name: "should require every term", content: "payment", query: "payment AND retry", expected: false},
+		{name: "should ignore qualifiers", content: "payment", query: "payment repo:acme/api", expected: true},
+		{name: "should not match empty queries", content: "payment", query: "repo:acme/api", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchesQuery(tt.content, tt.query))
+		})
+	}
+}
+
+// writeFixture creates a fixture file below dir
+func writeFixture(dir, relPath, content string) error {
+	path := filepath.Join(dir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,753 @@
+// Package fakevcs serves a fixtures-driven subset of the GitHub, GitLab and Gitea REST APIs,
+// and of the GitHub and GitLab GraphQL APIs, so providers and the pipeline can be exercised end to end
+// without real tokens.
+package fakevcs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Token is the only access token accepted by the fake server
+const Token = "sherpa-selftest-token"
+
+// API prefixes served for each platform
+const (
+	gitHubPrefix = "api/v3"
+	gitLabPrefix = "api/v4"
+	giteaPrefix  = "api/v1"
+)
+
+// graphQLPath is the GraphQL endpoint of GitHub Enterprise and GitLab, next to their REST APIs
+const graphQLPath = "/api/graphql"
+
+// Server is a fake GitHub, GitLab and Gitea API backed by fixture repositories
+type Server struct {
+	URL string
+
+	fixtures *Fixtures
+	server   *httptest.Server
+	requests int
+	mu       sync.Mutex
+}
+
+// NewServer starts a fake API server serving the given fixtures
+func NewServer(fixtures *Fixtures) *Server {
+	s := &Server{fixtures: fixtures}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// GitHubURL returns the base URL to configure for the GitHub provider
+func (s *Server) GitHubURL() string {
+	return s.URL + "/" + gitHubPrefix + "/"
+}
+
+// GitLabURL returns the base URL to configure for the GitLab provider
+func (s *Server) GitLabURL() string {
+	return s.URL
+}
+
+// GiteaURL returns the base URL to configure for the Gitea provider
+func (s *Server) GiteaURL() string {
+	return s.URL
+}
+
+// Requests returns the number of API requests served so far
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// handle authenticates the request and dispatches it to the platform handler
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+
+	if r.Method == http.MethodPost && r.URL.Path == graphQLPath {
+		// GitLab authenticates with a PRIVATE-TOKEN header, GitHub with a bearer token
+		if r.Header.Get("PRIVATE-TOKEN") != "" {
+			if r.Header.Get("PRIVATE-TOKEN") != Token {
+				writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+				return
+			}
+			s.gitLabGraphQL(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			writeError(w, http.StatusUnauthorized, "Bad credentials")
+			return
+		}
+		s.gitHubGraphQL(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	segments := splitPath(r.URL.EscapedPath())
+	if len(segments) < 2 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	prefix := segments[0] + "/" + segments[1]
+	switch prefix {
+	case gitHubPrefix:
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			writeError(w, http.StatusUnauthorized, "Bad credentials")
+			return
+		}
+		s.handleGitHub(w, r, segments[2:])
+	case gitLabPrefix:
+		if r.Header.Get("PRIVATE-TOKEN") != Token && r.Header.Get("Authorization") != "Bearer "+Token {
+			writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+			return
+		}
+		s.handleGitLab(w, r, segments[2:])
+	case giteaPrefix:
+		if r.Header.Get("Authorization") != "token "+Token {
+			writeError(w, http.StatusUnauthorized, "token is required")
+			return
+		}
+		s.handleGitea(w, r, segments[2:])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleGitHub serves the GitHub endpoints used by the GitHub client
+func (s *Server) handleGitHub(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 1 && segments[0] == "user":
+		writeJSON(w, map[string]interface{}{"id": 1, "login": "sherpa-selftest"})
+		return
+	case len(segments) == 2 && segments[0] == "search" && segments[1] == "code":
+		s.gitHubSearch(w, r.URL.Query().Get("q"))
+		return
+	case len(segments) == 2 && segments[0] == "gists":
+		s.gitHubGist(w, segments[1])
+		return
+	case len(segments) == 3 && (segments[0] == "orgs" || segments[0] == "users") && segments[2] == "repos":
+		s.listRepositories(w, segments[1], false, "Not Found", func(repo *Repository) map[string]interface{} {
+			return map[string]interface{}{
+				"full_name":      repo.Path,
+				"default_branch": repo.Branch(),
+				"archived":       repo.Archived,
+				"fork":           repo.Fork,
+				"private":        repo.Private,
+				"visibility":     repo.Visibility(),
+				"topics":         repo.Topics,
+			}
+		})
+		return
+	case len(segments) < 3 || segments[0] != "repos":
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	repo, ok := s.fixtures.Lookup(segments[1] + "/" + segments[2])
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	rest := segments[3:]
+	switch {
+	case len(rest) == 0:
+		writeJSON(w, map[string]interface{}{
+			"id":             1,
+			"name":           segments[2],
+			"full_name":      repo.Path,
+			"html_url":       s.URL + "/" + repo.Path,
+			"description":    repo.Description,
+			"default_branch": repo.Branch(),
+			"owner":          map[string]interface{}{"login": segments[1]},
+		})
+	case len(rest) >= 3 && rest[0] == "git" && rest[1] == "trees":
+		// Trees are named by a ref, or by ref:dir for the tree of a directory
+		ref, dir, _ := strings.Cut(strings.Join(rest[2:], "/"), ":")
+		dir = strings.Trim(dir, "/")
+		if !validRef(repo, ref) {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		recursive := r.URL.Query().Get("recursive") != ""
+		var entries []map[string]interface{}
+		for _, entryPath := range directories(repo) {
+			if rel, ok := treeEntry(entryPath, dir, recursive); ok {
+				entries = append(entries, map[string]interface{}{"path": rel, "mode": "040000", "type": "tree", "sha": blobSHA(entryPath)})
+			}
+		}
+		for _, filePath := range repo.SortedPaths() {
+			if rel, ok := treeEntry(filePath, dir, recursive); ok {
+				content := repo.Files[filePath]
+				entries = append(entries, map[string]interface{}{"path": rel, "mode": "100644", "type": "blob", "sha": blobSHA(content), "size": len(content)})
+			}
+		}
+		if dir != "" && len(entries) == 0 {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(w, map[string]interface{}{"sha": repo.Commit(), "tree": entries, "truncated": false})
+	case len(rest) >= 2 && rest[0] == "contents":
+		if !validRef(repo, r.URL.Query().Get("ref")) {
+			writeError(w, http.StatusNotFound, "No commit found for the ref")
+			return
+		}
+		filePath := strings.Join(rest[1:], "/")
+		content, ok := repo.Files[filePath]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"type":     "file",
+			"encoding": "base64",
+			"size":     len(content),
+			"name":     path.Base(filePath),
+			"path":     filePath,
+			"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+			"sha":      blobSHA(content),
+		})
+	case (len(rest) == 2 || len(rest) == 3 && rest[2] == "files") && rest[0] == "pulls":
+		s.gitHubPullRequest(w, repo, rest[1], len(rest) == 3)
+	case len(rest) >= 2 && rest[0] == "commits":
+		if !validRef(repo, strings.Join(rest[1:], "/")) {
+			writeError(w, http.StatusUnprocessableEntity, "No commit found for SHA")
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(repo.Commit()))
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// gitHubGraphQL answers the object expressions of a GraphQL query, which the GitHub client
+// passes as variables e0, e1, ... aliased f0, f1, ... in the repository. Other fields of the
+// query are not served.
+func (s *Server) gitHubGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	owner, _ := request.Variables["owner"].(string)
+	name, _ := request.Variables["name"].(string)
+	repo, ok := s.fixtures.Lookup(owner + "/" + name)
+	if !ok {
+		writeJSON(w, map[string]interface{}{
+			"data":   map[string]interface{}{"repository": nil},
+			"errors": []map[string]interface{}{{"type": "NOT_FOUND", "message": fmt.Sprintf("Could not resolve to a Repository with the name '%s/%s'.", owner, name)}},
+		})
+		return
+	}
+
+	objects := make(map[string]interface{})
+	for i := 0; ; i++ {
+		expression, ok := request.Variables[fmt.Sprintf("e%d", i)].(string)
+		if !ok {
+			break
+		}
+		alias := fmt.Sprintf("f%d", i)
+		objects[alias] = nil
+		ref, filePath, _ := strings.Cut(expression, ":")
+		content, exists := repo.Files[filePath]
+		if !exists || (ref != "HEAD" && !validRef(repo, ref)) {
+			continue
+		}
+		objects[alias] = map[string]interface{}{"text": content, "byteSize": len(content), "isBinary": false, "isTruncated": false}
+	}
+	writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"repository": objects}})
+}
+
+// gitHubPullRequest serves a pull request of a repository, or the files it changes
+func (s *Server) gitHubPullRequest(w http.ResponseWriter, repo *Repository, number string, files bool) {
+	pr, ok := repo.PullRequest(number)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if files {
+		changed := make([]map[string]interface{}, 0, len(pr.Files))
+		for _, file := range pr.Files {
+			changed = append(changed, map[string]interface{}{
+				"filename":          file.Path,
+				"previous_filename": file.PreviousPath,
+				"status":            file.Status,
+				"additions":         file.Additions,
+				"deletions":         file.Deletions,
+				"patch":             file.Patch,
+			})
+		}
+		writeJSON(w, changed)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"number":   pr.Number,
+		"title":    pr.Title,
+		"body":     pr.Body,
+		"html_url": fmt.Sprintf("%s/%s/pull/%d", s.URL, repo.Path, pr.Number),
+		"user":     map[string]interface{}{"login": pr.Author},
+		"base":     map[string]interface{}{"ref": repo.Branch()},
+		"head":     map[string]interface{}{"ref": fmt.Sprintf("pr-%d", pr.Number), "sha": repo.Commit()},
+	})
+}
+
+// gitHubSearch serves code search restricted by a repo: qualifier
+func (s *Server) gitHubSearch(w http.ResponseWriter, query string) {
+	var items []map[string]interface{}
+	for _, term := range strings.Fields(query) {
+		repoPath, ok := strings.CutPrefix(term, "repo:")
+		if !ok {
+			continue
+		}
+		if repo, found := s.fixtures.Lookup(repoPath); found {
+			for _, filePath := range searchFiles(repo, query) {
+				items = append(items, map[string]interface{}{"name": path.Base(filePath), "path": filePath, "sha": blobSHA(repo.Files[filePath])})
+			}
+		}
+	}
+	writeJSON(w, map[string]interface{}{"total_count": len(items), "incomplete_results": false, "items": items})
+}
+
+// gitHubGist serves a gist with the content of its files
+func (s *Server) gitHubGist(w http.ResponseWriter, id string) {
+	gist, ok := s.fixtures.LookupSnippet(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	files := make(map[string]interface{}, len(gist.Files))
+	for filename, content := range gist.Files {
+		files[filename] = map[string]interface{}{"filename": filename, "size": len(content), "content": content}
+	}
+	writeJSON(w, map[string]interface{}{
+		"id":          id,
+		"description": gist.Description,
+		"html_url":    s.URL + "/gist/" + id,
+		"owner":       map[string]interface{}{"login": "sherpa-selftest"},
+		"files":       files,
+	})
+}
+
+// gitLabGraphQL serves the blobs query of the GitLab GraphQL API, which the GitLab client
+// sends to look up the sizes of listed files. Sizes are BigInt values, serialized as strings.
+func (s *Server) gitLabGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Variables struct {
+			FullPath string   `json:"fullPath"`
+			Ref      string   `json:"ref"`
+			Paths    []string `json:"paths"`
+		} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	repo, ok := s.fixtures.Lookup(request.Variables.FullPath)
+	if !ok {
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"project": nil}})
+		return
+	}
+
+	nodes := []map[string]interface{}{}
+	if validRef(repo, request.Variables.Ref) {
+		for _, filePath := range request.Variables.Paths {
+			if content, exists := repo.Files[filePath]; exists {
+				nodes = append(nodes, map[string]interface{}{"path": filePath, "size": strconv.Itoa(len(content))})
+			}
+		}
+	}
+	writeJSON(w, map[string]interface{}{"data": map[string]interface{}{
+		"project": map[string]interface{}{"repository": map[string]interface{}{"blobs": map[string]interface{}{"nodes": nodes}}},
+	}})
+}
+
+// handleGitLab serves the GitLab endpoints used by the GitLab client
+func (s *Server) handleGitLab(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 1 && segments[0] == "user" {
+		writeJSON(w, map[string]interface{}{"id": 1, "username": "sherpa-selftest"})
+		return
+	}
+	if len(segments) >= 2 && segments[0] == "snippets" {
+		s.gitLabSnippet(w, segments[1], segments[2:])
+		return
+	}
+	if len(segments) == 3 && segments[0] == "groups" && segments[2] == "projects" {
+		nested := r.URL.Query().Get("include_subgroups") == "true"
+		s.listRepositories(w, segments[1], nested, "404 Group Not Found", func(repo *Repository) map[string]interface{} {
+			project := map[string]interface{}{
+				"path_with_namespace": repo.Path,
+				"default_branch":      repo.Branch(),
+				"archived":            repo.Archived,
+				"visibility":          repo.Visibility(),
+				"topics":              repo.Topics,
+			}
+			if repo.Fork {
+				project["forked_from_project"] = map[string]interface{}{"id": 2}
+			}
+			return project
+		})
+		return
+	}
+	if len(segments) < 2 || segments[0] != "projects" {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+
+	repo, ok := s.fixtures.Lookup(segments[1])
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+
+	query := r.URL.Query()
+	rest := segments[2:]
+	switch {
+	case len(rest) >= 2 && rest[0] == "snippets":
+		s.gitLabSnippet(w, rest[1], rest[2:])
+	case len(rest) == 0:
+		writeJSON(w, map[string]interface{}{
+			"id":                  1,
+			"name":                path.Base(repo.Path),
+			"path":                path.Base(repo.Path),
+			"path_with_namespace": repo.Path,
+			"web_url":             s.URL + "/" + repo.Path,
+			"description":         repo.Description,
+			"default_branch":      repo.Branch(),
+		})
+	case len(rest) == 2 && rest[0] == "-" && rest[1] == "search":
+		var blobs []map[string]interface{}
+		for _, filePath := range searchFiles(repo, query.Get("search")) {
+			blobs = append(blobs, map[string]interface{}{"path": filePath, "filename": filePath, "basename": path.Base(filePath), "ref": repo.Branch()})
+		}
+		writeJSON(w, nonNil(blobs))
+	case len(rest) == 2 && rest[0] == "repository" && rest[1] == "tree":
+		if !validRef(repo, query.Get("ref")) {
+			writeError(w, http.StatusNotFound, "404 Tree Not Found")
+			return
+		}
+		prefix := strings.Trim(query.Get("path"), "/")
+		recursive := query.Get("recursive") == "true"
+		var nodes []map[string]interface{}
+		for _, dir := range directories(repo) {
+			if _, ok := treeEntry(dir, prefix, recursive); ok {
+				nodes = append(nodes, map[string]interface{}{"id": blobSHA(dir), "name": path.Base(dir), "type": "tree", "path": dir, "mode": "040000"})
+			}
+		}
+		for _, filePath := range repo.SortedPaths() {
+			if _, ok := treeEntry(filePath, prefix, recursive); ok {
+				nodes = append(nodes, map[string]interface{}{"id": blobSHA(repo.Files[filePath]), "name": path.Base(filePath), "type": "blob", "path": filePath, "mode": "100644"})
+			}
+		}
+		if prefix != "" && len(nodes) == 0 {
+			writeError(w, http.StatusNotFound, "404 Tree Not Found")
+			return
+		}
+		writeJSON(w, nonNil(nodes))
+	case len(rest) == 4 && rest[0] == "repository" && rest[1] == "files" && rest[3] == "raw":
+		if !validRef(repo, query.Get("ref")) {
+			writeError(w, http.StatusNotFound, "404 Commit Not Found")
+			return
+		}
+		content, ok := repo.Files[rest[2]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "404 File Not Found")
+			return
+		}
+		_, _ = io.WriteString(w, content)
+	case len(rest) == 4 && rest[0] == "repository" && rest[1] == "blobs" && rest[3] == "raw":
+		for _, content := range repo.Files {
+			if blobSHA(content) == rest[2] {
+				_, _ = io.WriteString(w, content)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "404 Blob Not Found")
+	case len(rest) >= 3 && rest[0] == "repository" && rest[1] == "files":
+		if !validRef(repo, query.Get("ref")) {
+			writeError(w, http.StatusNotFound, "404 Commit Not Found")
+			return
+		}
+		filePath := strings.Join(rest[2:], "/")
+		content, ok := repo.Files[filePath]
+		if !ok {
+			writeError(w, http.StatusNotFound, "404 File Not Found")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"file_name": path.Base(filePath),
+			"file_path": filePath,
+			"size":      len(content),
+			"encoding":  "base64",
+			"content":   base64.StdEncoding.EncodeToString([]byte(content)),
+			"ref":       repo.Branch(),
+			"blob_id":   blobSHA(content),
+			"commit_id": repo.Commit(),
+		})
+	case len(rest) >= 3 && rest[0] == "repository" && rest[1] == "commits":
+		if !validRef(repo, strings.Join(rest[2:], "/")) {
+			writeError(w, http.StatusNotFound, "404 Commit Not Found")
+			return
+		}
+		commit := repo.Commit()
+		writeJSON(w, map[string]interface{}{"id": commit, "short_id": commit[:8], "title": "Fixture snapshot"})
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+// gitLabSnippet serves a snippet and the raw content of its files
+func (s *Server) gitLabSnippet(w http.ResponseWriter, id string, rest []string) {
+	snippet, ok := s.fixtures.LookupSnippet(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 Snippet Not Found")
+		return
+	}
+
+	switch {
+	case len(rest) == 0:
+		var files []map[string]interface{}
+		for _, filePath := range snippet.SortedPaths() {
+			files = append(files, map[string]interface{}{"path": filePath, "raw_url": s.URL + "/-/snippets/" + id + "/raw/" + snippet.Branch() + "/" + filePath})
+		}
+		writeJSON(w, map[string]interface{}{
+			"id":          1,
+			"title":       snippet.Description,
+			"description": snippet.Description,
+			"web_url":     s.URL + "/-/snippets/" + id,
+			"author":      map[string]interface{}{"username": "sherpa-selftest"},
+			"files":       nonNil(files),
+		})
+	case len(rest) == 4 && rest[0] == "files" && rest[3] == "raw":
+		content, ok := snippet.Files[rest[2]]
+		if !ok || rest[1] != snippet.Branch() {
+			writeError(w, http.StatusNotFound, "404 File Not Found")
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(content))
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+// handleGitea serves the Gitea endpoints used by the Gitea client
+func (s *Server) handleGitea(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 1 && segments[0] == "user" {
+		writeJSON(w, map[string]interface{}{"id": 1, "login": "sherpa-selftest"})
+		return
+	}
+	if len(segments) == 3 && (segments[0] == "orgs" || segments[0] == "users") && segments[2] == "repos" {
+		// Everything fits on the first page, later pages are empty
+		if page := r.URL.Query().Get("page"); page != "" && page != "1" {
+			writeJSON(w, []map[string]interface{}{})
+			return
+		}
+		s.listRepositories(w, segments[1], false, "The target couldn't be found.", func(repo *Repository) map[string]interface{} {
+			return map[string]interface{}{
+				"full_name":      repo.Path,
+				"default_branch": repo.Branch(),
+				"archived":       repo.Archived,
+				"fork":           repo.Fork,
+				"private":        repo.Private,
+				"topics":         repo.Topics,
+			}
+		})
+		return
+	}
+	if len(segments) < 3 || segments[0] != "repos" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	repo, ok := s.fixtures.Lookup(segments[1] + "/" + segments[2])
+	if !ok {
+		writeError(w, http.StatusNotFound, "The target couldn't be found.")
+		return
+	}
+
+	query := r.URL.Query()
+	rest := segments[3:]
+	switch {
+	case len(rest) == 0:
+		writeJSON(w, map[string]interface{}{
+			"id":             1,
+			"name":           segments[2],
+			"full_name":      repo.Path,
+			"html_url":       s.URL + "/" + repo.Path,
+			"description":    repo.Description,
+			"default_branch": repo.Branch(),
+		})
+	case len(rest) >= 3 && rest[0] == "git" && rest[1] == "trees":
+		if !validRef(repo, strings.Join(rest[2:], "/")) {
+			writeError(w, http.StatusNotFound, "sha not found")
+			return
+		}
+		var entries []map[string]interface{}
+		for _, dir := range directories(repo) {
+			entries = append(entries, map[string]interface{}{"path": dir, "mode": "040000", "type": "tree", "sha": blobSHA(dir)})
+		}
+		for _, filePath := range repo.SortedPaths() {
+			content := repo.Files[filePath]
+			entries = append(entries, map[string]interface{}{"path": filePath, "mode": "100644", "type": "blob", "sha": blobSHA(content), "size": len(content)})
+		}
+		writeJSON(w, map[string]interface{}{"sha": repo.Commit(), "tree": entries, "truncated": false, "page": 1, "total_count": len(entries)})
+	case len(rest) >= 2 && rest[0] == "contents":
+		if !validRef(repo, query.Get("ref")) {
+			writeError(w, http.StatusNotFound, "object does not exist")
+			return
+		}
+		filePath := strings.Join(rest[1:], "/")
+		content, ok := repo.Files[filePath]
+		if !ok {
+			writeError(w, http.StatusNotFound, "object does not exist")
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"type":     "file",
+			"encoding": "base64",
+			"size":     len(content),
+			"name":     path.Base(filePath),
+			"path":     filePath,
+			"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+			"sha":      blobSHA(content),
+		})
+	case len(rest) == 1 && rest[0] == "commits":
+		if !validRef(repo, query.Get("sha")) {
+			writeError(w, http.StatusNotFound, "object does not exist")
+			return
+		}
+		writeJSON(w, []map[string]interface{}{{"sha": repo.Commit()}})
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// validRef reports whether ref names the default branch or commit of the repository.
+// An empty ref selects the default branch.
+func validRef(repo *Repository, ref string) bool {
+	return ref == "" || ref == repo.Branch() || ref == repo.Commit()
+}
+
+// directories returns every directory containing a file of the repository
+func directories(repo *Repository) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, filePath := range repo.SortedPaths() {
+		for dir := path.Dir(filePath); dir != "."; dir = path.Dir(dir) {
+			if !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// searchFiles returns the paths of files matching a code search query
+func searchFiles(repo *Repository, query string) []string {
+	var matches []string
+	for _, filePath := range repo.SortedPaths() {
+		if matchesQuery(repo.Files[filePath], query) {
+			matches = append(matches, filePath)
+		}
+	}
+	return matches
+}
+
+// inPath reports whether p is below the directory prefix, or anywhere when prefix is empty
+func inPath(p, prefix string) bool {
+	return prefix == "" || strings.HasPrefix(p, prefix+"/")
+}
+
+// treeEntry returns the path of p relative to the tree of dir, and whether the tree lists
+// it: anywhere below dir when recursive, or directly in dir otherwise
+func treeEntry(p, dir string, recursive bool) (string, bool) {
+	if !inPath(p, dir) {
+		return "", false
+	}
+	rel := p
+	if dir != "" {
+		rel = strings.TrimPrefix(p, dir+"/")
+	}
+	return rel, recursive || !strings.Contains(rel, "/")
+}
+
+// blobSHA returns a git-style object id for content
+func blobSHA(content string) string {
+	return (&Repository{Files: map[string]string{"": content}}).Commit()
+}
+
+// splitPath splits an escaped URL path into unescaped segments
+func splitPath(escapedPath string) []string {
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(escapedPath, "/"), "/") {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// listRepositories writes the repositories of an owner or group in the platform format,
+// or a 404 when there are none
+func (s *Server) listRepositories(w http.ResponseWriter, group string, nested bool, notFound string, encode func(*Repository) map[string]interface{}) {
+	repos := s.fixtures.InGroup(group, nested)
+	if len(repos) == 0 {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	var items []map[string]interface{}
+	for _, repo := range repos {
+		items = append(items, encode(repo))
+	}
+	writeJSON(w, items)
+}
+
+// nonNil returns an empty list instead of nil so responses encode as []
+func nonNil(items []map[string]interface{}) []map[string]interface{} {
+	if items == nil {
+		return []map[string]interface{}{}
+	}
+	return items
+}
+
+// writeJSON encodes a successful JSON response
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError encodes an API error response with the given status
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,230 @@
+package fakevcs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"sherpa/internal/adapters"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Providers(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+
+	fixture := DefaultFixtures().Repositories[0]
+
+	gitHub, err := adapters.NewGitHubProvider(server.GitHubURL(), Token)
+	require.NoError(t, err)
+	gitLab, err := adapters.NewGitLabProvider(server.GitLabURL(), Token)
+	require.NoError(t, err)
+	gitea, err := adapters.NewGiteaProvider(server.GiteaURL(), Token)
+	require.NoError(t, err)
+
+	providers := map[string]adapters.Provider{"github": gitHub, "gitlab": gitLab, "gitea": gitea}
+
+	for name, provider := range providers {
+		t.Run("should authenticate with "+name, func(t *testing.T) {
+			assert.NoError(t, provider.TestConnection(context.Background()))
+		})
+
+		t.Run("should fetch repository info from "+name, func(t *testing.T) {
+			repo, err := provider.GetRepository(context.Background(), fixture.Path)
+			require.NoError(t, err)
+			assert.Equal(t, fixture.Path, repo.PathWithNamespace)
+			assert.Equal(t, fixture.Description, repo.Description)
+		})
+
+		t.Run("should list fixture files from "+name, func(t *testing.T) {
+			tree, err := provider.GetRepositoryTree(context.Background(), fixture.Path, "")
+			require.NoError(t, err)
+
+			var blobs []string
+			for _, entry := range tree {
+				if entry.Type == "blob" {
+					blobs = append(blobs, entry.Path)
+				}
+			}
+			assert.ElementsMatch(t, fixture.SortedPaths(), blobs)
+		})
+
+		t.Run("should fetch nested file content from "+name, func(t *testing.T) {
+			content, err := provider.GetFileContent(context.Background(), fixture.Path, "internal/greet/greet.go", "main")
+			require.NoError(t, err)
+			assert.Equal(t, fixture.Files["internal/greet/greet.go"], content)
+		})
+
+		t.Run("should resolve the default branch commit from "+name, func(t *testing.T) {
+			commit, err := provider.(adapters.CommitResolver).ResolveCommit(context.Background(), fixture.Path, "")
+			require.NoError(t, err)
+			assert.Equal(t, fixture.Commit(), commit)
+		})
+
+		t.Run("should search code on "+name, func(t *testing.T) {
+			searcher, ok := provider.(adapters.CodeSearcher)
+			if !ok {
+				t.Skip("code search is not supported by " + name)
+			}
+			paths, err := searcher.SearchCode(context.Background(), fixture.Path, "retry AND formatting", "")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"internal/greet/greet.go"}, paths)
+
+			paths, err = searcher.SearchCode(context.Background(), fixture.Path, "retry AND formatting", fixture.Branch())
+			require.NoError(t, err)
+			assert.Equal(t, []string{"internal/greet/greet.go"}, paths)
+		})
+
+		t.Run("should list the repositories of the fixture owner on "+name, func(t *testing.T) {
+			repos, err := provider.(adapters.RepositoryLister).ListRepositories(context.Background(), "sherpa-fixtures")
+			require.NoError(t, err)
+			require.Len(t, repos, 1)
+			assert.Equal(t, fixture.Path, repos[0].FullName)
+			assert.Equal(t, "public", repos[0].Visibility)
+		})
+
+		t.Run("should classify unknown repositories as not found on "+name, func(t *testing.T) {
+			_, err := provider.GetRepository(context.Background(), "missing/repo")
+			require.Error(t, err)
+			assert.ErrorIs(t, err, sherpaerrors.ErrNotFound)
+		})
+	}
+
+	t.Run("should reject invalid tokens", func(t *testing.T) {
+		provider, err := adapters.NewGitHubProvider(server.GitHubURL(), "wrong-token")
+		require.NoError(t, err)
+
+		err = provider.TestConnection(context.Background())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, sherpaerrors.ErrAuth)
+	})
+
+	t.Run("should reject invalid Gitea tokens", func(t *testing.T) {
+		provider, err := adapters.NewGiteaProvider(server.GiteaURL(), "wrong-token")
+		require.NoError(t, err)
+
+		err = provider.TestConnection(context.Background())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, sherpaerrors.ErrAuth)
+	})
+
+	t.Run("should count served requests", func(t *testing.T) {
+		assert.Greater(t, server.Requests(), 0)
+	})
+}
+
+func TestServer_GitHubGraphQL(t *testing.T) {
+	server := NewServer(DefaultFixtures())
+	defer server.Close()
+
+	fixture := DefaultFixtures().Repositories[0]
+	config := &models.Config{GitHub: models.GitHubConfig{BaseURL: server.GitHubURL(), API: models.GitHubAPIGraphQL}}
+	provider, err := adapters.CreateProvider(models.PlatformGitHub, config, Token)
+	require.NoError(t, err)
+
+	t.Run("should fetch files by batches with GraphQL", func(t *testing.T) {
+		before := server.Requests()
+		files, err := provider.GetMultipleFiles(context.Background(), fixture.Path, fixture.SortedPaths(), "main", 4, &models.ProcessingConfig{})
+		require.NoError(t, err)
+
+		require.Len(t, files, len(fixture.Files))
+		for _, file := range files {
+			require.NoError(t, file.Error)
+			assert.Equal(t, fixture.Files[file.Path], file.Content)
+			assert.True(t, file.IsText)
+		}
+		assert.Equal(t, 1, server.Requests()-before)
+	})
+
+	t.Run("should send one request per batch", func(t *testing.T) {
+		large := Repository{Path: "sherpa-fixtures/large", Files: map[string]string{}}
+		for i := range 120 {
+			large.Files[fmt.Sprintf("src/file%03d.go", i)] = fmt.Sprintf("package src // %d\n", i)
+		}
+		largeServer := NewServer(&Fixtures{Repositories: []Repository{large}})
+		defer largeServer.Close()
+		largeConfig := &models.Config{GitHub: models.GitHubConfig{BaseURL: largeServer.GitHubURL(), API: models.GitHubAPIGraphQL}}
+		largeProvider, err := adapters.CreateProvider(models.PlatformGitHub, largeConfig, Token)
+		require.NoError(t, err)
+
+		files, err := largeProvider.GetMultipleFiles(context.Background(), large.Path, large.SortedPaths(), "main", 4, &models.ProcessingConfig{})
+		require.NoError(t, err)
+
+		require.Len(t, files, len(large.Files))
+		for i, file := range files {
+			require.NoError(t, file.Error)
+			assert.Equal(t, large.SortedPaths()[i], file.Path)
+			assert.Equal(t, large.Files[file.Path], file.Content)
+		}
+		assert.Equal(t, 3, largeServer.Requests())
+	})
+
+	t.Run("should fetch files missing at the ref through the REST API", func(t *testing.T) {
+		files, err := provider.GetMultipleFiles(context.Background(), fixture.Path, []string{"missing.go"}, "main", 4, &models.ProcessingConfig{})
+		require.NoError(t, err)
+
+		require.Len(t, files, 1)
+		assert.ErrorIs(t, files[0].Error, sherpaerrors.ErrNotFound)
+	})
+
+	t.Run("should classify unknown repositories as not found", func(t *testing.T) {
+		files, err := provider.GetMultipleFiles(context.Background(), "missing/repo", []string{"main.go"}, "", 4, &models.ProcessingConfig{})
+		require.NoError(t, err)
+
+		require.Len(t, files, 1)
+		assert.ErrorIs(t, files[0].Error, sherpaerrors.ErrNotFound)
+	})
+}
+
+func TestServer_Snippets(t *testing.T) {
+	fixtures := DefaultFixtures()
+	fixtures.Snippets = []Repository{{
+		Path:        "42",
+		Description: "Deploy helpers",
+		Files:       map[string]string{"deploy.sh": "#!/bin/sh\nmake release\n", "notes.md": "# Notes\n"},
+	}}
+	server := NewServer(fixtures)
+	defer server.Close()
+
+	gists, err := adapters.NewGistProviderWithTransport(server.GitHubURL(), Token, nil)
+	require.NoError(t, err)
+	snippets, err := adapters.NewGitLabSnippetProviderWithTransport(server.GitLabURL(), Token, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		provider    adapters.Provider
+		snippetPath string
+	}{
+		{name: "gist", provider: gists, snippetPath: "sherpa-selftest/42"},
+		{name: "personal snippet", provider: snippets, snippetPath: "snippets/42"},
+		{name: "project snippet", provider: snippets, snippetPath: "sherpa-fixtures/hello/-/snippets/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run("should fetch the files of a "+tt.name, func(t *testing.T) {
+			repo, err := tt.provider.GetRepository(context.Background(), tt.snippetPath)
+			require.NoError(t, err)
+			assert.Equal(t, "Deploy helpers", repo.Description)
+
+			tree, err := tt.provider.GetRepositoryTree(context.Background(), tt.snippetPath, "")
+			require.NoError(t, err)
+			require.Len(t, tree, 2)
+			assert.Equal(t, "deploy.sh", tree[0].Path)
+
+			content, err := tt.provider.GetFileContent(context.Background(), tt.snippetPath, "deploy.sh", "")
+			require.NoError(t, err)
+			assert.Equal(t, "#!/bin/sh\nmake release\n", content)
+		})
+	}
+
+	t.Run("should classify unknown snippets as not found", func(t *testing.T) {
+		_, err := snippets.GetRepository(context.Background(), "snippets/7")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, sherpaerrors.ErrNotFound)
+	})
+}
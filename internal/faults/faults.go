@@ -0,0 +1,179 @@
+// Package faults injects random transport failures into provider HTTP traffic so
+// retry, resume and partial-failure reporting can be exercised under stress.
+package faults
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is a type of injected fault
+type Kind string
+
+// Injectable faults
+const (
+	KindTimeout     Kind = "timeout" // Request fails with a network timeout
+	KindRateLimited Kind = "429"     // Platform answers 429 Too Many Requests
+	KindServerError Kind = "500"     // Platform answers 500 Internal Server Error
+)
+
+// allKinds lists the faults injected when no kinds are configured
+var allKinds = []Kind{KindTimeout, KindRateLimited, KindServerError}
+
+// Injector decides which requests fail and how
+type Injector struct {
+	probability float64
+	seed        uint64
+	kinds       []Kind
+	rng         *rand.Rand
+	injected    map[Kind]int
+	mu          sync.Mutex
+}
+
+// Parse builds an injector from a spec like "p=0.05", "p=0.1,seed=42" or "p=0.2,kinds=429|500"
+func Parse(spec string) (*Injector, error) {
+	injector := &Injector{
+		seed:     uint64(time.Now().UnixNano()),
+		kinds:    allKinds,
+		injected: make(map[Kind]int),
+	}
+
+	hasProbability := false
+	for _, part := range strings.Split(spec, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid fault injection option %q (expected key=value)", part)
+		}
+
+		switch key {
+		case "p":
+			probability, err := strconv.ParseFloat(value, 64)
+			if err != nil || probability < 0 || probability > 1 {
+				return nil, fmt.Errorf("invalid fault probability %q (expected a number between 0 and 1)", value)
+			}
+			injector.probability = probability
+			hasProbability = true
+		case "seed":
+			seed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fault seed %q: %w", value, err)
+			}
+			injector.seed = seed
+		case "kinds":
+			var kinds []Kind
+			for _, name := range strings.Split(value, "|") {
+				kind := Kind(strings.TrimSpace(name))
+				if kind != KindTimeout && kind != KindRateLimited && kind != KindServerError {
+					return nil, fmt.Errorf("invalid fault kind %q (expected timeout, 429 or 500)", name)
+				}
+				kinds = append(kinds, kind)
+			}
+			injector.kinds = kinds
+		default:
+			return nil, fmt.Errorf("unknown fault injection option %q", key)
+		}
+	}
+
+	if !hasProbability {
+		return nil, fmt.Errorf("fault injection requires a probability, e.g. p=0.05")
+	}
+
+	injector.rng = rand.New(rand.NewPCG(injector.seed, injector.seed))
+	return injector, nil
+}
+
+// Seed returns the random seed, to reproduce a run
+func (i *Injector) Seed() uint64 {
+	return i.seed
+}
+
+// Injected returns the number of faults injected so far by kind
+func (i *Injector) Injected() map[Kind]int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	counts := make(map[Kind]int, len(i.injected))
+	for kind, count := range i.injected {
+		counts[kind] = count
+	}
+	return counts
+}
+
+// next returns the fault to inject into the next request, or "" to let it through
+func (i *Injector) next() Kind {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.rng.Float64() >= i.probability {
+		return ""
+	}
+	kind := i.kinds[i.rng.IntN(len(i.kinds))]
+	i.injected[kind]++
+	return kind
+}
+
+// Transport wraps base so requests randomly fail, using http.DefaultTransport when base is nil
+func (i *Injector) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{injector: i, base: base}
+}
+
+// transport is an http.RoundTripper injecting faults before reaching the platform
+type transport struct {
+	injector *Injector
+	base     http.RoundTripper
+}
+
+// RoundTrip sends the request or answers it with an injected fault
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch t.injector.next() {
+	case KindTimeout:
+		return nil, &timeoutError{url: req.URL.String()}
+	case KindRateLimited:
+		resp := faultResponse(req, http.StatusTooManyRequests, `{"message":"fault injection: rate limited"}`)
+		resp.Header.Set("Retry-After", "1")
+		return resp, nil
+	case KindServerError:
+		return faultResponse(req, http.StatusInternalServerError, `{"message":"fault injection: internal server error"}`), nil
+	default:
+		return t.base.RoundTrip(req)
+	}
+}
+
+// faultResponse builds a synthetic JSON response for req
+func faultResponse(req *http.Request, status int, body string) *http.Response {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// timeoutError is an injected network timeout, satisfying net.Error
+type timeoutError struct {
+	url string
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("fault injection: timeout requesting %s", e.url)
+}
+
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
@@ -0,0 +1,149 @@
+package faults
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/fakevcs"
+	sherpaerrors "sherpa/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		expectedError bool
+		probability   float64
+		kinds         []Kind
+	}{
+		{name: "should parse a probability", spec: "p=0.05", probability: 0.05, kinds: allKinds},
+		{name: "should parse seed and kinds", spec: "p=0.5,seed=42,kinds=429|500", probability: 0.5, kinds: []Kind{KindRateLimited, KindServerError}},
+		{name: "should require a probability", spec: "seed=42", expectedError: true},
+		{name: "should reject probabilities above 1", spec: "p=1.5", expectedError: true},
+		{name: "should reject unknown kinds", spec: "p=0.1,kinds=503", expectedError: true},
+		{name: "should reject unknown options", spec: "p=0.1,rate=3", expectedError: true},
+		{name: "should reject options without value", spec: "p", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector, err := Parse(tt.spec)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.probability, injector.probability)
+			assert.Equal(t, tt.kinds, injector.kinds)
+		})
+	}
+
+	t.Run("should use the given seed", func(t *testing.T) {
+		injector, err := Parse("p=0.1,seed=7")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), injector.Seed())
+	})
+}
+
+func TestInjector_Transport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	get := func(t *testing.T, spec string) (*http.Response, error) {
+		injector, err := Parse(spec)
+		require.NoError(t, err)
+		client := &http.Client{Transport: injector.Transport(nil)}
+		return client.Get(server.URL)
+	}
+
+	t.Run("should pass requests through with probability 0", func(t *testing.T) {
+		resp, err := get(t, "p=0")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should answer 429 with a retry hint", func(t *testing.T) {
+		resp, err := get(t, "p=1,kinds=429")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+	})
+
+	t.Run("should answer 500", func(t *testing.T) {
+		resp, err := get(t, "p=1,kinds=500")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+
+	t.Run("should fail with a network timeout", func(t *testing.T) {
+		_, err := get(t, "p=1,kinds=timeout")
+		require.Error(t, err)
+
+		var netErr net.Error
+		require.ErrorAs(t, err, &netErr)
+		assert.True(t, netErr.Timeout())
+	})
+
+	t.Run("should inject the same faults for the same seed", func(t *testing.T) {
+		sequence := func() []Kind {
+			injector, err := Parse("p=0.5,seed=42")
+			require.NoError(t, err)
+			var kinds []Kind
+			for range 20 {
+				kinds = append(kinds, injector.next())
+			}
+			return kinds
+		}
+		assert.Equal(t, sequence(), sequence())
+	})
+
+	t.Run("should count injected faults", func(t *testing.T) {
+		injector, err := Parse("p=1,kinds=500")
+		require.NoError(t, err)
+		client := &http.Client{Transport: injector.Transport(nil)}
+		for range 3 {
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+		assert.Equal(t, map[Kind]int{KindServerError: 3}, injector.Injected())
+	})
+}
+
+func TestInjector_Providers(t *testing.T) {
+	server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+	defer server.Close()
+
+	t.Run("should surface injected rate limits as classified provider errors", func(t *testing.T) {
+		injector, err := Parse("p=1,kinds=429")
+		require.NoError(t, err)
+
+		provider, err := adapters.NewGitHubProviderWithTransport(server.GitHubURL(), fakevcs.Token, injector.Transport(nil))
+		require.NoError(t, err)
+
+		err = provider.TestConnection(context.Background())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, sherpaerrors.ErrRateLimited)
+	})
+
+	t.Run("should reach the platform when no fault is drawn", func(t *testing.T) {
+		injector, err := Parse("p=0")
+		require.NoError(t, err)
+
+		provider, err := adapters.NewGitLabProviderWithTransport(server.GitLabURL(), fakevcs.Token, injector.Transport(nil))
+		require.NoError(t, err)
+		assert.NoError(t, provider.TestConnection(context.Background()))
+	})
+}
@@ -0,0 +1,89 @@
+// Package filelock serializes updates of files shared by concurrent sherpa processes, like
+// the files of the cache directory when CI runners execute several jobs in parallel.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lock is an exclusive advisory lock held on a lock file
+type Lock struct {
+	file *os.File
+}
+
+// Acquire blocks until it holds the exclusive lock of path, creating the lock file and
+// its directory when missing. Locks are released when the process exits, so a crashed
+// run never leaves the lock held.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory of %s: %w", path, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Release releases the lock
+func (l *Lock) Release() error {
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Update rewrites path with the content returned by update, which receives the current
+// content, nil when the file does not exist. Concurrent updates of the same path, from
+// this process or others, are serialized with the lock file path + ".lock", and the file
+// is replaced atomically, so readers never see a partially written file.
+func Update(path string, perm os.FileMode, update func(current []byte) ([]byte, error)) error {
+	lock, err := Acquire(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	data, err := update(current)
+	if err != nil {
+		return err
+	}
+	return WriteAtomic(path, data, perm)
+}
+
+// WriteAtomic writes data to a temporary file next to path and renames it over path
+func WriteAtomic(path string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := temp.Chmod(perm); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to set permissions of %s: %w", path, err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
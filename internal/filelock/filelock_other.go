@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package filelock
+
+import "os"
+
+// lockFile does nothing on platforms without file locks; files are still replaced atomically
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile does nothing on platforms without file locks
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	t.Run("should create the file and its directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cache", "counter")
+		require.NoError(t, Update(path, 0600, func(current []byte) ([]byte, error) {
+			assert.Nil(t, current)
+			return []byte("1"), nil
+		}))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "1", string(data))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("should serialize concurrent updates", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "counter")
+		increment := func(current []byte) ([]byte, error) {
+			count, _ := strconv.Atoi(string(current))
+			return []byte(strconv.Itoa(count + 1)), nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, Update(path, 0644, increment))
+			}()
+		}
+		wg.Wait()
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "20", string(data))
+	})
+
+	t.Run("should leave the file untouched when the update fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "counter")
+		require.NoError(t, os.WriteFile(path, []byte("1"), 0644))
+
+		err := Update(path, 0644, func(current []byte) ([]byte, error) {
+			return nil, assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "1", string(data))
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 2, "only the file and its lock should remain")
+	})
+}
@@ -0,0 +1,54 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// annotateTree attaches owner annotations to the tree nodes they describe
+func annotateTree(nodes []models.TreeNode, byPath map[string]models.Annotation) {
+	for i := range nodes {
+		if annotation, exists := byPath[nodes[i].Path]; exists {
+			nodes[i].Note = annotation.Description
+			nodes[i].ReadFirst = annotation.ReadFirst
+		}
+		annotateTree(nodes[i].Children, byPath)
+	}
+}
+
+// annotationsByPath indexes annotations by path
+func annotationsByPath(annotations []models.Annotation) map[string]models.Annotation {
+	byPath := make(map[string]models.Annotation, len(annotations))
+	for _, annotation := range annotations {
+		byPath[annotation.Path] = annotation
+	}
+	return byPath
+}
+
+// readFirstPatterns returns patterns matching the paths owners marked as read-first
+func readFirstPatterns(annotations []models.Annotation) []string {
+	var patterns []string
+	for _, annotation := range annotations {
+		if !annotation.ReadFirst {
+			continue
+		}
+		patterns = append(patterns, annotation.Path)
+		patterns = append(patterns, annotation.Path+"/")
+	}
+	return patterns
+}
+
+// annotationLabel renders the read-first marker and note appended to tree entries
+func (g *Generator) annotationLabel(node models.TreeNode) string {
+	var sb strings.Builder
+	if node.ReadFirst {
+		sb.WriteString(fmt.Sprintf(" [%s]", g.t(msgReadFirst)))
+	}
+	if node.Note != "" {
+		sb.WriteString(" — ")
+		sb.WriteString(node.Note)
+	}
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Annotations(t *testing.T) {
+	generator := NewGenerator(true)
+
+	result := &models.ProcessingResult{
+		Repository: models.Repository{Name: "test-repo"},
+		Files: []models.FileInfo{
+			{Path: "core", Name: "core", IsDir: true},
+			{Path: "core/engine.go", Name: "engine.go", Content: "package core", Size: 12, IsText: true},
+			{Path: "docs/ARCHITECTURE.md", Name: "ARCHITECTURE.md", Content: "# Architecture", Size: 14, IsText: true},
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+		},
+		Annotations: []models.Annotation{
+			{Path: "core", Description: "Domain logic"},
+			{Path: "docs/ARCHITECTURE.md", Description: "How the services fit together", ReadFirst: true},
+		},
+	}
+
+	output, err := generator.GenerateOutput(result)
+	require.NoError(t, err)
+
+	t.Run("should annotate tree entries", func(t *testing.T) {
+		text := generator.GenerateLLMsText(output)
+		assert.Contains(t, text, "core — Domain logic\n")
+		assert.Contains(t, text, "ARCHITECTURE.md [read first] — How the services fit together\n")
+	})
+
+	t.Run("should annotate file headings and list read-first files first", func(t *testing.T) {
+		text := generator.GenerateLLMsFullText(output)
+		assert.Contains(t, text, "### docs/ARCHITECTURE.md [read first]\n> How the services fit together\n\n")
+		assert.Less(t, strings.Index(text, "### docs/ARCHITECTURE.md"), strings.Index(text, "### main.go"))
+	})
+
+	t.Run("should leave unannotated entries unchanged", func(t *testing.T) {
+		text := generator.GenerateLLMsText(output)
+		assert.Contains(t, text, "main.go\n")
+		assert.NotContains(t, text, "main.go —")
+	})
+}
@@ -0,0 +1,175 @@
+package generators
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// changelogListLimit caps each list of files in a changelog, so notifications stay short
+const changelogListLimit = 50
+
+// GenerateChangelog synthesizes what changed between two tree.json snapshots of a
+// repository as Markdown: totals, new directories and the files added, removed or changed.
+func (g *Generator) GenerateChangelog(before, after *TreeDocument) string {
+	oldFiles, oldDirs := flattenTree(before.Tree)
+	newFiles, newDirs := flattenTree(after.Tree)
+
+	var added, removed, changed []TreeEntry
+	for filePath, entry := range newFiles {
+		previous, exists := oldFiles[filePath]
+		if !exists {
+			added = append(added, entry)
+		} else if previous.Size != entry.Size || previous.Tokens != entry.Tokens {
+			changed = append(changed, entry)
+		}
+	}
+	for filePath, entry := range oldFiles {
+		if _, exists := newFiles[filePath]; !exists {
+			removed = append(removed, entry)
+		}
+	}
+
+	// Directories are only listed when their parent existed before
+	var directories []TreeEntry
+	for dirPath, entry := range newDirs {
+		if _, exists := oldDirs[dirPath]; exists {
+			continue
+		}
+		parent := path.Dir(dirPath)
+		_, parentIsNew := newDirs[parent]
+		_, parentExisted := oldDirs[parent]
+		if parentIsNew && !parentExisted {
+			continue
+		}
+		directories = append(directories, entry)
+	}
+
+	sortByPath := func(entries []TreeEntry) {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	}
+	sortByPath(added)
+	sortByPath(removed)
+	sortByPath(directories)
+	// Changed files are listed by the size of their change, largest first
+	sort.Slice(changed, func(i, j int) bool {
+		di, dj := tokenChange(oldFiles, changed[i]), tokenChange(oldFiles, changed[j])
+		if di != dj {
+			return di > dj
+		}
+		return changed[i].Path < changed[j].Path
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s: %s\n\n", g.t(msgChangelog), after.Repository))
+	if from, to := snapshotLabel(before), snapshotLabel(after); from != "" && to != "" {
+		sb.WriteString(fmt.Sprintf("%s -> %s\n\n", from, to))
+	}
+
+	oldTokens, newTokens := totalTreeTokens(oldFiles), totalTreeTokens(newFiles)
+	sb.WriteString(fmt.Sprintf("| | %s | %s | %s |\n", g.t(msgBefore), g.t(msgAfter), g.t(msgDelta)))
+	sb.WriteString("|---|---:|---:|---:|\n")
+	sb.WriteString(fmt.Sprintf("| %s | %d | %d | %+d |\n", g.t(msgFiles), len(oldFiles), len(newFiles), len(newFiles)-len(oldFiles)))
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", g.t(msgSize), formatBytes(before.TotalSize), formatBytes(after.TotalSize), signedBytes(after.TotalSize-before.TotalSize)))
+	sb.WriteString(fmt.Sprintf("| %s | %d | %d | %+d |\n\n", g.t(msgTokens), oldTokens, newTokens, newTokens-oldTokens))
+
+	if len(added) == 0 && len(removed) == 0 && len(changed) == 0 {
+		sb.WriteString(g.t(msgNoChanges) + "\n")
+		return sb.String()
+	}
+
+	if len(directories) > 0 {
+		sb.WriteString(fmt.Sprintf("## %s (%d)\n\n", g.t(msgNewDirectories), len(directories)))
+		g.writeChangelogList(&sb, directories, func(entry TreeEntry) string {
+			return fmt.Sprintf("`%s/` (%s, %s)", entry.Path, g.t(msgFileCount, entry.FileCount), g.t(msgTokenCount, entry.Tokens))
+		})
+	}
+	if len(added) > 0 {
+		sb.WriteString(fmt.Sprintf("## %s (%d)\n\n", g.t(msgAddedFiles), len(added)))
+		g.writeChangelogList(&sb, added, func(entry TreeEntry) string {
+			return fmt.Sprintf("`%s` (%s, %s)", entry.Path, formatBytes(entry.Size), g.t(msgTokenCount, entry.Tokens))
+		})
+	}
+	if len(removed) > 0 {
+		sb.WriteString(fmt.Sprintf("## %s (%d)\n\n", g.t(msgRemovedFiles), len(removed)))
+		g.writeChangelogList(&sb, removed, func(entry TreeEntry) string {
+			return fmt.Sprintf("`%s` (%s, %s)", entry.Path, formatBytes(entry.Size), g.t(msgTokenCount, entry.Tokens))
+		})
+	}
+	if len(changed) > 0 {
+		sb.WriteString(fmt.Sprintf("## %s (%d)\n\n", g.t(msgChangedFiles), len(changed)))
+		g.writeChangelogList(&sb, changed, func(entry TreeEntry) string {
+			previous := oldFiles[entry.Path]
+			return fmt.Sprintf("`%s`: %s -> %s (%s)", entry.Path, formatBytes(previous.Size), formatBytes(entry.Size), g.t(msgTokenChange, entry.Tokens-previous.Tokens))
+		})
+	}
+	return sb.String()
+}
+
+// snapshotLabel identifies a snapshot by its generation time, or by its commit when it was
+// generated without one
+func snapshotLabel(document *TreeDocument) string {
+	if !document.GeneratedAt.IsZero() {
+		return document.GeneratedAt.Format(time.RFC3339)
+	}
+	return document.Commit
+}
+
+// writeChangelogList writes entries as a bulleted list, up to changelogListLimit of them
+func (g *Generator) writeChangelogList(sb *strings.Builder, entries []TreeEntry, line func(TreeEntry) string) {
+	for i, entry := range entries {
+		if i == changelogListLimit {
+			sb.WriteString(fmt.Sprintf("- %s\n", g.t(msgMoreEntries, len(entries)-changelogListLimit)))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- %s\n", line(entry)))
+	}
+	sb.WriteString("\n")
+}
+
+// flattenTree indexes the files and directories of a tree by path
+func flattenTree(entries []TreeEntry) (files, dirs map[string]TreeEntry) {
+	files = make(map[string]TreeEntry)
+	dirs = make(map[string]TreeEntry)
+	var walk func([]TreeEntry)
+	walk = func(entries []TreeEntry) {
+		for _, entry := range entries {
+			if entry.Type == TreeEntryDir {
+				dirs[entry.Path] = entry
+				walk(entry.Children)
+				continue
+			}
+			files[entry.Path] = entry
+		}
+	}
+	walk(entries)
+	return files, dirs
+}
+
+// tokenChange returns how many tokens a file gained or lost, as an absolute value
+func tokenChange(before map[string]TreeEntry, entry TreeEntry) int {
+	change := entry.Tokens - before[entry.Path].Tokens
+	if change < 0 {
+		return -change
+	}
+	return change
+}
+
+// totalTreeTokens sums the estimated tokens of files
+func totalTreeTokens(files map[string]TreeEntry) int {
+	total := 0
+	for _, entry := range files {
+		total += entry.Tokens
+	}
+	return total
+}
+
+// signedBytes formats a size difference with its sign, like +1.5 KB
+func signedBytes(delta int64) string {
+	if delta < 0 {
+		return "-" + formatBytes(-delta)
+	}
+	return "+" + formatBytes(delta)
+}
@@ -0,0 +1,72 @@
+package generators
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_GenerateChangelog(t *testing.T) {
+	file := func(path string, size int64, tokens int) TreeEntry {
+		return TreeEntry{Path: path, Type: TreeEntryFile, Size: size, Tokens: tokens}
+	}
+	dir := func(path string, files int, tokens int, children ...TreeEntry) TreeEntry {
+		return TreeEntry{Path: path, Type: TreeEntryDir, FileCount: files, Tokens: tokens, Children: children}
+	}
+
+	before := &TreeDocument{
+		Repository:  "owner/repo",
+		GeneratedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		TotalSize:   3000,
+		Tree: []TreeEntry{
+			dir("cmd", 1, 100, file("cmd/main.go", 1000, 100)),
+			file("legacy.go", 1000, 200),
+			file("README.md", 1000, 50),
+		},
+	}
+	after := &TreeDocument{
+		Repository:  "owner/repo",
+		GeneratedAt: time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC),
+		TotalSize:   4500,
+		Tree: []TreeEntry{
+			dir("cmd", 1, 150, file("cmd/main.go", 1500, 150)),
+			dir("services", 2, 300,
+				dir("services/billing", 2, 300, file("services/billing/api.go", 1000, 200), file("services/billing/db.go", 1000, 100)),
+			),
+			file("README.md", 1000, 50),
+		},
+	}
+
+	t.Run("should summarize totals, new directories and file changes", func(t *testing.T) {
+		changelog := NewGenerator(true).GenerateChangelog(before, after)
+
+		assert.Contains(t, changelog, "# Changes: owner/repo\n\n2024-03-01T12:00:00Z -> 2024-03-08T12:00:00Z\n")
+		assert.Contains(t, changelog, "| Files | 3 | 4 | +1 |\n")
+		assert.Contains(t, changelog, "| Size | 2.9 KB | 4.4 KB | +1.5 KB |\n")
+		assert.Contains(t, changelog, "| Tokens | 350 | 500 | +150 |\n")
+		assert.Contains(t, changelog, "## New Directories (1)\n\n- `services/` (2 files, ~300 tokens)\n\n")
+		assert.Contains(t, changelog, "## Added Files (2)\n\n- `services/billing/api.go` (1000 B, ~200 tokens)\n- `services/billing/db.go` (1000 B, ~100 tokens)\n\n")
+		assert.Contains(t, changelog, "## Removed Files (1)\n\n- `legacy.go` (1000 B, ~200 tokens)\n\n")
+		assert.Contains(t, changelog, "## Changed Files (1)\n\n- `cmd/main.go`: 1000 B -> 1.5 KB (+50 tokens)\n")
+		assert.NotContains(t, changelog, "README.md")
+	})
+
+	t.Run("should report snapshots without changes", func(t *testing.T) {
+		changelog := NewGenerator(true).GenerateChangelog(before, before)
+		assert.Contains(t, changelog, "No files changed between the snapshots.\n")
+		assert.NotContains(t, changelog, "## ")
+	})
+
+	t.Run("should cap long lists", func(t *testing.T) {
+		many := &TreeDocument{Repository: "owner/repo"}
+		for i := 0; i < changelogListLimit+5; i++ {
+			many.Tree = append(many.Tree, file(fmt.Sprintf("file%03d.go", i), 10, 1))
+		}
+		changelog := NewGenerator(true).GenerateChangelog(&TreeDocument{Repository: "owner/repo"}, many)
+		assert.Contains(t, changelog, "- `file049.go`")
+		assert.NotContains(t, changelog, "- `file050.go`")
+		assert.Contains(t, changelog, "- ... and 5 more\n")
+	})
+}
@@ -0,0 +1,96 @@
+package generators
+
+import (
+	"path/filepath"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// File classification tags rendered in file headings
+const (
+	TagTest       = "test"
+	TagConfig     = "config"
+	TagGenerated  = "generated"
+	TagDoc        = "doc"
+	TagEntrypoint = "entrypoint"
+)
+
+// generatedMarkers are header comments conventionally used by code generators
+var generatedMarkers = []string{
+	"code generated",
+	"@generated",
+	"do not edit",
+	"autogenerated",
+	"auto-generated",
+}
+
+// ClassifyFile returns the classification tags for a file, in a stable order. The categories
+// are the ones ranking files by priority, so tags match the order files are fetched and packed.
+func ClassifyFile(file models.FileInfo) []string {
+	var tags []string
+
+	if utils.IsEntrypointFile(file.Path) {
+		tags = append(tags, TagEntrypoint)
+	}
+	if utils.IsConfigFile(file.Path) {
+		tags = append(tags, TagConfig)
+	}
+	if utils.IsDocFile(file.Path) {
+		tags = append(tags, TagDoc)
+	}
+	if utils.IsTestFile(file.Path) {
+		tags = append(tags, TagTest)
+	}
+	if isGeneratedFile(file.Path, file.Content) {
+		tags = append(tags, TagGenerated)
+	}
+
+	return tags
+}
+
+// formatTags renders tags as a heading suffix like " [test] [config]"
+func formatTags(tags []string) string {
+	var sb strings.Builder
+	for _, tag := range tags {
+		sb.WriteString(" [")
+		sb.WriteString(tag)
+		sb.WriteString("]")
+	}
+	return sb.String()
+}
+
+// isGeneratedFile reports whether a file was written by a tool: generated code, minified
+// assets and lock files, or any file whose header carries a generator marker
+func isGeneratedFile(path, content string) bool {
+	fileName := strings.ToLower(filepath.Base(path))
+
+	generatedSuffixes := []string{".pb.go", ".pb.gw.go", "_generated.go", ".gen.go", ".min.js", ".min.css", "_pb2.py"}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(fileName, suffix) {
+			return true
+		}
+	}
+
+	lockFiles := []string{"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "cargo.lock", "poetry.lock", "composer.lock", "gemfile.lock"}
+	for _, name := range lockFiles {
+		if fileName == name {
+			return true
+		}
+	}
+
+	// Only inspect the first lines, where generators place their marker
+	head := content
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	head = strings.ToLower(head)
+	for _, marker := range generatedMarkers {
+		if strings.Contains(head, marker) {
+			return true
+		}
+	}
+
+	return false
+}
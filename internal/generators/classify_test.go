@@ -0,0 +1,89 @@
+package generators
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     models.FileInfo
+		expected []string
+	}{
+		{
+			name:     "should tag go entrypoint",
+			file:     models.FileInfo{Path: "cmd/server/main.go", Content: "package main"},
+			expected: []string{TagEntrypoint},
+		},
+		{
+			name:     "should tag go test file",
+			file:     models.FileInfo{Path: "pkg/utils/files_test.go"},
+			expected: []string{TagTest},
+		},
+		{
+			name:     "should tag files in test directories",
+			file:     models.FileInfo{Path: "tests/helpers.py"},
+			expected: []string{TagTest},
+		},
+		{
+			name:     "should tag configuration files",
+			file:     models.FileInfo{Path: "deploy/values.yaml"},
+			expected: []string{TagConfig},
+		},
+		{
+			name:     "should tag documentation",
+			file:     models.FileInfo{Path: "README.md"},
+			expected: []string{TagDoc},
+		},
+		{
+			name:     "should tag generated code from header marker",
+			file:     models.FileInfo{Path: "api/client.go", Content: "// Code generated by mockgen. DO NOT EDIT.\npackage api"},
+			expected: []string{TagGenerated},
+		},
+		{
+			name:     "should tag lock files as generated config",
+			file:     models.FileInfo{Path: "package-lock.json"},
+			expected: []string{TagConfig, TagGenerated},
+		},
+		{
+			name:     "should return no tags for plain source",
+			file:     models.FileInfo{Path: "internal/service.go", Content: "package internal"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyFile(tt.file))
+		})
+	}
+}
+
+func TestGenerator_FileTags(t *testing.T) {
+	output := &models.LLMsOutput{
+		Repository: models.Repository{Name: "test-repo"},
+		FileContents: []models.FileInfo{
+			{Path: "main.go", Content: "package main", Size: 12, IsText: true},
+			{Path: "main_test.go", Content: "package main", Size: 12, IsText: true},
+		},
+	}
+
+	t.Run("should tag file headings when enabled", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{FileTags: true})
+		text := generator.GenerateLLMsFullText(output)
+
+		assert.Contains(t, text, "### main.go [entrypoint]\n")
+		assert.Contains(t, text, "### main_test.go [test]\n")
+	})
+
+	t.Run("should not tag file headings by default", func(t *testing.T) {
+		text := NewGenerator(true).GenerateLLMsFullText(output)
+
+		assert.Contains(t, text, "### main.go\n")
+		assert.NotContains(t, text, "[entrypoint]")
+	})
+}
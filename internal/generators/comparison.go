@@ -0,0 +1,29 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// GenerateComparison generates llms-diff.txt for the changes between two refs of the
+// repository at repoPath, with the diff of every changed file. The content of the changed
+// files is left to llms-full.txt.
+func (g *Generator) GenerateComparison(repoPath string, comparison *models.Comparison) string {
+	var body strings.Builder
+	g.writeChanges(&body, comparison.Files, nil, 0)
+	additions, deletions := changeTotals(comparison.Files)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s: %s %s..%s\n", g.t(msgComparison), repoPath, comparison.Base, comparison.Head))
+	if label, value := g.generated(g.clock.Now(), ""); label != "" {
+		sb.WriteString(fmt.Sprintf("# %s: %s\n", label, value))
+	}
+	sb.WriteString(fmt.Sprintf("# %s: %d\n", g.t(msgCommits), comparison.Commits))
+	sb.WriteString(fmt.Sprintf("# %s: %d (+%d -%d)\n", g.t(msgChangedFiles), len(comparison.Files), additions, deletions))
+	sb.WriteString(fmt.Sprintf("# %s: %d\n\n", g.t(msgEstimatedTokens), utils.EstimateTokens(body.String())))
+	sb.WriteString(body.String())
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package generators
+
+import (
+	"testing"
+	"time"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_GenerateComparison(t *testing.T) {
+	comparison := &models.Comparison{
+		Base:    "v1.2.0",
+		Head:    "v1.3.0",
+		Commits: 4,
+		Files: []models.ChangedFile{
+			{Path: "retry.go", Status: "added", Additions: 2, Patch: "@@ -0,0 +1,2 @@\n+package upload\n+"},
+			{Path: "upload.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -2,1 +2,1 @@\n-\treturn send()\n+\treturn retry(send)\n"},
+		},
+	}
+
+	generator := NewGenerator(true)
+	generator.SetClock(utils.FixedClock{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)})
+	text := generator.GenerateComparison("owner/repo", comparison)
+
+	assert.Contains(t, text, "# Comparison: owner/repo v1.2.0..v1.3.0\n# Generated: 2026-03-01T12:00:00Z\n# Commits: 4\n# Changed Files: 2 (+3 -1)\n")
+	assert.Contains(t, text, "- added: retry.go (+2 -0)\n- modified: upload.go (+1 -1)\n")
+	assert.Contains(t, text, "### upload.go (modified, +1 -1)\n```diff\n@@ -2,1 +2,1 @@\n-\treturn send()\n+\treturn retry(send)\n```\n")
+	assert.NotContains(t, text, "Context around the changes")
+}
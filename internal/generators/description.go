@@ -0,0 +1,95 @@
+package generators
+
+import (
+	"regexp"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// maxDescriptionLength bounds descriptions taken from a README
+const maxDescriptionLength = 300
+
+var (
+	// markdownLinkPattern matches inline links and images, keeping their text
+	markdownLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	// underlinePattern matches setext and reStructuredText heading underlines
+	underlinePattern = regexp.MustCompile(`^[=\-~^*#]{3,}$`)
+)
+
+// readmeDescription returns the first prose paragraph of the root README, skipping
+// headings, badges and HTML, or an empty string when there is none
+func readmeDescription(files []models.FileInfo) string {
+	readme, found := rootReadme(files)
+	if !found {
+		return ""
+	}
+
+	var paragraph []string
+	for _, line := range strings.Split(readme.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if underlinePattern.MatchString(line) && len(paragraph) == 1 {
+			// The previous line was a setext heading
+			paragraph = nil
+			continue
+		}
+		if isDecorationLine(line) {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+
+	description := strings.Join(paragraph, " ")
+	description = strings.TrimSpace(markdownLinkPattern.ReplaceAllString(description, "$1"))
+	if len(description) > maxDescriptionLength {
+		cut := strings.LastIndex(description[:maxDescriptionLength], " ")
+		if cut <= 0 {
+			cut = maxDescriptionLength
+		}
+		description = strings.TrimSpace(description[:cut]) + "…"
+	}
+	return description
+}
+
+// rootReadme returns the README at the repository root, preferring markdown
+func rootReadme(files []models.FileInfo) (models.FileInfo, bool) {
+	var readme models.FileInfo
+	found := false
+	for _, file := range files {
+		if file.IsDir || file.IsBinary || strings.Contains(file.Path, "/") {
+			continue
+		}
+		name := strings.ToLower(file.Path)
+		if !strings.HasPrefix(name, "readme") {
+			continue
+		}
+		if !found || (strings.HasSuffix(name, ".md") && !strings.HasSuffix(strings.ToLower(readme.Path), ".md")) {
+			readme = file
+			found = true
+		}
+	}
+	return readme, found
+}
+
+// isDecorationLine reports whether a README line is a heading, badge, rule or HTML
+// rather than prose
+func isDecorationLine(line string) bool {
+	if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<") || strings.HasPrefix(line, "```") {
+		return true
+	}
+	if underlinePattern.MatchString(line) {
+		return true
+	}
+	// Lines made only of images, such as badge rows
+	return strings.HasPrefix(line, "[![") ||
+		(strings.HasPrefix(line, "![") && strings.TrimSpace(markdownLinkPattern.ReplaceAllString(line, "")) == "")
+}
@@ -0,0 +1,84 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadmeDescription(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []models.FileInfo
+		expected string
+	}{
+		{
+			name:     "should take the first paragraph after the title and badges",
+			files:    []models.FileInfo{{Path: "README.md", Content: "# Billing\n\n[![CI](https://ci/badge.svg)](https://ci)\n\nHandles invoices\nand payments.\n\n## Usage\n"}},
+			expected: "Handles invoices and payments.",
+		},
+		{
+			name:     "should keep the text of links",
+			files:    []models.FileInfo{{Path: "README.md", Content: "A client for the [Billing API](https://api).\n"}},
+			expected: "A client for the Billing API.",
+		},
+		{
+			name:     "should skip setext and reStructuredText titles",
+			files:    []models.FileInfo{{Path: "README.rst", Content: "Billing\n=======\n\nHandles invoices.\n"}},
+			expected: "Handles invoices.",
+		},
+		{
+			name: "should prefer the markdown README at the root",
+			files: []models.FileInfo{
+				{Path: "docs/README.md", Content: "Nested docs."},
+				{Path: "README.txt", Content: "Plain text."},
+				{Path: "README.md", Content: "Markdown."},
+			},
+			expected: "Markdown.",
+		},
+		{
+			name:     "should return nothing without a README",
+			files:    []models.FileInfo{{Path: "main.go", Content: "package main"}},
+			expected: "",
+		},
+		{
+			name:     "should return nothing for a README with only headings",
+			files:    []models.FileInfo{{Path: "README.md", Content: "# Billing\n\n## Usage\n"}},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, readmeDescription(tt.files))
+		})
+	}
+
+	t.Run("should shorten long paragraphs on a word boundary", func(t *testing.T) {
+		content := strings.Repeat("word ", 100)
+		description := readmeDescription([]models.FileInfo{{Path: "README.md", Content: content}})
+		assert.LessOrEqual(t, len(description), maxDescriptionLength+len("…"))
+		assert.True(t, strings.HasSuffix(description, "word…"))
+	})
+}
+
+func TestGenerator_ReadmeDescription(t *testing.T) {
+	generator := NewGenerator(true)
+	files := []models.FileInfo{{Path: "README.md", Name: "README.md", Content: "# Billing\n\nHandles invoices.\n", IsText: true}}
+
+	t.Run("should fill an empty description from the README", func(t *testing.T) {
+		output, err := generator.GenerateOutput(&models.ProcessingResult{Repository: models.Repository{Name: "billing"}, Files: files})
+		require.NoError(t, err)
+		assert.Contains(t, generator.GenerateLLMsText(output), "**Description:** Handles invoices.\n")
+	})
+
+	t.Run("should keep the platform description", func(t *testing.T) {
+		output, err := generator.GenerateOutput(&models.ProcessingResult{Repository: models.Repository{Name: "billing", Description: "Billing service"}, Files: files})
+		require.NoError(t, err)
+		assert.Equal(t, "Billing service", output.Repository.Description)
+	})
+}
@@ -0,0 +1,121 @@
+package generators
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// conventionFiles map the root files of a repository to the convention they show, in the
+// order conventions are listed. Patterns match file names.
+var conventionFiles = []struct {
+	patterns   []string
+	convention string
+}{
+	{[]string{"AGENTS.md", "CLAUDE.md", ".cursorrules", ".continuerules"}, "Follow the instructions for coding assistants in `%s`"},
+	{[]string{"CONTRIBUTING.md", "CONTRIBUTING.rst"}, "Contributions follow the guidelines in `%s`"},
+	{[]string{".editorconfig"}, "Indentation and line endings follow `%s`"},
+	{[]string{".golangci.yml", ".golangci.yaml", ".golangci.toml"}, "Go code is linted with golangci-lint, configured in `%s`"},
+	{[]string{".eslintrc*", "eslint.config.*"}, "JavaScript and TypeScript are linted with ESLint, configured in `%s`"},
+	{[]string{".prettierrc*", "prettier.config.*"}, "Code is formatted with Prettier, configured in `%s`"},
+	{[]string{"ruff.toml", ".ruff.toml"}, "Python code is linted with Ruff, configured in `%s`"},
+	{[]string{"rustfmt.toml", ".rustfmt.toml"}, "Rust code is formatted with rustfmt, configured in `%s`"},
+	{[]string{".rubocop.yml"}, "Ruby code follows RuboCop, configured in `%s`"},
+	{[]string{".pre-commit-config.yaml"}, "Pre-commit hooks are configured in `%s`"},
+}
+
+// GenerateEditorRules generates rules for editor assistants, written as .cursorrules for
+// Cursor and .continuerules for Continue: which generated context files to read, and the
+// conventions of the repository found in its root files. Rules are instructions to
+// assistants, so they are not localized.
+func (g *Generator) GenerateEditorRules(output *models.LLMsOutput, contextFiles []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", output.Repository.Name))
+	if description := strings.TrimSpace(output.Repository.Description); description != "" {
+		sb.WriteString(strings.Join(strings.Fields(description), " ") + "\n\n")
+	}
+	if summary := strings.TrimSpace(output.Summary); summary != "" {
+		sb.WriteString(summary + "\n\n")
+	}
+
+	if len(contextFiles) > 0 {
+		sb.WriteString("## Context\n\n")
+		sb.WriteString("Context generated from this repository is available next to these rules. Read it before answering questions about the codebase or changing it:\n\n")
+		for _, file := range contextFiles {
+			sb.WriteString(fmt.Sprintf("- `%s`: %s\n", file, contextFileDescription(file)))
+		}
+		sb.WriteString("\n")
+	}
+
+	var conventions []string
+	if languages := formatLanguages(LanguageStats(output.FileContents), "Other"); languages != "" {
+		conventions = append(conventions, "Languages: "+languages)
+	}
+	if len(output.Frameworks) > 0 {
+		conventions = append(conventions, "Built with: "+strings.Join(output.Frameworks, ", "))
+	}
+	for _, annotation := range output.Annotations {
+		if !annotation.ReadFirst {
+			continue
+		}
+		readFirst := fmt.Sprintf("Read `%s` first", annotation.Path)
+		if annotation.Description != "" {
+			readFirst += ": " + annotation.Description
+		}
+		conventions = append(conventions, readFirst)
+	}
+	conventions = append(conventions, repositoryConventions(output.FileContents)...)
+	if len(conventions) > 0 {
+		sb.WriteString("## Conventions\n\n")
+		for _, convention := range conventions {
+			sb.WriteString(fmt.Sprintf("- %s\n", convention))
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// repositoryConventions lists the conventions shown by the root files of a repository
+func repositoryConventions(files []models.FileInfo) []string {
+	var conventions []string
+	for _, convention := range conventionFiles {
+		if file, found := rootFileMatching(files, convention.patterns); found {
+			conventions = append(conventions, fmt.Sprintf(convention.convention, file))
+		}
+	}
+	return conventions
+}
+
+// rootFileMatching returns the first root file, in the order of patterns, whose name
+// matches one of them
+func rootFileMatching(files []models.FileInfo, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		for _, file := range files {
+			if file.IsDir || strings.Contains(file.Path, "/") {
+				continue
+			}
+			if matched, _ := path.Match(pattern, file.Path); matched {
+				return file.Path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// contextFileDescription describes a generated context file listed by the rules
+func contextFileDescription(name string) string {
+	switch {
+	case name == "llms.txt":
+		return "an index of the documentation, with links to each document"
+	case name == "tree.json":
+		return "the project tree with the size and estimated tokens of each file"
+	case strings.Contains(name, ".part"):
+		return "a part of the project tree and file contents, each part is self-contained"
+	default:
+		return "the project tree and the contents of the files"
+	}
+}
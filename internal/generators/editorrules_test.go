@@ -0,0 +1,55 @@
+package generators
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_GenerateEditorRules(t *testing.T) {
+	result := &models.ProcessingResult{
+		Repository: models.Repository{
+			Name:              "repo",
+			PathWithNamespace: "owner/repo",
+			Description:       "A tool\nfor things",
+		},
+		Files: []models.FileInfo{
+			{Path: ".editorconfig", Name: ".editorconfig", Content: "root = true\n", IsText: true},
+			{Path: ".golangci.yml", Name: ".golangci.yml", Content: "linters: {}\n", IsText: true},
+			{Path: "CONTRIBUTING.md", Name: "CONTRIBUTING.md", Content: "# Contributing\n", IsText: true},
+			{Path: "docs/.prettierrc", Name: ".prettierrc", Content: "{}\n", IsText: true},
+			{Path: "docs/guide.md", Name: "guide.md", Content: "# Guide\n", IsText: true},
+			{Path: "main.go", Name: "main.go", Content: "package main\n", IsText: true},
+		},
+		Annotations: []models.Annotation{{Path: "docs/guide.md", Description: "How to get started", ReadFirst: true}},
+	}
+	generator := NewGenerator(true)
+	output, err := generator.GenerateOutput(result)
+	require.NoError(t, err)
+
+	rules := generator.GenerateEditorRules(output, []string{"llms-full.part1.txt", "llms-full.part2.txt", "llms.txt"})
+
+	t.Run("should start with the repository name and description", func(t *testing.T) {
+		assert.Contains(t, rules, "# repo\n\nA tool for things\n\n")
+	})
+
+	t.Run("should point to the generated context files", func(t *testing.T) {
+		assert.Contains(t, rules, "- `llms-full.part1.txt`: a part of the project tree and file contents, each part is self-contained\n")
+		assert.Contains(t, rules, "- `llms.txt`: an index of the documentation, with links to each document\n")
+	})
+
+	t.Run("should summarize the conventions of the repository root files", func(t *testing.T) {
+		assert.Contains(t, rules, "- Read `docs/guide.md` first: How to get started\n"+
+			"- Contributions follow the guidelines in `CONTRIBUTING.md`\n"+
+			"- Indentation and line endings follow `.editorconfig`\n"+
+			"- Go code is linted with golangci-lint, configured in `.golangci.yml`")
+		assert.NotContains(t, rules, "Prettier")
+	})
+
+	t.Run("should leave out the context section without context files", func(t *testing.T) {
+		assert.NotContains(t, generator.GenerateEditorRules(output, nil), "## Context")
+	})
+}
@@ -0,0 +1,92 @@
+package generators
+
+import (
+	"sherpa/pkg/models"
+)
+
+// FoldProjectTree collapses deep subtrees into summary nodes so that the rendered
+// tree contains at most maxEntries lines where possible. Directories are folded
+// at the deepest level that satisfies the limit; top-level entries are always kept.
+func FoldProjectTree(nodes []models.TreeNode, maxEntries int) []models.TreeNode {
+	if maxEntries <= 0 || countTreeEntries(nodes, 1, -1) <= maxEntries {
+		return nodes
+	}
+
+	// Find the deepest folding level that fits within the limit
+	foldDepth := 1
+	for depth := treeDepth(nodes) - 1; depth > 1; depth-- {
+		if countTreeEntries(nodes, 1, depth) <= maxEntries {
+			foldDepth = depth
+			break
+		}
+	}
+
+	return foldAtDepth(nodes, 1, foldDepth)
+}
+
+// countTreeEntries counts rendered lines when directories at foldDepth are folded (-1 disables folding)
+func countTreeEntries(nodes []models.TreeNode, depth, foldDepth int) int {
+	count := 0
+	for _, node := range nodes {
+		count++
+		if node.IsDir && (foldDepth < 0 || depth < foldDepth) {
+			count += countTreeEntries(node.Children, depth+1, foldDepth)
+		}
+	}
+	return count
+}
+
+// treeDepth returns the maximum depth of the tree
+func treeDepth(nodes []models.TreeNode) int {
+	maxDepth := 0
+	for _, node := range nodes {
+		depth := 1
+		if node.IsDir {
+			depth += treeDepth(node.Children)
+		}
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	return maxDepth
+}
+
+// foldAtDepth returns a copy of the tree with directories at foldDepth collapsed
+func foldAtDepth(nodes []models.TreeNode, depth, foldDepth int) []models.TreeNode {
+	folded := make([]models.TreeNode, len(nodes))
+	for i, node := range nodes {
+		switch {
+		case !node.IsDir || len(node.Children) == 0:
+			folded[i] = node
+		case depth >= foldDepth:
+			fileCount, size := summarizeSubtree(node.Children)
+			folded[i] = models.TreeNode{
+				Name:      node.Name,
+				Path:      node.Path,
+				Size:      size,
+				IsDir:     true,
+				Folded:    true,
+				FileCount: fileCount,
+			}
+		default:
+			node.Children = foldAtDepth(node.Children, depth+1, foldDepth)
+			folded[i] = node
+		}
+	}
+	return folded
+}
+
+// summarizeSubtree returns the number of files and their total size below a node
+func summarizeSubtree(nodes []models.TreeNode) (fileCount int, size int64) {
+	for _, node := range nodes {
+		if node.IsDir {
+			childCount, childSize := summarizeSubtree(node.Children)
+			fileCount += childCount
+			size += childSize
+		} else {
+			fileCount++
+			size += node.Size
+		}
+	}
+	return fileCount, size
+}
@@ -0,0 +1,94 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleDeepTree() []models.TreeNode {
+	return []models.TreeNode{
+		{
+			Name:  "pkg",
+			Path:  "pkg",
+			IsDir: true,
+			Children: []models.TreeNode{
+				{
+					Name:  "deep",
+					Path:  "pkg/deep",
+					IsDir: true,
+					Children: []models.TreeNode{
+						{Name: "a.go", Path: "pkg/deep/a.go", Size: 1024},
+						{Name: "b.go", Path: "pkg/deep/b.go", Size: 2048},
+						{Name: "c.go", Path: "pkg/deep/c.go", Size: 1024},
+					},
+				},
+				{Name: "pkg.go", Path: "pkg/pkg.go", Size: 100},
+			},
+		},
+		{Name: "main.go", Path: "main.go", Size: 50},
+	}
+}
+
+func TestFoldProjectTree(t *testing.T) {
+	t.Run("should not fold when within limit", func(t *testing.T) {
+		nodes := sampleDeepTree()
+		assert.Equal(t, nodes, FoldProjectTree(nodes, 100))
+		assert.Equal(t, nodes, FoldProjectTree(nodes, 0))
+	})
+
+	t.Run("should fold deepest directories first", func(t *testing.T) {
+		folded := FoldProjectTree(sampleDeepTree(), 5)
+
+		deep := folded[0].Children[0]
+		assert.True(t, deep.Folded)
+		assert.Equal(t, 3, deep.FileCount)
+		assert.Equal(t, int64(4096), deep.Size)
+		assert.Empty(t, deep.Children)
+		assert.False(t, folded[0].Folded)
+	})
+
+	t.Run("should fold top-level directories when limit is very small", func(t *testing.T) {
+		folded := FoldProjectTree(sampleDeepTree(), 1)
+
+		assert.True(t, folded[0].Folded)
+		assert.Equal(t, 4, folded[0].FileCount)
+		assert.Equal(t, "main.go", folded[1].Name)
+	})
+}
+
+func TestGenerator_FoldedTreeRendering(t *testing.T) {
+	generator := NewGeneratorWithConfig(true, models.OutputConfig{MaxTreeEntries: 5})
+	result := &models.ProcessingResult{
+		Repository: models.Repository{Name: "test-repo"},
+		Files: []models.FileInfo{
+			{Path: "pkg/deep/a.go", Size: 1024},
+			{Path: "pkg/deep/b.go", Size: 2048},
+			{Path: "pkg/deep/c.go", Size: 1024},
+			{Path: "pkg/pkg.go", Size: 100},
+			{Path: "main.go", Size: 50},
+		},
+	}
+
+	t.Run("should render folded summary lines", func(t *testing.T) {
+		output, err := generator.GenerateOutput(result)
+		assert.NoError(t, err)
+
+		text := generator.GenerateLLMsText(output)
+		assert.Contains(t, text, "deep/ (3 files, 4.0 KB)")
+		assert.Contains(t, text, "2 directories, 5 files")
+		assert.False(t, strings.Contains(text, "a.go"))
+	})
+
+	t.Run("should expand tree when requested", func(t *testing.T) {
+		expanded := NewGeneratorWithConfig(true, models.OutputConfig{MaxTreeEntries: 5, ExpandTree: true})
+		output, err := expanded.GenerateOutput(result)
+		assert.NoError(t, err)
+
+		text := expanded.GenerateLLMsText(output)
+		assert.Contains(t, text, "a.go")
+	})
+}
@@ -0,0 +1,369 @@
+package generators
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultLanguage is the language used when none is configured
+const DefaultLanguage = "en"
+
+// Message keys for localized output strings
+const (
+	msgRepository       = "repository"
+	msgGenerated        = "generated"
+	msgTotalFiles       = "total_files"
+	msgTotalSize        = "total_size"
+	msgRepositoryInfo   = "repository_info"
+	msgName             = "name"
+	msgPath             = "path"
+	msgURL              = "url"
+	msgDescription      = "description"
+	msgProjectStructure = "project_structure"
+	msgFileContents     = "file_contents"
+	msgError            = "error"
+	msgLargeFile        = "large_file"
+	msgFileTooLarge     = "file_too_large"
+	msgTreeSummary      = "tree_summary"
+	msgFoldedSummary    = "folded_summary"
+	msgSensitiveFiles   = "sensitive_files"
+	msgBuiltWith        = "built_with"
+	msgLanguages        = "languages"
+	msgOtherLanguages   = "other_languages"
+	msgSummary          = "summary"
+	msgReadFirst        = "read_first"
+	msgContentOmitted   = "content_omitted"
+	msgOutlineOnly      = "outline_only"
+	msgTableOfContents  = "table_of_contents"
+	msgEstimatedTokens  = "estimated_tokens"
+	msgTokenCount       = "token_count"
+	msgTruncated        = "truncated"
+	msgOmittedFiles     = "omitted_files"
+	msgOmittedNote      = "omitted_note"
+	msgPart             = "part"
+	msgIncomplete       = "incomplete"
+	msgIncompleteResume = "incomplete_resume"
+	msgTimedOut         = "timed_out"
+	msgTimedOutResume   = "timed_out_resume"
+	msgNotFetched       = "not_fetched"
+	msgSample           = "sample"
+	msgSampleFiles      = "sample_files"
+	msgVendored         = "vendored"
+	msgPackageCount     = "package_count"
+	msgChangelog        = "changelog"
+	msgBefore           = "before"
+	msgAfter            = "after"
+	msgDelta            = "delta"
+	msgFiles            = "files"
+	msgSize             = "size"
+	msgTokens           = "tokens"
+	msgTokenChange      = "token_change"
+	msgFileCount        = "file_count"
+	msgNewDirectories   = "new_directories"
+	msgAddedFiles       = "added_files"
+	msgRemovedFiles     = "removed_files"
+	msgMoreEntries      = "more_entries"
+	msgNoChanges        = "no_changes"
+	msgPullRequest      = "pull_request"
+	msgPullRequestInfo  = "pull_request_info"
+	msgTitle            = "title"
+	msgAuthor           = "author"
+	msgBranches         = "branches"
+	msgHeadCommit       = "head_commit"
+	msgCommit           = "commit"
+	msgChangedFiles     = "changed_files"
+	msgChanges          = "changes"
+	msgNoDiff           = "no_diff"
+	msgChangeContext    = "change_context"
+	msgComparison       = "comparison"
+	msgCommits          = "commits"
+	msgIssues           = "issues"
+	msgState            = "state"
+	msgCreated          = "created"
+	msgLabels           = "labels"
+	msgComments         = "comments"
+	msgReleases         = "releases"
+	msgTag              = "tag"
+	msgPublished        = "published"
+	msgPrerelease       = "prerelease"
+	msgWiki             = "wiki"
+	msgLicenseOmitted   = "license_omitted"
+)
+
+// catalogs contains the output templates for each supported language
+var catalogs = map[string]map[string]string{
+	"en": {
+		msgRepository:       "Repository",
+		msgGenerated:        "Generated",
+		msgTotalFiles:       "Total Files",
+		msgTotalSize:        "Total Size",
+		msgRepositoryInfo:   "Repository Information",
+		msgName:             "Name",
+		msgPath:             "Path",
+		msgURL:              "URL",
+		msgDescription:      "Description",
+		msgProjectStructure: "Project Structure",
+		msgFileContents:     "File Contents",
+		msgError:            "Error",
+		msgLargeFile:        "Large file: %s",
+		msgFileTooLarge:     "File too large to include - %s (max: %s)",
+		msgTreeSummary:      "%d directories, %d files",
+		msgFoldedSummary:    "%d files, %s",
+		msgSensitiveFiles:   "Sensitive files included: %d",
+		msgBuiltWith:        "Built With",
+		msgLanguages:        "Languages",
+		msgOtherLanguages:   "Other",
+		msgSummary:          "Summary",
+		msgReadFirst:        "read first",
+		msgContentOmitted:   "Content omitted to fit the token budget (~%d tokens)",
+		msgOutlineOnly:      "Outline only, full content omitted to fit the token budget",
+		msgTableOfContents:  "Table of Contents",
+		msgEstimatedTokens:  "Estimated Tokens",
+		msgTokenCount:       "~%d tokens",
+		msgTruncated:        "Truncated to fit the token limit (~%d of ~%d tokens shown)",
+		msgOmittedFiles:     "Omitted Files",
+		msgOmittedNote:      "Left out to fit the limit of %d tokens:",
+		msgPart:             "Part %d of %d",
+		msgIncomplete:       "INCOMPLETE: rate limited at %s, %d/%d files",
+		msgIncompleteResume: "Run sherpa again once the rate limit resets to fetch the remaining files.",
+		msgTimedOut:         "INCOMPLETE: time budget ran out at %s, %d/%d files",
+		msgTimedOutResume:   "Files not fetched in time are listed as stubs. Raise --budget-time to include them.",
+		msgNotFetched:       "Not fetched: the time budget ran out",
+		msgSample:           "Sample",
+		msgSampleFiles:      "%d of %d files (%s)",
+		msgVendored:         "Vendored Dependencies",
+		msgPackageCount:     "%d packages",
+		msgChangelog:        "Changes",
+		msgBefore:           "Before",
+		msgAfter:            "After",
+		msgDelta:            "Change",
+		msgFiles:            "Files",
+		msgSize:             "Size",
+		msgTokens:           "Tokens",
+		msgTokenChange:      "%+d tokens",
+		msgFileCount:        "%d files",
+		msgNewDirectories:   "New Directories",
+		msgAddedFiles:       "Added Files",
+		msgRemovedFiles:     "Removed Files",
+		msgMoreEntries:      "... and %d more",
+		msgNoChanges:        "No files changed between the snapshots.",
+		msgPullRequest:      "Pull Request",
+		msgPullRequestInfo:  "Pull Request Information",
+		msgTitle:            "Title",
+		msgAuthor:           "Author",
+		msgBranches:         "Branches",
+		msgHeadCommit:       "Head Commit",
+		msgCommit:           "Commit",
+		msgChangedFiles:     "Changed Files",
+		msgChanges:          "Changes",
+		msgNoDiff:           "Diff not available: binary file or diff too large",
+		msgChangeContext:    "Context around the changes",
+		msgComparison:       "Comparison",
+		msgCommits:          "Commits",
+		msgIssues:           "Issues",
+		msgState:            "State",
+		msgCreated:          "Created",
+		msgLabels:           "Labels",
+		msgComments:         "Comments",
+		msgReleases:         "Releases",
+		msgTag:              "Tag",
+		msgPublished:        "Published",
+		msgPrerelease:       "Pre-release",
+		msgWiki:             "Wiki",
+		msgLicenseOmitted:   "License header omitted, identical to the one of %s",
+	},
+	"fr": {
+		msgRepository:       "Dépôt",
+		msgGenerated:        "Généré le",
+		msgTotalFiles:       "Nombre de fichiers",
+		msgTotalSize:        "Taille totale",
+		msgRepositoryInfo:   "Informations sur le dépôt",
+		msgName:             "Nom",
+		msgPath:             "Chemin",
+		msgURL:              "URL",
+		msgDescription:      "Description",
+		msgProjectStructure: "Structure du projet",
+		msgFileContents:     "Contenu des fichiers",
+		msgError:            "Erreur",
+		msgLargeFile:        "Fichier volumineux : %s",
+		msgFileTooLarge:     "Fichier trop volumineux pour être inclus - %s (max : %s)",
+		msgTreeSummary:      "%d répertoires, %d fichiers",
+		msgFoldedSummary:    "%d fichiers, %s",
+		msgSensitiveFiles:   "Fichiers sensibles inclus : %d",
+		msgBuiltWith:        "Construit avec",
+		msgLanguages:        "Langages",
+		msgOtherLanguages:   "Autres",
+		msgSummary:          "Résumé",
+		msgReadFirst:        "à lire en premier",
+		msgContentOmitted:   "Contenu omis pour respecter le budget de tokens (~%d tokens)",
+		msgOutlineOnly:      "Plan uniquement, contenu complet omis pour respecter le budget de tokens",
+		msgTableOfContents:  "Table des matières",
+		msgEstimatedTokens:  "Tokens estimés",
+		msgTokenCount:       "~%d tokens",
+		msgTruncated:        "Tronqué pour respecter la limite de tokens (~%d sur ~%d tokens affichés)",
+		msgOmittedFiles:     "Fichiers omis",
+		msgOmittedNote:      "Omis pour respecter la limite de %d tokens :",
+		msgPart:             "Partie %d sur %d",
+		msgIncomplete:       "INCOMPLET : limite de requêtes atteinte le %s, %d/%d fichiers",
+		msgIncompleteResume: "Relancez sherpa une fois la limite réinitialisée pour récupérer les fichiers restants.",
+		msgTimedOut:         "INCOMPLET : budget de temps épuisé le %s, %d/%d fichiers",
+		msgTimedOutResume:   "Les fichiers non récupérés à temps sont listés sans contenu. Augmentez --budget-time pour les inclure.",
+		msgNotFetched:       "Non récupéré : le budget de temps est épuisé",
+		msgSample:           "Échantillon",
+		msgSampleFiles:      "%d fichiers sur %d (%s)",
+		msgVendored:         "Dépendances embarquées",
+		msgPackageCount:     "%d paquets",
+		msgChangelog:        "Changements",
+		msgBefore:           "Avant",
+		msgAfter:            "Après",
+		msgDelta:            "Écart",
+		msgFiles:            "Fichiers",
+		msgSize:             "Taille",
+		msgTokens:           "Tokens",
+		msgTokenChange:      "%+d tokens",
+		msgFileCount:        "%d fichiers",
+		msgNewDirectories:   "Nouveaux répertoires",
+		msgAddedFiles:       "Fichiers ajoutés",
+		msgRemovedFiles:     "Fichiers supprimés",
+		msgMoreEntries:      "... et %d de plus",
+		msgNoChanges:        "Aucun fichier n'a changé entre les instantanés.",
+		msgPullRequest:      "Pull request",
+		msgPullRequestInfo:  "Informations sur la pull request",
+		msgTitle:            "Titre",
+		msgAuthor:           "Auteur",
+		msgBranches:         "Branches",
+		msgHeadCommit:       "Commit de tête",
+		msgCommit:           "Commit",
+		msgChangedFiles:     "Fichiers modifiés",
+		msgChanges:          "Modifications",
+		msgNoDiff:           "Diff indisponible : fichier binaire ou diff trop volumineux",
+		msgChangeContext:    "Contexte autour des modifications",
+		msgComparison:       "Comparaison",
+		msgCommits:          "Commits",
+		msgIssues:           "Tickets",
+		msgState:            "État",
+		msgCreated:          "Créé le",
+		msgLabels:           "Libellés",
+		msgComments:         "Commentaires",
+		msgReleases:         "Versions publiées",
+		msgTag:              "Tag",
+		msgPublished:        "Publiée le",
+		msgPrerelease:       "Préversion",
+		msgWiki:             "Wiki",
+		msgLicenseOmitted:   "En-tête de licence omis, identique à celui de %s",
+	},
+	"ja": {
+		msgRepository:       "リポジトリ",
+		msgGenerated:        "生成日時",
+		msgTotalFiles:       "ファイル数",
+		msgTotalSize:        "合計サイズ",
+		msgRepositoryInfo:   "リポジトリ情報",
+		msgName:             "名前",
+		msgPath:             "パス",
+		msgURL:              "URL",
+		msgDescription:      "説明",
+		msgProjectStructure: "プロジェクト構成",
+		msgFileContents:     "ファイル内容",
+		msgError:            "エラー",
+		msgLargeFile:        "大きなファイル: %s",
+		msgFileTooLarge:     "ファイルが大きすぎるため省略 - %s (上限: %s)",
+		msgTreeSummary:      "%d ディレクトリ, %d ファイル",
+		msgFoldedSummary:    "%d ファイル, %s",
+		msgSensitiveFiles:   "機密ファイル数: %d",
+		msgBuiltWith:        "使用フレームワーク",
+		msgLanguages:        "言語",
+		msgOtherLanguages:   "その他",
+		msgSummary:          "概要",
+		msgReadFirst:        "最初に読む",
+		msgContentOmitted:   "トークン予算に収めるため内容を省略 (約%dトークン)",
+		msgOutlineOnly:      "トークン予算に収めるためアウトラインのみ表示",
+		msgTableOfContents:  "目次",
+		msgEstimatedTokens:  "推定トークン数",
+		msgTokenCount:       "約%dトークン",
+		msgTruncated:        "トークン上限に収めるため切り詰め (約%[2]dトークン中約%[1]dトークンを表示)",
+		msgOmittedFiles:     "省略されたファイル",
+		msgOmittedNote:      "%dトークンの上限に収めるため省略:",
+		msgPart:             "パート %d / %d",
+		msgIncomplete:       "不完全: %s にレート制限に到達、%d/%d ファイル",
+		msgIncompleteResume: "レート制限の解除後に sherpa を再実行すると、残りのファイルを取得します。",
+		msgTimedOut:         "不完全: %s に時間予算が尽きました、%d/%d ファイル",
+		msgTimedOutResume:   "時間内に取得できなかったファイルは内容なしで記載されています。--budget-time を増やすと含まれます。",
+		msgNotFetched:       "未取得: 時間予算が尽きました",
+		msgSample:           "サンプル",
+		msgSampleFiles:      "%[2]d ファイル中 %[1]d ファイル (%[3]s)",
+		msgVendored:         "ベンダリングされた依存関係",
+		msgPackageCount:     "%d パッケージ",
+		msgChangelog:        "変更点",
+		msgBefore:           "変更前",
+		msgAfter:            "変更後",
+		msgDelta:            "差分",
+		msgFiles:            "ファイル",
+		msgSize:             "サイズ",
+		msgTokens:           "トークン",
+		msgTokenChange:      "%+d トークン",
+		msgFileCount:        "%d ファイル",
+		msgNewDirectories:   "新しいディレクトリ",
+		msgAddedFiles:       "追加されたファイル",
+		msgRemovedFiles:     "削除されたファイル",
+		msgMoreEntries:      "... ほか %d 件",
+		msgNoChanges:        "スナップショット間で変更されたファイルはありません。",
+		msgPullRequest:      "プルリクエスト",
+		msgPullRequestInfo:  "プルリクエスト情報",
+		msgTitle:            "タイトル",
+		msgAuthor:           "作成者",
+		msgBranches:         "ブランチ",
+		msgHeadCommit:       "先頭コミット",
+		msgCommit:           "コミット",
+		msgChangedFiles:     "変更されたファイル",
+		msgChanges:          "変更内容",
+		msgNoDiff:           "差分なし: バイナリファイルまたは差分が大きすぎます",
+		msgChangeContext:    "変更箇所の前後",
+		msgComparison:       "比較",
+		msgCommits:          "コミット数",
+		msgIssues:           "イシュー",
+		msgState:            "状態",
+		msgCreated:          "作成日",
+		msgLabels:           "ラベル",
+		msgComments:         "コメント数",
+		msgReleases:         "リリース",
+		msgTag:              "タグ",
+		msgPublished:        "公開日",
+		msgPrerelease:       "プレリリース",
+		msgWiki:             "Wiki",
+		msgLicenseOmitted:   "ライセンスヘッダーを省略（%s と同一）",
+	},
+}
+
+// SupportedLanguages returns the list of supported output languages
+func SupportedLanguages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// IsSupportedLanguage checks if a language has an output catalog
+func IsSupportedLanguage(lang string) bool {
+	_, exists := catalogs[lang]
+	return exists
+}
+
+// translate returns the localized string for a key, falling back to English
+func translate(lang, key string, args ...interface{}) string {
+	catalog, exists := catalogs[lang]
+	if !exists {
+		catalog = catalogs[DefaultLanguage]
+	}
+
+	template, exists := catalog[key]
+	if !exists {
+		template = catalogs[DefaultLanguage][key]
+	}
+
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
@@ -0,0 +1,91 @@
+package generators
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		key      string
+		args     []interface{}
+		expected string
+	}{
+		{
+			name:     "should return english heading",
+			lang:     "en",
+			key:      msgFileContents,
+			expected: "File Contents",
+		},
+		{
+			name:     "should return french heading",
+			lang:     "fr",
+			key:      msgFileContents,
+			expected: "Contenu des fichiers",
+		},
+		{
+			name:     "should format template arguments",
+			lang:     "ja",
+			key:      msgTreeSummary,
+			args:     []interface{}{2, 5},
+			expected: "2 ディレクトリ, 5 ファイル",
+		},
+		{
+			name:     "should fall back to english for unknown language",
+			lang:     "xx",
+			key:      msgProjectStructure,
+			expected: "Project Structure",
+		},
+		{
+			name:     "should fall back to english for empty language",
+			lang:     "",
+			key:      msgTotalFiles,
+			expected: "Total Files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, translate(tt.lang, tt.key, tt.args...))
+		})
+	}
+}
+
+func TestSupportedLanguages(t *testing.T) {
+	t.Run("should list all catalogs", func(t *testing.T) {
+		assert.Equal(t, []string{"en", "fr", "ja"}, SupportedLanguages())
+		assert.True(t, IsSupportedLanguage("fr"))
+		assert.False(t, IsSupportedLanguage("de"))
+	})
+
+	t.Run("should define every key in every catalog", func(t *testing.T) {
+		for lang, catalog := range catalogs {
+			for key := range catalogs[DefaultLanguage] {
+				assert.Contains(t, catalog, key, "language %s is missing key %s", lang, key)
+			}
+		}
+	})
+}
+
+func TestGenerator_LocalizedOutput(t *testing.T) {
+	t.Run("should render french headings", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{Language: "fr"})
+		output := &models.LLMsOutput{
+			Repository: models.Repository{Name: "test-repo"},
+			FileContents: []models.FileInfo{
+				{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+			},
+		}
+
+		text := generator.GenerateLLMsFullText(output)
+		assert.Contains(t, text, "# Dépôt: test-repo")
+		assert.Contains(t, text, "## Structure du projet")
+		assert.Contains(t, text, "## Contenu des fichiers")
+		assert.NotContains(t, text, "## File Contents")
+	})
+}
@@ -0,0 +1,42 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// writeIssues writes the Issues section, with the metadata and description of each issue.
+// Nothing is written without issues.
+func (g *Generator) writeIssues(sb *strings.Builder, issues []models.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgIssues)))
+	for _, issue := range issues {
+		sb.WriteString(fmt.Sprintf("### #%d %s\n", issue.Number, issue.Title))
+
+		details := []string{fmt.Sprintf("**%s:** %s", g.t(msgState), issue.State)}
+		if issue.Author != "" {
+			details = append(details, fmt.Sprintf("**%s:** %s", g.t(msgAuthor), issue.Author))
+		}
+		if !issue.CreatedAt.IsZero() {
+			details = append(details, fmt.Sprintf("**%s:** %s", g.t(msgCreated), issue.CreatedAt.UTC().Format("2006-01-02")))
+		}
+		if len(issue.Labels) > 0 {
+			details = append(details, fmt.Sprintf("**%s:** %s", g.t(msgLabels), strings.Join(issue.Labels, ", ")))
+		}
+		details = append(details, fmt.Sprintf("**%s:** %d", g.t(msgComments), issue.Comments))
+		sb.WriteString(strings.Join(details, " | ") + "\n")
+		if issue.URL != "" {
+			sb.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgURL), issue.URL))
+		}
+		sb.WriteString("\n")
+
+		if body := strings.TrimSpace(issue.Body); body != "" {
+			sb.WriteString(body + "\n\n")
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_WriteIssues(t *testing.T) {
+	issues := []models.Issue{
+		{
+			Number:    12,
+			Title:     "Uploads fail behind the proxy",
+			Body:      "Uploads time out after 30s.\n",
+			State:     models.IssueStateOpen,
+			Author:    "alice",
+			URL:       "https://github.com/owner/repo/issues/12",
+			Labels:    []string{"bug", "upload"},
+			Comments:  3,
+			CreatedAt: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{Number: 9, Title: "Document retries", State: models.IssueStateClosed},
+	}
+
+	t.Run("should write each issue with its metadata and description", func(t *testing.T) {
+		var sb strings.Builder
+		NewGenerator(true).writeIssues(&sb, issues)
+
+		assert.Equal(t, "## Issues\n\n"+
+			"### #12 Uploads fail behind the proxy\n"+
+			"**State:** open | **Author:** alice | **Created:** 2026-01-02 | **Labels:** bug, upload | **Comments:** 3\n"+
+			"**URL:** https://github.com/owner/repo/issues/12\n\n"+
+			"Uploads time out after 30s.\n\n"+
+			"### #9 Document retries\n"+
+			"**State:** closed | **Comments:** 0\n\n", sb.String())
+	})
+
+	t.Run("should write nothing without issues", func(t *testing.T) {
+		var sb strings.Builder
+		NewGenerator(true).writeIssues(&sb, nil)
+		assert.Empty(t, sb.String())
+	})
+
+	t.Run("should render issues once in llms-full.txt and llms-full.md", func(t *testing.T) {
+		generator := NewGenerator(true)
+		output, err := generator.GenerateOutput(&models.ProcessingResult{
+			Repository: models.Repository{Name: "repo"},
+			Files:      []models.FileInfo{{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true}},
+			Issues:     issues,
+		})
+		assert.NoError(t, err)
+
+		text := generator.GenerateLLMsFullText(output)
+		assert.Equal(t, 1, strings.Count(text, "## Issues\n"))
+		assert.Less(t, strings.Index(text, "## Issues"), strings.Index(text, "## File Contents"))
+
+		markdown := generator.GenerateMarkdown(output)
+		assert.Contains(t, markdown, "- [Issues](#issues)\n")
+		assert.Contains(t, markdown, "<a id=\"issues\"></a>\n\n## Issues\n")
+	})
+}
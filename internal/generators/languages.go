@@ -0,0 +1,147 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// maxLanguages is the number of languages listed in the header before the rest is grouped
+const maxLanguages = 6
+
+// languageNames maps file extensions to the language names GitHub shows in its language bar
+var languageNames = map[string]string{
+	".go":     "Go",
+	".py":     "Python",
+	".js":     "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".jsx":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".java":   "Java",
+	".c":      "C",
+	".h":      "C",
+	".cpp":    "C++",
+	".cxx":    "C++",
+	".cc":     "C++",
+	".hpp":    "C++",
+	".cs":     "C#",
+	".php":    "PHP",
+	".rb":     "Ruby",
+	".rs":     "Rust",
+	".swift":  "Swift",
+	".kt":     "Kotlin",
+	".scala":  "Scala",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".zsh":    "Shell",
+	".ps1":    "PowerShell",
+	".sql":    "SQL",
+	".html":   "HTML",
+	".htm":    "HTML",
+	".css":    "CSS",
+	".scss":   "SCSS",
+	".less":   "Less",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".json":   "JSON",
+	".yaml":   "YAML",
+	".yml":    "YAML",
+	".toml":   "TOML",
+	".xml":    "XML",
+	".proto":  "Protocol Buffer",
+	".tf":     "HCL",
+	".hcl":    "HCL",
+	".mk":     "Makefile",
+	".cmake":  "CMake",
+	".lua":    "Lua",
+	".pl":     "Perl",
+	".r":      "R",
+	".dart":   "Dart",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".erl":    "Erlang",
+	".hs":     "Haskell",
+	".ml":     "OCaml",
+	".fs":     "F#",
+	".clj":    "Clojure",
+	".el":     "Emacs Lisp",
+	".vim":    "Vim Script",
+}
+
+// LanguageShare is the part of the included content written in a language
+type LanguageShare struct {
+	Name    string
+	Bytes   int64
+	Percent float64
+}
+
+// fileLanguage returns the language of a file from its name or extension, or an empty
+// string when it is unknown
+func fileLanguage(path string) string {
+	fileName := strings.ToLower(filepath.Base(path))
+	switch {
+	case fileName == "dockerfile" || strings.HasSuffix(fileName, ".dockerfile"):
+		return "Dockerfile"
+	case fileName == "makefile" || fileName == "gnumakefile":
+		return "Makefile"
+	}
+	return languageNames[filepath.Ext(fileName)]
+}
+
+// LanguageStats returns the languages of the included files weighted by content size, the
+// largest first. Like GitHub's language bar, documentation and generated files are left out.
+func LanguageStats(files []models.FileInfo) []LanguageShare {
+	bytesByLanguage := make(map[string]int64)
+	var total int64
+	for _, file := range files {
+		if file.IsDir || file.IsBinary || file.Error != nil || file.Content == "" {
+			continue
+		}
+		if utils.IsDocFile(file.Path) || isGeneratedFile(file.Path, file.Content) {
+			continue
+		}
+		language := fileLanguage(file.Path)
+		if language == "" {
+			continue
+		}
+		bytesByLanguage[language] += int64(len(file.Content))
+		total += int64(len(file.Content))
+	}
+
+	shares := make([]LanguageShare, 0, len(bytesByLanguage))
+	for name, bytes := range bytesByLanguage {
+		shares = append(shares, LanguageShare{Name: name, Bytes: bytes, Percent: float64(bytes) * 100 / float64(total)})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Bytes != shares[j].Bytes {
+			return shares[i].Bytes > shares[j].Bytes
+		}
+		return shares[i].Name < shares[j].Name
+	})
+	return shares
+}
+
+// formatLanguages renders language shares like "Go 62%, TypeScript 25%, YAML 8%". Languages
+// below 1% and beyond the first few are grouped under other.
+func formatLanguages(shares []LanguageShare, other string) string {
+	var parts []string
+	remainder := 0.0
+	for i, share := range shares {
+		if i >= maxLanguages || share.Percent < 1 {
+			remainder += share.Percent
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %d%%", share.Name, int(math.Round(share.Percent))))
+	}
+	if math.Round(remainder) >= 1 {
+		parts = append(parts, fmt.Sprintf("%s %d%%", other, int(math.Round(remainder))))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,87 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguageStats(t *testing.T) {
+	t.Run("should weight languages by content size", func(t *testing.T) {
+		files := []models.FileInfo{
+			{Path: "main.go", Content: strings.Repeat("a", 600), IsText: true},
+			{Path: "web/app.ts", Content: strings.Repeat("b", 250), IsText: true},
+			{Path: "web/view.tsx", Content: strings.Repeat("c", 50), IsText: true},
+			{Path: ".github/ci.yml", Content: strings.Repeat("d", 100), IsText: true},
+			{Path: "README.md", Content: strings.Repeat("e", 5000), IsText: true},
+			{Path: "api/service.pb.go", Content: strings.Repeat("f", 5000), IsText: true},
+			{Path: "logo.png", Content: "png", IsBinary: true},
+			{Path: "web", IsDir: true},
+		}
+
+		shares := LanguageStats(files)
+		require.Len(t, shares, 3)
+		assert.Equal(t, "Go", shares[0].Name)
+		assert.InDelta(t, 60, shares[0].Percent, 0.01)
+		assert.Equal(t, "TypeScript", shares[1].Name)
+		assert.Equal(t, int64(300), shares[1].Bytes)
+		assert.Equal(t, "YAML", shares[2].Name)
+	})
+
+	t.Run("should detect languages from file names", func(t *testing.T) {
+		assert.Equal(t, "Dockerfile", fileLanguage("build/Dockerfile"))
+		assert.Equal(t, "Makefile", fileLanguage("Makefile"))
+		assert.Empty(t, fileLanguage("LICENSE"))
+	})
+}
+
+func TestFormatLanguages(t *testing.T) {
+	tests := []struct {
+		name     string
+		shares   []LanguageShare
+		expected string
+	}{
+		{
+			name:     "should round percentages",
+			shares:   []LanguageShare{{Name: "Go", Percent: 62.4}, {Name: "TypeScript", Percent: 25.3}, {Name: "YAML", Percent: 12.3}},
+			expected: "Go 62%, TypeScript 25%, YAML 12%",
+		},
+		{
+			name:     "should group small languages",
+			shares:   []LanguageShare{{Name: "Go", Percent: 97.2}, {Name: "Shell", Percent: 2.1}, {Name: "Makefile", Percent: 0.4}, {Name: "SQL", Percent: 0.3}},
+			expected: "Go 97%, Shell 2%, Other 1%",
+		},
+		{
+			name:     "should render nothing without languages",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatLanguages(tt.shares, "Other"))
+		})
+	}
+}
+
+func TestGenerator_LanguagesHeader(t *testing.T) {
+	generator := NewGenerator(true)
+	output := &models.LLMsOutput{
+		Repository: models.Repository{Name: "test-repo"},
+		FileContents: []models.FileInfo{
+			{Path: "main.go", Content: "package main\n", IsText: true},
+		},
+	}
+
+	t.Run("should list languages in the text header", func(t *testing.T) {
+		assert.Contains(t, generator.GenerateLLMsFullText(output), "# Languages: Go 100%\n")
+	})
+
+	t.Run("should list languages in the Markdown header", func(t *testing.T) {
+		assert.Contains(t, generator.GenerateMarkdown(output), "- **Languages:** Go 100%\n")
+	})
+}
@@ -0,0 +1,185 @@
+package generators
+
+import (
+	"regexp"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// licenseHeaderMinLines is the size below which license headers are kept, since a marker
+// would save nothing on one-line SPDX identifiers
+const licenseHeaderMinLines = 3
+
+// licenseKeywords mark a leading comment as a license header
+var licenseKeywords = []string{"license", "copyright", "spdx-license-identifier"}
+
+// licenseYears matches the years of copyright notices, which differ between headers that
+// are otherwise identical
+var licenseYears = regexp.MustCompile(`\b(19|20)\d{2}(\s*[-,]\s*((19|20)\d{2}|present))*\b`)
+
+// lineCommentPrefixes start the line comments a header can be written in
+var lineCommentPrefixes = []string{"//", ";;", "--", "#", "%"}
+
+// blockComments are the delimiters of the block comments a header can be written in
+var blockComments = []struct{ open, close string }{
+	{"/*", "*/"},
+	{"<!--", "-->"},
+	{"(*", "*)"},
+	{"{-", "-}"},
+}
+
+// licenseHeader is a license header found at the top of a file
+type licenseHeader struct {
+	start, end int    // Byte offsets of the header in the content
+	key        string // Text without comment markers and years, equal for repeated headers
+	open       string // Delimiters of a comment in the same syntax, for the marker
+	close      string
+}
+
+// stripLicenseHeaders replaces the license headers repeated at the top of files with a
+// one-line comment naming the first file with the same header, which keeps it. Headers are
+// compared without their comment markers, spacing and years. files is copied before any
+// header is stripped.
+func (g *Generator) stripLicenseHeaders(files []models.FileInfo) []models.FileInfo {
+	first := make(map[string]string)
+	var stripped []models.FileInfo
+	for i, file := range files {
+		if file.IsDir || file.IsBinary || file.Error != nil || file.NotFetched || file.Content == "" {
+			continue
+		}
+		header, ok := findLicenseHeader(file.Content)
+		if !ok {
+			continue
+		}
+		original, seen := first[header.key]
+		if !seen {
+			first[header.key] = file.Path
+			continue
+		}
+
+		if stripped == nil {
+			stripped = make([]models.FileInfo, len(files))
+			copy(stripped, files)
+		}
+		marker := header.open + g.t(msgLicenseOmitted, original) + header.close
+		stripped[i].Content = file.Content[:header.start] + marker + file.Content[header.end:]
+	}
+	if stripped == nil {
+		return files
+	}
+	return stripped
+}
+
+// findLicenseHeader returns the leading comment of content, after any shebang line, when it
+// is a license header
+func findLicenseHeader(content string) (licenseHeader, bool) {
+	start := 0
+	if strings.HasPrefix(content, "#!") {
+		newline := strings.IndexByte(content, '\n')
+		if newline < 0 {
+			return licenseHeader{}, false
+		}
+		start = newline + 1
+	}
+	for start < len(content) && strings.ContainsRune(" \t\r\n", rune(content[start])) {
+		start++
+	}
+
+	header, lines, ok := blockCommentHeader(content, start)
+	if !ok {
+		header, lines, ok = lineCommentHeader(content, start)
+	}
+	if !ok || lines < licenseHeaderMinLines {
+		return licenseHeader{}, false
+	}
+
+	text := strings.ToLower(content[header.start:header.end])
+	for _, keyword := range licenseKeywords {
+		if strings.Contains(text, keyword) {
+			header.key = licenseKey(content[header.start:header.end])
+			return header, true
+		}
+	}
+	return licenseHeader{}, false
+}
+
+// blockCommentHeader returns the block comment starting at start and its number of lines
+func blockCommentHeader(content string, start int) (licenseHeader, int, bool) {
+	rest := content[start:]
+	for _, delimiters := range blockComments {
+		if !strings.HasPrefix(rest, delimiters.open) {
+			continue
+		}
+		length := strings.Index(rest[len(delimiters.open):], delimiters.close)
+		if length < 0 {
+			return licenseHeader{}, 0, false
+		}
+		end := start + len(delimiters.open) + length + len(delimiters.close)
+		return licenseHeader{
+			start: start,
+			end:   end,
+			open:  delimiters.open + " ",
+			close: " " + delimiters.close,
+		}, strings.Count(content[start:end], "\n") + 1, true
+	}
+	return licenseHeader{}, 0, false
+}
+
+// lineCommentHeader returns the line comments starting at start, up to the first line that
+// is not a comment with the same prefix, and their number of lines
+func lineCommentHeader(content string, start int) (licenseHeader, int, bool) {
+	prefix := ""
+	for _, candidate := range lineCommentPrefixes {
+		if isLineComment(content[start:], candidate) {
+			prefix = candidate
+			break
+		}
+	}
+	if prefix == "" {
+		return licenseHeader{}, 0, false
+	}
+
+	end, lines := start, 0
+	for end < len(content) {
+		line := content[end:]
+		if newline := strings.IndexByte(line, '\n'); newline >= 0 {
+			line = line[:newline]
+		}
+		if !isLineComment(strings.TrimLeft(line, " \t"), prefix) {
+			break
+		}
+		lines++
+		next := end + len(line) + 1
+		if next > len(content) {
+			// The last line has no newline
+			return licenseHeader{start: start, end: len(content), open: prefix + " "}, lines, true
+		}
+		end = next
+	}
+	// The newline ending the header stays in place after the marker
+	return licenseHeader{start: start, end: end - 1, open: prefix + " "}, lines, true
+}
+
+// isLineComment reports whether line is a comment starting with prefix, like "// text" or
+// "#####", but not a preprocessor directive like "#include"
+func isLineComment(line, prefix string) bool {
+	if !strings.HasPrefix(line, prefix) {
+		return false
+	}
+	rest := line[len(prefix):]
+	return rest == "" || strings.ContainsRune(" \t\r", rune(rest[0])) || rest[0] == prefix[len(prefix)-1] || rest[0] == '!'
+}
+
+// licenseKey returns the text of a header without its comment markers, spacing and years
+func licenseKey(header string) string {
+	var lines []string
+	for _, line := range strings.Split(header, "\n") {
+		line = strings.Trim(line, " \t\r/*#-;%!<>(){}")
+		line = licenseYears.ReplaceAllString(line, "YEAR")
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,110 @@
+package generators
+
+import (
+	"fmt"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apacheHeader = `// Copyright %s The Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+`
+
+func TestFindLicenseHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string // Header found, empty when none
+	}{
+		{
+			name:     "should find line comment headers",
+			content:  "// Copyright 2024 Acme\n// Licensed under the MIT License\n// See LICENSE\n\npackage main\n",
+			expected: "// Copyright 2024 Acme\n// Licensed under the MIT License\n// See LICENSE",
+		},
+		{
+			name:     "should find block comment headers",
+			content:  "/*\n * Copyright 2024 Acme\n * SPDX-License-Identifier: MIT\n */\nint main() {}\n",
+			expected: "/*\n * Copyright 2024 Acme\n * SPDX-License-Identifier: MIT\n */",
+		},
+		{
+			name:     "should skip shebang lines",
+			content:  "#!/usr/bin/env python\n# Copyright 2024 Acme\n# Licensed under the MIT License\n# See LICENSE\nimport os\n",
+			expected: "# Copyright 2024 Acme\n# Licensed under the MIT License\n# See LICENSE",
+		},
+		{
+			name:    "should ignore comments without license",
+			content: "// Package main does things\n// in three\n// lines\npackage main\n",
+		},
+		{
+			name:    "should ignore one-line identifiers",
+			content: "// SPDX-License-Identifier: MIT\npackage main\n",
+		},
+		{
+			name:    "should ignore preprocessor directives",
+			content: "#include <license.h>\n#include <copyright.h>\n#include <stdio.h>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, ok := findLicenseHeader(tt.content)
+			if tt.expected == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, tt.content[header.start:header.end])
+		})
+	}
+}
+
+func TestGenerator_StripLicenseHeaders(t *testing.T) {
+	file := func(path, year, body string) models.FileInfo {
+		return models.FileInfo{Path: path, Name: path, IsText: true, Content: fmt.Sprintf(apacheHeader, year) + body}
+	}
+
+	t.Run("should keep the first header and mark the repeated ones", func(t *testing.T) {
+		files := []models.FileInfo{
+			file("a.go", "2019", "\npackage a\n"),
+			file("b.go", "2020-2024", "\npackage b\n"),
+			{Path: "c.go", Name: "c.go", IsText: true, Content: "package c\n"},
+		}
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{StripLicenses: true})
+		stripped := generator.stripLicenseHeaders(files)
+
+		assert.Equal(t, files[0], stripped[0])
+		assert.Equal(t, "// License header omitted, identical to the one of a.go\n\npackage b\n", stripped[1].Content)
+		assert.Equal(t, files[2], stripped[2])
+		assert.Contains(t, files[1].Content, "Apache License", "the files given are left unchanged")
+	})
+
+	t.Run("should keep distinct licenses", func(t *testing.T) {
+		files := []models.FileInfo{
+			file("a.go", "2024", "package a\n"),
+			{Path: "b.go", Name: "b.go", IsText: true, Content: "// Copyright 2024 Other\n// Licensed under the MIT License\n// See LICENSE\npackage b\n"},
+		}
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{StripLicenses: true})
+		assert.Equal(t, files, generator.stripLicenseHeaders(files))
+	})
+
+	t.Run("should strip headers in outputs when enabled", func(t *testing.T) {
+		result := &models.ProcessingResult{
+			Repository: models.Repository{Name: "repo"},
+			Files:      []models.FileInfo{file("a.go", "2024", "package a\n"), file("b.go", "2024", "package b\n")},
+		}
+
+		output, err := NewGeneratorWithConfig(true, models.OutputConfig{StripLicenses: true, Language: "fr"}).GenerateOutput(result)
+		require.NoError(t, err)
+		assert.Equal(t, "// En-tête de licence omis, identique à celui de a.go\npackage b\n", output.FileContents[1].Content)
+
+		output, err = NewGenerator(true).GenerateOutput(result)
+		require.NoError(t, err)
+		assert.Contains(t, output.FileContents[1].Content, "Apache License")
+	})
+}
@@ -4,39 +4,110 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
 )
 
 // Generator handles the generation of llms-full.txt files
 type Generator struct {
 	includeFullContent bool
+	config             models.OutputConfig
+	clock              utils.Clock
 }
 
 // NewGenerator creates a new LLMs generator
 func NewGenerator(includeFullContent bool) *Generator {
+	return NewGeneratorWithConfig(includeFullContent, models.OutputConfig{})
+}
+
+// NewGeneratorWithConfig creates a new LLMs generator using output settings
+func NewGeneratorWithConfig(includeFullContent bool, config models.OutputConfig) *Generator {
 	return &Generator{
 		includeFullContent: includeFullContent,
+		config:             config,
+		clock:              utils.SystemClock{},
 	}
 }
 
+// SetClock replaces the clock used for the generation timestamp
+func (g *Generator) SetClock(clock utils.Clock) {
+	g.clock = clock
+}
+
+// generated returns the label and value of the generation time header field. Deterministic
+// outputs show the commit the files were read at instead, or nothing when it is unknown,
+// since the time would differ on every run.
+func (g *Generator) generated(at time.Time, commit string) (label, value string) {
+	if !g.config.Deterministic {
+		return g.t(msgGenerated), at.Format(time.RFC3339)
+	}
+	if commit == "" {
+		return "", ""
+	}
+	return g.t(msgCommit), commit
+}
+
+// t returns the localized string for a message key
+func (g *Generator) t(key string, args ...interface{}) string {
+	return translate(g.config.Language, key, args...)
+}
+
 // GenerateOutput generates the LLMs output from processing results
 func (g *Generator) GenerateOutput(result *models.ProcessingResult) (*models.LLMsOutput, error) {
+	// Deterministic outputs have no generation time, and list files in path order whatever
+	// order they were fetched in
+	generatedAt := g.clock.Now()
+	files := result.Files
+	if g.config.Deterministic {
+		generatedAt = time.Time{}
+		files = make([]models.FileInfo, len(result.Files))
+		copy(files, result.Files)
+		sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	}
+	// The first file with a license header keeps it, so headers are stripped once files
+	// are in their final order
+	if g.config.StripLicenses {
+		files = g.stripLicenseHeaders(files)
+	}
+
 	// Build project tree
-	projectTree := g.buildProjectTree(result.Files)
+	projectTree := g.buildProjectTree(files)
+	if g.config.MaxTreeEntries > 0 && !g.config.ExpandTree {
+		projectTree = FoldProjectTree(projectTree, g.config.MaxTreeEntries)
+	}
+	if len(result.Annotations) > 0 {
+		annotateTree(projectTree, annotationsByPath(result.Annotations))
+	}
+
+	// Fall back to the README when the platform has no description
+	if result.Repository.Description == "" {
+		result.Repository.Description = readmeDescription(result.Files)
+	}
 
 	// Prepare output structure
 	output := &models.LLMsOutput{
 		Repository:    result.Repository,
-		GeneratedAt:   time.Now(),
+		GeneratedAt:   generatedAt,
 		TotalFiles:    result.TotalFiles,
 		TotalSize:     result.TotalSize,
 		ProjectTree:   projectTree,
+		Frameworks:    result.Frameworks,
+		Priority:      result.Priority,
+		Summary:       result.Summary,
+		Annotations:   result.Annotations,
 		ConfigFiles:   []models.FileInfo{},
 		Documentation: []models.FileInfo{},
-		FileContents:  result.Files,
+		FileContents:  files,
+		Incomplete:    result.Incomplete,
+		Sample:        result.Sample,
+		Issues:        result.Issues,
+		Releases:      result.Releases,
+		WikiPages:     result.WikiPages,
+		Vendored:      result.Vendored,
 	}
 
 	return output, nil
@@ -46,26 +117,15 @@ func (g *Generator) GenerateOutput(result *models.ProcessingResult) (*models.LLM
 func (g *Generator) GenerateLLMsText(output *models.LLMsOutput) string {
 	var sb strings.Builder
 
-	// Header
-	sb.WriteString(fmt.Sprintf("# Repository: %s\n", output.Repository.Name))
-	sb.WriteString(fmt.Sprintf("# Generated: %s\n", output.GeneratedAt.Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("# Total Files: %d\n", output.TotalFiles))
-	sb.WriteString(fmt.Sprintf("# Total Size: %s\n", formatBytes(output.TotalSize)))
-	sb.WriteString("\n")
-
-	// Repository information
-	sb.WriteString("## Repository Information\n\n")
-	sb.WriteString(fmt.Sprintf("**Name:** %s\n", output.Repository.Name))
-	sb.WriteString(fmt.Sprintf("**Path:** %s\n", output.Repository.PathWithNamespace))
-	sb.WriteString(fmt.Sprintf("**URL:** %s\n", output.Repository.WebURL))
-	if output.Repository.Description != "" {
-		sb.WriteString(fmt.Sprintf("**Description:** %s\n", output.Repository.Description))
-	}
-	sb.WriteString("\n")
+	g.writeHeader(&sb, output)
 
 	// Project Structure
-	sb.WriteString("## Project Structure\n\n")
-	g.writeProjectTreeUnix(&sb, output.ProjectTree)
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgProjectStructure)))
+	if g.config.TreeStyle == models.TreeStylePlain {
+		g.writeProjectTreePlain(&sb, output.ProjectTree)
+	} else {
+		g.writeProjectTreeUnix(&sb, output.ProjectTree)
+	}
 	sb.WriteString("\n")
 
 	return sb.String()
@@ -75,29 +135,107 @@ func (g *Generator) GenerateLLMsText(output *models.LLMsOutput) string {
 func (g *Generator) GenerateLLMsTextWithoutUnixTree(output *models.LLMsOutput) string {
 	var sb strings.Builder
 
+	g.writeHeader(&sb, output)
+
+	// Project Structure (regular format)
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgProjectStructure)))
+	g.writeProjectTree(&sb, output.ProjectTree, "")
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// writeHeader writes the document header and repository information section
+func (g *Generator) writeHeader(sb *strings.Builder, output *models.LLMsOutput) {
+	g.writeHeaderPart(sb, output, 0, 0)
+}
+
+// writeHeaderPart writes the header of a part of a split document; parts is zero when
+// the document is not split
+func (g *Generator) writeHeaderPart(sb *strings.Builder, output *models.LLMsOutput, part, parts int) {
+	// Marker for outputs missing files, so readers do not mistake them for complete ones
+	if output.Incomplete != nil {
+		g.writeIncomplete(sb, output.Incomplete)
+	}
+
+	// Disclaimer block for outputs containing sensitive files
+	if output.Disclaimer != "" {
+		g.writeDisclaimer(sb, output)
+	}
+
 	// Header
-	sb.WriteString(fmt.Sprintf("# Repository: %s\n", output.Repository.Name))
-	sb.WriteString(fmt.Sprintf("# Generated: %s\n", output.GeneratedAt.Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("# Total Files: %d\n", output.TotalFiles))
-	sb.WriteString(fmt.Sprintf("# Total Size: %s\n", formatBytes(output.TotalSize)))
+	sb.WriteString(fmt.Sprintf("# %s: %s\n", g.t(msgRepository), output.Repository.Name))
+	if label, value := g.generated(output.GeneratedAt, output.Commit); label != "" {
+		sb.WriteString(fmt.Sprintf("# %s: %s\n", label, value))
+	}
+	sb.WriteString(fmt.Sprintf("# %s: %d\n", g.t(msgTotalFiles), output.TotalFiles))
+	if output.Sample != nil {
+		sb.WriteString(fmt.Sprintf("# %s: %s\n", g.t(msgSample), g.sample(output.Sample)))
+	}
+	sb.WriteString(fmt.Sprintf("# %s: %s\n", g.t(msgTotalSize), formatBytes(output.TotalSize)))
+	sb.WriteString(fmt.Sprintf("# %s: %d\n", g.t(msgEstimatedTokens), totalTokens(output.FileContents)))
+	if languages := g.languages(output); languages != "" {
+		sb.WriteString(fmt.Sprintf("# %s: %s\n", g.t(msgLanguages), languages))
+	}
+	if len(output.Frameworks) > 0 {
+		sb.WriteString(fmt.Sprintf("# %s: %s\n", g.t(msgBuiltWith), strings.Join(output.Frameworks, ", ")))
+	}
+	if parts > 0 {
+		sb.WriteString(fmt.Sprintf("# %s\n", g.t(msgPart, part, parts)))
+	}
 	sb.WriteString("\n")
 
-	// Repository information
-	sb.WriteString("## Repository Information\n\n")
-	sb.WriteString(fmt.Sprintf("**Name:** %s\n", output.Repository.Name))
-	sb.WriteString(fmt.Sprintf("**Path:** %s\n", output.Repository.PathWithNamespace))
-	sb.WriteString(fmt.Sprintf("**URL:** %s\n", output.Repository.WebURL))
+	g.writeRepositoryInfo(sb, output)
+}
+
+// sample describes the share of files kept by sampling for the header
+func (g *Generator) sample(sample *models.Sample) string {
+	return g.t(msgSampleFiles, sample.Files, sample.Total, strconv.FormatFloat(sample.Percent, 'f', -1, 64)+"%")
+}
+
+// languages renders the languages of the included files for the header
+func (g *Generator) languages(output *models.LLMsOutput) string {
+	return formatLanguages(LanguageStats(output.FileContents), g.t(msgOtherLanguages))
+}
+
+// writeRepositoryInfo writes the repository information section
+func (g *Generator) writeRepositoryInfo(sb *strings.Builder, output *models.LLMsOutput) {
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgRepositoryInfo)))
+	sb.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgName), output.Repository.Name))
+	sb.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgPath), output.Repository.PathWithNamespace))
+	sb.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgURL), output.Repository.WebURL))
 	if output.Repository.Description != "" {
-		sb.WriteString(fmt.Sprintf("**Description:** %s\n", output.Repository.Description))
+		sb.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgDescription), output.Repository.Description))
+	}
+	if output.Summary != "" {
+		sb.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgSummary), strings.TrimSpace(output.Summary)))
 	}
 	sb.WriteString("\n")
+}
 
-	// Project Structure (regular format)
-	sb.WriteString("## Project Structure\n\n")
-	g.writeProjectTree(&sb, output.ProjectTree, "")
+// writeIncomplete writes the incomplete output marker as a quoted block
+func (g *Generator) writeIncomplete(sb *strings.Builder, incomplete *models.Incomplete) {
+	if !incomplete.RateLimitedAt.IsZero() {
+		sb.WriteString(fmt.Sprintf("> %s\n", g.t(msgIncomplete, incomplete.RateLimitedAt.UTC().Format(time.RFC3339), incomplete.Fetched, incomplete.Total)))
+		sb.WriteString(fmt.Sprintf("> %s\n", g.t(msgIncompleteResume)))
+	}
+	if !incomplete.TimedOutAt.IsZero() {
+		sb.WriteString(fmt.Sprintf("> %s\n", g.t(msgTimedOut, incomplete.TimedOutAt.UTC().Format(time.RFC3339), incomplete.Fetched, incomplete.Total)))
+		sb.WriteString(fmt.Sprintf("> %s\n", g.t(msgTimedOutResume)))
+	}
 	sb.WriteString("\n")
+}
 
-	return sb.String()
+// writeDisclaimer writes the sensitive-content disclaimer as a quoted block
+func (g *Generator) writeDisclaimer(sb *strings.Builder, output *models.LLMsOutput) {
+	for _, line := range strings.Split(strings.TrimSpace(output.Disclaimer), "\n") {
+		sb.WriteString(fmt.Sprintf("> %s\n", line))
+	}
+	if len(output.SensitiveFiles) > 0 {
+		sb.WriteString(">\n")
+		sb.WriteString(fmt.Sprintf("> %s\n", g.t(msgSensitiveFiles, len(output.SensitiveFiles))))
+	}
+	sb.WriteString("\n")
 }
 
 // File size constants for security
@@ -109,66 +247,157 @@ const (
 
 // GenerateLLMsFullText generates the complete llms-full.txt content with file contents
 func (g *Generator) GenerateLLMsFullText(output *models.LLMsOutput) string {
-	var sb strings.Builder
+	text, err := g.renderFullText(output)
+	if err != nil {
+		return fmt.Sprintf("## %s: %s\n\n", g.t(msgError), err.Error())
+	}
+	return text.prefix(0, 0) + strings.Join(text.sections, "") + text.suffix
+}
 
+// fullText is llms-full.txt rendered in pieces, so it can be split into self-contained parts
+type fullText struct {
+	prefix   func(part, parts int) string // Header, tree and file contents heading, numbered when split
+	sections []string                     // File contents, one section per file
+	suffix   string                       // Files omitted to fit max_tokens
+}
+
+// renderFullText renders the pieces of llms-full.txt
+func (g *Generator) renderFullText(output *models.LLMsOutput) (*fullText, error) {
 	// Validate total file size before processing
 	if err := g.validateFileSize(output.FileContents); err != nil {
-		sb.WriteString(fmt.Sprintf("## Error: %s\n\n", err.Error()))
-		return sb.String()
+		return nil, err
 	}
 
-	// Include basic structure but with regular tree format (not Unix tree)
-	sb.WriteString(g.GenerateLLMsTextWithoutUnixTree(output))
-
-	// Add file contents section
-	sb.WriteString("## File Contents\n\n")
+	// Include basic structure but with regular tree format (not Unix tree), followed by
+	// the file contents heading
+	text := &fullText{
+		prefix: func(part, parts int) string {
+			var sb strings.Builder
+			g.writeHeaderPart(&sb, output, part, parts)
+			sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgProjectStructure)))
+			g.writeProjectTree(&sb, output.ProjectTree, "")
+			sb.WriteString("\n")
+			// Vendored dependencies, issues, releases and wiki pages are rendered once, in the
+			// first part of split outputs
+			if part <= 1 {
+				g.writeVendored(&sb, output.Vendored)
+				g.writeIssues(&sb, output.Issues)
+				g.writeReleases(&sb, output.Releases)
+				g.writeWiki(&sb, output.WikiPages)
+			}
+			sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgFileContents)))
+			return sb.String()
+		},
+	}
+	prefixTokens := utils.EstimateTokens(text.prefix(0, 0))
 
-	// Sort files by category and name
-	sortedFiles := g.sortFilesByImportance(output.FileContents)
+	sortedFiles := g.orderFiles(output)
+	annotations := annotationsByPath(output.Annotations)
 
+	// Skip directories, binary files and files with errors in the file contents section
+	var files []models.FileInfo
 	for _, file := range sortedFiles {
-		// Skip directories in the file contents section
-		if file.IsDir {
-			continue
+		if !file.IsDir && !file.IsBinary && file.Error == nil {
+			files = append(files, file)
 		}
+	}
 
-		// Skip binary files
-		if file.IsBinary {
-			continue
-		}
+	// Fit file contents into the token budget by outlining or stubbing less relevant files
+	var modes map[string]PackMode
+	if g.config.TokenBudget > 0 {
+		modes = g.packFiles(files, output.Priority, annotations, utils.TokenLimit(g.config.TokenBudget)-prefixTokens)
+	}
 
-		// Skip files with errors
-		if file.Error != nil {
-			continue
-		}
+	render := func(file models.FileInfo) string {
+		var section strings.Builder
+		annotation, annotated := annotations[file.Path]
+		g.writeTextFile(&section, file, annotation, annotated, modes[file.Path])
+		return section.String()
+	}
 
-		// Skip very large files (>5MB)
-		if file.Size > MaxFileSize {
-			sb.WriteString(fmt.Sprintf("### %s\n", file.Path))
-			sb.WriteString(fmt.Sprintf("```\n[File too large to include - %s (max: %s)]\n```\n\n", formatBytes(file.Size), formatBytes(MaxFileSize)))
-			continue
-		}
+	// Cap the output at max_tokens by truncating or dropping the lowest-priority files
+	if g.config.MaxTokens > 0 {
+		sections, omitted := g.limitFiles(files, utils.TokenLimit(g.config.MaxTokens)-prefixTokens, render)
+		var suffix strings.Builder
+		g.writeOmittedFiles(&suffix, omitted)
+		text.sections = sections
+		text.suffix = suffix.String()
+		return text, nil
+	}
 
-		// Add header with warning for large files
-		if file.Size > WarningFileSize {
-			sb.WriteString(fmt.Sprintf("### %s (Large file: %s)\n", file.Path, formatBytes(file.Size)))
-		} else {
-			sb.WriteString(fmt.Sprintf("### %s\n", file.Path))
-		}
+	for _, file := range files {
+		text.sections = append(text.sections, render(file))
+	}
+	return text, nil
+}
+
+// writeTextFile writes the heading and content of a file in llms-full.txt
+func (g *Generator) writeTextFile(sb *strings.Builder, file models.FileInfo, annotation models.Annotation, annotated bool, mode PackMode) {
+	// Classification tags appended to the file heading
+	tags := ""
+	if g.config.FileTags {
+		tags = formatTags(ClassifyFile(file))
+	}
 
-		// Determine file extension for syntax highlighting
-		ext := strings.ToLower(filepath.Ext(file.Path))
-		lang := g.getLanguageFromExtension(ext)
+	// Owner annotations mark the heading and describe the file
+	if annotated && annotation.ReadFirst {
+		tags += fmt.Sprintf(" [%s]", g.t(msgReadFirst))
+	}
 
-		sb.WriteString(fmt.Sprintf("```%s\n", lang))
-		sb.WriteString(file.Content)
-		if !strings.HasSuffix(file.Content, "\n") {
-			sb.WriteString("\n")
-		}
-		sb.WriteString("```\n\n")
+	// Skip very large files (>5MB)
+	if file.Size > MaxFileSize {
+		sb.WriteString(fmt.Sprintf("### %s%s\n", file.Path, tags))
+		sb.WriteString(fmt.Sprintf("```\n[%s]\n```\n\n", g.t(msgFileTooLarge, formatBytes(file.Size), formatBytes(MaxFileSize))))
+		return
 	}
 
-	return sb.String()
+	// Add header with warning for large files
+	if file.Size > WarningFileSize {
+		sb.WriteString(fmt.Sprintf("### %s (%s)%s\n", file.Path, g.t(msgLargeFile, formatBytes(file.Size)), tags))
+	} else {
+		sb.WriteString(fmt.Sprintf("### %s%s\n", file.Path, tags))
+	}
+	if annotated && annotation.Description != "" {
+		sb.WriteString(fmt.Sprintf("> %s\n\n", annotation.Description))
+	}
+	if file.NotFetched {
+		sb.WriteString(fmt.Sprintf("```\n[%s]\n```\n\n", g.t(msgNotFetched)))
+		return
+	}
+
+	content := file.Content
+	switch mode {
+	case PackStub:
+		sb.WriteString(fmt.Sprintf("```\n[%s]\n```\n\n", g.t(msgContentOmitted, utils.EstimateTokens(file.Content))))
+		return
+	case PackOutline:
+		sb.WriteString(fmt.Sprintf("[%s]\n\n", g.t(msgOutlineOnly)))
+		content = outlineContent(file.Content)
+	}
+
+	// Determine file extension for syntax highlighting
+	ext := strings.ToLower(filepath.Ext(file.Path))
+	lang := g.getLanguageFromExtension(ext)
+
+	sb.WriteString(fmt.Sprintf("```%s\n", lang))
+	sb.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("```\n\n")
+}
+
+// orderFiles sorts files for the file contents section: read-first files, then owner
+// priorities, then category and name
+func (g *Generator) orderFiles(output *models.LLMsOutput) []models.FileInfo {
+	sortedFiles := g.sortFilesByImportance(output.FileContents)
+	if len(output.Priority) > 0 {
+		sortedFiles = prioritizeFiles(sortedFiles, output.Priority)
+	}
+	if readFirst := readFirstPatterns(output.Annotations); len(readFirst) > 0 {
+		sortedFiles = prioritizeFiles(sortedFiles, readFirst)
+	}
+	return sortedFiles
 }
 
 // validateFileSize validates that files don't exceed size limits
@@ -243,6 +472,7 @@ func (g *Generator) buildProjectTree(files []models.FileInfo) []models.TreeNode
 
 				if isLastPart && !file.IsDir {
 					newNode.Size = file.Size
+					newNode.Tokens = fileTokens(file)
 				}
 
 				current.Children = append(current.Children, newNode)
@@ -250,6 +480,7 @@ func (g *Generator) buildProjectTree(files []models.FileInfo) []models.TreeNode
 			} else if isLastPart && !file.IsDir {
 				// Update existing node with file info
 				found.Size = file.Size
+				found.Tokens = fileTokens(file)
 				found.IsDir = false
 			}
 
@@ -291,11 +522,17 @@ func (g *Generator) sortTreeNodesRecursive(node *models.TreeNode) {
 // writeProjectTree recursively writes the project tree structure
 func (g *Generator) writeProjectTree(sb *strings.Builder, nodes []models.TreeNode, indent string) {
 	for _, node := range nodes {
-		if node.IsDir {
-			sb.WriteString(fmt.Sprintf("%s%s/\n", indent, node.Name))
+		if node.Folded {
+			sb.WriteString(fmt.Sprintf("%s%s%s\n", indent, g.foldedLabel(node), g.annotationLabel(node)))
+		} else if node.IsDir {
+			sb.WriteString(fmt.Sprintf("%s%s/%s\n", indent, node.Name, g.annotationLabel(node)))
 			g.writeProjectTree(sb, node.Children, indent+"  ")
 		} else {
-			sb.WriteString(fmt.Sprintf("%s%s (%s)\n", indent, node.Name, formatBytes(node.Size)))
+			size := formatBytes(node.Size)
+			if node.Tokens > 0 {
+				size += ", " + g.t(msgTokenCount, node.Tokens)
+			}
+			sb.WriteString(fmt.Sprintf("%s%s (%s)%s\n", indent, node.Name, size, g.annotationLabel(node)))
 		}
 	}
 }
@@ -307,7 +544,7 @@ func (g *Generator) writeProjectTreeUnix(sb *strings.Builder, nodes []models.Tre
 
 	// Count directories and files
 	dirCount, fileCount := g.countDirectoriesAndFiles(nodes)
-	sb.WriteString(fmt.Sprintf("\n%d directories, %d files\n", dirCount, fileCount))
+	sb.WriteString(fmt.Sprintf("\n%s\n", g.t(msgTreeSummary, dirCount, fileCount)))
 }
 
 // writeProjectTreeUnixRecursive recursively writes the Unix-style tree structure
@@ -326,22 +563,55 @@ func (g *Generator) writeProjectTreeUnixRecursive(sb *strings.Builder, nodes []m
 		}
 
 		// Write the current node
-		if node.IsDir {
-			sb.WriteString(fmt.Sprintf("%s%s\n", currentPrefix, node.Name))
+		if node.Folded {
+			sb.WriteString(fmt.Sprintf("%s%s%s\n", currentPrefix, g.foldedLabel(node), g.annotationLabel(node)))
+		} else if node.IsDir {
+			sb.WriteString(fmt.Sprintf("%s%s%s\n", currentPrefix, node.Name, g.annotationLabel(node)))
 			// Recursively write children
 			if len(node.Children) > 0 {
 				g.writeProjectTreeUnixRecursive(sb, node.Children, nextPrefix, false)
 			}
 		} else {
-			sb.WriteString(fmt.Sprintf("%s%s\n", currentPrefix, node.Name))
+			sb.WriteString(fmt.Sprintf("%s%s%s\n", currentPrefix, node.Name, g.annotationLabel(node)))
+		}
+	}
+}
+
+// writeProjectTreePlain writes the project tree using indentation only, without box-drawing characters
+func (g *Generator) writeProjectTreePlain(sb *strings.Builder, nodes []models.TreeNode) {
+	sb.WriteString(".\n")
+	g.writeProjectTreePlainRecursive(sb, nodes, "    ")
+
+	dirCount, fileCount := g.countDirectoriesAndFiles(nodes)
+	sb.WriteString(fmt.Sprintf("\n%s\n", g.t(msgTreeSummary, dirCount, fileCount)))
+}
+
+// writeProjectTreePlainRecursive recursively writes the indentation-only tree structure
+func (g *Generator) writeProjectTreePlainRecursive(sb *strings.Builder, nodes []models.TreeNode, indent string) {
+	for _, node := range nodes {
+		if node.Folded {
+			sb.WriteString(fmt.Sprintf("%s%s%s\n", indent, g.foldedLabel(node), g.annotationLabel(node)))
+		} else if node.IsDir {
+			sb.WriteString(fmt.Sprintf("%s%s/%s\n", indent, node.Name, g.annotationLabel(node)))
+			g.writeProjectTreePlainRecursive(sb, node.Children, indent+"    ")
+		} else {
+			sb.WriteString(fmt.Sprintf("%s%s%s\n", indent, node.Name, g.annotationLabel(node)))
 		}
 	}
 }
 
+// foldedLabel renders the summary line of a folded directory
+func (g *Generator) foldedLabel(node models.TreeNode) string {
+	return fmt.Sprintf("%s/ (%s)", node.Name, g.t(msgFoldedSummary, node.FileCount, formatBytes(node.Size)))
+}
+
 // countDirectoriesAndFiles recursively counts directories and files in the tree
 func (g *Generator) countDirectoriesAndFiles(nodes []models.TreeNode) (dirCount, fileCount int) {
 	for _, node := range nodes {
-		if node.IsDir {
+		if node.Folded {
+			dirCount++
+			fileCount += node.FileCount
+		} else if node.IsDir {
 			dirCount++
 			childDirs, childFiles := g.countDirectoriesAndFiles(node.Children)
 			dirCount += childDirs
@@ -374,44 +644,35 @@ func (g *Generator) sortFilesByImportance(files []models.FileInfo) []models.File
 	return sorted
 }
 
-// getFilePriority returns priority order for file inclusion (lower = higher priority)
-func (g *Generator) getFilePriority(file models.FileInfo) int {
-	fileName := strings.ToLower(filepath.Base(file.Path))
-	filePath := strings.ToLower(file.Path)
-
-	// Highest priority: main files and entry points
-	if strings.Contains(fileName, "main") || strings.Contains(fileName, "index") {
-		return 1
+// prioritizeFiles moves files matching owner priority patterns to the front,
+// in pattern order, keeping the existing order otherwise
+func prioritizeFiles(files []models.FileInfo, patterns []string) []models.FileInfo {
+	matchers := make([]*utils.PatternMatcher, len(patterns))
+	for i, pattern := range patterns {
+		matchers[i] = utils.NewPatternMatcher([]string{pattern}, nil)
 	}
 
-	// High priority: configuration files
-	configExts := []string{".json", ".yaml", ".yml", ".toml", ".env"}
-	for _, ext := range configExts {
-		if strings.HasSuffix(fileName, ext) {
-			return 2
+	rank := func(file models.FileInfo) int {
+		for i, matcher := range matchers {
+			if matcher.ShouldIgnore(file.Path) {
+				return i
+			}
 		}
+		return len(matchers)
 	}
 
-	// Medium-high priority: documentation
-	if strings.HasSuffix(fileName, ".md") || strings.HasPrefix(fileName, "readme") {
-		return 3
-	}
-
-	// Medium priority: source code files
-	codeExts := []string{".go", ".py", ".js", ".ts", ".java", ".c", ".cpp", ".rs", ".rb"}
-	for _, ext := range codeExts {
-		if strings.HasSuffix(fileName, ext) {
-			return 4
-		}
-	}
+	prioritized := make([]models.FileInfo, len(files))
+	copy(prioritized, files)
+	sort.SliceStable(prioritized, func(i, j int) bool {
+		return rank(prioritized[i]) < rank(prioritized[j])
+	})
 
-	// Lower priority: test files
-	if strings.Contains(filePath, "test") || strings.Contains(fileName, "spec") {
-		return 6
-	}
+	return prioritized
+}
 
-	// Lowest priority: everything else
-	return 5
+// getFilePriority returns priority order for file inclusion (lower = higher priority)
+func (g *Generator) getFilePriority(file models.FileInfo) int {
+	return utils.FilePriority(file.Path)
 }
 
 // getLanguageFromExtension returns the language identifier for syntax highlighting
@@ -490,19 +751,7 @@ func (g *Generator) getLanguageFromExtension(ext string) string {
 	return ""
 }
 
-// Helper function to format bytes
+// Helper function to format bytes, in the units selected with utils.SetSIUnits
 func formatBytes(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-
-	units := []string{"KB", "MB", "GB", "TB"}
-	return fmt.Sprintf("%.1f %s", float64(bytes)/float64(div), units[exp])
+	return utils.FormatBytes(bytes)
 }
@@ -1,10 +1,12 @@
 package generators
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -174,3 +176,238 @@ func TestGenerator_GenerateLLMsFullText(t *testing.T) {
 		assert.Contains(t, text, "# Test Repository")
 	})
 }
+
+func TestGenerator_PlainTreeStyle(t *testing.T) {
+	output := &models.LLMsOutput{
+		Repository: models.Repository{Name: "test-repo"},
+		ProjectTree: []models.TreeNode{
+			{
+				Name:  "src",
+				Path:  "src",
+				IsDir: true,
+				Children: []models.TreeNode{
+					{Name: "main.go", Path: "src/main.go", Size: 10},
+				},
+			},
+			{Name: "README.md", Path: "README.md", Size: 5},
+		},
+	}
+
+	t.Run("should render tree without box-drawing characters", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{TreeStyle: models.TreeStylePlain})
+		text := generator.GenerateLLMsText(output)
+
+		assert.Contains(t, text, ".\n    src/\n        main.go\n    README.md\n")
+		assert.Contains(t, text, "1 directories, 2 files")
+		assert.NotContains(t, text, "├")
+		assert.NotContains(t, text, "└")
+		assert.NotContains(t, text, "│")
+	})
+
+	t.Run("should render unix tree by default", func(t *testing.T) {
+		generator := NewGenerator(true)
+		text := generator.GenerateLLMsText(output)
+
+		assert.Contains(t, text, "├── src")
+		assert.Contains(t, text, "└── README.md")
+	})
+}
+
+func TestGenerator_Disclaimer(t *testing.T) {
+	generator := NewGenerator(true)
+
+	t.Run("should prepend disclaimer block", func(t *testing.T) {
+		output := &models.LLMsOutput{
+			Repository:     models.Repository{Name: "test-repo"},
+			Disclaimer:     "Handle with care.",
+			SensitiveFiles: []string{"auth/login.go"},
+		}
+
+		text := generator.GenerateLLMsFullText(output)
+		assert.True(t, strings.HasPrefix(text, "> Handle with care.\n>\n> Sensitive files included: 1\n\n# Repository: test-repo"))
+	})
+
+	t.Run("should omit disclaimer when not set", func(t *testing.T) {
+		output := &models.LLMsOutput{Repository: models.Repository{Name: "test-repo"}}
+
+		text := generator.GenerateLLMsFullText(output)
+		assert.True(t, strings.HasPrefix(text, "# Repository: test-repo"))
+	})
+}
+
+func TestGenerator_Incomplete(t *testing.T) {
+	generator := NewGenerator(true)
+	output := &models.LLMsOutput{
+		Repository: models.Repository{Name: "test-repo"},
+		Incomplete: &models.Incomplete{RateLimitedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), Fetched: 512, Total: 1700},
+	}
+
+	t.Run("should prepend the incomplete marker", func(t *testing.T) {
+		text := generator.GenerateLLMsFullText(output)
+		assert.True(t, strings.HasPrefix(text, "> INCOMPLETE: rate limited at 2024-03-01T12:00:00Z, 512/1700 files\n> Run sherpa again"))
+	})
+
+	t.Run("should prepend the incomplete marker to Markdown outputs", func(t *testing.T) {
+		markdown := generator.GenerateMarkdown(output)
+		assert.True(t, strings.HasPrefix(markdown, "> INCOMPLETE: rate limited at 2024-03-01T12:00:00Z, 512/1700 files\n"))
+	})
+
+	t.Run("should mark runs out of time and stub the files not fetched", func(t *testing.T) {
+		timedOut := &models.LLMsOutput{
+			Repository: models.Repository{Name: "test-repo"},
+			Incomplete: &models.Incomplete{TimedOutAt: time.Date(2024, 3, 1, 12, 4, 30, 0, time.UTC), Fetched: 1, Total: 2},
+			FileContents: []models.FileInfo{
+				{Path: "main.go", Name: "main.go", Content: "package main\n", IsText: true},
+				{Path: "util.go", Name: "util.go", NotFetched: true},
+			},
+		}
+
+		text := generator.GenerateLLMsFullText(timedOut)
+		assert.True(t, strings.HasPrefix(text, "> INCOMPLETE: time budget ran out at 2024-03-01T12:04:30Z, 1/2 files\n> Files not fetched in time are listed as stubs."))
+		assert.NotContains(t, text, "rate limited")
+		assert.Contains(t, text, "### util.go\n```\n[Not fetched: the time budget ran out]\n```\n")
+
+		markdown := generator.GenerateMarkdown(timedOut)
+		assert.Contains(t, markdown, "_Not fetched: the time budget ran out_\n")
+	})
+}
+
+func TestGenerator_BuiltWithHeader(t *testing.T) {
+	generator := NewGenerator(true)
+
+	t.Run("should include detected frameworks in header", func(t *testing.T) {
+		output := &models.LLMsOutput{
+			Repository: models.Repository{Name: "test-repo"},
+			Frameworks: []string{"Django", "Terraform"},
+		}
+
+		text := generator.GenerateLLMsText(output)
+		assert.Contains(t, text, "# Built With: Django, Terraform\n")
+	})
+
+	t.Run("should omit built with line when nothing detected", func(t *testing.T) {
+		output := &models.LLMsOutput{Repository: models.Repository{Name: "test-repo"}}
+
+		text := generator.GenerateLLMsText(output)
+		assert.NotContains(t, text, "Built With")
+	})
+}
+
+func TestGenerator_SampleHeader(t *testing.T) {
+	generator := NewGenerator(true)
+	output := &models.LLMsOutput{
+		Repository: models.Repository{Name: "test-repo"},
+		Sample:     &models.Sample{Percent: 2.5, Files: 42, Total: 1680},
+	}
+
+	t.Run("should report the sample in the header", func(t *testing.T) {
+		assert.Contains(t, generator.GenerateLLMsText(output), "# Sample: 42 of 1680 files (2.5%)\n")
+		assert.Contains(t, generator.GenerateMarkdown(output), "- **Sample:** 42 of 1680 files (2.5%)\n")
+	})
+
+	t.Run("should translate the sample", func(t *testing.T) {
+		japanese := NewGeneratorWithConfig(true, models.OutputConfig{Language: "ja"})
+		assert.Contains(t, japanese.GenerateLLMsText(output), "# サンプル: 1680 ファイル中 42 ファイル (2.5%)\n")
+	})
+}
+
+func TestGenerator_RepoConfig(t *testing.T) {
+	generator := NewGenerator(true)
+
+	t.Run("should render owner summary in repository information", func(t *testing.T) {
+		output := &models.LLMsOutput{
+			Repository: models.Repository{Name: "test-repo"},
+			Summary:    "Payment gateway service.\n",
+		}
+
+		text := generator.GenerateLLMsText(output)
+		assert.Contains(t, text, "**Summary:** Payment gateway service.\n")
+	})
+
+	t.Run("should list priority files first", func(t *testing.T) {
+		output := &models.LLMsOutput{
+			Repository: models.Repository{Name: "test-repo"},
+			Priority:   []string{"docs/ARCHITECTURE.md", "core/"},
+			FileContents: []models.FileInfo{
+				{Path: "main.go", Content: "package main", Size: 12, IsText: true},
+				{Path: "core/engine.go", Content: "package core", Size: 12, IsText: true},
+				{Path: "docs/ARCHITECTURE.md", Content: "# Architecture", Size: 14, IsText: true},
+			},
+		}
+
+		text := generator.GenerateLLMsFullText(output)
+		architecture := strings.Index(text, "### docs/ARCHITECTURE.md")
+		engine := strings.Index(text, "### core/engine.go")
+		mainGo := strings.Index(text, "### main.go")
+		require.True(t, architecture >= 0 && engine >= 0 && mainGo >= 0)
+		assert.Less(t, architecture, engine)
+		assert.Less(t, engine, mainGo)
+	})
+}
+
+func TestGenerator_SetClock(t *testing.T) {
+	t.Run("should stamp outputs with the injected clock", func(t *testing.T) {
+		fixed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		generator := NewGenerator(true)
+		generator.SetClock(utils.FixedClock{Time: fixed})
+
+		output, err := generator.GenerateOutput(&models.ProcessingResult{Repository: models.Repository{Name: "test-repo"}})
+		require.NoError(t, err)
+		assert.Equal(t, fixed, output.GeneratedAt)
+	})
+}
+
+func TestGenerator_Deterministic(t *testing.T) {
+	result := &models.ProcessingResult{
+		Repository: models.Repository{Name: "test-repo"},
+		Files: []models.FileInfo{
+			{Path: "src/main.go", Name: "main.go", Content: "package main", Size: 12},
+			{Path: "go.mod", Name: "go.mod", Content: "module test", Size: 11},
+			{Path: "src/app.go", Name: "app.go", Content: "package main", Size: 12},
+		},
+	}
+
+	t.Run("should show the commit instead of the generation time", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{Deterministic: true})
+		output, err := generator.GenerateOutput(result)
+		require.NoError(t, err)
+		output.Commit = "abc123"
+
+		assert.True(t, output.GeneratedAt.IsZero())
+		text := generator.GenerateLLMsFullText(output)
+		assert.Contains(t, text, "# Commit: abc123\n")
+		assert.NotContains(t, text, "# Generated:")
+		assert.Contains(t, generator.GenerateMarkdown(output), "- **Commit:** abc123\n")
+	})
+
+	t.Run("should leave the header field out when the commit is unknown", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{Deterministic: true})
+		output, err := generator.GenerateOutput(result)
+		require.NoError(t, err)
+
+		text := generator.GenerateLLMsFullText(output)
+		assert.NotContains(t, text, "# Generated:")
+		assert.NotContains(t, text, "# Commit:")
+	})
+
+	t.Run("should produce identical outputs whatever the time and fetch order", func(t *testing.T) {
+		reversed := *result
+		reversed.Files = []models.FileInfo{result.Files[2], result.Files[1], result.Files[0]}
+
+		render := func(result *models.ProcessingResult, at time.Time) (string, string) {
+			generator := NewGeneratorWithConfig(true, models.OutputConfig{Deterministic: true})
+			generator.SetClock(utils.FixedClock{Time: at})
+			output, err := generator.GenerateOutput(result)
+			require.NoError(t, err)
+			treeJSON, err := generator.GenerateTreeJSON(output)
+			require.NoError(t, err)
+			return generator.GenerateLLMsFullText(output), string(treeJSON)
+		}
+
+		firstText, firstTree := render(result, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+		secondText, secondTree := render(&reversed, time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC))
+		assert.Equal(t, firstText, secondText)
+		assert.Equal(t, firstTree, secondTree)
+		assert.NotContains(t, firstTree, "generated_at")
+	})
+}
@@ -0,0 +1,154 @@
+package generators
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// llmsTxtDocExtensions are the documentation formats linked from llms.txt
+var llmsTxtDocExtensions = map[string]bool{".md": true, ".mdx": true, ".rst": true, ".adoc": true}
+
+// llmsTxtSecondaryPrefixes name documentation that is linked in the Optional section, since
+// it can be skipped when the context is short
+var llmsTxtSecondaryPrefixes = []string{"changelog", "contributing", "license", "authors", "code_of_conduct", "security"}
+
+// GenerateLLMsTxt generates llms.txt following the llmstxt.org specification: the repository
+// name as title, its description as a summary blockquote, and sections of links to the
+// README and documentation files. contextFile, when set, is linked in the Optional section.
+// Section names are part of the specification, so they are not localized.
+func (g *Generator) GenerateLLMsTxt(output *models.LLMsOutput, contextFile string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", output.Repository.Name))
+	if description := strings.TrimSpace(output.Repository.Description); description != "" {
+		sb.WriteString(fmt.Sprintf("> %s\n\n", strings.Join(strings.Fields(description), " ")))
+	}
+	if summary := strings.TrimSpace(output.Summary); summary != "" {
+		sb.WriteString(summary + "\n\n")
+	}
+
+	var details []string
+	if output.Repository.WebURL != "" {
+		details = append(details, fmt.Sprintf("- Repository: %s", output.Repository.WebURL))
+	}
+	if languages := g.languages(output); languages != "" {
+		details = append(details, fmt.Sprintf("- Languages: %s", languages))
+	}
+	if len(output.Frameworks) > 0 {
+		details = append(details, fmt.Sprintf("- Built with: %s", strings.Join(output.Frameworks, ", ")))
+	}
+	if len(details) > 0 {
+		sb.WriteString(strings.Join(details, "\n") + "\n\n")
+	}
+
+	docs, secondary := llmsTxtDocs(output)
+	annotations := annotationsByPath(output.Annotations)
+	if len(docs) > 0 {
+		sb.WriteString("## Docs\n\n")
+		for _, file := range docs {
+			sb.WriteString(llmsTxtLink(output, file.Path, annotations[file.Path].Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(secondary) > 0 || contextFile != "" {
+		sb.WriteString("## Optional\n\n")
+		if contextFile != "" {
+			sb.WriteString(fmt.Sprintf("- [%s](%s): Complete repository context with the project tree and file contents\n", contextFile, contextFile))
+		}
+		for _, file := range secondary {
+			sb.WriteString(llmsTxtLink(output, file.Path, annotations[file.Path].Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// llmsTxtDocs returns the documentation files linked from llms.txt: the main documents,
+// root README and read-first files first, and the secondary ones like the license
+func llmsTxtDocs(output *models.LLMsOutput) (docs, secondary []models.FileInfo) {
+	readme, hasReadme := rootReadme(output.FileContents)
+	annotations := annotationsByPath(output.Annotations)
+
+	for _, file := range output.FileContents {
+		if file.IsDir || file.IsBinary || file.Error != nil || !utils.IsDocFile(file.Path) {
+			continue
+		}
+		name := strings.ToLower(file.Name)
+		if name == "" {
+			name = strings.ToLower(filepath.Base(file.Path))
+		}
+		if hasSecondaryPrefix(name) {
+			secondary = append(secondary, file)
+			continue
+		}
+		if strings.HasPrefix(name, "readme") || llmsTxtDocExtensions[strings.ToLower(filepath.Ext(name))] {
+			docs = append(docs, file)
+		}
+	}
+
+	rank := func(file models.FileInfo) int {
+		switch {
+		case hasReadme && file.Path == readme.Path:
+			return 0
+		case annotations[file.Path].ReadFirst:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		if ri, rj := rank(docs[i]), rank(docs[j]); ri != rj {
+			return ri < rj
+		}
+		return docs[i].Path < docs[j].Path
+	})
+	sort.SliceStable(secondary, func(i, j int) bool { return secondary[i].Path < secondary[j].Path })
+	return docs, secondary
+}
+
+// hasSecondaryPrefix reports whether a file name is secondary documentation
+func hasSecondaryPrefix(name string) bool {
+	for _, prefix := range llmsTxtSecondaryPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// llmsTxtLink renders a link list item to a file of the repository, with its annotation
+// as details
+func llmsTxtLink(output *models.LLMsOutput, filePath, description string) string {
+	line := fmt.Sprintf("- [%s](%s)", filePath, fileURL(output.Repository, output.Ref, filePath))
+	if description != "" {
+		line += ": " + description
+	}
+	return line + "\n"
+}
+
+// fileURL returns the web URL of a file of the repository at ref, or the path itself when
+// the repository has no web URL
+func fileURL(repo models.Repository, ref, filePath string) string {
+	if repo.WebURL == "" || repo.Platform == models.PlatformLocal {
+		return filePath
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	base := strings.TrimSuffix(repo.WebURL, "/")
+	switch repo.Platform {
+	case models.PlatformGitLab:
+		return fmt.Sprintf("%s/-/blob/%s/%s", base, ref, filePath)
+	case models.PlatformGitea:
+		return fmt.Sprintf("%s/src/%s/%s", base, ref, filePath)
+	default:
+		return fmt.Sprintf("%s/blob/%s/%s", base, ref, filePath)
+	}
+}
@@ -0,0 +1,81 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_GenerateLLMsTxt(t *testing.T) {
+	result := &models.ProcessingResult{
+		Repository: models.Repository{
+			Name:              "repo",
+			PathWithNamespace: "owner/repo",
+			WebURL:            "https://github.com/owner/repo",
+			Description:       "A tool\nfor things",
+			Platform:          models.PlatformGitHub,
+		},
+		Summary: "Start with the guide.",
+		Files: []models.FileInfo{
+			{Path: "LICENSE", Name: "LICENSE", Content: "MIT", IsText: true},
+			{Path: "README.md", Name: "README.md", Content: "# Repo\n", IsText: true},
+			{Path: "docs/api.md", Name: "api.md", Content: "# API\n", IsText: true},
+			{Path: "docs/guide.md", Name: "guide.md", Content: "# Guide\n", IsText: true},
+			{Path: "docs/diagram.png", Name: "diagram.png", IsBinary: true},
+			{Path: "main.go", Name: "main.go", Content: "package main\n", IsText: true},
+			{Path: "requirements.txt", Name: "requirements.txt", Content: "requests\n", IsText: true},
+		},
+		Annotations: []models.Annotation{{Path: "docs/guide.md", Description: "How to get started", ReadFirst: true}},
+	}
+	generator := NewGenerator(true)
+	output, err := generator.GenerateOutput(result)
+	require.NoError(t, err)
+	output.Ref = "ab12cd3"
+
+	text := generator.GenerateLLMsTxt(output, "llms-full.txt")
+
+	t.Run("should start with the title and summary blockquote", func(t *testing.T) {
+		assert.Equal(t, "# repo\n\n> A tool for things\n\nStart with the guide.\n\n- Repository: https://github.com/owner/repo\n", text[:strings.Index(text, "- Languages")])
+	})
+
+	t.Run("should link the README first, then read-first files and other documents", func(t *testing.T) {
+		assert.Contains(t, text, "## Docs\n\n"+
+			"- [README.md](https://github.com/owner/repo/blob/ab12cd3/README.md)\n"+
+			"- [docs/guide.md](https://github.com/owner/repo/blob/ab12cd3/docs/guide.md): How to get started\n"+
+			"- [docs/api.md](https://github.com/owner/repo/blob/ab12cd3/docs/api.md)\n\n")
+		assert.NotContains(t, text, "main.go")
+		assert.NotContains(t, text, "requirements.txt")
+		assert.NotContains(t, text, "diagram.png")
+	})
+
+	t.Run("should link the context file and secondary documents as optional", func(t *testing.T) {
+		assert.Contains(t, text, "## Optional\n\n"+
+			"- [llms-full.txt](llms-full.txt): Complete repository context with the project tree and file contents\n"+
+			"- [LICENSE](https://github.com/owner/repo/blob/ab12cd3/LICENSE)\n")
+	})
+}
+
+func TestFileURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     models.Repository
+		ref      string
+		expected string
+	}{
+		{"github", models.Repository{WebURL: "https://github.com/o/r", Platform: models.PlatformGitHub}, "main", "https://github.com/o/r/blob/main/docs/a.md"},
+		{"gitlab", models.Repository{WebURL: "https://gitlab.com/g/r", Platform: models.PlatformGitLab}, "main", "https://gitlab.com/g/r/-/blob/main/docs/a.md"},
+		{"gitea", models.Repository{WebURL: "https://codeberg.org/o/r", Platform: models.PlatformGitea}, "main", "https://codeberg.org/o/r/src/main/docs/a.md"},
+		{"default branch", models.Repository{WebURL: "https://github.com/o/r", Platform: models.PlatformGitHub}, "", "https://github.com/o/r/blob/HEAD/docs/a.md"},
+		{"local folder", models.Repository{WebURL: "file:///src/app", Platform: models.PlatformLocal}, "", "docs/a.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fileURL(tt.repo, tt.ref, "docs/a.md"))
+		})
+	}
+}
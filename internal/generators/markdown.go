@@ -0,0 +1,273 @@
+package generators
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// Anchors of the document sections, kept in English so links survive localization
+const (
+	anchorRepositoryInfo   = "repository-information"
+	anchorProjectStructure = "project-structure"
+	anchorVendored         = "vendored-dependencies"
+	anchorIssues           = "issues"
+	anchorReleases         = "releases"
+	anchorWiki             = "wiki"
+	anchorFileContents     = "file-contents"
+)
+
+var (
+	// anchorUnsafePattern matches runs of characters not allowed in generated anchors
+	anchorUnsafePattern = regexp.MustCompile(`[^a-z0-9]+`)
+	// backtickRunPattern matches runs of backticks that would close a code fence
+	backtickRunPattern = regexp.MustCompile("`{3,}")
+)
+
+// GenerateMarkdown generates llms-full.md: the content of llms-full.txt with a linked table
+// of contents, an anchor per file and file contents in collapsible sections
+func (g *Generator) GenerateMarkdown(output *models.LLMsOutput) string {
+	var sb strings.Builder
+
+	if err := g.validateFileSize(output.FileContents); err != nil {
+		sb.WriteString(fmt.Sprintf("## %s: %s\n\n", g.t(msgError), err.Error()))
+		return sb.String()
+	}
+
+	sortedFiles := g.orderFiles(output)
+	var files []models.FileInfo
+	for _, file := range sortedFiles {
+		if !file.IsDir && !file.IsBinary && file.Error == nil {
+			files = append(files, file)
+		}
+	}
+	anchors := fileAnchors(files)
+	annotations := annotationsByPath(output.Annotations)
+
+	g.writeMarkdownPrefix(&sb, output, files, anchors)
+
+	var modes map[string]PackMode
+	if g.config.TokenBudget > 0 {
+		modes = g.packFiles(files, output.Priority, annotations, utils.TokenLimit(g.config.TokenBudget)-utils.EstimateTokens(sb.String()))
+	}
+
+	// Cap the output at max_tokens by truncating or dropping the lowest-priority files
+	if g.config.MaxTokens > 0 {
+		sections, omitted := g.limitFiles(files, utils.TokenLimit(g.config.MaxTokens)-utils.EstimateTokens(sb.String()), func(file models.FileInfo) string {
+			var section strings.Builder
+			annotation, annotated := annotations[file.Path]
+			g.writeMarkdownFile(&section, file, anchors[file.Path], annotation, annotated, modes[file.Path])
+			return section.String()
+		})
+
+		// Link only the files left in the table of contents
+		if len(omitted) > 0 {
+			dropped := make(map[string]bool, len(omitted))
+			for _, file := range omitted {
+				dropped[file.Path] = true
+			}
+			var kept []models.FileInfo
+			for _, file := range files {
+				if !dropped[file.Path] {
+					kept = append(kept, file)
+				}
+			}
+			sb.Reset()
+			g.writeMarkdownPrefix(&sb, output, kept, anchors)
+		}
+
+		for _, section := range sections {
+			sb.WriteString(section)
+		}
+		g.writeOmittedFiles(&sb, omitted)
+		return sb.String()
+	}
+
+	for _, file := range files {
+		annotation, annotated := annotations[file.Path]
+		g.writeMarkdownFile(&sb, file, anchors[file.Path], annotation, annotated, modes[file.Path])
+	}
+
+	return sb.String()
+}
+
+// writeMarkdownPrefix writes everything up to the file contents heading, linking files in
+// the table of contents
+func (g *Generator) writeMarkdownPrefix(sb *strings.Builder, output *models.LLMsOutput, files []models.FileInfo, anchors map[string]string) {
+	if output.Incomplete != nil {
+		g.writeIncomplete(sb, output.Incomplete)
+	}
+	if output.Disclaimer != "" {
+		g.writeDisclaimer(sb, output)
+	}
+	g.writeMarkdownHeader(sb, output)
+	g.writeMarkdownTOC(sb, output, files, anchors)
+
+	sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchorRepositoryInfo))
+	g.writeRepositoryInfo(sb, output)
+
+	sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchorProjectStructure))
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgProjectStructure)))
+	sb.WriteString("```\n")
+	if g.config.TreeStyle == models.TreeStylePlain {
+		g.writeProjectTreePlain(sb, output.ProjectTree)
+	} else {
+		g.writeProjectTreeUnix(sb, output.ProjectTree)
+	}
+	sb.WriteString("```\n\n")
+
+	if len(output.Vendored) > 0 {
+		sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchorVendored))
+		g.writeVendored(sb, output.Vendored)
+	}
+	if len(output.Issues) > 0 {
+		sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchorIssues))
+		g.writeIssues(sb, output.Issues)
+	}
+	if len(output.Releases) > 0 {
+		sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchorReleases))
+		g.writeReleases(sb, output.Releases)
+	}
+	if len(output.WikiPages) > 0 {
+		sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchorWiki))
+		g.writeWiki(sb, output.WikiPages)
+	}
+
+	sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchorFileContents))
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgFileContents)))
+}
+
+// writeMarkdownHeader writes the document title and generation metadata
+func (g *Generator) writeMarkdownHeader(sb *strings.Builder, output *models.LLMsOutput) {
+	sb.WriteString(fmt.Sprintf("# %s: %s\n\n", g.t(msgRepository), output.Repository.Name))
+	if label, value := g.generated(output.GeneratedAt, output.Commit); label != "" {
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", label, value))
+	}
+	sb.WriteString(fmt.Sprintf("- **%s:** %d\n", g.t(msgTotalFiles), output.TotalFiles))
+	if output.Sample != nil {
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", g.t(msgSample), g.sample(output.Sample)))
+	}
+	sb.WriteString(fmt.Sprintf("- **%s:** %s\n", g.t(msgTotalSize), formatBytes(output.TotalSize)))
+	sb.WriteString(fmt.Sprintf("- **%s:** %d\n", g.t(msgEstimatedTokens), totalTokens(output.FileContents)))
+	if languages := g.languages(output); languages != "" {
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", g.t(msgLanguages), languages))
+	}
+	if len(output.Frameworks) > 0 {
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", g.t(msgBuiltWith), strings.Join(output.Frameworks, ", ")))
+	}
+	sb.WriteString("\n")
+}
+
+// writeMarkdownTOC writes the table of contents linking sections and files
+func (g *Generator) writeMarkdownTOC(sb *strings.Builder, output *models.LLMsOutput, files []models.FileInfo, anchors map[string]string) {
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgTableOfContents)))
+	sb.WriteString(fmt.Sprintf("- [%s](#%s)\n", g.t(msgRepositoryInfo), anchorRepositoryInfo))
+	sb.WriteString(fmt.Sprintf("- [%s](#%s)\n", g.t(msgProjectStructure), anchorProjectStructure))
+	if len(output.Vendored) > 0 {
+		sb.WriteString(fmt.Sprintf("- [%s](#%s)\n", g.t(msgVendored), anchorVendored))
+	}
+	if len(output.Issues) > 0 {
+		sb.WriteString(fmt.Sprintf("- [%s](#%s)\n", g.t(msgIssues), anchorIssues))
+	}
+	if len(output.Releases) > 0 {
+		sb.WriteString(fmt.Sprintf("- [%s](#%s)\n", g.t(msgReleases), anchorReleases))
+	}
+	if len(output.WikiPages) > 0 {
+		sb.WriteString(fmt.Sprintf("- [%s](#%s)\n", g.t(msgWiki), anchorWiki))
+	}
+	sb.WriteString(fmt.Sprintf("- [%s](#%s)\n", g.t(msgFileContents), anchorFileContents))
+	for _, file := range files {
+		sb.WriteString(fmt.Sprintf("  - [`%s`](#%s)\n", file.Path, anchors[file.Path]))
+	}
+	sb.WriteString("\n")
+}
+
+// writeMarkdownFile writes a file heading followed by its content in a collapsible section.
+// Read-first files are expanded by default.
+func (g *Generator) writeMarkdownFile(sb *strings.Builder, file models.FileInfo, anchor string, annotation models.Annotation, annotated bool, mode PackMode) {
+	tags := ""
+	if g.config.FileTags {
+		tags = formatTags(ClassifyFile(file))
+	}
+	if annotated && annotation.ReadFirst {
+		tags += fmt.Sprintf(" [%s]", g.t(msgReadFirst))
+	}
+
+	sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchor))
+	sb.WriteString(fmt.Sprintf("### `%s`%s\n\n", file.Path, tags))
+	if annotated && annotation.Description != "" {
+		sb.WriteString(fmt.Sprintf("> %s\n\n", annotation.Description))
+	}
+
+	if file.Size > MaxFileSize {
+		sb.WriteString(fmt.Sprintf("_%s_\n\n", g.t(msgFileTooLarge, formatBytes(file.Size), formatBytes(MaxFileSize))))
+		return
+	}
+	if file.NotFetched {
+		sb.WriteString(fmt.Sprintf("_%s_\n\n", g.t(msgNotFetched)))
+		return
+	}
+
+	content := file.Content
+	switch mode {
+	case PackStub:
+		sb.WriteString(fmt.Sprintf("_%s_\n\n", g.t(msgContentOmitted, utils.EstimateTokens(file.Content))))
+		return
+	case PackOutline:
+		sb.WriteString(fmt.Sprintf("_%s_\n\n", g.t(msgOutlineOnly)))
+		content = outlineContent(file.Content)
+	}
+
+	summary := formatBytes(file.Size)
+	if file.Size > WarningFileSize {
+		summary = g.t(msgLargeFile, formatBytes(file.Size))
+	}
+
+	if annotated && annotation.ReadFirst {
+		sb.WriteString("<details open>\n")
+	} else {
+		sb.WriteString("<details>\n")
+	}
+	sb.WriteString(fmt.Sprintf("<summary>%s (%s)</summary>\n\n", filepath.Base(file.Path), summary))
+
+	fence := codeFence(content)
+	lang := g.getLanguageFromExtension(strings.ToLower(filepath.Ext(file.Path)))
+	sb.WriteString(fmt.Sprintf("%s%s\n", fence, lang))
+	sb.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("%s\n\n</details>\n\n", fence))
+}
+
+// fileAnchors returns a unique anchor for each file path, numbering paths that map to
+// the same anchor
+func fileAnchors(files []models.FileInfo) map[string]string {
+	anchors := make(map[string]string, len(files))
+	used := make(map[string]bool, len(files))
+	for _, file := range files {
+		base := "file-" + strings.Trim(anchorUnsafePattern.ReplaceAllString(strings.ToLower(file.Path), "-"), "-")
+		anchor := base
+		for n := 2; used[anchor]; n++ {
+			anchor = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[anchor] = true
+		anchors[file.Path] = anchor
+	}
+	return anchors
+}
+
+// codeFence returns a backtick fence longer than any backtick run in the content
+func codeFence(content string) string {
+	fence := "```"
+	for _, run := range backtickRunPattern.FindAllString(content, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+	return fence
+}
@@ -0,0 +1,87 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_GenerateMarkdown(t *testing.T) {
+	generator := NewGenerator(true)
+
+	result := &models.ProcessingResult{
+		Repository: models.Repository{Name: "repo", PathWithNamespace: "owner/repo"},
+		Files: []models.FileInfo{
+			{Path: "cmd/main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+			{Path: "docs/GUIDE.md", Name: "GUIDE.md", Content: "# Guide\n\n```go\nfmt.Println()\n```\n", Size: 34, IsText: true},
+			{Path: "logo.png", Name: "logo.png", Size: 100, IsBinary: true},
+		},
+		Annotations: []models.Annotation{{Path: "docs/GUIDE.md", Description: "Start here", ReadFirst: true}},
+	}
+
+	output, err := generator.GenerateOutput(result)
+	require.NoError(t, err)
+	markdown := generator.GenerateMarkdown(output)
+
+	t.Run("should link sections and files from the table of contents", func(t *testing.T) {
+		assert.Contains(t, markdown, "## Table of Contents\n")
+		assert.Contains(t, markdown, "- [Project Structure](#project-structure)\n")
+		assert.Contains(t, markdown, "  - [`cmd/main.go`](#file-cmd-main-go)\n")
+		assert.Contains(t, markdown, "<a id=\"file-cmd-main-go\"></a>\n\n### `cmd/main.go`\n")
+		assert.NotContains(t, markdown, "logo.png`")
+	})
+
+	t.Run("should render file contents in collapsible sections", func(t *testing.T) {
+		assert.Contains(t, markdown, "<details>\n<summary>main.go (12 B)</summary>\n\n```go\npackage main\n```\n\n</details>\n")
+	})
+
+	t.Run("should expand read-first files", func(t *testing.T) {
+		assert.Contains(t, markdown, "### `docs/GUIDE.md` [read first]\n\n> Start here\n\n<details open>\n")
+	})
+
+	t.Run("should fence contents containing code fences", func(t *testing.T) {
+		assert.Contains(t, markdown, "````markdown\n# Guide")
+	})
+
+	t.Run("should list read-first files before others", func(t *testing.T) {
+		assert.Less(t, strings.Index(markdown, "### `docs/GUIDE.md`"), strings.Index(markdown, "### `cmd/main.go`"))
+	})
+
+	t.Run("should render the tree in a code block", func(t *testing.T) {
+		assert.Contains(t, markdown, "## Project Structure\n\n```\n.\n")
+	})
+}
+
+func TestFileAnchors(t *testing.T) {
+	anchors := fileAnchors([]models.FileInfo{
+		{Path: "src/App.tsx"},
+		{Path: "src/app.tsx"},
+		{Path: "__init__.py"},
+	})
+
+	assert.Equal(t, "file-src-app-tsx", anchors["src/App.tsx"])
+	assert.Equal(t, "file-src-app-tsx-2", anchors["src/app.tsx"])
+	assert.Equal(t, "file-init-py", anchors["__init__.py"])
+}
+
+func TestCodeFence(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "should use three backticks by default", content: "package main", expected: "```"},
+		{name: "should outgrow fences in the content", content: "```go\n```", expected: "````"},
+		{name: "should outgrow the longest fence", content: "````\n`````", expected: "``````"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, codeFence(tt.content))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package generators
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// declarationPattern matches top-level or once-indented declarations in common languages
+	declarationPattern = regexp.MustCompile(`^(?:\t|  |    )?(?:(?:export|default|public|private|protected|internal|static|abstract|final|async|pub(?:\([a-z]+\))?|unsafe|extern)\s+)*(?:package|import|from|func|type|class|interface|struct|enum|trait|impl|mod|module|def|fn|function|const|var|let|namespace|object|record|protocol|extension)\b`)
+	// headingPattern matches markdown headings
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+\S`)
+)
+
+// outlineContent keeps the declaration lines and markdown headings of a file, returning
+// an empty string when nothing looks like a declaration
+func outlineContent(content string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		if declarationPattern.MatchString(line) || headingPattern.MatchString(line) {
+			sb.WriteString(strings.TrimRight(line, " \t\r"))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
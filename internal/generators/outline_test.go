@@ -0,0 +1,24 @@
+package generators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutlineContent(t *testing.T) {
+	t.Run("should keep declarations and drop bodies", func(t *testing.T) {
+		content := "package greet\n\nimport \"fmt\"\n\n// Hello greets\nfunc Hello(name string) string {\n\treturn fmt.Sprint(name)\n}\n\ntype Greeter struct {\n\tName string\n}\n"
+
+		outline := outlineContent(content)
+		assert.Equal(t, "package greet\nimport \"fmt\"\nfunc Hello(name string) string {\ntype Greeter struct {\n", outline)
+	})
+
+	t.Run("should keep markdown headings", func(t *testing.T) {
+		assert.Equal(t, "# Title\n## Usage\n", outlineContent("# Title\n\nSome text\n\n## Usage\nMore text\n"))
+	})
+
+	t.Run("should return empty when nothing looks like a declaration", func(t *testing.T) {
+		assert.Empty(t, outlineContent("just some text\nwithout structure\n"))
+	})
+}
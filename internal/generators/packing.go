@@ -0,0 +1,267 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// PackMode is how a file is rendered when outputs are packed into a token budget
+type PackMode string
+
+// Packing modes, from most to least content
+const (
+	PackFull    PackMode = "full"    // Complete file content
+	PackOutline PackMode = "outline" // Declarations only
+	PackStub    PackMode = "stub"    // Heading and a note, without content
+)
+
+// Share of a file's value kept by its reduced renderings
+const (
+	outlineValueRatio = 0.4
+	stubValueRatio    = 0.02
+)
+
+// maxKnapsackCells bounds the dynamic programming table used by knapsack refinement
+const maxKnapsackCells = 4_000_000
+
+// knapsackResolution is the number of capacity steps the budget is quantized into
+const knapsackResolution = 4096
+
+// PackItem describes a file competing for space in the token budget
+type PackItem struct {
+	Path          string
+	Value         float64 // Relevance of the complete file
+	FullTokens    int
+	OutlineTokens int // 0 when the file has no outline
+	StubTokens    int
+}
+
+// packOption is one way of rendering an item
+type packOption struct {
+	mode   PackMode
+	tokens int
+	value  float64
+}
+
+// options returns the renderings available for an item, stub first
+func (item PackItem) options() []packOption {
+	options := []packOption{{mode: PackStub, tokens: item.StubTokens, value: item.Value * stubValueRatio}}
+	if item.OutlineTokens > 0 && item.OutlineTokens < item.FullTokens {
+		options = append(options, packOption{mode: PackOutline, tokens: item.OutlineTokens, value: item.Value * outlineValueRatio})
+	}
+	return append(options, packOption{mode: PackFull, tokens: item.FullTokens, value: item.Value})
+}
+
+// PackFiles chooses how to render each file so the total stays within budget tokens while
+// maximizing relevance. Every file gets at least a stub, so the budget can only be exceeded
+// when stubs alone do not fit. The knapsack strategy refines the greedy result when it
+// finds a better packing.
+func PackFiles(items []PackItem, budget int, strategy string) map[string]PackMode {
+	modes := packGreedy(items, budget)
+	if strategy == models.PackingKnapsack {
+		if refined, ok := packKnapsack(items, budget); ok && packValue(items, refined) > packValue(items, modes) {
+			modes = refined
+		}
+	}
+
+	result := make(map[string]PackMode, len(items))
+	for i, item := range items {
+		result[item.Path] = modes[i]
+	}
+	return result
+}
+
+// packGreedy starts from stubs and upgrades files by value density, taking the full
+// content when it fits and falling back to the outline otherwise
+func packGreedy(items []PackItem, budget int) []PackMode {
+	modes := make([]PackMode, len(items))
+	used := 0
+	for i, item := range items {
+		modes[i] = PackStub
+		used += item.StubTokens
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return density(items[order[a]]) > density(items[order[b]])
+	})
+
+	for _, i := range order {
+		item := items[i]
+		for _, option := range reverse(item.options()) {
+			if option.mode == PackStub {
+				break
+			}
+			extra := option.tokens - item.StubTokens
+			if used+extra <= budget {
+				modes[i] = option.mode
+				used += extra
+				break
+			}
+		}
+	}
+
+	return modes
+}
+
+// packKnapsack solves the multiple-choice knapsack on a quantized budget. It reports false
+// when the problem is too large or no packing fits.
+func packKnapsack(items []PackItem, budget int) ([]PackMode, bool) {
+	if len(items) == 0 || budget <= 0 {
+		return nil, false
+	}
+
+	// Round weights up so quantized solutions never exceed the real budget
+	unit := (budget + knapsackResolution - 1) / knapsackResolution
+	capacity := budget / unit
+	if len(items)*(capacity+1) > maxKnapsackCells {
+		return nil, false
+	}
+
+	weight := func(tokens int) int {
+		return (tokens + unit - 1) / unit
+	}
+
+	best := make([]float64, capacity+1)
+	next := make([]float64, capacity+1)
+	choices := make([][]int8, len(items))
+
+	for i, item := range items {
+		options := item.options()
+		choices[i] = make([]int8, capacity+1)
+		for c := 0; c <= capacity; c++ {
+			next[c] = math.Inf(-1)
+			choices[i][c] = -1
+			for o, option := range options {
+				w := weight(option.tokens)
+				if w > c || math.IsInf(prevValue(best, i, c-w), -1) {
+					continue
+				}
+				if value := prevValue(best, i, c-w) + option.value; value > next[c] {
+					next[c] = value
+					choices[i][c] = int8(o)
+				}
+			}
+		}
+		best, next = next, best
+	}
+
+	if math.IsInf(best[capacity], -1) {
+		return nil, false
+	}
+
+	modes := make([]PackMode, len(items))
+	c := capacity
+	for i := len(items) - 1; i >= 0; i-- {
+		option := items[i].options()[choices[i][c]]
+		modes[i] = option.mode
+		c -= weight(option.tokens)
+	}
+	return modes, true
+}
+
+// prevValue returns the best value of the previous row, where the empty prefix is worth 0
+func prevValue(best []float64, i, c int) float64 {
+	if i == 0 {
+		return 0
+	}
+	return best[c]
+}
+
+// packValue returns the total value of a packing
+func packValue(items []PackItem, modes []PackMode) float64 {
+	total := 0.0
+	for i, item := range items {
+		for _, option := range item.options() {
+			if option.mode == modes[i] {
+				total += option.value
+			}
+		}
+	}
+	return total
+}
+
+// density returns the value per token of the complete file
+func density(item PackItem) float64 {
+	if item.FullTokens <= 0 {
+		return item.Value
+	}
+	return item.Value / float64(item.FullTokens)
+}
+
+// reverse returns options from most to least content
+func reverse(options []packOption) []packOption {
+	reversed := make([]packOption, len(options))
+	for i, option := range options {
+		reversed[len(options)-1-i] = option
+	}
+	return reversed
+}
+
+// packFiles estimates the token cost and relevance of each rendered file and chooses
+// how to render them within budget tokens
+func (g *Generator) packFiles(files []models.FileInfo, priority []string, annotations map[string]models.Annotation, budget int) map[string]PackMode {
+	priorityMatcher := utils.NewPatternMatcher(priority, nil)
+
+	var items []PackItem
+	for _, file := range files {
+		if file.IsDir || file.IsBinary || file.Error != nil || file.Size > MaxFileSize {
+			continue
+		}
+
+		// Headings, fences and descriptions are rendered whatever the mode
+		overhead := utils.EstimateTokens(fmt.Sprintf("### %s\n```\n```\n\n", file.Path))
+		annotation, annotated := annotations[file.Path]
+		if annotated {
+			overhead += utils.EstimateTokens(annotation.Description)
+		}
+
+		item := PackItem{
+			Path:       file.Path,
+			Value:      g.fileValue(file, annotated && annotation.ReadFirst, priorityMatcher.ShouldIgnore(file.Path)),
+			FullTokens: overhead + utils.EstimateTokens(file.Content),
+			StubTokens: overhead + utils.EstimateTokens(g.t(msgContentOmitted, utils.EstimateTokens(file.Content))),
+		}
+		if outline := outlineContent(file.Content); outline != "" {
+			item.OutlineTokens = overhead + utils.EstimateTokens(g.t(msgOutlineOnly)) + utils.EstimateTokens(outline)
+		}
+		items = append(items, item)
+	}
+
+	return PackFiles(items, budget, g.config.Packing)
+}
+
+// fileValue estimates how relevant a file is to a reader of the generated context
+func (g *Generator) fileValue(file models.FileInfo, readFirst, prioritized bool) float64 {
+	var value float64
+	switch g.getFilePriority(file) {
+	case 1:
+		value = 3.0 // Entry points
+	case 2, 3:
+		value = 1.5 // Configuration and documentation
+	case 4:
+		value = 1.0 // Source code
+	case 6:
+		value = 0.4 // Tests
+	default:
+		value = 0.6
+	}
+
+	if isGeneratedFile(file.Path, file.Content) {
+		value *= 0.1
+	}
+	if prioritized {
+		value *= 3
+	}
+	if readFirst {
+		value *= 5
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackFiles(t *testing.T) {
+	t.Run("should include every file in full when the budget allows", func(t *testing.T) {
+		items := []PackItem{
+			{Path: "a.go", Value: 1, FullTokens: 40, StubTokens: 5},
+			{Path: "b.go", Value: 1, FullTokens: 40, StubTokens: 5},
+		}
+
+		modes := PackFiles(items, 100, models.PackingGreedy)
+		assert.Equal(t, map[string]PackMode{"a.go": PackFull, "b.go": PackFull}, modes)
+	})
+
+	t.Run("should fall back to outlines when full content does not fit", func(t *testing.T) {
+		items := []PackItem{
+			{Path: "main.go", Value: 3, FullTokens: 60, OutlineTokens: 15, StubTokens: 5},
+			{Path: "util.go", Value: 1, FullTokens: 80, OutlineTokens: 20, StubTokens: 5},
+		}
+
+		modes := PackFiles(items, 90, models.PackingGreedy)
+		assert.Equal(t, PackFull, modes["main.go"])
+		assert.Equal(t, PackOutline, modes["util.go"])
+	})
+
+	t.Run("should stub files when nothing else fits", func(t *testing.T) {
+		items := []PackItem{
+			{Path: "big.go", Value: 1, FullTokens: 1000, OutlineTokens: 500, StubTokens: 5},
+		}
+
+		modes := PackFiles(items, 10, models.PackingGreedy)
+		assert.Equal(t, PackStub, modes["big.go"])
+	})
+
+	t.Run("should prefer denser files greedily", func(t *testing.T) {
+		items := []PackItem{
+			{Path: "large.go", Value: 2, FullTokens: 100},
+			{Path: "small.go", Value: 1, FullTokens: 10},
+		}
+
+		modes := PackFiles(items, 100, models.PackingGreedy)
+		assert.Equal(t, PackFull, modes["small.go"])
+		assert.Equal(t, PackStub, modes["large.go"])
+	})
+
+	t.Run("should improve on greedy with knapsack refinement", func(t *testing.T) {
+		items := []PackItem{
+			{Path: "a.go", Value: 10, FullTokens: 51},
+			{Path: "b.go", Value: 9, FullTokens: 50},
+			{Path: "c.go", Value: 9, FullTokens: 50},
+		}
+
+		greedy := PackFiles(items, 100, models.PackingGreedy)
+		assert.Equal(t, PackFull, greedy["a.go"])
+
+		knapsack := PackFiles(items, 100, models.PackingKnapsack)
+		assert.Equal(t, map[string]PackMode{"a.go": PackStub, "b.go": PackFull, "c.go": PackFull}, knapsack)
+	})
+}
+
+func TestGenerator_TokenBudget(t *testing.T) {
+	body := strings.Repeat("\tx := compute()\n", 200)
+	output := &models.LLMsOutput{
+		Repository: models.Repository{Name: "repo", PathWithNamespace: "owner/repo"},
+		FileContents: []models.FileInfo{
+			{Path: "main.go", Content: "package main\n\nfunc main() {}\n", Size: 30},
+			{Path: "pkg/heavy.go", Content: "package pkg\n\nfunc Heavy() {\n" + body + "}\n", Size: int64(len(body) + 30)},
+		},
+	}
+
+	t.Run("should outline files that do not fit the budget", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{TokenBudget: 300, Packing: models.PackingGreedy})
+		content := generator.GenerateLLMsFullText(output)
+
+		assert.Contains(t, content, "func main() {}")
+		assert.Contains(t, content, "[Outline only, full content omitted to fit the token budget]")
+		assert.Contains(t, content, "func Heavy() {")
+		assert.NotContains(t, content, "x := compute()")
+	})
+
+	t.Run("should render everything without a budget", func(t *testing.T) {
+		content := NewGenerator(true).GenerateLLMsFullText(output)
+		assert.Contains(t, content, "x := compute()")
+		assert.NotContains(t, content, "token budget")
+	})
+}
@@ -0,0 +1,161 @@
+package generators
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// hunkHeader matches the header of a unified diff hunk, capturing the first line and the
+// line count of the new version
+var hunkHeader = regexp.MustCompile(`(?m)^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// lineRange is an inclusive range of line numbers, starting at 1
+type lineRange struct {
+	start, end int
+}
+
+// GeneratePullRequest generates llms-pr.txt for a pull request of the repository at
+// repoPath, with the diff of every changed file. contents holds the content of changed
+// files at the head of the pull request; with contextLines above zero, the lines around
+// each change are included from it, so the diff can be read with its surroundings.
+func (g *Generator) GeneratePullRequest(repoPath string, pr *models.PullRequest, contents map[string]string, contextLines int) string {
+	var body strings.Builder
+
+	body.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgPullRequestInfo)))
+	if pr.Author != "" {
+		body.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgAuthor), pr.Author))
+	}
+	body.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgURL), pr.URL))
+	body.WriteString(fmt.Sprintf("**%s:** %s -> %s\n", g.t(msgBranches), pr.HeadRef, pr.BaseRef))
+	body.WriteString(fmt.Sprintf("**%s:** %s\n\n", g.t(msgHeadCommit), pr.HeadSHA))
+
+	if description := strings.TrimSpace(pr.Body); description != "" {
+		body.WriteString(fmt.Sprintf("## %s\n\n%s\n\n", g.t(msgDescription), description))
+	}
+
+	g.writeChanges(&body, pr.Files, contents, contextLines)
+	additions, deletions := changeTotals(pr.Files)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s: %s#%d\n", g.t(msgPullRequest), repoPath, pr.Number))
+	sb.WriteString(fmt.Sprintf("# %s: %s\n", g.t(msgTitle), pr.Title))
+	if label, value := g.generated(g.clock.Now(), ""); label != "" {
+		sb.WriteString(fmt.Sprintf("# %s: %s\n", label, value))
+	}
+	sb.WriteString(fmt.Sprintf("# %s: %d (+%d -%d)\n", g.t(msgChangedFiles), len(pr.Files), additions, deletions))
+	sb.WriteString(fmt.Sprintf("# %s: %d\n\n", g.t(msgEstimatedTokens), utils.EstimateTokens(body.String())))
+	sb.WriteString(body.String())
+	return sb.String()
+}
+
+// writeChanges writes the list of changed files followed by the diff of each one
+func (g *Generator) writeChanges(sb *strings.Builder, files []models.ChangedFile, contents map[string]string, contextLines int) {
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgChangedFiles)))
+	for _, file := range files {
+		sb.WriteString(fmt.Sprintf("- %s: %s (+%d -%d)\n", file.Status, changedPath(file), file.Additions, file.Deletions))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgChanges)))
+	for _, file := range files {
+		g.writeChangedFile(sb, file, contents[file.Path], contextLines)
+	}
+}
+
+// changeTotals sums the lines added and removed in files
+func changeTotals(files []models.ChangedFile) (int, int) {
+	additions, deletions := 0, 0
+	for _, file := range files {
+		additions += file.Additions
+		deletions += file.Deletions
+	}
+	return additions, deletions
+}
+
+// writeChangedFile writes the diff of a changed file, followed by the lines around its
+// changes when contextLines is above zero and the file content is known
+func (g *Generator) writeChangedFile(sb *strings.Builder, file models.ChangedFile, content string, contextLines int) {
+	sb.WriteString(fmt.Sprintf("### %s (%s, +%d -%d)\n", changedPath(file), file.Status, file.Additions, file.Deletions))
+	if file.Patch == "" {
+		sb.WriteString(fmt.Sprintf("```\n[%s]\n```\n\n", g.t(msgNoDiff)))
+		return
+	}
+
+	sb.WriteString("```diff\n")
+	sb.WriteString(file.Patch)
+	if !strings.HasSuffix(file.Patch, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("```\n\n")
+
+	if contextLines <= 0 || content == "" {
+		return
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	ranges := changeRanges(file.Patch, contextLines, len(lines))
+	if len(ranges) == 0 {
+		return
+	}
+
+	width := len(strconv.Itoa(ranges[len(ranges)-1].end))
+	sb.WriteString(fmt.Sprintf("%s:\n", g.t(msgChangeContext)))
+	sb.WriteString(fmt.Sprintf("```%s\n", g.getLanguageFromExtension(strings.ToLower(filepath.Ext(file.Path)))))
+	for i, r := range ranges {
+		if i > 0 {
+			sb.WriteString(fmt.Sprintf("%*s | ...\n", width, ""))
+		}
+		for number := r.start; number <= r.end; number++ {
+			sb.WriteString(fmt.Sprintf("%*d | %s\n", width, number, lines[number-1]))
+		}
+	}
+	sb.WriteString("```\n\n")
+}
+
+// changedPath describes the path of a changed file, with its former path when renamed
+func changedPath(file models.ChangedFile) string {
+	if file.PreviousPath != "" && file.PreviousPath != file.Path {
+		return file.PreviousPath + " -> " + file.Path
+	}
+	return file.Path
+}
+
+// changeRanges returns the lines of the new version touched by the hunks of patch, widened
+// by contextLines on each side, clamped to totalLines and merged when they overlap or touch
+func changeRanges(patch string, contextLines, totalLines int) []lineRange {
+	var ranges []lineRange
+	for _, match := range hunkHeader.FindAllStringSubmatch(patch, -1) {
+		start, _ := strconv.Atoi(match[1])
+		count := 1
+		if match[2] != "" {
+			count, _ = strconv.Atoi(match[2])
+		}
+		// Hunks only removing lines start at the line before the removal
+		end := start + count - 1
+		if count == 0 {
+			end = start
+		}
+
+		r := lineRange{start: max(start-contextLines, 1), end: min(end+contextLines, totalLines)}
+		if r.start <= r.end {
+			ranges = append(ranges, r)
+		}
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	var merged []lineRange
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r.start <= merged[last].end+1 {
+			merged[last].end = max(merged[last].end, r.end)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
@@ -0,0 +1,105 @@
+package generators
+
+import (
+	"testing"
+	"time"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeRanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		patch        string
+		contextLines int
+		totalLines   int
+		expected     []lineRange
+	}{
+		{
+			name:         "should widen each hunk by the context lines",
+			patch:        "@@ -10,3 +10,4 @@ func main() {\n a\n+b\n c\n d\n@@ -50,2 +51,2 @@\n-x\n+y\n z\n",
+			contextLines: 5,
+			totalLines:   100,
+			expected:     []lineRange{{start: 5, end: 18}, {start: 46, end: 57}},
+		},
+		{
+			name:         "should merge overlapping ranges and clamp them to the file",
+			patch:        "@@ -1,2 +1,3 @@\n+a\n b\n c\n@@ -8 +9 @@\n-x\n+y\n",
+			contextLines: 3,
+			totalLines:   10,
+			expected:     []lineRange{{start: 1, end: 10}},
+		},
+		{
+			name:         "should keep the line before removals",
+			patch:        "@@ -20,2 +19,0 @@\n-a\n-b\n",
+			contextLines: 1,
+			totalLines:   30,
+			expected:     []lineRange{{start: 18, end: 20}},
+		},
+		{
+			name:         "should ignore patches without hunks",
+			patch:        "Binary files differ",
+			contextLines: 3,
+			totalLines:   30,
+			expected:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, changeRanges(tt.patch, tt.contextLines, tt.totalLines))
+		})
+	}
+}
+
+func TestGenerator_GeneratePullRequest(t *testing.T) {
+	pr := &models.PullRequest{
+		Number:  42,
+		Title:   "Retry failed uploads",
+		Body:    "Uploads now retry three times.\n",
+		Author:  "alice",
+		URL:     "https://github.com/owner/repo/pull/42",
+		BaseRef: "main",
+		HeadRef: "retry-uploads",
+		HeadSHA: "abc123",
+		Files: []models.ChangedFile{
+			{Path: "upload.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -2,1 +2,1 @@\n-\treturn send()\n+\treturn retry(send)"},
+			{Path: "docs/logo.png", PreviousPath: "logo.png", Status: "renamed"},
+		},
+	}
+	contents := map[string]string{
+		"upload.go": "func upload() error {\n\treturn retry(send)\n}\n\nfunc other() {}\n",
+	}
+
+	generator := NewGenerator(true)
+	generator.SetClock(utils.FixedClock{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)})
+
+	t.Run("should write the description, changed files and diffs", func(t *testing.T) {
+		text := generator.GeneratePullRequest("owner/repo", pr, contents, 0)
+
+		assert.Contains(t, text, "# Pull Request: owner/repo#42\n# Title: Retry failed uploads\n# Generated: 2026-03-01T12:00:00Z\n# Changed Files: 2 (+1 -1)\n")
+		assert.Contains(t, text, "**Branches:** retry-uploads -> main\n")
+		assert.Contains(t, text, "## Description\n\nUploads now retry three times.\n\n")
+		assert.Contains(t, text, "- renamed: logo.png -> docs/logo.png (+0 -0)\n")
+		assert.Contains(t, text, "### upload.go (modified, +1 -1)\n```diff\n@@ -2,1 +2,1 @@\n-\treturn send()\n+\treturn retry(send)\n```\n")
+		assert.Contains(t, text, "[Diff not available: binary file or diff too large]")
+		assert.NotContains(t, text, "Context around the changes")
+	})
+
+	t.Run("should include the lines around the changes", func(t *testing.T) {
+		text := generator.GeneratePullRequest("owner/repo", pr, contents, 1)
+
+		assert.Contains(t, text, "Context around the changes:\n```go\n1 | func upload() error {\n2 | \treturn retry(send)\n3 | }\n```\n")
+	})
+
+	t.Run("should leave out an empty description", func(t *testing.T) {
+		withoutBody := *pr
+		withoutBody.Body = ""
+
+		text := generator.GeneratePullRequest("owner/repo", &withoutBody, contents, 0)
+		assert.NotContains(t, text, "## Description")
+	})
+}
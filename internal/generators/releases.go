@@ -0,0 +1,45 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// writeReleases writes the Releases section, with the notes of each release. Nothing is
+// written without releases.
+func (g *Generator) writeReleases(sb *strings.Builder, releases []models.Release) {
+	if len(releases) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgReleases)))
+	for _, release := range releases {
+		name := release.Name
+		if name == "" {
+			name = release.Tag
+		}
+		sb.WriteString(fmt.Sprintf("### %s\n", name))
+
+		details := []string{fmt.Sprintf("**%s:** %s", g.t(msgTag), release.Tag)}
+		if !release.PublishedAt.IsZero() {
+			details = append(details, fmt.Sprintf("**%s:** %s", g.t(msgPublished), release.PublishedAt.UTC().Format("2006-01-02")))
+		}
+		if release.Author != "" {
+			details = append(details, fmt.Sprintf("**%s:** %s", g.t(msgAuthor), release.Author))
+		}
+		if release.Prerelease {
+			details = append(details, fmt.Sprintf("**%s**", g.t(msgPrerelease)))
+		}
+		sb.WriteString(strings.Join(details, " | ") + "\n")
+		if release.URL != "" {
+			sb.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgURL), release.URL))
+		}
+		sb.WriteString("\n")
+
+		if body := strings.TrimSpace(release.Body); body != "" {
+			sb.WriteString(body + "\n\n")
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_WriteReleases(t *testing.T) {
+	releases := []models.Release{
+		{
+			Name:        "Retries",
+			Tag:         "v1.2.0",
+			Body:        "Uploads now retry three times.\n",
+			Author:      "alice",
+			URL:         "https://github.com/owner/repo/releases/tag/v1.2.0",
+			PublishedAt: time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{Tag: "v1.3.0-rc.1", Prerelease: true},
+	}
+
+	t.Run("should write each release with its metadata and notes", func(t *testing.T) {
+		var sb strings.Builder
+		NewGenerator(true).writeReleases(&sb, releases)
+
+		assert.Equal(t, "## Releases\n\n"+
+			"### Retries\n"+
+			"**Tag:** v1.2.0 | **Published:** 2026-02-03 | **Author:** alice\n"+
+			"**URL:** https://github.com/owner/repo/releases/tag/v1.2.0\n\n"+
+			"Uploads now retry three times.\n\n"+
+			"### v1.3.0-rc.1\n"+
+			"**Tag:** v1.3.0-rc.1 | **Pre-release**\n\n", sb.String())
+	})
+
+	t.Run("should write nothing without releases", func(t *testing.T) {
+		var sb strings.Builder
+		NewGenerator(true).writeReleases(&sb, nil)
+		assert.Empty(t, sb.String())
+	})
+
+	t.Run("should render releases once in llms-full.txt and llms-full.md", func(t *testing.T) {
+		generator := NewGenerator(true)
+		output, err := generator.GenerateOutput(&models.ProcessingResult{
+			Repository: models.Repository{Name: "repo"},
+			Files:      []models.FileInfo{{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true}},
+			Releases:   releases,
+		})
+		assert.NoError(t, err)
+
+		text := generator.GenerateLLMsFullText(output)
+		assert.Equal(t, 1, strings.Count(text, "## Releases\n"))
+		assert.Less(t, strings.Index(text, "## Releases"), strings.Index(text, "## File Contents"))
+
+		markdown := generator.GenerateMarkdown(output)
+		assert.Contains(t, markdown, "- [Releases](#releases)\n")
+		assert.Contains(t, markdown, "<a id=\"releases\"></a>\n\n## Releases\n")
+	})
+}
@@ -0,0 +1,160 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// repomixSummary is the file summary of the repomix XML format, kept in English and close to
+// the original wording so prompts written for repomix outputs apply unchanged
+const repomixSummary = `<file_summary>
+This section contains a summary of this file.
+
+<purpose>
+This file contains a packed representation of the entire repository's contents.
+It is designed to be easily consumable by AI systems for analysis, code review,
+or other automated processes.
+</purpose>
+
+<file_format>
+The content is organized as follows:
+1. This summary section
+2. Repository information
+3. Directory structure
+4. Repository files, each consisting of:
+  - File path as an attribute
+  - Full contents of the file
+</file_format>
+
+<usage_guidelines>
+- This file should be treated as read-only. Any changes should be made to the
+  original repository files, not this packed version.
+- When processing this file, use the file path to distinguish
+  between different files in the repository.
+- Be aware that this file may contain sensitive information. Handle it with
+  the same level of security as you would the original repository.
+</usage_guidelines>
+
+<notes>
+- Some files may have been excluded based on ignore and include patterns
+- Binary files are not included in this packed representation
+- Files matching patterns in .gitignore are excluded
+</notes>
+
+</file_summary>
+
+`
+
+// GenerateRepomix generates llms-full.xml in the XML file-delimiter format of repomix, with
+// the directory structure and each file in a <file path="..."> element. Token budgets and
+// limits apply as they do to llms-full.txt.
+func (g *Generator) GenerateRepomix(output *models.LLMsOutput) string {
+	var sb strings.Builder
+
+	if err := g.validateFileSize(output.FileContents); err != nil {
+		sb.WriteString(fmt.Sprintf("Error: %s\n", err.Error()))
+		return sb.String()
+	}
+
+	var files []models.FileInfo
+	for _, file := range g.orderFiles(output) {
+		if !file.IsDir && !file.IsBinary && file.Error == nil {
+			files = append(files, file)
+		}
+	}
+
+	g.writeRepomixPrefix(&sb, output)
+
+	var modes map[string]PackMode
+	if g.config.TokenBudget > 0 {
+		modes = g.packFiles(files, output.Priority, annotationsByPath(output.Annotations), utils.TokenLimit(g.config.TokenBudget)-utils.EstimateTokens(sb.String()))
+	}
+
+	sections := make([]string, 0, len(files))
+	var omitted []models.FileInfo
+	if g.config.MaxTokens > 0 {
+		// Leave room for the closing tag of the files section
+		sections, omitted = g.limitFiles(files, utils.TokenLimit(g.config.MaxTokens)-utils.EstimateTokens(sb.String()+"</files>\n"), func(file models.FileInfo) string {
+			return g.repomixFile(file, modes[file.Path])
+		})
+	} else {
+		for _, file := range files {
+			sections = append(sections, g.repomixFile(file, modes[file.Path]))
+		}
+	}
+	for _, section := range sections {
+		sb.WriteString(section)
+	}
+	sb.WriteString("</files>\n")
+	if len(omitted) > 0 {
+		sb.WriteString("\n")
+		g.writeOmittedFiles(&sb, omitted)
+	}
+	return sb.String()
+}
+
+// writeRepomixPrefix writes everything up to the opening of the files section
+func (g *Generator) writeRepomixPrefix(sb *strings.Builder, output *models.LLMsOutput) {
+	sb.WriteString("This file is a merged representation of the entire codebase, combined into a single document by Repomix.\n\n")
+	sb.WriteString(repomixSummary)
+
+	sb.WriteString("<repository_info>\n")
+	sb.WriteString(fmt.Sprintf("Repository: %s\n", output.Repository.PathWithNamespace))
+	if output.Repository.Description != "" {
+		sb.WriteString(fmt.Sprintf("Description: %s\n", output.Repository.Description))
+	}
+	if output.Repository.WebURL != "" {
+		sb.WriteString(fmt.Sprintf("URL: %s\n", output.Repository.WebURL))
+	}
+	if output.Sample != nil {
+		sb.WriteString(fmt.Sprintf("Sample: %s\n", g.sample(output.Sample)))
+	}
+	sb.WriteString("</repository_info>\n\n")
+
+	sb.WriteString("<directory_structure>\n")
+	writeRepomixTree(sb, output.ProjectTree, "")
+	sb.WriteString("</directory_structure>\n\n")
+
+	sb.WriteString("<files>\nThis section contains the contents of the repository's files.\n\n")
+}
+
+// writeRepomixTree writes the tree the way repomix does: two spaces per level and a slash
+// after directory names
+func writeRepomixTree(sb *strings.Builder, nodes []models.TreeNode, indent string) {
+	for _, node := range nodes {
+		if node.IsDir {
+			sb.WriteString(fmt.Sprintf("%s%s/\n", indent, node.Name))
+			writeRepomixTree(sb, node.Children, indent+"  ")
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s%s\n", indent, node.Name))
+	}
+}
+
+// repomixFile renders a file element. File contents are written as they are, like repomix
+// does, so the element is XML-like rather than well-formed XML.
+func (g *Generator) repomixFile(file models.FileInfo, mode PackMode) string {
+	content := file.Content
+	switch {
+	case file.Size > MaxFileSize:
+		content = fmt.Sprintf("[%s]", g.t(msgFileTooLarge, formatBytes(file.Size), formatBytes(MaxFileSize)))
+	case file.NotFetched:
+		content = fmt.Sprintf("[%s]", g.t(msgNotFetched))
+	case mode == PackStub:
+		content = fmt.Sprintf("[%s]", g.t(msgContentOmitted, utils.EstimateTokens(file.Content)))
+	case mode == PackOutline:
+		content = outlineContent(file.Content)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<file path=\"%s\">\n", file.Path))
+	sb.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("</file>\n\n")
+	return sb.String()
+}
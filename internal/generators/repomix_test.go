@@ -0,0 +1,54 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_GenerateRepomix(t *testing.T) {
+	result := &models.ProcessingResult{
+		Repository: models.Repository{Name: "repo", PathWithNamespace: "owner/repo", WebURL: "https://github.com/owner/repo"},
+		Files: []models.FileInfo{
+			{Path: "cmd/main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+			{Path: "README.md", Name: "README.md", Content: "# Repo\n", Size: 7, IsText: true},
+			{Path: "logo.png", Name: "logo.png", Size: 100, IsBinary: true},
+		},
+	}
+
+	t.Run("should render the repomix file summary, tree and file elements", func(t *testing.T) {
+		generator := NewGenerator(true)
+		output, err := generator.GenerateOutput(result)
+		require.NoError(t, err)
+		xml := generator.GenerateRepomix(output)
+
+		assert.True(t, strings.HasPrefix(xml, "This file is a merged representation of the entire codebase, combined into a single document by Repomix.\n\n<file_summary>\n"))
+		assert.Contains(t, xml, "<repository_info>\nRepository: owner/repo\nURL: https://github.com/owner/repo\n</repository_info>\n")
+		assert.Contains(t, xml, "<directory_structure>\ncmd/\n  main.go\n")
+		assert.Contains(t, xml, "<file path=\"cmd/main.go\">\npackage main\n</file>\n")
+		assert.Contains(t, xml, "<file path=\"README.md\">\n# Repo\n</file>\n")
+		assert.NotContains(t, xml, "<file path=\"logo.png\">")
+		assert.True(t, strings.HasSuffix(xml, "</file>\n\n</files>\n"))
+	})
+
+	t.Run("should list files dropped by max tokens after the files section", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{MaxTokens: 400})
+		large := &models.ProcessingResult{
+			Repository: result.Repository,
+			Files: []models.FileInfo{
+				{Path: "README.md", Name: "README.md", Content: "# Repo\n", Size: 7, IsText: true},
+				{Path: "data.go", Name: "data.go", Content: strings.Repeat("var x = 1\n", 500), Size: 5000, IsText: true},
+			},
+		}
+		output, err := generator.GenerateOutput(large)
+		require.NoError(t, err)
+		xml := generator.GenerateRepomix(output)
+
+		assert.Contains(t, xml, "</files>\n\n## Omitted Files\n")
+		assert.NotContains(t, xml, "<file path=\"data.go\">")
+	})
+}
@@ -0,0 +1,76 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// splitLimits returns the size and estimated token limits of a part of llms-full.txt, zero
+// when unset. The settings are validated when the configuration is loaded.
+func (g *Generator) splitLimits() (maxBytes int64, maxTokens int) {
+	if g.config.SplitSize != "" {
+		maxBytes, _ = utils.ParseSize(g.config.SplitSize)
+	}
+	if g.config.SplitTokens != "" {
+		maxTokens, _ = utils.ParseTokenCount(g.config.SplitTokens)
+		maxTokens = utils.TokenLimit(maxTokens)
+	}
+	return maxBytes, maxTokens
+}
+
+// GenerateLLMsFullTextParts generates llms-full.txt split into parts below split_size and
+// split_tokens. Each part is self-contained, repeating the header and project tree before
+// its subset of files. A single part is returned when no limit is set or everything fits.
+func (g *Generator) GenerateLLMsFullTextParts(output *models.LLMsOutput) []string {
+	maxBytes, maxTokens := g.splitLimits()
+	if maxBytes <= 0 && maxTokens <= 0 {
+		return []string{g.GenerateLLMsFullText(output)}
+	}
+
+	text, err := g.renderFullText(output)
+	if err != nil {
+		return []string{fmt.Sprintf("## %s: %s\n\n", g.t(msgError), err.Error())}
+	}
+
+	// Size parts with a numbered prefix, since every part of a split document carries one
+	prefix := text.prefix(1, 1)
+	prefixBytes, prefixTokens := int64(len(prefix)), utils.EstimateTokens(prefix)
+	fits := func(bytes int64, tokens int) bool {
+		return (maxBytes <= 0 || bytes <= maxBytes) && (maxTokens <= 0 || tokens <= maxTokens)
+	}
+
+	// Fill parts in priority order, giving each part at least one file even when the file
+	// alone exceeds the limits
+	var groups [][]string
+	var current []string
+	bytes, tokens := prefixBytes, prefixTokens
+	for _, section := range text.sections {
+		sectionBytes, sectionTokens := int64(len(section)), utils.EstimateTokens(section)
+		if len(current) > 0 && !fits(bytes+sectionBytes, tokens+sectionTokens) {
+			groups = append(groups, current)
+			current = nil
+			bytes, tokens = prefixBytes, prefixTokens
+		}
+		current = append(current, section)
+		bytes += sectionBytes
+		tokens += sectionTokens
+	}
+	if len(current) > 0 || len(groups) == 0 {
+		groups = append(groups, current)
+	}
+
+	if len(groups) == 1 {
+		return []string{text.prefix(0, 0) + strings.Join(groups[0], "") + text.suffix}
+	}
+
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		parts[i] = text.prefix(i+1, len(groups)) + strings.Join(group, "")
+	}
+	// The omitted files list closes the last part
+	parts[len(parts)-1] += text.suffix
+	return parts
+}
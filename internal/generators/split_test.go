@@ -0,0 +1,71 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_GenerateLLMsFullTextParts(t *testing.T) {
+	files := []models.FileInfo{
+		{Path: "a.go", Content: "package a\n\n// " + strings.Repeat("alpha ", 200) + "\n", Size: 1215},
+		{Path: "b.go", Content: "package b\n\n// " + strings.Repeat("bravo ", 200) + "\n", Size: 1215},
+		{Path: "c.go", Content: "package c\n\n// " + strings.Repeat("charlie ", 200) + "\n", Size: 1615},
+	}
+	output := &models.LLMsOutput{
+		Repository:   models.Repository{Name: "repo"},
+		TotalFiles:   len(files),
+		FileContents: files,
+	}
+	output.ProjectTree = NewGenerator(true).buildProjectTree(files)
+
+	t.Run("should return the whole document without limits", func(t *testing.T) {
+		generator := NewGenerator(true)
+		parts := generator.GenerateLLMsFullTextParts(output)
+		require.Len(t, parts, 1)
+		assert.Equal(t, generator.GenerateLLMsFullText(output), parts[0])
+	})
+
+	t.Run("should return a single unnumbered part when everything fits", func(t *testing.T) {
+		parts := NewGeneratorWithConfig(true, models.OutputConfig{SplitSize: "1MB"}).GenerateLLMsFullTextParts(output)
+		require.Len(t, parts, 1)
+		assert.NotContains(t, parts[0], "# Part")
+	})
+
+	t.Run("should split by size into self-contained parts", func(t *testing.T) {
+		parts := NewGeneratorWithConfig(true, models.OutputConfig{SplitSize: "3KB"}).GenerateLLMsFullTextParts(output)
+		require.Len(t, parts, 2)
+
+		for _, part := range parts {
+			assert.LessOrEqual(t, len(part), 3*1024)
+			assert.Contains(t, part, "# Repository: repo\n")
+			assert.Contains(t, part, "## Project Structure")
+			assert.Contains(t, part, "c.go (")
+		}
+		assert.Contains(t, parts[0], "# Part 1 of 2\n")
+		assert.Contains(t, parts[0], "### a.go\n")
+		assert.Contains(t, parts[0], "### b.go\n")
+		assert.Contains(t, parts[1], "# Part 2 of 2\n")
+		assert.Contains(t, parts[1], "### c.go\n")
+		assert.NotContains(t, parts[1], "### a.go\n")
+	})
+
+	t.Run("should split by tokens, keeping the safety margin free", func(t *testing.T) {
+		parts := NewGeneratorWithConfig(true, models.OutputConfig{SplitTokens: "670"}).GenerateLLMsFullTextParts(output)
+		require.Len(t, parts, 2)
+		for _, part := range parts {
+			assert.LessOrEqual(t, utils.EstimateTokens(part), utils.TokenLimit(670))
+		}
+	})
+
+	t.Run("should give each part at least one file", func(t *testing.T) {
+		parts := NewGeneratorWithConfig(true, models.OutputConfig{SplitSize: "10B"}).GenerateLLMsFullTextParts(output)
+		require.Len(t, parts, 3)
+		assert.Contains(t, parts[2], "### c.go\n")
+	})
+}
@@ -0,0 +1,122 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// minTruncatedTokens is the smallest part of a file worth keeping when truncating it
+const minTruncatedTokens = 200
+
+// fileTokens returns the estimated tokens of a file's content, or zero for files whose
+// content is not included
+func fileTokens(file models.FileInfo) int {
+	if file.IsDir || file.IsBinary || file.Error != nil {
+		return 0
+	}
+	return utils.EstimateTokens(file.Content)
+}
+
+// totalTokens returns the estimated tokens of all included file contents
+func totalTokens(files []models.FileInfo) int {
+	total := 0
+	for _, file := range files {
+		total += fileTokens(file)
+	}
+	return total
+}
+
+// limitFiles fits the file sections rendered by render into available tokens under
+// max_tokens. Files are kept whole in order while they fit, then the first file left out
+// is truncated into what remains; the others are dropped. Every dropped file costs a line
+// in the omitted files list, so that line is accounted for up front.
+func (g *Generator) limitFiles(files []models.FileInfo, available int, render func(models.FileInfo) string) (sections []string, omitted []models.FileInfo) {
+	rendered := make([]string, len(files))
+	costs := make([]int, len(files))
+	listed := make([]int, len(files))
+	remaining := available - utils.EstimateTokens(g.omittedHeading())
+	for i, file := range files {
+		rendered[i] = render(file)
+		costs[i] = utils.EstimateTokens(rendered[i])
+		listed[i] = utils.EstimateTokens(g.omittedLine(file))
+		remaining -= listed[i]
+	}
+
+	kept := make([]bool, len(files))
+	for i := range files {
+		if costs[i]-listed[i] <= remaining {
+			kept[i] = true
+			remaining -= costs[i] - listed[i]
+		}
+	}
+
+	// Truncate the most important file left out into the remaining tokens
+	for i, file := range files {
+		if kept[i] {
+			continue
+		}
+		contentTokens := utils.EstimateTokens(file.Content)
+		note := func(shown int) string { return "\n[" + g.t(msgTruncated, shown, contentTokens) + "]\n" }
+		budget := remaining + listed[i] - (costs[i] - contentTokens) - utils.EstimateTokens(note(contentTokens))
+		if budget >= minTruncatedTokens {
+			truncated := file
+			content, shown := truncateToTokens(file.Content, budget)
+			truncated.Content = content + note(shown)
+			if section := render(truncated); utils.EstimateTokens(section)-listed[i] <= remaining {
+				rendered[i] = section
+				kept[i] = true
+			}
+		}
+		break
+	}
+
+	for i, file := range files {
+		if kept[i] {
+			sections = append(sections, rendered[i])
+		} else {
+			omitted = append(omitted, file)
+		}
+	}
+	return sections, omitted
+}
+
+// truncateToTokens keeps the leading lines of content fitting into maxTokens and returns
+// them with their estimated tokens
+func truncateToTokens(content string, maxTokens int) (string, int) {
+	var sb strings.Builder
+	tokens := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineTokens := utils.EstimateTokens(line)
+		if tokens+lineTokens > maxTokens {
+			break
+		}
+		sb.WriteString(line)
+		tokens += lineTokens
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), tokens
+}
+
+// writeOmittedFiles lists the files left out to fit max_tokens
+func (g *Generator) writeOmittedFiles(sb *strings.Builder, omitted []models.FileInfo) {
+	if len(omitted) == 0 {
+		return
+	}
+	sb.WriteString(g.omittedHeading())
+	for _, file := range omitted {
+		sb.WriteString(g.omittedLine(file))
+	}
+	sb.WriteString("\n")
+}
+
+// omittedHeading renders the heading of the omitted files list
+func (g *Generator) omittedHeading() string {
+	return fmt.Sprintf("## %s\n\n%s\n\n", g.t(msgOmittedFiles), g.t(msgOmittedNote, g.config.MaxTokens))
+}
+
+// omittedLine renders a file of the omitted files list
+func (g *Generator) omittedLine(file models.FileInfo) string {
+	return fmt.Sprintf("- %s (%s)\n", file.Path, g.t(msgTokenCount, fileTokens(file)))
+}
@@ -0,0 +1,101 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_TokenCounts(t *testing.T) {
+	output := &models.LLMsOutput{
+		Repository: models.Repository{Name: "repo"},
+		FileContents: []models.FileInfo{
+			{Path: "main.go", Content: "package main\n\nfunc main() {}\n", Size: 30},
+			{Path: "logo.png", Content: "\x89PNG", Size: 4, IsBinary: true},
+		},
+	}
+	output.ProjectTree = NewGenerator(true).buildProjectTree(output.FileContents)
+	tokens := utils.EstimateTokens(output.FileContents[0].Content)
+
+	t.Run("should report the total in the header", func(t *testing.T) {
+		text := NewGenerator(true).GenerateLLMsFullText(output)
+		assert.Contains(t, text, fmt.Sprintf("# Estimated Tokens: %d\n", tokens))
+
+		markdown := NewGenerator(true).GenerateMarkdown(output)
+		assert.Contains(t, markdown, fmt.Sprintf("- **Estimated Tokens:** %d\n", tokens))
+	})
+
+	t.Run("should report each file in the tree", func(t *testing.T) {
+		text := NewGenerator(true).GenerateLLMsFullText(output)
+		assert.Contains(t, text, fmt.Sprintf("main.go (30 B, ~%d tokens)\n", tokens))
+		assert.Contains(t, text, "logo.png (4 B)\n")
+	})
+}
+
+func TestGenerator_MaxTokens(t *testing.T) {
+	body := strings.Repeat("\tresult = append(result, compute(value))\n", 400)
+	output := &models.LLMsOutput{
+		Repository: models.Repository{Name: "repo"},
+		FileContents: []models.FileInfo{
+			{Path: "README.md", Content: "# Repo\n\nStart here.\n", Size: 21},
+			{Path: "pkg/heavy.go", Content: "package pkg\n\nfunc Heavy() {\n" + body + "}\n", Size: int64(len(body) + 30)},
+			{Path: "pkg/light.go", Content: "package pkg\n\nfunc Light() {}\n", Size: 28},
+		},
+		Priority: []string{"README.md", "pkg/heavy.go"},
+	}
+
+	t.Run("should keep outputs under the limit", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{MaxTokens: 1500})
+		text := generator.GenerateLLMsFullText(output)
+
+		assert.LessOrEqual(t, utils.EstimateTokens(text), 1500)
+		assert.Contains(t, text, "Start here.")
+		assert.Contains(t, text, "func Light() {}")
+	})
+
+	t.Run("should truncate the most important file that does not fit", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{MaxTokens: 1500})
+		text := generator.GenerateLLMsFullText(output)
+
+		assert.Contains(t, text, "func Heavy() {")
+		assert.Contains(t, text, "[Truncated to fit the token limit (~")
+		assert.NotContains(t, text, "## Omitted Files")
+	})
+
+	t.Run("should drop files and list them when nothing is left to truncate", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{MaxTokens: 300})
+		text := generator.GenerateLLMsFullText(output)
+
+		assert.LessOrEqual(t, utils.EstimateTokens(text), 300)
+		assert.NotContains(t, text, "func Heavy() {")
+		assert.Contains(t, text, "## Omitted Files\n\nLeft out to fit the limit of 300 tokens:\n\n- pkg/heavy.go (~")
+	})
+
+	t.Run("should leave dropped files out of the Markdown table of contents", func(t *testing.T) {
+		generator := NewGeneratorWithConfig(true, models.OutputConfig{MaxTokens: 400})
+		markdown := generator.GenerateMarkdown(output)
+
+		assert.LessOrEqual(t, utils.EstimateTokens(markdown), 400)
+		assert.NotContains(t, markdown, "](#file-pkg-heavy-go)")
+		assert.Contains(t, markdown, "- pkg/heavy.go (~")
+	})
+
+	t.Run("should render everything without a limit", func(t *testing.T) {
+		text := NewGenerator(true).GenerateLLMsFullText(output)
+		assert.Contains(t, text, "compute(value)")
+		assert.NotContains(t, text, "Truncated")
+	})
+}
+
+func TestTruncateToTokens(t *testing.T) {
+	t.Run("should keep whole leading lines", func(t *testing.T) {
+		content, tokens := truncateToTokens("one\ntwo\nthree\n", 4)
+		assert.Equal(t, "one\ntwo", content)
+		assert.Equal(t, utils.EstimateTokens("one\ntwo\n"), tokens)
+	})
+}
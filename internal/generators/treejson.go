@@ -0,0 +1,108 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sherpa/pkg/models"
+)
+
+// Tree entry types in tree.json
+const (
+	TreeEntryDir  = "dir"
+	TreeEntryFile = "file"
+)
+
+// TreeDocument is the project tree written to tree.json for tools rendering interactive trees
+type TreeDocument struct {
+	Repository  string      `json:"repository"`
+	URL         string      `json:"url,omitempty"`
+	GeneratedAt time.Time   `json:"generated_at,omitzero"` // Left out of deterministic outputs
+	Commit      string      `json:"commit,omitempty"`
+	TotalFiles  int         `json:"total_files"`
+	TotalSize   int64       `json:"total_size"`
+	Tree        []TreeEntry `json:"tree"`
+}
+
+// TreeEntry is a file or directory of the project tree
+type TreeEntry struct {
+	Name      string      `json:"name"`
+	Path      string      `json:"path"`
+	Type      string      `json:"type"`                 // dir or file
+	Size      int64       `json:"size"`                 // Total size of the files below a directory
+	Tokens    int         `json:"tokens,omitempty"`     // Estimated tokens of the content, totaled for directories
+	FileCount int         `json:"file_count,omitempty"` // Number of files below a directory
+	Tags      []string    `json:"tags,omitempty"`       // File classifications like test or config
+	Note      string      `json:"note,omitempty"`       // Owner annotation
+	ReadFirst bool        `json:"read_first,omitempty"`
+	Children  []TreeEntry `json:"children,omitempty"`
+}
+
+// GenerateTreeJSON renders the complete project tree as indented JSON. Unlike the text
+// outputs the tree is never folded, since consumers can collapse directories themselves.
+func (g *Generator) GenerateTreeJSON(output *models.LLMsOutput) ([]byte, error) {
+	tree := g.buildProjectTree(output.FileContents)
+	if len(output.Annotations) > 0 {
+		annotateTree(tree, annotationsByPath(output.Annotations))
+	}
+
+	filesByPath := make(map[string]models.FileInfo, len(output.FileContents))
+	for _, file := range output.FileContents {
+		filesByPath[file.Path] = file
+	}
+
+	document := TreeDocument{
+		Repository:  output.Repository.PathWithNamespace,
+		URL:         output.Repository.WebURL,
+		GeneratedAt: output.GeneratedAt,
+		Commit:      output.Commit,
+		TotalFiles:  output.TotalFiles,
+		TotalSize:   output.TotalSize,
+		Tree:        treeEntries(tree, filesByPath),
+	}
+	if document.Repository == "" {
+		document.Repository = output.Repository.Name
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode project tree: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// treeEntries converts tree nodes to entries, totaling sizes and file counts of directories
+func treeEntries(nodes []models.TreeNode, filesByPath map[string]models.FileInfo) []TreeEntry {
+	entries := make([]TreeEntry, 0, len(nodes))
+	for _, node := range nodes {
+		entry := TreeEntry{
+			Name:      node.Name,
+			Path:      node.Path,
+			Type:      TreeEntryFile,
+			Size:      node.Size,
+			Tokens:    node.Tokens,
+			Note:      node.Note,
+			ReadFirst: node.ReadFirst,
+		}
+
+		if node.IsDir {
+			entry.Type = TreeEntryDir
+			entry.Children = treeEntries(node.Children, filesByPath)
+			for _, child := range entry.Children {
+				entry.Size += child.Size
+				entry.Tokens += child.Tokens
+				if child.Type == TreeEntryDir {
+					entry.FileCount += child.FileCount
+				} else {
+					entry.FileCount++
+				}
+			}
+		} else if file, exists := filesByPath[node.Path]; exists {
+			entry.Tags = ClassifyFile(file)
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,66 @@
+package generators
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_GenerateTreeJSON(t *testing.T) {
+	generator := NewGeneratorWithConfig(true, models.OutputConfig{MaxTreeEntries: 1})
+
+	output := &models.LLMsOutput{
+		Repository:  models.Repository{Name: "repo", PathWithNamespace: "owner/repo", WebURL: "https://github.com/owner/repo"},
+		GeneratedAt: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+		TotalFiles:  3,
+		TotalSize:   600,
+		FileContents: []models.FileInfo{
+			{Path: "cmd/main.go", Name: "main.go", Content: "package main", Size: 100, IsText: true},
+			{Path: "core/engine.go", Name: "engine.go", Content: "package core", Size: 200, IsText: true},
+			{Path: "core/engine_test.go", Name: "engine_test.go", Content: "package core", Size: 300, IsText: true},
+		},
+		Annotations: []models.Annotation{{Path: "core", Description: "Domain logic", ReadFirst: true}},
+	}
+
+	data, err := generator.GenerateTreeJSON(output)
+	require.NoError(t, err)
+
+	var document TreeDocument
+	require.NoError(t, json.Unmarshal(data, &document))
+
+	t.Run("should describe the repository", func(t *testing.T) {
+		assert.Equal(t, "owner/repo", document.Repository)
+		assert.Equal(t, "https://github.com/owner/repo", document.URL)
+		assert.Equal(t, 3, document.TotalFiles)
+		assert.Equal(t, int64(600), document.TotalSize)
+	})
+
+	t.Run("should total directory sizes and file counts", func(t *testing.T) {
+		require.Len(t, document.Tree, 2)
+		core := document.Tree[1]
+		assert.Equal(t, TreeEntryDir, core.Type)
+		assert.Equal(t, int64(500), core.Size)
+		assert.Equal(t, 2, core.FileCount)
+		assert.Equal(t, core.Children[0].Tokens+core.Children[1].Tokens, core.Tokens)
+		assert.Positive(t, core.Tokens)
+	})
+
+	t.Run("should keep annotations and tags", func(t *testing.T) {
+		core := document.Tree[1]
+		assert.Equal(t, "Domain logic", core.Note)
+		assert.True(t, core.ReadFirst)
+
+		require.Len(t, core.Children, 2)
+		assert.Equal(t, TreeEntryFile, core.Children[1].Type)
+		assert.Equal(t, []string{TagTest}, core.Children[1].Tags)
+	})
+
+	t.Run("should not fold the tree", func(t *testing.T) {
+		assert.Len(t, document.Tree[0].Children, 1)
+	})
+}
@@ -0,0 +1,29 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// writeVendored writes the Vendored Dependencies section, listing the packages of each
+// excluded vendor directory on one line. Nothing is written without vendor directories.
+func (g *Generator) writeVendored(sb *strings.Builder, vendored []models.VendoredDependencies) {
+	if len(vendored) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgVendored)))
+	for _, dependencies := range vendored {
+		sb.WriteString(fmt.Sprintf("### %s (%s, %s)\n", dependencies.Path, dependencies.Ecosystem, g.t(msgPackageCount, len(dependencies.Packages))))
+		packages := make([]string, len(dependencies.Packages))
+		for i, pkg := range dependencies.Packages {
+			packages[i] = pkg.Name
+			if pkg.Version != "" {
+				packages[i] += "@" + pkg.Version
+			}
+		}
+		sb.WriteString(strings.Join(packages, ", ") + "\n\n")
+	}
+}
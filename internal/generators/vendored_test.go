@@ -0,0 +1,51 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_WriteVendored(t *testing.T) {
+	vendored := []models.VendoredDependencies{
+		{Path: "vendor", Ecosystem: "go", Packages: []models.VendoredPackage{
+			{Name: "github.com/pkg/errors", Version: "v0.9.1"},
+			{Name: "example.com/local"},
+		}},
+	}
+
+	t.Run("should list the packages of each vendor directory on one line", func(t *testing.T) {
+		var sb strings.Builder
+		NewGenerator(true).writeVendored(&sb, vendored)
+
+		assert.Equal(t, "## Vendored Dependencies\n\n### vendor (go, 2 packages)\ngithub.com/pkg/errors@v0.9.1, example.com/local\n\n", sb.String())
+	})
+
+	t.Run("should write nothing without vendor directories", func(t *testing.T) {
+		var sb strings.Builder
+		NewGenerator(true).writeVendored(&sb, nil)
+		assert.Empty(t, sb.String())
+	})
+
+	t.Run("should render vendored dependencies after the project tree", func(t *testing.T) {
+		generator := NewGenerator(true)
+		output, err := generator.GenerateOutput(&models.ProcessingResult{
+			Repository: models.Repository{Name: "repo"},
+			Files:      []models.FileInfo{{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true}},
+			Issues:     []models.Issue{{Number: 1, Title: "Bug"}},
+			Vendored:   vendored,
+		})
+		assert.NoError(t, err)
+
+		text := generator.GenerateLLMsFullText(output)
+		assert.Less(t, strings.Index(text, "## Project Structure"), strings.Index(text, "## Vendored Dependencies"))
+		assert.Less(t, strings.Index(text, "## Vendored Dependencies"), strings.Index(text, "## Issues"))
+
+		markdown := generator.GenerateMarkdown(output)
+		assert.Contains(t, markdown, "- [Vendored Dependencies](#vendored-dependencies)\n")
+		assert.Contains(t, markdown, "<a id=\"vendored-dependencies\"></a>\n\n## Vendored Dependencies\n")
+	})
+}
@@ -0,0 +1,29 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// writeWiki writes the Wiki section, with the content of each page under its title.
+// Nothing is written without pages.
+func (g *Generator) writeWiki(sb *strings.Builder, pages []models.WikiPage) {
+	if len(pages) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", g.t(msgWiki)))
+	for _, page := range pages {
+		sb.WriteString(fmt.Sprintf("### %s\n", page.Title))
+		if page.Path != "" {
+			sb.WriteString(fmt.Sprintf("**%s:** %s\n", g.t(msgPath), page.Path))
+		}
+		sb.WriteString("\n")
+
+		if content := strings.TrimSpace(page.Content); content != "" {
+			sb.WriteString(content + "\n\n")
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_WriteWiki(t *testing.T) {
+	pages := []models.WikiPage{
+		{Title: "Home", Path: "Home.md", Content: "Welcome to the service.\n"},
+		{Title: "Architecture", Path: "Architecture.md", Content: "The API talks to the worker through a queue.\n"},
+	}
+
+	t.Run("should write each page under its title", func(t *testing.T) {
+		var sb strings.Builder
+		NewGenerator(true).writeWiki(&sb, pages)
+
+		assert.Equal(t, "## Wiki\n\n"+
+			"### Home\n**Path:** Home.md\n\nWelcome to the service.\n\n"+
+			"### Architecture\n**Path:** Architecture.md\n\nThe API talks to the worker through a queue.\n\n", sb.String())
+	})
+
+	t.Run("should write nothing without pages", func(t *testing.T) {
+		var sb strings.Builder
+		NewGenerator(true).writeWiki(&sb, nil)
+		assert.Empty(t, sb.String())
+	})
+
+	t.Run("should render the wiki after the releases", func(t *testing.T) {
+		generator := NewGenerator(true)
+		output, err := generator.GenerateOutput(&models.ProcessingResult{
+			Repository: models.Repository{Name: "repo"},
+			Files:      []models.FileInfo{{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true}},
+			Releases:   []models.Release{{Tag: "v1.0.0"}},
+			WikiPages:  pages,
+		})
+		assert.NoError(t, err)
+
+		text := generator.GenerateLLMsFullText(output)
+		assert.Less(t, strings.Index(text, "## Releases"), strings.Index(text, "## Wiki"))
+		assert.Less(t, strings.Index(text, "## Wiki"), strings.Index(text, "## File Contents"))
+
+		markdown := generator.GenerateMarkdown(output)
+		assert.Contains(t, markdown, "- [Wiki](#wiki)\n")
+		assert.Contains(t, markdown, "<a id=\"wiki\"></a>\n\n## Wiki\n")
+	})
+}
@@ -0,0 +1,148 @@
+// Package history records the runs of sherpa so they can be listed and replayed.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sherpa/internal/filelock"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// DefaultLimit is the number of runs kept in the history file
+const DefaultLimit = 200
+
+// Entry is a recorded run
+type Entry struct {
+	ID           string          `json:"id"`
+	StartedAt    time.Time       `json:"started_at"`
+	Duration     string          `json:"duration"`
+	Dir          string          `json:"dir"`  // Working directory the run was started from
+	Args         []string        `json:"args"` // Flags and repository arguments, without the token
+	Repositories []RepositoryRun `json:"repositories"`
+	Failed       int             `json:"failed"`
+	ExitCode     int             `json:"exit_code"`
+}
+
+// RepositoryRun is a repository whose output was written by a recorded run
+type RepositoryRun struct {
+	Repository string          `json:"repository"`
+	Platform   models.Platform `json:"platform"`
+	Duration   string          `json:"duration"`
+	Output     string          `json:"output"`
+}
+
+// DefaultFile returns the history file next to the user configuration file, like
+// ~/.config/sherpa/history.jsonl, or an empty string when there is no home directory
+func DefaultFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sherpa", "history.jsonl")
+}
+
+// Load reads the recorded runs, oldest first, returning none when the file does not exist.
+// Malformed lines, like the end of a run cut short while recording, are skipped with a warning.
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return parse(path, data)
+}
+
+// parse decodes the runs of the history file at path, one per line
+func parse(path string, data []byte) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logger.Logger.WithError(err).WithFields(map[string]interface{}{
+				"history": path,
+				"line":    line,
+			}).Warn("Skipping malformed history line")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// Append records a run, keeping only the last limit runs. Runs finishing at the same time
+// serialize their updates with a lock file next to the history, and the history is
+// replaced atomically, so no run is lost and a crash never leaves it half written.
+func Append(path string, entry Entry, limit int) error {
+	err := filelock.Update(path, 0600, func(current []byte) ([]byte, error) {
+		entries, err := parse(path, current)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+
+		var buf bytes.Buffer
+		for _, entry := range entries {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode history entry: %w", err)
+			}
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Find returns the run with the given id, the most recent one for "last", or the only run
+// whose id starts with the given prefix
+func Find(entries []Entry, id string) (*Entry, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no runs recorded yet")
+	}
+	if id == "last" {
+		return &entries[len(entries)-1], nil
+	}
+
+	var match *Entry
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+		if strings.HasPrefix(entries[i].ID, id) {
+			if match != nil {
+				return nil, fmt.Errorf("run id '%s' is ambiguous", id)
+			}
+			match = &entries[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no run with id '%s'", id)
+	}
+	return match, nil
+}
@@ -0,0 +1,121 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppend(t *testing.T) {
+	t.Run("should return no runs when the file does not exist", func(t *testing.T) {
+		entries, err := Load(filepath.Join(t.TempDir(), "history.jsonl"))
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should append runs and keep only the last ones", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sherpa", "history.jsonl")
+		startedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		for _, id := range []string{"run-1", "run-2", "run-3"} {
+			require.NoError(t, Append(path, Entry{
+				ID:        id,
+				StartedAt: startedAt,
+				Args:      []string{"--format=md", "owner/repo"},
+				Repositories: []RepositoryRun{
+					{Repository: "owner/repo", Platform: "github", Duration: "1.5s", Output: "/tmp/out"},
+				},
+			}, 2))
+		}
+
+		entries, err := Load(path)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "run-2", entries[0].ID)
+		assert.Equal(t, "run-3", entries[1].ID)
+		assert.Equal(t, startedAt, entries[1].StartedAt)
+		assert.Equal(t, []string{"--format=md", "owner/repo"}, entries[1].Args)
+		assert.Equal(t, "/tmp/out", entries[1].Repositories[0].Output)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("should skip malformed lines of a corrupted history", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte("{\"id\":\"run-1\"}\nnot json\n{\"id\":\"run-2\"}\n{\"id\":\"ru"), 0600))
+
+		entries, err := Load(path)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "run-2", entries[1].ID)
+
+		require.NoError(t, Append(path, Entry{ID: "run-3"}, 0))
+		entries, err = Load(path)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, "run-3", entries[2].ID)
+	})
+
+	t.Run("should keep the runs appended concurrently", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, Append(path, Entry{ID: fmt.Sprintf("run-%d", i)}, 0))
+			}()
+		}
+		wg.Wait()
+
+		entries, err := Load(path)
+		require.NoError(t, err)
+		assert.Len(t, entries, 8)
+	})
+}
+
+func TestFind(t *testing.T) {
+	entries := []Entry{
+		{ID: "20240301T120000Z-aaaaaa"},
+		{ID: "20240301T130000Z-bbbbbb"},
+		{ID: "20240302T090000Z-cccccc"},
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		expected string
+		err      string
+	}{
+		{name: "should find an exact id", id: "20240301T130000Z-bbbbbb", expected: "20240301T130000Z-bbbbbb"},
+		{name: "should find the last run", id: "last", expected: "20240302T090000Z-cccccc"},
+		{name: "should find a unique prefix", id: "20240302", expected: "20240302T090000Z-cccccc"},
+		{name: "should reject an ambiguous prefix", id: "20240301", err: "ambiguous"},
+		{name: "should reject an unknown id", id: "2023", err: "no run with id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := Find(entries, tt.id)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, entry.ID)
+		})
+	}
+
+	t.Run("should report an empty history", func(t *testing.T) {
+		_, err := Find(nil, "last")
+		assert.ErrorContains(t, err, "no runs recorded yet")
+	})
+}
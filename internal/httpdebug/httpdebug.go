@@ -0,0 +1,199 @@
+// Package httpdebug records the HTTP traffic sent to platforms and package registries,
+// to diagnose provider issues with self-hosted instances.
+package httpdebug
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"sherpa/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxBodySize is the number of bytes of each request and response body kept in dumps
+const MaxBodySize = 64 * 1024
+
+// redacted replaces the value of sensitive query parameters
+const redacted = "REDACTED"
+
+// sensitiveParams are substrings of query parameter names whose value is never recorded
+var sensitiveParams = []string{"token", "secret", "password", "signature", "api_key", "apikey", "access_key"}
+
+// Entry is the record of one request
+type Entry struct {
+	Time         time.Time         `json:"time"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Status       int               `json:"status,omitempty"`
+	LatencyMS    int64             `json:"latency_ms"`
+	RateLimit    map[string]string `json:"rate_limit,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	RequestBody  string            `json:"request_body,omitempty"`
+	ResponseBody string            `json:"response_body,omitempty"`
+}
+
+// Recorder logs request metadata, or writes entries with their bodies as JSON lines to a dump
+type Recorder struct {
+	out io.Writer // Dump receiving entries with bodies, nil to log metadata only
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewRecorder creates a recorder logging the metadata of every request
+func NewRecorder() *Recorder {
+	return &Recorder{now: time.Now}
+}
+
+// NewDumpRecorder creates a recorder writing every request with its bodies to out
+func NewDumpRecorder(out io.Writer) *Recorder {
+	return &Recorder{out: out, now: time.Now}
+}
+
+// Transport wraps base so requests are recorded, using http.DefaultTransport when base is nil
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{recorder: r, base: base}
+}
+
+// record logs or dumps an entry
+func (r *Recorder) record(entry Entry) {
+	if r.out == nil {
+		fields := logrus.Fields{
+			"method":     entry.Method,
+			"url":        entry.URL,
+			"status":     entry.Status,
+			"latency_ms": entry.LatencyMS,
+		}
+		for name, value := range entry.RateLimit {
+			fields[strings.ToLower(name)] = value
+		}
+		if entry.Error != "" {
+			fields["error"] = entry.Error
+		}
+		logger.Logger.WithFields(fields).Info("HTTP request")
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.Logger.WithError(err).Warn("Failed to encode HTTP debug entry")
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.out.Write(append(data, '\n')); err != nil {
+		logger.Logger.WithError(err).Warn("Failed to write HTTP debug entry")
+	}
+}
+
+// transport is an http.RoundTripper recording requests and their responses
+type transport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+// RoundTrip sends the request and records it. The latency is measured until the response
+// headers are received, and only the first MaxBodySize bytes of the bodies are dumped.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := Entry{
+		Time:   t.recorder.now(),
+		Method: req.Method,
+		URL:    SanitizeURL(req.URL),
+	}
+	dumpBodies := t.recorder.out != nil
+	if dumpBodies && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			entry.RequestBody = readPrefix(body)
+			body.Close()
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	entry.LatencyMS = t.recorder.now().Sub(entry.Time).Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+		t.recorder.record(entry)
+		return nil, err
+	}
+
+	entry.Status = resp.StatusCode
+	entry.RateLimit = rateLimitHeaders(resp.Header)
+	if dumpBodies && resp.Body != nil {
+		prefix, err := io.ReadAll(io.LimitReader(resp.Body, MaxBodySize))
+		if err != nil {
+			entry.Error = fmt.Sprintf("failed to read response body: %v", err)
+		}
+		entry.ResponseBody = string(prefix)
+		resp.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(prefix), resp.Body), Closer: resp.Body}
+	}
+	t.recorder.record(entry)
+	return resp, nil
+}
+
+// prefixedBody replays the part of a response body read for the dump before the rest
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// readPrefix returns the first MaxBodySize bytes of body
+func readPrefix(body io.Reader) string {
+	prefix, _ := io.ReadAll(io.LimitReader(body, MaxBodySize))
+	return string(prefix)
+}
+
+// rateLimitHeaders returns the rate limit headers of a response, like X-RateLimit-Remaining
+// on GitHub and Gitea, RateLimit-Remaining on GitLab and Retry-After
+func rateLimitHeaders(header http.Header) map[string]string {
+	var headers map[string]string
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		if !strings.Contains(lower, "ratelimit") && lower != "retry-after" {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// SanitizeURL returns u without credentials, replacing the value of query parameters
+// that look like secrets, such as private_token or access_token
+func SanitizeURL(u *url.URL) string {
+	sanitized := *u
+	sanitized.User = nil
+	if sanitized.RawQuery != "" {
+		query := sanitized.Query()
+		for name := range query {
+			if isSensitiveParam(name) {
+				query.Set(name, redacted)
+			}
+		}
+		sanitized.RawQuery = query.Encode()
+	}
+	return sanitized.String()
+}
+
+// isSensitiveParam reports whether a query parameter may carry a secret
+func isSensitiveParam(name string) bool {
+	lower := strings.ToLower(name)
+	for _, sensitive := range sensitiveParams {
+		if strings.Contains(lower, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,253 @@
+package orchestration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/filelock"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// ConnectionCacheFile is the name of the connection cache inside the cache directory
+const ConnectionCacheFile = "connections.json"
+
+// replayToken stands in for missing tokens when replaying a session
+const replayToken = "replay"
+
+// ConnectionCacheTTL is how long a successful connection test is trusted by later runs
+const ConnectionCacheTTL = 5 * time.Minute
+
+// ConnectionCache remembers successful connection tests by platform host and token, so runs
+// started shortly after each other skip the authentication round-trip. Tokens are only
+// stored hashed.
+type ConnectionCache struct {
+	path    string
+	ttl     time.Duration
+	clock   utils.Clock
+	entries map[string]time.Time // Time of the last successful test by connection key
+	changed bool
+	mu      sync.Mutex
+}
+
+// LoadConnectionCache reads the connection cache from the cache directory, starting empty
+// when none exists
+func LoadConnectionCache(cacheDir string, ttl time.Duration, clock utils.Clock) (*ConnectionCache, error) {
+	cache := &ConnectionCache{
+		path:    filepath.Join(cacheDir, ConnectionCacheFile),
+		ttl:     ttl,
+		clock:   clock,
+		entries: make(map[string]time.Time),
+	}
+
+	data, err := os.ReadFile(cache.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connection cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse connection cache %s: %w", cache.path, err)
+	}
+	return cache, nil
+}
+
+// Verified reports whether the connection was tested successfully within the TTL
+func (c *ConnectionCache) Verified(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	testedAt, exists := c.entries[key]
+	return exists && c.clock.Now().Sub(testedAt) < c.ttl
+}
+
+// Record remembers a successful connection test
+func (c *ConnectionCache) Record(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = c.clock.Now()
+	c.changed = true
+}
+
+// Save writes the cache without its expired entries, when a connection was recorded. The
+// entries are merged with the cache on disk, keeping the latest test of each connection, so
+// runs sharing the cache directory keep each other's entries.
+func (c *ConnectionCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.changed {
+		return nil
+	}
+
+	// The keys are derived from tokens, so the file is only readable by its owner
+	err := filelock.Update(c.path, 0600, func(current []byte) ([]byte, error) {
+		merged := make(map[string]time.Time)
+		if current != nil {
+			if err := json.Unmarshal(current, &merged); err != nil {
+				// An unreadable cache only costs connection tests, so it is replaced
+				merged = make(map[string]time.Time)
+			}
+		}
+		for key, testedAt := range c.entries {
+			if testedAt.After(merged[key]) {
+				merged[key] = testedAt
+			}
+		}
+		for key, testedAt := range merged {
+			if c.clock.Now().Sub(testedAt) >= c.ttl {
+				delete(merged, key)
+			}
+		}
+
+		data, err := json.MarshalIndent(merged, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode connection cache: %w", err)
+		}
+		c.entries = merged
+		return data, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write connection cache: %w", err)
+	}
+	c.changed = false
+	return nil
+}
+
+// connectionKey identifies a connection without revealing its token
+func connectionKey(platform models.Platform, baseURL, token string) string {
+	sum := sha256.Sum256([]byte(string(platform) + "\n" + baseURL + "\n" + token))
+	return hex.EncodeToString(sum[:])
+}
+
+// platformBaseURL returns the configured API base URL of a platform
+func platformBaseURL(platform models.Platform, config *models.Config) string {
+	switch platform {
+	case models.PlatformGitHub:
+		return config.GitHub.BaseURL
+	case models.PlatformGitLab:
+		return config.GitLab.BaseURL
+	case models.PlatformGitea:
+		return config.Gitea.BaseURL
+	default:
+		return ""
+	}
+}
+
+// platformConnection is the outcome of connecting to a platform before its repositories
+// are processed
+type platformConnection struct {
+	token     string
+	tokenErr  error // Set when no token was found
+	clone     bool  // Repositories are cloned with git instead of read through the API
+	transport http.RoundTripper
+	provider  adapters.Provider // API provider, nil when cloning
+	failure   string            // What failed for the whole platform, like "Connection test failed"
+	err       error             // Cause of the failure
+}
+
+// connectPlatforms connects to every remote platform concurrently, before any repository
+// is processed, so authentication problems surface up front
+func (o *Orchestrator) connectPlatforms(ctx context.Context, reposByPlatform map[models.Platform][]*models.RepositoryInfo, cache *ConnectionCache) map[models.Platform]*platformConnection {
+	connections := make(map[models.Platform]*platformConnection, len(reposByPlatform))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for platform, repoInfos := range reposByPlatform {
+		// Offline runs serve platform repositories from recorded state instead
+		if platform == models.PlatformLocal || o.cliOptions.Offline {
+			continue
+		}
+		wg.Add(1)
+		go func(platform models.Platform, repoInfos []*models.RepositoryInfo) {
+			defer wg.Done()
+			connection := o.connect(ctx, platform, repoInfos, cache)
+			mu.Lock()
+			connections[platform] = connection
+			mu.Unlock()
+		}(platform, repoInfos)
+	}
+	wg.Wait()
+	return connections
+}
+
+// connect gets the token of a platform and tests its connection, unless a recent run did.
+// Without a token, repositories are cloned with git instead of failing.
+func (o *Orchestrator) connect(ctx context.Context, platform models.Platform, repoInfos []*models.RepositoryInfo, cache *ConnectionCache) *platformConnection {
+	connection := &platformConnection{
+		clone:     o.cliOptions.Strategy == models.StrategyClone,
+		transport: o.transport(),
+	}
+
+	connection.token, connection.tokenErr = GetTokenForPlatform(platform, o.config, o.cliOptions.Token)
+	if connection.tokenErr != nil && o.cliOptions.Replay != "" {
+		// Replayed responses need no credentials, so a placeholder stands in for the token
+		connection.token, connection.tokenErr = replayToken, nil
+	}
+	if connection.tokenErr != nil && !connection.clone {
+		// Sessions only capture HTTP requests, so recorded runs do not fall back to git
+		if !hasRepositories(repoInfos) || o.cliOptions.Record != "" {
+			logger.Logger.WithError(connection.tokenErr).WithField("platform", platform).Error("Failed to get token for platform")
+			connection.failure, connection.err = "Failed to get token for platform", connection.tokenErr
+			return connection
+		}
+		logger.Logger.WithError(connection.tokenErr).WithField("platform", platform).Warn("No token found, cloning repositories with git")
+		connection.clone = true
+	}
+	if connection.clone {
+		return connection
+	}
+
+	provider, err := adapters.CreateProviderWithTransport(platform, o.config, connection.token, connection.transport)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("platform", platform).Error("Failed to create provider")
+		connection.failure, connection.err = "Failed to create provider for platform", err
+		return connection
+	}
+	connection.provider = provider
+
+	// Test connection (skip in dry run mode)
+	if o.cliOptions.DryRun {
+		logger.Logger.WithField("platform", platform).Info("[DRY RUN] Skipping connection test")
+		return connection
+	}
+	key := connectionKey(platform, platformBaseURL(platform, o.config), connection.token)
+	if cache != nil && cache.Verified(key) {
+		logger.Logger.WithField("platform", platform).Info("Connection verified by a recent run")
+		return connection
+	}
+	logger.Logger.WithField("platform", platform).Info("Testing connection...")
+	if err := provider.TestConnection(ctx); err != nil {
+		logger.Logger.WithError(err).WithField("platform", platform).Error("Connection test failed")
+		connection.failure, connection.err = "Connection test failed for platform", err
+		return connection
+	}
+	logger.Logger.WithField("platform", platform).Info("Connection successful")
+	if cache != nil {
+		cache.Record(key)
+	}
+	return connection
+}
+
+// hasRepositories reports whether inputs include repositories, which can be cloned
+// without a token unlike gists, snippets and groups listed through the API
+func hasRepositories(repoInfos []*models.RepositoryInfo) bool {
+	for _, repoInfo := range repoInfos {
+		if repoInfo.Kind != models.KindSnippet && repoInfo.Kind != models.KindGroup {
+			return true
+		}
+	}
+	return false
+}
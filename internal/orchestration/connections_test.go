@@ -0,0 +1,140 @@
+package orchestration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sherpa/internal/config"
+	"sherpa/internal/fakevcs"
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionCache(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	key := connectionKey(models.PlatformGitHub, "https://api.github.com", "ghp_secret")
+
+	t.Run("should trust recorded connections within the TTL", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, utils.FixedClock{Time: start})
+		require.NoError(t, err)
+		assert.False(t, cache.Verified(key))
+
+		cache.Record(key)
+		require.NoError(t, cache.Save())
+
+		later, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, utils.FixedClock{Time: start.Add(4 * time.Minute)})
+		require.NoError(t, err)
+		assert.True(t, later.Verified(key))
+		assert.False(t, later.Verified(connectionKey(models.PlatformGitHub, "https://api.github.com", "ghp_other")), "other tokens should be tested")
+
+		expired, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, utils.FixedClock{Time: start.Add(6 * time.Minute)})
+		require.NoError(t, err)
+		assert.False(t, expired.Verified(key))
+	})
+
+	t.Run("should not store tokens and drop expired entries", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		cache, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, utils.FixedClock{Time: start})
+		require.NoError(t, err)
+		cache.Record(key)
+		require.NoError(t, cache.Save())
+
+		data, err := os.ReadFile(filepath.Join(cacheDir, ConnectionCacheFile))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "ghp_secret")
+
+		later, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, utils.FixedClock{Time: start.Add(time.Hour)})
+		require.NoError(t, err)
+		otherKey := connectionKey(models.PlatformGitLab, "https://gitlab.com", "glpat")
+		later.Record(otherKey)
+		require.NoError(t, later.Save())
+
+		reloaded, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, utils.FixedClock{Time: start.Add(time.Hour)})
+		require.NoError(t, err)
+		assert.Len(t, reloaded.entries, 1)
+		assert.True(t, reloaded.Verified(otherKey))
+	})
+
+	t.Run("should keep the connections saved by concurrent runs", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		clock := utils.FixedClock{Time: start}
+		first, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, clock)
+		require.NoError(t, err)
+		second, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, clock)
+		require.NoError(t, err)
+
+		otherKey := connectionKey(models.PlatformGitLab, "https://gitlab.com", "glpat")
+		first.Record(key)
+		second.Record(otherKey)
+		require.NoError(t, first.Save())
+		require.NoError(t, second.Save())
+
+		reloaded, err := LoadConnectionCache(cacheDir, ConnectionCacheTTL, clock)
+		require.NoError(t, err)
+		assert.True(t, reloaded.Verified(key))
+		assert.True(t, reloaded.Verified(otherKey))
+	})
+}
+
+func TestOrchestrator_ConnectionCache(t *testing.T) {
+	t.Run("should skip the connection test of a recently verified platform", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = true
+		cfg.Cache.Directory = t.TempDir()
+
+		run := func() int {
+			before := server.Requests()
+			orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+			err := orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+				models.PlatformGitHub: {
+					{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"},
+				},
+			})
+			require.NoError(t, err)
+			require.NoError(t, orchestrator.Err())
+			return server.Requests() - before
+		}
+
+		first := run()
+		assert.FileExists(t, filepath.Join(cfg.Cache.Directory, ConnectionCacheFile))
+		second := run()
+		assert.Equal(t, first-1, second, "the second run should not test the connection again")
+	})
+
+	t.Run("should report platforms that fail the connection test", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = true
+		cfg.Cache.Directory = t.TempDir()
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: "invalid", Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitHub: {
+				{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Error(t, orchestrator.Err())
+		assert.NoFileExists(t, filepath.Join(cfg.Cache.Directory, ConnectionCacheFile))
+	})
+}
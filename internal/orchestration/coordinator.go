@@ -2,17 +2,28 @@ package orchestration
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"sherpa/internal/adapters"
+	"sherpa/internal/adapters/download"
+	"sherpa/internal/adapters/gitclone"
+	"sherpa/internal/faults"
 	"sherpa/internal/generators"
+	"sherpa/internal/httpdebug"
 	"sherpa/internal/pipeline"
+	"sherpa/internal/vision"
+	sherpaerrors "sherpa/pkg/errors"
 	"sherpa/pkg/logger"
 	"sherpa/pkg/models"
+	"sherpa/pkg/ui"
 	"sherpa/pkg/utils"
 )
 
@@ -20,6 +31,25 @@ import (
 type Orchestrator struct {
 	config     *models.Config
 	cliOptions *models.CLIOptions
+	lock       *LockFile // Commits recorded this run, or loaded from disk with --locked
+	writer     *OutputWriter
+	failures   []error             // Classified failures reported through Err
+	summary    RunSummary          // Combined totals of the last run
+	outcomes   []repositoryOutcome // Result of each repository, printed in quiet mode
+	failuresMu sync.Mutex          // Protects failures, summary and outcomes
+	clock      utils.Clock
+	newRunID   func(start time.Time) string
+	runID      string
+	faults     *faults.Injector       // Injects random API failures when set (--fault-inject)
+	recorder   *httpdebug.Recorder    // Records HTTP requests when set (--debug-http)
+	base       http.RoundTripper      // Sends requests, recording or replaying them with --record and --replay
+	throttler  *adapters.Throttler    // Paces platform requests by their rate limits, waiting up to max_wait
+	printer    *ui.Printer            // Messages shown to users, separate from logs
+	repoLogs   *logger.RepositoryLogs // Entries logged about each repository, written to its output directory with repo_logs
+	deadline   time.Time              // When fetching stops with --budget-time, zero without a budget
+	stop       context.CancelFunc     // Cancels the run at the first failure with --fail-fast
+	stopped    atomic.Bool            // Set once --fail-fast stopped the run
+	progress   func(Progress)         // Receives the stages reached by repositories, when set
 }
 
 // NewOrchestrator creates a new orchestrator instance
@@ -27,25 +57,194 @@ func NewOrchestrator(config *models.Config, cliOptions *models.CLIOptions) *Orch
 	return &Orchestrator{
 		config:     config,
 		cliOptions: cliOptions,
+		writer:     NewOutputWriter(config.Output.WriteWorkers, config.Output.Fsync),
+		clock:      utils.SystemClock{},
+		newRunID:   utils.NewRunID,
+		printer:    ui.Default(),
+		throttler:  adapters.NewThrottler(config.HTTP.MaxWait),
 	}
 }
 
+// SetPrinter replaces the printer of progress, summaries and failures
+func (o *Orchestrator) SetPrinter(printer *ui.Printer) {
+	o.printer = printer
+}
+
+// SetClock replaces the clock used for timestamps and dated output directories
+func (o *Orchestrator) SetClock(clock utils.Clock) {
+	o.clock = clock
+}
+
+// SetRunIDGenerator replaces the function generating run identifiers
+func (o *Orchestrator) SetRunIDGenerator(newRunID func(start time.Time) string) {
+	o.newRunID = newRunID
+}
+
+// SetFaultInjector makes provider API requests fail randomly for resilience testing
+func (o *Orchestrator) SetFaultInjector(injector *faults.Injector) {
+	o.faults = injector
+}
+
+// SetHTTPRecorder records the requests sent to platforms and package registries
+func (o *Orchestrator) SetHTTPRecorder(recorder *httpdebug.Recorder) {
+	o.recorder = recorder
+}
+
+// SetBaseTransport replaces the transport sending platform and download requests, like the
+// transports of recorded and replayed sessions
+func (o *Orchestrator) SetBaseTransport(transport http.RoundTripper) {
+	o.base = transport
+}
+
+// transport returns the transport of platform and download requests, carrying the
+// configured headers and injected faults and paced by rate limits. The recorder wraps the
+// others, so injected faults and retried requests show up in the debug output.
+func (o *Orchestrator) transport() http.RoundTripper {
+	// Offline runs refuse every request, so nothing slips through to the network
+	if o.cliOptions.Offline {
+		return adapters.NewOfflineTransport()
+	}
+	transport := adapters.NewHeaderTransport(o.base, o.config.HTTP)
+	if o.faults != nil {
+		transport = o.faults.Transport(transport)
+	}
+	transport = o.throttler.Transport(transport)
+	if o.recorder != nil {
+		transport = o.recorder.Transport(transport)
+	}
+	return transport
+}
+
+// cloneRepository shallow-clones a repository with git, at its pinned commit with --locked,
+// and creates a provider serving the clone
+func (o *Orchestrator) cloneRepository(ctx context.Context, repoInfo *models.RepositoryInfo, platform models.Platform, token string) (adapters.Provider, string, error) {
+	remote, err := adapters.CloneURL(repoInfo, o.config)
+	if err != nil {
+		return nil, "", err
+	}
+
+	options := gitclone.Options{URL: remote, Ref: repoInfo.Branch}
+	if o.cliOptions.Locked && repoInfo.Kind == "" {
+		entry, exists := o.lock.Lookup(platform, repoInfo.FullName, repoInfo.Branch)
+		if !exists {
+			return nil, "", fmt.Errorf("repository not found in lock file %s", o.config.Output.LockFile)
+		}
+		options.Ref = entry.Commit
+	}
+	if token != "" {
+		options.Username = adapters.CloneUsername(platform)
+		options.Token = token
+	}
+
+	webURL := repoInfo.URL
+	if webURL == "" {
+		webURL = strings.TrimSuffix(remote, ".git")
+	}
+	return adapters.CreateGitCloneProvider(ctx, options, repoInfo.Name, webURL, "")
+}
+
+// RunID returns the identifier of the current or last run
+func (o *Orchestrator) RunID() string {
+	return o.runID
+}
+
 // ProcessRepositories processes repositories grouped by platform
 func (o *Orchestrator) ProcessRepositories(ctx context.Context, reposByPlatform map[models.Platform][]*models.RepositoryInfo) error {
+	startTime := o.clock.Now()
+	o.runID = o.newRunID(startTime)
+	o.summary = RunSummary{}
+	o.outcomes = nil
+	o.deadline = fetchDeadline(startTime, o.config.Processing.BudgetTime)
+	o.stop = nil
+	o.stopped.Store(false)
+
+	// Stop processing repositories at the first failure
+	if o.cliOptions.FailFast {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		o.stop = cancel
+	}
+
 	// Create LLMs generator
-	logger.Logger.Debug("Creating LLMs generator")
-	llmsGenerator := generators.NewGenerator(true)
+	logger.Logger.WithField("run_id", o.runID).Debug("Creating LLMs generator")
+	llmsGenerator := generators.NewGeneratorWithConfig(true, o.config.Output)
+	llmsGenerator.SetClock(o.clock)
+
+	// Collect the entries logged about each repository for its own log file
+	o.repoLogs = nil
+	if o.config.Output.RepoLogs && !o.cliOptions.DryRun {
+		o.repoLogs = logger.NewRepositoryLogs()
+		stop := logger.CaptureRepositories(o.repoLogs)
+		defer stop()
+	}
+
+	// Load pinned commits or start a fresh lock file
+	if o.cliOptions.Locked {
+		lock, err := LoadLockFile(o.config.Output.LockFile)
+		if err != nil {
+			return err
+		}
+		o.lock = lock
+		logger.Logger.WithFields(map[string]interface{}{
+			"lock_file":    o.config.Output.LockFile,
+			"repositories": lock.Len(),
+		}).Info("Using pinned commits from lock file")
+	} else {
+		o.lock = NewLockFile()
+	}
+
+	// Load the skip list of files that keep failing, unless failures are injected on purpose
+	var skipList *pipeline.SkipList
+	if o.config.Cache.Enabled && o.faults == nil {
+		var err error
+		skipList, err = pipeline.LoadSkipList(o.config.Cache.Directory, o.config.Cache.SkipAfter, o.config.Cache.TTL)
+		if err != nil {
+			logger.Logger.WithError(err).Warn("Failed to load skip list, retrying all files")
+		}
+	}
+
+	// Let the user review the files of each repository before fetching them
+	var reviewer pipeline.FileReviewer
+	if o.cliOptions.Review && !o.cliOptions.DryRun {
+		reviewer = pipeline.NewInteractiveReviewer(os.Stdin, os.Stdout)
+	}
 
 	// Process repositories by platform
 	totalRepos := 0
 	for _, repos := range reposByPlatform {
 		totalRepos += len(repos)
 	}
-	logger.Logger.WithField("total_repos", totalRepos).Info("Starting repository processing")
+	o.summary.Repositories = totalRepos
+	markRefScoped(reposByPlatform)
+	logger.Logger.WithFields(map[string]interface{}{
+		"run_id":      o.runID,
+		"total_repos": totalRepos,
+	}).Info("Starting repository processing")
+
+	if o.faults != nil {
+		logger.Logger.WithField("seed", o.faults.Seed()).Warn("Fault injection enabled, API requests will fail randomly")
+	}
+
+	// Test the connection of every platform up front, trusting recent successful tests
+	var connectionCache *ConnectionCache
+	// Sessions capture every connection test, so recorded and replayed runs skip the cache
+	if o.config.Cache.Enabled && !o.cliOptions.DryRun && o.cliOptions.Record == "" && o.cliOptions.Replay == "" {
+		var err error
+		connectionCache, err = LoadConnectionCache(o.config.Cache.Directory, ConnectionCacheTTL, o.clock)
+		if err != nil {
+			logger.Logger.WithError(err).Warn("Failed to load connection cache, testing all connections")
+		}
+	}
+	connections := o.connectPlatforms(ctx, reposByPlatform, connectionCache)
+	if connectionCache != nil {
+		if err := connectionCache.Save(); err != nil {
+			logger.Logger.WithError(err).Warn("Failed to save connection cache")
+		}
+	}
 
 	// Process platforms concurrently
 	var platformWg sync.WaitGroup
-	var platformMu sync.Mutex // Protect stdout/stderr writes
 
 	for platform, repoInfos := range reposByPlatform {
 		platformWg.Add(1)
@@ -55,99 +254,281 @@ func (o *Orchestrator) ProcessRepositories(ctx context.Context, reposByPlatform
 
 			logger.Logger.WithField("platform", platform).Info("Processing repositories for platform")
 
-			// Get token for this platform (skip for local platform)
-			var platformToken string
-			var err error
-			if platform != models.PlatformLocal {
-				platformToken, err = GetTokenForPlatform(platform, o.config, o.cliOptions.Token)
-				if err != nil {
-					logger.Logger.WithError(err).WithField("platform", platform).Error("Failed to get token for platform")
-
-					platformMu.Lock()
-					fmt.Fprintf(os.Stderr, "Failed to get token for platform %s: %v\n", platform, err)
-					platformMu.Unlock()
-					return
+			// Stop when the platform could not be reached
+			connection := connections[platform]
+			if connection != nil && connection.err != nil {
+				o.printFailure(connection.err, "%s %s: %v", connection.failure, platform, connection.err)
+				o.recordFailure(connection.err)
+				for _, repoInfo := range repoInfos {
+					o.recordOutcome(repositoryOutcome{status: outcomeFailed, platform: platform, repository: repoInfo.FullName, err: connection.err})
 				}
+				return
 			}
 
-			// Create provider for this platform
-			var provider adapters.Provider
-			if platform == models.PlatformLocal {
-				// For local platform, use the folder path from the first repository
-				if len(repoInfos) > 0 {
-					provider, err = adapters.CreateLocalProvider(repoInfos[0].FullName)
-					if err != nil {
-						logger.Logger.WithError(err).WithField("platform", platform).Error("Failed to create local provider")
+			// Replace organizations and groups with their repositories
+			if connection != nil {
+				repoInfos = o.expandGroups(ctx, platform, connection, repoInfos)
+			}
 
-						platformMu.Lock()
-						fmt.Fprintf(os.Stderr, "Failed to create local provider for platform %s: %v\n", platform, err)
-						platformMu.Unlock()
-						return
+			// Downloads and clones are fetched into temporary directories removed once the platform is done
+			var downloadDirs []string
+			var downloadMu sync.Mutex
+			defer func() {
+				for _, dir := range downloadDirs {
+					if err := os.RemoveAll(dir); err != nil {
+						logger.Logger.WithError(err).WithField("dir", dir).Warn("Failed to remove download directory")
 					}
-				} else {
-					logger.Logger.WithField("platform", platform).Error("No repositories provided for local platform")
-					platformMu.Lock()
-					fmt.Fprintf(os.Stderr, "No repositories provided for local platform\n")
-					platformMu.Unlock()
-					return
-				}
-			} else {
-				provider, err = adapters.CreateProvider(platform, o.config, platformToken)
-				if err != nil {
-					logger.Logger.WithError(err).WithField("platform", platform).Error("Failed to create provider")
-
-					platformMu.Lock()
-					fmt.Fprintf(os.Stderr, "Failed to create provider for platform %s: %v\n", platform, err)
-					platformMu.Unlock()
-					return
 				}
+			}()
+			addDownloadDir := func(dir string) {
+				downloadMu.Lock()
+				downloadDirs = append(downloadDirs, dir)
+				downloadMu.Unlock()
 			}
 
-			// Test connection (skip in dry run mode)
-			if !o.cliOptions.DryRun {
-				logger.Logger.WithField("platform", platform).Info("Testing connection...")
-				if err := provider.TestConnection(ctx); err != nil {
-					logger.Logger.WithError(err).WithField("platform", platform).Error("Connection test failed")
+			var processorFor processorFactory
+			if platform == models.PlatformLocal {
+				// Each local folder gets its own provider, rooted at the folder
+				processorFor = func(ctx context.Context, repoInfo *models.RepositoryInfo) (*pipeline.RepoProcessor, error) {
+					// Downloads, packages and git remotes are fetched into a temporary folder first
+					if repoInfo.Kind != "" {
+						// Dry runs do not fetch anything, so there is nothing to download
+						if o.cliOptions.DryRun {
+							return nil, nil
+						}
+						if o.cliOptions.Offline {
+							return nil, fmt.Errorf("%s cannot be fetched: %w", repoInfo.FullName, adapters.ErrOffline)
+						}
+						client := &http.Client{Transport: o.transport()}
+						var provider adapters.Provider
+						var dir string
+						var err error
+						switch repoInfo.Kind {
+						case models.KindGoModule:
+							provider, dir, err = adapters.CreateGoModuleProvider(ctx, client, download.GoProxy(), repoInfo, "")
+						case models.KindNPMPackage:
+							provider, dir, err = adapters.CreateNPMPackageProvider(ctx, client, download.NPMRegistry(), repoInfo, "")
+						case models.KindPyPIPackage:
+							provider, dir, err = adapters.CreatePyPIPackageProvider(ctx, client, download.DefaultPyPIURL, repoInfo, "")
+						case models.KindGitClone:
+							provider, dir, err = o.cloneRepository(ctx, repoInfo, platform, "")
+						default:
+							provider, dir, err = adapters.CreateDownloadProvider(ctx, client, repoInfo.URL, "")
+						}
+						if err != nil {
+							return nil, err
+						}
+						addDownloadDir(dir)
+						return o.newRepoProcessor(platform, provider, skipList, reviewer), nil
+					}
 
-					platformMu.Lock()
-					fmt.Fprintf(os.Stderr, "Connection test failed for platform %s: %v\n", platform, err)
-					platformMu.Unlock()
-					return
+					provider, err := adapters.CreateLocalProvider(repoInfo.FullName, o.config.Local)
+					if err != nil {
+						return nil, fmt.Errorf("failed to create local provider: %w", err)
+					}
+					if !o.cliOptions.DryRun {
+						if err := provider.TestConnection(ctx); err != nil {
+							return nil, err
+						}
+					}
+					return o.newRepoProcessor(platform, provider, skipList, reviewer), nil
+				}
+			} else if o.cliOptions.Offline {
+				// Each repository is served from the state of a previous run
+				processorFor = func(_ context.Context, repoInfo *models.RepositoryInfo) (*pipeline.RepoProcessor, error) {
+					return o.offlineProcessor(repoInfo, platform, skipList, reviewer)
 				}
-				logger.Logger.WithField("platform", platform).Info("Connection successful")
 			} else {
-				logger.Logger.WithField("platform", platform).Info("[DRY RUN] Skipping connection test")
-			}
+				// Share one processor between the gists or snippets of this platform, created on
+				// first use. They have no git remote, so they always go through the API.
+				var snippetProcessor *pipeline.RepoProcessor
+				var snippetErr error
+				var snippetOnce sync.Once
+				snippetProcessorFor := func() (*pipeline.RepoProcessor, error) {
+					if connection.tokenErr != nil {
+						return nil, connection.tokenErr
+					}
+					snippetOnce.Do(func() {
+						snippetProvider, err := adapters.CreateSnippetProviderWithTransport(platform, o.config, connection.token, connection.transport)
+						if err != nil {
+							snippetErr = fmt.Errorf("failed to create snippet provider: %w", err)
+							return
+						}
+						snippetProcessor = o.newRepoProcessor(platform, snippetProvider, skipList, reviewer)
+					})
+					return snippetProcessor, snippetErr
+				}
 
-			// Create processor for this platform
-			logger.Logger.Debug("Creating repository processor")
-			repoProcessor := pipeline.NewRepoProcessor(provider, o.config.Processing)
+				if connection.clone {
+					// Each repository gets its own provider, rooted at its clone
+					processorFor = func(ctx context.Context, repoInfo *models.RepositoryInfo) (*pipeline.RepoProcessor, error) {
+						if repoInfo.Kind == models.KindSnippet {
+							return snippetProcessorFor()
+						}
+						// Dry runs do not fetch anything, so there is nothing to clone
+						if o.cliOptions.DryRun {
+							return nil, nil
+						}
+						provider, dir, err := o.cloneRepository(ctx, repoInfo, platform, connection.token)
+						if err != nil && connection.tokenErr != nil {
+							// Public repositories clone without a token, so the missing token is the likely cause
+							return nil, fmt.Errorf("%w (cloning without a token failed: %v)", connection.tokenErr, err)
+						}
+						if err != nil {
+							return nil, err
+						}
+						addDownloadDir(dir)
+						return o.newRepoProcessor(platform, provider, skipList, reviewer), nil
+					}
+				} else {
+					// Share one processor between the repositories of this platform
+					repoProcessor := o.newRepoProcessor(platform, connection.provider, skipList, reviewer)
+					processorFor = func(_ context.Context, repoInfo *models.RepositoryInfo) (*pipeline.RepoProcessor, error) {
+						if repoInfo.Kind == models.KindSnippet {
+							return snippetProcessorFor()
+						}
+						return repoProcessor, nil
+					}
+				}
+			}
 
 			// Process repositories concurrently within this platform
-			if err := o.processRepositoriesConcurrently(ctx, repoInfos, platform, repoProcessor, llmsGenerator, &platformMu); err != nil {
+			if err := o.processRepositoriesConcurrently(ctx, repoInfos, platform, processorFor, llmsGenerator); err != nil {
 				logger.Logger.WithError(err).WithField("platform", platform).Error("Failed to process repositories concurrently")
 
-				platformMu.Lock()
-				fmt.Fprintf(os.Stderr, "Failed to process repositories for platform %s: %v\n", platform, err)
-				platformMu.Unlock()
+				o.printer.Errorf("Failed to process repositories for platform %s: %v", platform, err)
 			}
 		}(platform, repoInfos)
 	}
 
 	platformWg.Wait()
 
-	logger.Logger.Info("Sherpa fetch operation completed successfully")
+	// Combine the results when several repositories were processed
+	summary := o.Summary()
+	if summary.Repositories > 1 && !o.cliOptions.Quiet && !o.cliOptions.DryRun {
+		block := o.printer.Block()
+		switch {
+		case summary.Skipped > 0:
+			block.Warning("Processed %d of %d repositories (%d failed, %d skipped after the first failure)", summary.Succeeded, summary.Repositories, summary.Failed(), summary.Skipped)
+		case summary.Failed() > 0:
+			block.Warning("Processed %d of %d repositories (%d failed)", summary.Succeeded, summary.Repositories, summary.Failed())
+		default:
+			block.Success("Processed %d of %d repositories (%d failed)", summary.Succeeded, summary.Repositories, summary.Failed())
+		}
+		block.Field("Files included", "%d", summary.Files).
+			Field("Total size", "%s", utils.FormatBytes(summary.Size)).
+			Field("Duration", "%s", o.clock.Now().Sub(startTime).Round(time.Millisecond))
+		writeRateLimits(block, o.throttler.Status())
+		block.Flush()
+	}
+
+	for _, limit := range o.throttler.Status().Limits {
+		logger.Logger.WithFields(map[string]interface{}{
+			"host":      limit.Host,
+			"resource":  limit.Resource,
+			"remaining": limit.Remaining,
+			"limit":     limit.Limit,
+			"reset":     limit.Reset.Format(time.RFC3339),
+		}).Debug("Rate limit quota at the end of the run")
+	}
+
+	// Scripts capture one line per repository even when progress output is suppressed
+	if o.cliOptions.Quiet && !o.cliOptions.DryRun {
+		o.printOutcomes()
+	}
+
+	if o.faults != nil {
+		injected := o.faults.Injected()
+		logger.Logger.WithFields(map[string]interface{}{
+			"seed":    o.faults.Seed(),
+			"timeout": injected[faults.KindTimeout],
+			"429":     injected[faults.KindRateLimited],
+			"500":     injected[faults.KindServerError],
+		}).Warn("Fault injection summary")
+	}
+
+	// The run summary records timings and the run ID, so deterministic runs leave it out
+	if !o.cliOptions.DryRun && !o.config.Output.Deterministic {
+		if err := o.writeRunSummary(startTime); err != nil {
+			logger.Logger.WithError(err).Warn("Failed to write run summary")
+		}
+	}
+
+	// Multi-repository runs get a page linking the outputs of every repository
+	if summary.Repositories > 1 && !o.cliOptions.DryRun {
+		if err := o.writeIndex(); err != nil {
+			logger.Logger.WithError(err).Warn("Failed to write index")
+		}
+	}
+
+	if skipList != nil && !o.cliOptions.DryRun {
+		if err := skipList.Save(); err != nil {
+			logger.Logger.WithError(err).Warn("Failed to save skip list")
+		}
+	}
+
+	// Record the commits used so the run can be reproduced with --locked
+	if !o.cliOptions.Locked && !o.cliOptions.DryRun && o.config.Output.LockFile != "" && o.lock.Len() > 0 {
+		if o.config.Output.Deterministic {
+			o.lock.OmitTimestamps = true
+		} else {
+			o.lock.RunID = o.runID
+			o.lock.GeneratedAt = startTime.UTC().Truncate(time.Second)
+		}
+		// The outputs are written by now, so a lock file that cannot be written only costs
+		// reproducing the run
+		if err := o.lock.Save(o.config.Output.LockFile); err != nil {
+			logger.Logger.WithError(err).Warn("Failed to write lock file")
+		} else {
+			logger.Logger.WithField("lock_file", o.config.Output.LockFile).Info("Wrote lock file")
+		}
+	}
+
+	logger.Logger.WithField("run_id", o.runID).Info("Sherpa fetch operation completed successfully")
 	return nil
 }
 
+// processorFactory returns the processor fetching a repository
+type processorFactory func(ctx context.Context, repoInfo *models.RepositoryInfo) (*pipeline.RepoProcessor, error)
+
+// newRepoProcessor creates a processor for provider sharing the run's skip list and reviewer,
+// fetching as many files at once as the platform allows
+func (o *Orchestrator) newRepoProcessor(platform models.Platform, provider adapters.Provider, skipList *pipeline.SkipList, reviewer pipeline.FileReviewer) *pipeline.RepoProcessor {
+	logger.Logger.Debug("Creating repository processor")
+	repoProcessor := pipeline.NewRepoProcessor(provider, o.config.ProcessingFor(platform))
+	repoProcessor.SetClock(o.clock)
+	if skipList != nil {
+		repoProcessor.SetSkipList(skipList)
+	}
+	if reviewer != nil {
+		repoProcessor.SetReviewer(reviewer)
+	}
+	if !o.deadline.IsZero() {
+		repoProcessor.SetDeadline(o.deadline)
+	}
+	if altText := o.config.Processing.AltText; altText.Include && !o.cliOptions.Offline {
+		repoProcessor.SetImageDescriber(vision.NewClient(altText, os.Getenv(altText.APIKeyEnv)))
+	}
+	return repoProcessor
+}
+
+// fetchDeadline returns when fetching stops for a run started at start with a time
+// budget, or zero without a budget. A tenth of the budget is kept to generate and write
+// the outputs once fetching stops.
+func fetchDeadline(start time.Time, budget time.Duration) time.Time {
+	if budget <= 0 {
+		return time.Time{}
+	}
+	return start.Add(budget - budget/10)
+}
+
 // processRepositoriesConcurrently processes multiple repositories concurrently within a platform
 func (o *Orchestrator) processRepositoriesConcurrently(
 	ctx context.Context,
 	repoInfos []*models.RepositoryInfo,
 	platform models.Platform,
-	repoProcessor *pipeline.RepoProcessor,
+	processorFor processorFactory,
 	llmsGenerator *generators.Generator,
-	platformMu *sync.Mutex,
 ) error {
 	maxConcurrency := o.cliOptions.MaxReposConcurrency
 	if maxConcurrency <= 0 {
@@ -174,7 +555,22 @@ func (o *Orchestrator) processRepositoriesConcurrently(
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			o.processRepository(ctx, repoInfo, platform, repoProcessor, llmsGenerator, platformMu)
+			if o.stopped.Load() {
+				o.recordSkipped(repoInfo.FullName, platform)
+				return
+			}
+
+			repoProcessor, err := processorFor(ctx, repoInfo)
+			if err != nil {
+				logger.Logger.WithError(err).WithField("repository", repoInfo.FullName).Error("Failed to create repository processor")
+
+				o.printFailure(err, "Failed to prepare repository %s: %v", repoInfo.FullName, err)
+				o.recordRepositoryFailure(repoInfo.FullName, platform, fmt.Errorf("%s: %w", repoInfo.FullName, err))
+				o.writeRepositoryLog(repoInfo, "")
+				return
+			}
+
+			o.processRepository(ctx, repoInfo, platform, repoProcessor, llmsGenerator)
 		}(repoInfo)
 	}
 
@@ -189,7 +585,6 @@ func (o *Orchestrator) processRepository(
 	platform models.Platform,
 	repoProcessor *pipeline.RepoProcessor,
 	llmsGenerator *generators.Generator,
-	platformMu *sync.Mutex,
 ) {
 	repoPath := repoInfo.FullName
 	logger.Logger.WithFields(map[string]interface{}{
@@ -201,24 +596,81 @@ func (o *Orchestrator) processRepository(
 
 	// Handle dry run mode
 	if o.cliOptions.DryRun {
-		o.processDryRun(ctx, repoInfo, platform, repoProcessor, platformMu)
+		o.processDryRun(ctx, repoInfo, platform, repoProcessor)
 		return
 	}
 
-	// Process repository
-	result, err := repoProcessor.ProcessRepository(ctx, repoPath, repoInfo.Branch)
+	o.reportProgress(Progress{Repository: repoPath, Stage: ProgressFetching})
+
+	// Write the entries logged about the repository next to its outputs, failed or not
+	var commit string
+	defer func() { o.writeRepositoryLog(repoInfo, commit) }()
+
+	// Pin the ref to a commit so the output can be reproduced later
+	ref, commit, err := o.resolveRef(ctx, repoInfo, platform, repoProcessor)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("repository", repoPath).Error("Failed to resolve commit")
+
+		o.printFailure(err, "Failed to resolve commit for %s: %v", repoPath, err)
+		o.recordRepositoryFailure(repoPath, platform, err)
+		return
+	}
+
+	// Process repository, reusing files unchanged since the previous incremental run or
+	// fetched before a previous run was rate limited at the same commit
+	var result *models.ProcessingResult
+	var statePath string
+	var previous *pipeline.RepoState
+	checkpoint := false
+	if commit != "" {
+		statePath = pipeline.StatePath(o.config.Output.Directory, outputName(repoInfo))
+		loaded, loadErr := pipeline.LoadRepoState(statePath)
+		if loadErr != nil {
+			logger.Logger.WithError(loadErr).WithField("repository", repoPath).Warn("Ignoring unreadable state file, fetching all files")
+		}
+		checkpoint = loaded != nil && loaded.Incomplete
+		if o.config.Output.Incremental || (checkpoint && loaded.Commit == commit) {
+			previous = loaded
+		}
+	}
+	if checkpoint && previous != nil {
+		logger.Logger.WithField("repository", repoPath).Info("Resuming from the checkpoint of a rate limited run")
+	}
+	if repoInfo.Subdirectory != "" {
+		repoProcessor = repoProcessor.WithSubdirectory(repoInfo.Subdirectory)
+	}
+	if o.config.Output.Incremental && commit != "" || previous != nil {
+		result, err = repoProcessor.ProcessRepositoryIncremental(ctx, repoPath, commit, previous)
+	} else {
+		result, err = repoProcessor.ProcessRepository(ctx, repoPath, ref)
+	}
 	if err != nil {
 		logger.Logger.WithError(err).WithFields(map[string]interface{}{
 			"repository": repoPath,
 			"platform":   platform,
 		}).Error("Failed to process repository")
 
-		platformMu.Lock()
-		fmt.Fprintf(os.Stderr, "Failed to process repository %s: %v\n", repoPath, err)
-		platformMu.Unlock()
+		block := o.printer.ErrorBlock().Error("Failed to process repository %s: %v", repoPath, err)
+		if hint := sherpaerrors.HintOf(err); hint != "" {
+			block.Field("Hint", "%s", hint)
+		}
+		var budgetErr *pipeline.BudgetExceededError
+		if errors.As(err, &budgetErr) {
+			for _, suggestion := range budgetErr.Suggestions {
+				block.Field("Suggestion", "%s", suggestion)
+			}
+		}
+		block.Flush()
+		o.recordRepositoryFailure(repoPath, platform, fmt.Errorf("%s: %w", repoPath, err))
 		return
 	}
 
+	// Capture the fetched files before outputs are generated from them
+	var state *pipeline.RepoState
+	if statePath != "" && (o.config.Output.Incremental || result.Incomplete != nil) {
+		state = pipeline.NewRepoState(commit, result)
+	}
+
 	// Report any errors encountered during processing
 	if len(result.Errors) > 0 {
 		logger.Logger.WithField("error_count", len(result.Errors)).WithField("repository", repoPath).Warn("Encountered errors during processing")
@@ -226,78 +678,398 @@ func (o *Orchestrator) processRepository(
 			logger.Logger.WithError(e).Debug("Processing error")
 		}
 		if o.cliOptions.Verbose {
-			platformMu.Lock()
-			fmt.Printf("Encountered %d errors during processing:\n", len(result.Errors))
+			block := o.printer.Block().Warning("Encountered %d errors during processing:", len(result.Errors))
 			for _, e := range result.Errors {
-				fmt.Printf("  - %v\n", e)
+				block.Line(1, "- [%s] %v", sherpaerrors.KindOf(e), e)
 			}
-			platformMu.Unlock()
+			block.Flush()
 		}
 	}
 
+	// Require acknowledgement before writing outputs that contain sensitive files
+	sensitiveFiles := pipeline.FindSensitiveFiles(result.Files, o.config.Sensitive.Patterns)
+	if len(sensitiveFiles) > 0 && !o.cliOptions.AckSensitive {
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository":      repoPath,
+			"sensitive_files": len(sensitiveFiles),
+		}).Error("Repository includes sensitive files; refusing to write output without --ack-sensitive")
+
+		o.printer.Errorf("Repository %s includes %d sensitive files (e.g. %s). Re-run with --ack-sensitive to proceed or exclude them with --ignore", repoPath, len(sensitiveFiles), sensitiveFiles[0])
+		o.recordRepositoryFailure(repoPath, platform, fmt.Errorf("%s: refusing to write output with %d sensitive files", repoPath, len(sensitiveFiles)))
+		return
+	}
+
 	// Generate LLMs output
 	logger.Logger.WithField("repository", repoPath).Debug("Generating LLMs output")
 	llmsOutput, err := llmsGenerator.GenerateOutput(result)
 	if err != nil {
 		logger.Logger.WithError(err).WithField("repository", repoPath).Error("Failed to generate LLMs output")
 
-		platformMu.Lock()
-		fmt.Fprintf(os.Stderr, "Failed to generate LLMs output for %s: %v\n", repoPath, err)
-		platformMu.Unlock()
+		o.printer.Errorf("Failed to generate LLMs output for %s: %v", repoPath, err)
+		o.recordRepositoryFailure(repoPath, platform, fmt.Errorf("%s: %w", repoPath, err))
 		return
 	}
 
-	// Create output directory
-	repoOutputDir := filepath.Join(o.config.Output.Directory, utils.SanitizeRepoName(repoPath))
-	if o.config.Output.OrganizeByDate {
-		dateDir := time.Now().Format("2006-01-02")
-		repoOutputDir = filepath.Join(o.config.Output.Directory, dateDir, utils.SanitizeRepoName(repoPath))
+	if len(sensitiveFiles) > 0 {
+		llmsOutput.Disclaimer = o.config.Sensitive.Disclaimer
+		llmsOutput.SensitiveFiles = sensitiveFiles
 	}
+	llmsOutput.Ref = ref
+	llmsOutput.Commit = commit
+
+	// Create output directory
+	repoOutputDir := o.repositoryOutputDir(repoInfo, commit)
 
 	logger.Logger.WithField("output_dir", repoOutputDir).Debug("Creating output directory")
 	if err := os.MkdirAll(repoOutputDir, 0755); err != nil {
 		logger.Logger.WithError(err).WithField("output_dir", repoOutputDir).Error("Failed to create output directory")
 
-		platformMu.Lock()
-		fmt.Fprintf(os.Stderr, "Failed to create output directory %s: %v\n", repoOutputDir, err)
-		platformMu.Unlock()
+		o.printer.Errorf("Failed to create output directory %s: %v", repoOutputDir, err)
+		o.recordRepositoryFailure(repoPath, platform, err)
 		return
 	}
 
-	// Generate and write llms-full.txt
-	logger.Logger.WithField("repository", repoPath).Debug("Generating llms-full.txt")
-	llmsFullText := llmsGenerator.GenerateLLMsFullText(llmsOutput)
-	llmsFullPath := filepath.Join(repoOutputDir, "llms-full.txt")
-	if err := WriteFile(llmsFullPath, llmsFullText); err != nil {
-		logger.Logger.WithError(err).WithField("file", llmsFullPath).Error("Failed to write llms-full.txt")
+	// Generate llms-full.txt, or llms-full.md and llms-full.xml in the Markdown and repomix
+	// formats, and llms.txt. All outputs are written concurrently below.
+	writeContext, writeIndex := OutputArtifacts(o.config.Output)
+	var outputFiles []OutputFile
+	contextFile := ""
+	if writeContext {
+		outputName := OutputFileName(o.config.Output.Format)
+		logger.Logger.WithField("repository", repoPath).WithField("file", outputName).Debug("Generating output")
+		var parts []string
+		switch o.config.Output.Format {
+		case models.FormatMarkdown:
+			parts = []string{llmsGenerator.GenerateMarkdown(llmsOutput)}
+		case models.FormatRepomix:
+			parts = []string{llmsGenerator.GenerateRepomix(llmsOutput)}
+		default:
+			parts = llmsGenerator.GenerateLLMsFullTextParts(llmsOutput)
+		}
+		if maxParts := o.config.Output.MaxParts; maxParts > 0 && len(parts) > maxParts {
+			err := fmt.Errorf("%s splits into %d parts, more than max_parts %d", outputName, len(parts), maxParts)
+			logger.Logger.WithError(err).WithField("repository", repoPath).Error("Failed to fit output")
+
+			o.printer.Errorf("Failed to fit outputs for %s: %v", repoPath, err)
+			o.recordRepositoryFailure(repoPath, platform, fmt.Errorf("%s: %w", repoPath, err))
+			return
+		}
+		outputFiles = append(outputFiles, OutputFile{Path: filepath.Join(repoOutputDir, outputName), Content: parts[0]})
+		if len(parts) > 1 {
+			// Split outputs are written as llms-full.part1.txt, llms-full.part2.txt, ...
+			outputFiles = outputFiles[:0]
+			for i, part := range parts {
+				outputFiles = append(outputFiles, OutputFile{Path: filepath.Join(repoOutputDir, PartFileName(o.config.Output.Format, i+1)), Content: part})
+			}
+			logger.Logger.WithField("repository", repoPath).WithField("parts", len(parts)).Debug("Split output into parts")
+		}
+		// llms.txt links the context file, or its first part when split
+		contextFile = filepath.Base(outputFiles[0].Path)
+	}
+	if writeIndex {
+		index := llmsGenerator.GenerateLLMsTxt(llmsOutput, contextFile)
+		if o.config.Output.LLMsTxtLegacy {
+			index = llmsGenerator.GenerateLLMsText(llmsOutput)
+		}
+		outputFiles = append(outputFiles, OutputFile{Path: filepath.Join(repoOutputDir, LLMsTxtFileName), Content: index})
+	}
+	if o.config.Output.TreeJSON {
+		treeJSON, err := llmsGenerator.GenerateTreeJSON(llmsOutput)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Error("Failed to generate tree.json")
+
+			o.printer.Errorf("Failed to generate tree.json for %s: %v", repoPath, err)
+			o.recordRepositoryFailure(repoPath, platform, fmt.Errorf("%s: %w", repoPath, err))
+			return
+		}
+		outputFiles = append(outputFiles, OutputFile{Path: filepath.Join(repoOutputDir, "tree.json"), Content: string(treeJSON)})
+	}
+	if o.config.Output.EditorRules {
+		// Rules point editor assistants to the context files written so far
+		contextFiles := make([]string, 0, len(outputFiles))
+		for _, file := range outputFiles {
+			contextFiles = append(contextFiles, filepath.Base(file.Path))
+		}
+		rules := llmsGenerator.GenerateEditorRules(llmsOutput, contextFiles)
+		outputFiles = append(outputFiles,
+			OutputFile{Path: filepath.Join(repoOutputDir, CursorRulesFileName), Content: rules},
+			OutputFile{Path: filepath.Join(repoOutputDir, ContinueRulesFileName), Content: rules})
+	}
+	if result.Comparison != nil {
+		outputFiles = append(outputFiles, OutputFile{Path: filepath.Join(repoOutputDir, DiffFileName), Content: llmsGenerator.GenerateComparison(repoPath, result.Comparison)})
+	}
+	o.reportProgress(Progress{Repository: repoPath, Stage: ProgressWriting})
+	if err := o.writer.WriteFiles(outputFiles); err != nil {
+		logger.Logger.WithError(err).WithField("output_dir", repoOutputDir).Error("Failed to write output")
 
-		platformMu.Lock()
-		fmt.Fprintf(os.Stderr, "Failed to write llms-full.txt for %s: %v\n", repoPath, err)
-		platformMu.Unlock()
+		o.printer.Errorf("Failed to write outputs for %s: %v", repoPath, err)
+		o.recordRepositoryFailure(repoPath, platform, fmt.Errorf("%s: %w", repoPath, err))
 		return
 	}
-	logger.Logger.WithField("file", llmsFullPath).Debug("Successfully wrote llms-full.txt")
+	logger.Logger.WithField("output_dir", repoOutputDir).WithField("files", len(outputFiles)).Debug("Successfully wrote output")
+
+	if commit != "" && !o.cliOptions.Locked {
+		o.lock.Record(LockEntry{
+			Platform:   platform,
+			Repository: repoPath,
+			Ref:        repoInfo.Branch,
+			Commit:     commit,
+			Tags:       repoInfo.Tags,
+		})
+	}
+
+	// Record the files fetched at this commit for the next incremental run, or as a
+	// checkpoint to resume from once the rate limit resets
+	if state != nil {
+		if err := state.Save(statePath); err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Failed to save state, the next run will fetch all files")
+		}
+	} else if checkpoint {
+		if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Failed to remove checkpoint")
+		}
+	}
 
 	// Success message
 	logger.Logger.WithFields(map[string]interface{}{
-		"repository":      repoPath,
-		"platform":        platform,
-		"files_processed": result.TotalFiles,
-		"total_size":      utils.FormatBytes(result.TotalSize),
-		"duration":        result.Duration.Round(time.Millisecond),
-		"output_dir":      repoOutputDir,
+		"repository":     repoPath,
+		"platform":       platform,
+		"files_included": result.Counts.Included,
+		"files_skipped":  result.Counts.SkippedBinary + result.Counts.SkippedLarge + result.Counts.SkippedIgnored,
+		"files_failed":   result.Counts.Failed,
+		"files_denied":   result.Counts.Denied,
+		"total_size":     utils.FormatBytes(result.TotalSize),
+		"duration":       result.Duration.Round(time.Millisecond),
+		"output_dir":     repoOutputDir,
 	}).Info("Successfully processed repository")
 
+	repoSummary := NewRepositorySummary(repoPath, platform, result)
+	repoSummary.Output = repoOutputDir
+	repoSummary.Commit = commit
+	repoSummary.Tags = repoInfo.Tags
+	for _, file := range outputFiles {
+		name, err := filepath.Rel(repoOutputDir, file.Path)
+		if err != nil {
+			name = filepath.Base(file.Path)
+		}
+		repoSummary.OutputFiles = append(repoSummary.OutputFiles, OutputSize{Name: filepath.ToSlash(name), Size: int64(len(file.Content))})
+	}
+	o.recordSuccess(repoSummary)
+	o.reportProgress(Progress{
+		Repository: repoPath,
+		Stage:      ProgressDone,
+		Output:     repoOutputDir,
+		Files:      repoSummary.Files,
+		Tokens:     repoSummary.Tokens,
+	})
+	if result.Incomplete != nil && !result.Incomplete.RateLimitedAt.IsZero() {
+		// The partial output was written, but the exit status still reports the rate limit
+		o.recordFailure(sherpaerrors.New(sherpaerrors.KindRateLimited, fmt.Sprintf("%s: rate limited, %d/%d files", repoPath, result.Incomplete.Fetched, result.Incomplete.Total)))
+	}
+
 	if !o.cliOptions.Quiet {
-		platformMu.Lock()
-		fmt.Printf("✓ Successfully processed %s (%s)\n", repoPath, platform)
-		fmt.Printf("  Files processed: %d\n", result.TotalFiles)
-		fmt.Printf("  Total size: %s\n", utils.FormatBytes(result.TotalSize))
-		fmt.Printf("  Duration: %s\n", result.Duration.Round(time.Millisecond))
-		fmt.Printf("  Output: %s\n", repoOutputDir)
-		fmt.Println()
-		platformMu.Unlock()
+		block := o.printer.Block()
+		if result.Incomplete != nil && !result.Incomplete.RateLimitedAt.IsZero() {
+			block.Warning("INCOMPLETE: %s rate limited at %s, %d/%d files", repoPath, result.Incomplete.RateLimitedAt.Format(time.RFC3339), result.Incomplete.Fetched, result.Incomplete.Total)
+			block.Line(1, "Run sherpa again once the rate limit resets to fetch the remaining files")
+		}
+		if result.Incomplete != nil && !result.Incomplete.TimedOutAt.IsZero() {
+			block.Warning("INCOMPLETE: %s ran out of time budget, %d/%d files", repoPath, result.Incomplete.Fetched, result.Incomplete.Total)
+			block.Line(1, "Files not fetched in time are listed as stubs; raise --budget-time to include them")
+		}
+		block.Success("Successfully processed %s (%s)", repoPath, platform)
+		if repoInfo.Subdirectory != "" {
+			block.Field("Path", "%s", repoInfo.Subdirectory)
+		}
+		if result.Comparison != nil {
+			block.Field("Diff", "%s..%s, %d commits, %d files changed", result.Comparison.Base, result.Comparison.Head, result.Comparison.Commits, len(result.Comparison.Files))
+		}
+		block.Field("Files included", "%d", result.Counts.Included)
+		if result.Counts.Reused > 0 {
+			block.Field("Files reused from the previous run", "%d", result.Counts.Reused)
+		}
+		block.Field("Files skipped", "%d binary, %d too large, %d ignored", result.Counts.SkippedBinary, result.Counts.SkippedLarge, result.Counts.SkippedIgnored)
+		if result.Counts.Failed > 0 {
+			block.Field("Files failed", "%d", result.Counts.Failed)
+		}
+		if result.Counts.Denied > 0 {
+			block.Field("Files denied", "%d not readable, listed with --verbose", result.Counts.Denied)
+			block.Field("Hint", "%s", pipeline.PermissionHint)
+		}
+		if result.Counts.NotFetched > 0 {
+			block.Field("Files not fetched in time", "%d", result.Counts.NotFetched)
+		}
+		block.Field("Total size", "%s", utils.FormatBytes(result.TotalSize))
+		block.Field("Duration", "%s", result.Duration.Round(time.Millisecond))
+		block.Field("Output", "%s", repoOutputDir)
+		if o.cliOptions.Verbose {
+			writeSlowFiles(block, result)
+			writeRepositorySizes(block, repoSummary)
+		}
+		block.Blank().Flush()
+	}
+}
+
+// writeSlowFiles lists the files and directories that took longest to fetch, to help
+// spot paths worth excluding
+func writeSlowFiles(block *ui.Block, result *models.ProcessingResult) {
+	if len(result.SlowFiles) == 0 {
+		return
+	}
+	block.Field("Slowest files", "")
+	for _, timing := range result.SlowFiles {
+		block.Line(2, "%8s  %s (%s)", timing.Duration.Round(time.Millisecond), timing.Path, utils.FormatBytes(timing.Size))
+	}
+	block.Field("Slowest directories", "")
+	for _, timing := range result.SlowDirectories {
+		block.Line(2, "%8s  %s/ (%d files, %s)", timing.Duration.Round(time.Millisecond), timing.Path, timing.Files, utils.FormatBytes(timing.Size))
+	}
+}
+
+// recordFailure remembers a platform or repository failure for the exit status
+func (o *Orchestrator) recordFailure(err error) {
+	o.failuresMu.Lock()
+	o.failures = append(o.failures, err)
+	o.failuresMu.Unlock()
+
+	if o.stop != nil && o.stopped.CompareAndSwap(false, true) {
+		logger.Logger.WithError(err).Warn("Stopping the run at the first failure")
+		o.stop()
+	}
+}
+
+// recordSkipped adds a repository left unprocessed once --fail-fast stopped the run
+func (o *Orchestrator) recordSkipped(repoPath string, platform models.Platform) {
+	o.failuresMu.Lock()
+	defer o.failuresMu.Unlock()
+	o.summary.Skipped++
+	o.outcomes = append(o.outcomes, repositoryOutcome{status: outcomeSkipped, platform: platform, repository: repoPath})
+}
+
+// recordSuccess adds a processed repository to the run summary
+func (o *Orchestrator) recordSuccess(repoSummary RepositorySummary) {
+	o.failuresMu.Lock()
+	defer o.failuresMu.Unlock()
+	o.summary.Succeeded++
+	o.summary.Files += repoSummary.Files
+	o.summary.Size += repoSummary.Size
+	o.summary.Results = append(o.summary.Results, repoSummary)
+	status := outcomeOK
+	if repoSummary.Incomplete {
+		status = outcomeIncomplete
+	}
+	o.outcomes = append(o.outcomes, repositoryOutcome{
+		status:     status,
+		platform:   repoSummary.Platform,
+		repository: repoSummary.Repository,
+		files:      repoSummary.Files,
+		tokens:     repoSummary.Tokens,
+		output:     repoSummary.Output,
+	})
+}
+
+// printFailure prints a failure, followed by the remediation hint of err when it has one
+func (o *Orchestrator) printFailure(err error, format string, args ...interface{}) {
+	block := o.printer.ErrorBlock().Error(format, args...)
+	if hint := sherpaerrors.HintOf(err); hint != "" {
+		block.Field("Hint", "%s", hint)
+	}
+	block.Flush()
+}
+
+// recordRepositoryFailure remembers a repository that produced no output
+func (o *Orchestrator) recordRepositoryFailure(repoPath string, platform models.Platform, err error) {
+	// Repositories interrupted by --fail-fast did not fail on their own
+	if o.stopped.Load() && errors.Is(err, context.Canceled) {
+		o.recordSkipped(repoPath, platform)
+		o.reportProgress(Progress{Repository: repoPath, Stage: ProgressSkipped})
+		return
+	}
+	o.recordFailure(err)
+	o.reportProgress(Progress{Repository: repoPath, Stage: ProgressFailed, Error: err.Error()})
+	o.recordOutcome(repositoryOutcome{status: outcomeFailed, platform: platform, repository: repoPath, err: err})
+}
+
+// recordOutcome adds the result of a repository to the quiet mode summary lines
+func (o *Orchestrator) recordOutcome(outcome repositoryOutcome) {
+	o.failuresMu.Lock()
+	defer o.failuresMu.Unlock()
+	o.outcomes = append(o.outcomes, outcome)
+}
+
+// Summary returns the combined totals of the last run
+func (o *Orchestrator) Summary() RunSummary {
+	o.failuresMu.Lock()
+	defer o.failuresMu.Unlock()
+	return o.summary
+}
+
+// FailedRepositories returns the number of repositories of the last run that failed or
+// are incomplete, which error thresholds are compared to
+func (o *Orchestrator) FailedRepositories() int {
+	o.failuresMu.Lock()
+	defer o.failuresMu.Unlock()
+	failed := 0
+	for _, outcome := range o.outcomes {
+		if outcome.status == outcomeFailed || outcome.status == outcomeIncomplete {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Err returns the failures of the last run, classified by the kind they share.
+// Mixed failures are reported as unknown so the generic exit code is used.
+func (o *Orchestrator) Err() error {
+	o.failuresMu.Lock()
+	defer o.failuresMu.Unlock()
+
+	if len(o.failures) == 0 {
+		return nil
+	}
+
+	kind := sherpaerrors.KindOf(o.failures[0])
+	for _, failure := range o.failures[1:] {
+		if sherpaerrors.KindOf(failure) != kind {
+			kind = sherpaerrors.KindUnknown
+			break
+		}
+	}
+
+	return &sherpaerrors.Error{
+		Kind:    kind,
+		Message: fmt.Sprintf("%d failures", len(o.failures)),
+		Err:     errors.Join(o.failures...),
+	}
+}
+
+// resolveRef returns the ref to fetch and the commit it is pinned to. With --locked
+// the commit comes from the lock file; otherwise it is resolved from the provider,
+// falling back to the requested branch when the commit cannot be determined.
+func (o *Orchestrator) resolveRef(
+	ctx context.Context,
+	repoInfo *models.RepositoryInfo,
+	platform models.Platform,
+	repoProcessor *pipeline.RepoProcessor,
+) (string, string, error) {
+	// Gists, snippets and downloads have no commits to pin
+	if o.cliOptions.Locked && repoInfo.Kind == "" {
+		entry, exists := o.lock.Lookup(platform, repoInfo.FullName, repoInfo.Branch)
+		if !exists {
+			return "", "", fmt.Errorf("repository not found in lock file %s", o.config.Output.LockFile)
+		}
+		return entry.Commit, entry.Commit, nil
+	}
+
+	commit, err := repoProcessor.ResolveCommit(ctx, repoInfo.FullName, repoInfo.Branch)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("repository", repoInfo.FullName).Warn("Could not resolve commit, output will not be pinned")
+		return repoInfo.Branch, "", nil
+	}
+	if commit == "" {
+		return repoInfo.Branch, "", nil
 	}
+	return commit, commit, nil
 }
 
 // processDryRun handles dry run mode for a repository
@@ -306,7 +1078,6 @@ func (o *Orchestrator) processDryRun(
 	repoInfo *models.RepositoryInfo,
 	platform models.Platform,
 	repoProcessor *pipeline.RepoProcessor,
-	platformMu *sync.Mutex,
 ) {
 	_ = ctx           // unused in dry run mode
 	_ = repoProcessor // unused in dry run mode
@@ -321,24 +1092,36 @@ func (o *Orchestrator) processDryRun(
 	mockResult := o.simulateRepositoryProcessing(repoInfo, platform)
 
 	// Calculate output directory
-	repoOutputDir := filepath.Join(o.config.Output.Directory, utils.SanitizeRepoName(repoPath))
-	if o.config.Output.OrganizeByDate {
-		dateDir := time.Now().Format("2006-01-02")
-		repoOutputDir = filepath.Join(o.config.Output.Directory, dateDir, utils.SanitizeRepoName(repoPath))
-	}
+	repoOutputDir := o.repositoryOutputDir(repoInfo, "")
 
 	// Display dry run results
 	if !o.cliOptions.Quiet {
-		platformMu.Lock()
-		fmt.Printf("[DRY RUN] Would process %s (%s)\n", repoPath, platform)
-		fmt.Printf("  Branch: %s\n", repoInfo.Branch)
-		fmt.Printf("  Estimated files: %d\n", mockResult.EstimatedFiles)
-		fmt.Printf("  Estimated size: %s\n", mockResult.EstimatedSize)
-		fmt.Printf("  Would create output: %s\n", repoOutputDir)
-		fmt.Printf("  File that would be created:\n")
-		fmt.Printf("    - %s/llms-full.txt\n", repoOutputDir)
-		fmt.Println()
-		platformMu.Unlock()
+		block := o.printer.Block().Info("[DRY RUN] Would process %s (%s)", repoPath, platform)
+		block.Field("Branch", "%s", repoInfo.Branch)
+		if repoInfo.Subdirectory != "" {
+			block.Field("Path", "%s", repoInfo.Subdirectory)
+		}
+		block.Field("Estimated files", "%d", mockResult.EstimatedFiles)
+		block.Field("Estimated size", "%s", mockResult.EstimatedSize)
+		block.Field("Would create output", "%s", repoOutputDir)
+		block.Field("File that would be created", "")
+		writeContext, writeIndex := OutputArtifacts(o.config.Output)
+		if writeContext && (o.config.Output.SplitSize != "" || o.config.Output.SplitTokens != "") {
+			block.Line(2, "- %s/%s (or %s, ... when split)", repoOutputDir, OutputFileName(o.config.Output.Format), PartFileName(o.config.Output.Format, 1))
+		} else if writeContext {
+			block.Line(2, "- %s/%s", repoOutputDir, OutputFileName(o.config.Output.Format))
+		}
+		if o.config.Output.TreeJSON {
+			block.Line(2, "- %s/tree.json", repoOutputDir)
+		}
+		if writeIndex {
+			block.Line(2, "- %s/%s", repoOutputDir, LLMsTxtFileName)
+		}
+		if o.config.Output.EditorRules {
+			block.Line(2, "- %s/%s", repoOutputDir, CursorRulesFileName)
+			block.Line(2, "- %s/%s", repoOutputDir, ContinueRulesFileName)
+		}
+		block.Blank().Flush()
 	}
 
 	logger.Logger.WithFields(map[string]interface{}{
@@ -350,6 +1133,117 @@ func (o *Orchestrator) processDryRun(
 	}).Info("[DRY RUN] Repository processing simulation completed")
 }
 
+// repositoryOutputDir returns the directory the outputs of a repository generated at
+// commit are written to
+func (o *Orchestrator) repositoryOutputDir(repoInfo *models.RepositoryInfo, commit string) string {
+	name := utils.SanitizeRepoName(o.outputDirName(repoInfo, commit))
+	if o.config.Output.OrganizeByDate {
+		dateDir := o.clock.Now().Format("2006-01-02")
+		return filepath.Join(o.config.Output.Directory, dateDir, name)
+	}
+	return filepath.Join(o.config.Output.Directory, name)
+}
+
+// writeRepositoryLog writes the entries logged about a repository to its output directory,
+// when repository logs are collected. Failing to write it only logs a warning, since the
+// outcome of the repository is already recorded.
+func (o *Orchestrator) writeRepositoryLog(repoInfo *models.RepositoryInfo, commit string) {
+	if o.repoLogs == nil {
+		return
+	}
+	entries := o.repoLogs.Take(repoInfo.FullName)
+	if len(entries) == 0 {
+		return
+	}
+
+	outputDir := o.repositoryOutputDir(repoInfo, commit)
+	path := filepath.Join(outputDir, RepositoryLogFileName)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		logger.Logger.WithError(err).WithField("file", path).Warn("Failed to write repository log")
+		return
+	}
+	if err := o.writer.WriteFiles([]OutputFile{{Path: path, Content: string(entries)}}); err != nil {
+		logger.Logger.WithError(err).WithField("file", path).Warn("Failed to write repository log")
+	}
+}
+
+// outputDirName names the output directory of a repository after the configured name
+// template, or after outputName without one
+func (o *Orchestrator) outputDirName(repoInfo *models.RepositoryInfo, commit string) string {
+	template := o.config.Output.NameTemplate
+	if template == "" {
+		return outputName(repoInfo)
+	}
+
+	// Repositories given at several refs stay apart even when the template leaves the ref out
+	if repoInfo.RefScoped && repoInfo.Branch != "" && !strings.Contains(template, "{ref}") {
+		template += "@{ref}"
+	}
+	repo := repoInfo.FullName
+	if repoInfo.Subdirectory != "" {
+		repo += "/" + repoInfo.Subdirectory
+	}
+	ref := repoInfo.Branch
+	if ref == "" {
+		ref = "default"
+	}
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+
+	name, err := utils.ExpandNameTemplate(template, map[string]string{
+		"owner":    repoInfo.Owner,
+		"name":     repoInfo.Name,
+		"repo":     repo,
+		"ref":      ref,
+		"sha":      commit,
+		"platform": string(repoInfo.Platform),
+	})
+	if err != nil {
+		logger.Logger.WithError(err).Warn("Invalid name template, using the repository name")
+		return outputName(repoInfo)
+	}
+	// An unknown commit leaves the separator before {sha} dangling
+	return strings.TrimRight(name, "@_-.")
+}
+
+// outputName names the outputs and state of a repository, keeping repositories scoped to
+// different subdirectories or processed at several refs apart
+func outputName(repoInfo *models.RepositoryInfo) string {
+	name := repoInfo.FullName
+	if repoInfo.Subdirectory != "" {
+		name += "/" + repoInfo.Subdirectory
+	}
+	if repoInfo.RefScoped && repoInfo.Branch != "" {
+		name += "@" + repoInfo.Branch
+	}
+	return name
+}
+
+// markRefScoped marks the repositories given at more than one ref, so the outputs of
+// each ref get a directory of their own instead of overwriting each other
+func markRefScoped(reposByPlatform map[models.Platform][]*models.RepositoryInfo) {
+	for _, repos := range reposByPlatform {
+		refs := make(map[string]map[string]bool)
+		for _, repoInfo := range repos {
+			if repoInfo.Kind != "" && repoInfo.Kind != models.KindGitClone {
+				continue
+			}
+			name := outputName(repoInfo)
+			if refs[name] == nil {
+				refs[name] = make(map[string]bool)
+			}
+			refs[name][repoInfo.Branch] = true
+		}
+		for _, repoInfo := range repos {
+			if repoInfo.Kind != "" && repoInfo.Kind != models.KindGitClone {
+				continue
+			}
+			repoInfo.RefScoped = len(refs[outputName(repoInfo)]) > 1
+		}
+	}
+}
+
 // DryRunResult contains simulated processing results
 type DryRunResult struct {
 	EstimatedFiles int
@@ -387,17 +1281,71 @@ func GetTokenForPlatform(platform models.Platform, config *models.Config, cliTok
 		if envToken := os.Getenv(config.GitLab.TokenEnv); envToken != "" {
 			return envToken, nil
 		}
-		return "", fmt.Errorf("GitLab token not found. Set %s environment variable or use --token flag", config.GitLab.TokenEnv)
+		return "", sherpaerrors.New(sherpaerrors.KindAuth, fmt.Sprintf("GitLab token not found. Set %s environment variable or use --token flag", config.GitLab.TokenEnv))
 	case models.PlatformGitHub:
 		if envToken := os.Getenv(config.GitHub.TokenEnv); envToken != "" {
 			return envToken, nil
 		}
-		return "", fmt.Errorf("GitHub token not found. Set %s environment variable or use --token flag", config.GitHub.TokenEnv)
+		return "", sherpaerrors.New(sherpaerrors.KindAuth, fmt.Sprintf("GitHub token not found. Set %s environment variable or use --token flag", config.GitHub.TokenEnv))
+	case models.PlatformGitea:
+		if envToken := os.Getenv(config.Gitea.TokenEnv); envToken != "" {
+			return envToken, nil
+		}
+		return "", sherpaerrors.New(sherpaerrors.KindAuth, fmt.Sprintf("Gitea token not found. Set %s environment variable or use --token flag", config.Gitea.TokenEnv))
 	default:
 		return "", fmt.Errorf("unsupported platform: %s", platform)
 	}
 }
 
+// RepositoryLogFileName is the name of the log file written to each output directory with
+// --repo-logs
+const RepositoryLogFileName = "sherpa.log"
+
+// DiffFileName is the name of the output holding the diffs of --diff
+const DiffFileName = "llms-diff.txt"
+
+// LLMsTxtFileName is the name of the llms.txt index written with --llms-txt
+const LLMsTxtFileName = "llms.txt"
+
+// Editor rules files written with --editor-rules, read by Cursor and Continue
+const (
+	CursorRulesFileName   = ".cursorrules"
+	ContinueRulesFileName = ".continuerules"
+)
+
+// OutputArtifacts reports whether the context file and the llms.txt index are written.
+// llms_txt and llms_txt_legacy add the index to the context file.
+func OutputArtifacts(output models.OutputConfig) (context, index bool) {
+	switch output.Artifacts {
+	case models.ArtifactsIndex:
+		return false, true
+	case models.ArtifactsBoth:
+		return true, true
+	default:
+		return true, output.LLMsTxt || output.LLMsTxtLegacy
+	}
+}
+
+// OutputFileName returns the name of the context file written in an output format
+func OutputFileName(format string) string {
+	switch format {
+	case models.FormatMarkdown:
+		return "llms-full.md"
+	case models.FormatRepomix:
+		return "llms-full.xml"
+	default:
+		return "llms-full.txt"
+	}
+}
+
+// PartFileName returns the name of a numbered part of a split context file, like
+// llms-full.part1.txt
+func PartFileName(format string, part int) string {
+	name := OutputFileName(format)
+	ext := filepath.Ext(name)
+	return fmt.Sprintf("%s.part%d%s", strings.TrimSuffix(name, ext), part, ext)
+}
+
 // WriteFile writes content to a file
 func WriteFile(path, content string) error {
 	file, err := os.Create(path)
@@ -1,12 +1,34 @@
 package orchestration
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"sherpa/internal/adapters"
+	"sherpa/internal/config"
+	"sherpa/internal/fakevcs"
+	"sherpa/internal/httpdebug"
+	"sherpa/internal/session"
+	sherpaerrors "sherpa/pkg/errors"
 	"sherpa/pkg/models"
+	"sherpa/pkg/ui"
+	"sherpa/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewOrchestrator(t *testing.T) {
@@ -36,6 +58,25 @@ func TestOrchestrator_ProcessRepositories(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("should error when lock file is missing in locked mode", func(t *testing.T) {
+		config := &models.Config{
+			Output: models.OutputConfig{
+				LockFile: filepath.Join(t.TempDir(), "sherpa.lock"),
+			},
+		}
+		cliOptions := &models.CLIOptions{
+			MaxReposConcurrency: 1,
+			Locked:              true,
+		}
+
+		orchestrator := NewOrchestrator(config, cliOptions)
+		reposByPlatform := make(map[models.Platform][]*models.RepositoryInfo)
+
+		err := orchestrator.ProcessRepositories(context.Background(), reposByPlatform)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read lock file")
+	})
+
 	t.Run("should handle invalid token", func(t *testing.T) {
 		config := &models.Config{
 			Processing: models.ProcessingConfig{},
@@ -62,6 +103,692 @@ func TestOrchestrator_ProcessRepositories(t *testing.T) {
 		err := orchestrator.ProcessRepositories(context.Background(), reposByPlatform)
 		// Should not return error as goroutines handle errors internally
 		assert.NoError(t, err)
+
+		// The missing token is reported as an authentication failure
+		runErr := orchestrator.Err()
+		require.Error(t, runErr)
+		assert.Equal(t, sherpaerrors.KindAuth, sherpaerrors.KindOf(runErr))
+	})
+
+	t.Run("should abort GitLab repositories above the maximum size before fetching files", func(t *testing.T) {
+		fixtures := &fakevcs.Fixtures{Repositories: []fakevcs.Repository{{
+			Path:  "group/data",
+			Files: map[string]string{"README.md": "# data\n", "data.csv": strings.Repeat("a,b\n", 1024)},
+		}}}
+		server := fakevcs.NewServer(fixtures)
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitLab.BaseURL = server.GitLabURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Processing.MaxRepoSize = "1KB"
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitLab: {{Platform: models.PlatformGitLab, Owner: "group", Name: "data", FullName: "group/data"}},
+		})
+		require.NoError(t, err)
+
+		runErr := orchestrator.Err()
+		require.Error(t, runErr)
+		assert.Contains(t, runErr.Error(), "exceeds maximum of 1.0 KB")
+		assert.Equal(t, sherpaerrors.KindTooLarge, sherpaerrors.KindOf(runErr))
+	})
+}
+
+func TestOrchestrator_ProcessLocalFolders(t *testing.T) {
+	t.Run("should read each local folder through its own provider", func(t *testing.T) {
+		root := t.TempDir()
+		folders := map[string]string{"auth": "package auth", "payment": "package payment"}
+		var repoInfos []*models.RepositoryInfo
+		for name, content := range folders {
+			dir := filepath.Join(root, name)
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name+".go"), []byte(content), 0644))
+			repoInfos = append(repoInfos, &models.RepositoryInfo{
+				Platform: models.PlatformLocal,
+				Owner:    "local",
+				Name:     name,
+				FullName: dir,
+			})
+		}
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(root, "output")
+		cfg.Output.OrganizeByDate = false
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{MaxReposConcurrency: 2, Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: repoInfos,
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+		assert.Equal(t, 2, orchestrator.Summary().Succeeded)
+
+		for name, content := range folders {
+			output, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(filepath.Join(root, name)), "llms-full.txt"))
+			require.NoError(t, err)
+			assert.Contains(t, string(output), content)
+			for other, otherContent := range folders {
+				if other != name {
+					assert.NotContains(t, string(output), otherContent)
+				}
+			}
+		}
+	})
+
+	t.Run("should report a missing folder without stopping the others", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0644))
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{MaxReposConcurrency: 2, Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {
+				{Platform: models.PlatformLocal, Owner: "local", Name: "present", FullName: root},
+				{Platform: models.PlatformLocal, Owner: "local", Name: "missing", FullName: filepath.Join(root, "missing")},
+			},
+		})
+		require.NoError(t, err)
+		assert.Error(t, orchestrator.Err())
+		assert.Equal(t, 1, orchestrator.Summary().Succeeded)
+		assert.Equal(t, 1, orchestrator.Summary().Failed())
+	})
+
+	t.Run("should write tree.json alongside the text output when enabled", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0644))
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.OrganizeByDate = false
+		cfg.Output.LockFile = ""
+		cfg.Output.TreeJSON = true
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {{Platform: models.PlatformLocal, Owner: "local", Name: "app", FullName: root}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		data, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(root), "tree.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"path": "main.go"`)
+	})
+
+	t.Run("should write llms-full.md in the markdown format", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0644))
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.OrganizeByDate = false
+		cfg.Output.LockFile = ""
+		cfg.Output.Format = models.FormatMarkdown
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {{Platform: models.PlatformLocal, Owner: "local", Name: "app", FullName: root}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		repoDir := filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(root))
+		data, err := os.ReadFile(filepath.Join(repoDir, "llms-full.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "(#file-main-go)")
+		assert.NoFileExists(t, filepath.Join(repoDir, "llms-full.txt"))
+	})
+
+	t.Run("should write llms.txt in the llmstxt.org format or the legacy one", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("# App\n\nAn app.\n"), 0644))
+
+		for _, legacy := range []bool{false, true} {
+			cfg, err := config.NewLoader().LoadConfig("")
+			require.NoError(t, err)
+			cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+			cfg.Output.OrganizeByDate = false
+			cfg.Output.LockFile = ""
+			cfg.Output.LLMsTxt = !legacy
+			cfg.Output.LLMsTxtLegacy = legacy
+			cfg.Cache.Enabled = false
+
+			orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+			err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+				models.PlatformLocal: {{Platform: models.PlatformLocal, Owner: "local", Name: "app", FullName: root}},
+			})
+			require.NoError(t, err)
+			require.NoError(t, orchestrator.Err())
+
+			data, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(root), "llms.txt"))
+			require.NoError(t, err)
+			if legacy {
+				assert.Contains(t, string(data), "## Project Structure\n")
+			} else {
+				assert.Contains(t, string(data), "## Docs\n")
+				assert.Contains(t, string(data), "- [README.md](README.md)\n")
+				assert.Contains(t, string(data), "- [llms-full.txt](llms-full.txt)")
+			}
+		}
+	})
+
+	t.Run("should write only llms.txt with the index artifacts", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("# App\n"), 0644))
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.OrganizeByDate = false
+		cfg.Output.LockFile = ""
+		cfg.Output.Artifacts = models.ArtifactsIndex
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {{Platform: models.PlatformLocal, Owner: "local", Name: "app", FullName: root}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		repoDir := filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(root))
+		data, err := os.ReadFile(filepath.Join(repoDir, "llms.txt"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "## Optional")
+		assert.NoFileExists(t, filepath.Join(repoDir, "llms-full.txt"))
+	})
+
+	t.Run("should write numbered parts when the output is split", func(t *testing.T) {
+		root := t.TempDir()
+		for _, name := range []string{"a.go", "b.go", "c.go"} {
+			content := "package main\n\n// " + strings.Repeat(name+" ", 300) + "\n"
+			require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0644))
+		}
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.OrganizeByDate = false
+		cfg.Output.LockFile = ""
+		cfg.Output.SplitSize = "2KB"
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {{Platform: models.PlatformLocal, Owner: "local", Name: "app", FullName: root}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		repoDir := filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(root))
+		for part := 1; part <= 3; part++ {
+			data, err := os.ReadFile(filepath.Join(repoDir, fmt.Sprintf("llms-full.part%d.txt", part)))
+			require.NoError(t, err)
+			assert.Contains(t, string(data), fmt.Sprintf("# Part %d of 3\n", part))
+		}
+		assert.NoFileExists(t, filepath.Join(repoDir, "llms-full.txt"))
+	})
+
+	t.Run("should fail repositories split into more parts than allowed", func(t *testing.T) {
+		root := t.TempDir()
+		for _, name := range []string{"a.go", "b.go", "c.go"} {
+			content := "package main\n\n// " + strings.Repeat(name+" ", 300) + "\n"
+			require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0644))
+		}
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.OrganizeByDate = false
+		cfg.Output.LockFile = ""
+		cfg.Output.SplitSize = "2KB"
+		cfg.Output.MaxParts = 2
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {{Platform: models.PlatformLocal, Owner: "local", Name: "app", FullName: root}},
+		})
+		require.NoError(t, err)
+		assert.ErrorContains(t, orchestrator.Err(), "llms-full.txt splits into 3 parts, more than max_parts 2")
+
+		repoDir := filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(root))
+		assert.NoFileExists(t, filepath.Join(repoDir, "llms-full.part1.txt"))
+	})
+}
+
+func TestOrchestrator_ProcessSnippets(t *testing.T) {
+	t.Run("should process gists next to repositories of the same platform", func(t *testing.T) {
+		fixtures := fakevcs.DefaultFixtures()
+		fixtures.Snippets = []fakevcs.Repository{{
+			Path:        "abc123",
+			Description: "Deploy helpers",
+			Files:       map[string]string{"deploy.sh": "#!/bin/sh\nmake release\n"},
+		}}
+		server := fakevcs.NewServer(fixtures)
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitHub: {
+				{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"},
+				{Platform: models.PlatformGitHub, Owner: "octocat", Name: "abc123", FullName: "octocat/abc123", Kind: models.KindSnippet},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+		assert.Equal(t, 2, orchestrator.Summary().Succeeded)
+
+		output, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("octocat/abc123"), "llms-full.txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "make release")
+	})
+}
+
+func TestOrchestrator_Incremental(t *testing.T) {
+	t.Run("should reuse files recorded by the previous run at the same commit", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Output.Incremental = true
+		cfg.Cache.Enabled = false
+
+		run := func() string {
+			orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+			err := orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+				models.PlatformGitHub: {
+					{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"},
+				},
+			})
+			require.NoError(t, err)
+			require.NoError(t, orchestrator.Err())
+
+			output, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("sherpa-fixtures/hello"), "llms-full.txt"))
+			require.NoError(t, err)
+			return string(output)
+		}
+
+		first := run()
+		firstRequests := server.Requests()
+		assert.FileExists(t, filepath.Join(cfg.Output.Directory, ".sherpa-state", utils.SanitizeRepoName("sherpa-fixtures/hello")+".json"))
+
+		second := run()
+		assert.Less(t, server.Requests()-firstRequests, firstRequests, "the second run should not fetch file contents again")
+		assert.Equal(t, strings.Count(first, "\n"), strings.Count(second, "\n"))
+	})
+}
+
+func TestOrchestrator_Offline(t *testing.T) {
+	hello := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"}
+
+	t.Run("should process repositories from the state of a previous incremental run", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Output.Incremental = true
+		cfg.Cache.Enabled = false
+		outputPath := filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("sherpa-fixtures/hello"), "llms-full.txt")
+
+		online := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		require.NoError(t, online.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: {hello}}))
+		require.NoError(t, online.Err())
+		first, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		requests := server.Requests()
+
+		offline := NewOrchestrator(cfg, &models.CLIOptions{Offline: true, Quiet: true})
+		require.NoError(t, offline.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: {hello}}))
+		require.NoError(t, offline.Err())
+		second, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, requests, server.Requests(), "the offline run should not send requests")
+		assert.Equal(t, strings.Count(string(first), "\n"), strings.Count(string(second), "\n"))
+	})
+
+	t.Run("should fail when no state was recorded", func(t *testing.T) {
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Offline: true, Quiet: true})
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: {hello}}))
+
+		err = orchestrator.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "run once online with --incremental")
+		assert.Equal(t, 1, orchestrator.Summary().Failed())
+	})
+
+	t.Run("should refuse downloads", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		rawURL := server.URL + "/raw/main/deploy.sh"
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Offline: true, Quiet: true})
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {{Platform: models.PlatformLocal, Name: "deploy.sh", FullName: rawURL, URL: rawURL, Kind: models.KindDownload}},
+		}))
+
+		assert.ErrorIs(t, orchestrator.Err(), adapters.ErrOffline)
+		assert.False(t, called)
+	})
+}
+
+func TestOrchestrator_Session(t *testing.T) {
+	t.Run("should reproduce a recorded run without the platform", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+		repos := map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitHub: {{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"}},
+		}
+		outputPath := filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("sherpa-fixtures/hello"), "llms-full.txt")
+
+		sessionPath := filepath.Join(t.TempDir(), "session.tar")
+		recorder, err := session.Create(sessionPath)
+		require.NoError(t, err)
+		recording := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Record: sessionPath, Quiet: true})
+		recording.SetBaseTransport(recorder.Transport(nil))
+		require.NoError(t, recording.ProcessRepositories(context.Background(), repos))
+		require.NoError(t, recording.Err())
+		require.NoError(t, recorder.Close())
+		recorded, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		server.Close()
+		require.NoError(t, os.RemoveAll(cfg.Output.Directory))
+
+		replayer, err := session.Open(sessionPath)
+		require.NoError(t, err)
+		replaying := NewOrchestrator(cfg, &models.CLIOptions{Replay: sessionPath, Quiet: true})
+		replaying.SetBaseTransport(replayer.Transport())
+		require.NoError(t, replaying.ProcessRepositories(context.Background(), repos))
+		require.NoError(t, replaying.Err())
+		replayed, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, strings.Count(string(recorded), "\n"), strings.Count(string(replayed), "\n"))
+		assert.Contains(t, string(replayed), "sherpa-fixtures/hello")
+	})
+}
+
+func TestOrchestrator_RateLimited(t *testing.T) {
+	t.Run("should write a partial output and resume from its checkpoint", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+
+		// Refuse two files while limited, like a quota exhausted in the middle of a repository.
+		// Repository settings are read on every run, so only file contents are counted.
+		target, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		upstream := httputil.NewSingleHostReverseProxy(target)
+		var limited atomic.Bool
+		var contents atomic.Int32
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.URL.Path, "/contents/") && !strings.HasSuffix(r.URL.Path, "/.sherpa.yml") && !strings.HasSuffix(r.URL.Path, "/llms-annotations.yml") {
+				contents.Add(1)
+				if limited.Load() && (strings.HasSuffix(r.URL.Path, "/README.md") || strings.HasSuffix(r.URL.Path, "/main.go")) {
+					http.Error(w, `{"message":"rate limit exceeded"}`, http.StatusTooManyRequests)
+					return
+				}
+			}
+			upstream.ServeHTTP(w, r)
+		}))
+		defer proxy.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Gitea.BaseURL = proxy.URL
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		repoInfos := map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitea: {
+				{Platform: models.PlatformGitea, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"},
+			},
+		}
+		outputPath := filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("sherpa-fixtures/hello"), "llms-full.txt")
+		statePath := filepath.Join(cfg.Output.Directory, ".sherpa-state", utils.SanitizeRepoName("sherpa-fixtures/hello")+".json")
+
+		limited.Store(true)
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), repoInfos))
+		assert.Equal(t, sherpaerrors.KindRateLimited, sherpaerrors.KindOf(orchestrator.Err()))
+
+		output, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "INCOMPLETE: rate limited at")
+		assert.Contains(t, string(output), "5/7 files")
+		assert.FileExists(t, statePath)
+
+		limited.Store(false)
+		before := contents.Load()
+		orchestrator = NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), repoInfos))
+		require.NoError(t, orchestrator.Err())
+		assert.Equal(t, int32(2), contents.Load()-before, "only the refused files should be fetched again")
+
+		output, err = os.ReadFile(outputPath)
+		require.NoError(t, err)
+		assert.NotContains(t, string(output), "INCOMPLETE")
+		assert.Contains(t, string(output), "Greets people over HTTP.")
+		assert.NoFileExists(t, statePath, "the checkpoint should be removed once complete")
+	})
+}
+
+func TestOrchestrator_ProcessDownloads(t *testing.T) {
+	t.Run("should process raw files as local folders", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("#!/bin/sh\nmake release\n"))
+		}))
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		rawURL := server.URL + "/raw/main/deploy.sh"
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {
+				{Platform: models.PlatformLocal, Name: "deploy.sh", FullName: rawURL, URL: rawURL, Kind: models.KindDownload},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		output, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(rawURL), "llms-full.txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "make release")
+		assert.Contains(t, string(output), rawURL)
+	})
+
+	t.Run("should send the configured headers", func(t *testing.T) {
+		var userAgent, route string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userAgent, route = r.UserAgent(), r.Header.Get("X-Gateway-Route")
+			w.Write([]byte("echo ok\n"))
+		}))
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+		cfg.HTTP = models.HTTPConfig{UserAgent: "sherpa-ci/1.0", Headers: map[string]string{"X-Gateway-Route": "internal"}}
+
+		rawURL := server.URL + "/raw/main/ok.sh"
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {
+				{Platform: models.PlatformLocal, Name: "ok.sh", FullName: rawURL, URL: rawURL, Kind: models.KindDownload},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+		assert.Equal(t, "sherpa-ci/1.0", userAgent)
+		assert.Equal(t, "internal", route)
+	})
+
+	t.Run("should record requests when HTTP debugging is enabled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("echo ok\n"))
+		}))
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		var dump bytes.Buffer
+		rawURL := server.URL + "/raw/main/ok.sh"
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		orchestrator.SetHTTPRecorder(httpdebug.NewDumpRecorder(&dump))
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {
+				{Platform: models.PlatformLocal, Name: "ok.sh", FullName: rawURL, URL: rawURL, Kind: models.KindDownload},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+		assert.Contains(t, dump.String(), `"url":"`+rawURL+`"`)
+		assert.Contains(t, dump.String(), `"response_body":"echo ok\n"`)
+	})
+
+	t.Run("should process Go modules fetched from the module proxy", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.Create("example.com/lib@v0.3.0/lib.go")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("package lib\n\nfunc Retry() {}\n"))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/example.com/lib/@v/v0.3.0.zip" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(buf.Bytes())
+		}))
+		defer server.Close()
+		t.Setenv("GOPROXY", server.URL+",direct")
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {
+				{Platform: models.PlatformLocal, Name: "lib@v0.3.0", FullName: "example.com/lib@v0.3.0", Kind: models.KindGoModule},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		output, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("example.com/lib@v0.3.0"), "llms-full.txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "func Retry()")
+		assert.Contains(t, string(output), "https://pkg.go.dev/example.com/lib@v0.3.0")
+	})
+}
+
+func TestOrchestrator_Summary(t *testing.T) {
+	t.Run("should combine the results of processed repositories", func(t *testing.T) {
+		orchestrator := NewOrchestrator(&models.Config{}, &models.CLIOptions{})
+		orchestrator.summary.Repositories = 3
+		orchestrator.recordSuccess(NewRepositorySummary("owner/a", models.PlatformGitHub, &models.ProcessingResult{TotalFiles: 5, TotalSize: 100, Counts: models.FileCounts{Included: 4}}))
+		orchestrator.recordSuccess(NewRepositorySummary("owner/b", models.PlatformGitHub, &models.ProcessingResult{TotalFiles: 2, TotalSize: 50, Counts: models.FileCounts{Included: 2, SkippedBinary: 1}}))
+
+		summary := orchestrator.Summary()
+		assert.Equal(t, 2, summary.Succeeded)
+		assert.Equal(t, 1, summary.Failed())
+		assert.Equal(t, 6, summary.Files)
+		assert.Equal(t, int64(150), summary.Size)
+	})
+}
+
+func TestOrchestrator_Err(t *testing.T) {
+	t.Run("should return nil without failures", func(t *testing.T) {
+		orchestrator := NewOrchestrator(&models.Config{}, &models.CLIOptions{})
+		assert.NoError(t, orchestrator.Err())
+	})
+
+	t.Run("should keep the kind shared by all failures", func(t *testing.T) {
+		orchestrator := NewOrchestrator(&models.Config{}, &models.CLIOptions{})
+		orchestrator.recordFailure(sherpaerrors.New(sherpaerrors.KindNotFound, "a"))
+		orchestrator.recordFailure(sherpaerrors.New(sherpaerrors.KindNotFound, "b"))
+
+		assert.Equal(t, sherpaerrors.ExitNotFound, sherpaerrors.ExitCode(orchestrator.Err()))
+	})
+
+	t.Run("should use the generic kind for mixed failures", func(t *testing.T) {
+		orchestrator := NewOrchestrator(&models.Config{}, &models.CLIOptions{})
+		orchestrator.recordFailure(sherpaerrors.New(sherpaerrors.KindNotFound, "a"))
+		orchestrator.recordFailure(sherpaerrors.New(sherpaerrors.KindAuth, "b"))
+
+		err := orchestrator.Err()
+		assert.Equal(t, sherpaerrors.ExitFailure, sherpaerrors.ExitCode(err))
+		assert.Contains(t, err.Error(), "2 failures")
 	})
 }
 
@@ -86,3 +813,446 @@ func TestOrchestrator_processRepository(t *testing.T) {
 		t.Skip("Implement with mocked dependencies")
 	})
 }
+
+func TestOrchestrator_Clock(t *testing.T) {
+	fixed := time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC)
+
+	t.Run("should use the injected run ID generator", func(t *testing.T) {
+		orchestrator := NewOrchestrator(&models.Config{}, &models.CLIOptions{MaxReposConcurrency: 1})
+		orchestrator.SetClock(utils.FixedClock{Time: fixed})
+		orchestrator.SetRunIDGenerator(func(start time.Time) string {
+			return "run-" + start.Format("20060102")
+		})
+
+		err := orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, "run-20240301", orchestrator.RunID())
+	})
+
+	t.Run("should start the time budget from the clock", func(t *testing.T) {
+		config := &models.Config{Processing: models.ProcessingConfig{BudgetTime: 10 * time.Minute}}
+		orchestrator := NewOrchestrator(config, &models.CLIOptions{MaxReposConcurrency: 1})
+		orchestrator.SetClock(utils.FixedClock{Time: fixed})
+
+		err := orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, fixed.Add(9*time.Minute), orchestrator.deadline)
+	})
+
+	t.Run("should name dated output directories from the clock", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n"), 0644))
+		outputDir := t.TempDir()
+
+		config := &models.Config{
+			Output: models.OutputConfig{
+				Directory:      outputDir,
+				OrganizeByDate: true,
+			},
+			Processing: models.ProcessingConfig{
+				MaxConcurrency:   1,
+				MaxFiles:         10,
+				MaxMemoryPerFile: 1024 * 1024,
+				MaxTotalMemory:   10 * 1024 * 1024,
+			},
+		}
+		orchestrator := NewOrchestrator(config, &models.CLIOptions{MaxReposConcurrency: 1, Quiet: true})
+		orchestrator.SetClock(utils.FixedClock{Time: fixed})
+
+		reposByPlatform := map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {{FullName: sourceDir, Platform: models.PlatformLocal}},
+		}
+
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), reposByPlatform))
+		require.NoError(t, orchestrator.Err())
+
+		outputPath := filepath.Join(outputDir, "2024-03-01", utils.SanitizeRepoName(sourceDir), "llms-full.txt")
+		content, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "2024-03-01T23:59:59Z")
+	})
+}
+
+func TestOrchestrator_Deterministic(t *testing.T) {
+	t.Run("should write byte-identical outputs for identical inputs", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+		hello := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"}
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = filepath.Join(t.TempDir(), "sherpa.lock")
+		cfg.Output.TreeJSON = true
+		cfg.Output.Deterministic = true
+		cfg.Cache.Enabled = false
+
+		run := func(at time.Time) map[string]string {
+			orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+			orchestrator.SetClock(utils.FixedClock{Time: at})
+			require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: {hello}}))
+			require.NoError(t, orchestrator.Err())
+
+			files := make(map[string]string)
+			outputDir := filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("sherpa-fixtures/hello"))
+			for _, path := range []string{filepath.Join(outputDir, "llms-full.txt"), filepath.Join(outputDir, "tree.json"), cfg.Output.LockFile} {
+				content, err := os.ReadFile(path)
+				require.NoError(t, err)
+				files[path] = string(content)
+			}
+			return files
+		}
+
+		first := run(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+		second := run(time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC))
+		assert.Equal(t, first, second)
+		assert.NoFileExists(t, filepath.Join(cfg.Output.Directory, RunSummaryFile))
+	})
+}
+
+func TestOrchestrator_RepositoryLogs(t *testing.T) {
+	t.Run("should write the log of each repository to its output directory", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+		hello := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"}
+		missing := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "missing", FullName: "sherpa-fixtures/missing"}
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Output.RepoLogs = true
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: {hello, missing}}))
+
+		helloLog, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("sherpa-fixtures/hello"), RepositoryLogFileName))
+		require.NoError(t, err)
+		assert.Contains(t, string(helloLog), `"msg":"Processing repository"`)
+		assert.NotContains(t, string(helloLog), "sherpa-fixtures/missing")
+
+		missingLog, err := os.ReadFile(filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName("sherpa-fixtures/missing"), RepositoryLogFileName))
+		require.NoError(t, err)
+		assert.Contains(t, string(missingLog), `"level":"error"`)
+	})
+}
+
+func TestOrchestrator_Hints(t *testing.T) {
+	hello := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"}
+	missing := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "missing", FullName: "sherpa-fixtures/missing"}
+
+	run := func(t *testing.T, token string, repoInfo *models.RepositoryInfo) (string, *Orchestrator) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		theme, err := ui.LookupTheme("plain")
+		require.NoError(t, err)
+		var out, errOut bytes.Buffer
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: token})
+		orchestrator.SetPrinter(ui.New(&out, &errOut, theme, false))
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: {repoInfo}}))
+		return errOut.String(), orchestrator
+	}
+
+	t.Run("should print a hint for invalid tokens", func(t *testing.T) {
+		errOut, orchestrator := run(t, "invalid-token", hello)
+		assert.Contains(t, errOut, "Hint: the token is invalid, expired or revoked")
+		assert.Equal(t, sherpaerrors.ExitAuth, sherpaerrors.ExitCode(orchestrator.Err()))
+	})
+
+	t.Run("should print a hint for missing repositories", func(t *testing.T) {
+		errOut, orchestrator := run(t, fakevcs.Token, missing)
+		assert.Contains(t, errOut, "Hint: check the repository name")
+		assert.Equal(t, sherpaerrors.ExitNotFound, sherpaerrors.ExitCode(orchestrator.Err()))
+	})
+}
+
+func TestOrchestrator_FailFast(t *testing.T) {
+	root := t.TempDir()
+	var repoInfos []*models.RepositoryInfo
+	for _, name := range []string{"first", "second", "third"} {
+		repoInfos = append(repoInfos, &models.RepositoryInfo{Platform: models.PlatformLocal, Owner: "local", Name: name, FullName: filepath.Join(root, name)})
+	}
+
+	run := func(t *testing.T, failFast bool) *Orchestrator {
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		theme, err := ui.LookupTheme("plain")
+		require.NoError(t, err)
+		var out, errOut bytes.Buffer
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{MaxReposConcurrency: 1, Quiet: true, FailFast: failFast})
+		orchestrator.SetPrinter(ui.New(&out, &errOut, theme, false))
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{models.PlatformLocal: repoInfos}))
+		return orchestrator
+	}
+
+	t.Run("should skip the remaining repositories after the first failure", func(t *testing.T) {
+		orchestrator := run(t, true)
+
+		require.Error(t, orchestrator.Err())
+		summary := orchestrator.Summary()
+		assert.Equal(t, 1, summary.Failed())
+		assert.Equal(t, 2, summary.Skipped)
+		assert.Equal(t, 1, orchestrator.FailedRepositories())
+	})
+
+	t.Run("should process every repository without it", func(t *testing.T) {
+		orchestrator := run(t, false)
+
+		assert.Equal(t, 3, orchestrator.Summary().Failed())
+		assert.Zero(t, orchestrator.Summary().Skipped)
+		assert.Equal(t, 3, orchestrator.FailedRepositories())
+	})
+}
+
+func TestPartFileName(t *testing.T) {
+	t.Run("should number parts before the extension", func(t *testing.T) {
+		assert.Equal(t, "llms-full.part1.txt", PartFileName(models.FormatText, 1))
+		assert.Equal(t, "llms-full.part12.txt", PartFileName("", 12))
+	})
+}
+
+func TestOutputArtifacts(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  models.OutputConfig
+		context bool
+		index   bool
+	}{
+		{"default", models.OutputConfig{}, true, false},
+		{"full", models.OutputConfig{Artifacts: models.ArtifactsFull}, true, false},
+		{"full with llms_txt", models.OutputConfig{Artifacts: models.ArtifactsFull, LLMsTxt: true}, true, true},
+		{"index", models.OutputConfig{Artifacts: models.ArtifactsIndex}, false, true},
+		{"both", models.OutputConfig{Artifacts: models.ArtifactsBoth}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context, index := OutputArtifacts(tt.output)
+			assert.Equal(t, tt.context, context)
+			assert.Equal(t, tt.index, index)
+		})
+	}
+}
+
+func TestFetchDeadline(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should keep a tenth of the budget to write outputs", func(t *testing.T) {
+		assert.Equal(t, start.Add(4*time.Minute+30*time.Second), fetchDeadline(start, 5*time.Minute))
+	})
+
+	t.Run("should not set a deadline without a budget", func(t *testing.T) {
+		assert.True(t, fetchDeadline(start, 0).IsZero())
+	})
+}
+
+func TestMarkRefScoped(t *testing.T) {
+	t.Run("should name outputs after the ref of repositories given at several refs", func(t *testing.T) {
+		main := &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/repo", Branch: "main"}
+		release := &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/repo", Branch: "release/2.0"}
+		other := &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/other", Branch: "main"}
+		gitlab := &models.RepositoryInfo{Platform: models.PlatformGitLab, FullName: "owner/repo", Branch: "dev"}
+
+		markRefScoped(map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitHub: {main, release, other},
+			models.PlatformGitLab: {gitlab},
+		})
+
+		assert.Equal(t, "owner/repo@main", outputName(main))
+		assert.Equal(t, "owner/repo@release/2.0", outputName(release))
+		assert.Equal(t, "owner/other", outputName(other))
+		assert.Equal(t, "owner/repo", outputName(gitlab))
+	})
+
+	t.Run("should keep the plain name for the default branch", func(t *testing.T) {
+		defaultBranch := &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/repo"}
+		dev := &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/repo", Branch: "dev"}
+
+		markRefScoped(map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: {defaultBranch, dev}})
+
+		assert.Equal(t, "owner/repo", outputName(defaultBranch))
+		assert.Equal(t, "owner/repo@dev", outputName(dev))
+	})
+
+	t.Run("should leave different subdirectories of the same ref alone", func(t *testing.T) {
+		api := &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/repo", Branch: "main", Subdirectory: "api"}
+		web := &models.RepositoryInfo{Platform: models.PlatformGitHub, FullName: "owner/repo", Branch: "main", Subdirectory: "web"}
+
+		markRefScoped(map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: {api, web}})
+
+		assert.Equal(t, "owner/repo/api", outputName(api))
+		assert.Equal(t, "owner/repo/web", outputName(web))
+	})
+}
+
+func TestOrchestrator_outputDirName(t *testing.T) {
+	repoInfo := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "owner", Name: "repo", FullName: "owner/repo", Branch: "main"}
+	newOrchestrator := func(template string) *Orchestrator {
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.NameTemplate = template
+		return NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+	}
+
+	t.Run("should name directories after the repository without a template", func(t *testing.T) {
+		assert.Equal(t, "owner/repo", newOrchestrator("").outputDirName(repoInfo, "ab12cd3ef"))
+	})
+
+	t.Run("should expand the ref and short commit", func(t *testing.T) {
+		assert.Equal(t, "owner/repo@main_ab12cd3", newOrchestrator("{repo}@{ref}_{sha}").outputDirName(repoInfo, "ab12cd3ef456"))
+	})
+
+	t.Run("should name the default branch and trim an unknown commit", func(t *testing.T) {
+		defaultBranch := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "owner", Name: "repo", FullName: "owner/repo"}
+		assert.Equal(t, "github-repo@default", newOrchestrator("{platform}-{name}@{ref}_{sha}").outputDirName(defaultBranch, ""))
+	})
+
+	t.Run("should add the ref of repositories given at several refs", func(t *testing.T) {
+		scoped := *repoInfo
+		scoped.RefScoped = true
+		assert.Equal(t, "owner/repo_ab12cd3@main", newOrchestrator("{repo}_{sha}").outputDirName(&scoped, "ab12cd3ef"))
+	})
+}
+
+func TestOrchestrator_Clone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// A repository served as file://<root>/team/tool.git
+	root := t.TempDir()
+	repoDir := filepath.Join(root, "team", "tool.git")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package tool"), 0644))
+	for _, args := range [][]string{{"init", "--quiet"}, {"add", "-A"}, {"commit", "--quiet", "-m", "init"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Sherpa", "-c", "user.email=sherpa@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	newConfig := func(t *testing.T) *models.Config {
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+		cfg.GitLab.BaseURL = "file://" + root
+		cfg.GitLab.TokenEnv = "NONEXISTENT_TOKEN"
+		return cfg
+	}
+
+	t.Run("should clone repositories when no token is available", func(t *testing.T) {
+		cfg := newConfig(t)
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err := orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitLab: {{Platform: models.PlatformGitLab, Owner: "team", Name: "tool", FullName: "team/tool"}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		output, err := os.ReadFile(filepath.Join(cfg.Output.Directory, "team_tool", "llms-full.txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "package tool")
+	})
+
+	t.Run("should clone git remotes", func(t *testing.T) {
+		cfg := newConfig(t)
+		remote := "file://" + repoDir
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Quiet: true})
+		err := orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {{Platform: models.PlatformLocal, Name: "tool", FullName: remote, URL: remote, Kind: models.KindGitClone}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+		assert.Equal(t, 1, orchestrator.Summary().Succeeded)
+	})
+}
+
+func TestOrchestrator_Printer(t *testing.T) {
+	t.Run("should print each repository as one block with the theme icons", func(t *testing.T) {
+		root := t.TempDir()
+		var repoInfos []*models.RepositoryInfo
+		for _, name := range []string{"auth", "payment", "search"} {
+			dir := filepath.Join(root, name)
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name+".go"), []byte("package "+name), 0644))
+			repoInfos = append(repoInfos, &models.RepositoryInfo{Platform: models.PlatformLocal, Owner: "local", Name: name, FullName: dir})
+		}
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(root, "output")
+		cfg.Output.OrganizeByDate = false
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		theme, err := ui.LookupTheme("plain")
+		require.NoError(t, err)
+		var out, errOut bytes.Buffer
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{MaxReposConcurrency: 3})
+		orchestrator.SetPrinter(ui.New(&out, &errOut, theme, false))
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: repoInfos,
+		}))
+
+		blocks := strings.Split(strings.TrimSpace(out.String()), "\n\n")
+		require.Len(t, blocks, 4)
+		for _, block := range blocks[:3] {
+			lines := strings.Split(block, "\n")
+			assert.True(t, strings.HasPrefix(lines[0], "+ Successfully processed "), lines[0])
+			for _, line := range lines[1:] {
+				assert.True(t, strings.HasPrefix(line, "  "), line)
+			}
+		}
+		assert.True(t, strings.HasPrefix(blocks[3], "+ Processed 3 of 3 repositories (0 failed)"), blocks[3])
+		assert.NotContains(t, out.String(), "\x1b[")
+		assert.Empty(t, errOut.String())
+	})
+
+	t.Run("should print one summary line per repository in quiet mode", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0644))
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.OrganizeByDate = false
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		theme, err := ui.LookupTheme("")
+		require.NoError(t, err)
+		var out, errOut bytes.Buffer
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{MaxReposConcurrency: 1, Quiet: true})
+		orchestrator.SetPrinter(ui.New(&out, &errOut, theme, false))
+		missing := filepath.Join(root, "missing dir")
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformLocal: {
+				{Platform: models.PlatformLocal, Owner: "local", Name: "present", FullName: root},
+				{Platform: models.PlatformLocal, Owner: "local", Name: "missing", FullName: missing},
+			},
+		}))
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 2)
+		tokens := orchestrator.Summary().Results[0].Tokens
+		assert.Equal(t, fmt.Sprintf("status=ok platform=local repo=%s files=1 tokens=%d output=%s", root, tokens, filepath.Join(cfg.Output.Directory, utils.SanitizeRepoName(root))), lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], fmt.Sprintf("status=failed platform=local repo=%q files=0 tokens=0 error=", missing)), lines[1])
+	})
+}
@@ -0,0 +1,93 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+
+	"sherpa/internal/adapters"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// expandGroups replaces organizations and groups with the repositories they contain that
+// match the repository filter, skipping repositories already named by another argument
+func (o *Orchestrator) expandGroups(ctx context.Context, platform models.Platform, connection *platformConnection, repoInfos []*models.RepositoryInfo) []*models.RepositoryInfo {
+	expanded := make([]*models.RepositoryInfo, 0, len(repoInfos))
+	seen := make(map[string]bool)
+	added := 0
+	for _, repoInfo := range repoInfos {
+		if repoInfo.Kind != models.KindGroup {
+			expanded = append(expanded, repoInfo)
+			seen[repoInfo.FullName] = true
+		}
+	}
+
+	for _, group := range repoInfos {
+		if group.Kind != models.KindGroup {
+			continue
+		}
+		repos, err := o.listGroup(ctx, platform, connection, group)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("group", group.FullName).Error("Failed to list group repositories")
+			o.printFailure(err, "Failed to list repositories of %s: %v", group.FullName, err)
+			o.recordRepositoryFailure(group.FullName, platform, fmt.Errorf("%s: %w", group.FullName, err))
+			continue
+		}
+
+		// The group was counted as one repository
+		added--
+		for _, repo := range repos {
+			if seen[repo.FullName] {
+				continue
+			}
+			seen[repo.FullName] = true
+			// Repositories of a tagged group share its tags
+			repo.Tags = group.Tags
+			expanded = append(expanded, repo)
+			added++
+		}
+		logger.Logger.WithFields(map[string]interface{}{
+			"group":        group.FullName,
+			"platform":     platform,
+			"repositories": len(repos),
+		}).Info("Listed group repositories")
+	}
+
+	o.failuresMu.Lock()
+	o.summary.Repositories += added
+	o.failuresMu.Unlock()
+	return expanded
+}
+
+// listGroup lists the repositories of an organization or group selected by the filter
+func (o *Orchestrator) listGroup(ctx context.Context, platform models.Platform, connection *platformConnection, group *models.RepositoryInfo) ([]*models.RepositoryInfo, error) {
+	// Listing goes through the API even when repositories are cloned
+	provider := connection.provider
+	if provider == nil {
+		if connection.tokenErr != nil {
+			return nil, connection.tokenErr
+		}
+		var err error
+		provider, err = adapters.CreateProviderWithTransport(platform, o.config, connection.token, connection.transport)
+		if err != nil {
+			return nil, err
+		}
+	}
+	lister, ok := provider.(adapters.RepositoryLister)
+	if !ok {
+		return nil, fmt.Errorf("listing repositories is not supported on %s", platform)
+	}
+
+	listed, err := lister.ListRepositories(ctx, group.FullName)
+	if err != nil {
+		return nil, err
+	}
+	repos := adapters.GroupRepositories(group, listed, o.cliOptions.RepositoryFilter)
+	if len(repos) == 0 {
+		logger.Logger.WithFields(map[string]interface{}{
+			"group":  group.FullName,
+			"listed": len(listed),
+		}).Warn("No repositories of the group match the filters")
+	}
+	return repos, nil
+}
@@ -0,0 +1,115 @@
+package orchestration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/internal/config"
+	"sherpa/internal/fakevcs"
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestrator_Groups(t *testing.T) {
+	fixtures := func() *fakevcs.Fixtures {
+		files := map[string]string{"README.md": "# Service\n"}
+		return &fakevcs.Fixtures{Repositories: []fakevcs.Repository{
+			{Path: "platform/api", Files: files, Topics: []string{"go"}},
+			{Path: "platform/web", Files: files},
+			{Path: "platform/legacy", Files: files, Archived: true},
+			{Path: "platform/api-fork", Files: files, Fork: true},
+			{Path: "other/tool", Files: files},
+		}}
+	}
+	outputs := func(t *testing.T, dir string) []string {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			if entry.IsDir() && entry.Name()[0] != '.' {
+				names = append(names, entry.Name())
+			}
+		}
+		return names
+	}
+
+	t.Run("should process the repositories of an organization except archived ones and forks", func(t *testing.T) {
+		server := fakevcs.NewServer(fixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true, MaxReposConcurrency: 2})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitHub: {
+				{Platform: models.PlatformGitHub, Owner: "platform", Name: "platform", FullName: "platform", Kind: models.KindGroup},
+				{Platform: models.PlatformGitHub, Owner: "platform", Name: "api", FullName: "platform/api"},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		summary := orchestrator.Summary()
+		assert.Equal(t, 2, summary.Repositories)
+		assert.Equal(t, 2, summary.Succeeded)
+		assert.ElementsMatch(t, []string{utils.SanitizeRepoName("platform/api"), utils.SanitizeRepoName("platform/web")}, outputs(t, cfg.Output.Directory))
+	})
+
+	t.Run("should filter a GitLab group by topic", func(t *testing.T) {
+		server := fakevcs.NewServer(fixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitLab.BaseURL = server.GitLabURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{
+			Token:            fakevcs.Token,
+			Quiet:            true,
+			RepositoryFilter: models.RepositoryFilter{IncludeForks: true, Topics: []string{"go"}},
+		})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitLab: {
+				{Platform: models.PlatformGitLab, Owner: "platform", Name: "platform", FullName: "platform", Kind: models.KindGroup},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+		assert.Equal(t, []string{utils.SanitizeRepoName("platform/api")}, outputs(t, cfg.Output.Directory))
+	})
+
+	t.Run("should report a missing group as a failure", func(t *testing.T) {
+		server := fakevcs.NewServer(fixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.Gitea.BaseURL = server.GiteaURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitea: {
+				{Platform: models.PlatformGitea, Owner: "missing", Name: "missing", FullName: "missing", Kind: models.KindGroup},
+			},
+		})
+		require.NoError(t, err)
+		assert.ErrorContains(t, orchestrator.Err(), "missing")
+		assert.Equal(t, 1, orchestrator.Summary().Failed())
+	})
+}
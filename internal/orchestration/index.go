@@ -0,0 +1,164 @@
+package orchestration
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sherpa/pkg/utils"
+)
+
+// IndexFileName is the name of the page linking the outputs of multi-repository runs
+const IndexFileName = "index.md"
+
+// writeIndex writes a Markdown page to the output directory linking the outputs written for
+// each repository, so the results of a run can be browsed from a shared drive
+func (o *Orchestrator) writeIndex() error {
+	o.failuresMu.Lock()
+	var failed []repositoryOutcome
+	for _, outcome := range o.outcomes {
+		if outcome.status == outcomeFailed || outcome.status == outcomeSkipped {
+			failed = append(failed, outcome)
+		}
+	}
+	o.failuresMu.Unlock()
+
+	if err := os.MkdirAll(o.config.Output.Directory, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	content := generateIndex(o.config.Output.Directory, o.Summary(), failed)
+	return o.writer.WriteFiles([]OutputFile{{Path: filepath.Join(o.config.Output.Directory, IndexFileName), Content: content}})
+}
+
+// generateIndex renders the index of a run written to root, with a table of the repositories
+// processed, a section listing the outputs of each one and the repositories without output.
+// Repositories are sorted so runs can be compared, and the page has no timings.
+func generateIndex(root string, summary RunSummary, failed []repositoryOutcome) string {
+	results := make([]RepositorySummary, len(summary.Results))
+	copy(results, summary.Results)
+	sort.Slice(results, func(i, j int) bool { return results[i].Repository < results[j].Repository })
+	sort.Slice(failed, func(i, j int) bool { return failed[i].repository < failed[j].repository })
+
+	tokens := 0
+	for _, result := range results {
+		tokens += result.Tokens
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Sherpa Outputs\n\n")
+	fmt.Fprintf(&sb, "%d repositories processed, %d succeeded", summary.Repositories, summary.Succeeded)
+	if summary.Failed() > 0 {
+		fmt.Fprintf(&sb, ", %d failed", summary.Failed())
+	}
+	if summary.Skipped > 0 {
+		fmt.Fprintf(&sb, ", %d skipped", summary.Skipped)
+	}
+	fmt.Fprintf(&sb, ". %d files, %s, %s tokens.\n", summary.Files, utils.FormatBytes(summary.Size), utils.FormatTokenCount(tokens))
+
+	if len(results) > 0 {
+		sb.WriteString("\n| Repository | Platform | Files | Size | Tokens | Output |\n")
+		sb.WriteString("| --- | --- | ---: | ---: | ---: | --- |\n")
+		for _, result := range results {
+			name := indexCell(result.Repository)
+			if result.Incomplete {
+				name += " (incomplete)"
+			}
+			fmt.Fprintf(&sb, "| [%s](#%s) | %s | %d | %s | %s | [%s](%s) |\n",
+				name, indexAnchor(result.Repository), result.Platform, result.Files,
+				utils.FormatBytes(result.Size), utils.FormatTokenCount(result.Tokens),
+				indexCell(indexPath(root, result.Output)), indexLink(root, result.Output))
+		}
+	}
+
+	for _, result := range results {
+		fmt.Fprintf(&sb, "\n## %s\n\n", result.Repository)
+		if result.Description != "" {
+			fmt.Fprintf(&sb, "%s\n\n", strings.Join(strings.Fields(result.Description), " "))
+		}
+		if result.WebURL != "" {
+			fmt.Fprintf(&sb, "- URL: <%s>\n", result.WebURL)
+		}
+		if result.Commit != "" {
+			fmt.Fprintf(&sb, "- Commit: `%s`\n", result.Commit)
+		}
+		if len(result.Tags) > 0 {
+			fmt.Fprintf(&sb, "- Tags: %s\n", strings.Join(result.Tags, ", "))
+		}
+		fmt.Fprintf(&sb, "- %d files, %s, %s tokens\n", result.Files, utils.FormatBytes(result.Size), utils.FormatTokenCount(result.Tokens))
+		if result.Incomplete {
+			sb.WriteString("- Incomplete: rate limited or out of time before every file was fetched\n")
+		}
+		if len(result.OutputFiles) == 0 {
+			continue
+		}
+		sb.WriteString("\n| Output | Size |\n")
+		sb.WriteString("| --- | ---: |\n")
+		for _, file := range result.OutputFiles {
+			path := filepath.Join(result.Output, filepath.FromSlash(file.Name))
+			fmt.Fprintf(&sb, "| [%s](%s) | %s |\n", indexCell(file.Name), indexLink(root, path), utils.FormatBytes(file.Size))
+		}
+	}
+
+	if len(failed) > 0 {
+		sb.WriteString("\n## Without Output\n\n")
+		for _, outcome := range failed {
+			if outcome.err == nil {
+				fmt.Fprintf(&sb, "- %s (%s): %s\n", outcome.repository, outcome.platform, outcome.status)
+				continue
+			}
+			message := strings.TrimPrefix(outcome.err.Error(), outcome.repository+": ")
+			fmt.Fprintf(&sb, "- %s (%s): %s\n", outcome.repository, outcome.platform, strings.Join(strings.Fields(message), " "))
+		}
+	}
+	return sb.String()
+}
+
+// indexPath returns path relative to root with forward slashes, or absolute when it is
+// outside root
+func indexPath(root, path string) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(absPath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// indexLink returns the link target of path from the index written to root, with each
+// segment escaped so names with spaces still link
+func indexLink(root, path string) string {
+	segments := strings.Split(indexPath(root, path), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// indexAnchor returns the anchor Markdown renderers give to the heading of a repository
+func indexAnchor(heading string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			sb.WriteRune('-')
+		case r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// indexCell escapes the pipes of a table cell
+func indexCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,136 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sherpa/internal/config"
+	"sherpa/internal/fakevcs"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	root := filepath.Join("out", "2024-01-02")
+	summary := RunSummary{
+		Repositories: 3,
+		Succeeded:    2,
+		Files:        15,
+		Size:         2048,
+		Results: []RepositorySummary{
+			{
+				Repository: "owner/zeta",
+				Platform:   models.PlatformGitLab,
+				Files:      3,
+				Size:       1024,
+				Tokens:     300,
+				Output:     filepath.Join(root, "owner_zeta"),
+				Incomplete: true,
+			},
+			{
+				Repository:  "owner/alpha",
+				Platform:    models.PlatformGitHub,
+				Files:       12,
+				Size:        1024,
+				Tokens:      1200,
+				Output:      filepath.Join(root, "owner alpha"),
+				WebURL:      "https://github.com/owner/alpha",
+				Description: "Alpha | service\nwith a long description",
+				Commit:      "0123456789abcdef",
+				Tags:        []string{"team:payments", "tier:critical"},
+				OutputFiles: []OutputSize{{Name: "llms.txt", Size: 100}, {Name: "llms-full.txt", Size: 2048}},
+			},
+		},
+	}
+	failed := []repositoryOutcome{
+		{status: outcomeFailed, platform: models.PlatformGitHub, repository: "owner/broken", err: errors.New("owner/broken: repository not found")},
+	}
+
+	index := generateIndex(root, summary, failed)
+
+	t.Run("should total the run", func(t *testing.T) {
+		assert.Contains(t, index, "3 repositories processed, 2 succeeded, 1 failed. 15 files, 2.0 KB, 1500 tokens.")
+	})
+
+	t.Run("should list the repositories sorted with links relative to the index", func(t *testing.T) {
+		alpha := "| [owner/alpha](#owneralpha) | github | 12 | 1.0 KB | 1200 | [owner alpha](owner%20alpha) |"
+		zeta := "| [owner/zeta (incomplete)](#ownerzeta) | gitlab | 3 | 1.0 KB | 300 | [owner_zeta](owner_zeta) |"
+		assert.Contains(t, index, alpha)
+		assert.Contains(t, index, zeta)
+		assert.Less(t, strings.Index(index, alpha), strings.Index(index, zeta))
+	})
+
+	t.Run("should describe each repository and link its outputs", func(t *testing.T) {
+		assert.Contains(t, index, "## owner/alpha\n\nAlpha | service with a long description\n\n- URL: <https://github.com/owner/alpha>\n- Commit: `0123456789abcdef`\n- Tags: team:payments, tier:critical\n")
+		assert.Contains(t, index, "| [llms.txt](owner%20alpha/llms.txt) | 100 B |\n| [llms-full.txt](owner%20alpha/llms-full.txt) | 2.0 KB |\n")
+		assert.Contains(t, index, "## owner/zeta\n\n- 3 files, 1.0 KB, 300 tokens\n- Incomplete:")
+	})
+
+	t.Run("should list the repositories without output", func(t *testing.T) {
+		assert.Contains(t, index, "## Without Output\n\n- owner/broken (github): repository not found\n")
+	})
+}
+
+func TestIndexPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     string
+		path     string
+		expected string
+	}{
+		{name: "should make paths relative to the root", root: "out", path: filepath.Join("out", "owner_repo", "llms.txt"), expected: "owner_repo/llms.txt"},
+		{name: "should keep paths outside the root absolute", root: "out", path: filepath.Join(string(filepath.Separator), "elsewhere", "llms.txt"), expected: filepath.ToSlash(filepath.Join(string(filepath.Separator), "elsewhere", "llms.txt"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, indexPath(tt.root, tt.path))
+		})
+	}
+}
+
+func TestOrchestrator_Index(t *testing.T) {
+	run := func(t *testing.T, repositories ...string) string {
+		t.Helper()
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		var repoInfos []*models.RepositoryInfo
+		for _, name := range repositories {
+			repoInfos = append(repoInfos, &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: name, FullName: "sherpa-fixtures/" + name})
+		}
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		require.NoError(t, orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{models.PlatformGitHub: repoInfos}))
+		return cfg.Output.Directory
+	}
+
+	t.Run("should link the outputs of multi-repository runs", func(t *testing.T) {
+		output := run(t, "hello", "missing")
+
+		data, err := os.ReadFile(filepath.Join(output, IndexFileName))
+		require.NoError(t, err)
+		index := string(data)
+		assert.Contains(t, index, "## sherpa-fixtures/hello\n\nSmall Go service used to validate providers end to end\n")
+		assert.Contains(t, index, "[llms-full.txt](sherpa-fixtures_hello/llms-full.txt)")
+		assert.Contains(t, index, "- sherpa-fixtures/missing (github): ")
+		assert.FileExists(t, filepath.Join(output, "sherpa-fixtures_hello", "llms-full.txt"))
+	})
+
+	t.Run("should not write an index for a single repository", func(t *testing.T) {
+		output := run(t, "hello")
+		assert.NoFileExists(t, filepath.Join(output, IndexFileName))
+	})
+}
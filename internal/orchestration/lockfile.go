@@ -0,0 +1,163 @@
+package orchestration
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"sherpa/internal/filelock"
+	"sherpa/pkg/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileVersion is the format version written to new lock files
+const LockFileVersion = 1
+
+// LockEntry pins a repository to the commit its output was generated from
+type LockEntry struct {
+	Platform   models.Platform `yaml:"platform"`
+	Repository string          `yaml:"repository"`
+	Ref        string          `yaml:"ref,omitempty"` // Branch or tag requested, empty for the default branch
+	Commit     string          `yaml:"commit"`
+	Tags       []string        `yaml:"tags,omitempty"` // Tags of the repository in the configuration file
+}
+
+// LockFile records repository to commit mappings for reproducible outputs
+type LockFile struct {
+	Version      int         `yaml:"version"`
+	RunID        string      `yaml:"run_id,omitempty"`
+	GeneratedAt  time.Time   `yaml:"generated_at,omitempty"`
+	Repositories []LockEntry `yaml:"repositories"`
+
+	// OmitTimestamps leaves the run ID and generation time out, so the lock file only
+	// changes with the commits it records
+	OmitTimestamps bool `yaml:"-"`
+
+	mu sync.Mutex
+}
+
+// NewLockFile creates an empty lock file
+func NewLockFile() *LockFile {
+	return &LockFile{Version: LockFileVersion}
+}
+
+// LoadLockFile reads a lock file from disk
+func LoadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file %s: %w", path, err)
+	}
+
+	lock := NewLockFile()
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", path, err)
+	}
+
+	if lock.Version > LockFileVersion {
+		return nil, fmt.Errorf("unsupported lock file version %d (max: %d)", lock.Version, LockFileVersion)
+	}
+
+	return lock, nil
+}
+
+// Lookup returns the entry recorded for a repository at ref. Without an entry for that ref,
+// it falls back to an entry of the default branch: the first entry of the repository when ref
+// is empty, or one recorded without a ref. Other refs never stand in for each other, so a
+// locked run of a branch fails instead of pinning the commit of another branch.
+func (l *LockFile) Lookup(platform models.Platform, repository, ref string) (LockEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var fallback *LockEntry
+	for i, entry := range l.Repositories {
+		if entry.Platform != platform || entry.Repository != repository {
+			continue
+		}
+		if entry.Ref == ref {
+			return entry, true
+		}
+		if fallback == nil && (ref == "" || entry.Ref == "") {
+			fallback = &l.Repositories[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return LockEntry{}, false
+}
+
+// Record adds an entry, replacing any existing entry for the same repository and ref
+func (l *LockFile) Record(entry LockEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, existing := range l.Repositories {
+		if existing.Platform == entry.Platform && existing.Repository == entry.Repository && existing.Ref == entry.Ref {
+			l.Repositories[i] = entry
+			return
+		}
+	}
+	l.Repositories = append(l.Repositories, entry)
+}
+
+// Len returns the number of recorded repositories
+func (l *LockFile) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.Repositories)
+}
+
+// Save writes the lock file to disk with entries in a stable order. The entries are merged
+// into the lock file on disk, replacing the entries of the same repositories and refs, so
+// runs processing other repositories keep theirs, even when saving concurrently.
+func (l *LockFile) Save(path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.OmitTimestamps {
+		l.RunID = ""
+		l.GeneratedAt = time.Time{}
+	} else if l.GeneratedAt.IsZero() {
+		l.GeneratedAt = time.Now().UTC().Truncate(time.Second)
+	}
+
+	err := filelock.Update(path, 0644, func(current []byte) ([]byte, error) {
+		merged := &LockFile{Version: LockFileVersion, RunID: l.RunID, GeneratedAt: l.GeneratedAt}
+		if current != nil {
+			existing := NewLockFile()
+			if err := yaml.Unmarshal(current, existing); err != nil {
+				return nil, fmt.Errorf("failed to parse lock file %s: %w", path, err)
+			}
+			if existing.Version > LockFileVersion {
+				return nil, fmt.Errorf("unsupported lock file version %d (max: %d)", existing.Version, LockFileVersion)
+			}
+			merged.Repositories = existing.Repositories
+		}
+		for _, entry := range l.Repositories {
+			merged.Record(entry)
+		}
+
+		sort.Slice(merged.Repositories, func(i, j int) bool {
+			if merged.Repositories[i].Platform != merged.Repositories[j].Platform {
+				return merged.Repositories[i].Platform < merged.Repositories[j].Platform
+			}
+			if merged.Repositories[i].Repository != merged.Repositories[j].Repository {
+				return merged.Repositories[i].Repository < merged.Repositories[j].Repository
+			}
+			return merged.Repositories[i].Ref < merged.Repositories[j].Ref
+		})
+
+		data, err := yaml.Marshal(merged)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode lock file: %w", err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write lock file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package orchestration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile_Record(t *testing.T) {
+	t.Run("should add new entries", func(t *testing.T) {
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Commit: "aaa"})
+		lock.Record(LockEntry{Platform: models.PlatformGitLab, Repository: "owner/a", Commit: "bbb"})
+
+		assert.Equal(t, 2, lock.Len())
+	})
+
+	t.Run("should replace entries for the same repository", func(t *testing.T) {
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Commit: "aaa"})
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Commit: "ccc"})
+
+		entry, exists := lock.Lookup(models.PlatformGitHub, "owner/a", "")
+		require.True(t, exists)
+		assert.Equal(t, "ccc", entry.Commit)
+		assert.Equal(t, 1, lock.Len())
+	})
+
+	t.Run("should keep one entry per ref of a repository", func(t *testing.T) {
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Ref: "main", Commit: "aaa"})
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Ref: "release", Commit: "bbb"})
+
+		assert.Equal(t, 2, lock.Len())
+		entry, exists := lock.Lookup(models.PlatformGitHub, "owner/a", "release")
+		require.True(t, exists)
+		assert.Equal(t, "bbb", entry.Commit)
+	})
+
+	t.Run("should fall back to another ref of the repository", func(t *testing.T) {
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Ref: "main", Commit: "aaa"})
+
+		entry, exists := lock.Lookup(models.PlatformGitHub, "owner/a", "")
+		require.True(t, exists)
+		assert.Equal(t, "aaa", entry.Commit)
+	})
+
+	t.Run("should fall back to an entry recorded without a ref", func(t *testing.T) {
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Commit: "aaa"})
+
+		entry, exists := lock.Lookup(models.PlatformGitHub, "owner/a", "main")
+		require.True(t, exists)
+		assert.Equal(t, "aaa", entry.Commit)
+	})
+
+	t.Run("should not fall back to another named ref", func(t *testing.T) {
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Ref: "main", Commit: "aaa"})
+
+		_, exists := lock.Lookup(models.PlatformGitHub, "owner/a", "release")
+		assert.False(t, exists)
+	})
+
+	t.Run("should not find unknown repositories", func(t *testing.T) {
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/a", Commit: "aaa"})
+
+		_, exists := lock.Lookup(models.PlatformGitLab, "owner/a", "")
+		assert.False(t, exists)
+	})
+}
+
+func TestLockFile_SaveAndLoad(t *testing.T) {
+	t.Run("should round trip entries in a stable order", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sherpa.lock")
+
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/zeta", Ref: "main", Commit: "222"})
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/alpha", Commit: "111"})
+		require.NoError(t, lock.Save(path))
+
+		loaded, err := LoadLockFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, LockFileVersion, loaded.Version)
+		assert.False(t, loaded.GeneratedAt.IsZero())
+		require.Len(t, loaded.Repositories, 2)
+		assert.Equal(t, "owner/alpha", loaded.Repositories[0].Repository)
+		assert.Equal(t, "owner/zeta", loaded.Repositories[1].Repository)
+		assert.Equal(t, "main", loaded.Repositories[1].Ref)
+		assert.Equal(t, "222", loaded.Repositories[1].Commit)
+	})
+
+	t.Run("should leave out the run ID and generation time when omitting timestamps", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sherpa.lock")
+
+		lock := NewLockFile()
+		lock.RunID = "20240301T120000Z-abcdef"
+		lock.OmitTimestamps = true
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/alpha", Commit: "111"})
+		require.NoError(t, lock.Save(path))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "run_id")
+		assert.NotContains(t, string(data), "generated_at")
+	})
+
+	t.Run("should merge entries into the existing lock file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sherpa.lock")
+
+		previous := NewLockFile()
+		previous.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/alpha", Commit: "111"})
+		previous.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/zeta", Commit: "222"})
+		require.NoError(t, previous.Save(path))
+
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/zeta", Commit: "333"})
+		lock.Record(LockEntry{Platform: models.PlatformGitLab, Repository: "group/beta", Commit: "444"})
+		require.NoError(t, lock.Save(path))
+
+		loaded, err := LoadLockFile(path)
+		require.NoError(t, err)
+		require.Len(t, loaded.Repositories, 3)
+		assert.Equal(t, "111", loaded.Repositories[0].Commit)
+		assert.Equal(t, "333", loaded.Repositories[1].Commit)
+		assert.Equal(t, "group/beta", loaded.Repositories[2].Repository)
+	})
+
+	t.Run("should not overwrite lock files of newer format versions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sherpa.lock")
+		require.NoError(t, os.WriteFile(path, []byte("version: 99\nrepositories: []\n"), 0644))
+
+		lock := NewLockFile()
+		lock.Record(LockEntry{Platform: models.PlatformGitHub, Repository: "owner/alpha", Commit: "111"})
+		err := lock.Save(path)
+		assert.ErrorContains(t, err, "unsupported lock file version")
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "version: 99\nrepositories: []\n", string(data))
+	})
+
+	t.Run("should error when file does not exist", func(t *testing.T) {
+		_, err := LoadLockFile(filepath.Join(t.TempDir(), "missing.lock"))
+		assert.Error(t, err)
+	})
+
+	t.Run("should error on newer format versions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sherpa.lock")
+		require.NoError(t, os.WriteFile(path, []byte("version: 99\nrepositories: []\n"), 0644))
+
+		_, err := LoadLockFile(path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported lock file version")
+	})
+}
@@ -0,0 +1,46 @@
+package orchestration
+
+import (
+	"fmt"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/pipeline"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+)
+
+// offlineProcessor returns a processor serving a platform repository from the state
+// recorded by a previous incremental run, failing when no complete state was recorded.
+// Gists, snippets and groups are only listed through the API, so they fail offline.
+func (o *Orchestrator) offlineProcessor(repoInfo *models.RepositoryInfo, platform models.Platform, skipList *pipeline.SkipList, reviewer pipeline.FileReviewer) (*pipeline.RepoProcessor, error) {
+	if repoInfo.Kind != "" {
+		return nil, fmt.Errorf("%s cannot be fetched: %w", repoInfo.FullName, adapters.ErrOffline)
+	}
+
+	statePath := pipeline.StatePath(o.config.Output.Directory, outputName(repoInfo))
+	state, err := pipeline.LoadRepoState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, sherpaerrors.New(sherpaerrors.KindNotFound, fmt.Sprintf("no recorded content in %s: run once online with --incremental to process it offline", statePath))
+	}
+	if state.Incomplete {
+		return nil, fmt.Errorf("the recorded content in %s is incomplete: finish the run online to process it offline", statePath)
+	}
+	if o.cliOptions.Locked {
+		if entry, exists := o.lock.Lookup(platform, repoInfo.FullName, repoInfo.Branch); exists && entry.Commit != state.Commit {
+			return nil, fmt.Errorf("the recorded content in %s is at commit %s, not at the locked commit %s", statePath, state.Commit, entry.Commit)
+		}
+	}
+
+	repository := models.Repository{
+		Name:              repoInfo.Name,
+		Path:              repoInfo.Name,
+		PathWithNamespace: repoInfo.FullName,
+		WebURL:            repoInfo.URL,
+		Platform:          platform,
+		Owner:             repoInfo.Owner,
+	}
+	return o.newRepoProcessor(platform, pipeline.NewStateProvider(repository, state), skipList, reviewer), nil
+}
@@ -0,0 +1,33 @@
+package orchestration
+
+// Stages of a repository reported to progress listeners
+const (
+	ProgressFetching = "fetching" // Resolving the ref and fetching files
+	ProgressWriting  = "writing"  // Writing the generated outputs
+	ProgressDone     = "done"     // Outputs written to Output
+	ProgressFailed   = "failed"   // Failed with Error
+	ProgressSkipped  = "skipped"  // Left unprocessed once --fail-fast stopped the run
+)
+
+// Progress is a stage reached by a repository during a run
+type Progress struct {
+	Repository string `json:"repository"`
+	Stage      string `json:"stage"`
+	Output     string `json:"output,omitempty"` // Output directory, once done
+	Files      int    `json:"files,omitempty"`
+	Tokens     int    `json:"tokens,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SetProgress calls report each time a repository reaches a stage. It is called from the
+// goroutines processing repositories, so it must be safe for concurrent use.
+func (o *Orchestrator) SetProgress(report func(Progress)) {
+	o.progress = report
+}
+
+// reportProgress sends a stage to the progress listener, if any
+func (o *Orchestrator) reportProgress(progress Progress) {
+	if o.progress != nil {
+		o.progress(progress)
+	}
+}
@@ -0,0 +1,100 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/generators"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// PullRequestFileName is the name of the output generated for a pull request
+const PullRequestFileName = "llms-pr.txt"
+
+// PullRequestOptions selects what the output of a pull request includes
+type PullRequestOptions struct {
+	ContextLines  int  // Lines of the new version shown around each change, 0 for diffs only
+	NoDescription bool // Leave the pull request description out
+}
+
+// ProcessPullRequest fetches the files changed by a pull request and writes llms-pr.txt for
+// them into a directory named after the repository and pull request, returning its path
+func (o *Orchestrator) ProcessPullRequest(ctx context.Context, repoInfo *models.RepositoryInfo, number int, options PullRequestOptions) (string, error) {
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository":   repoInfo.FullName,
+		"platform":     repoInfo.Platform,
+		"pull_request": number,
+	}).Info("Processing pull request")
+
+	token, err := GetTokenForPlatform(repoInfo.Platform, o.config, o.cliOptions.Token)
+	if err != nil {
+		return "", err
+	}
+	provider, err := adapters.CreateProviderWithTransport(repoInfo.Platform, o.config, token, o.transport())
+	if err != nil {
+		return "", fmt.Errorf("failed to create provider: %w", err)
+	}
+	fetcher, ok := provider.(adapters.PullRequestFetcher)
+	if !ok {
+		return "", fmt.Errorf("pull requests are not supported on %s", repoInfo.Platform)
+	}
+
+	pr, err := fetcher.GetPullRequest(ctx, repoInfo.FullName, number)
+	if err != nil {
+		return "", err
+	}
+	if options.NoDescription {
+		pr.Body = ""
+	}
+
+	// Read the changed files at the head of the pull request to show their surroundings
+	contents := make(map[string]string)
+	if options.ContextLines > 0 {
+		var paths []string
+		for _, file := range pr.Files {
+			if file.Status != "removed" && file.Patch != "" {
+				paths = append(paths, file.Path)
+			}
+		}
+		processing := o.config.ProcessingFor(repoInfo.Platform)
+		files, err := provider.GetMultipleFiles(ctx, repoInfo.FullName, paths, pr.HeadSHA, processing.MaxConcurrency, &processing)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch changed files: %w", err)
+		}
+		for _, file := range files {
+			if file.Error != nil || file.IsBinary {
+				logger.Logger.WithError(file.Error).WithField("file", file.Path).Trace("Leaving out the context of a changed file")
+				continue
+			}
+			contents[file.Path] = file.Content
+		}
+	}
+
+	generator := generators.NewGeneratorWithConfig(true, o.config.Output)
+	generator.SetClock(o.clock)
+	text := generator.GeneratePullRequest(repoInfo.FullName, pr, contents, options.ContextLines)
+
+	outputDir := filepath.Join(o.config.Output.Directory, utils.SanitizeRepoName(fmt.Sprintf("%s/pull/%d", repoInfo.FullName, number)))
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+	outputPath := filepath.Join(outputDir, PullRequestFileName)
+	if err := o.writer.WriteFiles([]OutputFile{{Path: outputPath, Content: text}}); err != nil {
+		return "", err
+	}
+
+	if !o.cliOptions.Quiet {
+		block := o.printer.Block().Success("Successfully processed pull request %s#%d (%s)", repoInfo.FullName, number, repoInfo.Platform)
+		block.Field("Title", "%s", pr.Title)
+		block.Field("Files changed", "%d", len(pr.Files))
+		block.Field("Estimated tokens", "%s", utils.FormatTokenCount(utils.EstimateTokens(text)))
+		block.Field("Output", "%s", outputPath)
+		block.Blank().Flush()
+	}
+	return outputPath, nil
+}
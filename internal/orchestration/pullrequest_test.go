@@ -0,0 +1,87 @@
+package orchestration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/internal/config"
+	"sherpa/internal/fakevcs"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestrator_ProcessPullRequest(t *testing.T) {
+	fixtures := &fakevcs.Fixtures{Repositories: []fakevcs.Repository{{
+		Path: "owner/service",
+		Files: map[string]string{
+			"main.go":   "package main\n\nfunc main() {\n\tserve(8080)\n}\n",
+			"README.md": "# Service\n",
+		},
+		PullRequests: []fakevcs.PullRequest{{
+			Number: 7,
+			Title:  "Listen on 8080",
+			Body:   "The old port clashes with the proxy.",
+			Author: "alice",
+			Files: []fakevcs.PullRequestFile{
+				{Path: "main.go", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -4,1 +4,1 @@\n-\tserve(80)\n+\tserve(8080)"},
+				{Path: "old.go", Status: "removed", Deletions: 3, Patch: "@@ -1,3 +0,0 @@\n-package main\n-\n-func old() {}"},
+			},
+		}},
+	}}}
+
+	newOrchestrator := func(t *testing.T, server *fakevcs.Server) (*Orchestrator, string) {
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		return NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true}), cfg.Output.Directory
+	}
+	repoInfo := &models.RepositoryInfo{Platform: models.PlatformGitHub, Owner: "owner", Name: "service", FullName: "owner/service"}
+
+	t.Run("should write the changed files of the pull request to llms-pr.txt", func(t *testing.T) {
+		server := fakevcs.NewServer(fixtures)
+		defer server.Close()
+		orchestrator, outputDir := newOrchestrator(t, server)
+
+		outputPath, err := orchestrator.ProcessPullRequest(context.Background(), repoInfo, 7, PullRequestOptions{ContextLines: 2})
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(outputDir, "owner_service_pull_7", PullRequestFileName), outputPath)
+
+		content, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		text := string(content)
+		assert.Contains(t, text, "# Pull Request: owner/service#7\n# Title: Listen on 8080\n")
+		assert.Contains(t, text, "The old port clashes with the proxy.")
+		assert.Contains(t, text, "- removed: old.go (+0 -3)\n")
+		assert.Contains(t, text, "+\tserve(8080)")
+		assert.Contains(t, text, "2 | \n3 | func main() {\n4 | \tserve(8080)\n5 | }\n")
+		assert.NotContains(t, text, "# Service")
+	})
+
+	t.Run("should leave out the description when asked", func(t *testing.T) {
+		server := fakevcs.NewServer(fixtures)
+		defer server.Close()
+		orchestrator, _ := newOrchestrator(t, server)
+
+		outputPath, err := orchestrator.ProcessPullRequest(context.Background(), repoInfo, 7, PullRequestOptions{NoDescription: true})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "The old port clashes with the proxy.")
+		assert.NotContains(t, string(content), "func main() {\n")
+	})
+
+	t.Run("should fail for unknown pull requests", func(t *testing.T) {
+		server := fakevcs.NewServer(fixtures)
+		defer server.Close()
+		orchestrator, _ := newOrchestrator(t, server)
+
+		_, err := orchestrator.ProcessPullRequest(context.Background(), repoInfo, 8, PullRequestOptions{})
+		assert.ErrorContains(t, err, "failed to fetch pull request #8")
+	})
+}
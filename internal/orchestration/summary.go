@@ -0,0 +1,243 @@
+package orchestration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/pipeline"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+	"sherpa/pkg/ui"
+	"sherpa/pkg/utils"
+)
+
+// RunSummaryFile is the name of the run summary written to the output directory
+const RunSummaryFile = "run-summary.json"
+
+// RunSummary totals the repositories processed in a run
+type RunSummary struct {
+	Repositories int                 // Repositories requested
+	Succeeded    int                 // Repositories whose output was written
+	Skipped      int                 // Repositories left unprocessed once --fail-fast stopped the run
+	Files        int                 // Files included across successful repositories
+	Size         int64               // Bytes processed across successful repositories
+	Results      []RepositorySummary // Successful repositories, in completion order
+}
+
+// Failed returns the number of repositories without output, other than the skipped ones
+func (s RunSummary) Failed() int {
+	return s.Repositories - s.Succeeded - s.Skipped
+}
+
+// RepositorySummary describes what a processed repository contributes to its output, so
+// users can see which files are worth excluding
+type RepositorySummary struct {
+	Repository     string                 `json:"repository"`
+	Platform       models.Platform        `json:"platform"`
+	Files          int                    `json:"files"`
+	Size           int64                  `json:"size"`
+	Tokens         int                    `json:"tokens"`
+	DurationMS     int64                  `json:"duration_ms"`
+	Output         string                 `json:"output"`               // Directory the output was written to
+	Incomplete     bool                   `json:"incomplete,omitempty"` // Rate limited or out of time before every file was fetched
+	WebURL         string                 `json:"web_url,omitempty"`
+	Description    string                 `json:"description,omitempty"`
+	Commit         string                 `json:"commit,omitempty"` // Commit the output was generated from
+	Tags           []string               `json:"tags,omitempty"`   // Tags of the repository in the configuration file
+	OutputFiles    []OutputSize           `json:"output_files,omitempty"`
+	LargestFiles   []pipeline.FileSize    `json:"largest_files"`
+	TokenHistogram []pipeline.TokenBucket `json:"token_histogram"`
+}
+
+// OutputSize is a file written for a repository
+type OutputSize struct {
+	Name string `json:"name"` // Path relative to the output directory of the repository
+	Size int64  `json:"size"`
+}
+
+// NewRepositorySummary summarizes the included files of a processing result
+func NewRepositorySummary(repoPath string, platform models.Platform, result *models.ProcessingResult) RepositorySummary {
+	calculator := pipeline.NewStatsCalculator()
+	sizes := calculator.FileSizes(result.Files)
+	tokens := 0
+	for _, size := range sizes {
+		tokens += size.Tokens
+	}
+	return RepositorySummary{
+		Repository:     repoPath,
+		Platform:       platform,
+		Files:          result.Counts.Included,
+		Size:           result.TotalSize,
+		Tokens:         tokens,
+		DurationMS:     result.Duration.Milliseconds(),
+		Incomplete:     result.Incomplete != nil,
+		WebURL:         result.Repository.WebURL,
+		Description:    result.Repository.Description,
+		LargestFiles:   calculator.LargestFiles(sizes, pipeline.LargestFilesLimit),
+		TokenHistogram: calculator.TokenHistogram(sizes),
+	}
+}
+
+// runSummaryDocument is the layout of run-summary.json
+type runSummaryDocument struct {
+	RunID         string               `json:"run_id"`
+	StartedAt     time.Time            `json:"started_at"`
+	Duration      string               `json:"duration"`
+	Repositories  int                  `json:"repositories"`
+	Succeeded     int                  `json:"succeeded"`
+	Failed        int                  `json:"failed"`
+	Skipped       int                  `json:"skipped,omitempty"`
+	Files         int                  `json:"files"`
+	Size          int64                `json:"size"`
+	RateLimits    []adapters.RateLimit `json:"rate_limits,omitempty"`     // Quota left on each platform API at the end of the run
+	RateLimitWait string               `json:"rate_limit_wait,omitempty"` // Time spent waiting for quotas to reset
+	Results       []RepositorySummary  `json:"results"`
+}
+
+// writeRunSummary writes the totals and repository summaries of the run to the output
+// directory, sorted by repository so runs can be compared
+func (o *Orchestrator) writeRunSummary(startTime time.Time) error {
+	summary := o.Summary()
+	results := make([]RepositorySummary, len(summary.Results))
+	copy(results, summary.Results)
+	sort.Slice(results, func(i, j int) bool { return results[i].Repository < results[j].Repository })
+	rateLimits := o.throttler.Status()
+	rateLimitWait := ""
+	if rateLimits.Waited > 0 {
+		rateLimitWait = rateLimits.Waited.Round(time.Second).String()
+	}
+
+	data, err := json.MarshalIndent(runSummaryDocument{
+		RunID:         o.runID,
+		StartedAt:     startTime.UTC().Truncate(time.Second),
+		Duration:      o.clock.Now().Sub(startTime).Round(time.Millisecond).String(),
+		Repositories:  summary.Repositories,
+		Succeeded:     summary.Succeeded,
+		Failed:        summary.Failed(),
+		Skipped:       summary.Skipped,
+		Files:         summary.Files,
+		Size:          summary.Size,
+		RateLimits:    rateLimits.Limits,
+		RateLimitWait: rateLimitWait,
+		Results:       results,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run summary: %w", err)
+	}
+	if err := os.MkdirAll(o.config.Output.Directory, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return o.writer.WriteFiles([]OutputFile{{Path: filepath.Join(o.config.Output.Directory, RunSummaryFile), Content: string(data) + "\n"}})
+}
+
+// writeRateLimits lists the quota left on each platform API and the time spent waiting for
+// quotas to reset
+func writeRateLimits(block *ui.Block, status adapters.RateLimitStatus) {
+	if len(status.Limits) == 0 {
+		return
+	}
+	block.Field("Rate limits", "")
+	for _, limit := range status.Limits {
+		block.Line(2, "%d/%d left on %s (%s), resets at %s", limit.Remaining, limit.Limit, limit.Host, limit.Resource, limit.Reset.Format(time.TimeOnly))
+	}
+	if status.Waits > 0 {
+		block.Field("Waited for rate limits", "%s (%d times)", status.Waited.Round(time.Second), status.Waits)
+	}
+}
+
+// writeRepositorySizes lists the files adding the most tokens to an output and how tokens
+// are spread across its files
+func writeRepositorySizes(block *ui.Block, summary RepositorySummary) {
+	if len(summary.LargestFiles) == 0 {
+		return
+	}
+	block.Field("Largest files", "")
+	for _, size := range summary.LargestFiles {
+		block.Line(2, "%8s tokens  %s (%s)", utils.FormatTokenCount(size.Tokens), size.Path, utils.FormatBytes(size.Size))
+	}
+	block.Field("Token histogram", "")
+	for _, bucket := range summary.TokenHistogram {
+		block.Line(2, "%-9s %5d files  %8s tokens  %s", bucket.Label, bucket.Files, utils.FormatTokenCount(bucket.Tokens), utils.FormatBytes(bucket.Size))
+	}
+}
+
+// Statuses of a repository in the quiet mode summary lines
+const (
+	outcomeOK         = "ok"
+	outcomeIncomplete = "incomplete" // Written with the files fetched before the rate limit or time budget
+	outcomeFailed     = "failed"
+	outcomeSkipped    = "skipped" // Left unprocessed once --fail-fast stopped the run
+)
+
+// repositoryOutcome is the result of one repository, printed as a machine-parseable line
+// in quiet mode
+type repositoryOutcome struct {
+	status     string
+	platform   models.Platform
+	repository string
+	files      int
+	tokens     int
+	output     string
+	err        error
+}
+
+// printOutcomes prints one logfmt line per repository, sorted by platform and repository
+// so the output does not depend on which repository finished first
+func (o *Orchestrator) printOutcomes() {
+	o.failuresMu.Lock()
+	outcomes := append([]repositoryOutcome(nil), o.outcomes...)
+	o.failuresMu.Unlock()
+	sort.SliceStable(outcomes, func(i, j int) bool {
+		if outcomes[i].platform != outcomes[j].platform {
+			return outcomes[i].platform < outcomes[j].platform
+		}
+		return outcomes[i].repository < outcomes[j].repository
+	})
+
+	block := o.printer.Block()
+	for _, outcome := range outcomes {
+		block.Line(0, "%s", formatOutcome(outcome))
+	}
+	block.Flush()
+}
+
+// formatOutcome renders a repository result as status=ok platform=github repo=owner/repo
+// files=12 tokens=3400 output=..., with an error field instead of the output on failure, and
+// a hint field when the error has a remediation. Skipped repositories have neither.
+func formatOutcome(outcome repositoryOutcome) string {
+	fields := []string{
+		"status=" + outcome.status,
+		"platform=" + logfmtValue(string(outcome.platform)),
+		"repo=" + logfmtValue(outcome.repository),
+		fmt.Sprintf("files=%d", outcome.files),
+		fmt.Sprintf("tokens=%d", outcome.tokens),
+	}
+	if outcome.status == outcomeSkipped {
+		return strings.Join(fields, " ")
+	}
+	if outcome.err != nil {
+		message := strings.TrimPrefix(outcome.err.Error(), outcome.repository+": ")
+		fields = append(fields, "error="+logfmtValue(message))
+		if hint := sherpaerrors.HintOf(outcome.err); hint != "" {
+			fields = append(fields, "hint="+logfmtValue(hint))
+		}
+	} else {
+		fields = append(fields, "output="+logfmtValue(outcome.output))
+	}
+	return strings.Join(fields, " ")
+}
+
+// logfmtValue quotes values that are empty or contain spaces, quotes, equal signs or line breaks
+func logfmtValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,122 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/internal/config"
+	"sherpa/internal/fakevcs"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRepositorySummary(t *testing.T) {
+	t.Run("should total the tokens of included files", func(t *testing.T) {
+		result := &models.ProcessingResult{
+			TotalSize: 30,
+			Counts:    models.FileCounts{Included: 2},
+			Files: []models.FileInfo{
+				{Path: "main.go", Content: "package main\n\nfunc main() {}\n", Size: 28, IsText: true},
+				{Path: "logo.png", Size: 2, IsBinary: true},
+				{Path: "cmd", IsDir: true},
+			},
+		}
+
+		summary := NewRepositorySummary("owner/repo", models.PlatformGitHub, result)
+		assert.Equal(t, "owner/repo", summary.Repository)
+		assert.Equal(t, 2, summary.Files)
+		assert.Equal(t, int64(30), summary.Size)
+		assert.Positive(t, summary.Tokens)
+		require.Len(t, summary.LargestFiles, 2)
+		assert.Equal(t, "main.go", summary.LargestFiles[0].Path)
+		assert.Equal(t, summary.Tokens, summary.LargestFiles[0].Tokens)
+	})
+}
+
+func TestOrchestrator_RunSummary(t *testing.T) {
+	t.Run("should write the run summary to the output directory", func(t *testing.T) {
+		server := fakevcs.NewServer(fakevcs.DefaultFixtures())
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+		cfg.Output.Directory = filepath.Join(t.TempDir(), "output")
+		cfg.Output.LockFile = ""
+		cfg.Cache.Enabled = false
+
+		orchestrator := NewOrchestrator(cfg, &models.CLIOptions{Token: fakevcs.Token, Quiet: true})
+		err = orchestrator.ProcessRepositories(context.Background(), map[models.Platform][]*models.RepositoryInfo{
+			models.PlatformGitHub: {
+				{Platform: models.PlatformGitHub, Owner: "sherpa-fixtures", Name: "hello", FullName: "sherpa-fixtures/hello"},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, orchestrator.Err())
+
+		data, err := os.ReadFile(filepath.Join(cfg.Output.Directory, RunSummaryFile))
+		require.NoError(t, err)
+		var document runSummaryDocument
+		require.NoError(t, json.Unmarshal(data, &document))
+		assert.Equal(t, orchestrator.RunID(), document.RunID)
+		assert.Equal(t, 1, document.Succeeded)
+		require.Len(t, document.Results, 1)
+
+		result := document.Results[0]
+		assert.Equal(t, "sherpa-fixtures/hello", result.Repository)
+		assert.Equal(t, models.PlatformGitHub, result.Platform)
+		assert.Len(t, result.LargestFiles, result.Files)
+		histogramFiles := 0
+		for _, bucket := range result.TokenHistogram {
+			histogramFiles += bucket.Files
+		}
+		assert.Equal(t, result.Files, histogramFiles)
+	})
+}
+
+func TestFormatOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcome  repositoryOutcome
+		expected string
+	}{
+		{
+			name:     "should format a processed repository",
+			outcome:  repositoryOutcome{status: outcomeOK, platform: models.PlatformGitHub, repository: "owner/repo", files: 12, tokens: 3400, output: "sherpa-output/owner_repo"},
+			expected: "status=ok platform=github repo=owner/repo files=12 tokens=3400 output=sherpa-output/owner_repo",
+		},
+		{
+			name:     "should format an incomplete repository",
+			outcome:  repositoryOutcome{status: outcomeIncomplete, platform: models.PlatformGitLab, repository: "group/project", files: 5, tokens: 800, output: "out/group_project"},
+			expected: "status=incomplete platform=gitlab repo=group/project files=5 tokens=800 output=out/group_project",
+		},
+		{
+			name:     "should quote the error of a failed repository without repeating the repository",
+			outcome:  repositoryOutcome{status: outcomeFailed, platform: models.PlatformGitHub, repository: "owner/repo", err: errors.New(`owner/repo: failed to fetch "main"`)},
+			expected: `status=failed platform=github repo=owner/repo files=0 tokens=0 error="failed to fetch \"main\""`,
+		},
+		{
+			name:     "should format a skipped repository without output or error",
+			outcome:  repositoryOutcome{status: outcomeSkipped, platform: models.PlatformGitHub, repository: "owner/repo"},
+			expected: "status=skipped platform=github repo=owner/repo files=0 tokens=0",
+		},
+		{
+			name:     "should add the remediation hint of a failed repository",
+			outcome:  repositoryOutcome{status: outcomeFailed, platform: models.PlatformGitHub, repository: "owner/repo", err: sherpaerrors.WithHint(sherpaerrors.New(sherpaerrors.KindAuth, "401 Bad credentials"), "create a new token")},
+			expected: `status=failed platform=github repo=owner/repo files=0 tokens=0 error="401 Bad credentials" hint="create a new token"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatOutcome(tt.outcome))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package orchestration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrorThreshold is how many repositories of a run may fail or stay incomplete before the
+// run fails, as a count or as a share of the repositories
+type ErrorThreshold struct {
+	Count   int
+	Percent float64 // Share of the repositories out of 100, used instead of Count when set
+}
+
+// ParseErrorThreshold parses a threshold like "3" or "10%". An empty value tolerates no
+// failure.
+func ParseErrorThreshold(value string) (ErrorThreshold, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ErrorThreshold{}, nil
+	}
+
+	if number, isPercent := strings.CutSuffix(value, "%"); isPercent {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return ErrorThreshold{}, fmt.Errorf("invalid error threshold '%s': expected a percentage between 0%% and 100%%", value)
+		}
+		return ErrorThreshold{Percent: percent}, nil
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return ErrorThreshold{}, fmt.Errorf("invalid error threshold '%s': expected a number of repositories like 3 or a percentage like 10%%", value)
+	}
+	return ErrorThreshold{Count: count}, nil
+}
+
+// Exceeded reports whether failed out of total repositories is above the threshold
+func (t ErrorThreshold) Exceeded(failed, total int) bool {
+	if failed == 0 {
+		return false
+	}
+	if t.Percent > 0 {
+		return float64(failed)*100 > t.Percent*float64(total)
+	}
+	return failed > t.Count
+}
+
+// String renders the threshold as it is given on the command line
+func (t ErrorThreshold) String() string {
+	if t.Percent > 0 {
+		return strconv.FormatFloat(t.Percent, 'f', -1, 64) + "%"
+	}
+	return strconv.Itoa(t.Count)
+}
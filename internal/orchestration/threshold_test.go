@@ -0,0 +1,59 @@
+package orchestration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected ErrorThreshold
+		wantErr  bool
+	}{
+		{name: "should tolerate no failure by default", value: "", expected: ErrorThreshold{}},
+		{name: "should parse a count", value: "3", expected: ErrorThreshold{Count: 3}},
+		{name: "should parse a percentage", value: "10%", expected: ErrorThreshold{Percent: 10}},
+		{name: "should reject a negative count", value: "-1", wantErr: true},
+		{name: "should reject a value that is not a number", value: "abc", wantErr: true},
+		{name: "should reject a percentage above 100", value: "150%", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threshold, err := ParseErrorThreshold(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, threshold)
+		})
+	}
+}
+
+func TestErrorThreshold_Exceeded(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold ErrorThreshold
+		failed    int
+		total     int
+		expected  bool
+	}{
+		{name: "should not be exceeded without failures", threshold: ErrorThreshold{}, failed: 0, total: 10, expected: false},
+		{name: "should be exceeded by any failure by default", threshold: ErrorThreshold{}, failed: 1, total: 10, expected: true},
+		{name: "should tolerate failures up to the count", threshold: ErrorThreshold{Count: 2}, failed: 2, total: 10, expected: false},
+		{name: "should be exceeded above the count", threshold: ErrorThreshold{Count: 2}, failed: 3, total: 10, expected: true},
+		{name: "should tolerate failures up to the percentage", threshold: ErrorThreshold{Percent: 10}, failed: 1, total: 10, expected: false},
+		{name: "should be exceeded above the percentage", threshold: ErrorThreshold{Percent: 10}, failed: 2, total: 10, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.threshold.Exceeded(tt.failed, tt.total))
+		})
+	}
+}
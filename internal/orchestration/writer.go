@@ -0,0 +1,139 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// OutputFile is a generated file waiting to be written
+type OutputFile struct {
+	Path    string
+	Content string
+}
+
+// OutputWriter writes generated files through a bounded pool of workers
+type OutputWriter struct {
+	concurrency int
+	fsync       string
+}
+
+// NewOutputWriter creates a writer using at most concurrency workers and the given fsync policy
+func NewOutputWriter(concurrency int, fsync string) *OutputWriter {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if fsync == "" {
+		fsync = models.FsyncNone
+	}
+	return &OutputWriter{
+		concurrency: concurrency,
+		fsync:       fsync,
+	}
+}
+
+// WriteFiles writes all files concurrently and returns the combined errors of failed writes
+func (w *OutputWriter) WriteFiles(files []OutputFile) error {
+	semaphore := make(chan struct{}, w.concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+
+	for _, file := range files {
+		wg.Add(1)
+
+		go func(file OutputFile) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if err := w.writeFile(file); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(file)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// Persist directory entries once per directory rather than once per file
+	if w.fsync == models.FsyncFull {
+		for _, dir := range parentDirs(files) {
+			if err := syncDir(dir); err != nil {
+				return err
+			}
+		}
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"files":       len(files),
+		"concurrency": w.concurrency,
+		"fsync":       w.fsync,
+	}).Debug("Wrote output files")
+
+	return nil
+}
+
+// writeFile writes a single file, syncing it to disk when the policy requires it
+func (w *OutputWriter) writeFile(file OutputFile) error {
+	f, err := os.Create(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", file.Path, err)
+	}
+
+	if _, err := f.WriteString(file.Content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", file.Path, err)
+	}
+
+	if w.fsync != models.FsyncNone {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to sync %s: %w", file.Path, err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+// parentDirs returns the distinct parent directories of files
+func parentDirs(files []OutputFile) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, file := range files {
+		dir := filepath.Dir(file.Path)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// syncDir flushes a directory's entries to disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %s: %w", dir, err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package orchestration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOutputWriter(t *testing.T) {
+	t.Run("should apply defaults for invalid settings", func(t *testing.T) {
+		writer := NewOutputWriter(0, "")
+		assert.Equal(t, 1, writer.concurrency)
+		assert.Equal(t, models.FsyncNone, writer.fsync)
+	})
+}
+
+func TestOutputWriter_WriteFiles(t *testing.T) {
+	policies := []string{models.FsyncNone, models.FsyncFile, models.FsyncFull}
+
+	for _, policy := range policies {
+		t.Run(fmt.Sprintf("should write all files with fsync %s", policy), func(t *testing.T) {
+			dir := t.TempDir()
+			var files []OutputFile
+			for i := 0; i < 20; i++ {
+				files = append(files, OutputFile{
+					Path:    filepath.Join(dir, fmt.Sprintf("part%d.txt", i)),
+					Content: fmt.Sprintf("chunk %d", i),
+				})
+			}
+
+			writer := NewOutputWriter(4, policy)
+			require.NoError(t, writer.WriteFiles(files))
+
+			for i, file := range files {
+				content, err := os.ReadFile(file.Path)
+				require.NoError(t, err)
+				assert.Equal(t, fmt.Sprintf("chunk %d", i), string(content))
+			}
+		})
+	}
+
+	t.Run("should report every failed write", func(t *testing.T) {
+		dir := t.TempDir()
+		files := []OutputFile{
+			{Path: filepath.Join(dir, "missing", "a.txt"), Content: "a"},
+			{Path: filepath.Join(dir, "ok.txt"), Content: "ok"},
+			{Path: filepath.Join(dir, "missing", "b.txt"), Content: "b"},
+		}
+
+		writer := NewOutputWriter(2, models.FsyncNone)
+		err := writer.WriteFiles(files)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a.txt")
+		assert.Contains(t, err.Error(), "b.txt")
+		assert.FileExists(t, filepath.Join(dir, "ok.txt"))
+	})
+}
@@ -0,0 +1,214 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"sherpa/internal/adapters"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// ImageDescriber describes an image in one line, used as alt text for the images of docs
+type ImageDescriber interface {
+	DescribeImage(ctx context.Context, name, mediaType string, image []byte) (string, error)
+}
+
+// maxAltTextLength bounds the alt text written for an image, in characters
+const maxAltTextLength = 200
+
+// markdownImagePattern matches Markdown images without alt text, like ![](docs/flow.png),
+// capturing their target
+var markdownImagePattern = regexp.MustCompile(`!\[\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// htmlImagePattern matches HTML img tags
+var htmlImagePattern = regexp.MustCompile(`(?i)<img\s[^>]*>`)
+
+// htmlSourcePattern captures the src attribute of an HTML img tag
+var htmlSourcePattern = regexp.MustCompile(`(?i)\ssrc\s*=\s*["']([^"']+)["']`)
+
+// htmlAltPattern matches the alt attribute of an HTML img tag, empty ones marking
+// decorative images
+var htmlAltPattern = regexp.MustCompile(`(?i)\salt\s*=`)
+
+// imageMediaTypes maps the extensions of the images vision models accept to their media type
+var imageMediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// SetImageDescriber lets the images referenced by included Markdown files be described, for
+// their alt text, when alt text is included
+func (rp *RepoProcessor) SetImageDescriber(describer ImageDescriber) {
+	rp.describer = describer
+}
+
+// writeAltText writes alt text for the images without any referenced by the Markdown files of
+// files, reading them from the repository tree. Each image is described once, up to the
+// configured limit. It returns the number of images described and by how many bytes the
+// content of files grew, and stops at the first image that cannot be described.
+func (rp *RepoProcessor) writeAltText(ctx context.Context, repoPath, branch string, files []models.FileInfo, tree []models.RepositoryTree) (int, int64, error) {
+	var maxSize int64
+	if rp.config.AltText.MaxSize != "" {
+		size, err := utils.ParseSize(rp.config.AltText.MaxSize)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid alt text max_size: %w", err)
+		}
+		maxSize = size
+	}
+
+	blobs := make(map[string]models.RepositoryTree)
+	for _, entry := range tree {
+		if entry.Type == "blob" {
+			blobs[entry.Path] = entry
+		}
+	}
+
+	descriptions := make(map[string]string)
+	var firstErr error
+	describe := func(imagePath string) string {
+		if description, ok := descriptions[imagePath]; ok || firstErr != nil {
+			return description
+		}
+		if rp.config.AltText.Limit > 0 && len(descriptions) >= rp.config.AltText.Limit {
+			return ""
+		}
+		entry, ok := blobs[imagePath]
+		mediaType := imageMediaTypes[strings.ToLower(path.Ext(imagePath))]
+		if !ok || mediaType == "" || (maxSize > 0 && entry.Size > maxSize) {
+			return ""
+		}
+
+		image, err := rp.readImage(ctx, repoPath, imagePath, branch)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("image", imagePath).Debug("Could not read image, leaving it undescribed")
+			descriptions[imagePath] = ""
+			return ""
+		}
+		if maxSize > 0 && int64(len(image)) > maxSize {
+			descriptions[imagePath] = ""
+			return ""
+		}
+
+		description, err := rp.describer.DescribeImage(ctx, imagePath, mediaType, image)
+		if err != nil {
+			firstErr = err
+			return ""
+		}
+		descriptions[imagePath] = cleanAltText(description)
+		return descriptions[imagePath]
+	}
+
+	var grown int64
+	for i := range files {
+		file := &files[i]
+		if file.IsDir || file.Content == "" || !utils.IsMarkdownFile(file.Path) {
+			continue
+		}
+		content := addAltText(file.Content, func(target string) string {
+			imagePath, ok := resolveImage(file.Path, target)
+			if !ok {
+				return ""
+			}
+			return describe(imagePath)
+		})
+		grown += int64(len(content) - len(file.Content))
+		file.ContentSize += int64(len(content) - len(file.Content))
+		file.Content = content
+	}
+
+	described := 0
+	for _, description := range descriptions {
+		if description != "" {
+			described++
+		}
+	}
+	return described, grown, firstErr
+}
+
+// readImage reads an image as it is from the repository
+func (rp *RepoProcessor) readImage(ctx context.Context, repoPath, imagePath, branch string) ([]byte, error) {
+	if reader, ok := rp.provider.(adapters.RawFileReader); ok {
+		return reader.ReadRawFile(ctx, repoPath, imagePath, branch)
+	}
+	content, err := rp.provider.GetFileContent(ctx, repoPath, imagePath, branch)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// addAltText returns content with the alt text given by describe for the target of each
+// Markdown image and HTML img tag without any. Images describe returns no text for are left
+// unchanged.
+func addAltText(content string, describe func(target string) string) string {
+	content = markdownImagePattern.ReplaceAllStringFunc(content, func(image string) string {
+		alt := describe(markdownImagePattern.FindStringSubmatch(image)[1])
+		if alt == "" {
+			return image
+		}
+		return "![" + alt + "]" + strings.TrimPrefix(image, "![]")
+	})
+	return htmlImagePattern.ReplaceAllStringFunc(content, func(tag string) string {
+		source := htmlSourcePattern.FindStringSubmatch(tag)
+		if source == nil || htmlAltPattern.MatchString(tag) {
+			return tag
+		}
+		alt := describe(html.UnescapeString(source[1]))
+		if alt == "" {
+			return tag
+		}
+		return tag[:len("<img")] + ` alt="` + html.EscapeString(alt) + `"` + tag[len("<img"):]
+	})
+}
+
+// resolveImage returns the path in the repository of the image target referenced by the
+// Markdown file at filePath, relative to the file or, with a leading /, to the root. Remote
+// images are not resolved.
+func resolveImage(filePath, target string) (string, bool) {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "data:") {
+		return "", false
+	}
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	target, err := url.PathUnescape(target)
+	if err != nil || target == "" {
+		return "", false
+	}
+
+	imagePath := path.Join(path.Dir(filePath), target)
+	if strings.HasPrefix(target, "/") {
+		imagePath = path.Clean(strings.TrimPrefix(target, "/"))
+	}
+	if imagePath == ".." || strings.HasPrefix(imagePath, "../") {
+		return "", false
+	}
+	return imagePath, true
+}
+
+// cleanAltText turns the description of an image into one line of alt text, without the
+// brackets and quotes that would end it early
+func cleanAltText(description string) string {
+	description = strings.Map(func(r rune) rune {
+		switch r {
+		case '[', ']', '"':
+			return -1
+		}
+		return r
+	}, description)
+	description = strings.Join(strings.Fields(description), " ")
+	if runes := []rune(description); len(runes) > maxAltTextLength {
+		description = strings.TrimSpace(string(runes[:maxAltTextLength]))
+	}
+	return description
+}
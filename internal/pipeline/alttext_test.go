@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDescriber describes images with fixed descriptions, recording the images described
+type fakeDescriber struct {
+	descriptions map[string]string
+	described    []string
+	err          error
+}
+
+func (f *fakeDescriber) DescribeImage(ctx context.Context, name, mediaType string, image []byte) (string, error) {
+	f.described = append(f.described, name)
+	return f.descriptions[name], f.err
+}
+
+func TestAddAltText(t *testing.T) {
+	describe := func(target string) string {
+		if target == "flow.png" {
+			return "Payment flow"
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "should fill empty Markdown alt text", content: "See ![](flow.png \"Flow\").", expected: "See ![Payment flow](flow.png \"Flow\")."},
+		{name: "should keep existing Markdown alt text", content: "![Flow](flow.png)", expected: "![Flow](flow.png)"},
+		{name: "should add alt attributes to HTML images", content: `<img src="flow.png" width="400">`, expected: `<img alt="Payment flow" src="flow.png" width="400">`},
+		{name: "should keep HTML images with an alt attribute", content: `<img src="flow.png" alt="">`, expected: `<img src="flow.png" alt="">`},
+		{name: "should keep images left undescribed", content: "![](other.png)", expected: "![](other.png)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, addAltText(tt.content, describe))
+		})
+	}
+}
+
+func TestResolveImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		target   string
+		expected string
+		ok       bool
+	}{
+		{name: "should resolve targets relative to the file", filePath: "docs/guide.md", target: "../assets/flow.png", expected: "assets/flow.png", ok: true},
+		{name: "should resolve targets relative to the root", filePath: "docs/guide.md", target: "/assets/flow.png", expected: "assets/flow.png", ok: true},
+		{name: "should drop queries and decode escapes", filePath: "README.md", target: "img/my%20flow.png?raw=true", expected: "img/my flow.png", ok: true},
+		{name: "should not resolve remote images", filePath: "README.md", target: "https://example.com/flow.png", ok: false},
+		{name: "should not resolve targets outside the repository", filePath: "README.md", target: "../flow.png", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imagePath, ok := resolveImage(tt.filePath, tt.target)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, imagePath)
+		})
+	}
+}
+
+func TestRepoProcessor_writeAltText(t *testing.T) {
+	tree := []models.RepositoryTree{
+		{Path: "docs/guide.md", Type: "blob"},
+		{Path: "docs/flow.png", Type: "blob", Size: 10},
+		{Path: "docs/large.png", Type: "blob", Size: 4096},
+		{Path: "docs/logo.svg", Type: "blob", Size: 10},
+	}
+	files := func() []models.FileInfo {
+		content := "![](flow.png)\n![](flow.png)\n![](large.png)\n![](logo.svg)\n"
+		return []models.FileInfo{{Path: "docs/guide.md", Content: content, ContentSize: int64(len(content))}}
+	}
+
+	t.Run("should describe each image once", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		mockProvider.On("GetFileContent", mock.Anything, "owner/repo", "docs/flow.png", "main").Return("png", nil).Once()
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{AltText: models.AltTextConfig{Include: true, MaxSize: "1KB"}})
+		describer := &fakeDescriber{descriptions: map[string]string{"docs/flow.png": "Payment\nflow [v2]"}}
+		processor.SetImageDescriber(describer)
+
+		processed := files()
+		described, grown, err := processor.writeAltText(context.Background(), "owner/repo", "main", processed, tree)
+		require.NoError(t, err)
+		assert.Equal(t, 1, described)
+		assert.Equal(t, []string{"docs/flow.png"}, describer.described)
+		assert.Equal(t, "![Payment flow v2](flow.png)\n![Payment flow v2](flow.png)\n![](large.png)\n![](logo.svg)\n", processed[0].Content)
+		assert.Equal(t, int64(len(processed[0].Content)), processed[0].ContentSize)
+		assert.Equal(t, int64(2*len("Payment flow v2")), grown)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should stop at the first image that cannot be described", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		mockProvider.On("GetFileContent", mock.Anything, "owner/repo", "docs/flow.png", "main").Return("png", nil)
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{AltText: models.AltTextConfig{Include: true}})
+		processor.SetImageDescriber(&fakeDescriber{err: errors.New("connection refused")})
+
+		processed := files()
+		described, _, err := processor.writeAltText(context.Background(), "owner/repo", "main", processed, tree)
+		assert.ErrorContains(t, err, "connection refused")
+		assert.Zero(t, described)
+		assert.Equal(t, files()[0].Content, processed[0].Content)
+	})
+}
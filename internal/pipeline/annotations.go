@@ -0,0 +1,150 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Files repository owners can add to annotate paths in the generated context
+const (
+	AnnotationsFile = "llms-annotations.yml"
+	ContextFile     = "CONTEXT.md"
+)
+
+var (
+	// contextEntryPattern matches list items like "- `core/`: Domain logic" or "- core/ - Domain logic"
+	contextEntryPattern = regexp.MustCompile("^\\s*[-*]\\s+(?:`([^`]+)`|([^\\s:`]+))(?::\\s*|\\s+[-–—]\\s+)(.+)$")
+	// contextHeadingPattern matches markdown headings
+	contextHeadingPattern = regexp.MustCompile(`^#+\s+(.+)$`)
+)
+
+// annotationsDocument is the layout of llms-annotations.yml
+type annotationsDocument struct {
+	Annotations []models.Annotation `yaml:"annotations"`
+}
+
+// loadAnnotations fetches owner annotations from llms-annotations.yml and CONTEXT.md.
+// Entries from llms-annotations.yml take precedence when both describe the same path.
+func (rp *RepoProcessor) loadAnnotations(ctx context.Context, repoPath, branch string, tree []models.RepositoryTree) []models.Annotation {
+	var yamlAnnotations, markdownAnnotations []models.Annotation
+
+	for _, entry := range tree {
+		if entry.Type != "blob" || (entry.Path != AnnotationsFile && entry.Path != ContextFile) {
+			continue
+		}
+
+		content, err := rp.provider.GetFileContent(ctx, repoPath, entry.Path, branch)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(map[string]interface{}{
+				"repository": repoPath,
+				"file":       entry.Path,
+			}).Warn("Failed to fetch annotations file, ignoring it")
+			continue
+		}
+
+		if entry.Path == AnnotationsFile {
+			annotations, err := ParseAnnotations(content)
+			if err != nil {
+				logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Invalid annotations file, ignoring it")
+				continue
+			}
+			yamlAnnotations = annotations
+		} else {
+			markdownAnnotations = ParseContextMarkdown(content)
+		}
+	}
+
+	annotations := mergeAnnotations(yamlAnnotations, markdownAnnotations)
+	if len(annotations) > 0 {
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository":  repoPath,
+			"annotations": len(annotations),
+		}).Debug("Loaded owner annotations")
+	}
+	return annotations
+}
+
+// ParseAnnotations parses the content of an llms-annotations.yml file
+func ParseAnnotations(content string) ([]models.Annotation, error) {
+	var doc annotationsDocument
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", AnnotationsFile, err)
+	}
+
+	var annotations []models.Annotation
+	for _, annotation := range doc.Annotations {
+		annotation.Path = normalizeAnnotationPath(annotation.Path)
+		if annotation.Path != "" {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations, nil
+}
+
+// ParseContextMarkdown extracts path annotations from list items in a CONTEXT.md file.
+// Entries below a heading mentioning "read first" are marked as read-first.
+func ParseContextMarkdown(content string) []models.Annotation {
+	var annotations []models.Annotation
+	readFirst := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if heading := contextHeadingPattern.FindStringSubmatch(line); heading != nil {
+			title := strings.ToLower(heading[1])
+			readFirst = strings.Contains(title, "read first") || strings.Contains(title, "read this first")
+			continue
+		}
+
+		entry := contextEntryPattern.FindStringSubmatch(line)
+		if entry == nil {
+			continue
+		}
+
+		path := entry[1]
+		if path == "" {
+			path = entry[2]
+		}
+		path = normalizeAnnotationPath(path)
+		if path == "" {
+			continue
+		}
+
+		annotations = append(annotations, models.Annotation{
+			Path:        path,
+			Description: strings.TrimSpace(entry[3]),
+			ReadFirst:   readFirst,
+		})
+	}
+
+	return annotations
+}
+
+// mergeAnnotations combines annotation sets, keeping the first entry seen for each path
+func mergeAnnotations(sets ...[]models.Annotation) []models.Annotation {
+	seen := make(map[string]bool)
+	var merged []models.Annotation
+	for _, set := range sets {
+		for _, annotation := range set {
+			if seen[annotation.Path] {
+				continue
+			}
+			seen[annotation.Path] = true
+			merged = append(merged, annotation)
+		}
+	}
+	return merged
+}
+
+// normalizeAnnotationPath strips leading "./" and "/" and trailing "/" from a path
+func normalizeAnnotationPath(path string) string {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "./")
+	path = strings.Trim(path, "/")
+	return path
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	t.Run("should parse annotations and normalize paths", func(t *testing.T) {
+		content := `
+annotations:
+  - path: ./core/
+    description: Domain logic
+    read_first: true
+  - path: cmd/main.go
+    description: CLI entrypoint
+  - path: ""
+    description: ignored
+`
+		annotations, err := ParseAnnotations(content)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Annotation{
+			{Path: "core", Description: "Domain logic", ReadFirst: true},
+			{Path: "cmd/main.go", Description: "CLI entrypoint"},
+		}, annotations)
+	})
+
+	t.Run("should error on invalid YAML", func(t *testing.T) {
+		_, err := ParseAnnotations("annotations: [")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseContextMarkdown(t *testing.T) {
+	content := "# Context\n\n" +
+		"Some introduction text.\n\n" +
+		"## Read this first\n\n" +
+		"- `docs/ARCHITECTURE.md`: How the services fit together\n\n" +
+		"## Layout\n\n" +
+		"- core/ - Domain logic\n" +
+		"* `cmd/main.go` — CLI entrypoint\n" +
+		"- just a note without a path\n"
+
+	annotations := ParseContextMarkdown(content)
+
+	t.Run("should extract annotated list items", func(t *testing.T) {
+		assert.Equal(t, []models.Annotation{
+			{Path: "docs/ARCHITECTURE.md", Description: "How the services fit together", ReadFirst: true},
+			{Path: "core", Description: "Domain logic"},
+			{Path: "cmd/main.go", Description: "CLI entrypoint"},
+		}, annotations)
+	})
+}
+
+func TestMergeAnnotations(t *testing.T) {
+	t.Run("should keep the first entry for each path", func(t *testing.T) {
+		merged := mergeAnnotations(
+			[]models.Annotation{{Path: "core", Description: "from yaml"}},
+			[]models.Annotation{{Path: "core", Description: "from markdown"}, {Path: "cmd", Description: "commands"}},
+		)
+
+		assert.Equal(t, []models.Annotation{
+			{Path: "core", Description: "from yaml"},
+			{Path: "cmd", Description: "commands"},
+		}, merged)
+	})
+}
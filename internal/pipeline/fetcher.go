@@ -2,7 +2,10 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -11,14 +14,25 @@ import (
 	"time"
 
 	"sherpa/internal/adapters"
+	sherpaerrors "sherpa/pkg/errors"
 	"sherpa/pkg/logger"
 	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
 )
 
+// maxSuggestions is the number of filter suggestions attached to budget errors
+const maxSuggestions = 3
+
 // RepoProcessor handles repository processing logic
 type RepoProcessor struct {
-	provider adapters.Provider
-	config   models.ProcessingConfig
+	provider  adapters.Provider
+	config    models.ProcessingConfig
+	skipList  *SkipList      // Optional list of files to skip after repeated failures
+	reviewer  FileReviewer   // Optional user review of the files to fetch
+	subdir    string         // Optional path within the repository to restrict processing to
+	deadline  time.Time      // Optional time at which fetching stops, leaving the remaining files as stubs
+	clock     utils.Clock    // Time the deadline is checked against
+	describer ImageDescriber // Optional description of the images of docs, for their alt text
 }
 
 // NewRepoProcessor creates a new repository processor
@@ -26,11 +40,66 @@ func NewRepoProcessor(provider adapters.Provider, config models.ProcessingConfig
 	return &RepoProcessor{
 		provider: provider,
 		config:   config,
+		clock:    utils.SystemClock{},
+	}
+}
+
+// SetSkipList enables skipping files that failed consistently in previous runs
+func (rp *RepoProcessor) SetSkipList(skipList *SkipList) {
+	rp.skipList = skipList
+}
+
+// SetReviewer lets a user choose the files to fetch once the tree is filtered
+func (rp *RepoProcessor) SetReviewer(reviewer FileReviewer) {
+	rp.reviewer = reviewer
+}
+
+// SetDeadline stops fetching files at deadline. Files not fetched by then are kept as
+// stubs, so outputs can still be written from the files fetched in time.
+func (rp *RepoProcessor) SetDeadline(deadline time.Time) {
+	rp.deadline = deadline
+}
+
+// SetClock replaces the clock the deadline and rate limit times are read from
+func (rp *RepoProcessor) SetClock(clock utils.Clock) {
+	rp.clock = clock
+}
+
+// fetchContext returns the context of file fetches, cancelled at the deadline when set. The
+// time left is read from the clock, so the deadline holds whatever time the clock gives.
+func (rp *RepoProcessor) fetchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rp.deadline.IsZero() {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, rp.deadline.Sub(rp.clock.Now()))
+}
+
+// timedOut reports whether the deadline passed while ctx itself is still running
+func (rp *RepoProcessor) timedOut(ctx context.Context) bool {
+	return !rp.deadline.IsZero() && ctx.Err() == nil && !rp.clock.Now().Before(rp.deadline)
+}
+
+// WithSubdirectory returns a copy of the processor restricted to the files under subdir,
+// so one shared processor can serve repositories scoped to different paths
+func (rp *RepoProcessor) WithSubdirectory(subdir string) *RepoProcessor {
+	scoped := *rp
+	scoped.subdir = subdir
+	return &scoped
 }
 
 // ProcessRepository processes a complete repository
 func (rp *RepoProcessor) ProcessRepository(ctx context.Context, repoPath string, branch string) (*models.ProcessingResult, error) {
+	return rp.processRepository(ctx, repoPath, branch, nil)
+}
+
+// ProcessRepositoryIncremental processes a repository at commit, reusing the content of
+// files recorded in previous that did not change since its commit. Files are fetched as
+// usual when previous is nil or the changes cannot be listed.
+func (rp *RepoProcessor) ProcessRepositoryIncremental(ctx context.Context, repoPath, commit string, previous *RepoState) (*models.ProcessingResult, error) {
+	return rp.processRepository(ctx, repoPath, commit, previous)
+}
+
+func (rp *RepoProcessor) processRepository(ctx context.Context, repoPath string, branch string, previous *RepoState) (*models.ProcessingResult, error) {
 	logger.Logger.WithFields(map[string]interface{}{
 		"repository": repoPath,
 		"branch":     branch,
@@ -50,7 +119,16 @@ func (rp *RepoProcessor) ProcessRepository(ctx context.Context, repoPath string,
 		"repository": repoPath,
 		"branch":     branch,
 	}).Debug("Fetching repository tree")
-	tree, err := rp.provider.GetRepositoryTree(ctx, repoPath, branch)
+	var tree []models.RepositoryTree
+	var prefetched map[string]models.FileInfo
+	if rp.config.Match != "" {
+		tree, err = rp.getMatchingTree(ctx, repoPath, branch)
+	} else {
+		// Files are fetched while listing unless they may be reused, restricted to changes
+		// or a sample, or deselected by the user
+		prefetch := previous == nil && rp.config.Diff == "" && rp.config.Sample == "" && rp.reviewer == nil
+		tree, prefetched, err = rp.listTree(ctx, repoPath, branch, prefetch)
+	}
 
 	if err != nil {
 		logger.Logger.WithError(err).WithFields(map[string]interface{}{
@@ -60,21 +138,86 @@ func (rp *RepoProcessor) ProcessRepository(ctx context.Context, repoPath string,
 		return nil, fmt.Errorf("failed to get repository tree: %w", err)
 	}
 
+	// Keep only the requested subdirectory, while repository files like .sherpa.yml are
+	// still read from the full tree
+	fullTree := tree
+	if rp.subdir != "" {
+		tree = scopeTree(tree, rp.subdir)
+		if len(tree) == 0 {
+			return nil, sherpaerrors.New(sherpaerrors.KindNotFound, fmt.Sprintf("path %s not found in repository", rp.subdir))
+		}
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"path":       rp.subdir,
+			"entries":    len(tree),
+		}).Debug("Restricted tree to subdirectory")
+	}
+
+	// Keep only the files changed between the refs of --diff
+	var comparison *models.Comparison
+	if rp.config.Diff != "" {
+		comparison, err = rp.compareRefs(ctx, repoPath, branch)
+		if err != nil {
+			return nil, err
+		}
+		tree = changedTree(tree, comparison)
+	}
+
+	// Detect frameworks to describe the project and extend ignore presets
+	frameworks := DetectFrameworks(tree)
+	var extraIgnore []string
+	if len(frameworks) > 0 {
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"frameworks": frameworkNames(frameworks),
+		}).Debug("Detected frameworks")
+		if rp.config.FrameworkPresets {
+			extraIgnore = frameworkIgnorePatterns(frameworks)
+		}
+	}
+
+	// Let repository owners curate their context with their own .sherpa.yml and .sherpaignore
+	var repoConfig *models.RepoConfig
+	var repoIgnore []string
+	var annotations []models.Annotation
+	if rp.config.RepoConfig {
+		repoConfig = rp.loadRepoConfig(ctx, repoPath, branch, fullTree)
+		repoIgnore = rp.loadIgnoreFile(ctx, repoPath, branch, fullTree)
+		annotations = rp.loadAnnotations(ctx, repoPath, branch, fullTree)
+	}
+	if repoConfig == nil {
+		repoConfig = &models.RepoConfig{}
+	}
+	extraIgnore = append(extraIgnore, repoConfig.Ignore...)
+	extraIgnore = append(extraIgnore, repoIgnore...)
+
 	// Filter files based on ignore and include patterns
 	logger.Logger.WithFields(map[string]interface{}{
 		"repository":  repoPath,
 		"total_files": len(tree),
 	}).Debug("Filtering files based on ignore and include patterns")
-	filteredFiles := rp.filterFiles(tree)
+	filteredFiles := rp.filterFiles(tree, extraIgnore)
 	logger.Logger.WithFields(map[string]interface{}{
 		"repository":     repoPath,
 		"filtered_files": len(filteredFiles),
 		"original_files": len(tree),
 	}).Debug("Files filtered successfully")
 
+	// List the packages of excluded vendor directories, without their content
+	var vendored []models.VendoredDependencies
+	if rp.config.VendoredVersions {
+		vendored = rp.loadVendored(ctx, repoPath, branch, tree, filteredFiles)
+	}
+
 	var processedFiles []models.FileInfo
-	var totalSize int64
+	var totalSize, totalContentSize int64
 	var errors []error
+	var counts models.FileCounts
+	for _, denied := range rp.deniedPaths(extraIgnore) {
+		errors = append(errors, sherpaerrors.WithHint(
+			sherpaerrors.New(sherpaerrors.KindAuth, fmt.Sprintf("permission denied listing %s", denied)), PermissionHint))
+		counts.Denied++
+	}
 
 	// Separate files from directories
 	var fileEntries []models.RepositoryTree
@@ -87,12 +230,62 @@ func (rp *RepoProcessor) ProcessRepository(ctx context.Context, repoPath string,
 			fileEntries = append(fileEntries, entry)
 		}
 	}
+	for _, entry := range tree {
+		if entry.Type != "tree" {
+			counts.SkippedIgnored++
+		}
+	}
+	counts.SkippedIgnored -= len(fileEntries)
+
+	// Keep a reproducible share of the files for exploratory runs
+	var sample *models.Sample
+	if rp.config.Sample != "" {
+		percent, err := utils.ParsePercent(rp.config.Sample)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample: %w", err)
+		}
+		sampled := sampleFiles(fileEntries, percent, repoPath+"@"+branch)
+		sample = &models.Sample{Percent: percent, Files: len(sampled), Total: len(fileEntries)}
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"percent":    percent,
+			"sampled":    sample.Files,
+			"total":      sample.Total,
+		}).Debug("Sampled files")
+		counts.SkippedIgnored += len(fileEntries) - len(sampled)
+		fileEntries = sampled
+		directoryEntries = sampledDirectories(directoryEntries, sampled)
+	}
 
-	// Process files with concurrency control
-	maxConcurrency := rp.config.MaxConcurrency
-	if maxConcurrency <= 0 {
-		maxConcurrency = 20 // Default increased from 10 to 20 for better performance
+	// Let the user toggle files before any content is fetched
+	if rp.reviewer != nil && len(fileEntries) > 0 {
+		reviewed, err := rp.reviewer.Review(repoPath, fileEntries)
+		if err != nil {
+			return nil, err
+		}
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"selected":   len(reviewed),
+			"deselected": len(fileEntries) - len(reviewed),
+		}).Debug("Files reviewed")
+		counts.SkippedIgnored += len(fileEntries) - len(reviewed)
+		fileEntries = reviewed
 	}
+
+	// Guard against huge repositories before fetching any content
+	if err := rp.checkRepoSize(repoPath, fileEntries); err != nil {
+		return nil, err
+	}
+
+	if rp.config.MaxFiles > 0 && len(fileEntries) > rp.config.MaxFiles {
+		return nil, &BudgetExceededError{
+			Reason:      fmt.Sprintf("too many files to process safely: %d (max: %d); narrow it down with --ignore or --include-only patterns or raise --max-files", len(fileEntries), rp.config.MaxFiles),
+			Suggestions: SuggestFilters(fileEntries, false, maxSuggestions),
+		}
+	}
+
+	// Process files with concurrency control
+	maxConcurrency := rp.maxConcurrency()
 	logger.Logger.WithFields(map[string]interface{}{
 		"repository":      repoPath,
 		"file_count":      len(fileEntries),
@@ -100,43 +293,150 @@ func (rp *RepoProcessor) ProcessRepository(ctx context.Context, repoPath string,
 		"max_concurrency": maxConcurrency,
 	}).Debug("Processing files with concurrency control")
 
-	filePaths := make([]string, len(fileEntries))
-	for i, file := range fileEntries {
-		filePaths[i] = file.Path
+	// Sizes from tree metadata let large files be skipped before downloading them
+	var maxFileSize int64
+	if rp.config.MaxFileSize != "" {
+		if size, err := parseSize(rp.config.MaxFileSize); err == nil {
+			maxFileSize = size
+		}
 	}
 
-	files, err := rp.provider.GetMultipleFiles(ctx, repoPath, filePaths, branch, maxConcurrency, &rp.config)
-	if err != nil {
-		logger.Logger.WithError(err).WithField("repository", repoPath).Error("Failed to fetch files")
-		return nil, fmt.Errorf("failed to fetch files: %w", err)
+	filePaths := make([]string, 0, len(fileEntries))
+	skippedFiles := 0
+	for _, file := range fileEntries {
+		// Skip files that failed consistently in previous runs
+		if rp.skipList != nil && rp.skipList.ShouldSkip(repoPath, file.Path) {
+			skippedFiles++
+			counts.SkippedIgnored++
+			continue
+		}
+		if maxFileSize > 0 && file.Size > maxFileSize {
+			logger.Logger.WithField("file", file.Path).Trace("Skipping file because its tree size is too large")
+			counts.SkippedLarge++
+			continue
+		}
+		if rp.config.SkipBinary && hasBinaryExtension(file.Path, rp.config.BinaryExtensions) {
+			logger.Logger.WithField("file", file.Path).Trace("Skipping file because its extension is binary")
+			counts.SkippedBinary++
+			continue
+		}
+		filePaths = append(filePaths, file.Path)
 	}
 
+	if skippedFiles > 0 {
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository":    repoPath,
+			"skipped_files": skippedFiles,
+		}).Warn("Skipped files that failed repeatedly in previous runs; clear the skip list in the cache directory to retry them")
+	}
+
+	var files []models.FileInfo
+	fetchPaths := filePaths
+	if previous != nil {
+		files, fetchPaths = rp.reuseUnchanged(ctx, repoPath, branch, filePaths, previous)
+		counts.Reused = len(files)
+	}
+	if len(prefetched) > 0 {
+		var ahead []models.FileInfo
+		ahead, fetchPaths = takePrefetched(fetchPaths, prefetched)
+		files = append(files, ahead...)
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"prefetched": len(ahead),
+			"dropped":    len(prefetched) - len(ahead),
+		}).Debug("Reused files fetched while listing the tree")
+	}
+
+	if previous == nil || len(fetchPaths) > 0 {
+		fetchPaths = fetchOrder(fetchPaths, repoConfig.Priority, annotations)
+		fetchCtx, cancel := rp.fetchContext(ctx)
+		fetched, err := rp.provider.GetMultipleFiles(fetchCtx, repoPath, fetchPaths, branch, maxConcurrency, &rp.config)
+		cancel()
+		if err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Error("Failed to fetch files")
+			return nil, fmt.Errorf("failed to fetch files: %w", err)
+		}
+		files = append(files, fetched...)
+	}
+
+	// Restore the tree order mixed up by reusing files and fetching by priority
+	if len(files) > 1 {
+		order := make(map[string]int, len(filePaths))
+		for i, filePath := range filePaths {
+			order[filePath] = i
+		}
+		sort.SliceStable(files, func(i, j int) bool { return order[files[i].Path] < order[files[j].Path] })
+	}
+
+	// Timings cover every fetched file, including those dropped below for their size or errors
+	slowFiles := SlowestFiles(files, SlowFilesLimit)
+	slowDirectories := SlowestDirectories(files, SlowFilesLimit)
+
 	// Process each file
+	var rateLimitedAt time.Time
+	rateLimited := 0
 	for _, file := range files {
-		// Apply file size limit
-		if rp.config.MaxFileSize != "" {
-			maxSize, err := parseSize(rp.config.MaxFileSize)
-			if err == nil && file.Size > maxSize {
-				logger.Logger.WithField("file", file.Path).Debug("Skipping file because it's too large")
-				continue
+		// Files cut off by the time budget are kept as stubs, neither failed nor skipped
+		if file.Error != nil && rp.timedOut(ctx) {
+			processedFiles = append(processedFiles, models.FileInfo{Path: file.Path, Name: file.Name, NotFetched: true})
+			counts.NotFetched++
+			continue
+		}
+
+		// Files refused by the rate limit are fetched again once it resets, not skipped
+		if file.Error != nil && sherpaerrors.KindOf(file.Error) == sherpaerrors.KindRateLimited {
+			if rateLimited == 0 {
+				rateLimitedAt = rp.clock.Now()
+			}
+			rateLimited++
+			errors = append(errors, file.Error)
+			counts.Failed++
+			continue
+		}
+
+		// Files that cannot be read are reported on every run rather than remembered as failures
+		if file.Error != nil && permissionDenied(file.Error) {
+			logger.Logger.WithField("file", file.Path).Debug("Skipping file because it cannot be read")
+			errors = append(errors, sherpaerrors.WithHint(file.Error, PermissionHint))
+			counts.Denied++
+			continue
+		}
+
+		// Remember failures so persistent ones are skipped next time
+		if rp.skipList != nil {
+			if file.Error == nil {
+				rp.skipList.RecordSuccess(repoPath, file.Path)
+			} else if fileFailure(file.Error) {
+				rp.skipList.RecordFailure(repoPath, file.Path, file.Error)
 			}
 		}
 
+		// Apply file size limit
+		if maxFileSize > 0 && file.Size > maxFileSize {
+			logger.Logger.WithField("file", file.Path).Trace("Skipping file because it's too large")
+			counts.SkippedLarge++
+			continue
+		}
+
 		// Skip binary files if configured
 		if rp.config.SkipBinary && file.IsBinary {
-			logger.Logger.WithField("file", file.Path).Debug("Skipping binary file")
+			logger.Logger.WithField("file", file.Path).Trace("Skipping binary file")
+			counts.SkippedBinary++
 			continue
 		}
 
 		// Collect errors but continue processing
 		if file.Error != nil {
-			logger.Logger.WithField("file", file.Path).Debug("Skipping file because it has an error")
+			logger.Logger.WithField("file", file.Path).Trace("Skipping file because it has an error")
 			errors = append(errors, file.Error)
+			counts.Failed++
 			continue
 		}
 
 		processedFiles = append(processedFiles, file)
+		counts.Included++
 		totalSize += file.Size
+		totalContentSize += file.ContentSize
 	}
 
 	// Add directories as empty FileInfo entries for tree building
@@ -151,6 +451,74 @@ func (rp *RepoProcessor) ProcessRepository(ctx context.Context, repoPath string,
 		processedFiles = append(processedFiles, dirInfo)
 	}
 
+	var incomplete *models.Incomplete
+	if rateLimited > 0 || counts.NotFetched > 0 {
+		incomplete = &models.Incomplete{
+			RateLimitedAt: rateLimitedAt,
+			Fetched:       len(filePaths) - rateLimited - counts.NotFetched,
+			Total:         len(filePaths),
+		}
+		if counts.NotFetched > 0 {
+			incomplete.TimedOutAt = rp.deadline
+		}
+		fields := logger.Logger.WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"fetched":    incomplete.Fetched,
+			"total":      incomplete.Total,
+		})
+		if rateLimited > 0 {
+			fields.Warn("Rate limit reached, the output is incomplete")
+		}
+		if counts.NotFetched > 0 {
+			fields.Warn("Time budget ran out, the output is incomplete")
+		}
+	}
+
+	// Optional context is left out once the time budget ran out
+	optional := !rp.timedOut(ctx)
+
+	// Issues are optional context, so failing to list them does not fail the repository
+	var issues []models.Issue
+	if rp.config.Issues.Include && optional {
+		issues, err = rp.listIssues(ctx, repoPath)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Could not list issues")
+			errors = append(errors, err)
+		}
+	}
+
+	var releases []models.Release
+	if rp.config.Releases.Include && optional {
+		releases, err = rp.listReleases(ctx, repoPath)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Could not list releases")
+			errors = append(errors, err)
+		}
+	}
+
+	var wikiPages []models.WikiPage
+	if rp.config.Wiki && optional {
+		wikiPages, err = rp.listWikiPages(ctx, repoPath)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Could not read the wiki")
+			errors = append(errors, err)
+		}
+	}
+
+	// Images stay undescribed when the model cannot be reached, as alt text is optional too
+	if rp.config.AltText.Include && rp.describer != nil && optional {
+		described, grown, err := rp.writeAltText(ctx, repoPath, branch, processedFiles, fullTree)
+		totalContentSize += grown
+		if err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Could not write alt text for images")
+			errors = append(errors, err)
+		}
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"images":     described,
+		}).Debug("Wrote alt text for images")
+	}
+
 	duration := time.Since(startTime)
 
 	logger.Logger.WithFields(map[string]interface{}{
@@ -162,23 +530,339 @@ func (rp *RepoProcessor) ProcessRepository(ctx context.Context, repoPath string,
 	}).Info("Repository processing completed")
 
 	return &models.ProcessingResult{
-		Repository:  *repo,
-		Files:       processedFiles,
-		TotalFiles:  len(processedFiles),
-		TotalSize:   totalSize,
-		ProcessedAt: startTime,
-		Duration:    duration,
-		Errors:      errors,
+		Repository:       *repo,
+		Files:            processedFiles,
+		TotalFiles:       len(processedFiles),
+		TotalSize:        totalSize,
+		TotalContentSize: totalContentSize,
+		ProcessedAt:      startTime,
+		Duration:         duration,
+		Errors:           errors,
+		Frameworks:       frameworkNames(frameworks),
+		Priority:         repoConfig.Priority,
+		Summary:          repoConfig.Summary,
+		Annotations:      annotations,
+		Counts:           counts,
+		Incomplete:       incomplete,
+		Sample:           sample,
+		SlowFiles:        slowFiles,
+		SlowDirectories:  slowDirectories,
+		Comparison:       comparison,
+		Issues:           issues,
+		Releases:         releases,
+		WikiPages:        wikiPages,
+		Vendored:         vendored,
 	}, nil
 }
 
-// filterFiles applies ignore and include patterns to filter the file list
-func (rp *RepoProcessor) filterFiles(tree []models.RepositoryTree) []models.RepositoryTree {
+// maxConcurrency returns the number of files fetched concurrently
+func (rp *RepoProcessor) maxConcurrency() int {
+	if rp.config.MaxConcurrency <= 0 {
+		return 20 // Default increased from 10 to 20 for better performance
+	}
+	return rp.config.MaxConcurrency
+}
+
+// listIssues lists the issues of the repository selected by the issues configuration
+func (rp *RepoProcessor) listIssues(ctx context.Context, repoPath string) ([]models.Issue, error) {
+	lister, ok := rp.provider.(adapters.IssueLister)
+	if !ok {
+		return nil, fmt.Errorf("issues are not supported by this provider")
+	}
+	issues, err := lister.ListIssues(ctx, repoPath, rp.config.Issues)
+	if err != nil {
+		return nil, err
+	}
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"issues":     len(issues),
+	}).Debug("Listed issues")
+	return issues, nil
+}
+
+// listReleases lists the releases of the repository, up to the configured limit
+func (rp *RepoProcessor) listReleases(ctx context.Context, repoPath string) ([]models.Release, error) {
+	lister, ok := rp.provider.(adapters.ReleaseLister)
+	if !ok {
+		return nil, fmt.Errorf("releases are not supported by this provider")
+	}
+	releases, err := lister.ListReleases(ctx, repoPath, rp.config.Releases.Limit)
+	if err != nil {
+		return nil, err
+	}
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"releases":   len(releases),
+	}).Debug("Listed releases")
+	return releases, nil
+}
+
+// listWikiPages reads the pages of the repository wiki
+func (rp *RepoProcessor) listWikiPages(ctx context.Context, repoPath string) ([]models.WikiPage, error) {
+	reader, ok := rp.provider.(adapters.WikiReader)
+	if !ok {
+		return nil, fmt.Errorf("wikis are not supported by this provider")
+	}
+	pages, err := reader.ListWikiPages(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"wiki_pages": len(pages),
+	}).Debug("Read wiki pages")
+	return pages, nil
+}
+
+// reuseUnchanged splits filePaths into the files recorded in previous that did not change
+// since its commit, and the paths left to fetch. Every path is left to fetch when the
+// changes cannot be listed.
+func (rp *RepoProcessor) reuseUnchanged(ctx context.Context, repoPath, commit string, filePaths []string, previous *RepoState) ([]models.FileInfo, []string) {
+	if previous.Commit == "" || commit == "" {
+		return nil, filePaths
+	}
+
+	changed := make(map[string]bool)
+	if previous.Commit != commit {
+		lister, ok := rp.provider.(adapters.ChangeLister)
+		if !ok {
+			logger.Logger.WithField("repository", repoPath).Debug("Provider cannot list changed files, fetching all files")
+			return nil, filePaths
+		}
+		paths, err := lister.ChangedFiles(ctx, repoPath, previous.Commit, commit)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Could not list changed files, fetching all files")
+			return nil, filePaths
+		}
+		for _, filePath := range paths {
+			changed[filePath] = true
+		}
+	}
+
+	recorded := previous.files()
+	var reused []models.FileInfo
+	var fetchPaths []string
+	for _, filePath := range filePaths {
+		if file, exists := recorded[filePath]; exists && !changed[filePath] {
+			reused = append(reused, file)
+			continue
+		}
+		fetchPaths = append(fetchPaths, filePath)
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository":      repoPath,
+		"previous_commit": previous.Commit,
+		"commit":          commit,
+		"reused_files":    len(reused),
+		"fetched_files":   len(fetchPaths),
+	}).Info("Reusing files unchanged since the previous run")
+	return reused, fetchPaths
+}
+
+// ResolveCommit pins a ref to its commit SHA. It returns an empty string when
+// the provider has no notion of commits, such as local folders.
+func (rp *RepoProcessor) ResolveCommit(ctx context.Context, repoPath, ref string) (string, error) {
+	resolver, ok := rp.provider.(adapters.CommitResolver)
+	if !ok {
+		return "", nil
+	}
+	return resolver.ResolveCommit(ctx, repoPath, ref)
+}
+
+// compareRefs lists the files changed from the base of --diff to ref, the head being
+// processed, keeping only the files of the subdirectory when one is set
+func (rp *RepoProcessor) compareRefs(ctx context.Context, repoPath, ref string) (*models.Comparison, error) {
+	comparer, ok := rp.provider.(adapters.RefComparer)
+	if !ok {
+		return nil, fmt.Errorf("comparing refs is not supported by this provider")
+	}
+	base, head, err := models.ParseDiffRange(rp.config.Diff)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := comparer.CompareRefs(ctx, repoPath, base, ref)
+	if err != nil {
+		return nil, err
+	}
+	comparison.Head = head
+	if rp.subdir != "" {
+		var files []models.ChangedFile
+		for _, file := range comparison.Files {
+			if strings.HasPrefix(file.Path, rp.subdir+"/") || strings.HasPrefix(file.PreviousPath, rp.subdir+"/") {
+				files = append(files, file)
+			}
+		}
+		comparison.Files = files
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository":    repoPath,
+		"diff":          rp.config.Diff,
+		"commits":       comparison.Commits,
+		"changed_files": len(comparison.Files),
+	}).Info("Compared refs")
+	return comparison, nil
+}
+
+// changedTree returns the files of tree changed in comparison. Removed files are no longer
+// in the tree, so only their diff is kept.
+func changedTree(tree []models.RepositoryTree, comparison *models.Comparison) []models.RepositoryTree {
+	changed := make(map[string]bool, len(comparison.Files))
+	for _, file := range comparison.Files {
+		changed[file.Path] = true
+	}
+	var filtered []models.RepositoryTree
+	for _, entry := range tree {
+		if entry.Type != "tree" && changed[entry.Path] {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// scopeTree returns the entries of tree located under subdir
+func scopeTree(tree []models.RepositoryTree, subdir string) []models.RepositoryTree {
+	prefix := subdir + "/"
+	var scoped []models.RepositoryTree
+	for _, entry := range tree {
+		if strings.HasPrefix(entry.Path, prefix) || (entry.Path == subdir && entry.Type != "tree") {
+			scoped = append(scoped, entry)
+		}
+	}
+	return scoped
+}
+
+// getMatchingTree builds a tree restricted to files matching the code search query
+func (rp *RepoProcessor) getMatchingTree(ctx context.Context, repoPath, branch string) ([]models.RepositoryTree, error) {
+	searcher, ok := rp.provider.(adapters.CodeSearcher)
+	if !ok {
+		return nil, fmt.Errorf("code search is not supported by this provider")
+	}
+
+	paths, err := searcher.SearchCode(ctx, repoPath, rp.config.Match, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository":    repoPath,
+		"query":         rp.config.Match,
+		"matched_files": len(paths),
+	}).Info("Code search completed")
+
+	if !rp.config.MatchNeighbors {
+		tree := make([]models.RepositoryTree, 0, len(paths))
+		for _, p := range paths {
+			tree = append(tree, models.RepositoryTree{
+				ID:   p,
+				Name: path.Base(p),
+				Type: "blob",
+				Path: p,
+			})
+		}
+		return tree, nil
+	}
+
+	// Neighbors require the full listing to find sibling files
+	fullTree, err := rp.provider.GetRepositoryTree(ctx, repoPath, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	matchedDirs := make(map[string]bool)
+	for _, p := range paths {
+		matchedDirs[path.Dir(p)] = true
+	}
+
+	var tree []models.RepositoryTree
+	for _, entry := range fullTree {
+		if entry.Type == "blob" && matchedDirs[path.Dir(entry.Path)] {
+			tree = append(tree, entry)
+		}
+	}
+	return tree, nil
+}
+
+// checkRepoSize verifies that the summed tree sizes stay below the configured maximum
+func (rp *RepoProcessor) checkRepoSize(repoPath string, fileEntries []models.RepositoryTree) error {
+	if rp.config.MaxRepoSize == "" {
+		return nil
+	}
+
+	maxRepoSize, err := parseSize(rp.config.MaxRepoSize)
+	if err != nil {
+		return fmt.Errorf("invalid max repository size: %w", err)
+	}
+
+	var treeSize int64
+	for _, entry := range fileEntries {
+		treeSize += entry.Size
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository":    repoPath,
+		"tree_size":     formatBytes(treeSize),
+		"max_repo_size": formatBytes(maxRepoSize),
+	}).Debug("Checking repository size")
+
+	if treeSize > maxRepoSize {
+		return &BudgetExceededError{
+			Reason: fmt.Sprintf("repository size %s exceeds maximum of %s; narrow it down with --ignore or --include-only patterns (e.g. --ignore \"data/,*.csv\") or raise --max-repo-size",
+				formatBytes(treeSize), formatBytes(maxRepoSize)),
+			Suggestions: SuggestFilters(fileEntries, true, maxSuggestions),
+		}
+	}
+
+	return nil
+}
+
+// fileFailure reports whether err comes from the file itself, rather than from rejected
+// credentials or a cancellation stopping every fetch, including of files never requested
+func fileFailure(err error) bool {
+	return sherpaerrors.KindOf(err) != sherpaerrors.KindAuth &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// PermissionHint tells how to get the files of a folder Sherpa is not allowed to read
+const PermissionHint = "run Sherpa as a user allowed to read them, for example with sudo, or exclude them with --ignore"
+
+// permissionDenied reports whether err comes from a file the provider was not allowed to read
+func permissionDenied(err error) bool {
+	return errors.Is(err, fs.ErrPermission)
+}
+
+// deniedPaths returns the directories the provider skipped while listing the tree because
+// they could not be read, within the subdirectory and outside the ignored paths
+func (rp *RepoProcessor) deniedPaths(extraIgnore []string) []string {
+	reporter, ok := rp.provider.(adapters.PermissionReporter)
+	if !ok {
+		return nil
+	}
+	var denied []string
+	for _, path := range reporter.DeniedPaths() {
+		if rp.subdir != "" && path != rp.subdir && !strings.HasPrefix(path, rp.subdir+"/") {
+			continue
+		}
+		if utils.MatchDirPatterns(rp.config.Ignore, path) || utils.MatchDirPatterns(extraIgnore, path) {
+			continue
+		}
+		denied = append(denied, path)
+	}
+	return denied
+}
+
+// filterFiles applies ignore and include patterns to filter the file list.
+// extraIgnore holds repository-specific patterns applied on top of the configuration. They
+// are matched separately, so their negated patterns cannot re-include paths the
+// configuration or the organization policy ignore.
+func (rp *RepoProcessor) filterFiles(tree []models.RepositoryTree, extraIgnore []string) []models.RepositoryTree {
 	var filtered []models.RepositoryTree
 
 	for _, file := range tree {
 		// Apply ignore patterns
-		if rp.shouldIgnore(file.Path) {
+		if shouldIgnoreEntry(file, rp.config.Ignore) || shouldIgnoreEntry(file, extraIgnore) {
 			continue
 		}
 
@@ -201,32 +885,35 @@ func (rp *RepoProcessor) filterFiles(tree []models.RepositoryTree) []models.Repo
 	return filtered
 }
 
+// hasBinaryExtension reports whether the name of filePath ends with one of extensions,
+// ignoring case, so files known to be binary are skipped without fetching their content
+func hasBinaryExtension(filePath string, extensions []string) bool {
+	name := strings.ToLower(filepath.Base(filePath))
+	for _, ext := range extensions {
+		ext = "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+		if len(name) > len(ext) && strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
 // shouldIgnore checks if a file should be ignored based on ignore patterns
-func (rp *RepoProcessor) shouldIgnore(filePath string) bool {
-	if len(rp.config.Ignore) == 0 {
+func shouldIgnore(filePath string, patterns []string) bool {
+	if len(patterns) == 0 {
 		return false
 	}
 
-	for _, pattern := range rp.config.Ignore {
-		if matched, _ := filepath.Match(pattern, filepath.Base(filePath)); matched {
-			return true
-		}
-
-		// Check if pattern matches the full path
-		if matched, _ := filepath.Match(pattern, filePath); matched {
-			return true
-		}
+	return utils.MatchPatterns(patterns, filePath)
+}
 
-		// Check if it's a directory pattern
-		if strings.HasSuffix(pattern, "/") {
-			dirPattern := strings.TrimSuffix(pattern, "/")
-			if strings.Contains(filePath, dirPattern+"/") {
-				return true
-			}
-		}
+// shouldIgnoreEntry checks if a tree entry should be ignored, directory patterns like
+// node_modules/ also matching the entries of the directories themselves
+func shouldIgnoreEntry(entry models.RepositoryTree, patterns []string) bool {
+	if entry.Type == "tree" {
+		return len(patterns) > 0 && utils.MatchDirPatterns(patterns, entry.Path)
 	}
-
-	return false
+	return shouldIgnore(entry.Path, patterns)
 }
 
 // shouldInclude checks if a file should be included based on include-only patterns
@@ -235,18 +922,7 @@ func (rp *RepoProcessor) shouldInclude(filePath string) bool {
 		return true
 	}
 
-	for _, pattern := range rp.config.IncludeOnly {
-		if matched, _ := filepath.Match(pattern, filepath.Base(filePath)); matched {
-			return true
-		}
-
-		// Check if pattern matches the full path
-		if matched, _ := filepath.Match(pattern, filePath); matched {
-			return true
-		}
-	}
-
-	return false
+	return utils.MatchPatterns(rp.config.IncludeOnly, filePath)
 }
 
 // BuildProjectTree builds a hierarchical tree structure from flat file list
@@ -347,8 +1023,16 @@ func (rp *RepoProcessor) GetProcessingStats(result *models.ProcessingResult) map
 	stats["total_files"] = result.TotalFiles
 	stats["total_size"] = result.TotalSize
 	stats["total_size_human"] = formatBytes(result.TotalSize)
+	stats["total_content_size"] = result.TotalContentSize
+	stats["total_content_size_human"] = formatBytes(result.TotalContentSize)
 	stats["processing_duration"] = result.Duration.String()
 	stats["errors_count"] = len(result.Errors)
+	stats["included"] = result.Counts.Included
+	stats["skipped_binary"] = result.Counts.SkippedBinary
+	stats["skipped_large"] = result.Counts.SkippedLarge
+	stats["skipped_ignored"] = result.Counts.SkippedIgnored
+	stats["failed"] = result.Counts.Failed
+	stats["denied"] = result.Counts.Denied
 	stats["avg_file_size"] = int64(0)
 
 	if result.TotalFiles > 0 {
@@ -2,10 +2,16 @@ package pipeline
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
 	"testing"
 	"time"
 
+	sherpaerrors "sherpa/pkg/errors"
 	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -47,6 +53,71 @@ func (m *MockProvider) TestConnection(ctx context.Context) error {
 	return args.Error(0)
 }
 
+// MockPermissionProvider adds the reporting of unreadable directories to MockProvider
+type MockPermissionProvider struct {
+	MockProvider
+	denied []string
+}
+
+func (m *MockPermissionProvider) DeniedPaths() []string {
+	return m.denied
+}
+
+// MockSearchProvider adds code search support to MockProvider
+type MockSearchProvider struct {
+	MockProvider
+}
+
+func (m *MockSearchProvider) SearchCode(ctx context.Context, repoPath, query, branch string) ([]string, error) {
+	args := m.Called(ctx, repoPath, query, branch)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockChangeProvider adds changed file listing to MockProvider
+type MockChangeProvider struct {
+	MockProvider
+}
+
+func (m *MockChangeProvider) ChangedFiles(ctx context.Context, repoPath, base, head string) ([]string, error) {
+	args := m.Called(ctx, repoPath, base, head)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockCompareProvider adds ref comparison to MockProvider
+type MockCompareProvider struct {
+	MockProvider
+}
+
+func (m *MockCompareProvider) CompareRefs(ctx context.Context, repoPath, base, head string) (*models.Comparison, error) {
+	args := m.Called(ctx, repoPath, base, head)
+	return args.Get(0).(*models.Comparison), args.Error(1)
+}
+
+// MockIssueProvider adds issue listing to MockProvider
+type MockIssueProvider struct {
+	MockProvider
+}
+
+func (m *MockIssueProvider) ListIssues(ctx context.Context, repoPath string, config models.IssuesConfig) ([]models.Issue, error) {
+	args := m.Called(ctx, repoPath, config)
+	return args.Get(0).([]models.Issue), args.Error(1)
+}
+
+// MockDocsProvider adds release listing and wiki reading to MockProvider
+type MockDocsProvider struct {
+	MockProvider
+}
+
+func (m *MockDocsProvider) ListReleases(ctx context.Context, repoPath string, limit int) ([]models.Release, error) {
+	args := m.Called(ctx, repoPath, limit)
+	return args.Get(0).([]models.Release), args.Error(1)
+}
+
+func (m *MockDocsProvider) ListWikiPages(ctx context.Context, repoPath string) ([]models.WikiPage, error) {
+	args := m.Called(ctx, repoPath)
+	return args.Get(0).([]models.WikiPage), args.Error(1)
+}
+
 func TestNewRepoProcessor(t *testing.T) {
 	mockProvider := &MockProvider{}
 	config := models.ProcessingConfig{
@@ -119,7 +190,7 @@ func TestRepoProcessor_ProcessRepository(t *testing.T) {
 
 		mockProvider.On("GetRepository", mock.Anything, "owner/test-repo").Return(repo, nil)
 		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/test-repo", "main").Return(tree, nil)
-		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/test-repo", []string{"README.md", "src/main.go"}, "main", 5, mock.Anything).Return(files, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/test-repo", []string{"src/main.go", "README.md"}, "main", 5, mock.Anything).Return(files, nil)
 
 		result, err := processor.ProcessRepository(context.Background(), "owner/test-repo", "main")
 		require.NoError(t, err)
@@ -128,6 +199,7 @@ func TestRepoProcessor_ProcessRepository(t *testing.T) {
 		assert.Len(t, result.Files, 2) // app.log should be filtered out
 		assert.Equal(t, 2, result.TotalFiles)
 		assert.Equal(t, int64(28), result.TotalSize)
+		assert.Equal(t, models.FileCounts{Included: 2, SkippedIgnored: 1}, result.Counts)
 		assert.Greater(t, result.Duration, time.Duration(0))
 
 		mockProvider.AssertExpectations(t)
@@ -167,6 +239,196 @@ func TestRepoProcessor_ProcessRepository(t *testing.T) {
 		mockProvider.AssertExpectations(t)
 	})
 
+	t.Run("should process only the files of a subdirectory", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+		}
+		processor := NewRepoProcessor(mockProvider, config).WithSubdirectory("services/api")
+
+		repo := &models.Repository{
+			Name:              "monorepo",
+			PathWithNamespace: "owner/monorepo",
+		}
+
+		tree := []models.RepositoryTree{
+			{Path: "README.md", Name: "README.md", Type: "blob"},
+			{Path: "services", Name: "services", Type: "tree"},
+			{Path: "services/api", Name: "api", Type: "tree"},
+			{Path: "services/api/main.go", Name: "main.go", Type: "blob"},
+			{Path: "services/api-gateway/main.go", Name: "main.go", Type: "blob"},
+			{Path: "services/web/index.ts", Name: "index.ts", Type: "blob"},
+		}
+
+		files := []models.FileInfo{
+			{Path: "services/api/main.go", Name: "main.go", Content: "package main", Size: 12},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/monorepo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/monorepo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/monorepo", []string{"services/api/main.go"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/monorepo", "main")
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.TotalFiles)
+		assert.Equal(t, 0, result.Counts.SkippedIgnored)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should fail when the subdirectory does not exist", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{}).WithSubdirectory("missing")
+
+		repo := &models.Repository{
+			Name:              "monorepo",
+			PathWithNamespace: "owner/monorepo",
+		}
+		tree := []models.RepositoryTree{
+			{Path: "README.md", Name: "README.md", Type: "blob"},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/monorepo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/monorepo", "main").Return(tree, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/monorepo", "main")
+		assert.ErrorContains(t, err, "path missing not found")
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(err))
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should process only the files changed between the refs of the diff", func(t *testing.T) {
+		mockProvider := &MockCompareProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			Diff:           "v1.0.0..v1.1.0",
+		}
+		processor := NewRepoProcessor(mockProvider, config).WithSubdirectory("api")
+
+		repo := &models.Repository{
+			Name:              "service",
+			PathWithNamespace: "owner/service",
+		}
+		tree := []models.RepositoryTree{
+			{Path: "README.md", Name: "README.md", Type: "blob"},
+			{Path: "api", Name: "api", Type: "tree"},
+			{Path: "api/handler.go", Name: "handler.go", Type: "blob"},
+			{Path: "api/routes.go", Name: "routes.go", Type: "blob"},
+		}
+		comparison := &models.Comparison{
+			Base:    "v1.0.0",
+			Head:    "abc123",
+			Commits: 3,
+			Files: []models.ChangedFile{
+				{Path: "api/handler.go", Status: "modified", Patch: "@@ -1 +1 @@\n-a\n+b"},
+				{Path: "api/old.go", Status: "removed", Patch: "@@ -1 +0,0 @@\n-a"},
+				{Path: "README.md", Status: "modified", Patch: "@@ -1 +1 @@\n-a\n+b"},
+			},
+		}
+		files := []models.FileInfo{
+			{Path: "api/handler.go", Name: "handler.go", Content: "package api", Size: 11, IsText: true},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/service").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/service", "abc123").Return(tree, nil)
+		mockProvider.On("CompareRefs", mock.Anything, "owner/service", "v1.0.0", "abc123").Return(comparison, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/service", []string{"api/handler.go"}, "abc123", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/service", "abc123")
+		require.NoError(t, err)
+		assert.Len(t, result.Files, 1)
+		require.NotNil(t, result.Comparison)
+		assert.Equal(t, "v1.1.0", result.Comparison.Head)
+		assert.Len(t, result.Comparison.Files, 2)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should fail to diff with providers that cannot compare refs", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{Diff: "v1.0.0..v1.1.0"})
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/service").Return(&models.Repository{Name: "service"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/service", "v1.1.0").Return([]models.RepositoryTree{}, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/service", "v1.1.0")
+		assert.ErrorContains(t, err, "comparing refs is not supported")
+	})
+
+	t.Run("should include the issues of the repository", func(t *testing.T) {
+		mockProvider := &MockIssueProvider{}
+		issuesConfig := models.IssuesConfig{Include: true, State: models.IssueStateOpen, Labels: []string{"bug"}, Limit: 5}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2, Issues: issuesConfig})
+
+		issues := []models.Issue{{Number: 12, Title: "Uploads fail", State: models.IssueStateOpen}}
+		mockProvider.On("GetRepository", mock.Anything, "owner/service").Return(&models.Repository{Name: "service"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/service", "main").Return([]models.RepositoryTree{}, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/service", mock.Anything, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+		mockProvider.On("ListIssues", mock.Anything, "owner/service", issuesConfig).Return(issues, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/service", "main")
+		require.NoError(t, err)
+		assert.Equal(t, issues, result.Issues)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should record an error when issues cannot be listed", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2, Issues: models.IssuesConfig{Include: true}})
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/service").Return(&models.Repository{Name: "service"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/service", "main").Return([]models.RepositoryTree{}, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/service", mock.Anything, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/service", "main")
+		require.NoError(t, err)
+		assert.Empty(t, result.Issues)
+		require.Len(t, result.Errors, 1)
+		assert.ErrorContains(t, result.Errors[0], "issues are not supported")
+	})
+
+	t.Run("should include the releases and wiki pages of the repository", func(t *testing.T) {
+		mockProvider := &MockDocsProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{
+			MaxConcurrency: 2,
+			Releases:       models.ReleasesConfig{Include: true, Limit: 3},
+			Wiki:           true,
+		})
+
+		releases := []models.Release{{Name: "v1.2.0", Tag: "v1.2.0", Body: "Retries uploads"}}
+		pages := []models.WikiPage{{Title: "Home", Path: "Home.md", Content: "Welcome"}}
+		mockProvider.On("GetRepository", mock.Anything, "owner/service").Return(&models.Repository{Name: "service"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/service", "main").Return([]models.RepositoryTree{}, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/service", mock.Anything, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+		mockProvider.On("ListReleases", mock.Anything, "owner/service", 3).Return(releases, nil)
+		mockProvider.On("ListWikiPages", mock.Anything, "owner/service").Return(pages, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/service", "main")
+		require.NoError(t, err)
+		assert.Equal(t, releases, result.Releases)
+		assert.Equal(t, pages, result.WikiPages)
+		assert.Empty(t, result.Errors)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should record an error when the wiki cannot be read", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2, Wiki: true})
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/service").Return(&models.Repository{Name: "service"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/service", "main").Return([]models.RepositoryTree{}, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/service", mock.Anything, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/service", "main")
+		require.NoError(t, err)
+		assert.Empty(t, result.WikiPages)
+		require.Len(t, result.Errors, 1)
+		assert.ErrorContains(t, result.Errors[0], "wikis are not supported")
+	})
+
 	t.Run("should handle file fetch errors gracefully", func(t *testing.T) {
 		mockProvider := &MockProvider{}
 		config := models.ProcessingConfig{
@@ -210,4 +472,753 @@ func TestRepoProcessor_ProcessRepository(t *testing.T) {
 
 		mockProvider.AssertExpectations(t)
 	})
+
+	t.Run("should abort before fetching when repository exceeds max size", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			MaxRepoSize:    "1KB",
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		repo := &models.Repository{
+			Name:              "test-repo",
+			PathWithNamespace: "owner/test-repo",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "data.csv", Path: "data.csv", Type: "blob", Size: 4096},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/test-repo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/test-repo", "main").Return(tree, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/test-repo", "main")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds maximum of 1.0 KB")
+		assert.Contains(t, err.Error(), "--ignore")
+		assert.Equal(t, sherpaerrors.KindTooLarge, sherpaerrors.KindOf(err))
+
+		mockProvider.AssertExpectations(t)
+		mockProvider.AssertNotCalled(t, "GetMultipleFiles", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should apply framework ignore presets", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency:   2,
+			FrameworkPresets: true,
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		repo := &models.Repository{
+			Name:              "web-app",
+			PathWithNamespace: "owner/web-app",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "next.config.js", Path: "next.config.js", Type: "blob"},
+			{Name: "chunk.js", Path: ".next/static/chunk.js", Type: "blob"},
+		}
+
+		files := []models.FileInfo{
+			{Path: "next.config.js", Name: "next.config.js", Content: "module.exports = {}", Size: 19, IsText: true},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/web-app").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/web-app", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/web-app", []string{"next.config.js"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/web-app", "main")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Next.js"}, result.Frameworks)
+		assert.Len(t, result.Files, 1)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should fetch only files matching the search query", func(t *testing.T) {
+		mockProvider := &MockSearchProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			Match:          "payment AND retry",
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		repo := &models.Repository{
+			Name:              "billing",
+			PathWithNamespace: "owner/billing",
+		}
+
+		files := []models.FileInfo{
+			{Path: "pay/retry.go", Name: "retry.go", Content: "package pay", Size: 11, IsText: true},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/billing").Return(repo, nil)
+		mockProvider.On("SearchCode", mock.Anything, "owner/billing", "payment AND retry", "main").Return([]string{"pay/retry.go"}, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/billing", []string{"pay/retry.go"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/billing", "main")
+		require.NoError(t, err)
+		assert.Len(t, result.Files, 1)
+
+		mockProvider.AssertExpectations(t)
+		mockProvider.AssertNotCalled(t, "GetRepositoryTree", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should include neighbors of matching files", func(t *testing.T) {
+		mockProvider := &MockSearchProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			Match:          "retry",
+			MatchNeighbors: true,
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		repo := &models.Repository{
+			Name:              "billing",
+			PathWithNamespace: "owner/billing",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "pay", Path: "pay", Type: "tree"},
+			{Name: "retry.go", Path: "pay/retry.go", Type: "blob"},
+			{Name: "client.go", Path: "pay/client.go", Type: "blob"},
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/billing").Return(repo, nil)
+		mockProvider.On("SearchCode", mock.Anything, "owner/billing", "retry", "main").Return([]string{"pay/retry.go"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/billing", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/billing", []string{"pay/retry.go", "pay/client.go"}, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/billing", "main")
+		require.NoError(t, err)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should error when provider does not support search", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			Match:          "retry",
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		repo := &models.Repository{
+			Name:              "billing",
+			PathWithNamespace: "owner/billing",
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/billing").Return(repo, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/billing", "main")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "code search is not supported")
+	})
+
+	t.Run("should honor the repository .sherpa.yml", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			RepoConfig:     true,
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		repo := &models.Repository{
+			Name:              "billing",
+			PathWithNamespace: "owner/billing",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: ".sherpa.yml", Path: ".sherpa.yml", Type: "blob"},
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "data.json", Path: "fixtures/data.json", Type: "blob"},
+		}
+
+		repoConfig := "summary: Billing service\nignore: [fixtures/]\npriority: [main.go]\n"
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/billing").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/billing", "main").Return(tree, nil)
+		mockProvider.On("GetFileContent", mock.Anything, "owner/billing", ".sherpa.yml", "main").Return(repoConfig, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/billing", []string{"main.go", ".sherpa.yml"}, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/billing", "main")
+		require.NoError(t, err)
+		assert.Equal(t, "Billing service", result.Summary)
+		assert.Equal(t, []string{"main.go"}, result.Priority)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should not re-include files ignored by the configuration from the repository", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			RepoConfig:     true,
+			Ignore:         []string{"secrets/", ".sherpa.yml"},
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		tree := []models.RepositoryTree{
+			{Name: ".sherpa.yml", Path: ".sherpa.yml", Type: "blob"},
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "secrets", Path: "secrets", Type: "tree"},
+			{Name: "prod.env", Path: "secrets/prod.env", Type: "blob"},
+		}
+
+		repoConfig := "ignore: [\"!secrets/**\", \"!secrets/\"]\n"
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/billing").Return(&models.Repository{Name: "billing"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/billing", "main").Return(tree, nil)
+		mockProvider.On("GetFileContent", mock.Anything, "owner/billing", ".sherpa.yml", "main").Return(repoConfig, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/billing", []string{"main.go"}, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/billing", "main")
+		require.NoError(t, err)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should honor the repository .sherpaignore", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			RepoConfig:     true,
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		tree := []models.RepositoryTree{
+			{Name: ".sherpaignore", Path: ".sherpaignore", Type: "blob"},
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "fixtures", Path: "fixtures", Type: "tree"},
+			{Name: "data.json", Path: "fixtures/data.json", Type: "blob"},
+			{Name: "schema.json", Path: "fixtures/schema.json", Type: "blob"},
+		}
+
+		ignoreFile := "# Test data\nfixtures/\n!fixtures/schema.json\n\n/.sherpaignore\n"
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/billing").Return(&models.Repository{Name: "billing"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/billing", "main").Return(tree, nil)
+		mockProvider.On("GetFileContent", mock.Anything, "owner/billing", ".sherpaignore", "main").Return(ignoreFile, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/billing", []string{"main.go", "fixtures/schema.json"}, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/billing", "main")
+		require.NoError(t, err)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should not re-include files ignored by a policy from the .sherpaignore", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			RepoConfig:     true,
+			// Ignore rules added by the organization policy
+			Ignore: []string{"*.pem", "/.sherpaignore"},
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		tree := []models.RepositoryTree{
+			{Name: ".sherpaignore", Path: ".sherpaignore", Type: "blob"},
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "certs", Path: "certs", Type: "tree"},
+			{Name: "server.pem", Path: "certs/server.pem", Type: "blob"},
+			{Name: "ca.pem", Path: "certs/ca.pem", Type: "blob"},
+		}
+
+		ignoreFile := "certs/\n!certs/**\n!*.pem\n"
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/billing").Return(&models.Repository{Name: "billing"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/billing", "main").Return(tree, nil)
+		mockProvider.On("GetFileContent", mock.Anything, "owner/billing", ".sherpaignore", "main").Return(ignoreFile, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/billing", []string{"main.go"}, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/billing", "main")
+		require.NoError(t, err)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should skip files that failed in previous runs", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		skipList, err := LoadSkipList(t.TempDir(), 1, 0)
+		require.NoError(t, err)
+		skipList.RecordFailure("owner/repo", "assets/model.bin", fmt.Errorf("403 Forbidden"))
+		processor.SetSkipList(skipList)
+
+		repo := &models.Repository{
+			Name:              "repo",
+			PathWithNamespace: "owner/repo",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "model.bin", Path: "assets/model.bin", Type: "blob"},
+			{Name: "broken.go", Path: "broken.go", Type: "blob"},
+		}
+
+		files := []models.FileInfo{
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+			{Path: "broken.go", Name: "broken.go", Error: fmt.Errorf("500 Internal Server Error")},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"main.go", "broken.go"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		assert.Len(t, result.Errors, 1)
+		assert.Equal(t, models.FileCounts{Included: 1, SkippedIgnored: 1, Failed: 1}, result.Counts)
+		assert.True(t, skipList.ShouldSkip("owner/repo", "broken.go"))
+		assert.False(t, skipList.ShouldSkip("owner/repo", "main.go"))
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should not remember failures stopping every fetch", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2})
+
+		skipList, err := LoadSkipList(t.TempDir(), 1, 0)
+		require.NoError(t, err)
+		processor.SetSkipList(skipList)
+
+		tree := []models.RepositoryTree{
+			{Name: "a.go", Path: "a.go", Type: "blob"},
+			{Name: "b.go", Path: "b.go", Type: "blob"},
+			{Name: "c.go", Path: "c.go", Type: "blob"},
+		}
+		auth := sherpaerrors.New(sherpaerrors.KindAuth, "401 Unauthorized")
+		files := []models.FileInfo{
+			{Path: "a.go", Name: "a.go", Error: auth},
+			// Left unfetched after the credentials were rejected
+			{Path: "b.go", Name: "b.go", Error: auth},
+			{Path: "c.go", Name: "c.go", Error: fmt.Errorf("failed to get file content: %w", context.Canceled)},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(&models.Repository{Name: "repo"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"a.go", "b.go", "c.go"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Counts.Failed)
+		for _, path := range []string{"a.go", "b.go", "c.go"} {
+			assert.False(t, skipList.ShouldSkip("owner/repo", path), path)
+		}
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should mark the result incomplete when rate limited", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		skipList, err := LoadSkipList(t.TempDir(), 1, 0)
+		require.NoError(t, err)
+		processor.SetSkipList(skipList)
+
+		repo := &models.Repository{
+			Name:              "repo",
+			PathWithNamespace: "owner/repo",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "util.go", Path: "util.go", Type: "blob"},
+			{Name: "api.go", Path: "api.go", Type: "blob"},
+		}
+
+		files := []models.FileInfo{
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+			{Path: "util.go", Name: "util.go", Error: sherpaerrors.Wrap(sherpaerrors.KindRateLimited, fmt.Errorf("403 API rate limit exceeded"))},
+			{Path: "api.go", Name: "api.go", Error: sherpaerrors.Wrap(sherpaerrors.KindRateLimited, fmt.Errorf("403 API rate limit exceeded"))},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"main.go", "util.go", "api.go"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		require.NotNil(t, result.Incomplete)
+		assert.Equal(t, 1, result.Incomplete.Fetched)
+		assert.Equal(t, 3, result.Incomplete.Total)
+		assert.False(t, result.Incomplete.RateLimitedAt.IsZero())
+		assert.Equal(t, models.FileCounts{Included: 1, Failed: 2}, result.Counts)
+		assert.False(t, skipList.ShouldSkip("owner/repo", "util.go"), "rate limited files should be fetched again")
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should fetch only a sample of the files", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			Sample:         "50%",
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		repo := &models.Repository{
+			Name:              "repo",
+			PathWithNamespace: "owner/repo",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "a.go", Path: "a.go", Type: "blob"},
+			{Name: "b.go", Path: "b.go", Type: "blob"},
+			{Name: "c.go", Path: "c.go", Type: "blob"},
+			{Name: "d.go", Path: "d.go", Type: "blob"},
+		}
+		sampled := paths(sampleFiles(tree, 50, "owner/repo@main"))
+		var files []models.FileInfo
+		for _, filePath := range sampled {
+			files = append(files, models.FileInfo{Path: filePath, Name: filePath, Content: "package main", Size: 12, IsText: true})
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", sampled, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		assert.Equal(t, &models.Sample{Percent: 50, Files: 2, Total: 4}, result.Sample)
+		assert.Equal(t, models.FileCounts{Included: 2, SkippedIgnored: 2}, result.Counts)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should keep files not fetched before the deadline as stubs", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			Issues:         models.IssuesConfig{Include: true},
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+		deadline := time.Now().Add(-time.Second)
+		processor.SetDeadline(deadline)
+
+		skipList, err := LoadSkipList(t.TempDir(), 1, 0)
+		require.NoError(t, err)
+		processor.SetSkipList(skipList)
+
+		repo := &models.Repository{
+			Name:              "repo",
+			PathWithNamespace: "owner/repo",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "util.go", Path: "util.go", Type: "blob"},
+		}
+
+		files := []models.FileInfo{
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+			{Path: "util.go", Name: "util.go", Error: fmt.Errorf("failed to get file util.go: %w", context.DeadlineExceeded)},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"main.go", "util.go"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		require.NotNil(t, result.Incomplete)
+		assert.Equal(t, 1, result.Incomplete.Fetched)
+		assert.Equal(t, 2, result.Incomplete.Total)
+		assert.Equal(t, deadline, result.Incomplete.TimedOutAt)
+		assert.True(t, result.Incomplete.RateLimitedAt.IsZero())
+		assert.Equal(t, models.FileCounts{Included: 1, NotFetched: 1}, result.Counts)
+		assert.Contains(t, result.Files, models.FileInfo{Path: "util.go", Name: "util.go", NotFetched: true})
+		assert.Empty(t, result.Errors, "issues should not be listed once the time budget ran out")
+		assert.False(t, skipList.ShouldSkip("owner/repo", "util.go"), "files not fetched in time should be fetched again")
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should check the deadline against the clock", func(t *testing.T) {
+		tree := []models.RepositoryTree{{Name: "main.go", Path: "main.go", Type: "blob"}}
+		files := []models.FileInfo{{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true}}
+		// A deadline passed on the wall clock, but not on the processor's clock, and the reverse
+		past := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		future := time.Now().Add(time.Hour)
+
+		tests := []struct {
+			name     string
+			deadline time.Time
+			now      time.Time
+			timedOut bool
+		}{
+			{name: "before the deadline", deadline: past, now: past.Add(-time.Minute), timedOut: false},
+			{name: "after the deadline", deadline: future, now: future.Add(time.Minute), timedOut: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockProvider := &MockProvider{}
+				processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2})
+				processor.SetDeadline(tt.deadline)
+				processor.SetClock(utils.FixedClock{Time: tt.now})
+
+				var fetchErr error
+				mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(&models.Repository{Name: "repo", PathWithNamespace: "owner/repo"}, nil)
+				mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+				mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"main.go"}, "main", 2, mock.Anything).
+					Run(func(args mock.Arguments) { fetchErr = args.Get(0).(context.Context).Err() }).
+					Return(files, nil)
+
+				result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+				require.NoError(t, err)
+				if tt.timedOut {
+					assert.ErrorIs(t, fetchErr, context.DeadlineExceeded)
+				} else {
+					assert.NoError(t, fetchErr)
+					assert.Nil(t, result.Incomplete)
+				}
+			})
+		}
+	})
+
+	t.Run("should skip large files from tree sizes before fetching them", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			MaxFileSize:    "1KB",
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		repo := &models.Repository{
+			Name:              "repo",
+			PathWithNamespace: "owner/repo",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "main.go", Path: "main.go", Type: "blob", Size: 14},
+			{Name: "dump.sql", Path: "dump.sql", Type: "blob", Size: 4096},
+		}
+
+		// Raw sizes come from metadata and can differ from the decoded content
+		files := []models.FileInfo{
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 14, ContentSize: 12, IsText: true},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"main.go"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		assert.Equal(t, int64(14), result.TotalSize)
+		assert.Equal(t, int64(12), result.TotalContentSize)
+		assert.Equal(t, models.FileCounts{Included: 1, SkippedLarge: 1}, result.Counts)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should skip files with binary extensions before fetching them", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency:   2,
+			SkipBinary:       true,
+			BinaryExtensions: []string{".png", "woff2"},
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		tree := []models.RepositoryTree{
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "logo.PNG", Path: "assets/logo.PNG", Type: "blob"},
+			{Name: "font.woff2", Path: "assets/font.woff2", Type: "blob"},
+		}
+		files := []models.FileInfo{
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(&models.Repository{Name: "repo"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"main.go"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		assert.Equal(t, models.FileCounts{Included: 1, SkippedBinary: 2}, result.Counts)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should report the files and directories that cannot be read", func(t *testing.T) {
+		mockProvider := &MockPermissionProvider{denied: []string{"secrets", "node_modules/.cache"}}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+			Ignore:         []string{"node_modules/"},
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+
+		tree := []models.RepositoryTree{
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "app.conf", Path: "app.conf", Type: "blob"},
+		}
+		files := []models.FileInfo{
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+			{Path: "app.conf", Name: "app.conf", Error: fmt.Errorf("failed to read file: %w", fs.ErrPermission)},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(&models.Repository{Name: "repo"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"main.go", "app.conf"}, "main", 2, mock.Anything).Return(files, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+		assert.Equal(t, models.FileCounts{Included: 1, Denied: 2}, result.Counts)
+		require.Len(t, result.Errors, 2)
+		assert.ErrorContains(t, result.Errors[0], "permission denied listing secrets")
+		assert.ErrorIs(t, result.Errors[1], fs.ErrPermission)
+		assert.Contains(t, sherpaerrors.HintOf(result.Errors[1]), "sudo")
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should fetch only the files kept during review", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		config := models.ProcessingConfig{
+			MaxConcurrency: 2,
+		}
+		processor := NewRepoProcessor(mockProvider, config)
+		processor.SetReviewer(NewInteractiveReviewer(strings.NewReader("2\n\n"), io.Discard))
+
+		repo := &models.Repository{
+			Name:              "repo",
+			PathWithNamespace: "owner/repo",
+		}
+
+		tree := []models.RepositoryTree{
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+			{Name: "secrets.go", Path: "internal/secrets.go", Type: "blob"},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/repo", []string{"main.go"}, "main", 2, mock.Anything).Return([]models.FileInfo{}, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		require.NoError(t, err)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should fail when the review is aborted", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2})
+		processor.SetReviewer(NewInteractiveReviewer(strings.NewReader("q\n"), io.Discard))
+
+		tree := []models.RepositoryTree{
+			{Name: "main.go", Path: "main.go", Type: "blob"},
+		}
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/repo").Return(&models.Repository{Name: "repo"}, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/repo", "main").Return(tree, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "owner/repo", "main")
+		assert.ErrorIs(t, err, ErrReviewAborted)
+	})
+}
+
+func TestRepoProcessor_ProcessRepositoryIncremental(t *testing.T) {
+	repo := &models.Repository{Name: "api", PathWithNamespace: "owner/api"}
+	tree := []models.RepositoryTree{
+		{Name: "README.md", Path: "README.md", Type: "blob"},
+		{Name: "main.go", Path: "main.go", Type: "blob"},
+		{Name: "new.go", Path: "new.go", Type: "blob"},
+	}
+	previous := &RepoState{Version: StateVersion, Commit: "old", Files: []StateFile{
+		{Path: "README.md", Content: "# API", Size: 5, ContentSize: 5},
+		{Path: "main.go", Content: "package old", Size: 11, ContentSize: 11},
+		{Path: "removed.go", Content: "package gone", Size: 12, ContentSize: 12},
+	}}
+
+	t.Run("should fetch only files changed since the previous commit", func(t *testing.T) {
+		mockProvider := &MockChangeProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2})
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/api").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/api", "new").Return(tree, nil)
+		mockProvider.On("ChangedFiles", mock.Anything, "owner/api", "old", "new").Return([]string{"main.go", "new.go", "removed.go"}, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/api", []string{"main.go", "new.go"}, "new", 2, mock.Anything).Return([]models.FileInfo{
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+			{Path: "new.go", Name: "new.go", Content: "package main", Size: 12, IsText: true},
+		}, nil)
+
+		result, err := processor.ProcessRepositoryIncremental(context.Background(), "owner/api", "new", previous)
+		require.NoError(t, err)
+
+		require.Len(t, result.Files, 3)
+		assert.Equal(t, "README.md", result.Files[0].Path)
+		assert.Equal(t, "# API", result.Files[0].Content)
+		assert.Equal(t, "package main", result.Files[1].Content)
+		assert.Equal(t, 3, result.Counts.Included)
+		assert.Equal(t, 1, result.Counts.Reused)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should reuse every file at the same commit", func(t *testing.T) {
+		mockProvider := &MockChangeProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2})
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/api").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/api", "old").Return(tree[:2], nil)
+
+		result, err := processor.ProcessRepositoryIncremental(context.Background(), "owner/api", "old", previous)
+		require.NoError(t, err)
+
+		assert.Len(t, result.Files, 2)
+		assert.Equal(t, 2, result.Counts.Reused)
+		mockProvider.AssertNotCalled(t, "ChangedFiles", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockProvider.AssertNotCalled(t, "GetMultipleFiles", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should fetch every file when changes cannot be listed", func(t *testing.T) {
+		mockProvider := &MockChangeProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2})
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/api").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/api", "new").Return(tree[:2], nil)
+		mockProvider.On("ChangedFiles", mock.Anything, "owner/api", "old", "new").Return([]string(nil), fmt.Errorf("commit new does not descend from old"))
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/api", []string{"main.go", "README.md"}, "new", 2, mock.Anything).Return([]models.FileInfo{
+			{Path: "README.md", Name: "README.md", Content: "# API v2", Size: 8, IsText: true},
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, IsText: true},
+		}, nil)
+
+		result, err := processor.ProcessRepositoryIncremental(context.Background(), "owner/api", "new", previous)
+		require.NoError(t, err)
+
+		assert.Equal(t, "# API v2", result.Files[0].Content)
+		assert.Equal(t, 0, result.Counts.Reused)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should fetch every file without a previous state", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2})
+
+		mockProvider.On("GetRepository", mock.Anything, "owner/api").Return(repo, nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "owner/api", "new").Return(tree[:1], nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "owner/api", []string{"README.md"}, "new", 2, mock.Anything).Return([]models.FileInfo{
+			{Path: "README.md", Name: "README.md", Content: "# API", Size: 5, IsText: true},
+		}, nil)
+
+		result, err := processor.ProcessRepositoryIncremental(context.Background(), "owner/api", "new", nil)
+		require.NoError(t, err)
+
+		assert.Len(t, result.Files, 1)
+		mockProvider.AssertExpectations(t)
+	})
 }
@@ -23,7 +23,10 @@ func (ff *FileFilter) FilterFiles(tree []models.RepositoryTree) []models.Reposit
 
 	for _, file := range tree {
 		// Apply ignore patterns
-		if ff.patternMatcher.ShouldIgnore(file.Path) {
+		if file.Type == "tree" && ff.patternMatcher.ShouldIgnoreDir(file.Path) {
+			continue
+		}
+		if file.Type != "tree" && ff.patternMatcher.ShouldIgnore(file.Path) {
 			continue
 		}
 
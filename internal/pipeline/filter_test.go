@@ -132,6 +132,41 @@ func TestFileFilter_PatternMatching(t *testing.T) {
 			fileType:         "blob",
 			shouldBeFiltered: true,
 		},
+		{
+			name:             "should include nested files with a double star",
+			includePatterns:  []string{"src/**"},
+			filePath:         "src/api/v1/server.go",
+			fileType:         "blob",
+			shouldBeFiltered: false,
+		},
+		{
+			name:             "should filter out files excluded by a negated pattern",
+			includePatterns:  []string{"src/**", "!src/**/testdata/**"},
+			filePath:         "src/parser/testdata/input.go",
+			fileType:         "blob",
+			shouldBeFiltered: true,
+		},
+		{
+			name:             "should keep files re-included by a negated ignore pattern",
+			ignorePatterns:   []string{"*.md", "!README.md"},
+			filePath:         "docs/README.md",
+			fileType:         "blob",
+			shouldBeFiltered: false,
+		},
+		{
+			name:             "should filter out directories matching a directory pattern",
+			ignorePatterns:   []string{"node_modules/"},
+			filePath:         "web/node_modules",
+			fileType:         "tree",
+			shouldBeFiltered: true,
+		},
+		{
+			name:             "should keep files matching a directory pattern",
+			ignorePatterns:   []string{"build/"},
+			filePath:         "scripts/build",
+			fileType:         "blob",
+			shouldBeFiltered: false,
+		},
 	}
 
 	for _, tt := range tests {
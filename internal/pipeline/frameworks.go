@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"path"
+	"strings"
+
+	"sherpa/pkg/models"
+)
+
+// Framework describes a detected framework and the ignore patterns it implies
+type Framework struct {
+	Name   string
+	Ignore []string
+}
+
+// frameworkRule detects a framework from the set of file paths in a repository
+type frameworkRule struct {
+	framework Framework
+	detect    func(paths map[string]bool, names map[string]bool, exts map[string]bool) bool
+}
+
+// frameworkRules lists the supported frameworks in display order
+var frameworkRules = []frameworkRule{
+	{
+		framework: Framework{Name: "Django", Ignore: []string{"__pycache__/", "*.pyc", "staticfiles/", "media/"}},
+		detect: func(paths, names, exts map[string]bool) bool {
+			return names["manage.py"] && (names["settings.py"] || names["wsgi.py"])
+		},
+	},
+	{
+		framework: Framework{Name: "Rails", Ignore: []string{"tmp/", "log/", "public/assets/", "public/packs/"}},
+		detect: func(paths, names, exts map[string]bool) bool {
+			return paths["Gemfile"] && paths["config/routes.rb"]
+		},
+	},
+	{
+		framework: Framework{Name: "Spring", Ignore: []string{"target/", "build/", ".gradle/"}},
+		detect: func(paths, names, exts map[string]bool) bool {
+			hasBuild := names["pom.xml"] || names["build.gradle"] || names["build.gradle.kts"]
+			hasSpringConfig := names["application.properties"] || names["application.yml"] || names["application.yaml"]
+			return hasBuild && hasSpringConfig
+		},
+	},
+	{
+		framework: Framework{Name: "Next.js", Ignore: []string{".next/", "out/"}},
+		detect: func(paths, names, exts map[string]bool) bool {
+			return names["next.config.js"] || names["next.config.mjs"] || names["next.config.ts"]
+		},
+	},
+	{
+		framework: Framework{Name: "Terraform", Ignore: []string{".terraform/", "*.tfstate", "*.tfstate.backup"}},
+		detect: func(paths, names, exts map[string]bool) bool {
+			return exts[".tf"]
+		},
+	},
+}
+
+// DetectFrameworks detects frameworks from the files present in a repository tree
+func DetectFrameworks(tree []models.RepositoryTree) []Framework {
+	paths := make(map[string]bool)
+	names := make(map[string]bool)
+	exts := make(map[string]bool)
+
+	for _, entry := range tree {
+		if entry.Type == "tree" {
+			continue
+		}
+		paths[entry.Path] = true
+		names[path.Base(entry.Path)] = true
+		if ext := strings.ToLower(path.Ext(entry.Path)); ext != "" {
+			exts[ext] = true
+		}
+	}
+
+	var frameworks []Framework
+	for _, rule := range frameworkRules {
+		if rule.detect(paths, names, exts) {
+			frameworks = append(frameworks, rule.framework)
+		}
+	}
+	return frameworks
+}
+
+// frameworkNames returns the display names of detected frameworks
+func frameworkNames(frameworks []Framework) []string {
+	var names []string
+	for _, framework := range frameworks {
+		names = append(names, framework.Name)
+	}
+	return names
+}
+
+// frameworkIgnorePatterns returns the combined ignore presets of detected frameworks
+func frameworkIgnorePatterns(frameworks []Framework) []string {
+	var patterns []string
+	for _, framework := range frameworks {
+		patterns = append(patterns, framework.Ignore...)
+	}
+	return patterns
+}
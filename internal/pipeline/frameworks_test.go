@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func blobs(paths ...string) []models.RepositoryTree {
+	var tree []models.RepositoryTree
+	for _, p := range paths {
+		tree = append(tree, models.RepositoryTree{Path: p, Type: "blob"})
+	}
+	return tree
+}
+
+func TestDetectFrameworks(t *testing.T) {
+	tests := []struct {
+		name     string
+		tree     []models.RepositoryTree
+		expected []string
+	}{
+		{
+			name:     "should detect django",
+			tree:     blobs("manage.py", "mysite/settings.py", "mysite/urls.py"),
+			expected: []string{"Django"},
+		},
+		{
+			name:     "should detect rails",
+			tree:     blobs("Gemfile", "config/routes.rb", "app/models/user.rb"),
+			expected: []string{"Rails"},
+		},
+		{
+			name:     "should detect spring",
+			tree:     blobs("pom.xml", "src/main/resources/application.yml", "src/main/java/App.java"),
+			expected: []string{"Spring"},
+		},
+		{
+			name:     "should detect next.js",
+			tree:     blobs("package.json", "next.config.mjs", "pages/index.tsx"),
+			expected: []string{"Next.js"},
+		},
+		{
+			name:     "should detect multiple frameworks",
+			tree:     blobs("web/next.config.js", "infra/main.tf"),
+			expected: []string{"Next.js", "Terraform"},
+		},
+		{
+			name:     "should detect nothing for plain repository",
+			tree:     blobs("main.go", "go.mod"),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, frameworkNames(DetectFrameworks(tt.tree)))
+		})
+	}
+}
+
+func TestFrameworkIgnorePatterns(t *testing.T) {
+	t.Run("should combine presets of detected frameworks", func(t *testing.T) {
+		frameworks := DetectFrameworks(blobs("next.config.js", "main.tf"))
+		patterns := frameworkIgnorePatterns(frameworks)
+
+		assert.Contains(t, patterns, ".next/")
+		assert.Contains(t, patterns, ".terraform/")
+	})
+}
@@ -0,0 +1,200 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+
+	"sherpa/internal/adapters"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// prefetchQueue is the number of tree pages whose files wait to be fetched before listing
+// the tree blocks
+const prefetchQueue = 64
+
+// listTree lists the repository tree. With prefetch set and a provider walking the tree
+// page by page, the files of each page likely to be processed are fetched while the next
+// pages are listed, overlapping both latencies. Fetched files are returned keyed by path.
+//
+// Which files are processed is only known once the whole tree is listed, so prefetching
+// stays conservative: it applies the configured patterns, every framework preset and the
+// file size limit. Files left out are fetched afterwards as usual, and prefetched files
+// that end up filtered out are dropped. Once the files listed exceed the file or repository
+// size limits, which reject the repository, prefetching stops and the files fetched ahead
+// are discarded.
+//
+// When include-only patterns or the requested subdirectory restrict files to a few
+// directories and the provider lists them one by one, only those are listed, without
+// prefetching.
+func (rp *RepoProcessor) listTree(ctx context.Context, repoPath, branch string, prefetch bool) ([]models.RepositoryTree, map[string]models.FileInfo, error) {
+	if lister, ok := rp.provider.(adapters.SubtreeLister); ok {
+		if roots := rp.subtreeRoots(); roots != nil {
+			tree, err := rp.listSubtrees(ctx, lister, repoPath, branch, roots)
+			return tree, nil, err
+		}
+	}
+
+	walker, ok := rp.provider.(adapters.TreeWalker)
+	if !ok || !prefetch {
+		tree, err := rp.provider.GetRepositoryTree(ctx, repoPath, branch)
+		return tree, nil, err
+	}
+
+	filter := rp.newPrefetchFilter(repoPath)
+	pages := make(chan []string, prefetchQueue)
+	prefetched := make(map[string]models.FileInfo)
+	var fetchErr error
+	fetchCtx, cancel := rp.fetchContext(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for paths := range pages {
+			if fetchErr != nil || fetchCtx.Err() != nil {
+				continue
+			}
+			files, err := rp.provider.GetMultipleFiles(fetchCtx, repoPath, paths, branch, rp.maxConcurrency(), &rp.config)
+			if err != nil {
+				fetchErr = err
+				continue
+			}
+			for _, file := range files {
+				prefetched[file.Path] = file
+			}
+		}
+	}()
+
+	var tree []models.RepositoryTree
+	err := walker.WalkRepositoryTree(ctx, repoPath, branch, func(entries []models.RepositoryTree) error {
+		tree = append(tree, entries...)
+		if filter.exceeded() {
+			return nil
+		}
+		var paths []string
+		for _, entry := range entries {
+			if filter.accept(entry) {
+				paths = append(paths, entry.Path)
+			}
+		}
+		// The whole tree is still listed to report its totals when rejecting the repository
+		if filter.exceeded() {
+			cancel()
+			return nil
+		}
+		if len(paths) == 0 {
+			return nil
+		}
+		select {
+		case pages <- fetchOrder(paths, nil, nil):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(pages)
+	<-done
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if filter.exceeded() {
+		logger.Logger.WithFields(map[string]interface{}{
+			"repository": repoPath,
+			"files":      filter.files,
+			"size":       formatBytes(filter.size),
+		}).Debug("Listed files exceed the limits, discarding files fetched ahead")
+		return tree, nil, nil
+	}
+	if fetchErr != nil {
+		logger.Logger.WithError(fetchErr).WithField("repository", repoPath).Debug("Prefetching files failed, fetching them after the tree")
+		return tree, nil, nil
+	}
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"entries":    len(tree),
+		"prefetched": len(prefetched),
+	}).Debug("Listed tree while prefetching files")
+	return tree, prefetched, nil
+}
+
+// prefetchFilter selects the files fetched while the tree is listed
+type prefetchFilter struct {
+	rp          *RepoProcessor
+	repoPath    string
+	ignore      []string
+	maxFileSize int64
+	maxRepoSize int64
+	files       int
+	size        int64
+}
+
+// newPrefetchFilter creates the filter of a repository from the processing configuration
+func (rp *RepoProcessor) newPrefetchFilter(repoPath string) *prefetchFilter {
+	filter := &prefetchFilter{rp: rp, repoPath: repoPath, ignore: rp.config.Ignore}
+	// Frameworks are detected from the whole tree, so the presets of all of them apply
+	if rp.config.FrameworkPresets {
+		filter.ignore = append([]string{}, rp.config.Ignore...)
+		for _, rule := range frameworkRules {
+			filter.ignore = append(filter.ignore, rule.framework.Ignore...)
+		}
+	}
+	if rp.config.MaxFileSize != "" {
+		if size, err := parseSize(rp.config.MaxFileSize); err == nil {
+			filter.maxFileSize = size
+		}
+	}
+	if rp.config.MaxRepoSize != "" {
+		if size, err := parseSize(rp.config.MaxRepoSize); err == nil {
+			filter.maxRepoSize = size
+		}
+	}
+	return filter
+}
+
+// accept reports whether entry is fetched ahead, counting it against the limits
+func (f *prefetchFilter) accept(entry models.RepositoryTree) bool {
+	if entry.Type == "tree" {
+		return false
+	}
+	if f.rp.subdir != "" && !strings.HasPrefix(entry.Path, f.rp.subdir+"/") && entry.Path != f.rp.subdir {
+		return false
+	}
+	if shouldIgnore(entry.Path, f.ignore) || !f.rp.shouldInclude(entry.Path) {
+		return false
+	}
+	if f.maxFileSize > 0 && entry.Size > f.maxFileSize {
+		return false
+	}
+	if f.rp.config.SkipBinary && hasBinaryExtension(entry.Path, f.rp.config.BinaryExtensions) {
+		return false
+	}
+	if f.rp.skipList != nil && f.rp.skipList.ShouldSkip(f.repoPath, entry.Path) {
+		return false
+	}
+	f.files++
+	f.size += entry.Size
+	return true
+}
+
+// exceeded reports whether the files accepted so far exceed the file or repository size
+// limits. Accepted files are processed unless the repository itself ignores them, so the
+// repository is then most likely rejected and nothing more should be fetched ahead.
+func (f *prefetchFilter) exceeded() bool {
+	return (f.rp.config.MaxFiles > 0 && f.files > f.rp.config.MaxFiles) ||
+		(f.maxRepoSize > 0 && f.size > f.maxRepoSize)
+}
+
+// takePrefetched splits paths into the files already prefetched and the paths left to fetch
+func takePrefetched(paths []string, prefetched map[string]models.FileInfo) ([]models.FileInfo, []string) {
+	var files []models.FileInfo
+	var remaining []string
+	for _, filePath := range paths {
+		if file, ok := prefetched[filePath]; ok {
+			files = append(files, file)
+			continue
+		}
+		remaining = append(remaining, filePath)
+	}
+	return files, remaining
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWalkProvider adds page by page tree listing to MockProvider
+type MockWalkProvider struct {
+	MockProvider
+	pages [][]models.RepositoryTree
+}
+
+func (m *MockWalkProvider) WalkRepositoryTree(ctx context.Context, repoPath, ref string, fn func(entries []models.RepositoryTree) error) error {
+	for _, page := range m.pages {
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRepoProcessor_listTree(t *testing.T) {
+	textFile := func(path string) models.FileInfo {
+		return models.FileInfo{Path: path, Name: path, Content: "package main", Size: 12, IsText: true}
+	}
+
+	t.Run("should fetch files while the tree is listed", func(t *testing.T) {
+		mockProvider := &MockWalkProvider{pages: [][]models.RepositoryTree{
+			{{Path: "main.go", Type: "blob"}, {Path: "src", Type: "tree"}},
+			{{Path: "src/app.go", Type: "blob"}, {Path: ".next/cache.js", Type: "blob"}},
+		}}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2, FrameworkPresets: true})
+
+		mockProvider.On("GetRepository", mock.Anything, "group/service").Return(&models.Repository{Name: "service"}, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "group/service", []string{"main.go"}, "main", 2, mock.Anything).Return([]models.FileInfo{textFile("main.go")}, nil).Once()
+		mockProvider.On("GetMultipleFiles", mock.Anything, "group/service", []string{"src/app.go"}, "main", 2, mock.Anything).Return([]models.FileInfo{textFile("src/app.go")}, nil).Once()
+		// Next.js is not detected, so its build output left out ahead is fetched afterwards
+		mockProvider.On("GetMultipleFiles", mock.Anything, "group/service", []string{".next/cache.js"}, "main", 2, mock.Anything).Return([]models.FileInfo{textFile(".next/cache.js")}, nil).Once()
+
+		result, err := processor.ProcessRepository(context.Background(), "group/service", "main")
+		require.NoError(t, err)
+
+		var paths []string
+		for _, file := range result.Files {
+			paths = append(paths, file.Path)
+		}
+		assert.Equal(t, []string{"main.go", "src/app.go", ".next/cache.js", "src"}, paths)
+		mockProvider.AssertExpectations(t)
+		mockProvider.AssertNotCalled(t, "GetRepositoryTree", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not fetch anything for a repository over the limits", func(t *testing.T) {
+		mockProvider := &MockWalkProvider{pages: [][]models.RepositoryTree{
+			{{Path: "a.go", Type: "blob"}, {Path: "b.go", Type: "blob"}},
+			{{Path: "c.go", Type: "blob"}},
+		}}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2, MaxFiles: 1})
+
+		mockProvider.On("GetRepository", mock.Anything, "group/service").Return(&models.Repository{Name: "service"}, nil)
+
+		_, err := processor.ProcessRepository(context.Background(), "group/service", "main")
+		var budgetErr *BudgetExceededError
+		require.ErrorAs(t, err, &budgetErr)
+		assert.Contains(t, budgetErr.Reason, "too many files to process safely: 3")
+		mockProvider.AssertNotCalled(t, "GetMultipleFiles", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should discard files fetched ahead once the listed files exceed the limits", func(t *testing.T) {
+		mockProvider := &MockWalkProvider{pages: [][]models.RepositoryTree{
+			{{Path: "a.go", Type: "blob", Size: 600}},
+			{{Path: "b.go", Type: "blob", Size: 600}},
+		}}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2, MaxRepoSize: "1KB"})
+
+		mockProvider.On("GetMultipleFiles", mock.Anything, "group/service", []string{"a.go"}, "main", 2, mock.Anything).Return([]models.FileInfo{textFile("a.go")}, nil).Maybe()
+
+		tree, prefetched, err := processor.listTree(context.Background(), "group/service", "main", true)
+		require.NoError(t, err)
+		assert.Len(t, tree, 2)
+		assert.Nil(t, prefetched)
+		mockProvider.AssertNotCalled(t, "GetMultipleFiles", mock.Anything, "group/service", []string{"b.go"}, "main", 2, mock.Anything)
+	})
+
+	t.Run("should list the whole tree first with --diff", func(t *testing.T) {
+		mockProvider := &MockWalkProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{MaxConcurrency: 2})
+
+		mockProvider.On("GetRepositoryTree", mock.Anything, "group/service", "main").Return([]models.RepositoryTree{{Path: "main.go", Type: "blob"}}, nil)
+
+		tree, prefetched, err := processor.listTree(context.Background(), "group/service", "main", false)
+		require.NoError(t, err)
+		assert.Len(t, tree, 1)
+		assert.Nil(t, prefetched)
+	})
+}
+
+func TestTakePrefetched(t *testing.T) {
+	t.Run("should split prefetched files from the paths left to fetch", func(t *testing.T) {
+		prefetched := map[string]models.FileInfo{"a.go": {Path: "a.go"}, "dropped.go": {Path: "dropped.go"}}
+
+		files, remaining := takePrefetched([]string{"a.go", "b.go"}, prefetched)
+		assert.Equal(t, []models.FileInfo{{Path: "a.go"}}, files)
+		assert.Equal(t, []string{"b.go"}, remaining)
+	})
+}
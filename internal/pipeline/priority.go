@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"sort"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// fetchOrder sorts paths so the most valuable files are fetched first, in the order the
+// generator lists them: read-first annotations, then owner priority patterns, then entry
+// points, configuration, documentation and source code before other files and tests.
+// Runs cut short by the rate limit then still hold the files that matter most.
+func fetchOrder(paths []string, priority []string, annotations []models.Annotation) []string {
+	var readFirst []string
+	for _, annotation := range annotations {
+		if annotation.ReadFirst {
+			readFirst = append(readFirst, annotation.Path, annotation.Path+"/")
+		}
+	}
+	readFirstMatchers := patternMatchers(readFirst)
+	priorityMatchers := patternMatchers(priority)
+
+	type rankedPath struct {
+		path                          string
+		readFirst, priority, category int
+	}
+	ranked := make([]rankedPath, len(paths))
+	for i, filePath := range paths {
+		ranked[i] = rankedPath{
+			path:      filePath,
+			readFirst: patternRank(readFirstMatchers, filePath),
+			priority:  patternRank(priorityMatchers, filePath),
+			category:  utils.FilePriority(filePath),
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].readFirst != ranked[j].readFirst {
+			return ranked[i].readFirst < ranked[j].readFirst
+		}
+		if ranked[i].priority != ranked[j].priority {
+			return ranked[i].priority < ranked[j].priority
+		}
+		return ranked[i].category < ranked[j].category
+	})
+
+	ordered := make([]string, len(ranked))
+	for i, r := range ranked {
+		ordered[i] = r.path
+	}
+	return ordered
+}
+
+// patternMatchers creates a matcher per pattern, keeping their order
+func patternMatchers(patterns []string) []*utils.PatternMatcher {
+	matchers := make([]*utils.PatternMatcher, len(patterns))
+	for i, pattern := range patterns {
+		matchers[i] = utils.NewPatternMatcher([]string{pattern}, nil)
+	}
+	return matchers
+}
+
+// patternRank returns the index of the first matcher matching filePath, or the number of
+// matchers when none does
+func patternRank(matchers []*utils.PatternMatcher, filePath string) int {
+	for i, matcher := range matchers {
+		if matcher.ShouldIgnore(filePath) {
+			return i
+		}
+	}
+	return len(matchers)
+}
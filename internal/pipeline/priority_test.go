@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchOrder(t *testing.T) {
+	paths := []string{"testdata/golden.txt", "docs/guide.md", "internal/store.go", "config.yaml", "cmd/main.go", "assets/logo.svg"}
+
+	tests := []struct {
+		name        string
+		priority    []string
+		annotations []models.Annotation
+		expected    []string
+	}{
+		{
+			name:     "should fetch entry points, configuration, docs and code before other files and tests",
+			expected: []string{"cmd/main.go", "config.yaml", "docs/guide.md", "internal/store.go", "assets/logo.svg", "testdata/golden.txt"},
+		},
+		{
+			name:     "should fetch owner priorities first, in pattern order",
+			priority: []string{"internal/store.go", "docs/"},
+			expected: []string{"internal/store.go", "docs/guide.md", "cmd/main.go", "config.yaml", "assets/logo.svg", "testdata/golden.txt"},
+		},
+		{
+			name:        "should fetch read-first annotations before owner priorities",
+			priority:    []string{"docs/"},
+			annotations: []models.Annotation{{Path: "assets", ReadFirst: true}, {Path: "cmd", Description: "CLI"}},
+			expected:    []string{"assets/logo.svg", "docs/guide.md", "cmd/main.go", "config.yaml", "internal/store.go", "testdata/golden.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fetchOrder(paths, tt.priority, tt.annotations))
+		})
+	}
+}
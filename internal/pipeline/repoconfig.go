@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFile is the file repository owners add to curate their generated context
+const RepoConfigFile = ".sherpa.yml"
+
+// IgnoreFile is the file repository owners add to exclude paths from their generated context,
+// with the syntax of .gitignore files
+const IgnoreFile = ".sherpaignore"
+
+// loadRepoConfig fetches and parses the repository's own .sherpa.yml when present.
+// Invalid files are logged and ignored so they never block processing.
+func (rp *RepoProcessor) loadRepoConfig(ctx context.Context, repoPath, branch string, tree []models.RepositoryTree) *models.RepoConfig {
+	found := false
+	for _, entry := range tree {
+		if entry.Type == "blob" && entry.Path == RepoConfigFile {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	content, err := rp.provider.GetFileContent(ctx, repoPath, RepoConfigFile, branch)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Failed to fetch repository .sherpa.yml, ignoring it")
+		return nil
+	}
+
+	repoConfig, err := ParseRepoConfig(content)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Invalid repository .sherpa.yml, ignoring it")
+		return nil
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"ignore":     len(repoConfig.Ignore),
+		"priority":   len(repoConfig.Priority),
+	}).Debug("Loaded repository .sherpa.yml")
+
+	return repoConfig
+}
+
+// ParseRepoConfig parses the content of a repository .sherpa.yml
+func ParseRepoConfig(content string) (*models.RepoConfig, error) {
+	var repoConfig models.RepoConfig
+	if err := yaml.Unmarshal([]byte(content), &repoConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", RepoConfigFile, err)
+	}
+	return &repoConfig, nil
+}
+
+// loadIgnoreFile fetches and parses the patterns of the repository's own .sherpaignore when
+// present. Files that cannot be fetched are logged and ignored so they never block processing.
+func (rp *RepoProcessor) loadIgnoreFile(ctx context.Context, repoPath, branch string, tree []models.RepositoryTree) []string {
+	found := false
+	for _, entry := range tree {
+		if entry.Type == "blob" && entry.Path == IgnoreFile {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	content, err := rp.provider.GetFileContent(ctx, repoPath, IgnoreFile, branch)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("repository", repoPath).Warn("Failed to fetch repository .sherpaignore, ignoring it")
+		return nil
+	}
+
+	patterns := utils.ParseIgnoreFile(content)
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"patterns":   len(patterns),
+	}).Debug("Loaded repository .sherpaignore")
+
+	return patterns
+}
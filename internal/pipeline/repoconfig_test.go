@@ -0,0 +1,38 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepoConfig(t *testing.T) {
+	t.Run("should parse ignore, priority and summary", func(t *testing.T) {
+		content := `
+summary: Payment gateway service
+ignore:
+  - fixtures/
+  - "*.snap"
+priority:
+  - docs/ARCHITECTURE.md
+  - core/
+`
+		repoConfig, err := ParseRepoConfig(content)
+		require.NoError(t, err)
+		assert.Equal(t, "Payment gateway service", repoConfig.Summary)
+		assert.Equal(t, []string{"fixtures/", "*.snap"}, repoConfig.Ignore)
+		assert.Equal(t, []string{"docs/ARCHITECTURE.md", "core/"}, repoConfig.Priority)
+	})
+
+	t.Run("should accept an empty file", func(t *testing.T) {
+		repoConfig, err := ParseRepoConfig("")
+		require.NoError(t, err)
+		assert.Empty(t, repoConfig.Ignore)
+	})
+
+	t.Run("should error on invalid YAML", func(t *testing.T) {
+		_, err := ParseRepoConfig("ignore: [")
+		assert.Error(t, err)
+	})
+}
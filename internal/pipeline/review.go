@@ -0,0 +1,243 @@
+package pipeline
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrReviewAborted is returned when the user quits a review instead of confirming it
+var ErrReviewAborted = errors.New("file review aborted")
+
+// FileReviewer lets a user choose which files are fetched, before any content is downloaded
+type FileReviewer interface {
+	Review(repoPath string, files []models.RepositoryTree) ([]models.RepositoryTree, error)
+}
+
+// reviewHelp describes the commands accepted by the interactive reviewer
+const reviewHelp = `Commands:
+  3 5-8        toggle files by number
+  a / n        select all / none
+  +PATTERN     select files matching an ignore-style pattern (e.g. +*.go, +docs/)
+  -PATTERN     deselect files matching a pattern
+  w FILE       save deselected files as ignore patterns to FILE
+  l            list files again
+  ?            show this help
+  Enter        confirm the selection
+  q            abort this repository
+`
+
+// InteractiveReviewer asks the user to toggle files on a terminal
+type InteractiveReviewer struct {
+	in  *bufio.Scanner
+	out io.Writer
+	mu  sync.Mutex // Repositories processed concurrently are reviewed one at a time
+}
+
+// NewInteractiveReviewer creates a reviewer reading commands from in and writing prompts to out
+func NewInteractiveReviewer(in io.Reader, out io.Writer) *InteractiveReviewer {
+	return &InteractiveReviewer{in: bufio.NewScanner(in), out: out}
+}
+
+// Review lists files and applies commands until the selection is confirmed
+func (r *InteractiveReviewer) Review(repoPath string, files []models.RepositoryTree) ([]models.RepositoryTree, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	selected := make([]bool, len(files))
+	for i := range selected {
+		selected[i] = true
+	}
+
+	fmt.Fprintf(r.out, "\nReview files for %s (%d files)\n", repoPath, len(files))
+	r.list(files, selected)
+	fmt.Fprint(r.out, reviewHelp)
+
+	for {
+		fmt.Fprintf(r.out, "[%d/%d selected] > ", countSelected(selected), len(files))
+		if !r.in.Scan() {
+			if err := r.in.Err(); err != nil {
+				return nil, fmt.Errorf("failed to read review input: %w", err)
+			}
+			return nil, ErrReviewAborted
+		}
+
+		command := strings.TrimSpace(r.in.Text())
+		switch {
+		case command == "":
+			var reviewed []models.RepositoryTree
+			for i, file := range files {
+				if selected[i] {
+					reviewed = append(reviewed, file)
+				}
+			}
+			return reviewed, nil
+		case command == "q":
+			return nil, ErrReviewAborted
+		case command == "a" || command == "n":
+			for i := range selected {
+				selected[i] = command == "a"
+			}
+		case command == "l":
+			r.list(files, selected)
+		case command == "?":
+			fmt.Fprint(r.out, reviewHelp)
+		case strings.HasPrefix(command, "+") || strings.HasPrefix(command, "-"):
+			pattern := strings.TrimSpace(command[1:])
+			matched := 0
+			for i, file := range files {
+				if shouldIgnore(file.Path, []string{pattern}) {
+					selected[i] = command[0] == '+'
+					matched++
+				}
+			}
+			fmt.Fprintf(r.out, "%d files matched %s\n", matched, pattern)
+		case strings.HasPrefix(command, "w "):
+			target := strings.TrimSpace(strings.TrimPrefix(command, "w "))
+			if err := SaveReviewPatterns(target, ReviewIgnorePatterns(files, selected)); err != nil {
+				fmt.Fprintf(r.out, "%v\n", err)
+				continue
+			}
+			fmt.Fprintf(r.out, "Saved ignore patterns to %s\n", target)
+		default:
+			if err := toggleFiles(selected, command); err != nil {
+				fmt.Fprintf(r.out, "%v (type ? for help)\n", err)
+			}
+		}
+	}
+}
+
+// list prints the numbered files with their selection state
+func (r *InteractiveReviewer) list(files []models.RepositoryTree, selected []bool) {
+	for i, file := range files {
+		mark := " "
+		if selected[i] {
+			mark = "x"
+		}
+		size := ""
+		if file.Size > 0 {
+			size = fmt.Sprintf(" (%s)", utils.FormatBytes(file.Size))
+		}
+		fmt.Fprintf(r.out, "  [%s] %4d  %s%s\n", mark, i+1, file.Path, size)
+	}
+}
+
+// toggleFiles flips the selection of the files numbered in a command like "3 5-8,10"
+func toggleFiles(selected []bool, command string) error {
+	fields := strings.FieldsFunc(command, func(c rune) bool { return c == ' ' || c == ',' })
+	for _, field := range fields {
+		start, end, isRange := strings.Cut(field, "-")
+		if !isRange {
+			end = start
+		}
+
+		first, err := strconv.Atoi(start)
+		if err != nil {
+			return fmt.Errorf("unknown command %q", field)
+		}
+		last, err := strconv.Atoi(end)
+		if err != nil {
+			return fmt.Errorf("unknown command %q", field)
+		}
+		if first < 1 || last > len(selected) || first > last {
+			return fmt.Errorf("file number out of range: %s (1-%d)", field, len(selected))
+		}
+
+		for i := first - 1; i < last; i++ {
+			selected[i] = !selected[i]
+		}
+	}
+	return nil
+}
+
+// ReviewIgnorePatterns returns ignore patterns excluding the deselected files,
+// collapsing directories whose files are all deselected into "/dir/" patterns. Patterns
+// are anchored at the root and escaped, so they match the deselected paths only.
+func ReviewIgnorePatterns(files []models.RepositoryTree, selected []bool) []string {
+	// Count files kept below each directory
+	kept := make(map[string]int)
+	for i, file := range files {
+		if !selected[i] {
+			continue
+		}
+		for dir := path.Dir(file.Path); dir != "."; dir = path.Dir(dir) {
+			kept[dir]++
+		}
+	}
+
+	seen := make(map[string]bool)
+	var patterns []string
+	for i, file := range files {
+		if selected[i] {
+			continue
+		}
+
+		// Use the outermost directory without any kept file
+		pattern := literalPattern(file.Path)
+		for dir := path.Dir(file.Path); dir != "."; dir = path.Dir(dir) {
+			if kept[dir] == 0 {
+				pattern = literalPattern(dir) + "/"
+			}
+		}
+
+		if !seen[pattern] {
+			seen[pattern] = true
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	sort.Strings(patterns)
+	return patterns
+}
+
+// literalPattern returns a pattern matching the root-relative filePath only, anchoring it
+// at the root and escaping the characters patterns give a meaning to
+func literalPattern(filePath string) string {
+	var b strings.Builder
+	b.WriteString("/")
+	for i, r := range filePath {
+		if strings.ContainsRune(`\*?[!`, r) || (r == '#' && i == 0) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SaveReviewPatterns writes ignore patterns using the layout of a repository .sherpa.yml
+func SaveReviewPatterns(filePath string, patterns []string) error {
+	data, err := yaml.Marshal(struct {
+		Ignore []string `yaml:"ignore"`
+	}{Ignore: patterns})
+	if err != nil {
+		return fmt.Errorf("failed to encode review patterns: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save review patterns: %w", err)
+	}
+	return nil
+}
+
+// countSelected returns the number of selected files
+func countSelected(selected []bool) int {
+	count := 0
+	for _, isSelected := range selected {
+		if isSelected {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,142 @@
+package pipeline
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reviewTree(paths ...string) []models.RepositoryTree {
+	var tree []models.RepositoryTree
+	for _, path := range paths {
+		tree = append(tree, models.RepositoryTree{Name: filepath.Base(path), Path: path, Type: "blob"})
+	}
+	return tree
+}
+
+func reviewedPaths(files []models.RepositoryTree) []string {
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func TestInteractiveReviewer_Review(t *testing.T) {
+	files := reviewTree("README.md", "main.go", "internal/auth/token.go", "internal/auth/keys.go", "docs/guide.md")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "should keep every file when confirmed immediately",
+			input:    "\n",
+			expected: []string{"README.md", "main.go", "internal/auth/token.go", "internal/auth/keys.go", "docs/guide.md"},
+		},
+		{
+			name:     "should toggle files by number and range",
+			input:    "1 3-4\n\n",
+			expected: []string{"main.go", "docs/guide.md"},
+		},
+		{
+			name:     "should deselect files matching a pattern",
+			input:    "-internal/auth/\n\n",
+			expected: []string{"README.md", "main.go", "docs/guide.md"},
+		},
+		{
+			name:     "should select only files matching a pattern",
+			input:    "n\n+*.md\n\n",
+			expected: []string{"README.md", "docs/guide.md"},
+		},
+		{
+			name:     "should ignore invalid commands",
+			input:    "99\nfoo\n\n",
+			expected: []string{"README.md", "main.go", "internal/auth/token.go", "internal/auth/keys.go", "docs/guide.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reviewer := NewInteractiveReviewer(strings.NewReader(tt.input), &bytes.Buffer{})
+
+			reviewed, err := reviewer.Review("owner/repo", files)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, reviewedPaths(reviewed))
+		})
+	}
+
+	t.Run("should abort on q or end of input", func(t *testing.T) {
+		for _, input := range []string{"q\n", ""} {
+			reviewer := NewInteractiveReviewer(strings.NewReader(input), &bytes.Buffer{})
+			_, err := reviewer.Review("owner/repo", files)
+			assert.ErrorIs(t, err, ErrReviewAborted)
+		}
+	})
+
+	t.Run("should save deselected files as ignore patterns", func(t *testing.T) {
+		target := filepath.Join(t.TempDir(), "review.yml")
+		out := &bytes.Buffer{}
+		reviewer := NewInteractiveReviewer(strings.NewReader("-internal/auth/\n2\nw "+target+"\n\n"), out)
+
+		_, err := reviewer.Review("owner/repo", files)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Saved ignore patterns")
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		repoConfig, err := ParseRepoConfig(string(data))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/internal/", "/main.go"}, repoConfig.Ignore)
+	})
+}
+
+func TestReviewIgnorePatterns(t *testing.T) {
+	files := reviewTree("cmd/main.go", "internal/auth/token.go", "internal/auth/keys.go", "internal/api/server.go")
+
+	t.Run("should collapse fully deselected directories", func(t *testing.T) {
+		patterns := ReviewIgnorePatterns(files, []bool{true, false, false, true})
+		assert.Equal(t, []string{"/internal/auth/"}, patterns)
+	})
+
+	t.Run("should list files of partially deselected directories", func(t *testing.T) {
+		patterns := ReviewIgnorePatterns(files, []bool{true, false, true, true})
+		assert.Equal(t, []string{"/internal/auth/token.go"}, patterns)
+	})
+
+	t.Run("should return no patterns when everything is selected", func(t *testing.T) {
+		assert.Empty(t, ReviewIgnorePatterns(files, []bool{true, true, true, true}))
+	})
+
+	t.Run("should only match the deselected paths and not their namesakes", func(t *testing.T) {
+		files := reviewTree("README.md", "docs/guide.md", "pkg/README.md", "web/docs/index.md")
+
+		patterns := ReviewIgnorePatterns(files, []bool{false, false, true, true})
+		assert.Equal(t, []string{"/README.md", "/docs/"}, patterns)
+		assert.True(t, utils.MatchPatterns(patterns, "README.md"))
+		assert.True(t, utils.MatchPatterns(patterns, "docs/guide.md"))
+		assert.False(t, utils.MatchPatterns(patterns, "pkg/README.md"))
+		assert.False(t, utils.MatchPatterns(patterns, "web/docs/index.md"))
+	})
+
+	t.Run("should escape pattern characters in paths", func(t *testing.T) {
+		files := reviewTree("#notes.md", "[draft]/a*b?.md", "!keep.md", "main.go")
+
+		patterns := ReviewIgnorePatterns(files, []bool{false, false, false, true})
+		assert.Equal(t, []string{`/\!keep.md`, `/\#notes.md`, `/\[draft]/`}, patterns)
+		for _, file := range []string{"#notes.md", "[draft]/a*b?.md", "!keep.md"} {
+			assert.True(t, utils.MatchPatterns(patterns, file), file)
+		}
+		assert.False(t, utils.MatchPatterns(patterns, "d/a*b?.md"))
+		assert.False(t, utils.MatchPatterns(patterns, "main.go"))
+	})
+}
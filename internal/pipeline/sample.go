@@ -0,0 +1,59 @@
+package pipeline
+
+import (
+	"hash/fnv"
+	"math"
+	"path"
+	"sort"
+
+	"sherpa/pkg/models"
+)
+
+// sampleFiles keeps a pseudo-random share of files, percent out of 100 and at least one
+// file. Each path is ranked by a hash seeded with seed, so the same repository at the same
+// ref always yields the same sample. Files keep their tree order.
+func sampleFiles(files []models.RepositoryTree, percent float64, seed string) []models.RepositoryTree {
+	if len(files) == 0 || percent >= 100 {
+		return files
+	}
+	keep := max(int(math.Ceil(float64(len(files))*percent/100)), 1)
+
+	ranks := make([]uint64, len(files))
+	order := make([]int, len(files))
+	for i, file := range files {
+		hash := fnv.New64a()
+		hash.Write([]byte(seed))
+		hash.Write([]byte{0})
+		hash.Write([]byte(file.Path))
+		ranks[i] = hash.Sum64()
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return ranks[order[i]] < ranks[order[j]] })
+
+	kept := order[:keep]
+	sort.Ints(kept)
+	sampled := make([]models.RepositoryTree, len(kept))
+	for i, index := range kept {
+		sampled[i] = files[index]
+	}
+	return sampled
+}
+
+// sampledDirectories keeps the directories holding at least one of files, so the tree of
+// a sample has no empty branches
+func sampledDirectories(directories, files []models.RepositoryTree) []models.RepositoryTree {
+	holding := make(map[string]bool)
+	for _, file := range files {
+		for dir := path.Dir(file.Path); dir != "." && dir != "/" && !holding[dir]; dir = path.Dir(dir) {
+			holding[dir] = true
+		}
+	}
+
+	var kept []models.RepositoryTree
+	for _, dir := range directories {
+		if holding[dir.Path] {
+			kept = append(kept, dir)
+		}
+	}
+	return kept
+}
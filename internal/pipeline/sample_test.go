@@ -0,0 +1,59 @@
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleFiles(t *testing.T) {
+	var files []models.RepositoryTree
+	for i := 0; i < 50; i++ {
+		files = append(files, models.RepositoryTree{Path: fmt.Sprintf("src/file%02d.go", i), Type: "blob"})
+	}
+
+	t.Run("should keep the share of files in tree order", func(t *testing.T) {
+		sampled := sampleFiles(files, 10, "owner/repo@main")
+
+		assert.Len(t, sampled, 5)
+		assert.IsIncreasing(t, paths(sampled))
+	})
+
+	t.Run("should pick the same files for the same seed", func(t *testing.T) {
+		assert.Equal(t, sampleFiles(files, 10, "owner/repo@main"), sampleFiles(files, 10, "owner/repo@main"))
+		assert.NotEqual(t, sampleFiles(files, 10, "owner/repo@main"), sampleFiles(files, 10, "owner/repo@dev"))
+	})
+
+	t.Run("should keep at least one file", func(t *testing.T) {
+		assert.Len(t, sampleFiles(files[:3], 1, "owner/repo@main"), 1)
+	})
+
+	t.Run("should keep every file of a full sample", func(t *testing.T) {
+		assert.Equal(t, files, sampleFiles(files, 100, "owner/repo@main"))
+	})
+}
+
+func TestSampledDirectories(t *testing.T) {
+	directories := []models.RepositoryTree{
+		{Path: "src", Type: "tree"},
+		{Path: "src/api", Type: "tree"},
+		{Path: "docs", Type: "tree"},
+	}
+	files := []models.RepositoryTree{{Path: "src/api/handler.go", Type: "blob"}}
+
+	t.Run("should keep only the directories holding sampled files", func(t *testing.T) {
+		assert.Equal(t, directories[:2], sampledDirectories(directories, files))
+	})
+}
+
+// paths returns the paths of tree entries
+func paths(entries []models.RepositoryTree) []string {
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Path
+	}
+	return result
+}
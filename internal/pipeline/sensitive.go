@@ -0,0 +1,29 @@
+package pipeline
+
+import (
+	"sort"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// FindSensitiveFiles returns the sorted paths of included files matching sensitive patterns
+func FindSensitiveFiles(files []models.FileInfo, patterns []string) []string {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	matcher := utils.NewPatternMatcher(patterns, nil)
+	var sensitive []string
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		if matcher.ShouldIgnore(file.Path) {
+			sensitive = append(sensitive, file.Path)
+		}
+	}
+
+	sort.Strings(sensitive)
+	return sensitive
+}
@@ -0,0 +1,31 @@
+package pipeline
+
+import (
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSensitiveFiles(t *testing.T) {
+	files := []models.FileInfo{
+		{Path: "src/auth/login.go"},
+		{Path: "payments/stripe.go"},
+		{Path: "src/auth", IsDir: true},
+		{Path: "README.md"},
+	}
+
+	t.Run("should return matching file paths sorted", func(t *testing.T) {
+		result := FindSensitiveFiles(files, []string{"auth/", "payments/"})
+		assert.Equal(t, []string{"payments/stripe.go", "src/auth/login.go"}, result)
+	})
+
+	t.Run("should return nothing without patterns", func(t *testing.T) {
+		assert.Empty(t, FindSensitiveFiles(files, nil))
+	})
+
+	t.Run("should return nothing when no file matches", func(t *testing.T) {
+		assert.Empty(t, FindSensitiveFiles(files, []string{"crypto/"}))
+	})
+}
@@ -0,0 +1,147 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"sherpa/internal/filelock"
+)
+
+// SkipListFile is the name of the skip list inside the cache directory
+const SkipListFile = "skiplist.json"
+
+// SkipEntry tracks consecutive fetch failures of a single file
+type SkipEntry struct {
+	Failures  int       `json:"failures"`
+	LastError string    `json:"last_error"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// SkipList remembers files that keep failing so later runs skip them
+// instead of retrying and logging the same errors again
+type SkipList struct {
+	path      string
+	threshold int
+	ttl       time.Duration
+	entries   map[string]SkipEntry
+	changed   map[string]bool // Keys recorded or forgotten since the list was loaded
+	mu        sync.Mutex
+}
+
+// LoadSkipList reads the skip list from the cache directory, starting empty when none exists.
+// Files are skipped after threshold consecutive failures; entries older than ttl are retried.
+func LoadSkipList(cacheDir string, threshold int, ttl time.Duration) (*SkipList, error) {
+	if threshold <= 0 {
+		threshold = 1
+	}
+
+	skipList := &SkipList{
+		path:      filepath.Join(cacheDir, SkipListFile),
+		threshold: threshold,
+		ttl:       ttl,
+		entries:   make(map[string]SkipEntry),
+		changed:   make(map[string]bool),
+	}
+
+	data, err := os.ReadFile(skipList.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return skipList, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skip list: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &skipList.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse skip list %s: %w", skipList.path, err)
+	}
+	return skipList, nil
+}
+
+// ShouldSkip reports whether a file has failed often enough to be skipped
+func (s *SkipList) ShouldSkip(repoPath, filePath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[skipKey(repoPath, filePath)]
+	if !exists || entry.Failures < s.threshold {
+		return false
+	}
+	if s.ttl > 0 && time.Since(entry.LastSeen) > s.ttl {
+		return false
+	}
+	return true
+}
+
+// RecordFailure counts a failed fetch of a file
+func (s *SkipList) RecordFailure(repoPath, filePath string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := skipKey(repoPath, filePath)
+	entry := s.entries[key]
+	entry.Failures++
+	entry.LastError = err.Error()
+	entry.LastSeen = time.Now()
+	s.entries[key] = entry
+	s.changed[key] = true
+}
+
+// RecordSuccess forgets previous failures of a file
+func (s *SkipList) RecordSuccess(repoPath, filePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := skipKey(repoPath, filePath)
+	delete(s.entries, key)
+	s.changed[key] = true
+}
+
+// Save writes the skip list to the cache directory. The entries recorded by this run are
+// merged into the skip list on disk, so runs sharing the cache directory keep each other's
+// entries.
+func (s *SkipList) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.changed) == 0 {
+		return nil
+	}
+	err := filelock.Update(s.path, 0644, func(current []byte) ([]byte, error) {
+		merged := make(map[string]SkipEntry)
+		if current != nil {
+			if err := json.Unmarshal(current, &merged); err != nil {
+				// An unreadable skip list only costs retries, so it is replaced
+				merged = make(map[string]SkipEntry)
+			}
+		}
+		for key := range s.changed {
+			if entry, exists := s.entries[key]; exists {
+				merged[key] = entry
+			} else {
+				delete(merged, key)
+			}
+		}
+
+		data, err := json.MarshalIndent(merged, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode skip list: %w", err)
+		}
+		s.entries = merged
+		return data, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write skip list: %w", err)
+	}
+	s.changed = make(map[string]bool)
+	return nil
+}
+
+// skipKey identifies a file across repositories
+func skipKey(repoPath, filePath string) string {
+	return repoPath + ":" + filePath
+}
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSkipList(t *testing.T) {
+	t.Run("should start empty when no skip list exists", func(t *testing.T) {
+		skipList, err := LoadSkipList(t.TempDir(), 3, 0)
+		require.NoError(t, err)
+		assert.False(t, skipList.ShouldSkip("owner/repo", "big.bin"))
+	})
+
+	t.Run("should error on corrupted skip list", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, SkipListFile), []byte("{"), 0644))
+
+		_, err := LoadSkipList(dir, 3, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestSkipList(t *testing.T) {
+	fetchErr := errors.New("403 Forbidden: LFS object")
+
+	t.Run("should skip files after the failure threshold", func(t *testing.T) {
+		skipList, err := LoadSkipList(t.TempDir(), 2, 0)
+		require.NoError(t, err)
+
+		skipList.RecordFailure("owner/repo", "assets/model.bin", fetchErr)
+		assert.False(t, skipList.ShouldSkip("owner/repo", "assets/model.bin"))
+
+		skipList.RecordFailure("owner/repo", "assets/model.bin", fetchErr)
+		assert.True(t, skipList.ShouldSkip("owner/repo", "assets/model.bin"))
+		assert.False(t, skipList.ShouldSkip("owner/other", "assets/model.bin"))
+	})
+
+	t.Run("should reset failures after a success", func(t *testing.T) {
+		skipList, err := LoadSkipList(t.TempDir(), 1, 0)
+		require.NoError(t, err)
+
+		skipList.RecordFailure("owner/repo", "flaky.go", fetchErr)
+		skipList.RecordSuccess("owner/repo", "flaky.go")
+		assert.False(t, skipList.ShouldSkip("owner/repo", "flaky.go"))
+	})
+
+	t.Run("should retry entries older than the TTL", func(t *testing.T) {
+		skipList, err := LoadSkipList(t.TempDir(), 1, time.Hour)
+		require.NoError(t, err)
+
+		skipList.RecordFailure("owner/repo", "old.bin", fetchErr)
+		entry := skipList.entries[skipKey("owner/repo", "old.bin")]
+		entry.LastSeen = time.Now().Add(-2 * time.Hour)
+		skipList.entries[skipKey("owner/repo", "old.bin")] = entry
+
+		assert.False(t, skipList.ShouldSkip("owner/repo", "old.bin"))
+	})
+
+	t.Run("should persist entries across runs", func(t *testing.T) {
+		dir := t.TempDir()
+		skipList, err := LoadSkipList(dir, 1, 0)
+		require.NoError(t, err)
+
+		skipList.RecordFailure("owner/repo", "assets/model.bin", fetchErr)
+		require.NoError(t, skipList.Save())
+
+		reloaded, err := LoadSkipList(dir, 1, 0)
+		require.NoError(t, err)
+		assert.True(t, reloaded.ShouldSkip("owner/repo", "assets/model.bin"))
+		assert.Equal(t, fetchErr.Error(), reloaded.entries[skipKey("owner/repo", "assets/model.bin")].LastError)
+	})
+
+	t.Run("should keep the entries saved by concurrent runs", func(t *testing.T) {
+		dir := t.TempDir()
+		first, err := LoadSkipList(dir, 1, 0)
+		require.NoError(t, err)
+		second, err := LoadSkipList(dir, 1, 0)
+		require.NoError(t, err)
+
+		first.RecordFailure("owner/repo", "a.bin", fetchErr)
+		second.RecordFailure("owner/repo", "b.bin", fetchErr)
+		require.NoError(t, first.Save())
+		require.NoError(t, second.Save())
+
+		reloaded, err := LoadSkipList(dir, 1, 0)
+		require.NoError(t, err)
+		assert.True(t, reloaded.ShouldSkip("owner/repo", "a.bin"))
+		assert.True(t, reloaded.ShouldSkip("owner/repo", "b.bin"))
+
+		// Forgetting an entry removes it without touching the others
+		second.RecordSuccess("owner/repo", "a.bin")
+		require.NoError(t, second.Save())
+		reloaded, err = LoadSkipList(dir, 1, 0)
+		require.NoError(t, err)
+		assert.False(t, reloaded.ShouldSkip("owner/repo", "a.bin"))
+		assert.True(t, reloaded.ShouldSkip("owner/repo", "b.bin"))
+	})
+}
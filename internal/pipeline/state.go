@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"sherpa/pkg/models"
+	"sherpa/pkg/utils"
+)
+
+// StateDir is the directory below the output directory holding the state of incremental runs
+const StateDir = ".sherpa-state"
+
+// StateVersion is the format version written to new state files
+const StateVersion = 1
+
+// RepoState records the commit a repository was last processed at with the content of its
+// included files, so the next incremental run only fetches files changed since. States of
+// runs cut short by rate limiting are checkpoints the next run resumes from.
+type RepoState struct {
+	Version    int                `json:"version"`
+	Commit     string             `json:"commit"`
+	Incomplete bool               `json:"incomplete,omitempty"`
+	Repository *models.Repository `json:"repository,omitempty"` // Metadata of the repository, so it can be processed offline
+	Files      []StateFile        `json:"files"`
+}
+
+// StateFile is a file whose content was included in the previous output
+type StateFile struct {
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	ContentSize int64  `json:"content_size"`
+	Content     string `json:"content"`
+	IsBinary    bool   `json:"is_binary,omitempty"`
+}
+
+// StatePath returns the state file of a repository below an output directory
+func StatePath(outputDir, repoPath string) string {
+	return filepath.Join(outputDir, StateDir, utils.SanitizeRepoName(repoPath)+".json")
+}
+
+// NewRepoState records the included files of a processing result at commit
+func NewRepoState(commit string, result *models.ProcessingResult) *RepoState {
+	repository := result.Repository
+	state := &RepoState{Version: StateVersion, Commit: commit, Incomplete: result.Incomplete != nil, Repository: &repository}
+	for _, file := range result.Files {
+		if file.IsDir || file.Error != nil || file.NotFetched {
+			continue
+		}
+		state.Files = append(state.Files, StateFile{
+			Path:        file.Path,
+			Size:        file.Size,
+			ContentSize: file.ContentSize,
+			Content:     file.Content,
+			IsBinary:    file.IsBinary,
+		})
+	}
+	return state
+}
+
+// LoadRepoState reads a state file, returning nil without error when none exists
+func LoadRepoState(statePath string) (*RepoState, error) {
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state RepoState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", statePath, err)
+	}
+	if state.Version > StateVersion {
+		return nil, fmt.Errorf("unsupported state file version %d (max: %d)", state.Version, StateVersion)
+	}
+	return &state, nil
+}
+
+// Save writes the state file, creating its directory when needed
+func (s *RepoState) Save(statePath string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode state file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", statePath, err)
+	}
+	return nil
+}
+
+// files returns the recorded files by path
+func (s *RepoState) files() map[string]models.FileInfo {
+	files := make(map[string]models.FileInfo, len(s.Files))
+	for _, file := range s.Files {
+		files[file.Path] = models.FileInfo{
+			Path:        file.Path,
+			Name:        path.Base(file.Path),
+			Size:        file.Size,
+			ContentSize: file.ContentSize,
+			Content:     file.Content,
+			IsText:      !file.IsBinary,
+			IsBinary:    file.IsBinary,
+		}
+	}
+	return files
+}
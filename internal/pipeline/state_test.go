@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRepoState(t *testing.T) {
+	t.Run("should record included files only", func(t *testing.T) {
+		state := NewRepoState("abc123", &models.ProcessingResult{Files: []models.FileInfo{
+			{Path: "main.go", Name: "main.go", Content: "package main", Size: 12, ContentSize: 12, IsText: true},
+			{Path: "src", Name: "src", IsDir: true},
+			{Path: "broken.go", Name: "broken.go", Error: fmt.Errorf("boom")},
+		}})
+
+		assert.Equal(t, StateVersion, state.Version)
+		assert.Equal(t, "abc123", state.Commit)
+		require.Len(t, state.Files, 1)
+		assert.Equal(t, StateFile{Path: "main.go", Size: 12, ContentSize: 12, Content: "package main"}, state.Files[0])
+	})
+}
+
+func TestRepoState_SaveAndLoad(t *testing.T) {
+	t.Run("should round-trip the recorded files", func(t *testing.T) {
+		statePath := StatePath(t.TempDir(), "owner/repo")
+		state := &RepoState{Version: StateVersion, Commit: "abc123", Files: []StateFile{{Path: "src/main.go", Size: 12, ContentSize: 12, Content: "package main"}}}
+		require.NoError(t, state.Save(statePath))
+
+		loaded, err := LoadRepoState(statePath)
+		require.NoError(t, err)
+		assert.Equal(t, state, loaded)
+		assert.Equal(t, models.FileInfo{Path: "src/main.go", Name: "main.go", Size: 12, ContentSize: 12, Content: "package main", IsText: true}, loaded.files()["src/main.go"])
+	})
+
+	t.Run("should return nil when no state was saved", func(t *testing.T) {
+		state, err := LoadRepoState(StatePath(t.TempDir(), "owner/repo"))
+		require.NoError(t, err)
+		assert.Nil(t, state)
+	})
+
+	t.Run("should reject newer state versions", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), "state.json")
+		require.NoError(t, os.WriteFile(statePath, []byte(`{"version": 99, "commit": "abc123"}`), 0644))
+
+		_, err := LoadRepoState(statePath)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported state file version")
+	})
+}
@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+)
+
+// StateProvider serves a repository from the state recorded by a previous incremental run,
+// so it can be processed again without network access. Only the files included in the
+// previous output are known.
+type StateProvider struct {
+	repository models.Repository
+	commit     string
+	files      map[string]models.FileInfo
+}
+
+// NewStateProvider creates a provider serving the files of state. repository describes the
+// repository when the state does not record it, as with states written by older versions.
+func NewStateProvider(repository models.Repository, state *RepoState) *StateProvider {
+	if state.Repository != nil {
+		repository = *state.Repository
+	}
+	return &StateProvider{repository: repository, commit: state.Commit, files: state.files()}
+}
+
+// GetRepository returns the recorded repository
+func (p *StateProvider) GetRepository(ctx context.Context, repoPath string) (*models.Repository, error) {
+	repository := p.repository
+	return &repository, nil
+}
+
+// GetRepositoryTree returns the recorded files with their parent directories
+func (p *StateProvider) GetRepositoryTree(ctx context.Context, repoPath, branch string) ([]models.RepositoryTree, error) {
+	dirs := make(map[string]bool)
+	var tree []models.RepositoryTree
+	for filePath, file := range p.files {
+		tree = append(tree, models.RepositoryTree{ID: filePath, Name: file.Name, Type: "blob", Path: filePath, Mode: "100644", Size: file.Size})
+		for dir := path.Dir(filePath); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+			tree = append(tree, models.RepositoryTree{ID: dir, Name: path.Base(dir), Type: "tree", Path: dir, Mode: "040000"})
+		}
+	}
+	sort.Slice(tree, func(i, j int) bool { return tree[i].Path < tree[j].Path })
+	return tree, nil
+}
+
+// GetFileContent returns the recorded content of a file
+func (p *StateProvider) GetFileContent(ctx context.Context, repoPath, filePath, branch string) (string, error) {
+	file, exists := p.files[filePath]
+	if !exists {
+		return "", notRecorded(filePath)
+	}
+	if file.IsBinary {
+		return "", sherpaerrors.New(sherpaerrors.KindBinarySkipped, fmt.Sprintf("file is binary: %s", filePath))
+	}
+	return file.Content, nil
+}
+
+// GetFileInfo returns the recorded information and content of a file
+func (p *StateProvider) GetFileInfo(ctx context.Context, repoPath, filePath, branch string) (*models.FileInfo, error) {
+	file, exists := p.files[filePath]
+	if !exists {
+		return &models.FileInfo{Path: filePath, Name: path.Base(filePath), Error: notRecorded(filePath)}, nil
+	}
+	return &file, nil
+}
+
+// GetMultipleFiles returns the recorded information and content of files
+func (p *StateProvider) GetMultipleFiles(ctx context.Context, repoPath string, filePaths []string, branch string, maxConcurrency int, config *models.ProcessingConfig) ([]models.FileInfo, error) {
+	results := make([]models.FileInfo, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		file, _ := p.GetFileInfo(ctx, repoPath, filePath, branch)
+		results = append(results, *file)
+	}
+	return results, nil
+}
+
+// ResolveCommit returns the commit the state was recorded at
+func (p *StateProvider) ResolveCommit(ctx context.Context, repoPath, ref string) (string, error) {
+	return p.commit, nil
+}
+
+// TestConnection always succeeds, since nothing is fetched
+func (p *StateProvider) TestConnection(ctx context.Context) error {
+	return nil
+}
+
+// notRecorded reports a file missing from the recorded state
+func notRecorded(filePath string) error {
+	return sherpaerrors.New(sherpaerrors.KindNotFound, fmt.Sprintf("file not recorded by the previous run: %s", filePath))
+}
@@ -0,0 +1,77 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateProvider(t *testing.T) {
+	ctx := context.Background()
+	state := &RepoState{
+		Version:    StateVersion,
+		Commit:     "abc123",
+		Repository: &models.Repository{Name: "repo", PathWithNamespace: "owner/repo", WebURL: "https://github.com/owner/repo", Platform: models.PlatformGitHub},
+		Files: []StateFile{
+			{Path: "src/app/main.go", Size: 12, ContentSize: 12, Content: "package main"},
+			{Path: "logo.png", Size: 2048, IsBinary: true},
+		},
+	}
+
+	t.Run("should list the recorded files with their directories", func(t *testing.T) {
+		tree, err := NewStateProvider(models.Repository{}, state).GetRepositoryTree(ctx, "owner/repo", "main")
+		require.NoError(t, err)
+
+		var entries []string
+		for _, entry := range tree {
+			entries = append(entries, entry.Type+" "+entry.Path)
+		}
+		assert.Equal(t, []string{"blob logo.png", "tree src", "tree src/app", "blob src/app/main.go"}, entries)
+	})
+
+	t.Run("should serve the recorded contents", func(t *testing.T) {
+		provider := NewStateProvider(models.Repository{}, state)
+
+		content, err := provider.GetFileContent(ctx, "owner/repo", "src/app/main.go", "main")
+		require.NoError(t, err)
+		assert.Equal(t, "package main", content)
+
+		files, err := provider.GetMultipleFiles(ctx, "owner/repo", []string{"src/app/main.go", "logo.png"}, "main", 4, &models.ProcessingConfig{})
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		assert.Equal(t, "package main", files[0].Content)
+		assert.True(t, files[1].IsBinary)
+	})
+
+	t.Run("should report files missing from the state as not found", func(t *testing.T) {
+		provider := NewStateProvider(models.Repository{}, state)
+
+		_, err := provider.GetFileContent(ctx, "owner/repo", "README.md", "main")
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(err))
+
+		info, err := provider.GetFileInfo(ctx, "owner/repo", "README.md", "main")
+		require.NoError(t, err)
+		assert.Equal(t, sherpaerrors.KindNotFound, sherpaerrors.KindOf(info.Error))
+	})
+
+	t.Run("should resolve every ref to the recorded commit", func(t *testing.T) {
+		commit, err := NewStateProvider(models.Repository{}, state).ResolveCommit(ctx, "owner/repo", "main")
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", commit)
+	})
+
+	t.Run("should prefer the recorded repository over the given one", func(t *testing.T) {
+		repository, err := NewStateProvider(models.Repository{Name: "given"}, state).GetRepository(ctx, "owner/repo")
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/owner/repo", repository.WebURL)
+
+		repository, err = NewStateProvider(models.Repository{Name: "given"}, &RepoState{Commit: "abc123"}).GetRepository(ctx, "owner/repo")
+		require.NoError(t, err)
+		assert.Equal(t, "given", repository.Name)
+	})
+}
@@ -1,10 +1,46 @@
 package pipeline
 
 import (
+	"path"
+	"sort"
+
 	"sherpa/pkg/models"
 	"sherpa/pkg/utils"
 )
 
+// SlowFilesLimit is the number of files and directories listed in slow-file reports
+const SlowFilesLimit = 10
+
+// LargestFilesLimit is the number of files listed in largest-file reports
+const LargestFilesLimit = 20
+
+// FileSize is the estimated tokens and raw bytes an included file adds to the output
+type FileSize struct {
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+	Size   int64  `json:"size"`
+}
+
+// TokenBucket counts the included files whose estimated tokens fall in [Min, Max).
+// Max is zero for the last, unbounded bucket.
+type TokenBucket struct {
+	Label  string `json:"label"`
+	Min    int    `json:"min"`
+	Max    int    `json:"max,omitempty"`
+	Files  int    `json:"files"`
+	Tokens int    `json:"tokens"`
+	Size   int64  `json:"size"`
+}
+
+// tokenBuckets are the bounds of the token histogram
+var tokenBuckets = []TokenBucket{
+	{Label: "< 100", Min: 0, Max: 100},
+	{Label: "100-1K", Min: 100, Max: 1000},
+	{Label: "1K-10K", Min: 1000, Max: 10000},
+	{Label: "10K-100K", Min: 10000, Max: 100000},
+	{Label: ">= 100K", Min: 100000},
+}
+
 // StatsCalculator handles processing statistics calculation
 type StatsCalculator struct{}
 
@@ -20,8 +56,16 @@ func (sc *StatsCalculator) GetProcessingStats(result *models.ProcessingResult) m
 	stats["total_files"] = result.TotalFiles
 	stats["total_size"] = result.TotalSize
 	stats["total_size_human"] = utils.FormatBytes(result.TotalSize)
+	stats["total_content_size"] = result.TotalContentSize
+	stats["total_content_size_human"] = utils.FormatBytes(result.TotalContentSize)
 	stats["processing_duration"] = result.Duration.String()
 	stats["errors_count"] = len(result.Errors)
+	stats["included"] = result.Counts.Included
+	stats["skipped_binary"] = result.Counts.SkippedBinary
+	stats["skipped_large"] = result.Counts.SkippedLarge
+	stats["skipped_ignored"] = result.Counts.SkippedIgnored
+	stats["failed"] = result.Counts.Failed
+	stats["denied"] = result.Counts.Denied
 	stats["avg_file_size"] = int64(0)
 
 	if result.TotalFiles > 0 {
@@ -41,6 +85,119 @@ func (sc *StatsCalculator) GetProcessingStats(result *models.ProcessingResult) m
 
 	stats["text_files"] = textFiles
 	stats["binary_files"] = binaryFiles
+	stats["slowest_files"] = result.SlowFiles
+	stats["slowest_directories"] = result.SlowDirectories
+
+	sizes := fileSizes(result.Files)
+	stats["largest_files"] = sc.LargestFiles(sizes, LargestFilesLimit)
+	stats["token_histogram"] = sc.TokenHistogram(sizes)
 
 	return stats
 }
+
+// FileSizes returns the estimated tokens and raw bytes of the included files, in order
+func (sc *StatsCalculator) FileSizes(files []models.FileInfo) []FileSize {
+	return fileSizes(files)
+}
+
+// LargestFiles returns the n files adding the most tokens to the output, then the most bytes
+func (sc *StatsCalculator) LargestFiles(sizes []FileSize, n int) []FileSize {
+	largest := make([]FileSize, len(sizes))
+	copy(largest, sizes)
+	sort.SliceStable(largest, func(i, j int) bool {
+		if largest[i].Tokens != largest[j].Tokens {
+			return largest[i].Tokens > largest[j].Tokens
+		}
+		return largest[i].Size > largest[j].Size
+	})
+	if len(largest) > n {
+		largest = largest[:n]
+	}
+	return largest
+}
+
+// TokenHistogram counts the files, tokens and bytes in each token bucket
+func (sc *StatsCalculator) TokenHistogram(sizes []FileSize) []TokenBucket {
+	histogram := make([]TokenBucket, len(tokenBuckets))
+	copy(histogram, tokenBuckets)
+	for _, size := range sizes {
+		for i := range histogram {
+			if size.Tokens >= histogram[i].Min && (histogram[i].Max == 0 || size.Tokens < histogram[i].Max) {
+				histogram[i].Files++
+				histogram[i].Tokens += size.Tokens
+				histogram[i].Size += size.Size
+				break
+			}
+		}
+	}
+	return histogram
+}
+
+// fileSizes estimates the tokens of the included files whose content is rendered
+func fileSizes(files []models.FileInfo) []FileSize {
+	var sizes []FileSize
+	for _, file := range files {
+		if file.IsDir || file.Error != nil {
+			continue
+		}
+		tokens := 0
+		if !file.IsBinary {
+			tokens = utils.EstimateTokens(file.Content)
+		}
+		sizes = append(sizes, FileSize{Path: file.Path, Tokens: tokens, Size: file.Size})
+	}
+	return sizes
+}
+
+// SlowestFiles returns the n fetched files that took longest, slowest first. Files that
+// were not fetched from a platform API, like reused or local files, are left out.
+func SlowestFiles(files []models.FileInfo, n int) []models.FileTiming {
+	var timings []models.FileTiming
+	for _, file := range files {
+		if file.IsDir || file.FetchDuration <= 0 {
+			continue
+		}
+		timings = append(timings, models.FileTiming{Path: file.Path, Duration: file.FetchDuration, Files: 1, Size: file.Size})
+	}
+	return slowest(timings, n)
+}
+
+// SlowestDirectories sums fetch durations by the directory directly containing each file
+// and returns the n slowest directories, slowest first
+func SlowestDirectories(files []models.FileInfo, n int) []models.FileTiming {
+	byDir := make(map[string]*models.FileTiming)
+	for _, file := range files {
+		if file.IsDir || file.FetchDuration <= 0 {
+			continue
+		}
+		dir := path.Dir(file.Path)
+		timing, exists := byDir[dir]
+		if !exists {
+			timing = &models.FileTiming{Path: dir}
+			byDir[dir] = timing
+		}
+		timing.Duration += file.FetchDuration
+		timing.Files++
+		timing.Size += file.Size
+	}
+
+	timings := make([]models.FileTiming, 0, len(byDir))
+	for _, timing := range byDir {
+		timings = append(timings, *timing)
+	}
+	return slowest(timings, n)
+}
+
+// slowest sorts timings by decreasing duration, then path, and keeps the first n
+func slowest(timings []models.FileTiming, n int) []models.FileTiming {
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Duration != timings[j].Duration {
+			return timings[i].Duration > timings[j].Duration
+		}
+		return timings[i].Path < timings[j].Path
+	})
+	if len(timings) > n {
+		timings = timings[:n]
+	}
+	return timings
+}
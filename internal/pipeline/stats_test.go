@@ -2,12 +2,14 @@ package pipeline
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"sherpa/pkg/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewStatsCalculator(t *testing.T) {
@@ -20,15 +22,17 @@ func TestStatsCalculator_GetProcessingStats(t *testing.T) {
 
 	// Test with basic processing result
 	result := &models.ProcessingResult{
-		TotalFiles: 3,
-		TotalSize:  1024,
-		Duration:   time.Second * 5,
+		TotalFiles:       3,
+		TotalSize:        1024,
+		TotalContentSize: 1000,
+		Duration:         time.Second * 5,
 		Files: []models.FileInfo{
 			{Path: "main.go", Size: 512, IsText: true},
 			{Path: "image.png", Size: 256, IsText: false},
 			{Path: "readme.txt", Size: 256, IsText: true},
 		},
 		Errors: []error{errors.New("error1"), errors.New("error2")},
+		Counts: models.FileCounts{Included: 3, SkippedBinary: 1, SkippedLarge: 2, SkippedIgnored: 4, Failed: 2},
 	}
 
 	stats := calculator.GetProcessingStats(result)
@@ -36,8 +40,15 @@ func TestStatsCalculator_GetProcessingStats(t *testing.T) {
 	assert.Equal(t, 3, stats["total_files"])
 	assert.Equal(t, int64(1024), stats["total_size"])
 	assert.Equal(t, "1.0 KB", stats["total_size_human"])
+	assert.Equal(t, int64(1000), stats["total_content_size"])
+	assert.Equal(t, "1000 B", stats["total_content_size_human"])
 	assert.Equal(t, "5s", stats["processing_duration"])
 	assert.Equal(t, 2, stats["errors_count"])
+	assert.Equal(t, 3, stats["included"])
+	assert.Equal(t, 1, stats["skipped_binary"])
+	assert.Equal(t, 2, stats["skipped_large"])
+	assert.Equal(t, 4, stats["skipped_ignored"])
+	assert.Equal(t, 2, stats["failed"])
 	assert.Equal(t, int64(341), stats["avg_file_size"]) // 1024/3
 	assert.Equal(t, "341 B", stats["avg_file_size_human"])
 	assert.Equal(t, 2, stats["text_files"])
@@ -112,3 +123,62 @@ func TestStatsCalculator_GetProcessingStats_WithErrors(t *testing.T) {
 	assert.Equal(t, 1, stats["text_files"])
 	assert.Equal(t, 0, stats["binary_files"])
 }
+
+func TestSlowestFiles(t *testing.T) {
+	files := []models.FileInfo{
+		{Path: "src/main.go", Size: 100, FetchDuration: 200 * time.Millisecond},
+		{Path: "src/util.go", Size: 50, FetchDuration: 100 * time.Millisecond},
+		{Path: "assets/huge.bin", Size: 5000, FetchDuration: 900 * time.Millisecond},
+		{Path: "README.md", Size: 10},
+		{Path: "src", IsDir: true},
+	}
+
+	t.Run("should list fetched files slowest first", func(t *testing.T) {
+		timings := SlowestFiles(files, 2)
+		assert.Equal(t, []models.FileTiming{
+			{Path: "assets/huge.bin", Duration: 900 * time.Millisecond, Files: 1, Size: 5000},
+			{Path: "src/main.go", Duration: 200 * time.Millisecond, Files: 1, Size: 100},
+		}, timings)
+	})
+
+	t.Run("should sum durations by directory", func(t *testing.T) {
+		timings := SlowestDirectories(files, SlowFilesLimit)
+		assert.Equal(t, []models.FileTiming{
+			{Path: "assets", Duration: 900 * time.Millisecond, Files: 1, Size: 5000},
+			{Path: "src", Duration: 300 * time.Millisecond, Files: 2, Size: 150},
+		}, timings)
+	})
+
+	t.Run("should ignore files that were not fetched", func(t *testing.T) {
+		assert.Empty(t, SlowestFiles([]models.FileInfo{{Path: "README.md"}}, SlowFilesLimit))
+	})
+}
+
+func TestStatsCalculator_LargestFiles(t *testing.T) {
+	calculator := NewStatsCalculator()
+	files := []models.FileInfo{
+		{Path: "small.go", Content: "package main", Size: 12, IsText: true},
+		{Path: "big.go", Content: strings.Repeat("func f() {}\n", 200), Size: 2400, IsText: true},
+		{Path: "logo.png", Size: 90000, IsBinary: true},
+		{Path: "broken.go", Error: errors.New("500")},
+		{Path: "src", IsDir: true},
+	}
+	sizes := calculator.FileSizes(files)
+
+	t.Run("should rank files by tokens then bytes", func(t *testing.T) {
+		largest := calculator.LargestFiles(sizes, 2)
+		require.Len(t, largest, 2)
+		assert.Equal(t, "big.go", largest[0].Path)
+		assert.Equal(t, "small.go", largest[1].Path)
+		assert.Len(t, calculator.LargestFiles(sizes, LargestFilesLimit), 3)
+	})
+
+	t.Run("should bucket files by tokens", func(t *testing.T) {
+		histogram := calculator.TokenHistogram(sizes)
+		require.Len(t, histogram, 5)
+		assert.Equal(t, 2, histogram[0].Files, "small and binary files")
+		assert.Equal(t, int64(90012), histogram[0].Size)
+		assert.Equal(t, 1, histogram[2].Files)
+		assert.Equal(t, sizes[1].Tokens, histogram[2].Tokens)
+	})
+}
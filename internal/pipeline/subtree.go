@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"sherpa/internal/adapters"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// subtreeRoots returns the directories holding every file the run may process, or nil when
+// files may be anywhere in the repository. Only include-only patterns anchored at the root
+// with a directory before their first wildcard restrict files to a subtree, negated patterns
+// only narrow them down, and the requested subdirectory restricts them further.
+func (rp *RepoProcessor) subtreeRoots() []string {
+	var roots []string
+	for _, pattern := range rp.config.IncludeOnly {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		root := patternRoot(pattern)
+		if root == "" {
+			// The pattern matches files in any directory
+			roots = nil
+			break
+		}
+		roots = append(roots, root)
+	}
+
+	if rp.subdir != "" {
+		if len(rp.config.IncludeOnly) == 0 || roots == nil {
+			return []string{rp.subdir}
+		}
+		var scoped []string
+		for _, root := range roots {
+			switch {
+			case root == rp.subdir || strings.HasPrefix(root, rp.subdir+"/"):
+				scoped = append(scoped, root)
+			case strings.HasPrefix(rp.subdir, root+"/"):
+				scoped = append(scoped, rp.subdir)
+			}
+		}
+		if len(scoped) == 0 {
+			// No file of the subdirectory is included, which is reported once it is listed
+			return []string{rp.subdir}
+		}
+		roots = scoped
+	}
+
+	// Drop the roots nested in another one
+	sort.Strings(roots)
+	var outer []string
+	for _, root := range roots {
+		if len(outer) > 0 {
+			last := outer[len(outer)-1]
+			if root == last || strings.HasPrefix(root, last+"/") {
+				continue
+			}
+		}
+		outer = append(outer, root)
+	}
+	return outer
+}
+
+// patternRoot returns the directory of an anchored pattern before its first wildcard, like
+// src for src/**/*.go or web for /web/, or an empty string for patterns matching at any
+// depth or at the root
+func patternRoot(pattern string) string {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimRight(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		return ""
+	}
+	literal := strings.TrimLeft(pattern, "/")
+	if i := strings.IndexAny(literal, `*?[\`); i >= 0 {
+		literal = literal[:i]
+	} else if dirOnly {
+		return literal
+	}
+	i := strings.LastIndex(literal, "/")
+	if i < 0 {
+		return ""
+	}
+	return strings.Trim(literal[:i], "/")
+}
+
+// listSubtrees lists the files directly at the root of the repository, where repository
+// files like .sherpa.yml and framework manifests are found, and everything below roots.
+// The whole tree is listed instead when a root is not a directory, as a subdirectory given
+// by the user may be a single file.
+func (rp *RepoProcessor) listSubtrees(ctx context.Context, lister adapters.SubtreeLister, repoPath, branch string, roots []string) ([]models.RepositoryTree, error) {
+	tree, err := lister.ListSubtree(ctx, repoPath, branch, "", false)
+	if err != nil {
+		return nil, err
+	}
+	for _, root := range roots {
+		entries, err := lister.ListSubtree(ctx, repoPath, branch, root, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return rp.provider.GetRepositoryTree(ctx, repoPath, branch)
+		}
+		tree = append(tree, entries...)
+	}
+
+	logger.Logger.WithFields(map[string]interface{}{
+		"repository": repoPath,
+		"subtrees":   roots,
+		"entries":    len(tree),
+	}).Debug("Listed only the subtrees holding the files to process")
+	return tree, nil
+}
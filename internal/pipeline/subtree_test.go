@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSubtreeProvider adds directory listing to MockProvider
+type MockSubtreeProvider struct {
+	MockProvider
+}
+
+func (m *MockSubtreeProvider) ListSubtree(ctx context.Context, repoPath, ref, dir string, recursive bool) ([]models.RepositoryTree, error) {
+	args := m.Called(ctx, repoPath, ref, dir, recursive)
+	return args.Get(0).([]models.RepositoryTree), args.Error(1)
+}
+
+func TestRepoProcessor_subtreeRoots(t *testing.T) {
+	tests := []struct {
+		name        string
+		includeOnly []string
+		subdir      string
+		expected    []string
+	}{
+		{name: "should list the whole tree without include-only patterns", expected: nil},
+		{name: "should list the directories before the first wildcard", includeOnly: []string{"src/**", "docs/api/*.md"}, expected: []string{"docs/api", "src"}},
+		{name: "should list the directory of literal paths", includeOnly: []string{"cmd/main.go"}, expected: []string{"cmd"}},
+		{name: "should list the whole tree when a pattern matches base names", includeOnly: []string{"src/**", "*.go"}, expected: nil},
+		{name: "should list the whole tree when a pattern starts with a wildcard", includeOnly: []string{"*/main.go"}, expected: nil},
+		{name: "should list the whole tree when a directory pattern matches at any depth", includeOnly: []string{"src/"}, expected: nil},
+		{name: "should list the directories of anchored patterns", includeOnly: []string{"/docs/*.md", "/web/"}, expected: []string{"docs", "web"}},
+		{name: "should ignore negated patterns", includeOnly: []string{"src/**", "!src/**/testdata/**"}, expected: []string{"src"}},
+		{name: "should drop nested directories", includeOnly: []string{"src/api/*.go", "src/*.go"}, expected: []string{"src"}},
+		{name: "should list the subdirectory", subdir: "services/api", expected: []string{"services/api"}},
+		{name: "should list the subdirectory when patterns match base names", includeOnly: []string{"*.go"}, subdir: "services", expected: []string{"services"}},
+		{name: "should keep the directories inside the subdirectory", includeOnly: []string{"services/api/*.go", "docs/*.md"}, subdir: "services", expected: []string{"services/api"}},
+		{name: "should narrow directories to the subdirectory", includeOnly: []string{"services/**"}, subdir: "services/api", expected: []string{"services/api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewRepoProcessor(&MockProvider{}, models.ProcessingConfig{IncludeOnly: tt.includeOnly}).WithSubdirectory(tt.subdir)
+			assert.Equal(t, tt.expected, processor.subtreeRoots())
+		})
+	}
+}
+
+func TestRepoProcessor_listSubtrees(t *testing.T) {
+	t.Run("should list only the root and the included directories", func(t *testing.T) {
+		mockProvider := &MockSubtreeProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{IncludeOnly: []string{"src/*.go"}, MaxConcurrency: 2})
+
+		mockProvider.On("GetRepository", mock.Anything, "group/service").Return(&models.Repository{Name: "service"}, nil)
+		mockProvider.On("ListSubtree", mock.Anything, "group/service", "main", "", false).Return([]models.RepositoryTree{
+			{Path: "go.mod", Type: "blob"}, {Path: "src", Type: "tree"},
+		}, nil)
+		mockProvider.On("ListSubtree", mock.Anything, "group/service", "main", "src", true).Return([]models.RepositoryTree{
+			{Path: "src/app.go", Type: "blob"},
+		}, nil)
+		mockProvider.On("GetMultipleFiles", mock.Anything, "group/service", []string{"src/app.go"}, "main", 2, mock.Anything).Return([]models.FileInfo{
+			{Path: "src/app.go", Name: "app.go", Content: "package src", Size: 11, IsText: true},
+		}, nil)
+
+		result, err := processor.ProcessRepository(context.Background(), "group/service", "main")
+		require.NoError(t, err)
+
+		var paths []string
+		for _, file := range result.Files {
+			paths = append(paths, file.Path)
+		}
+		assert.Equal(t, []string{"src/app.go", "src"}, paths)
+		mockProvider.AssertExpectations(t)
+		mockProvider.AssertNotCalled(t, "GetRepositoryTree", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should list the whole tree when a root is not a directory", func(t *testing.T) {
+		mockProvider := &MockSubtreeProvider{}
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{})
+		tree := []models.RepositoryTree{{Path: "README.md", Type: "blob"}, {Path: "docs/guide.md", Type: "blob"}}
+
+		mockProvider.On("ListSubtree", mock.Anything, "group/service", "main", "", false).Return([]models.RepositoryTree{{Path: "README.md", Type: "blob"}}, nil)
+		mockProvider.On("ListSubtree", mock.Anything, "group/service", "main", "README.md", true).Return([]models.RepositoryTree(nil), nil)
+		mockProvider.On("GetRepositoryTree", mock.Anything, "group/service", "main").Return(tree, nil)
+
+		listed, err := processor.listSubtrees(context.Background(), mockProvider, "group/service", "main", []string{"README.md"})
+		require.NoError(t, err)
+		assert.Equal(t, tree, listed)
+	})
+}
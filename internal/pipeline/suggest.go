@@ -0,0 +1,151 @@
+package pipeline
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+)
+
+// maxSuggestionDepth limits directory candidates to the first levels of the tree
+const maxSuggestionDepth = 3
+
+// FilterSuggestion describes an ignore pattern and how much it would save
+type FilterSuggestion struct {
+	Pattern string
+	Files   int
+	Size    int64
+	Percent float64 // Share of the budget metric saved by the pattern
+	BySize  bool    // Whether Percent refers to bytes (true) or file count (false)
+}
+
+// String renders the suggestion as a human-readable sentence
+func (s FilterSuggestion) String() string {
+	metric := "of the files"
+	if s.BySize {
+		metric = "of the repository size"
+	}
+	return fmt.Sprintf("adding `%s` would save %.0f%% %s (%d files, %s)", s.Pattern, s.Percent, metric, s.Files, formatBytes(s.Size))
+}
+
+// BudgetExceededError is returned when a repository exceeds a processing budget
+type BudgetExceededError struct {
+	Reason      string
+	Suggestions []FilterSuggestion
+}
+
+func (e *BudgetExceededError) Error() string {
+	return e.Reason
+}
+
+// Unwrap classifies budget errors as too large
+func (e *BudgetExceededError) Unwrap() error {
+	return sherpaerrors.ErrTooLarge
+}
+
+// SuggestFilters analyzes file entries and returns the ignore patterns that would
+// reduce the budget metric the most. Savings are measured in bytes when bySize is
+// true and in file count otherwise.
+func SuggestFilters(entries []models.RepositoryTree, bySize bool, limit int) []FilterSuggestion {
+	type bucket struct {
+		files int
+		size  int64
+	}
+
+	buckets := make(map[string]*bucket)
+	add := func(pattern string, entry models.RepositoryTree) {
+		b, exists := buckets[pattern]
+		if !exists {
+			b = &bucket{}
+			buckets[pattern] = b
+		}
+		b.files++
+		b.size += entry.Size
+	}
+
+	var totalFiles int
+	var totalSize int64
+	for _, entry := range entries {
+		if entry.Type == "tree" {
+			continue
+		}
+		totalFiles++
+		totalSize += entry.Size
+
+		// Directory candidates
+		parts := strings.Split(entry.Path, "/")
+		for depth := 1; depth < len(parts) && depth <= maxSuggestionDepth; depth++ {
+			add(strings.Join(parts[:depth], "/")+"/", entry)
+		}
+
+		// Extension candidates
+		if ext := path.Ext(entry.Path); ext != "" {
+			add("*"+ext, entry)
+		}
+	}
+
+	if totalFiles == 0 || (bySize && totalSize == 0) {
+		return nil
+	}
+
+	var suggestions []FilterSuggestion
+	for pattern, b := range buckets {
+		// A pattern excluding everything is not a useful suggestion
+		if b.files == totalFiles {
+			continue
+		}
+
+		percent := float64(b.files) / float64(totalFiles) * 100
+		if bySize {
+			percent = float64(b.size) / float64(totalSize) * 100
+		}
+		if percent < 1 {
+			continue
+		}
+
+		suggestions = append(suggestions, FilterSuggestion{
+			Pattern: pattern,
+			Files:   b.files,
+			Size:    b.size,
+			Percent: percent,
+			BySize:  bySize,
+		})
+	}
+
+	// Prefer a more specific directory when its parent would save the same amount
+	var specific []FilterSuggestion
+	for _, candidate := range suggestions {
+		redundant := false
+		for _, other := range suggestions {
+			if other.Pattern != candidate.Pattern &&
+				strings.HasPrefix(other.Pattern, candidate.Pattern) &&
+				other.Files == candidate.Files {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			specific = append(specific, candidate)
+		}
+	}
+
+	sort.Slice(specific, func(i, j int) bool {
+		if specific[i].Percent != specific[j].Percent {
+			return specific[i].Percent > specific[j].Percent
+		}
+		// Directory patterns are easier to reason about than extensions
+		iDir, jDir := strings.HasSuffix(specific[i].Pattern, "/"), strings.HasSuffix(specific[j].Pattern, "/")
+		if iDir != jDir {
+			return iDir
+		}
+		return specific[i].Pattern < specific[j].Pattern
+	})
+
+	if limit > 0 && len(specific) > limit {
+		specific = specific[:limit]
+	}
+	return specific
+}
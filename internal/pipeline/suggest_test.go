@@ -0,0 +1,76 @@
+package pipeline
+
+import (
+	"errors"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestFilters(t *testing.T) {
+	entries := []models.RepositoryTree{
+		{Path: "web/dist/app.js", Type: "blob", Size: 6000},
+		{Path: "web/dist/vendor.js", Type: "blob", Size: 2000},
+		{Path: "web/src/index.ts", Type: "blob", Size: 1000},
+		{Path: "main.go", Type: "blob", Size: 1000},
+		{Path: "web", Type: "tree"},
+	}
+
+	t.Run("should rank patterns by size savings", func(t *testing.T) {
+		suggestions := SuggestFilters(entries, true, 3)
+		require.Len(t, suggestions, 3)
+
+		assert.Equal(t, "web/", suggestions[0].Pattern)
+		assert.InDelta(t, 90.0, suggestions[0].Percent, 0.01)
+		assert.Equal(t, "web/dist/", suggestions[1].Pattern)
+		assert.InDelta(t, 80.0, suggestions[1].Percent, 0.01)
+		assert.Equal(t, 2, suggestions[1].Files)
+	})
+
+	t.Run("should prefer specific directory over equivalent parent", func(t *testing.T) {
+		nested := []models.RepositoryTree{
+			{Path: "assets/img/logo.png", Type: "blob", Size: 900},
+			{Path: "main.go", Type: "blob", Size: 100},
+		}
+
+		suggestions := SuggestFilters(nested, true, 0)
+		patterns := []string{}
+		for _, s := range suggestions {
+			patterns = append(patterns, s.Pattern)
+		}
+		assert.Contains(t, patterns, "assets/img/")
+		assert.NotContains(t, patterns, "assets/")
+	})
+
+	t.Run("should rank by file count when sizes are unknown", func(t *testing.T) {
+		unsized := []models.RepositoryTree{
+			{Path: "docs/a.md", Type: "blob"},
+			{Path: "docs/b.md", Type: "blob"},
+			{Path: "docs/c.md", Type: "blob"},
+			{Path: "main.go", Type: "blob"},
+		}
+
+		suggestions := SuggestFilters(unsized, false, 1)
+		require.Len(t, suggestions, 1)
+		assert.InDelta(t, 75.0, suggestions[0].Percent, 0.01)
+		assert.Contains(t, suggestions[0].String(), "would save 75% of the files")
+	})
+
+	t.Run("should return nothing for empty input", func(t *testing.T) {
+		assert.Empty(t, SuggestFilters(nil, true, 3))
+	})
+}
+
+func TestBudgetExceededError(t *testing.T) {
+	t.Run("should be detectable with errors.As", func(t *testing.T) {
+		var err error = &BudgetExceededError{Reason: "too big"}
+		wrapped := errors.Join(err)
+
+		var budgetErr *BudgetExceededError
+		assert.True(t, errors.As(wrapped, &budgetErr))
+		assert.Equal(t, "too big", budgetErr.Error())
+	})
+}
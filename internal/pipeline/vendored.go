@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// vendorManifest is a file listing the packages of a vendor directory
+type vendorManifest struct {
+	dir       string // Name of the vendor directory
+	file      string // Path of the manifest within the vendor directory
+	ecosystem string
+	parse     func(content string) ([]models.VendoredPackage, error)
+}
+
+// vendorManifests are the manifests read from excluded vendor directories
+var vendorManifests = []vendorManifest{
+	{dir: "vendor", file: "modules.txt", ecosystem: "go", parse: parseGoVendor},
+	{dir: "node_modules", file: ".package-lock.json", ecosystem: "npm", parse: parseNPMHiddenLockfile},
+	{dir: "vendor", file: "composer/installed.json", ecosystem: "composer", parse: parseComposerInstalled},
+}
+
+// loadVendored reads the manifests of vendor directories whose files are left out of the
+// output, so the dependency surface is known without their content. Manifests that cannot
+// be read are skipped.
+func (rp *RepoProcessor) loadVendored(ctx context.Context, repoPath, branch string, tree, filtered []models.RepositoryTree) []models.VendoredDependencies {
+	included := make(map[string]bool, len(filtered))
+	for _, entry := range filtered {
+		included[entry.Path] = true
+	}
+
+	var vendored []models.VendoredDependencies
+	for _, entry := range tree {
+		if entry.Type == "tree" || included[entry.Path] {
+			continue
+		}
+		for _, manifest := range vendorManifests {
+			suffix := manifest.dir + "/" + manifest.file
+			if entry.Path != suffix && !strings.HasSuffix(entry.Path, "/"+suffix) {
+				continue
+			}
+
+			content, err := rp.provider.GetFileContent(ctx, repoPath, entry.Path, branch)
+			if err != nil {
+				logger.Logger.WithError(err).WithField("file", entry.Path).Warn("Failed to fetch vendor manifest, leaving it out")
+				break
+			}
+			packages, err := manifest.parse(content)
+			if err != nil {
+				logger.Logger.WithError(err).WithField("file", entry.Path).Warn("Invalid vendor manifest, leaving it out")
+				break
+			}
+			if len(packages) > 0 {
+				vendored = append(vendored, models.VendoredDependencies{
+					Path:      strings.TrimSuffix(entry.Path, "/"+manifest.file),
+					Ecosystem: manifest.ecosystem,
+					Packages:  packages,
+				})
+			}
+			break
+		}
+	}
+
+	sort.Slice(vendored, func(i, j int) bool { return vendored[i].Path < vendored[j].Path })
+	return vendored
+}
+
+// parseGoVendor reads the modules of vendor/modules.txt, from lines like
+// "# github.com/pkg/errors v0.9.1"
+func parseGoVendor(content string) ([]models.VendoredPackage, error) {
+	var packages []models.VendoredPackage
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "# ") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "# "))
+		if len(fields) == 0 {
+			continue
+		}
+		pkg := models.VendoredPackage{Name: fields[0]}
+		if len(fields) > 1 && fields[1] != "=>" {
+			pkg.Version = fields[1]
+		}
+		packages = append(packages, pkg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read modules.txt: %w", err)
+	}
+	return packages, nil
+}
+
+// parseNPMHiddenLockfile reads the top-level packages of node_modules/.package-lock.json,
+// written by npm 7 and later
+func parseNPMHiddenLockfile(content string) ([]models.VendoredPackage, error) {
+	var lockfile struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal([]byte(content), &lockfile); err != nil {
+		return nil, fmt.Errorf("failed to parse .package-lock.json: %w", err)
+	}
+
+	var packages []models.VendoredPackage
+	for key, info := range lockfile.Packages {
+		name, found := strings.CutPrefix(key, "node_modules/")
+		// Nested packages are dependencies of dependencies
+		if !found || strings.Contains(name, "/node_modules/") {
+			continue
+		}
+		packages = append(packages, models.VendoredPackage{Name: name, Version: info.Version})
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	return packages, nil
+}
+
+// parseComposerInstalled reads the packages of vendor/composer/installed.json, a list of
+// packages before Composer 2 and an object holding them since
+func parseComposerInstalled(content string) ([]models.VendoredPackage, error) {
+	type composerPackage struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	var list []composerPackage
+	if err := json.Unmarshal([]byte(content), &list); err != nil {
+		var installed struct {
+			Packages []composerPackage `json:"packages"`
+		}
+		if err := json.Unmarshal([]byte(content), &installed); err != nil {
+			return nil, fmt.Errorf("failed to parse installed.json: %w", err)
+		}
+		list = installed.Packages
+	}
+
+	packages := make([]models.VendoredPackage, 0, len(list))
+	for _, pkg := range list {
+		packages = append(packages, models.VendoredPackage{Name: pkg.Name, Version: pkg.Version})
+	}
+	return packages, nil
+}
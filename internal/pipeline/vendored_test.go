@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVendorManifests(t *testing.T) {
+	t.Run("should read go modules with their versions", func(t *testing.T) {
+		content := "# github.com/pkg/errors v0.9.1\n## explicit\ngithub.com/pkg/errors\n# example.com/local => ./local\nexample.com/local\n"
+
+		packages, err := parseGoVendor(content)
+		require.NoError(t, err)
+		assert.Equal(t, []models.VendoredPackage{{Name: "github.com/pkg/errors", Version: "v0.9.1"}, {Name: "example.com/local"}}, packages)
+	})
+
+	t.Run("should read top-level npm packages", func(t *testing.T) {
+		content := `{"packages": {"node_modules/react": {"version": "18.2.0"}, "node_modules/@babel/core": {"version": "7.24.0"}, "node_modules/react/node_modules/loose-envify": {"version": "1.4.0"}}}`
+
+		packages, err := parseNPMHiddenLockfile(content)
+		require.NoError(t, err)
+		assert.Equal(t, []models.VendoredPackage{{Name: "@babel/core", Version: "7.24.0"}, {Name: "react", Version: "18.2.0"}}, packages)
+	})
+
+	t.Run("should read composer packages in both formats", func(t *testing.T) {
+		expected := []models.VendoredPackage{{Name: "monolog/monolog", Version: "3.5.0"}}
+
+		packages, err := parseComposerInstalled(`[{"name": "monolog/monolog", "version": "3.5.0"}]`)
+		require.NoError(t, err)
+		assert.Equal(t, expected, packages)
+
+		packages, err = parseComposerInstalled(`{"packages": [{"name": "monolog/monolog", "version": "3.5.0"}]}`)
+		require.NoError(t, err)
+		assert.Equal(t, expected, packages)
+	})
+
+	t.Run("should fail on invalid JSON", func(t *testing.T) {
+		_, err := parseNPMHiddenLockfile("{")
+		assert.ErrorContains(t, err, "failed to parse .package-lock.json")
+	})
+}
+
+func TestRepoProcessor_loadVendored(t *testing.T) {
+	tree := []models.RepositoryTree{
+		{Path: "main.go", Type: "blob"},
+		{Path: "vendor", Type: "tree"},
+		{Path: "vendor/modules.txt", Type: "blob"},
+		{Path: "web/node_modules/.package-lock.json", Type: "blob"},
+		{Path: "docs/vendor/modules.txt", Type: "blob"},
+	}
+
+	t.Run("should list the packages of excluded vendor directories", func(t *testing.T) {
+		mockProvider := &MockProvider{}
+		mockProvider.On("GetFileContent", mock.Anything, "owner/repo", "vendor/modules.txt", "main").Return("# github.com/pkg/errors v0.9.1\n", nil)
+		mockProvider.On("GetFileContent", mock.Anything, "owner/repo", "web/node_modules/.package-lock.json", "main").Return(`{"packages": {"node_modules/react": {"version": "18.2.0"}}}`, nil)
+		processor := NewRepoProcessor(mockProvider, models.ProcessingConfig{})
+
+		// docs/vendor/modules.txt is included, so its content is already in the output
+		filtered := []models.RepositoryTree{tree[0], tree[4]}
+		vendored := processor.loadVendored(context.Background(), "owner/repo", "main", tree, filtered)
+
+		assert.Equal(t, []models.VendoredDependencies{
+			{Path: "vendor", Ecosystem: "go", Packages: []models.VendoredPackage{{Name: "github.com/pkg/errors", Version: "v0.9.1"}}},
+			{Path: "web/node_modules", Ecosystem: "npm", Packages: []models.VendoredPackage{{Name: "react", Version: "18.2.0"}}},
+		}, vendored)
+		mockProvider.AssertExpectations(t)
+	})
+}
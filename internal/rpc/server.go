@@ -0,0 +1,329 @@
+// Package rpc serves sherpa to editor extensions over JSON-RPC 2.0, with one message per
+// line on standard input and output. Extensions list the profiles, generate outputs for
+// the open workspace while receiving progress notifications, and open the files written.
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/config"
+	"sherpa/internal/orchestration"
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+	"sherpa/pkg/ui"
+)
+
+// ProtocolVersion is the version of the methods and messages, increased on breaking changes
+const ProtocolVersion = 1
+
+// DefaultProfile generates outputs with the configuration file settings, without preset
+const DefaultProfile = "default"
+
+// maxMessageSize bounds a request line
+const maxMessageSize = 1024 * 1024
+
+// Error codes defined by JSON-RPC 2.0, and codeGenerationFailed for failed runs
+const (
+	codeParseError       = -32700
+	codeInvalidRequest   = -32600
+	codeMethodNotFound   = -32601
+	codeInvalidParams    = -32602
+	codeGenerationFailed = -32000
+)
+
+// Methods served, in the order listed by initialize
+var methods = []string{"initialize", "profiles/list", "generate", "shutdown"}
+
+// Server answers the requests of an editor extension
+type Server struct {
+	ConfigFile string // Configuration file, the defaults only when empty
+	Version    string // Version of sherpa reported by initialize
+
+	encoder *json.Encoder
+	mu      sync.Mutex // Serializes messages, since progress is sent from processing goroutines
+}
+
+// request is a JSON-RPC request, or a notification when it has no id
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response, with either a result or an error
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// notification is a message sent without being asked for, like progress
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// Error is a JSON-RPC error. Failed generations carry the kind and hint of the failure as data.
+type Error struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *ErrorData `json:"data,omitempty"`
+}
+
+// ErrorData classifies a failed generation, so extensions can suggest a remediation
+type ErrorData struct {
+	Kind string `json:"kind"`
+	Hint string `json:"hint,omitempty"`
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// InitializeResult describes the server to the extension
+type InitializeResult struct {
+	Name            string   `json:"name"`
+	Version         string   `json:"version"`
+	ProtocolVersion int      `json:"protocol_version"`
+	Methods         []string `json:"methods"`
+}
+
+// Profile is a set of output settings the extension can generate with
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// GenerateParams are the parameters of generate
+type GenerateParams struct {
+	Path    string `json:"path"`              // Workspace folder to generate outputs for
+	Profile string `json:"profile,omitempty"` // Profile name, DefaultProfile when empty
+	Output  string `json:"output,omitempty"`  // Output directory, relative to the workspace, or the configured one when empty
+}
+
+// GenerateResult lists the outputs written by generate
+type GenerateResult struct {
+	Directory string   `json:"directory"` // Output directory of the workspace
+	Outputs   []string `json:"outputs"`   // Absolute paths of the files written, sorted
+	Files     int      `json:"files"`     // Workspace files included
+	Tokens    int      `json:"tokens"`
+}
+
+// ProgressParams are the parameters of progress notifications, sent while generate runs
+type ProgressParams struct {
+	Request json.RawMessage `json:"request"` // Id of the generate request
+	orchestration.Progress
+}
+
+// Serve answers the requests read from in on out, one JSON message per line, until in is
+// closed, shutdown is requested or ctx is canceled. Requests are handled one at a time.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.encoder = json.NewEncoder(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			if err := s.send(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: codeParseError, Message: fmt.Sprintf("invalid JSON: %v", err)}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, rpcErr := s.handle(ctx, req)
+		if req.ID == nil {
+			// Notifications get no response, even when they fail
+			continue
+		}
+		resp := response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+		if rpcErr == nil && result == nil {
+			resp.Result = struct{}{}
+		}
+		if err := s.send(resp); err != nil {
+			return err
+		}
+		if req.Method == "shutdown" {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// handle dispatches a request to its method
+func (s *Server) handle(ctx context.Context, req request) (interface{}, *Error) {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return nil, &Error{Code: codeInvalidRequest, Message: "requests need jsonrpc \"2.0\" and a method"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{Name: "sherpa", Version: s.Version, ProtocolVersion: ProtocolVersion, Methods: methods}, nil
+	case "profiles/list":
+		return Profiles(), nil
+	case "generate":
+		var params GenerateParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, &Error{Code: codeInvalidParams, Message: fmt.Sprintf("invalid generate parameters: %v", err)}
+			}
+		}
+		result, err := s.generate(ctx, req.ID, params)
+		if err != nil {
+			// A nil result would be answered as null next to the error
+			return nil, err
+		}
+		return result, nil
+	case "shutdown":
+		return nil, nil
+	default:
+		return nil, &Error{Code: codeMethodNotFound, Message: fmt.Sprintf("unknown method '%s'. Valid options: %s", req.Method, strings.Join(methods, ", "))}
+	}
+}
+
+// Profiles lists the default profile and the --fit-for presets
+func Profiles() []Profile {
+	profiles := []Profile{{Name: DefaultProfile, Description: "Settings of the configuration file"}}
+	for _, name := range config.FitPresetNames() {
+		profiles = append(profiles, Profile{Name: name, Description: config.FitPresets[name].Description})
+	}
+	return profiles
+}
+
+// generate processes the workspace folder like sherpa <path> --fit-for <profile> would, and
+// sends the stages reached as progress notifications
+func (s *Server) generate(ctx context.Context, id json.RawMessage, params GenerateParams) (*GenerateResult, *Error) {
+	if params.Path == "" {
+		return nil, &Error{Code: codeInvalidParams, Message: "generate needs the path of the workspace"}
+	}
+	workspace, err := filepath.Abs(params.Path)
+	if err != nil {
+		return nil, &Error{Code: codeInvalidParams, Message: fmt.Sprintf("invalid workspace path: %v", err)}
+	}
+	repoInfo, err := adapters.ParseRepositoryURL(workspace, models.PlatformLocal)
+	if err != nil {
+		return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	profile := params.Profile
+	if profile == DefaultProfile {
+		profile = ""
+	}
+	if _, exists := config.FitPresets[profile]; profile != "" && !exists {
+		return nil, &Error{Code: codeInvalidParams, Message: fmt.Sprintf("unknown profile '%s'. Valid options: %s, %s", params.Profile, DefaultProfile, strings.Join(config.FitPresetNames(), ", "))}
+	}
+
+	loader := config.NewLoader()
+	cfg, err := loader.LoadConfig(s.ConfigFile)
+	if err != nil {
+		return nil, failure(fmt.Errorf("failed to load configuration: %w", err))
+	}
+
+	// Outputs are written to the workspace, which must not read them back on the next run
+	output := params.Output
+	if output == "" {
+		output = cfg.Output.Directory
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(workspace, output)
+	}
+	if rel, err := filepath.Rel(workspace, output); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		cfg.Processing.Ignore = append(cfg.Processing.Ignore, filepath.ToSlash(rel)+"/")
+	}
+	if cfg.Output.LockFile != "" && !filepath.IsAbs(cfg.Output.LockFile) {
+		cfg.Output.LockFile = filepath.Join(workspace, cfg.Output.LockFile)
+	}
+
+	cliOptions := &models.CLIOptions{Output: output, FitFor: profile, Quiet: true}
+	if err := loader.OverrideWithFlags(cfg, cliOptions); err != nil {
+		return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+	}
+	if err := loader.ValidateConfig(cfg); err != nil {
+		return nil, failure(fmt.Errorf("invalid configuration: %w", err))
+	}
+
+	theme, _ := ui.LookupTheme("plain")
+	orchestrator := orchestration.NewOrchestrator(cfg, cliOptions)
+	orchestrator.SetPrinter(ui.New(io.Discard, io.Discard, theme, false))
+	orchestrator.SetProgress(func(progress orchestration.Progress) {
+		// A closed output fails the response as well, so the error is reported there
+		_ = s.send(notification{JSONRPC: "2.0", Method: "progress", Params: ProgressParams{Request: id, Progress: progress}})
+	})
+
+	err = orchestrator.ProcessRepositories(ctx, map[models.Platform][]*models.RepositoryInfo{models.PlatformLocal: {repoInfo}})
+	if err == nil {
+		err = orchestrator.Err()
+	}
+	if err != nil {
+		return nil, failure(err)
+	}
+
+	summary := orchestrator.Summary()
+	if len(summary.Results) == 0 {
+		return nil, failure(fmt.Errorf("no output was generated for %s", workspace))
+	}
+	repoSummary := summary.Results[0]
+	outputs, err := listOutputs(repoSummary.Output)
+	if err != nil {
+		return nil, failure(err)
+	}
+	return &GenerateResult{Directory: repoSummary.Output, Outputs: outputs, Files: repoSummary.Files, Tokens: repoSummary.Tokens}, nil
+}
+
+// failure describes a failed generation with the kind and hint of err
+func failure(err error) *Error {
+	return &Error{
+		Code:    codeGenerationFailed,
+		Message: err.Error(),
+		Data:    &ErrorData{Kind: string(sherpaerrors.KindOf(err)), Hint: sherpaerrors.HintOf(err)},
+	}
+}
+
+// listOutputs returns the absolute paths of the files of an output directory, sorted
+func listOutputs(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outputs: %w", err)
+	}
+	var outputs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			outputs = append(outputs, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(outputs)
+	return outputs, nil
+}
+
+// send writes a message on its own line
+func (s *Server) send(message interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(message)
+}
@@ -0,0 +1,177 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sherpa/internal/orchestration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// message is a response or a notification written by the server
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+	Params json.RawMessage `json:"params"`
+}
+
+// serve sends requests to a server, one per line, and returns the messages it wrote
+func serve(t *testing.T, requests ...string) []message {
+	t.Helper()
+	// Keep the configuration of the user out of the tests
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	var out strings.Builder
+	server := &Server{Version: "1.2.3"}
+	require.NoError(t, server.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &out))
+
+	var messages []message
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var m message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &m), scanner.Text())
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+// workspace creates a folder with a few source files
+func workspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Workspace\n"), 0644))
+	return dir
+}
+
+// generateRequest returns a generate request for params
+func generateRequest(t *testing.T, id int, params GenerateParams) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": "generate", "params": params})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestServer_Methods(t *testing.T) {
+	t.Run("should describe the server and its methods", func(t *testing.T) {
+		messages := serve(t, `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+		require.Len(t, messages, 1)
+
+		var result InitializeResult
+		require.NoError(t, json.Unmarshal(messages[0].Result, &result))
+		assert.Equal(t, "1.2.3", result.Version)
+		assert.Equal(t, ProtocolVersion, result.ProtocolVersion)
+		assert.Contains(t, result.Methods, "generate")
+	})
+
+	t.Run("should list the default profile and the presets", func(t *testing.T) {
+		messages := serve(t, `{"jsonrpc":"2.0","id":"profiles","method":"profiles/list"}`)
+		require.Len(t, messages, 1)
+		assert.JSONEq(t, `"profiles"`, string(messages[0].ID))
+
+		var profiles []Profile
+		require.NoError(t, json.Unmarshal(messages[0].Result, &profiles))
+		require.NotEmpty(t, profiles)
+		assert.Equal(t, DefaultProfile, profiles[0].Name)
+		names := make([]string, 0, len(profiles))
+		for _, profile := range profiles {
+			names = append(names, profile.Name)
+		}
+		assert.Contains(t, names, "cursor")
+	})
+
+	t.Run("should answer errors and keep serving", func(t *testing.T) {
+		messages := serve(t,
+			`not json`,
+			`{"jsonrpc":"2.0","id":2,"method":"unknown"}`,
+			`{"id":3,"method":"initialize"}`,
+			`{"jsonrpc":"2.0","method":"unknown"}`,
+			`{"jsonrpc":"2.0","id":4,"method":"initialize"}`,
+		)
+		require.Len(t, messages, 4)
+		assert.Equal(t, codeParseError, messages[0].Error.Code)
+		assert.Equal(t, codeMethodNotFound, messages[1].Error.Code)
+		assert.Equal(t, codeInvalidRequest, messages[2].Error.Code)
+		assert.Nil(t, messages[3].Error)
+	})
+
+	t.Run("should stop serving at shutdown", func(t *testing.T) {
+		messages := serve(t,
+			`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`,
+			`{"jsonrpc":"2.0","id":2,"method":"initialize"}`,
+		)
+		require.Len(t, messages, 1)
+		assert.JSONEq(t, `{}`, string(messages[0].Result))
+	})
+}
+
+func TestServer_Generate(t *testing.T) {
+	t.Run("should report progress and return the outputs written", func(t *testing.T) {
+		dir := workspace(t)
+		messages := serve(t, generateRequest(t, 7, GenerateParams{Path: dir, Profile: "cursor"}))
+		require.NotEmpty(t, messages)
+
+		var stages []string
+		for _, m := range messages[:len(messages)-1] {
+			assert.Equal(t, "progress", m.Method)
+			var progress ProgressParams
+			require.NoError(t, json.Unmarshal(m.Params, &progress))
+			assert.JSONEq(t, `7`, string(progress.Request))
+			stages = append(stages, progress.Stage)
+		}
+		assert.Equal(t, []string{orchestration.ProgressFetching, orchestration.ProgressWriting, orchestration.ProgressDone}, stages)
+
+		response := messages[len(messages)-1]
+		require.Nil(t, response.Error)
+		var result GenerateResult
+		require.NoError(t, json.Unmarshal(response.Result, &result))
+		assert.Equal(t, 2, result.Files)
+		assert.True(t, strings.HasPrefix(result.Directory, filepath.Join(dir, "sherpa-output")), result.Directory)
+		assert.Contains(t, result.Outputs, filepath.Join(result.Directory, "llms-full.txt"))
+		for _, output := range result.Outputs {
+			assert.FileExists(t, output)
+		}
+	})
+
+	t.Run("should not read back the outputs written to the workspace", func(t *testing.T) {
+		dir := workspace(t)
+		messages := serve(t,
+			generateRequest(t, 1, GenerateParams{Path: dir}),
+			generateRequest(t, 2, GenerateParams{Path: dir}),
+		)
+
+		var result GenerateResult
+		require.NoError(t, json.Unmarshal(messages[len(messages)-1].Result, &result))
+		assert.Equal(t, 2, result.Files)
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			params GenerateParams
+		}{
+			{name: "missing path", params: GenerateParams{}},
+			{name: "missing folder", params: GenerateParams{Path: filepath.Join(t.TempDir(), "missing")}},
+			{name: "unknown profile", params: GenerateParams{Path: t.TempDir(), Profile: "unknown"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				messages := serve(t, generateRequest(t, 1, tt.params))
+				require.Len(t, messages, 1)
+				require.NotNil(t, messages[0].Error)
+				assert.Equal(t, codeInvalidParams, messages[0].Error.Code)
+				assert.Empty(t, messages[0].Result)
+			})
+		}
+	})
+}
@@ -0,0 +1,372 @@
+// Package session records the HTTP traffic sent to platforms and package registries to a tar
+// archive, and replays it without network access, so bug reports can be reproduced offline
+// and regression tests can run against captured real-world responses.
+package session
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"sherpa/internal/httpdebug"
+)
+
+// Version is the format version written to new sessions
+const Version = 1
+
+// manifestName is the first entry of a session archive
+const manifestName = "session.json"
+
+// ErrNotRecorded is returned when replaying a request missing from the session
+var ErrNotRecorded = errors.New("no recorded response")
+
+// droppedHeaders are response headers never recorded, since they can carry credentials
+var droppedHeaders = []string{"Set-Cookie"}
+
+// Manifest describes a session archive
+type Manifest struct {
+	Version    int       `json:"version"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Exchange is a recorded request with its response. Request headers are not recorded, so
+// tokens never end up in a session, and secrets in URLs are redacted.
+type Exchange struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestHash string      `json:"request_hash,omitempty"` // SHA-256 of the request body, empty without body
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Error       string      `json:"error,omitempty"` // Set when the request failed without a response
+}
+
+// key identifies the requests an exchange answers
+func (e Exchange) key() string {
+	return e.Method + " " + e.URL + " " + e.RequestHash
+}
+
+// Recorder writes every request sent through its transport with the response to a session
+type Recorder struct {
+	tw     *tar.Writer
+	closer io.Closer
+	count  int
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// Create creates the session file at path and starts recording to it
+func Create(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session file: %w", err)
+	}
+	recorder, err := newRecorder(file, file, time.Now)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// NewRecorder starts recording a session to w
+func NewRecorder(w io.Writer) (*Recorder, error) {
+	return newRecorder(w, nil, time.Now)
+}
+
+// newRecorder writes the manifest of a new session to w
+func newRecorder(w io.Writer, closer io.Closer, now func() time.Time) (*Recorder, error) {
+	recorder := &Recorder{tw: tar.NewWriter(w), closer: closer, now: now}
+	data, err := json.Marshal(Manifest{Version: Version, RecordedAt: now().UTC().Truncate(time.Second)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session manifest: %w", err)
+	}
+	if err := recorder.writeEntry(manifestName, data); err != nil {
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// Len returns the number of recorded exchanges
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// Close finishes the session archive, and closes its file when created with Create
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.tw.Close()
+	if r.closer != nil {
+		if closeErr := r.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// Transport wraps base so requests are recorded, using http.DefaultTransport when base is nil
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordingTransport{recorder: r, base: base}
+}
+
+// record writes an exchange and its response body
+func (r *Recorder) record(exchange Exchange, body []byte) error {
+	data, err := json.Marshal(exchange)
+	if err != nil {
+		return fmt.Errorf("failed to encode exchange: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count++
+	name := fmt.Sprintf("exchanges/%06d", r.count)
+	if err := r.writeEntry(name+".json", data); err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		return r.writeEntry(name+".body", body)
+	}
+	return nil
+}
+
+// writeEntry adds a file to the archive
+func (r *Recorder) writeEntry(name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: r.now()}
+	if err := r.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write session entry %s: %w", name, err)
+	}
+	if _, err := r.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write session entry %s: %w", name, err)
+	}
+	return nil
+}
+
+// recordingTransport is an http.RoundTripper recording requests and their responses
+type recordingTransport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+// RoundTrip sends the request and records it. The whole response body is read before the
+// response is returned, so it can be recorded.
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange, err := newExchange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		exchange.Error = err.Error()
+		if recordErr := t.recorder.record(exchange, nil); recordErr != nil {
+			return nil, errors.Join(err, recordErr)
+		}
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	exchange.Status = resp.StatusCode
+	exchange.Header = resp.Header.Clone()
+	for _, name := range droppedHeaders {
+		exchange.Header.Del(name)
+	}
+	if err := t.recorder.record(exchange, body); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// newExchange describes a request, hashing its body
+func newExchange(req *http.Request) (Exchange, error) {
+	exchange := Exchange{Method: req.Method, URL: httpdebug.SanitizeURL(req.URL)}
+	if req.Body == nil || req.Body == http.NoBody {
+		return exchange, nil
+	}
+
+	body := req.Body
+	if req.GetBody != nil {
+		var err error
+		if body, err = req.GetBody(); err != nil {
+			return exchange, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return exchange, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if req.GetBody == nil {
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	if len(data) > 0 {
+		sum := sha256.Sum256(data)
+		exchange.RequestHash = hex.EncodeToString(sum[:])
+	}
+	return exchange, nil
+}
+
+// replayedResponse is a recorded response served by a replayer
+type replayedResponse struct {
+	exchange Exchange
+	body     []byte
+}
+
+// Replayer answers requests with the responses of a recorded session, without network access.
+// Requests are matched by method, URL and body. Identical requests get the recorded responses
+// in order, the last one being repeated once they are exhausted.
+type Replayer struct {
+	responses map[string][]replayedResponse
+	served    map[string]int
+	mu        sync.Mutex
+}
+
+// Open reads the session file at path
+func Open(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session file: %w", err)
+	}
+	defer file.Close()
+	return Load(file)
+}
+
+// Load reads a session archive
+func Load(r io.Reader) (*Replayer, error) {
+	replayer := &Replayer{responses: make(map[string][]replayedResponse), served: make(map[string]int)}
+	tr := tar.NewReader(r)
+
+	var manifest *Manifest
+	var pending *replayedResponse
+	flush := func() {
+		if pending != nil {
+			key := pending.exchange.key()
+			replayer.responses[key] = append(replayer.responses[key], *pending)
+			pending = nil
+		}
+	}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session entry %s: %w", header.Name, err)
+		}
+
+		switch {
+		case header.Name == manifestName:
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("failed to parse session manifest: %w", err)
+			}
+			if manifest.Version > Version {
+				return nil, fmt.Errorf("unsupported session version %d (max: %d)", manifest.Version, Version)
+			}
+		case strings.HasSuffix(header.Name, ".json"):
+			flush()
+			pending = &replayedResponse{}
+			if err := json.Unmarshal(data, &pending.exchange); err != nil {
+				return nil, fmt.Errorf("failed to parse session entry %s: %w", header.Name, err)
+			}
+		case strings.HasSuffix(header.Name, ".body") && pending != nil:
+			pending.body = data
+		}
+	}
+	flush()
+
+	if manifest == nil {
+		return nil, fmt.Errorf("not a session archive: %s is missing", manifestName)
+	}
+	return replayer, nil
+}
+
+// Transport returns a transport answering requests from the session
+func (r *Replayer) Transport() http.RoundTripper {
+	return &replayingTransport{replayer: r}
+}
+
+// next returns the response to serve for a request, and whether one was recorded
+func (r *Replayer) next(key string) (replayedResponse, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	responses := r.responses[key]
+	if len(responses) == 0 {
+		return replayedResponse{}, false
+	}
+	i := r.served[key]
+	if i >= len(responses) {
+		i = len(responses) - 1
+	}
+	r.served[key]++
+	return responses[i], true
+}
+
+// replayingTransport is an http.RoundTripper serving recorded responses
+type replayingTransport struct {
+	replayer *Replayer
+}
+
+// RoundTrip returns the recorded response of the request, or its recorded error
+func (t *replayingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange, err := newExchange(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	recorded, found := t.replayer.next(exchange.key())
+	if !found {
+		return nil, fmt.Errorf("%s %s: %w", exchange.Method, exchange.URL, ErrNotRecorded)
+	}
+	if recorded.exchange.Error != "" {
+		return nil, errors.New(recorded.exchange.Error)
+	}
+
+	header := recorded.exchange.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.exchange.Status, http.StatusText(recorded.exchange.Status)),
+		StatusCode:    recorded.exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(recorded.body)),
+		ContentLength: int64(len(recorded.body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,163 @@
+package session
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// get sends a GET request through transport and returns the response body
+func get(t *testing.T, transport http.RoundTripper, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestRecorderAndReplayer(t *testing.T) {
+	t.Run("should replay recorded responses without the server", func(t *testing.T) {
+		hits := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("hello from " + r.URL.Path))
+		}))
+
+		var archive bytes.Buffer
+		recorder, err := NewRecorder(&archive)
+		require.NoError(t, err)
+		resp, body := get(t, recorder.Transport(nil), server.URL+"/repos/owner/repo?per_page=100")
+		assert.Equal(t, "hello from /repos/owner/repo", body, "the response should still be read while recording")
+		assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+		require.NoError(t, recorder.Close())
+		assert.Equal(t, 1, recorder.Len())
+		server.Close()
+
+		replayer, err := Load(&archive)
+		require.NoError(t, err)
+		resp, body = get(t, replayer.Transport(), server.URL+"/repos/owner/repo?per_page=100")
+		assert.Equal(t, "hello from /repos/owner/repo", body)
+		assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+		assert.Equal(t, "4999", resp.Header.Get("X-RateLimit-Remaining"))
+		assert.Empty(t, resp.Header.Get("Set-Cookie"), "cookies should not be recorded")
+		assert.Equal(t, 1, hits)
+	})
+
+	t.Run("should serve identical requests in recorded order, repeating the last response", func(t *testing.T) {
+		count := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count++
+			w.Write([]byte(strings.Repeat("x", count)))
+		}))
+		defer server.Close()
+
+		var archive bytes.Buffer
+		recorder, err := NewRecorder(&archive)
+		require.NoError(t, err)
+		get(t, recorder.Transport(nil), server.URL)
+		get(t, recorder.Transport(nil), server.URL)
+		require.NoError(t, recorder.Close())
+
+		replayer, err := Load(&archive)
+		require.NoError(t, err)
+		var bodies []string
+		for i := 0; i < 3; i++ {
+			_, body := get(t, replayer.Transport(), server.URL)
+			bodies = append(bodies, body)
+		}
+		assert.Equal(t, []string{"x", "xx", "xx"}, bodies)
+	})
+
+	t.Run("should match requests by body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(w, r.Body)
+		}))
+		defer server.Close()
+
+		var archive bytes.Buffer
+		recorder, err := NewRecorder(&archive)
+		require.NoError(t, err)
+		client := &http.Client{Transport: recorder.Transport(nil)}
+		for _, query := range []string{"query A", "query B"} {
+			resp, err := client.Post(server.URL+"/graphql", "text/plain", strings.NewReader(query))
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+		require.NoError(t, recorder.Close())
+
+		replayer, err := Load(&archive)
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: replayer.Transport()}).Post(server.URL+"/graphql", "text/plain", strings.NewReader("query B"))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "query B", string(body))
+	})
+
+	t.Run("should redact secrets in recorded URLs", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		var archive bytes.Buffer
+		recorder, err := NewRecorder(&archive)
+		require.NoError(t, err)
+		get(t, recorder.Transport(nil), server.URL+"/projects?private_token=glpat-secret")
+		require.NoError(t, recorder.Close())
+
+		assert.NotContains(t, archive.String(), "glpat-secret")
+	})
+
+	t.Run("should fail requests missing from the session", func(t *testing.T) {
+		var archive bytes.Buffer
+		recorder, err := NewRecorder(&archive)
+		require.NoError(t, err)
+		require.NoError(t, recorder.Close())
+
+		replayer, err := Load(&archive)
+		require.NoError(t, err)
+		_, err = (&http.Client{Transport: replayer.Transport()}).Get("https://api.github.com/repos/owner/repo")
+		assert.ErrorIs(t, err, ErrNotRecorded)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	archive := func(name, content string) io.Reader {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		return &buf
+	}
+
+	tests := []struct {
+		name     string
+		input    io.Reader
+		expected string
+	}{
+		{name: "should reject archives without manifest", input: archive("notes.txt", "hello"), expected: "not a session archive"},
+		{name: "should reject newer session versions", input: archive(manifestName, `{"version": 99}`), expected: "unsupported session version"},
+		{name: "should reject files that are not archives", input: strings.NewReader("not a tar file at all"), expected: "failed to read session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
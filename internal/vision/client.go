@@ -0,0 +1,124 @@
+// Package vision describes images with a vision-capable model served behind an
+// OpenAI-compatible chat completions endpoint, like the ones of OpenAI, Ollama or vLLM
+package vision
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+)
+
+// maxDescriptionTokens bounds the length of the descriptions requested from the model
+const maxDescriptionTokens = 100
+
+// prompt asks the model for alt text of an image of a repository
+const prompt = "Write one line of alt text, under 25 words, for the image %s from the documentation of a software repository. For diagrams, say what they show. Reply with the alt text only."
+
+// Client describes images with a model of an OpenAI-compatible chat completions endpoint
+type Client struct {
+	endpoint   string
+	model      string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the endpoint and model of config, sending apiKey as a
+// bearer token when set, and giving up on an image after config.Timeout
+func NewClient(config models.AltTextConfig, apiKey string) *Client {
+	return &Client{
+		endpoint:   config.Endpoint,
+		model:      config.Model,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// chatRequest is the body of a chat completions request
+type chatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	MaxTokens int           `json:"max_tokens"`
+}
+
+// chatMessage is a message of a chat completions request, with text and image parts
+type chatMessage struct {
+	Role    string        `json:"role"`
+	Content []contentPart `json:"content"`
+}
+
+// contentPart is the text or the image of a message
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+// imageURL holds an image as a data URL
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+// chatResponse is the part of a chat completions response holding the reply
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// DescribeImage returns a one-line description of image, found at name in the repository.
+// mediaType is the type of the image, like image/png.
+func (c *Client) DescribeImage(ctx context.Context, name, mediaType string, image []byte) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{{
+			Role: "user",
+			Content: []contentPart{
+				{Type: "text", Text: fmt.Sprintf(prompt, name)},
+				{Type: "image_url", ImageURL: &imageURL{URL: "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(image)}},
+			},
+		}},
+		MaxTokens: maxDescriptionTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode alt text request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create alt text request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to describe %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", sherpaerrors.FromStatus(resp.StatusCode, fmt.Errorf("failed to describe %s: %s: %s", name, resp.Status, strings.TrimSpace(string(message))))
+	}
+
+	var reply chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("failed to decode the description of %s: %w", name, err)
+	}
+	if len(reply.Choices) == 0 || strings.TrimSpace(reply.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("no description returned for %s", name)
+	}
+	return reply.Choices[0].Message.Content, nil
+}
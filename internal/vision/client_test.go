@@ -0,0 +1,62 @@
+package vision
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sherpaerrors "sherpa/pkg/errors"
+	"sherpa/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DescribeImage(t *testing.T) {
+	t.Run("should send the image to the model and return its reply", func(t *testing.T) {
+		var request chatRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Sequence diagram of a card payment"}}]}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(models.AltTextConfig{Endpoint: server.URL, Model: "llava", Timeout: time.Minute}, "secret")
+		description, err := client.DescribeImage(context.Background(), "docs/flow.png", "image/png", []byte{0x89, 'P', 'N', 'G'})
+		require.NoError(t, err)
+		assert.Equal(t, "Sequence diagram of a card payment", description)
+
+		assert.Equal(t, "llava", request.Model)
+		require.Len(t, request.Messages, 1)
+		require.Len(t, request.Messages[0].Content, 2)
+		assert.Contains(t, request.Messages[0].Content[0].Text, "docs/flow.png")
+		assert.Equal(t, "data:image/png;base64,iVBORw==", request.Messages[0].Content[1].ImageURL.URL)
+	})
+
+	t.Run("should classify failed requests", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		client := NewClient(models.AltTextConfig{Endpoint: server.URL, Model: "llava"}, "")
+		_, err := client.DescribeImage(context.Background(), "docs/flow.png", "image/png", []byte("image"))
+		assert.ErrorContains(t, err, "invalid api key")
+		assert.Equal(t, sherpaerrors.KindAuth, sherpaerrors.KindOf(err))
+	})
+
+	t.Run("should fail without a description", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"choices":[]}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(models.AltTextConfig{Endpoint: server.URL, Model: "llava"}, "")
+		_, err := client.DescribeImage(context.Background(), "docs/flow.png", "image/png", []byte("image"))
+		assert.ErrorContains(t, err, "no description returned for docs/flow.png")
+	})
+}
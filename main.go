@@ -6,10 +6,11 @@ import (
 
 	"github.com/charmbracelet/fang"
 	"sherpa/cmd"
+	sherpaerrors "sherpa/pkg/errors"
 )
 
 func main() {
 	if err := fang.Execute(context.TODO(), cmd.RootCmd); err != nil {
-		os.Exit(1)
+		os.Exit(sherpaerrors.ExitCode(err))
 	}
 }
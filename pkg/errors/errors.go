@@ -0,0 +1,157 @@
+package errors
+
+import (
+	stderrors "errors"
+	"net/http"
+)
+
+// Kind classifies an error by its cause
+type Kind string
+
+// Error kinds
+const (
+	KindUnknown       Kind = "unknown"
+	KindAuth          Kind = "auth"           // Missing, invalid or insufficient credentials
+	KindNotFound      Kind = "not_found"      // Repository, ref or file does not exist
+	KindRateLimited   Kind = "rate_limited"   // Platform rate or abuse limit reached
+	KindTooLarge      Kind = "too_large"      // Content exceeds a size or budget limit
+	KindBinarySkipped Kind = "binary_skipped" // Binary content that is intentionally not included
+)
+
+// Exit codes returned by the CLI for each kind of failure
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitAuth        = 3
+	ExitNotFound    = 4
+	ExitRateLimited = 5
+	ExitTooLarge    = 6
+)
+
+// Sentinel errors matching any Error of the same kind with errors.Is
+var (
+	ErrAuth          = &Error{Kind: KindAuth}
+	ErrNotFound      = &Error{Kind: KindNotFound}
+	ErrRateLimited   = &Error{Kind: KindRateLimited}
+	ErrTooLarge      = &Error{Kind: KindTooLarge}
+	ErrBinarySkipped = &Error{Kind: KindBinarySkipped}
+)
+
+// Error is a classified error wrapping its underlying cause
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+	Hint    string // Remediation shown to users, like the scope a token lacks
+}
+
+// New creates a classified error with a message
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap classifies an existing error, returning nil when err is nil
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// WithHint attaches a remediation hint to err, keeping its kind. It returns err unchanged
+// when hint is empty.
+func WithHint(err error, hint string) error {
+	if err == nil || hint == "" {
+		return err
+	}
+	return &Error{Kind: KindOf(err), Err: err, Hint: hint}
+}
+
+// HintOf returns the first remediation hint in the chain, or an empty string without one
+func HintOf(err error) string {
+	for err != nil {
+		var classified *Error
+		if !stderrors.As(err, &classified) {
+			return ""
+		}
+		if classified.Hint != "" {
+			return classified.Hint
+		}
+		err = classified.Err
+	}
+	return ""
+}
+
+// Error returns the message followed by the wrapped error
+func (e *Error) Error() string {
+	switch {
+	case e.Message != "" && e.Err != nil:
+		return e.Message + ": " + e.Err.Error()
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	default:
+		return string(e.Kind)
+	}
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind so sentinels work with errors.Is
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind
+}
+
+// KindOf returns the kind of the first classified error in the chain
+func KindOf(err error) Kind {
+	var classified *Error
+	if stderrors.As(err, &classified) {
+		return classified.Kind
+	}
+	return KindUnknown
+}
+
+// FromStatus classifies an error using the HTTP status code of the response that caused it
+func FromStatus(status int, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return Wrap(KindAuth, err)
+	case http.StatusNotFound:
+		return Wrap(KindNotFound, err)
+	case http.StatusTooManyRequests:
+		return Wrap(KindRateLimited, err)
+	case http.StatusRequestEntityTooLarge:
+		return Wrap(KindTooLarge, err)
+	default:
+		return err
+	}
+}
+
+// ExitCode maps an error to the CLI exit code of its kind
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	switch KindOf(err) {
+	case KindAuth:
+		return ExitAuth
+	case KindNotFound:
+		return ExitNotFound
+	case KindRateLimited:
+		return ExitRateLimited
+	case KindTooLarge:
+		return ExitTooLarge
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,123 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	t.Run("should format message and cause", func(t *testing.T) {
+		err := &Error{Kind: KindNotFound, Message: "repository missing", Err: fmt.Errorf("404")}
+		assert.Equal(t, "repository missing: 404", err.Error())
+	})
+
+	t.Run("should fall back to the kind without message or cause", func(t *testing.T) {
+		assert.Equal(t, "auth", (&Error{Kind: KindAuth}).Error())
+	})
+
+	t.Run("should match sentinels of the same kind through wrapping", func(t *testing.T) {
+		err := fmt.Errorf("failed to fetch file: %w", Wrap(KindRateLimited, fmt.Errorf("429")))
+
+		assert.True(t, stderrors.Is(err, ErrRateLimited))
+		assert.False(t, stderrors.Is(err, ErrNotFound))
+	})
+
+	t.Run("should return nil when wrapping nil", func(t *testing.T) {
+		assert.Nil(t, Wrap(KindAuth, nil))
+	})
+}
+
+func TestWithHint(t *testing.T) {
+	t.Run("should keep the kind and message of the error", func(t *testing.T) {
+		err := WithHint(Wrap(KindAuth, fmt.Errorf("401 Bad credentials")), "create a new token")
+
+		assert.Equal(t, KindAuth, KindOf(err))
+		assert.True(t, stderrors.Is(err, ErrAuth))
+		assert.Equal(t, "401 Bad credentials", err.Error())
+	})
+
+	t.Run("should find the hint through wrapping", func(t *testing.T) {
+		err := fmt.Errorf("failed to fetch repository: %w", WithHint(Wrap(KindNotFound, fmt.Errorf("404")), "check the name"))
+		assert.Equal(t, "check the name", HintOf(err))
+	})
+
+	t.Run("should return the error unchanged without a hint", func(t *testing.T) {
+		cause := Wrap(KindAuth, fmt.Errorf("401"))
+		assert.Same(t, cause, WithHint(cause, ""))
+		assert.Nil(t, WithHint(nil, "hint"))
+	})
+
+	t.Run("should return an empty hint when none is attached", func(t *testing.T) {
+		assert.Empty(t, HintOf(Wrap(KindAuth, fmt.Errorf("401"))))
+		assert.Empty(t, HintOf(fmt.Errorf("boom")))
+		assert.Empty(t, HintOf(nil))
+	})
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected Kind
+	}{
+		{name: "should classify wrapped errors", err: fmt.Errorf("ctx: %w", New(KindTooLarge, "budget")), expected: KindTooLarge},
+		{name: "should return unknown for plain errors", err: fmt.Errorf("boom"), expected: KindUnknown},
+		{name: "should return unknown for nil", err: nil, expected: KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, KindOf(tt.err))
+		})
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	cause := fmt.Errorf("request failed")
+
+	tests := []struct {
+		name     string
+		status   int
+		expected Kind
+	}{
+		{name: "should classify 401 as auth", status: http.StatusUnauthorized, expected: KindAuth},
+		{name: "should classify 403 as auth", status: http.StatusForbidden, expected: KindAuth},
+		{name: "should classify 404 as not found", status: http.StatusNotFound, expected: KindNotFound},
+		{name: "should classify 429 as rate limited", status: http.StatusTooManyRequests, expected: KindRateLimited},
+		{name: "should classify 413 as too large", status: http.StatusRequestEntityTooLarge, expected: KindTooLarge},
+		{name: "should leave server errors unclassified", status: http.StatusInternalServerError, expected: KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromStatus(tt.status, cause)
+			assert.Equal(t, tt.expected, KindOf(err))
+			assert.True(t, stderrors.Is(err, cause))
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "should succeed without error", err: nil, expected: ExitOK},
+		{name: "should map auth errors", err: New(KindAuth, "bad token"), expected: ExitAuth},
+		{name: "should map not found errors", err: New(KindNotFound, "missing"), expected: ExitNotFound},
+		{name: "should map rate limit errors", err: New(KindRateLimited, "slow down"), expected: ExitRateLimited},
+		{name: "should map too large errors", err: New(KindTooLarge, "budget"), expected: ExitTooLarge},
+		{name: "should use generic failure otherwise", err: fmt.Errorf("boom"), expected: ExitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExitCode(tt.err))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WarningBudget is how many times an identical warning is logged. Further occurrences are
+// only counted, and summarized by FlushRepeated.
+const WarningBudget = 3
+
+// dedupFormatter formats entries with its Formatter, dropping warnings whose message was
+// already logged WarningBudget times. Every warning is logged at debug verbosity and above.
+type dedupFormatter struct {
+	logrus.Formatter
+	counts map[string]int
+	order  []string // Messages in the order they were first logged
+	mu     sync.Mutex
+}
+
+// newDedupFormatter wraps formatter
+func newDedupFormatter(formatter logrus.Formatter) *dedupFormatter {
+	return &dedupFormatter{Formatter: formatter, counts: make(map[string]int)}
+}
+
+// Format formats the entry, or returns nothing for a warning over its budget
+func (f *dedupFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level != logrus.WarnLevel || debugEnabled(entry.Logger) {
+		return f.Formatter.Format(entry)
+	}
+
+	f.mu.Lock()
+	if f.counts[entry.Message] == 0 {
+		f.order = append(f.order, entry.Message)
+	}
+	f.counts[entry.Message]++
+	count := f.counts[entry.Message]
+	f.mu.Unlock()
+
+	if count > WarningBudget {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// repeated returns the warnings logged more than WarningBudget times with the number of
+// occurrences dropped, and resets the counts
+func (f *dedupFormatter) repeated() ([]string, []int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var messages []string
+	var dropped []int
+	for _, message := range f.order {
+		if count := f.counts[message]; count > WarningBudget {
+			messages = append(messages, message)
+			dropped = append(dropped, count-WarningBudget)
+		}
+	}
+	f.counts = make(map[string]int)
+	f.order = nil
+	return messages, dropped
+}
+
+// FlushRepeated logs one summary line for each warning that went over its budget, with
+// the number of occurrences left out, like at the end of a run
+func FlushRepeated() {
+	formatter, ok := Logger.Formatter.(*dedupFormatter)
+	if !ok {
+		return
+	}
+	messages, dropped := formatter.repeated()
+	for i, message := range messages {
+		Logger.Warn(fmt.Sprintf("%s (repeated %d more times)", message, dropped[i]))
+	}
+}
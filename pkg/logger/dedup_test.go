@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupFormatter(t *testing.T) {
+	setup := func(level logrus.Level) *bytes.Buffer {
+		Logger = logrus.New()
+		SetColors(false)
+		Logger.SetLevel(level)
+		var buf bytes.Buffer
+		Logger.SetOutput(&buf)
+		return &buf
+	}
+
+	t.Run("should log identical warnings up to the budget and summarize the rest", func(t *testing.T) {
+		buf := setup(logrus.InfoLevel)
+		for i := 0; i < 10; i++ {
+			Logger.WithField("file", i).Warn("Skipping binary file")
+		}
+		Logger.Warn("Could not list issues")
+		assert.Equal(t, WarningBudget+1, strings.Count(buf.String(), "\n"))
+
+		buf.Reset()
+		FlushRepeated()
+		assert.Contains(t, buf.String(), "Skipping binary file (repeated 7 more times)")
+		assert.NotContains(t, buf.String(), "Could not list issues", "warnings within the budget should not be summarized")
+	})
+
+	t.Run("should start a new budget after flushing", func(t *testing.T) {
+		buf := setup(logrus.InfoLevel)
+		for i := 0; i < 5; i++ {
+			Logger.Warn("Rate limited")
+		}
+		FlushRepeated()
+
+		buf.Reset()
+		Logger.Warn("Rate limited")
+		assert.Contains(t, buf.String(), "Rate limited")
+	})
+
+	t.Run("should not deduplicate other levels", func(t *testing.T) {
+		buf := setup(logrus.InfoLevel)
+		for i := 0; i < 10; i++ {
+			Logger.Info("Processing repository")
+		}
+		assert.Equal(t, 10, strings.Count(buf.String(), "\n"))
+	})
+
+	t.Run("should log every warning at debug verbosity", func(t *testing.T) {
+		buf := setup(logrus.DebugLevel)
+		for i := 0; i < 10; i++ {
+			Logger.Warn("Skipping binary file")
+		}
+		assert.Equal(t, 10, strings.Count(buf.String(), "\n"))
+
+		buf.Reset()
+		FlushRepeated()
+		assert.Empty(t, buf.String())
+	})
+}
@@ -3,6 +3,8 @@ package logger
 import (
 	"os"
 
+	"sherpa/pkg/ui"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -11,23 +13,31 @@ var Logger *logrus.Logger
 func init() {
 	Logger = logrus.New()
 
-	// Set output to stdout
-	Logger.SetOutput(os.Stdout)
+	// Logs go to stderr, leaving stdout to the messages of the ui package
+	Logger.SetOutput(os.Stderr)
 
 	// Set default log level to Info
 	Logger.SetLevel(logrus.InfoLevel)
 
-	// Use colored text formatter for better readability
-	Logger.SetFormatter(&logrus.TextFormatter{
-		ForceColors:     true,
+	// Color levels only where colors are allowed, keeping CI logs free of escape sequences
+	SetColors(ui.ColorEnabled(os.Stderr))
+}
+
+// SetColors enables or disables colored log levels
+func SetColors(enabled bool) {
+	Logger.SetFormatter(newDedupFormatter(&logrus.TextFormatter{
+		ForceColors:     enabled,
+		DisableColors:   !enabled,
 		FullTimestamp:   true,
 		TimestampFormat: "2006-01-02 15:04:05",
-	})
+	}))
 }
 
 // SetLevel sets the logging level
 func SetLevel(level string) {
 	switch level {
+	case "trace":
+		Logger.SetLevel(logrus.TraceLevel)
 	case "debug":
 		Logger.SetLevel(logrus.DebugLevel)
 	case "info":
@@ -50,3 +60,18 @@ func SetQuiet() {
 func SetVerbose() {
 	Logger.SetLevel(logrus.DebugLevel)
 }
+
+// SetVerbosity sets the logging level from the number of -v flags: warnings and errors
+// without any, then info, debug and trace, which adds a line for every fetched file
+func SetVerbosity(verbosity int) {
+	switch {
+	case verbosity <= 0:
+		Logger.SetLevel(logrus.WarnLevel)
+	case verbosity == 1:
+		Logger.SetLevel(logrus.InfoLevel)
+	case verbosity == 2:
+		Logger.SetLevel(logrus.DebugLevel)
+	default:
+		Logger.SetLevel(logrus.TraceLevel)
+	}
+}
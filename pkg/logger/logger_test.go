@@ -24,6 +24,29 @@ func TestSetQuiet(t *testing.T) {
 	assert.Equal(t, logrus.ErrorLevel, Logger.Level)
 }
 
+func TestSetVerbosity(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity int
+		expected  logrus.Level
+	}{
+		{name: "should log warnings without -v", verbosity: 0, expected: logrus.WarnLevel},
+		{name: "should log info with -v", verbosity: 1, expected: logrus.InfoLevel},
+		{name: "should log debug with -vv", verbosity: 2, expected: logrus.DebugLevel},
+		{name: "should log trace with -vvv", verbosity: 3, expected: logrus.TraceLevel},
+		{name: "should cap the level at trace", verbosity: 5, expected: logrus.TraceLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Logger = logrus.New()
+
+			SetVerbosity(tt.verbosity)
+			assert.Equal(t, tt.expected, Logger.Level)
+		})
+	}
+}
+
 func TestLoggerOutput(t *testing.T) {
 	// Reset logger state
 	Logger = logrus.New()
@@ -103,3 +126,25 @@ func TestLoggerError(t *testing.T) {
 		assert.Contains(t, output, testErr.Error())
 	})
 }
+
+func TestSetColors(t *testing.T) {
+	// Reset logger state
+	Logger = logrus.New()
+	var buf bytes.Buffer
+	Logger.SetOutput(&buf)
+
+	t.Run("should write levels without escape sequences when disabled", func(t *testing.T) {
+		SetColors(false)
+		buf.Reset()
+		Logger.Warn("plain message")
+		assert.Contains(t, buf.String(), "level=warning")
+		assert.NotContains(t, buf.String(), "\x1b[")
+	})
+
+	t.Run("should color levels when enabled", func(t *testing.T) {
+		SetColors(true)
+		buf.Reset()
+		Logger.Warn("colored message")
+		assert.Contains(t, buf.String(), "\x1b[")
+	})
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RepositoryField is the field naming the repository an entry is about
+const RepositoryField = "repository"
+
+// RepositoryLogs collects the entries logged about each repository as JSON lines, so they
+// can be written next to the outputs of the repository. Identical warnings are all kept.
+type RepositoryLogs struct {
+	formatter logrus.Formatter
+	entries   map[string]*bytes.Buffer
+	mu        sync.Mutex
+}
+
+// NewRepositoryLogs creates an empty collection of repository logs
+func NewRepositoryLogs() *RepositoryLogs {
+	return &RepositoryLogs{
+		formatter: &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano},
+		entries:   make(map[string]*bytes.Buffer),
+	}
+}
+
+// Levels returns every level enabled on the logger
+func (r *RepositoryLogs) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire records the entry under its repository field, and ignores entries without one
+func (r *RepositoryLogs) Fire(entry *logrus.Entry) error {
+	repository, ok := entry.Data[RepositoryField].(string)
+	if !ok || repository == "" {
+		return nil
+	}
+	line, err := r.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	buf, exists := r.entries[repository]
+	if !exists {
+		buf = &bytes.Buffer{}
+		r.entries[repository] = buf
+	}
+	buf.Write(line)
+	return nil
+}
+
+// Take returns the entries recorded about repository and forgets them
+func (r *RepositoryLogs) Take(repository string) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	buf, exists := r.entries[repository]
+	if !exists {
+		return nil
+	}
+	delete(r.entries, repository)
+	return buf.Bytes()
+}
+
+// CaptureRepositories sends the entries logged about each repository to logs, down to the
+// debug level whatever the verbosity of the console. It returns a function that stops the
+// capture.
+func CaptureRepositories(logs *RepositoryLogs) (stop func()) {
+	level := Logger.GetLevel()
+	formatter := Logger.Formatter
+	if level < logrus.DebugLevel {
+		Logger.SetFormatter(&levelFormatter{Formatter: formatter, level: level})
+		Logger.SetLevel(logrus.DebugLevel)
+	}
+	Logger.AddHook(logs)
+
+	return func() {
+		removeHook(logs)
+		Logger.SetLevel(level)
+		Logger.SetFormatter(formatter)
+	}
+}
+
+// levelFormatter drops entries above level, so the console keeps its verbosity while hooks
+// receive more detailed entries
+type levelFormatter struct {
+	logrus.Formatter
+	level logrus.Level
+}
+
+// Format formats the entry, or returns nothing when it is above the level
+func (f *levelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level > f.level {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// debugEnabled reports whether debug entries are shown on the console, which they may not
+// be at the debug logger level while repository logs are captured
+func debugEnabled(logger *logrus.Logger) bool {
+	if formatter, ok := logger.Formatter.(*levelFormatter); ok {
+		return formatter.level >= logrus.DebugLevel
+	}
+	return logger.IsLevelEnabled(logrus.DebugLevel)
+}
+
+// removeHook stops sending entries to hook
+func removeHook(hook logrus.Hook) {
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range Logger.Hooks {
+		for _, h := range levelHooks {
+			if h != hook {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	Logger.ReplaceHooks(hooks)
+}
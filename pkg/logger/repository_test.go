@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryLogs(t *testing.T) {
+	setup := func(level logrus.Level) *bytes.Buffer {
+		Logger = logrus.New()
+		SetColors(false)
+		Logger.SetLevel(level)
+		var buf bytes.Buffer
+		Logger.SetOutput(&buf)
+		return &buf
+	}
+
+	t.Run("should collect entries by repository as JSON lines", func(t *testing.T) {
+		setup(logrus.InfoLevel)
+		logs := NewRepositoryLogs()
+		stop := CaptureRepositories(logs)
+		defer stop()
+
+		Logger.WithField(RepositoryField, "owner/api").Info("Processing repository")
+		Logger.WithField(RepositoryField, "owner/web").Info("Processing repository")
+		Logger.WithField(RepositoryField, "owner/api").WithField("file", "main.go").Warn("Failed to fetch file")
+		Logger.Info("Starting run")
+
+		lines := strings.Split(strings.TrimSpace(string(logs.Take("owner/api"))), "\n")
+		require.Len(t, lines, 2)
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+		assert.Equal(t, "warning", entry["level"])
+		assert.Equal(t, "Failed to fetch file", entry["msg"])
+		assert.Equal(t, "main.go", entry["file"])
+
+		assert.Empty(t, logs.Take("owner/api"), "taken entries should be forgotten")
+		assert.NotEmpty(t, logs.Take("owner/web"))
+	})
+
+	t.Run("should capture debug entries without showing them on the console", func(t *testing.T) {
+		buf := setup(logrus.WarnLevel)
+		logs := NewRepositoryLogs()
+		stop := CaptureRepositories(logs)
+
+		Logger.WithField(RepositoryField, "owner/api").Debug("Fetching repository tree")
+		for i := 0; i < 5; i++ {
+			Logger.WithField(RepositoryField, "owner/api").Warn("Skipping binary file")
+		}
+		stop()
+
+		assert.Equal(t, WarningBudget, strings.Count(buf.String(), "\n"), "the console should keep its level and deduplication")
+		assert.Equal(t, 6, strings.Count(string(logs.Take("owner/api")), "\n"))
+
+		Logger.WithField(RepositoryField, "owner/api").Warn("Rate limited")
+		assert.Empty(t, logs.Take("owner/api"), "entries should not be captured once stopped")
+		assert.Equal(t, logrus.WarnLevel, Logger.GetLevel())
+	})
+}
@@ -1,46 +1,240 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
 // Config represents the complete configuration for Sherpa
 type Config struct {
-	GitLab     GitLabConfig     `yaml:"gitlab"`
-	GitHub     GitHubConfig     `yaml:"github"`
-	Processing ProcessingConfig `yaml:"processing"`
-	Output     OutputConfig     `yaml:"output"`
-	Cache      CacheConfig      `yaml:"cache"`
+	GitLab     GitLabConfig      `yaml:"gitlab"`
+	GitHub     GitHubConfig      `yaml:"github"`
+	Gitea      GiteaConfig       `yaml:"gitea"`
+	Local      LocalConfig       `yaml:"local"`
+	Processing ProcessingConfig  `yaml:"processing"`
+	Output     OutputConfig      `yaml:"output"`
+	Cache      CacheConfig       `yaml:"cache"`
+	Sensitive  SensitiveConfig   `yaml:"sensitive"`
+	HTTP       HTTPConfig        `yaml:"http"`
+	Policy     PolicyConfig      `yaml:"policy"`
+	Aliases    map[string]string `yaml:"aliases,omitempty"`      // Repository arguments by alias name
+	Repos      []TaggedRepo      `yaml:"repositories,omitempty"` // Repositories selected by their tags with --tags
+}
+
+// TaggedRepo is a repository listed in the configuration file with tags like team:payments,
+// which select it with --tags and are recorded in the outputs of the run
+type TaggedRepo struct {
+	Repo string   `yaml:"repo"` // Repository argument, in any format accepted on the command line
+	Tags []string `yaml:"tags"`
 }
 
 // GitLabConfig contains GitLab connection settings
 type GitLabConfig struct {
-	BaseURL  string `yaml:"base_url"`
-	TokenEnv string `yaml:"token_env"`
+	BaseURL       string `yaml:"base_url"`
+	TokenEnv      string `yaml:"token_env"`
+	RequestConfig `yaml:",inline"`
 }
 
 // GitHubConfig contains GitHub connection settings
 type GitHubConfig struct {
-	BaseURL  string `yaml:"base_url"`
-	TokenEnv string `yaml:"token_env"`
+	BaseURL       string `yaml:"base_url"`
+	TokenEnv      string `yaml:"token_env"`
+	API           string `yaml:"api"` // How file contents are fetched: GitHubAPIREST or GitHubAPIGraphQL
+	RequestConfig `yaml:",inline"`
+}
+
+// GitHub APIs file contents can be fetched with
+const (
+	GitHubAPIREST    = "rest"    // One Contents API request per file
+	GitHubAPIGraphQL = "graphql" // Many file blobs per GraphQL request
+)
+
+// GiteaConfig contains Gitea and Forgejo connection settings
+type GiteaConfig struct {
+	BaseURL       string `yaml:"base_url"`
+	TokenEnv      string `yaml:"token_env"`
+	RequestConfig `yaml:",inline"`
+}
+
+// LocalConfig contains the settings of local folder walks
+type LocalConfig struct {
+	FollowSymlinks bool   `yaml:"follow_symlinks"`  // Walk into symlinked directories, each directory once so symlink loops end
+	MaxWalkEntries int    `yaml:"max_walk_entries"` // Fail a folder holding more entries than this (0 = unlimited)
+	Mounts         string `yaml:"mounts"`           // Mounted filesystems walked into: all, local or same
+}
+
+// Mount policies of local folder walks
+const (
+	MountsAll   = "all"   // Walk into every mounted filesystem
+	MountsLocal = "local" // Skip network and FUSE mounts, like NFS, SMB or sshfs
+	MountsSame  = "same"  // Stay on the filesystem of the folder
+)
+
+// RequestConfig contains the request settings of a platform, tuned to its rate limits
+type RequestConfig struct {
+	MaxConcurrency int           `yaml:"max_concurrency"` // Files fetched at once from the platform (0 = processing.max_concurrency)
+	Retries        int           `yaml:"retries"`         // Times a request failing with a network or server error is sent again
+	Backoff        time.Duration `yaml:"backoff"`         // Delay before the first retry, doubled before each following one
+}
+
+// Requests returns the request settings of a platform, empty for platforms without any
+func (c *Config) Requests(platform Platform) RequestConfig {
+	switch platform {
+	case PlatformGitHub:
+		return c.GitHub.RequestConfig
+	case PlatformGitLab:
+		return c.GitLab.RequestConfig
+	case PlatformGitea:
+		return c.Gitea.RequestConfig
+	default:
+		return RequestConfig{}
+	}
+}
+
+// ProcessingFor returns the processing settings of a platform, fetching as many files at
+// once as its max_concurrency allows
+func (c *Config) ProcessingFor(platform Platform) ProcessingConfig {
+	processing := c.Processing
+	if concurrency := c.Requests(platform).MaxConcurrency; concurrency > 0 {
+		processing.MaxConcurrency = concurrency
+	}
+	return processing
 }
 
 // ProcessingConfig contains file processing settings
 type ProcessingConfig struct {
-	Ignore           []string `yaml:"ignore"`
-	IncludeOnly      []string `yaml:"include_only"`
-	MaxFileSize      string   `yaml:"max_file_size"`
-	SkipBinary       bool     `yaml:"skip_binary"`
-	MaxConcurrency   int      `yaml:"max_concurrency"`
-	MaxMemoryPerFile int64    `yaml:"max_memory_per_file"` // Maximum memory per file in bytes
-	MaxTotalMemory   int64    `yaml:"max_total_memory"`    // Maximum total memory in bytes
-	MaxFiles         int      `yaml:"max_files"`           // Maximum number of files to process
+	Ignore           []string       `yaml:"ignore"`
+	IncludeOnly      []string       `yaml:"include_only"`
+	MaxFileSize      string         `yaml:"max_file_size"`
+	SkipBinary       bool           `yaml:"skip_binary"`
+	BinaryExtensions []string       `yaml:"binary_extensions"` // Extensions of files skipped as binary without fetching them, when SkipBinary is set
+	MaxConcurrency   int            `yaml:"max_concurrency"`
+	MaxMemoryPerFile int64          `yaml:"max_memory_per_file"` // Maximum memory per file in bytes
+	MaxTotalMemory   int64          `yaml:"max_total_memory"`    // Maximum total memory in bytes
+	MaxFiles         int            `yaml:"max_files"`           // Maximum number of files to process
+	MaxRepoSize      string         `yaml:"max_repo_size"`       // Abort before fetching when the filtered tree exceeds this size
+	FrameworkPresets bool           `yaml:"framework_presets"`   // Add ignore presets for detected frameworks
+	Match            string         `yaml:"match"`               // Code search query restricting fetched files
+	MatchNeighbors   bool           `yaml:"match_neighbors"`     // Also fetch files sharing a directory with matches
+	Diff             string         `yaml:"diff"`                // Refs compared as base..head, restricting fetched files to the changes
+	Issues           IssuesConfig   `yaml:"issues"`              // Issues included as project context
+	Releases         ReleasesConfig `yaml:"releases"`            // Release notes included as project context
+	Wiki             bool           `yaml:"wiki"`                // Include the pages of the repository wiki
+	AltText          AltTextConfig  `yaml:"alt_text"`            // Alt text written for the images of included Markdown files
+	VendoredVersions bool           `yaml:"vendored_versions"`   // List the packages of excluded vendor directories from their manifests
+	Sample           string         `yaml:"sample"`              // Fetch only this share of the files, like 10%, picked reproducibly per repository and ref
+	BudgetTime       time.Duration  `yaml:"budget_time"`         // Stop fetching when the run nears this duration and write what was fetched (0 = no limit)
+	RepoConfig       bool           `yaml:"repo_config"`         // Honor .sherpa.yml, .sherpaignore and annotation files found inside processed repositories
+}
+
+// Annotation is an owner-provided note attached to a path in the generated output
+type Annotation struct {
+	Path        string `yaml:"path"`
+	Description string `yaml:"description"`
+	ReadFirst   bool   `yaml:"read_first"` // List the path first and mark it in the output
+}
+
+// RepoConfig contains the settings a repository can declare in its own .sherpa.yml
+type RepoConfig struct {
+	Ignore   []string `yaml:"ignore"`   // Additional ignore patterns
+	Priority []string `yaml:"priority"` // Patterns whose files are listed first, in order
+	Summary  string   `yaml:"summary"`  // Description rendered in the repository information section
 }
 
 // OutputConfig contains output generation settings
 type OutputConfig struct {
 	Directory      string `yaml:"directory"`
 	OrganizeByDate bool   `yaml:"organize_by_date"`
+	Language       string `yaml:"language"`         // Language for generated headings (en, fr, ja)
+	TreeStyle      string `yaml:"tree_style"`       // Project tree rendering: unix or plain
+	MaxTreeEntries int    `yaml:"max_tree_entries"` // Fold deep subtrees when the tree exceeds this many entries (0 = unlimited)
+	ExpandTree     bool   `yaml:"expand_tree"`      // Disable tree folding regardless of MaxTreeEntries
+	FileTags       bool   `yaml:"file_tags"`        // Tag file headings with classifications like [test] or [config]
+	LockFile       string `yaml:"lock_file"`        // Path of the lock file recording the commit of each repository, written when set
+	WriteWorkers   int    `yaml:"write_workers"`    // Maximum number of output files written concurrently
+	Fsync          string `yaml:"fsync"`            // Durability policy for written files: none, file or full
+	TokenBudget    int    `yaml:"token_budget"`     // Pack file contents into roughly this many tokens (0 = unlimited)
+	Packing        string `yaml:"packing"`          // Packing strategy under a token budget: greedy or knapsack
+	MaxTokens      int    `yaml:"max_tokens"`       // Truncate or drop the lowest-priority files above this many tokens (0 = unlimited)
+	SplitSize      string `yaml:"split_size"`       // Split llms-full.txt into parts below this size (e.g. 2MB)
+	SplitTokens    string `yaml:"split_tokens"`     // Split llms-full.txt into parts below this many tokens (e.g. 100k)
+	MaxParts       int    `yaml:"max_parts"`        // Fail repositories whose llms-full.txt splits into more parts (0 = unlimited)
+	TreeJSON       bool   `yaml:"tree_json"`        // Also write the project tree as tree.json
+	LLMsTxt        bool   `yaml:"llms_txt"`         // Also write an llms.txt index following the llmstxt.org specification
+	LLMsTxtLegacy  bool   `yaml:"llms_txt_legacy"`  // Write llms.txt in the former header and tree format instead
+	Artifacts      string `yaml:"artifacts"`        // Context files written: full (llms-full.txt), index (llms.txt) or both
+	Format         string `yaml:"format"`           // Output format: txt (llms-full.txt), md (llms-full.md) or repomix (llms-full.xml)
+	Incremental    bool   `yaml:"incremental"`      // Fetch only files changed since the commit of the previous run
+	SizeUnits      string `yaml:"size_units"`       // Units of printed sizes and token counts: binary or si
+	NameTemplate   string `yaml:"name_template"`    // Name of repository output directories, with tokens like {repo}, {ref} and {sha}
+	Deterministic  bool   `yaml:"deterministic"`    // Leave out timestamps and run identifiers so identical inputs give byte-identical outputs
+	EditorRules    bool   `yaml:"editor_rules"`     // Also write .cursorrules and .continuerules pointing editor assistants to the outputs
+	RepoLogs       bool   `yaml:"repo_logs"`        // Write the entries logged about each repository to sherpa.log in its output directory
+	StripLicenses  bool   `yaml:"strip_licenses"`   // Replace the license headers repeated at the top of files with a one-line marker
+}
+
+// OutputNameTokens lists the tokens of output name templates
+var OutputNameTokens = []string{"owner", "name", "repo", "ref", "sha", "platform"}
+
+// Tree rendering styles
+const (
+	TreeStyleUnix  = "unix"  // Box-drawing characters like the tree command
+	TreeStylePlain = "plain" // Indentation only, for screen readers and plain-text consumers
+)
+
+// Output formats
+const (
+	FormatText     = "txt"     // Plain text llms-full.txt
+	FormatMarkdown = "md"      // Markdown llms-full.md with a table of contents and collapsible files
+	FormatRepomix  = "repomix" // XML file delimiters of repomix in llms-full.xml
+)
+
+// Context artifacts written for each repository
+const (
+	ArtifactsFull  = "full"  // llms-full.txt only, plus llms.txt with llms_txt
+	ArtifactsIndex = "index" // llms.txt only
+	ArtifactsBoth  = "both"  // llms.txt and llms-full.txt
+)
+
+// Packing strategies for fitting file contents into a token budget
+const (
+	PackingGreedy   = "greedy"   // Upgrade files by value density while they fit
+	PackingKnapsack = "knapsack" // Refine the greedy result with a knapsack solver
+)
+
+// Units of printed sizes and token counts
+const (
+	SizeUnitsBinary = "binary" // Powers of 1024 labelled KB, MB, GB, with token counts in full
+	SizeUnitsSI     = "si"     // Powers of 1000 labelled kB, MB, GB, with token counts like 3.4k
+)
+
+// Fsync policies for output files
+const (
+	FsyncNone = "none" // Leave flushing to the operating system
+	FsyncFile = "file" // Sync each file before closing it
+	FsyncFull = "full" // Sync each file and its parent directory
+)
+
+// SensitiveConfig contains settings for files that require explicit acknowledgement
+type SensitiveConfig struct {
+	Patterns   []string `yaml:"patterns"`   // Paths considered sensitive (e.g. auth/, payments/)
+	Disclaimer string   `yaml:"disclaimer"` // Block prepended to outputs containing sensitive files
+}
+
+// HTTPConfig contains settings applied to every request sent to platforms and downloads
+type HTTPConfig struct {
+	UserAgent string            `yaml:"user_agent"` // Replaces the User-Agent of platform clients when set
+	Headers   map[string]string `yaml:"headers"`    // Extra headers added to every request
+	MaxWait   time.Duration     `yaml:"max_wait"`   // Longest wait for a rate limit quota to reset (0 = never wait)
+}
+
+// PolicyConfig locates an organization policy: signed ignore rules and sensitive patterns
+// maintained centrally, added to the local configuration
+type PolicyConfig struct {
+	URL       string        `yaml:"url"`
+	PublicKey string        `yaml:"public_key"` // Base64 Ed25519 key checking the signature served at <url>.sig
+	TTL       time.Duration `yaml:"ttl"`        // How long a fetched policy is used before it is fetched again
 }
 
 // CacheConfig contains caching settings
@@ -48,6 +242,7 @@ type CacheConfig struct {
 	Enabled   bool          `yaml:"enabled"`
 	Directory string        `yaml:"directory"`
 	TTL       time.Duration `yaml:"ttl"`
+	SkipAfter int           `yaml:"skip_after"` // Consecutive failures before a file is skipped on later runs
 }
 
 // Platform represents the VCS platform type
@@ -56,6 +251,7 @@ type Platform string
 const (
 	PlatformGitLab Platform = "gitlab"
 	PlatformGitHub Platform = "github"
+	PlatformGitea  Platform = "gitea" // Gitea and Forgejo
 	PlatformLocal  Platform = "local"
 )
 
@@ -78,60 +274,331 @@ type RepositoryTree struct {
 	Type string `json:"type"`
 	Path string `json:"path"`
 	Mode string `json:"mode"`
+	Size int64  `json:"size"` // Blob size in bytes when reported by the provider, 0 if unknown
 }
 
 // FileInfo contains information about a file in the repository
 type FileInfo struct {
-	Path     string
-	Name     string
-	Size     int64
-	Content  string
-	IsText   bool
-	IsBinary bool
-	IsDir    bool
-	Error    error
+	Path          string
+	Name          string
+	Size          int64 // Raw size in bytes from provider metadata (tree or blob size, os.Stat)
+	ContentSize   int64 // Size of the decoded UTF-8 content in bytes
+	Content       string
+	IsText        bool
+	IsBinary      bool
+	IsDir         bool
+	Error         error
+	FetchDuration time.Duration // Time spent fetching the file from the platform API, zero when read locally or reused
+	NotFetched    bool          // Left out when the time budget ran out, rendered as a stub
 }
 
 // ProcessingResult contains the result of processing a repository
 type ProcessingResult struct {
-	Repository  Repository
-	Files       []FileInfo
-	TotalFiles  int
-	TotalSize   int64
-	ProcessedAt time.Time
-	Duration    time.Duration
-	Errors      []error
+	Repository       Repository
+	Files            []FileInfo
+	TotalFiles       int
+	TotalSize        int64 // Raw bytes reported by the provider
+	TotalContentSize int64 // Bytes of decoded UTF-8 content
+	ProcessedAt      time.Time
+	Duration         time.Duration
+	Errors           []error
+	Frameworks       []string // Frameworks detected from the repository files
+	Priority         []string // File patterns listed first, from the repository .sherpa.yml
+	Summary          string   // Summary text from the repository .sherpa.yml
+	Annotations      []Annotation
+	Counts           FileCounts             // What happened to each file of the tree
+	Incomplete       *Incomplete            // Set when rate limiting or the time budget stopped files from being fetched
+	Sample           *Sample                // Set when only a sample of the files was fetched
+	SlowFiles        []FileTiming           // Files that took longest to fetch, slowest first
+	SlowDirectories  []FileTiming           // Directories whose files took longest to fetch, slowest first
+	Comparison       *Comparison            // Changes between the refs of --diff, nil otherwise
+	Issues           []Issue                // Issues included with --include-issues
+	Releases         []Release              // Releases included with --include-releases
+	WikiPages        []WikiPage             // Wiki pages included with --include-wiki
+	Vendored         []VendoredDependencies // Packages of vendor directories excluded from the files
+}
+
+// FileTiming is the time spent fetching a file, or the files directly inside a directory
+type FileTiming struct {
+	Path     string
+	Duration time.Duration
+	Files    int   // Number of files fetched
+	Size     int64 // Raw bytes of the fetched files
+}
+
+// Incomplete describes an output cut short because the platform rate limit was reached or
+// the time budget ran out
+type Incomplete struct {
+	RateLimitedAt time.Time // When the first file was refused, zero unless rate limited
+	TimedOutAt    time.Time // When the time budget stopped fetching, zero unless it ran out
+	Fetched       int       // Files fetched before the limit was reached
+	Total         int       // Files that should have been fetched
+}
+
+// Sample describes the share of files kept by --sample
+type Sample struct {
+	Percent float64 // Requested share of the files, out of 100
+	Files   int     // Files kept in the sample
+	Total   int     // Files the sample was drawn from
+}
+
+// FileCounts breaks down the files of a repository by outcome
+type FileCounts struct {
+	Included       int `json:"included"`              // Files whose content is in the output
+	SkippedBinary  int `json:"skipped_binary"`        // Binary files left out
+	SkippedLarge   int `json:"skipped_large"`         // Files above the maximum file size
+	SkippedIgnored int `json:"skipped_ignored"`       // Files excluded by patterns, sampling, review or the skip list
+	Failed         int `json:"failed"`                // Files that could not be fetched
+	Denied         int `json:"denied,omitempty"`      // Files and directories that could not be read for lack of permission
+	Reused         int `json:"reused,omitempty"`      // Included files reused from the previous incremental run
+	NotFetched     int `json:"not_fetched,omitempty"` // Files left as stubs when the time budget ran out
 }
 
 // LLMsOutput represents the structure for generating llms.txt files
 type LLMsOutput struct {
-	Repository    Repository
-	GeneratedAt   time.Time
-	TotalFiles    int
-	TotalSize     int64
-	ProjectTree   []TreeNode
-	ConfigFiles   []FileInfo
-	Documentation []FileInfo
-	FileContents  []FileInfo
+	Repository     Repository
+	GeneratedAt    time.Time
+	TotalFiles     int
+	TotalSize      int64
+	ProjectTree    []TreeNode
+	ConfigFiles    []FileInfo
+	Documentation  []FileInfo
+	FileContents   []FileInfo
+	Frameworks     []string // Frameworks detected from the repository files
+	Disclaimer     string   // Optional notice rendered before the header
+	SensitiveFiles []string // Included files matching sensitive patterns
+	Priority       []string // File patterns listed first in the file contents section
+	Summary        string   // Owner-provided repository summary
+	Annotations    []Annotation
+	Incomplete     *Incomplete            // Rendered as a marker before the header when set
+	Sample         *Sample                // Rendered in the header when only a sample of the files was fetched
+	Issues         []Issue                // Rendered in an Issues section after the project tree
+	Releases       []Release              // Rendered in a Releases section after the issues
+	WikiPages      []WikiPage             // Rendered in a Wiki section after the releases
+	Vendored       []VendoredDependencies // Rendered in a Vendored Dependencies section after the project tree
+	Ref            string                 // Commit or branch the files were read at, used to link files from llms.txt
+	Commit         string                 // Commit the files were read at, shown instead of the generation time by deterministic outputs
 }
 
 // TreeNode represents a node in the project tree structure
 type TreeNode struct {
-	Name     string
-	Path     string
-	Size     int64
-	IsDir    bool
-	Children []TreeNode
+	Name      string
+	Path      string
+	Size      int64
+	Tokens    int // Estimated tokens of a file's content
+	IsDir     bool
+	Children  []TreeNode
+	Folded    bool   // Directory collapsed into a summary line
+	FileCount int    // Number of files below a folded directory
+	Note      string // Owner annotation describing the entry
+	ReadFirst bool   // Owner marked the entry as read-first
 }
 
 // RepositoryInfo contains parsed repository information
 type RepositoryInfo struct {
-	Platform Platform
-	Owner    string
-	Name     string
-	FullName string // owner/repo format
-	URL      string // original URL if provided
-	Branch   string // target branch, empty means default branch
+	Platform     Platform
+	Owner        string
+	Name         string
+	FullName     string // owner/repo format
+	URL          string // original URL if provided
+	Branch       string // target branch, empty means default branch
+	Subdirectory string // path within the repository to restrict processing to, empty for the whole repository
+	Kind         string // KindSnippet, KindDownload, KindGitClone, KindGroup or a package kind, empty for repositories
+	RefScoped    bool   // Name outputs after the branch too, set when the repository is processed at several refs in one run
+	// Tags of the repository in the configuration file
+	Tags []string
+}
+
+// Strategies for fetching platform repositories
+const (
+	StrategyAPI   = "api"   // Read files through the platform API
+	StrategyClone = "clone" // Shallow-clone the repository with git
+)
+
+// Kinds of inputs that are not plain repositories
+const (
+	// KindSnippet marks a GitHub gist or GitLab snippet, fetched with its files in one go
+	KindSnippet = "snippet"
+	// KindDownload marks an archive or raw file URL, downloaded and processed as a local folder
+	KindDownload = "download"
+	// KindGoModule marks a Go module version, fetched from the module proxy as a local folder
+	KindGoModule = "gomod"
+	// KindNPMPackage marks an npm package version, fetched from the registry as a local folder
+	KindNPMPackage = "npm"
+	// KindPyPIPackage marks a PyPI package release, fetched from the index as a local folder
+	KindPyPIPackage = "pypi"
+	// KindGitClone marks a git remote we have no adapter for, shallow-cloned as a local folder
+	KindGitClone = "git"
+	// KindGroup marks an organization, user or group whose repositories are listed and each processed
+	KindGroup = "group"
+)
+
+// ListedRepository is a repository found when listing an organization or group
+type ListedRepository struct {
+	FullName      string // owner/repo, or group/subgroup/project on GitLab
+	DefaultBranch string
+	Archived      bool
+	Fork          bool
+	Visibility    string // public, private or internal
+	Topics        []string
+}
+
+// PullRequest is a pull request with the files it changes
+type PullRequest struct {
+	Number  int
+	Title   string
+	Body    string
+	Author  string
+	URL     string
+	BaseRef string // Branch the pull request merges into
+	HeadRef string // Branch holding the changes
+	HeadSHA string // Commit the changed files are read at
+	Files   []ChangedFile
+}
+
+// IssuesConfig selects the issues of a repository included in its output
+type IssuesConfig struct {
+	Include bool     `yaml:"include"` // Render issues in an Issues section
+	State   string   `yaml:"state"`   // open, closed or all
+	Labels  []string `yaml:"labels"`  // Labels an issue must all have
+	Limit   int      `yaml:"limit"`   // Maximum number of issues, most recently updated first
+}
+
+// Issue states selected with IssuesConfig
+const (
+	IssueStateOpen   = "open"
+	IssueStateClosed = "closed"
+	IssueStateAll    = "all"
+)
+
+// Issue is an issue of a repository, included as project context
+type Issue struct {
+	Number    int
+	Title     string
+	Body      string
+	State     string // open or closed
+	Author    string
+	URL       string
+	Labels    []string
+	Comments  int
+	CreatedAt time.Time
+}
+
+// ReleasesConfig selects the releases of a repository included in its output
+type ReleasesConfig struct {
+	Include bool `yaml:"include"` // Render release notes in a Releases section
+	Limit   int  `yaml:"limit"`   // Maximum number of releases, most recent first
+}
+
+// AltTextConfig selects the images of Markdown files described by a vision-capable model, so
+// the diagrams referenced by docs are not blind spots of the output
+type AltTextConfig struct {
+	Include   bool          `yaml:"include"`     // Write alt text for the images without any in included Markdown files
+	Endpoint  string        `yaml:"endpoint"`    // OpenAI-compatible chat completions URL of the model
+	Model     string        `yaml:"model"`       // Model named in each request
+	APIKeyEnv string        `yaml:"api_key_env"` // Environment variable holding the API key, sent as a bearer token when set
+	Limit     int           `yaml:"limit"`       // Maximum number of images described per repository
+	MaxSize   string        `yaml:"max_size"`    // Larger images are left undescribed
+	Timeout   time.Duration `yaml:"timeout"`     // Time allowed to describe an image
+}
+
+// Release is a published release of a repository with its notes
+type Release struct {
+	Name        string
+	Tag         string
+	Body        string // Release notes
+	Author      string
+	URL         string
+	Prerelease  bool
+	PublishedAt time.Time
+}
+
+// WikiPage is a page of a repository wiki
+type WikiPage struct {
+	Title   string
+	Path    string // Path of the page within the wiki, like Home.md
+	Content string
+}
+
+// VendoredDependencies lists the packages vendored in a directory left out of the output,
+// read from its manifest
+type VendoredDependencies struct {
+	Path      string // Vendor directory, like vendor or web/node_modules
+	Ecosystem string // go, npm or composer
+	Packages  []VendoredPackage
+}
+
+// VendoredPackage is a package at the version found in a vendor directory
+type VendoredPackage struct {
+	Name    string
+	Version string
+}
+
+// Comparison lists the files changed between two refs of a repository
+type Comparison struct {
+	Base    string // Ref compared from
+	Head    string // Ref compared to, whose files are fetched
+	Commits int    // Number of commits from base to head
+	Files   []ChangedFile
+}
+
+// ParseDiffRange splits a range like v1.2.0..v1.3.0, or v1.2.0...v1.3.0, into its base
+// and head refs
+func ParseDiffRange(spec string) (string, string, error) {
+	separator := ".."
+	if strings.Contains(spec, "...") {
+		separator = "..."
+	}
+	base, head, found := strings.Cut(spec, separator)
+	if !found || base == "" || head == "" || strings.Contains(head, "..") {
+		return "", "", fmt.Errorf("invalid diff range '%s': expected base..head", spec)
+	}
+	return base, head, nil
+}
+
+// ChangedFile is a file changed by a pull request or between two refs
+type ChangedFile struct {
+	Path         string
+	PreviousPath string // Path before a rename, empty otherwise
+	Status       string // added, modified, removed, renamed, copied or changed
+	Additions    int
+	Deletions    int
+	Patch        string // Unified diff hunks, empty for binary files and very large diffs
+}
+
+// RepositoryFilter selects the repositories of an organization or group. Archived
+// repositories and forks are left out unless included.
+type RepositoryFilter struct {
+	IncludeArchived bool
+	IncludeForks    bool
+	Visibility      string   // public, private or internal, empty for any
+	Topics          []string // Topics a repository must all have
+}
+
+// Matches reports whether a listed repository is selected by the filter
+func (f RepositoryFilter) Matches(repo ListedRepository) bool {
+	if repo.Archived && !f.IncludeArchived {
+		return false
+	}
+	if repo.Fork && !f.IncludeForks {
+		return false
+	}
+	if f.Visibility != "" && !strings.EqualFold(repo.Visibility, f.Visibility) {
+		return false
+	}
+	for _, topic := range f.Topics {
+		found := false
+		for _, repoTopic := range repo.Topics {
+			if strings.EqualFold(repoTopic, topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 // CLIOptions contains command-line options
@@ -151,4 +618,63 @@ type CLIOptions struct {
 	Verbose             bool
 	Quiet               bool
 	DryRun              bool
+	Language            string
+	MaxTreeEntries      int
+	ExpandTree          bool
+	TreeJSON            bool
+	Deterministic       bool
+	RepoLogs            bool
+	EditorRules         bool
+	StripLicenses       bool
+	LLMsTxt             bool
+	LLMsTxtLegacy       bool
+	Artifacts           string
+	FileTags            bool
+	AckSensitive        bool
+	MaxRepoSize         string
+	Match               string
+	MatchNeighbors      bool
+	Diff                string
+	IncludeIssues       bool
+	IssueState          string
+	IssueLabels         []string
+	IssueLimit          int
+	IncludeReleases     bool
+	ReleaseLimit        int
+	IncludeWiki         bool
+	AltText             bool
+	AltTextEndpoint     string
+	AltTextModel        string
+	BudgetTime          time.Duration
+	Sample              string
+	Lock                bool // Record the commit of each repository in the lock file
+	Locked              bool
+	FailFast            bool // Stop processing repositories at the first failure
+	Offline             bool // Serve repositories from local folders and recorded state without network access
+	WriteWorkers        int
+	Fsync               string
+	NoRepoConfig        bool
+	TokenBudget         int
+	Packing             string
+	NameTemplate        string
+	MaxTokens           int
+	SplitSize           string
+	SplitTokens         string
+	FitFor              string // Downstream tool whose upload constraints the outputs fit
+	Format              string
+	Incremental         bool
+	SI                  bool
+	Path                string
+	Branch              string
+	Review              bool
+	UserAgent           string
+	MaxWait             time.Duration    // Longest wait for a rate limit quota to reset
+	Headers             []string         // Extra request headers as "Name: value"
+	DebugHTTP           bool             // Log the metadata of every HTTP request
+	DebugHTTPFile       string           // Dump every HTTP request with its bodies to this file
+	Record              string           // Record every HTTP request with its response to this session archive
+	Replay              string           // Answer HTTP requests from this session archive instead of the network
+	Strategy            string           // How repositories are fetched: StrategyAPI or StrategyClone
+	FaultInject         string           // Hidden: fault injection spec for resilience testing
+	RepositoryFilter    RepositoryFilter // Selects the repositories of organizations and groups
 }
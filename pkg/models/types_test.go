@@ -234,3 +234,56 @@ func TestCLIOptions(t *testing.T) {
 		assert.False(t, options.Quiet)
 	})
 }
+
+func TestRepositoryFilter_Matches(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   RepositoryFilter
+		repo     ListedRepository
+		expected bool
+	}{
+		{name: "should match an active repository", repo: ListedRepository{Visibility: "public"}, expected: true},
+		{name: "should skip archived repositories", repo: ListedRepository{Archived: true}, expected: false},
+		{name: "should include archived repositories when asked", filter: RepositoryFilter{IncludeArchived: true}, repo: ListedRepository{Archived: true}, expected: true},
+		{name: "should skip forks", repo: ListedRepository{Fork: true}, expected: false},
+		{name: "should include forks when asked", filter: RepositoryFilter{IncludeForks: true}, repo: ListedRepository{Fork: true}, expected: true},
+		{name: "should match the visibility", filter: RepositoryFilter{Visibility: "private"}, repo: ListedRepository{Visibility: "public"}, expected: false},
+		{name: "should require every topic", filter: RepositoryFilter{Topics: []string{"go", "api"}}, repo: ListedRepository{Topics: []string{"go"}}, expected: false},
+		{name: "should match topics regardless of case", filter: RepositoryFilter{Topics: []string{"Go"}}, repo: ListedRepository{Topics: []string{"go", "cli"}}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Matches(tt.repo))
+		})
+	}
+}
+
+func TestParseDiffRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         string
+		expectedBase string
+		expectedHead string
+		wantErr      bool
+	}{
+		{name: "should split two-dot ranges", spec: "v1.2.0..v1.3.0", expectedBase: "v1.2.0", expectedHead: "v1.3.0"},
+		{name: "should split three-dot ranges", spec: "main...feature/retry", expectedBase: "main", expectedHead: "feature/retry"},
+		{name: "should reject a single ref", spec: "v1.2.0", wantErr: true},
+		{name: "should reject a missing head", spec: "v1.2.0..", wantErr: true},
+		{name: "should reject several ranges", spec: "a..b..c", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, head, err := ParseDiffRange(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBase, base)
+			assert.Equal(t, tt.expectedHead, head)
+		})
+	}
+}
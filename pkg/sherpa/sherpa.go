@@ -0,0 +1,192 @@
+// Package sherpa generates LLM context from repositories without going through the CLI.
+//
+//	client := sherpa.New(sherpa.Options{Token: os.Getenv("GITHUB_TOKEN")})
+//	result, err := client.Process(ctx, "github.com/owner/repo")
+//	if err != nil {
+//		return err
+//	}
+//	text, err := result.Render(sherpa.FormatText)
+//
+// Repository references are parsed like the arguments of the CLI: URLs, owner/repo
+// shorthands, #branch fragments, subdirectories and local folders are all accepted.
+package sherpa
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"sherpa/internal/adapters"
+	"sherpa/internal/config"
+	"sherpa/internal/generators"
+	"sherpa/internal/orchestration"
+	"sherpa/internal/pipeline"
+	"sherpa/internal/vision"
+	"sherpa/pkg/logger"
+	"sherpa/pkg/models"
+)
+
+// Output formats accepted by Result.Render
+const (
+	FormatText     = models.FormatText     // Plain text, as written to llms-full.txt
+	FormatMarkdown = models.FormatMarkdown // Markdown, as written to llms-full.md
+	FormatRepomix  = models.FormatRepomix  // XML file delimiters of repomix, as written to llms-full.xml
+	FormatJSON     = "json"                // Directory tree with file metadata, as written to tree.json
+)
+
+// Options configures a Sherpa
+type Options struct {
+	Config          *models.Config    // Configuration, the defaults of the CLI when nil
+	Token           string            // Access token, read from the token variable of the platform when empty
+	DefaultPlatform models.Platform   // Platform of owner/repo references, detected like the CLI when empty
+	Transport       http.RoundTripper // Transport of platform API requests, the client default when nil
+}
+
+// Sherpa processes repositories into LLM context
+type Sherpa struct {
+	options Options
+}
+
+// Result is a processed repository, ready to be rendered
+type Result struct {
+	Repository     *models.RepositoryInfo   // Repository the reference resolved to
+	Commit         string                   // Commit the files were read at, empty when it could not be resolved
+	Processing     *models.ProcessingResult // Files, tree and counts of the repository
+	SensitiveFiles []string                 // Included files matching the sensitive patterns of the configuration
+
+	output    *models.LLMsOutput
+	generator *generators.Generator
+}
+
+// New creates a Sherpa with options
+func New(options Options) *Sherpa {
+	return &Sherpa{options: options}
+}
+
+// Process fetches the repository referenced by repoRef. Single repositories and local
+// folders are supported; organizations, groups, packages, gists and downloads are not.
+func (s *Sherpa) Process(ctx context.Context, repoRef string) (*Result, error) {
+	cfg, err := s.config()
+	if err != nil {
+		return nil, err
+	}
+
+	repoInfo, err := adapters.ParseRepositoryURL(repoRef, s.options.DefaultPlatform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository '%s': %w", repoRef, err)
+	}
+	if repoInfo.Kind != "" {
+		return nil, fmt.Errorf("unsupported repository '%s': only single repositories and local folders can be processed", repoRef)
+	}
+
+	provider, err := s.provider(ctx, repoInfo, cfg)
+	if err != nil {
+		return nil, err
+	}
+	repoProcessor := pipeline.NewRepoProcessor(provider, cfg.ProcessingFor(repoInfo.Platform))
+	if altText := cfg.Processing.AltText; altText.Include {
+		repoProcessor.SetImageDescriber(vision.NewClient(altText, os.Getenv(altText.APIKeyEnv)))
+	}
+	if repoInfo.Subdirectory != "" {
+		repoProcessor = repoProcessor.WithSubdirectory(repoInfo.Subdirectory)
+	}
+
+	// Pin the ref to a commit, so every file is read at the same point in history
+	ref := repoInfo.Branch
+	commit, err := repoProcessor.ResolveCommit(ctx, repoInfo.FullName, repoInfo.Branch)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("repository", repoInfo.FullName).Warn("Could not resolve commit, reading the branch instead")
+		commit = ""
+	}
+	if commit != "" {
+		ref = commit
+	}
+
+	processing, err := repoProcessor.ProcessRepository(ctx, repoInfo.FullName, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process repository %s: %w", repoInfo.FullName, err)
+	}
+
+	generator := generators.NewGeneratorWithConfig(true, cfg.Output)
+	output, err := generator.GenerateOutput(processing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate output for %s: %w", repoInfo.FullName, err)
+	}
+	sensitiveFiles := pipeline.FindSensitiveFiles(processing.Files, cfg.Sensitive.Patterns)
+	if len(sensitiveFiles) > 0 {
+		output.Disclaimer = cfg.Sensitive.Disclaimer
+		output.SensitiveFiles = sensitiveFiles
+	}
+
+	return &Result{
+		Repository:     repoInfo,
+		Commit:         commit,
+		Processing:     processing,
+		SensitiveFiles: sensitiveFiles,
+		output:         output,
+		generator:      generator,
+	}, nil
+}
+
+// config returns the configuration of the options, or the validated defaults of the CLI
+func (s *Sherpa) config() (*models.Config, error) {
+	loader := config.NewLoader()
+	cfg := s.options.Config
+	if cfg == nil {
+		var err error
+		cfg, err = loader.LoadConfig("")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+	if err := loader.ValidateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return cfg, nil
+}
+
+// provider creates the provider reading repoInfo, rooted at the folder for local folders
+func (s *Sherpa) provider(ctx context.Context, repoInfo *models.RepositoryInfo, cfg *models.Config) (adapters.Provider, error) {
+	if repoInfo.Platform == models.PlatformLocal {
+		provider, err := adapters.CreateLocalProvider(repoInfo.FullName, cfg.Local)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create local provider: %w", err)
+		}
+		if err := provider.TestConnection(ctx); err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+
+	token, err := orchestration.GetTokenForPlatform(repoInfo.Platform, cfg, s.options.Token)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := adapters.CreateProviderWithTransport(repoInfo.Platform, cfg, token, s.options.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+	return provider, nil
+}
+
+// Render renders the result in format: FormatText, FormatMarkdown, FormatRepomix or
+// FormatJSON. Outputs are rendered whole, even when the configuration splits them into parts.
+func (r *Result) Render(format string) (string, error) {
+	switch format {
+	case FormatText:
+		return r.generator.GenerateLLMsFullText(r.output), nil
+	case FormatMarkdown:
+		return r.generator.GenerateMarkdown(r.output), nil
+	case FormatRepomix:
+		return r.generator.GenerateRepomix(r.output), nil
+	case FormatJSON:
+		treeJSON, err := r.generator.GenerateTreeJSON(r.output)
+		if err != nil {
+			return "", err
+		}
+		return string(treeJSON), nil
+	default:
+		return "", fmt.Errorf("invalid format '%s'. Valid options: %s, %s, %s, %s", format, FormatText, FormatMarkdown, FormatRepomix, FormatJSON)
+	}
+}
@@ -0,0 +1,72 @@
+package sherpa
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sherpa/internal/config"
+	"sherpa/internal/fakevcs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSherpa_Process(t *testing.T) {
+	t.Run("should process a repository and render it in every format", func(t *testing.T) {
+		server := fakevcs.NewServer(&fakevcs.Fixtures{Repositories: []fakevcs.Repository{{
+			Path: "owner/service",
+			Files: map[string]string{
+				"main.go":   "package main\n",
+				"README.md": "# Service\n",
+			},
+		}}})
+		defer server.Close()
+
+		cfg, err := config.NewLoader().LoadConfig("")
+		require.NoError(t, err)
+		cfg.GitHub.BaseURL = server.GitHubURL()
+
+		result, err := New(Options{Config: cfg, Token: fakevcs.Token}).Process(context.Background(), "https://github.com/owner/service")
+		require.NoError(t, err)
+		assert.Equal(t, "owner/service", result.Repository.FullName)
+		assert.Equal(t, 2, result.Processing.Counts.Included)
+
+		text, err := result.Render(FormatText)
+		require.NoError(t, err)
+		assert.Contains(t, text, "package main\n")
+
+		markdown, err := result.Render(FormatMarkdown)
+		require.NoError(t, err)
+		assert.Contains(t, markdown, "# Service\n")
+
+		repomix, err := result.Render(FormatRepomix)
+		require.NoError(t, err)
+		assert.Contains(t, repomix, "<file path=\"main.go\">\npackage main\n</file>\n")
+
+		treeJSON, err := result.Render(FormatJSON)
+		require.NoError(t, err)
+		assert.Contains(t, treeJSON, `"main.go"`)
+
+		_, err = result.Render("html")
+		assert.ErrorContains(t, err, "invalid format 'html'")
+	})
+
+	t.Run("should process a local folder with the default configuration", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("Local notes\n"), 0644))
+
+		result, err := New(Options{}).Process(context.Background(), dir)
+		require.NoError(t, err)
+
+		text, err := result.Render(FormatText)
+		require.NoError(t, err)
+		assert.Contains(t, text, "Local notes\n")
+	})
+
+	t.Run("should reject gists", func(t *testing.T) {
+		_, err := New(Options{Token: fakevcs.Token}).Process(context.Background(), "https://gist.github.com/octocat/6cad326836d38bd3a7ae")
+		assert.ErrorContains(t, err, "only single repositories and local folders can be processed")
+	})
+}
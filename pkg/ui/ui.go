@@ -0,0 +1,217 @@
+// Package ui renders the messages shown to users: progress, summaries and failures.
+// Diagnostics go through the logger package instead.
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Style is the role of a piece of output, colored and prefixed with an icon by the theme
+type Style int
+
+const (
+	StyleSuccess Style = iota
+	StyleWarning
+	StyleError
+	StyleInfo
+	StyleMuted
+)
+
+// Theme defines the colors and icons of each style
+type Theme struct {
+	Name   string
+	Colors map[Style]string // ANSI SGR parameters, e.g. "32" for green
+	Icons  map[Style]string
+}
+
+// DefaultTheme is used unless another theme is selected
+const DefaultTheme = "default"
+
+var (
+	unicodeIcons = map[Style]string{StyleSuccess: "✓", StyleWarning: "⚠", StyleError: "✗", StyleInfo: "•"}
+	asciiIcons   = map[Style]string{StyleSuccess: "+", StyleWarning: "!", StyleError: "x", StyleInfo: "-"}
+
+	themes = map[string]Theme{
+		"default": {
+			Name:   "default",
+			Colors: map[Style]string{StyleSuccess: "32", StyleWarning: "33", StyleError: "31", StyleInfo: "36", StyleMuted: "2"},
+			Icons:  unicodeIcons,
+		},
+		"high-contrast": {
+			Name:   "high-contrast",
+			Colors: map[Style]string{StyleSuccess: "1;92", StyleWarning: "1;93", StyleError: "1;91", StyleInfo: "1;96", StyleMuted: "37"},
+			Icons:  unicodeIcons,
+		},
+		"plain": {
+			Name:  "plain",
+			Icons: asciiIcons,
+		},
+	}
+)
+
+// Themes returns the names of the available themes
+func Themes() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme returns the theme with the given name, or the default theme for an empty name
+func LookupTheme(name string) (Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme '%s' (available: %s)", name, strings.Join(Themes(), ", "))
+	}
+	return theme, nil
+}
+
+// ColorEnabled reports whether colors should be written to the given file. NO_COLOR
+// disables them, FORCE_COLOR or CLICOLOR_FORCE enable them, and otherwise they are only
+// used on terminals, so CI logs stay free of escape sequences.
+func ColorEnabled(f *os.File) bool {
+	terminal := false
+	if info, err := f.Stat(); err == nil {
+		terminal = info.Mode()&os.ModeCharDevice != 0
+	}
+	return colorEnabled(os.Getenv, terminal)
+}
+
+func colorEnabled(getenv func(string) string, terminal bool) bool {
+	if getenv("NO_COLOR") != "" {
+		return false
+	}
+	if getenv("FORCE_COLOR") != "" || (getenv("CLICOLOR_FORCE") != "" && getenv("CLICOLOR_FORCE") != "0") {
+		return true
+	}
+	if getenv("TERM") == "dumb" {
+		return false
+	}
+	return terminal
+}
+
+// Printer writes styled messages to the standard output and errors to the standard error
+type Printer struct {
+	mu     sync.Mutex // Blocks from concurrent repositories are written one at a time
+	out    io.Writer
+	errOut io.Writer
+	theme  Theme
+	color  bool
+}
+
+// New creates a printer writing messages to out and errors to errOut
+func New(out, errOut io.Writer, theme Theme, color bool) *Printer {
+	return &Printer{out: out, errOut: errOut, theme: theme, color: color}
+}
+
+// Default creates a printer for the standard output and error with the default theme,
+// colored when the standard output allows it
+func Default() *Printer {
+	theme, _ := LookupTheme(DefaultTheme)
+	return New(os.Stdout, os.Stderr, theme, ColorEnabled(os.Stdout))
+}
+
+// Block starts a group of lines written to the standard output together
+func (p *Printer) Block() *Block {
+	return &Block{printer: p, w: p.out}
+}
+
+// ErrorBlock starts a group of lines written to the standard error together
+func (p *Printer) ErrorBlock() *Block {
+	return &Block{printer: p, w: p.errOut}
+}
+
+// Errorf writes a single error line to the standard error
+func (p *Printer) Errorf(format string, args ...interface{}) {
+	p.ErrorBlock().Error(format, args...).Flush()
+}
+
+// Paint colors text with the given style when colors are enabled
+func (p *Printer) Paint(style Style, text string) string {
+	code := p.theme.Colors[style]
+	if !p.color || code == "" {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// Block buffers lines so that messages about one repository are not interleaved with
+// messages about another
+type Block struct {
+	printer *Printer
+	w       io.Writer
+	buf     bytes.Buffer
+}
+
+// Success adds a line announcing a completed step
+func (b *Block) Success(format string, args ...interface{}) *Block {
+	return b.status(StyleSuccess, format, args...)
+}
+
+// Warning adds a line announcing a degraded result
+func (b *Block) Warning(format string, args ...interface{}) *Block {
+	return b.status(StyleWarning, format, args...)
+}
+
+// Error adds a line announcing a failure
+func (b *Block) Error(format string, args ...interface{}) *Block {
+	return b.status(StyleError, format, args...)
+}
+
+// Info adds a line announcing something that happened or would happen
+func (b *Block) Info(format string, args ...interface{}) *Block {
+	return b.status(StyleInfo, format, args...)
+}
+
+// Field adds an indented "label: value" line with a muted label
+func (b *Block) Field(label string, format string, args ...interface{}) *Block {
+	line := "  " + b.printer.Paint(StyleMuted, label+":")
+	if value := fmt.Sprintf(format, args...); value != "" {
+		line += " " + value
+	}
+	b.buf.WriteString(line + "\n")
+	return b
+}
+
+// Line adds a line indented by two spaces per level
+func (b *Block) Line(indent int, format string, args ...interface{}) *Block {
+	fmt.Fprintf(&b.buf, "%s%s\n", strings.Repeat("  ", indent), fmt.Sprintf(format, args...))
+	return b
+}
+
+// Blank adds an empty line
+func (b *Block) Blank() *Block {
+	b.buf.WriteByte('\n')
+	return b
+}
+
+// Flush writes the buffered lines at once
+func (b *Block) Flush() {
+	if b.buf.Len() == 0 {
+		return
+	}
+	b.printer.mu.Lock()
+	defer b.printer.mu.Unlock()
+	b.w.Write(b.buf.Bytes())
+	b.buf.Reset()
+}
+
+func (b *Block) status(style Style, format string, args ...interface{}) *Block {
+	text := fmt.Sprintf(format, args...)
+	if icon := b.printer.theme.Icons[style]; icon != "" {
+		text = b.printer.Paint(style, icon) + " " + text
+	}
+	b.buf.WriteString(text + "\n")
+	return b
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		terminal bool
+		expected bool
+	}{
+		{name: "should color terminals", terminal: true, expected: true},
+		{name: "should not color pipes and CI logs", terminal: false, expected: false},
+		{name: "should honor NO_COLOR on terminals", env: map[string]string{"NO_COLOR": "1"}, terminal: true, expected: false},
+		{name: "should prefer NO_COLOR over FORCE_COLOR", env: map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, terminal: true, expected: false},
+		{name: "should force colors with FORCE_COLOR", env: map[string]string{"FORCE_COLOR": "1"}, expected: true},
+		{name: "should force colors with CLICOLOR_FORCE", env: map[string]string{"CLICOLOR_FORCE": "1"}, expected: true},
+		{name: "should ignore CLICOLOR_FORCE=0", env: map[string]string{"CLICOLOR_FORCE": "0"}, expected: false},
+		{name: "should not color dumb terminals", env: map[string]string{"TERM": "dumb"}, terminal: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			assert.Equal(t, tt.expected, colorEnabled(getenv, tt.terminal))
+		})
+	}
+}
+
+func TestLookupTheme(t *testing.T) {
+	t.Run("should return the default theme for an empty name", func(t *testing.T) {
+		theme, err := LookupTheme("")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultTheme, theme.Name)
+	})
+
+	t.Run("should list the available themes for an unknown name", func(t *testing.T) {
+		_, err := LookupTheme("neon")
+		assert.EqualError(t, err, "unknown theme 'neon' (available: default, high-contrast, plain)")
+	})
+}
+
+func TestBlock(t *testing.T) {
+	t.Run("should write icons and fields without colors", func(t *testing.T) {
+		theme, _ := LookupTheme(DefaultTheme)
+		var out, errOut bytes.Buffer
+		printer := New(&out, &errOut, theme, false)
+
+		printer.Block().Success("Processed %s", "owner/repo").Field("Files", "%d", 3).Field("Largest files", "").Line(2, "main.go").Blank().Flush()
+		printer.Errorf("Failed to process %s", "owner/other")
+
+		assert.Equal(t, "✓ Processed owner/repo\n  Files: 3\n  Largest files:\n    main.go\n\n", out.String())
+		assert.Equal(t, "✗ Failed to process owner/other\n", errOut.String())
+	})
+
+	t.Run("should color icons and labels with the theme", func(t *testing.T) {
+		theme, _ := LookupTheme(DefaultTheme)
+		var out bytes.Buffer
+		New(&out, &out, theme, true).Block().Warning("Rate limited").Field("Files", "2").Flush()
+
+		assert.Equal(t, "\x1b[33m⚠\x1b[0m Rate limited\n  \x1b[2mFiles:\x1b[0m 2\n", out.String())
+	})
+
+	t.Run("should use ASCII icons and no colors with the plain theme", func(t *testing.T) {
+		theme, _ := LookupTheme("plain")
+		var out bytes.Buffer
+		New(&out, &out, theme, true).Block().Success("done").Warning("partial").Error("failed").Info("would process").Flush()
+
+		assert.Equal(t, "+ done\n! partial\nx failed\n- would process\n", out.String())
+	})
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"path"
+	"strings"
+)
+
+// sourceExtensions are the extensions of source code files
+var sourceExtensions = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".mjs": true, ".ts": true, ".java": true,
+	".c": true, ".cpp": true, ".cs": true, ".rs": true, ".rb": true,
+}
+
+// entrypointStems are the names, without extension, of the source files programs start from
+var entrypointStems = map[string]bool{
+	"main": true, "__main__": true, "index": true, "app": true, "server": true,
+	"manage": true, "wsgi": true, "program": true,
+}
+
+// configNames are configuration files recognized by their whole name
+var configNames = map[string]bool{
+	"dockerfile": true, "makefile": true, "go.mod": true, "setup.cfg": true, "pom.xml": true,
+	"build.gradle": true, ".gitignore": true, ".dockerignore": true, ".editorconfig": true,
+}
+
+// configExtensions are the extensions of configuration files
+var configExtensions = map[string]bool{
+	".json": true, ".yaml": true, ".yml": true, ".toml": true, ".ini": true,
+	".cfg": true, ".conf": true, ".env": true, ".properties": true,
+}
+
+// docPrefixes start the names of the documents found at the root of repositories
+var docPrefixes = []string{"readme", "changelog", "contributing", "license", "authors"}
+
+// docExtensions are the extensions of documentation files other than Markdown
+var docExtensions = map[string]bool{".rst": true, ".adoc": true, ".txt": true}
+
+// testDirs are the directories holding tests and their fixtures
+var testDirs = []string{"test/", "tests/", "__tests__/", "testdata/", "spec/"}
+
+// IsSourceFile reports whether filePath is a source code file
+func IsSourceFile(filePath string) bool {
+	return sourceExtensions[strings.ToLower(path.Ext(filePath))]
+}
+
+// IsEntrypointFile reports whether filePath is a source file a program starts from, like
+// main.go or index.ts
+func IsEntrypointFile(filePath string) bool {
+	fileName := strings.ToLower(path.Base(filePath))
+	return IsSourceFile(fileName) && entrypointStems[strings.TrimSuffix(fileName, path.Ext(fileName))]
+}
+
+// IsConfigFile reports whether filePath configures the build, tooling or deployment of a
+// project
+func IsConfigFile(filePath string) bool {
+	fileName := strings.ToLower(path.Base(filePath))
+	return configNames[fileName] || configExtensions[path.Ext(fileName)] || strings.HasPrefix(fileName, ".env")
+}
+
+// IsMarkdownFile reports whether filePath is a Markdown document
+func IsMarkdownFile(filePath string) bool {
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".md", ".markdown", ".mdx":
+		return true
+	}
+	return false
+}
+
+// IsDocFile reports whether filePath is documentation: Markdown and other text documents,
+// READMEs, licenses and the like, or any file under a docs directory
+func IsDocFile(filePath string) bool {
+	lowerPath := strings.ToLower(filePath)
+	fileName := path.Base(lowerPath)
+
+	for _, prefix := range docPrefixes {
+		if strings.HasPrefix(fileName, prefix) {
+			return true
+		}
+	}
+	if IsMarkdownFile(fileName) || docExtensions[path.Ext(fileName)] {
+		return true
+	}
+	return strings.HasPrefix(lowerPath, "docs/") || strings.Contains(lowerPath, "/docs/")
+}
+
+// IsTestFile reports whether filePath is a test, named like one or found in a test directory
+func IsTestFile(filePath string) bool {
+	lowerPath := strings.ToLower(filePath)
+	fileName := path.Base(lowerPath)
+
+	if strings.HasPrefix(fileName, "test_") ||
+		strings.Contains(fileName, "_test.") ||
+		strings.Contains(fileName, ".test.") ||
+		strings.Contains(fileName, ".spec.") {
+		return true
+	}
+
+	for _, dir := range testDirs {
+		if strings.HasPrefix(lowerPath, dir) || strings.Contains(lowerPath, "/"+dir) {
+			return true
+		}
+	}
+	return false
+}
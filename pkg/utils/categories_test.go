@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCategories(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		entrypoint bool
+		config     bool
+		doc        bool
+		test       bool
+	}{
+		{name: "should detect entry points", path: "cmd/server/main.go", entrypoint: true},
+		{name: "should detect entry points by stem", path: "src/index.ts", entrypoint: true},
+		{name: "should not take non-source files for entry points", path: "public/index.html"},
+		{name: "should detect configuration by name", path: "Dockerfile", config: true},
+		{name: "should detect configuration by extension", path: "deploy/values.yaml", config: true},
+		{name: "should detect environment files", path: ".env.local", config: true},
+		{name: "should detect Markdown documents", path: "guide.mdx", doc: true},
+		{name: "should detect root documents", path: "LICENSE", doc: true},
+		{name: "should detect files under docs", path: "docs/conf.py", doc: true},
+		{name: "should detect tests by name", path: "src/app.spec.ts", test: true},
+		{name: "should detect tests by directory", path: "tests/helpers.py", test: true},
+		{name: "should detect nothing for plain source", path: "internal/service.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.entrypoint, IsEntrypointFile(tt.path))
+			assert.Equal(t, tt.config, IsConfigFile(tt.path))
+			assert.Equal(t, tt.doc, IsDocFile(tt.path))
+			assert.Equal(t, tt.test, IsTestFile(tt.path))
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Clock provides the current time so callers can be tested with a fixed time
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the time from the operating system
+type SystemClock struct{}
+
+// Now returns the current local time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same time
+type FixedClock struct {
+	Time time.Time
+}
+
+// Now returns the fixed time
+func (c FixedClock) Now() time.Time {
+	return c.Time
+}
+
+// NewRunID returns a sortable run identifier made of the UTC start time and a random suffix
+func NewRunID(start time.Time) string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return start.UTC().Format("20060102T150405Z")
+	}
+	return start.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedClock(t *testing.T) {
+	t.Run("should always return the configured time", func(t *testing.T) {
+		fixed := time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC)
+		clock := FixedClock{Time: fixed}
+
+		assert.Equal(t, fixed, clock.Now())
+		assert.Equal(t, fixed, clock.Now())
+	})
+}
+
+func TestNewRunID(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	t.Run("should start with the UTC start time", func(t *testing.T) {
+		runID := NewRunID(start)
+		assert.Regexp(t, regexp.MustCompile(`^20240301T123000Z-[0-9a-f]{6}$`), runID)
+	})
+
+	t.Run("should differ between runs started at the same time", func(t *testing.T) {
+		assert.NotEqual(t, NewRunID(start), NewRunID(start))
+	})
+}
@@ -4,29 +4,56 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"unicode"
 )
 
+// siUnits makes FormatBytes and FormatTokenCount use SI units, see SetSIUnits
+var siUnits atomic.Bool
+
+// SetSIUnits switches formatted sizes to powers of 1000 labelled kB, MB, GB and abbreviates
+// formatted token counts like 3.4k. By default sizes use powers of 1024 labelled KB, MB, GB
+// and token counts are written in full
+func SetSIUnits(enabled bool) {
+	siUnits.Store(enabled)
+}
+
 // FormatBytes formats byte counts into human-readable strings
 func FormatBytes(bytes int64) string {
-	const unit = 1024
+	unit, units := int64(1024), []string{"KB", "MB", "GB", "TB"}
+	if siUnits.Load() {
+		unit, units = 1000, []string{"kB", "MB", "GB", "TB"}
+	}
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
 	}
 
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
+	div, exp := unit, 0
+	for n := bytes / unit; n >= unit && exp < len(units)-1; n /= unit {
 		div *= unit
 		exp++
 	}
 
-	units := []string{"KB", "MB", "GB", "TB"}
 	return fmt.Sprintf("%.1f %s", float64(bytes)/float64(div), units[exp])
 }
 
+// ParsePercent parses percentages like "10%" or "2.5" into a number above 0 and up to 100
+func ParsePercent(percentStr string) (float64, error) {
+	number := strings.TrimSuffix(strings.TrimSpace(percentStr), "%")
+	percent, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage: %s", percentStr)
+	}
+	if percent <= 0 || percent > 100 {
+		return 0, fmt.Errorf("percentage must be above 0%% and up to 100%%: %s", percentStr)
+	}
+	return percent, nil
+}
+
 // ParseSize parses size strings like "1MB", "500KB" into bytes
 func ParseSize(sizeStr string) (int64, error) {
 	sizeStr = strings.TrimSpace(strings.ToUpper(sizeStr))
@@ -61,6 +88,20 @@ func ParseSize(sizeStr string) (int64, error) {
 	return int64(size * float64(multiplier)), nil
 }
 
+// ExpandHome replaces a leading ~ in path with the home directory of the current user.
+// Other paths, including ~user forms, are returned unchanged.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(os.PathSeparator)) {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot expand %s: %w", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
 // ExtractFileName extracts the filename from a file path
 func ExtractFileName(path string) string {
 	parts := strings.Split(path, "/")
@@ -97,6 +138,26 @@ func IsTextFile(content string) bool {
 	return true
 }
 
+// nameToken matches a {token} placeholder of a name template
+var nameToken = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// ExpandNameTemplate replaces the {token} placeholders of template with their values,
+// failing on tokens without a value
+func ExpandNameTemplate(template string, values map[string]string) (string, error) {
+	var unknown []string
+	expanded := nameToken.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, exists := values[placeholder[1:len(placeholder)-1]]
+		if !exists {
+			unknown = append(unknown, placeholder)
+		}
+		return value
+	})
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown name token %s", strings.Join(unknown, ", "))
+	}
+	return expanded, nil
+}
+
 // SanitizeRepoName sanitizes repository names for use in filenames
 func SanitizeRepoName(repoPath string) string {
 	// Replace problematic characters with underscores
@@ -112,6 +173,25 @@ func SanitizeRepoName(repoPath string) string {
 	return sanitized
 }
 
+// FilePriority ranks a file by its likely value as context, lower first: entry points,
+// configuration, documentation, source code, other files and finally tests
+func FilePriority(filePath string) int {
+	switch {
+	case IsTestFile(filePath):
+		return 6
+	case IsEntrypointFile(filePath):
+		return 1
+	case IsConfigFile(filePath):
+		return 2
+	case IsDocFile(filePath):
+		return 3
+	case IsSourceFile(filePath):
+		return 4
+	default:
+		return 5
+	}
+}
+
 // IsBinaryFile checks if a file is binary by reading the first few bytes
 func IsBinaryFile(filePath string) bool {
 	file, err := os.Open(filePath)
@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSanitizeRepoName(t *testing.T) {
@@ -52,6 +54,78 @@ func TestSanitizeRepoName(t *testing.T) {
 	}
 }
 
+func TestExpandNameTemplate(t *testing.T) {
+	values := map[string]string{"repo": "owner/repo", "ref": "main", "sha": "ab12cd3"}
+
+	t.Run("should replace the tokens with their values", func(t *testing.T) {
+		name, err := ExpandNameTemplate("{repo}@{ref}_{sha}", values)
+		assert.NoError(t, err)
+		assert.Equal(t, "owner/repo@main_ab12cd3", name)
+	})
+
+	t.Run("should keep text without tokens", func(t *testing.T) {
+		name, err := ExpandNameTemplate("docs-{ref}", values)
+		assert.NoError(t, err)
+		assert.Equal(t, "docs-main", name)
+	})
+
+	t.Run("should fail on unknown tokens", func(t *testing.T) {
+		_, err := ExpandNameTemplate("{repo}@{branch}", values)
+		assert.ErrorContains(t, err, "unknown name token {branch}")
+	})
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected float64
+		err      string
+	}{
+		{name: "should parse percentages", input: "10%", expected: 10},
+		{name: "should parse bare numbers", input: " 2.5 ", expected: 2.5},
+		{name: "should accept the whole", input: "100%", expected: 100},
+		{name: "should reject zero", input: "0%", err: "must be above 0% and up to 100%"},
+		{name: "should reject more than the whole", input: "150%", err: "must be above 0% and up to 100%"},
+		{name: "should reject other text", input: "half", err: "invalid percentage: half"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percent, err := ParsePercent(tt.input)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, percent)
+		})
+	}
+}
+
+func TestFilePriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{name: "should rank entry points first", path: "cmd/sherpa/main.go", expected: 1},
+		{name: "should rank configuration second", path: "config/app.yaml", expected: 2},
+		{name: "should rank documentation third", path: "docs/README.md", expected: 3},
+		{name: "should rank source code fourth", path: "internal/store.go", expected: 4},
+		{name: "should rank other files fifth", path: "assets/logo.svg", expected: 5},
+		{name: "should rank tests last", path: "testdata/fixture.bin", expected: 6},
+		{name: "should rank tests of entry points last", path: "cmd/sherpa/main_test.go", expected: 6},
+		{name: "should not take names containing main for entry points", path: "internal/domain.go", expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FilePriority(tt.path))
+		})
+	}
+}
+
 func TestFormatBytes(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -108,6 +182,44 @@ func TestFormatBytes(t *testing.T) {
 	}
 }
 
+func TestFormatBytes_SIUnits(t *testing.T) {
+	SetSIUnits(true)
+	t.Cleanup(func() { SetSIUnits(false) })
+
+	tests := []struct {
+		name     string
+		bytes    int64
+		expected string
+	}{
+		{
+			name:     "should format bytes",
+			bytes:    999,
+			expected: "999 B",
+		},
+		{
+			name:     "should format kilobytes in powers of 1000",
+			bytes:    1500,
+			expected: "1.5 kB",
+		},
+		{
+			name:     "should format megabytes in powers of 1000",
+			bytes:    2500000,
+			expected: "2.5 MB",
+		},
+		{
+			name:     "should format gigabytes in powers of 1000",
+			bytes:    1000000000,
+			expected: "1.0 GB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatBytes(tt.bytes))
+		})
+	}
+}
+
 func TestParseSize(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -248,6 +360,47 @@ func TestIsTextFile(t *testing.T) {
 	}
 }
 
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "should expand the home directory",
+			path:     "~",
+			expected: home,
+		},
+		{
+			name:     "should expand paths under the home directory",
+			path:     "~/work/api",
+			expected: filepath.Join(home, "work", "api"),
+		},
+		{
+			name:     "should keep paths of other users",
+			path:     "~alice/work",
+			expected: "~alice/work",
+		},
+		{
+			name:     "should keep other paths",
+			path:     "./work/~/api",
+			expected: "./work/~/api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := ExpandHome(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, expanded)
+		})
+	}
+}
+
 func TestExtractFileName(t *testing.T) {
 	tests := []struct {
 		name     string
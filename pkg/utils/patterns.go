@@ -1,75 +1,174 @@
 package utils
 
 import (
-	"path/filepath"
+	"path"
 	"strings"
 )
 
-// PatternMatcher handles file pattern matching for ignore and include patterns
-type PatternMatcher struct {
-	ignorePatterns  []string
-	includePatterns []string
+// Pattern is an ignore or include pattern compiled for matching paths relative to the
+// repository root, with the syntax of .gitignore files:
+//
+//   - *, ? and [...] match within a path segment, and a ** segment matches any number of
+//     segments, so src/**/testdata/** matches every testdata directory below src
+//   - a pattern with a / at its start or in its middle is anchored at the root, while other
+//     patterns, like *.log, match at any depth
+//   - a pattern ending with / only matches directories, like node_modules/
+//   - a pattern starting with ! excludes again the paths matched by the patterns before it,
+//     and a leading \ escapes a pattern starting with ! or #
+//
+// A pattern matching a directory matches everything below it. Unlike in .gitignore files, a
+// negated pattern can exclude again a path whose parent directory is matched, so
+// src/** followed by !src/**/*_test.go matches the sources without their tests. Negated
+// patterns only apply to the list they are part of: lists from sources trusted differently,
+// like a central configuration and a repository, must be matched separately so one cannot
+// re-include what the other matches.
+type Pattern struct {
+	segments []string
+	negated  bool
+	dirOnly  bool
 }
 
-// NewPatternMatcher creates a new pattern matcher
-func NewPatternMatcher(ignorePatterns, includePatterns []string) *PatternMatcher {
-	return &PatternMatcher{
-		ignorePatterns:  ignorePatterns,
-		includePatterns: includePatterns,
+// CompilePattern compiles pattern for matching
+func CompilePattern(pattern string) Pattern {
+	var p Pattern
+	if strings.HasPrefix(pattern, "!") {
+		p.negated = true
+		pattern = pattern[1:]
 	}
-}
+	if strings.HasSuffix(pattern, "/") {
+		p.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimLeft(pattern, "/")
 
-// ShouldIgnore checks if a file should be ignored based on ignore patterns
-func (pm *PatternMatcher) ShouldIgnore(filePath string) bool {
-	for _, pattern := range pm.ignorePatterns {
-		if pm.matchesPattern(filePath, pattern) {
-			return true
-		}
+	p.segments = strings.Split(pattern, "/")
+	if !anchored {
+		p.segments = append([]string{"**"}, p.segments...)
 	}
-	return false
+	return p
 }
 
-// ShouldInclude checks if a file should be included based on include patterns
-// Returns true if no include patterns are specified or if the file matches any include pattern
-func (pm *PatternMatcher) ShouldInclude(filePath string) bool {
-	if len(pm.includePatterns) == 0 {
-		return true
+// CompilePatterns compiles every pattern of patterns, keeping their order
+func CompilePatterns(patterns []string) []Pattern {
+	compiled := make([]Pattern, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i] = CompilePattern(pattern)
 	}
+	return compiled
+}
+
+// Negated reports whether the pattern starts with !
+func (p Pattern) Negated() bool {
+	return p.negated
+}
+
+// Matches reports whether filePath, or one of its parent directories, matches the pattern,
+// regardless of its negation
+func (p Pattern) Matches(filePath string) bool {
+	return p.match(filePath, false)
+}
 
-	for _, pattern := range pm.includePatterns {
-		if pm.matchesPattern(filePath, pattern) {
+// match reports whether path, or one of its parent directories, matches the pattern. The
+// path itself is matched by directory-only patterns when isDir is set.
+func (p Pattern) match(path string, isDir bool) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	last := len(parts)
+	if p.dirOnly && !isDir {
+		// Only parent directories are known to be directories
+		last--
+	}
+	for n := 1; n <= last; n++ {
+		if matchSegments(p.segments, parts[:n]) {
 			return true
 		}
 	}
 	return false
 }
 
-// matchesPattern checks if a file path matches a pattern
-func (pm *PatternMatcher) matchesPattern(filePath, pattern string) bool {
-	// Handle glob patterns
-	if matched, err := filepath.Match(pattern, filepath.Base(filePath)); err == nil && matched {
-		return true
+// matchSegments reports whether the segments of a path match the segments of a pattern
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// A trailing ** matches everything inside a directory, not the directory itself
+			start := 0
+			if len(pattern) == 1 {
+				start = 1
+			}
+			for i := start; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], parts[0]); err != nil || !matched {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
 	}
+	return len(parts) == 0
+}
 
-	// Handle full path patterns
-	if matched, err := filepath.Match(pattern, filePath); err == nil && matched {
-		return true
-	}
+// MatchPatterns reports whether filePath matches patterns. The last pattern matching it
+// decides, so negated patterns exclude the paths matched by the patterns before them.
+func MatchPatterns(patterns []string, filePath string) bool {
+	return matchCompiled(CompilePatterns(patterns), filePath, false)
+}
 
-	// Handle directory patterns (ending with /)
-	if strings.HasSuffix(pattern, "/") {
-		dirPattern := strings.TrimSuffix(pattern, "/")
-		if strings.Contains(filePath, dirPattern+"/") || strings.HasPrefix(filePath, dirPattern+"/") {
-			return true
+// MatchDirPatterns reports whether the directory dirPath matches patterns, like
+// MatchPatterns but with directory-only patterns also matching dirPath itself
+func MatchDirPatterns(patterns []string, dirPath string) bool {
+	return matchCompiled(CompilePatterns(patterns), dirPath, true)
+}
+
+// matchCompiled reports whether path matches compiled patterns, like MatchPatterns
+func matchCompiled(patterns []Pattern, path string, isDir bool) bool {
+	matched := false
+	for _, pattern := range patterns {
+		// Only patterns that would change the outcome are matched
+		if pattern.negated == matched && pattern.match(path, isDir) {
+			matched = !pattern.negated
 		}
 	}
+	return matched
+}
 
-	// Handle substring matching for simple patterns
-	if strings.Contains(filePath, pattern) {
-		return true
+// PatternMatcher handles file pattern matching for ignore and include patterns
+type PatternMatcher struct {
+	ignorePatterns  []Pattern
+	includePatterns []Pattern
+}
+
+// NewPatternMatcher creates a new pattern matcher
+func NewPatternMatcher(ignorePatterns, includePatterns []string) *PatternMatcher {
+	return &PatternMatcher{
+		ignorePatterns:  CompilePatterns(ignorePatterns),
+		includePatterns: CompilePatterns(includePatterns),
 	}
+}
 
-	return false
+// ShouldIgnore checks if a file should be ignored based on ignore patterns
+func (pm *PatternMatcher) ShouldIgnore(filePath string) bool {
+	return matchCompiled(pm.ignorePatterns, filePath, false)
+}
+
+// ShouldIgnoreDir checks if a directory should be ignored, directory patterns like
+// node_modules/ also matching the directory itself
+func (pm *PatternMatcher) ShouldIgnoreDir(dirPath string) bool {
+	return matchCompiled(pm.ignorePatterns, dirPath, true)
+}
+
+// ShouldInclude checks if a file should be included based on include patterns
+// Returns true if no include patterns are specified or if the file matches the include patterns
+func (pm *PatternMatcher) ShouldInclude(filePath string) bool {
+	if len(pm.includePatterns) == 0 {
+		return true
+	}
+	return matchCompiled(pm.includePatterns, filePath, false)
 }
 
 // ParsePatterns parses comma-separated pattern strings into slices
@@ -90,3 +189,24 @@ func ParsePatterns(patternStr string) []string {
 
 	return result
 }
+
+// ParseIgnoreFile parses the patterns of a file with the syntax of .gitignore files, like
+// .sherpaignore: one pattern per line, skipping blank lines and comments starting with #.
+// Trailing spaces are dropped unless escaped with a backslash.
+func ParseIgnoreFile(content string) []string {
+	var patterns []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern := strings.TrimRight(line, " ")
+		if strings.HasSuffix(pattern, "\\") && len(pattern) < len(line) {
+			pattern += " "
+		}
+		if pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
@@ -80,4 +80,79 @@ func TestPatternMatcher(t *testing.T) {
 		assert.True(t, pm.ShouldInclude("any.file"))
 		assert.False(t, pm.ShouldIgnore("any.file"))
 	})
+
+	t.Run("should handle negated patterns", func(t *testing.T) {
+		pm := NewPatternMatcher([]string{"*.log", "!keep.log"}, []string{"src/**", "!src/**/*_test.go"})
+
+		assert.True(t, pm.ShouldIgnore("logs/app.log"))
+		assert.False(t, pm.ShouldIgnore("logs/keep.log"))
+		assert.True(t, pm.ShouldInclude("src/api/server.go"))
+		assert.False(t, pm.ShouldInclude("src/api/server_test.go"))
+	})
+}
+
+func TestMatchPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		expected bool
+	}{
+		{name: "should match base names at any depth", patterns: []string{"*.log"}, path: "logs/app/server.log", expected: true},
+		{name: "should match directories at any depth", patterns: []string{"node_modules/"}, path: "web/node_modules/react/index.js", expected: true},
+		{name: "should not match directory names as substrings", patterns: []string{"auth/"}, path: "services/oauth/token.go", expected: false},
+		{name: "should not match file names with directory patterns", patterns: []string{"build/"}, path: "scripts/build", expected: false},
+		{name: "should not match paths containing a plain pattern", patterns: []string{"test"}, path: "src/latest.go", expected: false},
+		{name: "should match everything below a matching directory", patterns: []string{"vendor"}, path: "vendor/github.com/pkg/errors/errors.go", expected: true},
+		{name: "should anchor patterns with a slash at the root", patterns: []string{"docs/*.md"}, path: "web/docs/guide.md", expected: false},
+		{name: "should anchor patterns starting with a slash", patterns: []string{"/README.md"}, path: "docs/README.md", expected: false},
+		{name: "should match anchored patterns from the root", patterns: []string{"/README.md"}, path: "README.md", expected: true},
+		{name: "should not cross directories with a single star", patterns: []string{"src/*.go"}, path: "src/api/server.go", expected: false},
+		{name: "should match any depth with a double star", patterns: []string{"src/**/*.go"}, path: "src/api/v1/server.go", expected: true},
+		{name: "should match no directory with a double star", patterns: []string{"src/**/*.go"}, path: "src/main.go", expected: true},
+		{name: "should match every file below a double star", patterns: []string{"src/**/testdata/**"}, path: "src/parser/testdata/input.txt", expected: true},
+		{name: "should not match outside the anchored directory", patterns: []string{"src/**/testdata/**"}, path: "testdata/input.txt", expected: false},
+		{name: "should match nested directories with a leading double star", patterns: []string{"**/fixtures/*.json"}, path: "a/b/fixtures/user.json", expected: true},
+		{name: "should exclude paths with negated patterns", patterns: []string{"*.md", "!CHANGELOG.md"}, path: "CHANGELOG.md", expected: false},
+		{name: "should keep other paths with negated patterns", patterns: []string{"*.md", "!CHANGELOG.md"}, path: "README.md", expected: true},
+		{name: "should let the last matching pattern decide", patterns: []string{"docs/", "!docs/api/", "docs/api/internal/"}, path: "docs/api/internal/keys.md", expected: true},
+		{name: "should not match negated patterns alone", patterns: []string{"!*.go"}, path: "main.go", expected: false},
+		{name: "should not match invalid patterns", patterns: []string{"[a-"}, path: "a", expected: false},
+		{name: "should match a leading ! escaped with a backslash", patterns: []string{"\\!important.md"}, path: "docs/!important.md", expected: true},
+		{name: "should match a leading # escaped with a backslash", patterns: []string{"\\#notes"}, path: "#notes", expected: true},
+		{name: "should not match the directory itself with a trailing double star", patterns: []string{"dist/**"}, path: "dist", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchPatterns(tt.patterns, tt.path))
+		})
+	}
+}
+
+func TestParseIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{name: "should read one pattern per line", content: "*.log\nnode_modules/\n", expected: []string{"*.log", "node_modules/"}},
+		{name: "should skip comments and blank lines", content: "# Logs\n\n*.log\r\n", expected: []string{"*.log"}},
+		{name: "should keep escaped leading characters", content: "\\#notes\n\\!keep\n", expected: []string{"\\#notes", "\\!keep"}},
+		{name: "should drop unescaped trailing spaces", content: "build/  \nname\\  \n", expected: []string{"build/", "name\\ "}},
+		{name: "should read nothing from an empty file", content: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseIgnoreFile(tt.content))
+		})
+	}
+}
+
+func TestMatchDirPatterns(t *testing.T) {
+	assert.True(t, MatchDirPatterns([]string{"private/"}, "private"))
+	assert.True(t, MatchDirPatterns([]string{"node_modules/"}, "web/node_modules/.cache"))
+	assert.False(t, MatchDirPatterns([]string{"private/", "!private/"}, "private"))
+	assert.False(t, MatchDirPatterns([]string{"*.go"}, "private"))
 }
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Piece lengths a BPE vocabulary covers with a single token
+const (
+	lettersPerToken     = 6  // Common words are one token, long identifiers split every few letters
+	digitsPerToken      = 3  // Numbers are split into groups of up to three digits
+	symbolBytesPerToken = 2  // Punctuation pairs like "()" or ":=" are usually merged
+	whitespacePerToken  = 16 // Indentation runs are merged into single tokens
+)
+
+// TokenSafetyMargin is the share of a token limit kept free when the limit is enforced with
+// EstimateTokens, since a real tokenizer may count more tokens than estimated
+const TokenSafetyMargin = 0.1
+
+// EstimateTokens estimates the tokens of text for BPE tokenizers like tiktoken's cl100k_base.
+// Text is cut into the pieces of the cl100k pre-tokenizer: words carrying their leading space,
+// digit runs, punctuation runs and whitespace. Without the tokenizer's merge table, each piece
+// is then charged by length, assuming common words are one token and long ones split every
+// few letters. The estimate is not exact: rare words, identifiers and non-Latin scripts can
+// split into more tokens than charged, and repeated runs of symbols into fewer. Limits are
+// enforced on it with TokenLimit, keeping TokenSafetyMargin of the limit free.
+func EstimateTokens(text string) int {
+	runes := []rune(text)
+	tokens := 0
+	for i := 0; i < len(runes); {
+		start := i
+
+		// A single space is part of the word or punctuation following it
+		if runes[i] == ' ' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && !unicode.IsDigit(runes[i+1]) {
+			i++
+			start = i
+		}
+
+		switch r := runes[i]; {
+		case isWordRune(r):
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens += wordTokens(runes[start:i])
+		case unicode.IsDigit(r):
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens += ceilDiv(i-start, digitsPerToken)
+		case unicode.IsSpace(r):
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+			// Leave the last space to the word that follows, as the pre-tokenizer does
+			if i < len(runes) && i-start > 1 && runes[i-1] == ' ' && !unicode.IsDigit(runes[i]) {
+				i--
+			}
+			tokens += whitespaceTokens(runes[start:i])
+		default:
+			for i < len(runes) && !isWordRune(runes[i]) && !unicode.IsDigit(runes[i]) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			tokens += ceilDiv(len(string(runes[start:i])), symbolBytesPerToken)
+		}
+	}
+	return tokens
+}
+
+// isWordRune reports whether r belongs to a word piece
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r)
+}
+
+// wordTokens charges Latin letters by length and other scripts, like CJK, one token per
+// character
+func wordTokens(word []rune) int {
+	latin, other := 0, 0
+	for _, r := range word {
+		if r < 0x250 {
+			latin++
+		} else {
+			other++
+		}
+	}
+	return ceilDiv(latin, lettersPerToken) + other
+}
+
+// whitespaceTokens charges line breaks and the indentation after them separately
+func whitespaceTokens(run []rune) int {
+	lastBreak := -1
+	for i, r := range run {
+		if r == '\n' || r == '\r' {
+			lastBreak = i
+		}
+	}
+	if lastBreak < 0 || lastBreak == len(run)-1 {
+		return ceilDiv(len(run), whitespacePerToken)
+	}
+	return 1 + ceilDiv(len(run)-lastBreak-1, whitespacePerToken)
+}
+
+// TokenLimit returns the estimated tokens allowed under a limit of limit tokens, keeping
+// TokenSafetyMargin of it free for the error of EstimateTokens
+func TokenLimit(limit int) int {
+	return limit - int(float64(limit)*TokenSafetyMargin)
+}
+
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
+
+// ParseTokenCount parses a token count like 8000, 100k or 1.5M
+func ParseTokenCount(countStr string) (int, error) {
+	number := strings.TrimSpace(strings.ToUpper(countStr))
+
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(number, "K"):
+		multiplier = 1000
+		number = strings.TrimSuffix(number, "K")
+	case strings.HasSuffix(number, "M"):
+		multiplier = 1000 * 1000
+		number = strings.TrimSuffix(number, "M")
+	}
+
+	count, err := strconv.ParseFloat(number, 64)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid token count: %s", countStr)
+	}
+	return int(count * multiplier), nil
+}
+
+// FormatTokenCount formats a token count in full, or like 3.4k and 1.2M with SI units
+func FormatTokenCount(count int) string {
+	if !siUnits.Load() || count < 1000 {
+		return strconv.Itoa(count)
+	}
+	if count < 1000*1000 {
+		return fmt.Sprintf("%.1fk", float64(count)/1000)
+	}
+	return fmt.Sprintf("%.1fM", float64(count)/(1000*1000))
+}
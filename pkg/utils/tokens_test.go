@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{name: "should return zero for empty text", text: "", expected: 0},
+		{name: "should count common words with their leading space once", text: "hello world", expected: 2},
+		{name: "should split long identifiers", text: "processRepositoriesConcurrently", expected: 6},
+		{name: "should merge punctuation pairs", text: "func main() {}", expected: 4},
+		{name: "should group digits by three", text: "1234567", expected: 3},
+		{name: "should charge line breaks and indentation separately", text: "{\n\t\treturn", expected: 4},
+		{name: "should count one token per CJK character", text: "日本語", expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EstimateTokens(tt.text))
+		})
+	}
+}
+
+func TestTokenLimit(t *testing.T) {
+	t.Run("should keep the safety margin of a limit free", func(t *testing.T) {
+		assert.Equal(t, 90000, TokenLimit(100000))
+		assert.Equal(t, 0, TokenLimit(0))
+	})
+}
+
+func TestParseTokenCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{name: "should parse plain counts", input: "8000", expected: 8000},
+		{name: "should parse thousands", input: "100k", expected: 100000},
+		{name: "should parse fractional millions", input: "1.5M", expected: 1500000},
+		{name: "should reject other units", input: "10MB", wantErr: true},
+		{name: "should reject negative counts", input: "-5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := ParseTokenCount(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+}
+
+func TestFormatTokenCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		si       bool
+		expected string
+	}{
+		{name: "should write counts in full by default", count: 123456, expected: "123456"},
+		{name: "should keep small counts in full with SI units", count: 999, si: true, expected: "999"},
+		{name: "should abbreviate thousands with SI units", count: 3400, si: true, expected: "3.4k"},
+		{name: "should abbreviate millions with SI units", count: 1250000, si: true, expected: "1.2M"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSIUnits(tt.si)
+			t.Cleanup(func() { SetSIUnits(false) })
+
+			assert.Equal(t, tt.expected, FormatTokenCount(tt.count))
+		})
+	}
+}